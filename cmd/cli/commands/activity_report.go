@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// ActivityReportCmd creates the activityReport command
+func ActivityReportCmd(app *AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "activityReport",
+		Short: "Report each volunteer's shifts, changes and responses across a date range",
+		Long: `Lists every active volunteer — and anyone else with something in the range —
+with the shifts they were allocated, the times they were put on or taken off a
+shift since, the Roles they worked, how many rounds they answered and the date
+of their last shift. Use --format csv or --format json to save it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+			format, _ := cmd.Flags().GetString("format")
+			if format != "table" && format != "csv" && format != "json" {
+				return fmt.Errorf("format must be table, csv or json, got: %s", format)
+			}
+
+			app.Logger.Debug("activityReport command",
+				zap.String("from", from),
+				zap.String("to", to),
+				zap.String("format", format))
+
+			report, err := services.BuildActivityReport(
+				app.Ctx,
+				app.Database,
+				app.Volunteers,
+				app.Cfg,
+				services.ActivityReportParams{From: from, To: to},
+				app.Logger,
+			)
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "csv":
+				return services.WriteActivityReportCSV(os.Stdout, report)
+			case "json":
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(report)
+			}
+			printActivityTable(os.Stdout, report)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("from", "", "First date of the range, e.g. 2026-01-01 (required)")
+	cmd.Flags().String("to", "", "Last date of the range, e.g. 2026-06-30 (required)")
+	cmd.Flags().String("format", "table", "Output format: table, csv or json")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+// printActivityTable writes the report for reading in a terminal. The two
+// things a coordinator scans for are at the ends of a row — how much somebody
+// worked, and when they last did — so everything else sits between them.
+func printActivityTable(w io.Writer, report *services.ActivityReport) {
+	fmt.Fprintf(w, "\nVolunteer activity %s to %s (%d rotas)\n\n", report.From, report.To, report.Rotas)
+
+	nameColWidth := 20
+	for _, v := range report.Volunteers {
+		if len(v.VolunteerName)+2 > nameColWidth {
+			nameColWidth = len(v.VolunteerName) + 2
+		}
+	}
+
+	fmt.Fprintf(w, "%-*s%-8s%-10s%-8s%-8s%-12s%-14s%s\n", nameColWidth, "", "Worked", "Allocated", "In", "Out", "Responded", "Last shift", "Roles")
+	fmt.Fprintln(w, strings.Repeat("-", nameColWidth+60))
+	for _, v := range report.Volunteers {
+		name := v.VolunteerName
+		if !v.Active {
+			name += " *"
+		}
+		last := v.LastShift
+		if last == "" {
+			last = "-"
+		}
+		fmt.Fprintf(w, "%-*s%-8d%-10d%-8d%-8d%-12s%-14s%s\n",
+			nameColWidth, name,
+			v.ShiftsWorked,
+			v.ShiftsAllocated,
+			v.AlterationsIn,
+			v.AlterationsOut,
+			fmt.Sprintf("%d/%d", v.Responses, v.RoundsAsked),
+			last,
+			rolesCell(v.Roles),
+		)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "  Worked    = shifts on the rota as it now stands; Allocated = what allocation gave them")
+	fmt.Fprintln(w, "  In / Out  = times put on or taken off a shift after allocation")
+	fmt.Fprintln(w, "  Responded = rounds answered before allocation, of those they were sent")
+	fmt.Fprintln(w, "  *         = no longer active")
+}
+
+// rolesCell is the Roles somebody worked, shortest form: "Team lead 2, Service
+// volunteer 1".
+func rolesCell(roles []services.RoleShifts) string {
+	parts := make([]string, 0, len(roles))
+	for _, r := range roles {
+		parts = append(parts, fmt.Sprintf("%s %d", r.Role, r.Shifts))
+	}
+	return strings.Join(parts, ", ")
+}
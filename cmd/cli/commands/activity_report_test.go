@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+func TestRolesCell(t *testing.T) {
+	assert.Equal(t, "", rolesCell(nil))
+	assert.Equal(t, "Team lead 2, Service volunteer 1", rolesCell([]services.RoleShifts{
+		{Role: "Team lead", Shifts: 2},
+		{Role: "Service volunteer", Shifts: 1},
+	}))
+}
+
+func TestPrintActivityTable(t *testing.T) {
+	var buf bytes.Buffer
+	printActivityTable(&buf, &services.ActivityReport{
+		From: "2026-01-01", To: "2026-03-31", Rotas: 2,
+		Volunteers: []services.VolunteerActivity{
+			{VolunteerName: "Alice Adams", Active: true, ShiftsWorked: 3, ShiftsAllocated: 4, AlterationsOut: 1, RoundsAsked: 2, Responses: 2, LastShift: "2026-03-01"},
+			{VolunteerName: "Tom Jones", RoundsAsked: 2},
+		},
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "2026-01-01 to 2026-03-31 (2 rotas)")
+	assert.Contains(t, out, "2/2")
+	assert.Contains(t, out, "Tom Jones *", "an inactive volunteer is marked")
+	assert.Contains(t, out, "0/2")
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/jakechorley/ilford-drop-in/internal/config"
 	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 )
 
@@ -14,8 +15,11 @@ import (
 type AppContext struct {
 	Cfg          *config.Config
 	SheetsClient *sheetsclient.Client
-	Database     *db.DB
-	Logger       *zap.Logger
-	Ctx          context.Context
-	UserEmail    string
+	// Volunteers is the roster, read from whichever source the config names:
+	// the sheet, through SheetsClient, or the database.
+	Volunteers services.VolunteerClient
+	Database   *db.DB
+	Logger     *zap.Logger
+	Ctx        context.Context
+	UserEmail  string
 }
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// ExportRotaCmd creates the export-rota command
+func ExportRotaCmd(app *AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-rota [rotaID]",
+		Short: "Export a rota as CSV, JSON or XLSX without touching Google Sheets",
+		Long: `Writes a rota laid out as the published sheet is — a column per Role in
+priority order, closed shifts marked, everybody where the rota's changes have
+left them. If no rotaID is provided, exports the latest rota.
+
+CSV and JSON go to stdout unless --output names a file. A workbook is not
+something to print, so --format xlsx writes rota-<start date>.xlsx when
+--output is not given.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rotaID := ""
+			if len(args) > 0 {
+				rotaID = args[0]
+			}
+			format, _ := cmd.Flags().GetString("format")
+			output, _ := cmd.Flags().GetString("output")
+			if err := services.CheckRotaExportFormat(format); err != nil {
+				return err
+			}
+
+			app.Logger.Debug("export-rota command",
+				zap.String("rota_id", rotaID),
+				zap.String("format", format),
+				zap.String("output", output))
+
+			rota, err := services.BuildPublishedRota(
+				app.Ctx,
+				app.Database,
+				app.Volunteers,
+				app.Cfg,
+				app.Logger,
+				rotaID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to build rota: %w", err)
+			}
+
+			if output == "" && format == services.RotaExportXLSX {
+				output = services.RotaExportFilename(rota, format)
+			}
+			if output == "" {
+				return services.WriteRotaExport(os.Stdout, rota, format)
+			}
+			if err := writeFile(output, func(w io.Writer) error {
+				return services.WriteRotaExport(w, rota, format)
+			}); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote rota starting %s to %s\n", rota.StartDate, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("format", services.RotaExportCSV, "Output format: csv, json or xlsx")
+	cmd.Flags().String("output", "", "File to write to (default stdout; rota-<start date>.xlsx for xlsx)")
+
+	return cmd
+}
+
+// writeFile writes a file through write, and does not leave half of one
+// behind when write fails.
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
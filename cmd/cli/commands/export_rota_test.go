@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "rota.csv")
+	require.NoError(t, writeFile(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "Date\n")
+		return err
+	}))
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "Date\n", string(got))
+
+	path = filepath.Join(dir, "broken.csv")
+	failed := errors.New("failed")
+	err = writeFile(path, func(w io.Writer) error {
+		_, _ = io.WriteString(w, "Da")
+		return failed
+	})
+	assert.ErrorIs(t, err, failed)
+	assert.NoFileExists(t, path, "half a file is not left behind")
+}
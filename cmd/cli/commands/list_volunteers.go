@@ -23,7 +23,7 @@ func ListVolunteersCmd(app *AppContext) *cobra.Command {
 			}
 
 			// Fetch volunteers
-			volunteers, err := app.SheetsClient.ListVolunteers(app.Cfg, roles)
+			volunteers, err := app.Volunteers.ListVolunteers(app.Cfg, roles)
 			if err != nil {
 				return fmt.Errorf("failed to list volunteers: %w", err)
 			}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// PrintRotaCmd creates the print-rota command
+func PrintRotaCmd(app *AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "print-rota [rotaID]",
+		Short: "Write a rota as a PDF to print",
+		Long: `Writes a rota as a landscape A4 PDF: a row per shift, a column per Role in
+its colour, everybody where the rota's changes have left them. If no rotaID is
+provided, prints the latest rota. The file is rota-<start date>.pdf unless
+--output names another.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rotaID := ""
+			if len(args) > 0 {
+				rotaID = args[0]
+			}
+			output, _ := cmd.Flags().GetString("output")
+
+			app.Logger.Debug("print-rota command", zap.String("rota_id", rotaID), zap.String("output", output))
+
+			overview, err := services.BuildRotaOverview(
+				app.Ctx,
+				app.Database,
+				app.Volunteers,
+				app.Cfg,
+				app.Logger,
+				rotaID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to build rota: %w", err)
+			}
+
+			if output == "" {
+				output = services.RotaOverviewFilename(overview)
+			}
+			if err := writeFile(output, func(w io.Writer) error {
+				return services.WriteRotaOverviewPDF(w, overview)
+			}); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote rota starting %s to %s\n", overview.Rota.StartDate, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("output", "", "File to write to (default rota-<start date>.pdf)")
+
+	return cmd
+}
+
+// SignInSheetCmd creates the sign-in-sheet command
+func SignInSheetCmd(app *AppContext) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign-in-sheet <date>",
+		Short: "Write the sign-in sheet for one shift as a PDF to print",
+		Long: `Writes the sheet people sign in on at the door for the shift on a date, e.g.
+2026-08-02: everyone on the shift with their Role and its times, then blank
+lines for walk-ins. The file is sign-in-<date>.pdf unless --output names
+another.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			walkIns, _ := cmd.Flags().GetInt("walk-ins")
+
+			app.Logger.Debug("sign-in-sheet command", zap.String("date", args[0]), zap.Int("walk_ins", walkIns))
+
+			shiftID, err := services.ShiftOnDate(app.Ctx, app.Database, args[0])
+			if err != nil {
+				return err
+			}
+			sheet, err := services.BuildSignInSheet(app.Ctx, app.Database, app.Volunteers, app.Cfg, shiftID)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				output = services.SignInSheetFilename(sheet)
+			}
+			if err := writeFile(output, func(w io.Writer) error {
+				return services.WriteSignInSheetPDF(w, sheet, walkIns)
+			}); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Wrote the sign-in sheet for %s to %s\n", sheet.Date, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("output", "", "File to write to (default sign-in-<date>.pdf)")
+	cmd.Flags().Int("walk-ins", services.DefaultWalkInRows, "Blank lines to leave for walk-ins")
+
+	return cmd
+}
@@ -31,7 +31,7 @@ func PublishRotaCmd(app *AppContext) *cobra.Command {
 				app.Ctx,
 				app.Database,
 				app.SheetsClient,
-				app.SheetsClient,
+				app.Volunteers,
 				app.Cfg,
 				app.Logger,
 				rotaID,
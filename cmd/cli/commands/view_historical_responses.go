@@ -36,7 +36,7 @@ func ViewHistoricalResponsesCmd(app *AppContext) *cobra.Command {
 			result, err := services.ViewHistoricalResponses(
 				app.Ctx,
 				app.Database,
-				app.SheetsClient,
+				app.Volunteers,
 				app.Cfg,
 				app.Logger,
 				count,
@@ -73,7 +73,7 @@ func ViewHistoricalResponsesCmd(app *AppContext) *cobra.Command {
 			for i := range result.Rotations {
 				fmt.Printf("%-*s", rotaColWidth, fmt.Sprintf("Rota %d", i+1))
 			}
-			fmt.Println()
+			fmt.Println("No-shows")
 
 			// Print header row with start dates
 			fmt.Printf("%-*s", nameColWidth, "")
@@ -121,6 +121,7 @@ func ViewHistoricalResponsesCmd(app *AppContext) *cobra.Command {
 						fmt.Printf("%s%-*s%s", colorDim, rotaColWidth, "Not asked", colorReset)
 					}
 				}
+				fmt.Print(attendanceCell(vol, result.Rotations, result.Matrix, colorRed, colorReset))
 				fmt.Println()
 			}
 
@@ -133,6 +134,7 @@ func ViewHistoricalResponsesCmd(app *AppContext) *cobra.Command {
 			fmt.Printf("  %s0/Y%s   = responded with no availability\n", colorRed, colorReset)
 			fmt.Printf("  %sNo response%s = asked, no response before allocation\n", colorRed, colorReset)
 			fmt.Printf("  %sNot asked%s   = no availability request for this rota\n", colorDim, colorReset)
+			fmt.Println("  No-shows    = shifts recorded as not turned up to, and left early, across these rotas")
 
 			return nil
 		},
@@ -168,3 +170,26 @@ func availabilityColor(available, total int, green, yellow, orange string) strin
 	}
 	return green
 }
+
+// attendanceCell is a volunteer's no-shows across the rotations, with the
+// shifts they left early alongside when there were any. Blank when nothing
+// of either was recorded, so the column only draws the eye where it should.
+func attendanceCell(vol model.Volunteer, rotations []db.Rotation, matrix map[string]map[string]services.VolunteerRotaStatus, red, reset string) string {
+	noShows, leftEarly := 0, 0
+	for _, rota := range rotations {
+		status := matrix[vol.ID][rota.ID]
+		noShows += status.NoShows
+		leftEarly += status.LeftEarly
+	}
+	if noShows == 0 && leftEarly == 0 {
+		return ""
+	}
+	cell := fmt.Sprintf("%d", noShows)
+	if noShows > 0 {
+		cell = red + cell + reset
+	}
+	if leftEarly > 0 {
+		cell += fmt.Sprintf(" (%d left early)", leftEarly)
+	}
+	return cell
+}
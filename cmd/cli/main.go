@@ -11,6 +11,7 @@ import (
 	"github.com/jakechorley/ilford-drop-in/cmd/cli/commands"
 	"github.com/jakechorley/ilford-drop-in/internal/config"
 	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 	"github.com/jakechorley/ilford-drop-in/pkg/utils"
 	"github.com/jakechorley/ilford-drop-in/pkg/utils/logging"
@@ -64,8 +65,12 @@ left here reads or writes a Sheet.`,
 	// in one step could not honour that — two paths where one breaks the rule is
 	// worse than one path (ADR 0008).
 	rootCmd.AddCommand(newLazyCommand(commands.PublishRotaCmd))
+	rootCmd.AddCommand(newLazyCommand(commands.ExportRotaCmd))
+	rootCmd.AddCommand(newLazyCommand(commands.PrintRotaCmd))
+	rootCmd.AddCommand(newLazyCommand(commands.SignInSheetCmd))
 	rootCmd.AddCommand(newLazyCommand(commands.ListVolunteersCmd))
 	rootCmd.AddCommand(newLazyCommand(commands.ViewHistoricalResponsesCmd))
+	rootCmd.AddCommand(newLazyCommand(commands.ActivityReportCmd))
 
 	// Not lazy, and deliberately not initialised: validate-config reads a file
 	// and nothing else, so it can vet a prod config from a laptop. It shadows
@@ -162,10 +167,16 @@ func initApp() error {
 	}
 	logger.Debug("PostgreSQL database initialized successfully")
 
+	var volunteers services.VolunteerClient = sheetsClient
+	if cfg.RosterSource() == config.RosterSourceDatabase {
+		volunteers = services.NewDatabaseRoster(database)
+	}
+
 	// Initialize the global app context
 	app = &commands.AppContext{
 		Cfg:          cfg,
 		SheetsClient: sheetsClient,
+		Volunteers:   volunteers,
 		Database:     database,
 		Logger:       logger,
 		Ctx:          ctx,
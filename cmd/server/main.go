@@ -17,7 +17,9 @@ import (
 	"github.com/jakechorley/ilford-drop-in/internal/devmode"
 	"github.com/jakechorley/ilford-drop-in/pkg/api"
 	"github.com/jakechorley/ilford-drop-in/pkg/clients/gmailclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/clients/mailclient"
 	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 	"github.com/jakechorley/ilford-drop-in/pkg/utils/logging"
@@ -84,7 +86,14 @@ func run(env string, portOverride int) error {
 	// the identity provider and outbound mail — so the server runs on a checkout
 	// with no credentials at all. Config keeps it to the dev environment; this
 	// branch is the only place it changes what gets built.
-	var syncVolunteers api.VolunteerSyncFunc
+	//
+	// fetchRoster reads the sheet (or its dev CSV) whichever source is the
+	// roster: where the database is, it is what an import copies. The startup
+	// load and the sync, and the cache they fill, exist only where the sheet is
+	// — there is nothing to sync a roster kept here from, and the sync
+	// endpoints answer 503 without one.
+	var fetchRoster api.RosterFetchFunc
+	sheetIsRoster := cfg.RosterSource() == config.RosterSourceSheet
 	var authenticator *api.Authenticator
 	var newMailer api.MailerFunc
 	if cfg.DevMode != nil {
@@ -123,28 +132,27 @@ func run(env string, portOverride int) error {
 			logger.Info("DEV MODE: seeded rota defaults")
 		}
 
-		syncVolunteers = func(ctx context.Context) error {
+		fetchRoster = func(ctx context.Context) ([]model.Volunteer, error) {
 			roles, err := services.RoleTable(ctx, database)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			fetched, err := devmode.LoadVolunteers(cfg.DevMode.VolunteersCSV, roles)
+			return devmode.LoadVolunteers(cfg.DevMode.VolunteersCSV, roles)
+		}
+
+		if sheetIsRoster {
+			// Unlike a Sheets outage this is a local file that either exists or
+			// does not, so an unreadable roster is a misconfiguration worth
+			// failing on rather than something a later sync might fix.
+			fetched, err := fetchRoster(ctx)
 			if err != nil {
-				return err
+				return fmt.Errorf("failed to load the dev volunteer roster: %w", err)
 			}
 			volunteers.Replace(fetched)
 			logger.Info("Volunteer roster loaded from CSV", zap.Int("count", len(fetched)))
-			return nil
 		}
 
-		// Unlike a Sheets outage this is a local file that either exists or does
-		// not, so an unreadable roster is a misconfiguration worth failing on
-		// rather than something a later sync might fix.
-		if err := syncVolunteers(ctx); err != nil {
-			return fmt.Errorf("failed to load the dev volunteer roster: %w", err)
-		}
-
-		authenticator, err = api.NewStubAuthenticator(cfg.DevMode, cfg.Server, logger, syncVolunteers)
+		authenticator, err = api.NewStubAuthenticator(cfg.DevMode, cfg.Server, logger)
 		if err != nil {
 			return fmt.Errorf("failed to create stub authenticator: %w", err)
 		}
@@ -156,40 +164,48 @@ func run(env string, portOverride int) error {
 		}
 
 		// The volunteer roster is fetched from the sheet with the server's own
-		// service account: once at startup (below) and again on each admin sync. The
-		// admin only triggers the refetch — no token is taken from them.
+		// service account: once at startup (below), again on each admin sync and
+		// on the configured timer, or once, by an import, where the database is
+		// the roster. The admin only triggers the fetch — no token is taken from
+		// them.
 		serviceAccount, err := config.LoadServiceAccountWithEnv(env)
 		if err != nil {
 			return fmt.Errorf("failed to load service account: %w", err)
 		}
 
-		syncVolunteers = func(ctx context.Context) error {
+		fetchRoster = func(ctx context.Context) ([]model.Volunteer, error) {
 			client, err := sheetsclient.NewClientFromServiceAccount(ctx, serviceAccount.JSON)
 			if err != nil {
-				return fmt.Errorf("failed to build sheets client for sync: %w", err)
+				return nil, fmt.Errorf("failed to build sheets client: %w", err)
 			}
 			roles, err := services.RoleTable(ctx, database)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			fetched, err := client.ListVolunteers(cfg, roles)
 			if err != nil {
-				return fmt.Errorf("failed to fetch volunteers for sync: %w", err)
+				return nil, fmt.Errorf("failed to fetch volunteers from the sheet: %w", err)
 			}
-			volunteers.Replace(fetched)
-			logger.Info("Volunteer roster synced", zap.Int("count", len(fetched)))
-			return nil
+			return fetched, nil
 		}
 
 		// Populate the roster at startup so reads work before any admin syncs. A
-		// failure here (transient Sheets outage, say) is not fatal: the server boots
-		// with an empty roster and an admin can retry via the sync button, matching
-		// the store's "degrade to no volunteers" behaviour.
-		if err := syncVolunteers(ctx); err != nil {
-			logger.Warn("Failed to populate volunteer roster at startup; starting empty", zap.Error(err))
+		// failure here (transient Sheets outage, say) is not fatal: the server
+		// boots with an empty roster and an admin can retry via the sync
+		// button, or the timer will, matching the store's "degrade to no
+		// volunteers" behaviour. Not a sync an admin confirmed, so not recorded
+		// as one.
+		if sheetIsRoster {
+			if fetched, err := fetchRoster(ctx); err != nil {
+				volunteers.RecordFailedSync()
+				logger.Warn("Failed to populate volunteer roster at startup; starting empty", zap.Error(err))
+			} else {
+				volunteers.Replace(fetched)
+				logger.Info("Volunteer roster loaded", zap.Int("count", len(fetched)))
+			}
 		}
 
-		authenticator, err = api.NewAuthenticator(ctx, webOAuthCfg, cfg.Server, env, logger, syncVolunteers)
+		authenticator, err = api.NewAuthenticator(ctx, webOAuthCfg, cfg.Server, env, logger)
 		if err != nil {
 			return fmt.Errorf("failed to create authenticator: %w", err)
 		}
@@ -198,11 +214,27 @@ func run(env string, portOverride int) error {
 		// gmail.send token they grant per send and the server never stores.
 		// There is nothing to build here beyond the token itself — the client
 		// is constructed inside the send and discarded with it.
-		newMailer = func(ctx context.Context, token *oauth2.Token) (services.GmailClient, error) {
+		newMailer = func(ctx context.Context, token *oauth2.Token) (services.MailClient, error) {
 			return gmailclient.NewClientFromToken(ctx, token)
 		}
 	}
 
+	// A configured transport other than Gmail replaces whichever mailer was
+	// chosen above, dev mode's log included: the point of an outbox on the dev
+	// stack is to read the emails as files rather than out of the log.
+	if cfg.MailTransport() != config.MailTransportGmail {
+		mailer, err := newTransportMailer(cfg.Mail)
+		if err != nil {
+			return err
+		}
+		logger.Info("Availability emails go out through the configured transport, not Gmail",
+			zap.String("transport", cfg.Mail.Transport),
+			zap.String("from", cfg.Mail.From))
+		newMailer = func(context.Context, *oauth2.Token) (services.MailClient, error) {
+			return mailer, nil
+		}
+	}
+
 	// A server with no Roles starts and serves, but nobody holds a Role, so
 	// nothing can be allocated. That is the state a deployment is in between
 	// this ticket's migration and someone creating its Roles, and it is quiet
@@ -213,7 +245,24 @@ func run(env string, portOverride int) error {
 		logger.Warn("No roles exist — the roster will match none and allocation will refuse to run; create them on the admin settings screen")
 	}
 
-	handler := api.NewHandler(database, volunteers, cfg, authenticator, web.Dist(), newMailer, logger)
+	// Everything that reads the roster reads it through one client, so which
+	// source is authoritative is decided here and nowhere else.
+	var roster services.VolunteerClient = volunteers
+	if !sheetIsRoster {
+		roster = services.NewDatabaseRoster(database)
+		logger.Info("The volunteer roster is kept in the database; the sheet is not read except by an import")
+	}
+
+	handler := api.NewHandler(database, roster, cfg, authenticator, web.Dist(), newMailer, logger)
+	handler.EnableRosterImport(fetchRoster)
+	if sheetIsRoster {
+		handler.EnableRosterSync(fetchRoster, volunteers)
+	}
+	// Stopped by the same signal as the server; a sync it was part-way through
+	// is abandoned with the request context it was reading the sheet on.
+	if interval := cfg.RosterSyncInterval(); interval > 0 {
+		go handler.RunScheduledSyncs(ctx, interval)
+	}
 
 	server := &http.Server{
 		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
@@ -243,3 +292,21 @@ func run(env string, portOverride int) error {
 	logger.Info("Server stopped")
 	return nil
 }
+
+// newTransportMailer builds the one client every send shares under a transport
+// that needs no grant. It holds no per-admin credential, so unlike a Gmail
+// client there is nothing to build per send.
+func newTransportMailer(mail *config.MailConfig) (services.MailClient, error) {
+	switch mail.Transport {
+	case config.MailTransportSMTP:
+		return mailclient.NewSMTPClient(*mail.SMTP, mail.From), nil
+	case config.MailTransportFile:
+		client, err := mailclient.NewOutboxClient(mail.Outbox, mail.From)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", mail.Transport)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"gopkg.in/yaml.v3"
@@ -30,6 +31,28 @@ type ServerConfig struct {
 	// locality. Set it where the default guess is wrong — chiefly a git worktree,
 	// whose frontend runs on its own port (see docs/agents/worktrees.md).
 	RedirectURI string `yaml:"redirectURI,omitempty" validate:"omitempty,uri"`
+	// LegacyCalendarLinksUntil closes the migration window for calendar feeds
+	// addressed by a volunteer's sheet ID rather than a calendar token: from
+	// this date (YYYY-MM-DD, UTC) those URLs 404. Optional: when empty they
+	// keep answering, with every use logged so the operator can see who still
+	// subscribes to one before setting a date.
+	LegacyCalendarLinksUntil string `yaml:"legacyCalendarLinksUntil,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// LegacyCalendarLinksOpen reports whether a calendar feed may still be
+// addressed by volunteer ID at now: always without a server block or a
+// closing date, and up to the start of the closing date otherwise.
+func (c *Config) LegacyCalendarLinksOpen(now time.Time) bool {
+	if c == nil || c.Server == nil || c.Server.LegacyCalendarLinksUntil == "" {
+		return true
+	}
+	until, err := time.Parse("2006-01-02", c.Server.LegacyCalendarLinksUntil)
+	if err != nil {
+		// Validated on load; a config built by hand with a bad date errs on
+		// the side of not breaking anybody's subscription.
+		return true
+	}
+	return now.Before(until)
 }
 
 // DevEnv is the only environment the development stubs may run in. It is
@@ -52,16 +75,113 @@ type DevModeConfig struct {
 	VolunteersCSV string `yaml:"volunteersCSV" validate:"required"`
 }
 
+// The allocator engines a deployment can run. CP-SAT is the default and the
+// reference: the in-process engine exists so a host with no Python — a small
+// site, a CI runner — can still draft and allocate a rota.
+const (
+	AllocatorEngineCpsat = "cpsat"
+	AllocatorEngineGo    = "go"
+)
+
+// AllocatorConfig chooses how a rota is solved. Omit the block for CP-SAT.
+//
+// It is deployment rather than a setting an admin edits (ADR 0006): what it
+// answers is whether this host has the pyallocator venv, which only the
+// operator who built the host knows.
+type AllocatorConfig struct {
+	// Engine is "cpsat" (pyallocator, run as a subprocess) or "go" (the
+	// in-process fallback solver). Empty means cpsat.
+	Engine string `yaml:"engine,omitempty" validate:"omitempty,oneof=cpsat go"`
+}
+
+// The transports availability email can leave by. Gmail is the default: mail
+// goes out from the admin's own account, through a grant they give per send.
+// SMTP is for a deployment whose organisation relays its own mail, and file is
+// for dev — each email written to a directory instead of sent anywhere.
+const (
+	MailTransportGmail = "gmail"
+	MailTransportSMTP  = "smtp"
+	MailTransportFile  = "file"
+)
+
+// How an SMTP relay's connection is secured.
+const (
+	SMTPSecurityStartTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+	SMTPSecurityNone     = "none"
+)
+
+// SMTPConfig is a mail relay. STARTTLS is the default because it is what a
+// submission port (587) speaks; "tls" is for one that expects TLS from the
+// first byte (465), and "none" only for a relay on the same host.
+type SMTPConfig struct {
+	Host string `yaml:"host" validate:"required,hostname|ip"`
+	Port int    `yaml:"port" validate:"required,min=1,max=65535"`
+	// Security is "starttls", "tls" or "none". Empty means starttls.
+	Security string `yaml:"security,omitempty" validate:"omitempty,oneof=starttls tls none"`
+	// Username and Password are optional: a relay that trusts the host needs
+	// neither. Both or neither.
+	Username string `yaml:"username,omitempty" validate:"required_with=Password"`
+	Password string `yaml:"password,omitempty" validate:"required_with=Username"`
+}
+
+// MailConfig chooses how availability emails are sent. Omit the block for
+// Gmail.
+//
+// It is deployment for the same reason the allocator engine is: which relay
+// this host can reach, and with what credentials, is the operator's to know.
+type MailConfig struct {
+	// Transport is "gmail", "smtp" or "file". Empty means gmail.
+	Transport string `yaml:"transport,omitempty" validate:"omitempty,oneof=gmail smtp file"`
+	// From is the address mail is sent as. Gmail sends as the admin and
+	// ignores it; the other transports have nobody else to send as.
+	From string      `yaml:"from,omitempty" validate:"required_if=Transport smtp,required_if=Transport file,omitempty,email"`
+	SMTP *SMTPConfig `yaml:"smtp,omitempty" validate:"required_if=Transport smtp"`
+	// Outbox is the directory the file transport writes to, created if it is
+	// missing. Relative paths resolve from the server's working directory.
+	Outbox string `yaml:"outbox,omitempty" validate:"required_if=Transport file"`
+}
+
+// Where the volunteer roster is read from. The sheet is the default, and was
+// the only choice until the roster could be kept in the database: a server
+// that reads the sheet holds the roster in memory, so one restarted while
+// Sheets is unreachable has nobody on it until a sync succeeds.
+const (
+	RosterSourceSheet    = "sheet"
+	RosterSourceDatabase = "database"
+)
+
+// RosterConfig chooses which roster is authoritative. Omit the block for the
+// sheet.
+//
+// It is deployment rather than a setting an admin edits: switching is a
+// one-way move an operator makes once the roster has been imported (see
+// `POST /api/volunteers/import`), not a toggle to flip back and forth, and
+// nothing reconciles the two copies if it were.
+type RosterConfig struct {
+	// Source is "sheet" or "database". Empty means sheet.
+	Source string `yaml:"source,omitempty" validate:"omitempty,oneof=sheet database"`
+	// SyncInterval re-reads the sheet on a timer, as a Go duration ("6h").
+	// Optional: when empty the sheet is read at startup and whenever an admin
+	// syncs, and a volunteer added to it in between is not on the roster until
+	// one does. Ignored where the database is the roster. No shorter than five
+	// minutes, since every sync is a read against the Sheets API's quota.
+	SyncInterval time.Duration `yaml:"syncInterval,omitempty" validate:"omitempty,min=5m"`
+}
+
 // Config represents the application configuration
 type Config struct {
-	VolunteerSheetID     string         `yaml:"volunteerSheetID" validate:"required"`
-	ServiceVolunteersTab string         `yaml:"serviceVolunteersTab" validate:"required"`
-	RotaSheetID          string         `yaml:"rotaSheetID" validate:"required"`
-	DatabaseURL          string         `yaml:"databaseURL" validate:"required"`
-	GmailUserID          string         `yaml:"gmailUserID" validate:"required"`
-	GmailSender          string         `yaml:"gmailSender,omitempty"`
-	Server               *ServerConfig  `yaml:"server,omitempty"`
-	DevMode              *DevModeConfig `yaml:"devMode,omitempty"`
+	VolunteerSheetID     string           `yaml:"volunteerSheetID" validate:"required"`
+	ServiceVolunteersTab string           `yaml:"serviceVolunteersTab" validate:"required"`
+	RotaSheetID          string           `yaml:"rotaSheetID" validate:"required"`
+	DatabaseURL          string           `yaml:"databaseURL" validate:"required"`
+	GmailUserID          string           `yaml:"gmailUserID" validate:"required"`
+	GmailSender          string           `yaml:"gmailSender,omitempty"`
+	Server               *ServerConfig    `yaml:"server,omitempty"`
+	DevMode              *DevModeConfig   `yaml:"devMode,omitempty"`
+	Allocator            *AllocatorConfig `yaml:"allocator,omitempty"`
+	Mail                 *MailConfig      `yaml:"mail,omitempty"`
+	Roster               *RosterConfig    `yaml:"roster,omitempty"`
 	// shiftStartTime, shiftEndTime and shiftTimezone used to live here, and so
 	// did maxAllocationFrequency, requiresMale and defaultShiftSize. They are
 	// all settings now, edited on the Settings screen (ADR 0006, #128, #129 and
@@ -93,6 +213,51 @@ type Config struct {
 	// otherwise ignored, like any key this build does not know.
 }
 
+// AllocatorEngine is the engine this deployment solves rotas with: the
+// configured one, or CP-SAT when the allocator block is absent.
+func (c *Config) AllocatorEngine() string {
+	if c == nil || c.Allocator == nil || c.Allocator.Engine == "" {
+		return AllocatorEngineCpsat
+	}
+	return c.Allocator.Engine
+}
+
+// MailTransport is how this deployment sends availability email: the
+// configured transport, or Gmail when the mail block is absent.
+func (c *Config) MailTransport() string {
+	if c == nil || c.Mail == nil || c.Mail.Transport == "" {
+		return MailTransportGmail
+	}
+	return c.Mail.Transport
+}
+
+// RosterSource is where this deployment reads the volunteer roster: the
+// configured source, or the sheet when the roster block is absent.
+func (c *Config) RosterSource() string {
+	if c == nil || c.Roster == nil || c.Roster.Source == "" {
+		return RosterSourceSheet
+	}
+	return c.Roster.Source
+}
+
+// RosterSyncInterval is how often the sheet is re-read on a timer, or zero
+// for never: where the database is the roster, or no interval is configured.
+func (c *Config) RosterSyncInterval() time.Duration {
+	if c.RosterSource() != RosterSourceSheet || c.Roster == nil {
+		return 0
+	}
+	return c.Roster.SyncInterval
+}
+
+// SecurityMode is how the relay's connection is secured: the configured
+// value, or STARTTLS when none is given.
+func (s *SMTPConfig) SecurityMode() string {
+	if s.Security == "" {
+		return SMTPSecurityStartTLS
+	}
+	return s.Security
+}
+
 var validate *validator.Validate
 
 func init() {
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -416,6 +417,28 @@ func TestValidate_ServerConfig(t *testing.T) {
 	badAdminEmail.Server = validServer()
 	badAdminEmail.Server.AdminEmails = []string{"not-an-email"}
 	assert.Error(t, Validate(&badAdminEmail))
+
+	badLegacyDate := base
+	badLegacyDate.Server = validServer()
+	badLegacyDate.Server.LegacyCalendarLinksUntil = "31/12/2026"
+	assert.Error(t, Validate(&badLegacyDate))
+}
+
+// Legacy calendar links answer until the operator names a day they stop, and
+// stop at the start of it.
+func TestLegacyCalendarLinksOpen(t *testing.T) {
+	before := time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC)
+	on := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, baseConfig().LegacyCalendarLinksOpen(on), "no server block")
+
+	cfg := baseConfig()
+	cfg.Server = &ServerConfig{}
+	assert.True(t, cfg.LegacyCalendarLinksOpen(on), "no closing date")
+
+	cfg.Server.LegacyCalendarLinksUntil = "2027-01-01"
+	assert.True(t, cfg.LegacyCalendarLinksOpen(before))
+	assert.False(t, cfg.LegacyCalendarLinksOpen(on))
 }
 
 func TestValidate_DevMode(t *testing.T) {
@@ -454,6 +477,147 @@ func TestValidate_DevMode(t *testing.T) {
 	assert.Error(t, Validate(&missingCSV))
 }
 
+func TestValidate_Allocator(t *testing.T) {
+	for _, engine := range []string{"", AllocatorEngineCpsat, AllocatorEngineGo} {
+		cfg := baseConfig()
+		cfg.Allocator = &AllocatorConfig{Engine: engine}
+		assert.NoError(t, Validate(cfg), "engine %q", engine)
+	}
+
+	cfg := baseConfig()
+	cfg.Allocator = &AllocatorConfig{Engine: "ortools"}
+	assert.Error(t, Validate(cfg))
+}
+
+// A deployment that says nothing about the allocator solves with CP-SAT, as
+// every deployment did before there was a choice.
+func TestAllocatorEngine_DefaultsToCpsat(t *testing.T) {
+	assert.Equal(t, AllocatorEngineCpsat, baseConfig().AllocatorEngine())
+
+	cfg := baseConfig()
+	cfg.Allocator = &AllocatorConfig{}
+	assert.Equal(t, AllocatorEngineCpsat, cfg.AllocatorEngine())
+
+	cfg.Allocator.Engine = AllocatorEngineGo
+	assert.Equal(t, AllocatorEngineGo, cfg.AllocatorEngine())
+}
+
+func TestValidate_Roster(t *testing.T) {
+	for _, source := range []string{"", RosterSourceSheet, RosterSourceDatabase} {
+		cfg := baseConfig()
+		cfg.Roster = &RosterConfig{Source: source}
+		assert.NoError(t, Validate(cfg), "source %q", source)
+	}
+
+	cfg := baseConfig()
+	cfg.Roster = &RosterConfig{Source: "csv"}
+	assert.Error(t, Validate(cfg))
+
+	cfg = baseConfig()
+	cfg.Roster = &RosterConfig{SyncInterval: 6 * time.Hour}
+	assert.NoError(t, Validate(cfg))
+
+	cfg.Roster = &RosterConfig{SyncInterval: time.Minute}
+	assert.Error(t, Validate(cfg), "a sync a minute would spend the Sheets quota")
+}
+
+// A timed sync is off unless configured, and only ever re-reads a sheet that is
+// the roster.
+func TestRosterSyncInterval(t *testing.T) {
+	assert.Zero(t, baseConfig().RosterSyncInterval())
+
+	cfg := baseConfig()
+	cfg.Roster = &RosterConfig{SyncInterval: 6 * time.Hour}
+	assert.Equal(t, 6*time.Hour, cfg.RosterSyncInterval())
+
+	cfg.Roster.Source = RosterSourceDatabase
+	assert.Zero(t, cfg.RosterSyncInterval())
+}
+
+func TestLoadFromPath_RosterSyncInterval(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(minimalConfigYAML+"roster:\n  syncInterval: \"6h\"\n"), 0644))
+
+	cfg, err := LoadFromPath(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, cfg.RosterSyncInterval())
+}
+
+// A deployment that says nothing about the roster reads the sheet, as every
+// deployment did before there was a choice.
+func TestRosterSource_DefaultsToSheet(t *testing.T) {
+	assert.Equal(t, RosterSourceSheet, baseConfig().RosterSource())
+
+	cfg := baseConfig()
+	cfg.Roster = &RosterConfig{}
+	assert.Equal(t, RosterSourceSheet, cfg.RosterSource())
+
+	cfg.Roster.Source = RosterSourceDatabase
+	assert.Equal(t, RosterSourceDatabase, cfg.RosterSource())
+}
+
+func TestValidate_Mail(t *testing.T) {
+	relay := func() *SMTPConfig {
+		return &SMTPConfig{Host: "smtp.example.org", Port: 587, Username: "dropin", Password: "secret"}
+	}
+	tests := []struct {
+		name    string
+		mail    *MailConfig
+		wantErr bool
+	}{
+		{name: "absent", mail: nil},
+		{name: "gmail needs nothing else", mail: &MailConfig{Transport: MailTransportGmail}},
+		{name: "smtp", mail: &MailConfig{Transport: MailTransportSMTP, From: "rota@example.org", SMTP: relay()}},
+		{
+			name: "smtp with no credentials",
+			mail: &MailConfig{Transport: MailTransportSMTP, From: "rota@example.org", SMTP: &SMTPConfig{Host: "localhost", Port: 25, Security: SMTPSecurityNone}},
+		},
+		{name: "file", mail: &MailConfig{Transport: MailTransportFile, From: "rota@example.org", Outbox: "outbox"}},
+		{name: "unknown transport", mail: &MailConfig{Transport: "carrier-pigeon"}, wantErr: true},
+		{name: "smtp without a relay", mail: &MailConfig{Transport: MailTransportSMTP, From: "rota@example.org"}, wantErr: true},
+		{name: "smtp without a from", mail: &MailConfig{Transport: MailTransportSMTP, SMTP: relay()}, wantErr: true},
+		{name: "from that is not an address", mail: &MailConfig{Transport: MailTransportFile, From: "rota", Outbox: "outbox"}, wantErr: true},
+		{name: "file without an outbox", mail: &MailConfig{Transport: MailTransportFile, From: "rota@example.org"}, wantErr: true},
+		{
+			name:    "a username with no password",
+			mail:    &MailConfig{Transport: MailTransportSMTP, From: "rota@example.org", SMTP: &SMTPConfig{Host: "smtp.example.org", Port: 587, Username: "dropin"}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown security",
+			mail:    &MailConfig{Transport: MailTransportSMTP, From: "rota@example.org", SMTP: &SMTPConfig{Host: "smtp.example.org", Port: 587, Security: "ssl"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := baseConfig()
+			cfg.Mail = tt.mail
+			if tt.wantErr {
+				assert.Error(t, Validate(cfg))
+			} else {
+				assert.NoError(t, Validate(cfg))
+			}
+		})
+	}
+}
+
+// A deployment that says nothing about mail sends through Gmail, as every
+// deployment did before there was a choice.
+func TestMailTransport_DefaultsToGmail(t *testing.T) {
+	assert.Equal(t, MailTransportGmail, baseConfig().MailTransport())
+
+	cfg := baseConfig()
+	cfg.Mail = &MailConfig{}
+	assert.Equal(t, MailTransportGmail, cfg.MailTransport())
+
+	cfg.Mail.Transport = MailTransportSMTP
+	assert.Equal(t, MailTransportSMTP, cfg.MailTransport())
+
+	assert.Equal(t, SMTPSecurityStartTLS, (&SMTPConfig{}).SecurityMode())
+}
+
 // The dev stubs replace Google with a roster file and a session minted for a
 // configured address — catastrophic in prod, where anyone could then log in as
 // an admin. The env name is the gate: only "dev" may carry a devMode block.
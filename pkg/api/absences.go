@@ -0,0 +1,144 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// createAbsenceRequest is one stretch of days a volunteer is away, both ends
+// "YYYY-MM-DD" and inclusive. volunteerId is the admin's choice of volunteer;
+// on a volunteer's own link it is not accepted at all, since the link already
+// says whose it is.
+type createAbsenceRequest struct {
+	VolunteerID string `json:"volunteerId"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Note        string `json:"note,omitempty"`
+}
+
+// createOwnAbsenceRequest is createAbsenceRequest as a volunteer sends it.
+type createOwnAbsenceRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Note string `json:"note,omitempty"`
+}
+
+// absenceResponse carries the volunteer by id and by name, as a Pairing Rule
+// does. createdBy is absent when the volunteer recorded it themselves.
+type absenceResponse struct {
+	ID          string `json:"id"`
+	VolunteerID string `json:"volunteerId"`
+	Name        string `json:"name,omitempty"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Note        string `json:"note,omitempty"`
+	CreatedBy   string `json:"createdBy,omitempty"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type listAbsencesResponse struct {
+	Absences []absenceResponse `json:"absences"`
+}
+
+// handleListAbsences returns every Absence under way or still to come,
+// soonest first.
+func (h *Handler) handleListAbsences(w http.ResponseWriter, r *http.Request) {
+	views, err := services.ListAbsences(r.Context(), h.store, h.volunteers, h.cfg, time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, listAbsencesResponse{Absences: toAbsenceResponses(views)})
+}
+
+// handleCreateAbsence records one for a volunteer. Validation lives in the
+// service; rejections map to 400/404 via writeServiceError.
+func (h *Handler) handleCreateAbsence(w http.ResponseWriter, r *http.Request) {
+	var req createAbsenceRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	view, err := services.AddAbsence(r.Context(), h.store, h.volunteers, h.cfg, services.AddAbsenceParams{
+		VolunteerID: req.VolunteerID,
+		From:        req.From,
+		To:          req.To,
+		Note:        req.Note,
+	}, adminEmail(r.Context()), time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, toAbsenceResponse(*view))
+}
+
+// handleDeleteAbsence removes one by id. 204 on success, 404 when it has
+// already gone.
+func (h *Handler) handleDeleteAbsence(w http.ResponseWriter, r *http.Request) {
+	if err := services.DeleteAbsence(r.Context(), h.store, r.PathValue("id"), h.logger); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCreateOwnAbsence records one for the volunteer a link belongs to and
+// returns their form as it now stands, so the page redraws from one response.
+func (h *Handler) handleCreateOwnAbsence(w http.ResponseWriter, r *http.Request) {
+	var req createOwnAbsenceRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	form, err := services.AddOwnAbsence(r.Context(), h.store, h.volunteers, h.cfg, h.logger, r.PathValue("token"), services.AddAbsenceParams{
+		From: req.From,
+		To:   req.To,
+		Note: req.Note,
+	}, time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, toFormResponse(form))
+}
+
+// handleDeleteOwnAbsence removes one of the link holder's own Absences and
+// returns their form. Anybody else's is a 404, as a missing one is.
+func (h *Handler) handleDeleteOwnAbsence(w http.ResponseWriter, r *http.Request) {
+	form, err := services.DeleteOwnAbsence(r.Context(), h.store, h.volunteers, h.cfg, h.logger, r.PathValue("token"), r.PathValue("id"))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, toFormResponse(form))
+}
+
+func toAbsenceResponses(views []services.AbsenceView) []absenceResponse {
+	out := make([]absenceResponse, 0, len(views))
+	for _, v := range views {
+		out = append(out, toAbsenceResponse(v))
+	}
+	return out
+}
+
+func toAbsenceResponse(v services.AbsenceView) absenceResponse {
+	return absenceResponse{
+		ID:          v.ID,
+		VolunteerID: v.VolunteerID,
+		Name:        v.Name,
+		From:        v.From,
+		To:          v.To,
+		Note:        v.Note,
+		CreatedBy:   v.CreatedBy,
+		CreatedAt:   v.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
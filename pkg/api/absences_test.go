@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The Absence methods of mockStore, over a slice.
+func (m *mockStore) GetAbsencesFrom(_ context.Context, date string) ([]db.Absence, error) {
+	var out []db.Absence
+	for _, a := range m.absences {
+		if a.To >= date {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) GetAbsenceByID(_ context.Context, id string) (*db.Absence, error) {
+	for i := range m.absences {
+		if m.absences[i].ID == id {
+			return &m.absences[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockStore) InsertAbsence(_ context.Context, absence db.Absence) (db.Absence, error) {
+	absence.CreatedAt = time.Now()
+	m.absences = append(m.absences, absence)
+	return absence, nil
+}
+
+func (m *mockStore) DeleteAbsenceByID(_ context.Context, id string) (bool, error) {
+	for i := range m.absences {
+		if m.absences[i].ID == id {
+			m.absences = append(m.absences[:i], m.absences[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// absenceJSON is the wire shape of one Absence.
+type absenceJSON struct {
+	ID          string `json:"id"`
+	VolunteerID string `json:"volunteerId"`
+	Name        string `json:"name"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Note        string `json:"note"`
+	CreatedBy   string `json:"createdBy"`
+}
+
+func TestAbsenceEndpoints(t *testing.T) {
+	store := &mockStore{}
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/absences",
+		`{"volunteerId":"bob","from":"2030-12-01","to":"2030-12-31","note":"abroad"}`, adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created absenceJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "bob", created.VolunteerID)
+	assert.NotEmpty(t, created.Name)
+	assert.Equal(t, "2030-12-01", created.From)
+	assert.NotEmpty(t, created.CreatedBy, "an admin's absence says which admin")
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/absences", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code)
+	var listed struct {
+		Absences []absenceJSON `json:"absences"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed.Absences, 1)
+	assert.Equal(t, "abroad", listed.Absences[0].Note)
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/absences/"+created.ID, "", adminCookie())
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doRequest(t, handler, http.MethodDelete, "/api/absences/"+created.ID, "", adminCookie())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAbsenceEndpoints_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+	}{
+		{name: "malformed json", body: `{`, wantCode: http.StatusBadRequest},
+		{name: "unknown field", body: `{"volunteerId":"bob","from":"2030-12-01","to":"2030-12-31","days":30}`, wantCode: http.StatusBadRequest},
+		{name: "not a date", body: `{"volunteerId":"bob","from":"December","to":"2030-12-31"}`, wantCode: http.StatusBadRequest},
+		{name: "back to front", body: `{"volunteerId":"bob","from":"2030-12-31","to":"2030-12-01"}`, wantCode: http.StatusBadRequest},
+		{name: "unknown volunteer", body: `{"volunteerId":"ghost","from":"2030-12-01","to":"2030-12-31"}`, wantCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockStore{}
+			rec := doRequest(t, newTestHandler(store, testVolunteers()), http.MethodPost, "/api/absences", tt.body, adminCookie())
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Empty(t, store.absences)
+		})
+	}
+}
+
+// Who is away when is the admins' business, so the list is not public.
+func TestAbsenceEndpointsRequireAdmin(t *testing.T) {
+	handler := newTestHandler(&mockStore{}, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/absences", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doRequest(t, handler, http.MethodPost, "/api/absences", `{"volunteerId":"bob","from":"2030-12-01","to":"2030-12-31"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// A volunteer records their own days away through their link, and the form
+// that comes back shows them and leaves the shift they cover unticked.
+func TestOwnAbsenceEndpoints(t *testing.T) {
+	store := &mockStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: "2030-12-01", End: "2030-12-08", ShiftCount: 2}},
+		shifts: []db.Shift{
+			{ID: "shift-1", RotaID: "rota-1", Date: "2030-12-01"},
+			{ID: "shift-2", RotaID: "rota-1", Date: "2030-12-08"},
+		},
+		availabilityRequests: []db.AvailabilityRequest{
+			{ID: "req-1", RotaID: "rota-1", VolunteerID: "bob", Token: "bob-token"},
+		},
+	}
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/availability/bob-token/absences",
+		`{"from":"2030-12-05","to":"2030-12-20"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var form struct {
+		SelectedShiftIDs []string      `json:"selectedShiftIds"`
+		AbsentShiftIDs   []string      `json:"absentShiftIds"`
+		Absences         []absenceJSON `json:"absences"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &form))
+	assert.Equal(t, []string{"shift-1"}, form.SelectedShiftIDs)
+	assert.Equal(t, []string{"shift-2"}, form.AbsentShiftIDs)
+	require.Len(t, form.Absences, 1)
+	assert.Empty(t, form.Absences[0].CreatedBy, "the volunteer's own absence names no admin")
+
+	// Naming the volunteer is refused rather than ignored: the link says who.
+	rec = doRequest(t, handler, http.MethodPost, "/api/availability/bob-token/absences",
+		`{"volunteerId":"alice","from":"2030-12-05","to":"2030-12-20"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/availability/bob-token/absences/"+form.Absences[0].ID, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, store.absences)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+type roleShiftsResponse struct {
+	Role   string `json:"role"`
+	Shifts int    `json:"shifts"`
+}
+
+// volunteerActivityResponse is one row of the activity report. roles is
+// always a list, never null; lastShift is absent for somebody with no shift
+// in the range.
+type volunteerActivityResponse struct {
+	VolunteerID     string               `json:"volunteerId"`
+	VolunteerName   string               `json:"volunteerName"`
+	Active          bool                 `json:"active"`
+	ShiftsAllocated int                  `json:"shiftsAllocated"`
+	AlterationsIn   int                  `json:"alterationsIn"`
+	AlterationsOut  int                  `json:"alterationsOut"`
+	ShiftsWorked    int                  `json:"shiftsWorked"`
+	Roles           []roleShiftsResponse `json:"roles"`
+	RoundsAsked     int                  `json:"roundsAsked"`
+	Responses       int                  `json:"responses"`
+	LastShift       string               `json:"lastShift,omitempty"`
+}
+
+type activityReportResponse struct {
+	From       string                      `json:"from"`
+	To         string                      `json:"to"`
+	Rotas      int                         `json:"rotas"`
+	Volunteers []volunteerActivityResponse `json:"volunteers"`
+}
+
+// handleActivityReport serves every volunteer's activity between ?from and
+// ?to, as JSON or — with ?format=csv — as a file to open in a spreadsheet.
+// The CSV is an attachment named for its range, so a coordinator who saves a
+// few can tell them apart.
+func (h *Handler) handleActivityReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "csv" {
+		h.writeError(w, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+
+	report, err := services.BuildActivityReport(r.Context(), h.store, h.volunteers, h.cfg, services.ActivityReportParams{
+		From: query.Get("from"),
+		To:   query.Get("to"),
+	}, h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="activity-`+report.From+`-to-`+report.To+`.csv"`)
+		if err := services.WriteActivityReportCSV(w, report); err != nil {
+			h.logger.Error("Failed to write activity report", zap.Error(err))
+		}
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toActivityReportResponse(report))
+}
+
+func toActivityReportResponse(report *services.ActivityReport) activityReportResponse {
+	resp := activityReportResponse{
+		From:       report.From,
+		To:         report.To,
+		Rotas:      report.Rotas,
+		Volunteers: make([]volunteerActivityResponse, 0, len(report.Volunteers)),
+	}
+	for _, v := range report.Volunteers {
+		row := volunteerActivityResponse{
+			VolunteerID:     v.VolunteerID,
+			VolunteerName:   v.VolunteerName,
+			Active:          v.Active,
+			ShiftsAllocated: v.ShiftsAllocated,
+			AlterationsIn:   v.AlterationsIn,
+			AlterationsOut:  v.AlterationsOut,
+			ShiftsWorked:    v.ShiftsWorked,
+			Roles:           make([]roleShiftsResponse, 0, len(v.Roles)),
+			RoundsAsked:     v.RoundsAsked,
+			Responses:       v.Responses,
+			LastShift:       v.LastShift,
+		}
+		for _, role := range v.Roles {
+			row.Roles = append(row.Roles, roleShiftsResponse{Role: role.Role, Shifts: role.Shifts})
+		}
+		resp.Volunteers = append(resp.Volunteers, row)
+	}
+	return resp
+}
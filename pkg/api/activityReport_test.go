@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// activityTestStore is one allocated rota of one shift, which Alice led with
+// Bob beside her. Charlie was on nothing.
+func activityTestStore() *mockStore {
+	return &mockStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: "2026-03-01", End: "2026-03-01", ShiftCount: 1, AllocatedDatetime: "2026-02-20T09:00:00Z"}},
+		shifts:    []db.Shift{{ID: "shift-1", RotaID: "rota-1", Date: "2026-03-01"}},
+		allocations: []db.Allocation{
+			{ID: "a1", ShiftID: "shift-1", VolunteerID: "alice", Role: "Team lead"},
+			{ID: "a2", ShiftID: "shift-1", VolunteerID: "bob", Role: "Service volunteer"},
+		},
+	}
+}
+
+func TestActivityReportEndpoint(t *testing.T) {
+	handler := newTestHandler(activityTestStore(), testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/reports/activity?from=2026-01-01&to=2026-03-31", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var report activityReportResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Rotas)
+	require.Len(t, report.Volunteers, 3, "Charlie is listed at nought")
+	alice := report.Volunteers[0]
+	assert.Equal(t, "Alice Adams", alice.VolunteerName)
+	assert.Equal(t, 1, alice.ShiftsWorked)
+	assert.Equal(t, []roleShiftsResponse{{Role: "Team lead", Shifts: 1}}, alice.Roles)
+	assert.Equal(t, "2026-03-01", alice.LastShift)
+	assert.NotNil(t, report.Volunteers[2].Roles, "no Roles is an empty list, not null")
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/reports/activity?from=2026-01-01&to=2026-03-31&format=csv", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "activity-2026-01-01-to-2026-03-31.csv")
+	rows, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, "bob", rows[2][0])
+}
+
+func TestActivityReportRefusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		admin    bool
+		wantCode int
+	}{
+		{name: "not an admin", target: "/api/reports/activity?from=2026-01-01&to=2026-03-31", wantCode: http.StatusUnauthorized},
+		{name: "no range", target: "/api/reports/activity", admin: true, wantCode: http.StatusBadRequest},
+		{name: "backwards", target: "/api/reports/activity?from=2026-03-31&to=2026-01-01", admin: true, wantCode: http.StatusBadRequest},
+		{name: "unknown format", target: "/api/reports/activity?from=2026-01-01&to=2026-03-31&format=xml", admin: true, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tt.admin {
+				cookies = append(cookies, adminCookie())
+			}
+			rec := doRequest(t, newTestHandler(activityTestStore(), testVolunteers()), http.MethodGet, tt.target, "", cookies...)
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+		})
+	}
+}
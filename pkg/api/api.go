@@ -17,15 +17,21 @@ import (
 
 // Store defines the database operations the API needs (satisfied by *db.DB)
 type Store interface {
+	services.AbsenceStore
 	services.AllocateRotaStore
-	services.AvailabilityStore
+	services.AvailabilitySendStore
 	services.ChangeRotaStore
 	services.DefaultShapeWriteStore
 	services.DefineRotaStore
 	services.DraftRotaAllocationStore
 	services.ListShiftsStore
+	services.PairingRuleStore
 	services.PreallocationStore
+	services.PublishRotaStore
+	services.SignInSheetStore
 	services.RoleWriteStore
+	services.RosterStore
+	services.RosterSyncStore
 	services.RotaDefaultsStore
 	services.RotaLifecycleStore
 	services.RotaProposalStore
@@ -33,6 +39,10 @@ type Store interface {
 	services.ShiftShapeWriteStore
 	services.UpdateShiftStore
 	services.StandingPreallocationStore
+	services.SwapStore
+	services.CoverRequestStore
+	services.AttendanceStore
+	services.ActivityReportStore
 	// Ping reports whether the database is reachable, for GET /health.
 	Ping(ctx context.Context) error
 }
@@ -43,8 +53,9 @@ type Store interface {
 // client that writes emails to the log instead of sending them.
 //
 // The token is short-lived and carries no refresh token: it is used for one send
-// and discarded. It is nil in dev mode, where nothing granted anything.
-type MailerFunc func(ctx context.Context, token *oauth2.Token) (services.GmailClient, error)
+// and discarded. It is nil in dev mode, where nothing granted anything, and
+// under any transport but Gmail, where nothing needs to.
+type MailerFunc func(ctx context.Context, token *oauth2.Token) (services.MailClient, error)
 
 // Handler serves the HTTP API
 type Handler struct {
@@ -58,9 +69,12 @@ type Handler struct {
 	// substitutes one that refuses, so a server wired without mail says so on
 	// the first send rather than panicking.
 	newMailer MailerFunc
-	// sends holds the availability sends in flight. They are jobs rather than
-	// requests because a round takes about ninety seconds — see sendjobs.go.
-	sends *sendJobs
+	// fetchRoster reads the roster a database-kept one is imported from. Nil
+	// until EnableRosterImport, which leaves the import unavailable.
+	fetchRoster RosterFetchFunc
+	// syncs is the sheet sync's preview slot. Nil until EnableRosterSync,
+	// which leaves syncing unavailable.
+	syncs *rosterSyncs
 	// drafts is the one solve slot draft solves take turns in, so that two
 	// admins reading the rota at once do not start two solvers over the same
 	// inputs — see draftsolves.go.
@@ -74,7 +88,7 @@ type Handler struct {
 // it.
 func NewHandler(store Store, volunteers services.VolunteerClient, cfg *config.Config, auth *Authenticator, frontend fs.FS, newMailer MailerFunc, logger *zap.Logger) *Handler {
 	if newMailer == nil {
-		newMailer = func(context.Context, *oauth2.Token) (services.GmailClient, error) {
+		newMailer = func(context.Context, *oauth2.Token) (services.MailClient, error) {
 			return nil, errors.New("this server is not configured to send mail")
 		}
 	}
@@ -87,7 +101,6 @@ func NewHandler(store Store, volunteers services.VolunteerClient, cfg *config.Co
 		frontend:   frontend,
 		logger:     logger,
 		newMailer:  newMailer,
-		sends:      newSendJobs(),
 		drafts:     newDraftSolves(),
 	}
 
@@ -112,7 +125,7 @@ const apiPrefix = "/api"
 //
 // Three paths deliberately stay unprefixed: /health, which the deploy tooling
 // and scripts/dev-stack.sh poll; /auth, a browser redirect flow whose callback
-// URI is registered with Google; and /calendars/{filename}, whose URLs are
+// URI is registered with Google; and /calendars, whose URLs are
 // subscribed to from volunteers' calendar apps and so cannot be moved without
 // breaking subscriptions that live outside this app.
 func (h *Handler) Routes() http.Handler {
@@ -127,6 +140,15 @@ func (h *Handler) Routes() http.Handler {
 	// without "an absent field means unchanged" and "an absent Role means gone"
 	// meaning opposite things in one body.
 	api.Handle("PUT /shifts/{id}/shape", h.auth.requireAdmin(http.HandlerFunc(h.handleSaveShiftShape)))
+	// Who turned up to a shift that has happened, one volunteer at a time so
+	// an admin and the Team lead marking the same night do not undo each
+	// other. DELETE forgets an answer given against the wrong person.
+	api.Handle("GET /shifts/{id}/attendance", h.auth.requireAdmin(http.HandlerFunc(h.handleGetShiftAttendance)))
+	api.Handle("PUT /shifts/{id}/attendance/{volunteerId}", h.auth.requireAdmin(http.HandlerFunc(h.handleRecordAttendance)))
+	api.Handle("DELETE /shifts/{id}/attendance/{volunteerId}", h.auth.requireAdmin(http.HandlerFunc(h.handleClearAttendance)))
+	// The sheet people sign in on at the door, as a PDF to print. Admin-only
+	// like the attendance it is the paper half of.
+	api.Handle("GET /shifts/{id}/sign-in-sheet", h.auth.requireAdmin(http.HandlerFunc(h.handleSignInSheet)))
 	// Public alongside the rota: it is what tells a client which Roles exist
 	// and what each is drawn in, and the rota names Roles on every chip. The
 	// writes beside it are admin-only — which Roles exist is a decision about
@@ -150,6 +172,13 @@ func (h *Handler) Routes() http.Handler {
 	api.Handle("PUT /rota-defaults/shift-times", h.auth.requireAdmin(http.HandlerFunc(h.handleSaveShiftTimeDefaults)))
 	api.Handle("PUT /rota-defaults/shape", h.auth.requireAdmin(http.HandlerFunc(h.handleSaveDefaultShape)))
 	api.Handle("PUT /rota-defaults/allocation-settings", h.auth.requireAdmin(http.HandlerFunc(h.handleSaveAllocationSettings)))
+	api.Handle("PUT /rota-defaults/swap-settings", h.auth.requireAdmin(http.HandlerFunc(h.handleSaveSwapSettings)))
+	// The wording of the emails, one email at a time: two admins rewording two
+	// emails must not undo each other. DELETE puts an email back to its
+	// default, and a preview renders wording that need not be saved yet.
+	api.Handle("PUT /rota-defaults/email-templates/{kind}", h.auth.requireAdmin(http.HandlerFunc(h.handleSaveEmailTemplate)))
+	api.Handle("DELETE /rota-defaults/email-templates/{kind}", h.auth.requireAdmin(http.HandlerFunc(h.handleResetEmailTemplate)))
+	api.Handle("POST /rota-defaults/email-templates/{kind}/preview", h.auth.requireAdmin(http.HandlerFunc(h.handlePreviewEmailTemplate)))
 	// The rota's own lifecycle. One rota is in flight at a time, so the read is
 	// a singleton at a fixed path rather than a listing: there is nothing to
 	// pick between, which is the whole point of the rule (issue #139). It does
@@ -162,6 +191,11 @@ func (h *Handler) Routes() http.Handler {
 	// screen read one of these each (issue #140).
 	api.Handle("GET /rotations/proposed", h.auth.requireAdmin(http.HandlerFunc(h.handleGetRotaProposal)))
 	api.Handle("DELETE /rotations/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleDiscardRota)))
+	// Any rota as a file, laid out as the published sheet is, for those who
+	// work without the Google Sheet. CSV, JSON or XLSX with ?format.
+	api.Handle("GET /rotations/{id}/export", h.auth.requireAdmin(http.HandlerFunc(h.handleExportRota)))
+	// The same rota as a PDF to pin up, each Role in its colour.
+	api.Handle("GET /rotations/{id}/print", h.auth.requireAdmin(http.HandlerFunc(h.handlePrintRota)))
 	// Allocating: the act that turns the rota in flight into the rota, and the
 	// end of its lifecycle. Under the rota rather than under the draft beside
 	// it, because what it changes is the Rotation — the draft is what it
@@ -189,6 +223,25 @@ func (h *Handler) Routes() http.Handler {
 	api.Handle("GET /draft-rota-allocation", h.auth.requireAdmin(http.HandlerFunc(h.handleGetDraftRotaAllocation)))
 	api.Handle("POST /draft-rota-allocation", h.auth.requireAdmin(http.HandlerFunc(h.handleSolveDraftRotaAllocation)))
 	api.Handle("POST /alterations", h.auth.requireAdmin(http.HandlerFunc(h.handleCreateAlteration)))
+	// Who was told about a change. Telling them is a send like any other,
+	// started at /auth/gmail?mode=cover; this reads back the ones that were.
+	api.Handle("GET /covers/{id}/notifications", h.auth.requireAdmin(http.HandlerFunc(h.handleListCoverNotifications)))
+	// Swaps the volunteers have arranged between themselves, for an admin to
+	// approve or decline when approval is switched on. Approving one is a rota
+	// change made in the admin's name, so it answers as POST /alterations does.
+	api.Handle("GET /swap-requests", h.auth.requireAdmin(http.HandlerFunc(h.handleListSwapRequests)))
+	api.Handle("POST /swap-requests/{id}/approval", h.auth.requireAdmin(http.HandlerFunc(h.handleApproveSwap)))
+	api.Handle("POST /swap-requests/{id}/refusal", h.auth.requireAdmin(http.HandlerFunc(h.handleDeclineSwap)))
+	// Cover asked for on an allocated rota: the links minted for everyone who
+	// could do it, and their answers. Accepting an answer is a rota change
+	// made in the admin's name, so it too answers as POST /alterations does.
+	api.Handle("GET /cover-requests", h.auth.requireAdmin(http.HandlerFunc(h.handleListCoverRequests)))
+	api.Handle("POST /cover-requests", h.auth.requireAdmin(http.HandlerFunc(h.handleCreateCoverRequest)))
+	api.Handle("DELETE /cover-requests/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleCancelCoverRequest)))
+	api.Handle("POST /cover-requests/{id}/answers/{volunteerId}/acceptance", h.auth.requireAdmin(http.HandlerFunc(h.handleAcceptCoverAnswer)))
+	// Who has been doing what across a date range, for spotting volunteers who
+	// have lapsed and those doing too much. JSON, or CSV with ?format=csv.
+	api.Handle("GET /reports/activity", h.auth.requireAdmin(http.HandlerFunc(h.handleActivityReport)))
 	// Reading pins is admin-only alongside writing them: a listing names people
 	// against dates whose rota has not been allocated, let alone published, and
 	// nothing outside the admin UI has any use for it.
@@ -204,6 +257,42 @@ func (h *Handler) Routes() http.Handler {
 	api.Handle("POST /preallocations", h.auth.requireAdmin(http.HandlerFunc(h.handleCreatePreallocation)))
 	api.Handle("DELETE /preallocations/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleDeletePreallocation)))
 	api.Handle("GET /volunteers", h.auth.requireAdmin(http.HandlerFunc(h.handleListVolunteers)))
+	// Editing the roster, for a deployment that keeps it in the database
+	// (config roster.source). Where the sheet is the roster these refuse with
+	// a 409: it is edited in the sheet. The import is the one-shot copy of the
+	// sheet into an empty roster, and works either way, so the copy can be
+	// checked before the switch.
+	api.Handle("POST /volunteers", h.auth.requireAdmin(http.HandlerFunc(h.handleCreateVolunteer)))
+	api.Handle("POST /volunteers/import", h.auth.requireAdmin(http.HandlerFunc(h.handleImportRoster)))
+	// Syncing from the sheet: a preview of what would change, then a confirm
+	// of that preview, and the record of the syncs confirmed.
+	api.Handle("POST /volunteers/sync/preview", h.auth.requireAdmin(http.HandlerFunc(h.handlePreviewSync)))
+	api.Handle("POST /volunteers/sync", h.auth.requireAdmin(http.HandlerFunc(h.handleApplySync)))
+	api.Handle("GET /volunteers/syncs", h.auth.requireAdmin(http.HandlerFunc(h.handleListSyncs)))
+	api.Handle("PUT /volunteers/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleUpdateVolunteer)))
+	api.Handle("DELETE /volunteers/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleDeleteVolunteer)))
+	// Pairing Rules beside the roster they are about. Admin-only, and more so
+	// than most: an "apart" rule is usually a safeguarding decision, and the
+	// rule alone says more than either volunteer should find on a screen. No
+	// PUT, as with Standing Preallocations — a pair has one rule, and changing
+	// it is removing it and making the one that was meant.
+	api.Handle("GET /pairing-rules", h.auth.requireAdmin(http.HandlerFunc(h.handleListPairingRules)))
+	api.Handle("POST /pairing-rules", h.auth.requireAdmin(http.HandlerFunc(h.handleCreatePairingRule)))
+	api.Handle("DELETE /pairing-rules/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleDeletePairingRule)))
+	// Absences, the days a volunteer has said they are away. No PUT, for the
+	// reason there is none for a Pairing Rule: changing one is removing it and
+	// making the one that was meant.
+	api.Handle("GET /absences", h.auth.requireAdmin(http.HandlerFunc(h.handleListAbsences)))
+	api.Handle("POST /absences", h.auth.requireAdmin(http.HandlerFunc(h.handleCreateAbsence)))
+	api.Handle("DELETE /absences/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleDeleteAbsence)))
+	// Calendar tokens, the secret each volunteer's feed is addressed by. Minting
+	// one for a volunteer who has one replaces it, which is how a link that has
+	// got out is revoked while leaving them a working one; the POST without a
+	// volunteer mints for everybody who has none and touches nobody who does.
+	api.Handle("GET /calendar-tokens", h.auth.requireAdmin(http.HandlerFunc(h.handleListCalendarTokens)))
+	api.Handle("POST /calendar-tokens", h.auth.requireAdmin(http.HandlerFunc(h.handleMintMissingCalendarTokens)))
+	api.Handle("POST /calendar-tokens/{volunteerId}", h.auth.requireAdmin(http.HandlerFunc(h.handleMintCalendarToken)))
+	api.Handle("DELETE /calendar-tokens/{volunteerId}", h.auth.requireAdmin(http.HandlerFunc(h.handleRevokeCalendarToken)))
 	// Rounds are admin-only: the roster hands out every volunteer's link, which
 	// is a bearer credential for their availability.
 	api.Handle("POST /availability-rounds", h.auth.requireAdmin(http.HandlerFunc(h.handleMintAvailabilityRound)))
@@ -211,17 +300,45 @@ func (h *Handler) Routes() http.Handler {
 	// A send is watched, never started, from under /api: starting one is a
 	// browser redirect to Google for the gmail.send grant, which lives at
 	// /auth/gmail alongside the rest of the OAuth flow.
+	api.Handle("GET /availability-sends", h.auth.requireAdmin(http.HandlerFunc(h.handleListSends)))
 	api.Handle("GET /availability-sends/{id}", h.auth.requireAdmin(http.HandlerFunc(h.handleGetSend)))
 	// The volunteer's own link, public by design — the link is the identity and
 	// volunteers never log in. Registered under a separate prefix from the
 	// admin rounds above so neither path can shadow the other.
 	api.HandleFunc("GET /availability/{token}", h.handleAvailabilityForm)
 	api.HandleFunc("POST /availability/{token}", h.handleSubmitAvailability)
+	// The volunteer's own Absences, from the same page. Each answers with the
+	// form, which is where they are shown.
+	api.HandleFunc("POST /availability/{token}/absences", h.handleCreateOwnAbsence)
+	api.HandleFunc("DELETE /availability/{token}/absences/{id}", h.handleDeleteOwnAbsence)
+	// The same link once its rota is allocated: the volunteer's shifts, and
+	// the ones others have asked to give up that they could take. Public for
+	// the reason the form is, and under its own prefix for the reason the
+	// rounds are.
+	api.HandleFunc("GET /swaps/{token}", h.handleSwapPage)
+	api.HandleFunc("POST /swaps/{token}/requests", h.handleRequestSwap)
+	api.HandleFunc("DELETE /swaps/{token}/requests/{id}", h.handleWithdrawSwap)
+	api.HandleFunc("POST /swaps/{token}/offers/{id}/acceptance", h.handleAcceptSwap)
+	// A volunteer's link to one cover request, minted when it was made and
+	// dead once its shift starts. Public for the reason the form is.
+	api.HandleFunc("GET /cover/{token}", h.handleCoverForm)
+	api.HandleFunc("POST /cover/{token}", h.handleAnswerCover)
+	// The same availability link again, for a Team lead: the shifts they led
+	// that have started, to say who turned up. Public for the reason the swap
+	// page is.
+	api.HandleFunc("GET /attendance/{token}", h.handleLeadAttendance)
+	api.HandleFunc("PUT /attendance/{token}/shifts/{shiftId}/{volunteerId}", h.handleRecordLeadAttendance)
+	api.HandleFunc("DELETE /attendance/{token}/shifts/{shiftId}/{volunteerId}", h.handleClearLeadAttendance)
 
 	mux := http.NewServeMux()
 	mux.Handle(apiPrefix+"/", http.StripPrefix(apiPrefix, h.apiRouter(api)))
 	mux.HandleFunc("GET /health", h.handleHealth)
 	mux.HandleFunc("GET /calendars/{filename}", h.handleCalendar)
+	// The whole drop-in, and one Role across it, beside the volunteers' own
+	// feeds. all.ics is the more specific pattern, so it wins over a volunteer
+	// of that ID.
+	mux.HandleFunc("GET /calendars/all.ics", h.handleDropInCalendar)
+	mux.HandleFunc("GET /calendars/role/{filename}", h.handleRoleCalendar)
 	h.auth.registerRoutes(mux)
 	// Sits under /auth rather than /api because it is the same browser redirect
 	// dance as login and shares its registered callback URI — it is an OAuth
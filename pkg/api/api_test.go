@@ -8,6 +8,7 @@ import (
 	"net/http/httptest"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -65,6 +66,52 @@ type mockStore struct {
 	getShiftsErr     error
 	getRotationsErr  error
 
+	// pairingRules are the Pairing Rules between volunteers; pairingWriteErr is
+	// what the database says to a write of one.
+	pairingRules          []db.PairingRule
+	insertedPairingRules  []db.PairingRule
+	deletedPairingRuleIDs []string
+	pairingWriteErr       error
+
+	// swapRequests are the volunteers' swaps, whose methods live in
+	// swaps_test.go.
+	swapRequests []db.SwapRequest
+
+	// coverRequests and coverTokens are the admins' asks for cover and the
+	// links they handed out, whose methods live in coverRequests_test.go.
+	coverRequests []db.CoverRequest
+	coverTokens   []db.CoverRequestToken
+
+	// attendance is who turned up to the shifts that have happened, whose
+	// methods live in attendance_test.go.
+	attendance []db.Attendance
+
+	// calendarTokens address the volunteers' calendar feeds, whose methods
+	// live in calendarTokens_test.go. A send about a decided rota mints them
+	// from its own goroutine, so they are guarded.
+	calendarTokensMu sync.Mutex
+	calendarTokens   []db.CalendarToken
+
+	// absences are volunteers' days away, whose methods live in
+	// absences_test.go.
+	absences []db.Absence
+
+	// roster is the volunteer roster as the database keeps it, for a
+	// deployment whose roster is not the sheet; its methods live in
+	// roster_test.go. history is the ids a rota names, which a delete refuses.
+	roster  []db.Volunteer
+	history map[string]bool
+
+	// rosterSyncs are the recorded syncs from the sheet, newest first; their
+	// methods live in sync_test.go.
+	rosterSyncs []db.RosterSync
+
+	// sends and sendOutcomes are the recorded availability sends. A send runs
+	// in its own goroutine while the test polls it, so they are guarded.
+	sendsMu      sync.Mutex
+	sends        []db.AvailabilitySend
+	sendOutcomes []db.AvailabilitySendOutcome
+
 	// roles overrides apiTestRoles for a test that cares which Roles exist;
 	// rolesErr makes the read fail.
 	roles    []db.Role
@@ -337,6 +384,30 @@ func (m *mockStore) GetShiftShapes(_ context.Context, shiftIDs []string) (map[st
 	return shapes, nil
 }
 
+func (m *mockStore) GetPairingRules(context.Context) ([]db.PairingRule, error) {
+	return m.pairingRules, nil
+}
+
+func (m *mockStore) InsertPairingRule(_ context.Context, rule db.PairingRule) error {
+	if m.pairingWriteErr != nil {
+		return m.pairingWriteErr
+	}
+	m.pairingRules = append(m.pairingRules, rule)
+	m.insertedPairingRules = append(m.insertedPairingRules, rule)
+	return nil
+}
+
+func (m *mockStore) DeletePairingRuleByID(_ context.Context, id string) (bool, error) {
+	for i := range m.pairingRules {
+		if m.pairingRules[i].ID == id {
+			m.pairingRules = append(m.pairingRules[:i], m.pairingRules[i+1:]...)
+			m.deletedPairingRuleIDs = append(m.deletedPairingRuleIDs, id)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (m *mockStore) GetStandingPreallocations(context.Context) ([]db.StandingPreallocation, error) {
 	return m.standingPreallocations, nil
 }
@@ -383,6 +454,16 @@ func (m *mockStore) GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []str
 	return filtered, nil
 }
 
+func (m *mockStore) GetAlterationsByCoverID(ctx context.Context, coverID string) ([]db.Alteration, error) {
+	var filtered []db.Alteration
+	for _, a := range m.alterations {
+		if a.CoverID == coverID {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered, nil
+}
+
 func (m *mockStore) GetShiftByDate(ctx context.Context, date time.Time) (*db.Shift, error) {
 	dateStr := date.Format("2006-01-02")
 	for i := range m.shifts {
@@ -595,11 +676,11 @@ func (m *mockStore) GetLatestAvailability(ctx context.Context, requestIDs []stri
 	return map[string]db.AvailabilityGeneration{}, nil
 }
 
-func (m *mockStore) InsertAvailabilityResponse(ctx context.Context, requestID string, answers []db.ShiftAnswer) (*db.AvailabilityGeneration, error) {
+func (m *mockStore) InsertAvailabilityResponse(ctx context.Context, requestID string, answers []db.ShiftAnswer, preferredShiftCount int) (*db.AvailabilityGeneration, error) {
 	if m.insertErr != nil {
 		return nil, m.insertErr
 	}
-	return &db.AvailabilityGeneration{RequestID: requestID, ResponseID: "response-1", SubmittedAt: time.Now(), Answers: answers}, nil
+	return &db.AvailabilityGeneration{RequestID: requestID, ResponseID: "response-1", SubmittedAt: time.Now(), Answers: answers, PreferredShiftCount: preferredShiftCount}, nil
 }
 
 // idSet turns a shift id slice into a lookup set.
@@ -1224,6 +1305,36 @@ func TestCalendarEndpoint_EmptyFeedIsValid(t *testing.T) {
 	assert.NotContains(t, rec.Body.String(), "BEGIN:VEVENT")
 }
 
+func TestDropInCalendarEndpoint(t *testing.T) {
+	store := &mockStore{
+		allocations: []db.Allocation{
+			{ID: "a1", ShiftID: "2026-01-11", Role: "Team lead", VolunteerID: "alice"},
+			{ID: "a2", ShiftID: "2026-01-11", Role: "Service volunteer", VolunteerID: "bob"},
+			{ID: "a3", ShiftID: "2026-01-18", Role: "Service volunteer", VolunteerID: "bob"},
+		},
+	}
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/calendars/all.ics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/calendar")
+	body := rec.Body.String()
+	assert.Contains(t, body, "UID:all-2026-01-11@ilford-drop-in")
+	assert.Contains(t, body, "UID:all-2026-01-18@ilford-drop-in")
+
+	rec = doRequest(t, handler, http.MethodGet, "/calendars/role/role-team-lead.ics", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	body = rec.Body.String()
+	assert.Contains(t, body, "UID:role-role-team-lead-2026-01-11@ilford-drop-in")
+	assert.Contains(t, body, "SUMMARY:Ilford Drop-In shift (Team lead: nobody yet)", "the 18th asks for a lead and has none")
+
+	// A Role is addressed by ID, not by name
+	rec = doRequest(t, handler, http.MethodGet, "/calendars/role/Team%20lead.ics", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doRequest(t, handler, http.MethodGet, "/calendars/role/role-team-lead", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
 func TestMethodNotAllowed(t *testing.T) {
 	handler := newTestHandler(&mockStore{}, testVolunteers())
 
@@ -1249,7 +1360,7 @@ func newFullStackHandler(store *mockStore) http.Handler {
 func TestUnknownAPIPathIsAJSONNotFound(t *testing.T) {
 	handler := newFullStackHandler(&mockStore{})
 
-	for _, path := range []string{"/api/nonsense", "/api/shift", "/api/", "/api/volunteers/alice"} {
+	for _, path := range []string{"/api/nonsense", "/api/shift", "/api/", "/api/volunteers/alice/roles"} {
 		rec := doRequest(t, handler, http.MethodGet, path, "")
 		require.Equal(t, http.StatusNotFound, rec.Code, path)
 		assert.Contains(t, rec.Header().Get("Content-Type"), "application/json", path)
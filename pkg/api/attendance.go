@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// attendanceEntryResponse is one volunteer on a shift that has happened.
+// status is absent until somebody has said whether they came.
+type attendanceEntryResponse struct {
+	VolunteerID    string `json:"volunteerId"`
+	VolunteerName  string `json:"volunteerName"`
+	Role           string `json:"role"`
+	Status         string `json:"status,omitempty"`
+	RecordedBy     string `json:"recordedBy,omitempty"`
+	RecordedByName string `json:"recordedByName,omitempty"`
+	RecordedAt     string `json:"recordedAt,omitempty"`
+}
+
+// shiftAttendanceResponse is a shift that has happened and everyone who was
+// on it. people is always a list, never null: a shift whose every seat was a
+// custom entry has nobody on the roster to mark.
+type shiftAttendanceResponse struct {
+	ShiftID string                    `json:"shiftId"`
+	Date    string                    `json:"date"`
+	Start   string                    `json:"start"`
+	End     string                    `json:"end"`
+	People  []attendanceEntryResponse `json:"people"`
+}
+
+// leadAttendanceResponse is what is behind a Team lead's attendance link.
+type leadAttendanceResponse struct {
+	VolunteerName string                    `json:"volunteerName"`
+	Role          string                    `json:"role"`
+	Shifts        []shiftAttendanceResponse `json:"shifts"`
+}
+
+type recordAttendanceRequest struct {
+	Status string `json:"status"`
+}
+
+// decodeAttendanceStatus reads the one answer a PUT states. An empty one is
+// refused rather than read as clearing: that is what DELETE is for, and a
+// client that forgot the field should hear about it.
+func (h *Handler) decodeAttendanceStatus(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req recordAttendanceRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return "", false
+	}
+	if req.Status == "" {
+		h.writeError(w, http.StatusBadRequest, "status is required: DELETE clears what was recorded")
+		return "", false
+	}
+	return req.Status, true
+}
+
+// handleGetShiftAttendance is one shift that has happened, for an admin.
+func (h *Handler) handleGetShiftAttendance(w http.ResponseWriter, r *http.Request) {
+	view, err := services.GetShiftAttendance(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("id"), time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toShiftAttendanceResponse(*view))
+}
+
+// handleRecordAttendance is an admin saying whether somebody turned up, and
+// answers with the shift as it now stands.
+func (h *Handler) handleRecordAttendance(w http.ResponseWriter, r *http.Request) {
+	status, ok := h.decodeAttendanceStatus(w, r)
+	if !ok {
+		return
+	}
+	h.recordAttendance(w, r, status)
+}
+
+// handleClearAttendance forgets what was recorded of somebody on a shift.
+func (h *Handler) handleClearAttendance(w http.ResponseWriter, r *http.Request) {
+	h.recordAttendance(w, r, "")
+}
+
+func (h *Handler) recordAttendance(w http.ResponseWriter, r *http.Request, status string) {
+	view, err := services.RecordAttendance(r.Context(), h.store, h.volunteers, h.cfg, services.AttendanceParams{
+		ShiftID:     r.PathValue("id"),
+		VolunteerID: r.PathValue("volunteerId"),
+		Status:      status,
+		RecordedBy:  adminEmail(r.Context()),
+	}, time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toShiftAttendanceResponse(*view))
+}
+
+// handleLeadAttendance serves a Team lead's attendance page: the shifts on
+// their link's rota they led that have started. Public, for the reason the
+// swap page is — the link is the identity.
+func (h *Handler) handleLeadAttendance(w http.ResponseWriter, r *http.Request) {
+	page, err := services.GetLeadAttendance(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toLeadAttendanceResponse(page))
+}
+
+// handleRecordLeadAttendance is a Team lead marking somebody on a shift they
+// led, and answers with their page as it now stands.
+func (h *Handler) handleRecordLeadAttendance(w http.ResponseWriter, r *http.Request) {
+	status, ok := h.decodeAttendanceStatus(w, r)
+	if !ok {
+		return
+	}
+	h.recordLeadAttendance(w, r, status)
+}
+
+// handleClearLeadAttendance is a Team lead taking back an answer.
+func (h *Handler) handleClearLeadAttendance(w http.ResponseWriter, r *http.Request) {
+	h.recordLeadAttendance(w, r, "")
+}
+
+func (h *Handler) recordLeadAttendance(w http.ResponseWriter, r *http.Request, status string) {
+	page, err := services.RecordLeadAttendance(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), services.AttendanceParams{
+		ShiftID:     r.PathValue("shiftId"),
+		VolunteerID: r.PathValue("volunteerId"),
+		Status:      status,
+	}, time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toLeadAttendanceResponse(page))
+}
+
+func toShiftAttendanceResponse(v services.ShiftAttendance) shiftAttendanceResponse {
+	resp := shiftAttendanceResponse{
+		ShiftID: v.ShiftID,
+		Date:    v.Date,
+		Start:   v.Start,
+		End:     v.End,
+		People:  make([]attendanceEntryResponse, 0, len(v.People)),
+	}
+	for _, p := range v.People {
+		entry := attendanceEntryResponse{
+			VolunteerID:    p.VolunteerID,
+			VolunteerName:  p.VolunteerName,
+			Role:           p.Role,
+			Status:         p.Status,
+			RecordedBy:     p.RecordedBy,
+			RecordedByName: p.RecordedByName,
+		}
+		if p.RecordedAt != nil {
+			entry.RecordedAt = p.RecordedAt.UTC().Format(time.RFC3339)
+		}
+		resp.People = append(resp.People, entry)
+	}
+	return resp
+}
+
+func toLeadAttendanceResponse(page *services.LeadAttendancePage) leadAttendanceResponse {
+	resp := leadAttendanceResponse{
+		VolunteerName: page.VolunteerName,
+		Role:          page.Role,
+		Shifts:        make([]shiftAttendanceResponse, 0, len(page.Shifts)),
+	}
+	for _, s := range page.Shifts {
+		resp.Shifts = append(resp.Shifts, toShiftAttendanceResponse(s))
+	}
+	return resp
+}
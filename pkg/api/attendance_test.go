@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The attendance methods of mockStore: one answer per volunteer per shift, a
+// second replacing the first as the real upsert does.
+func (m *mockStore) GetAttendanceByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Attendance, error) {
+	want := idSet(shiftIDs)
+	var out []db.Attendance
+	for _, a := range m.attendance {
+		if want[a.ShiftID] {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) RecordAttendance(_ context.Context, a db.Attendance) error {
+	a.RecordedAt = time.Now()
+	for i := range m.attendance {
+		if m.attendance[i].ShiftID == a.ShiftID && m.attendance[i].VolunteerID == a.VolunteerID {
+			m.attendance[i] = a
+			return nil
+		}
+	}
+	m.attendance = append(m.attendance, a)
+	return nil
+}
+
+func (m *mockStore) ClearAttendance(_ context.Context, shiftID, volunteerID string) error {
+	kept := m.attendance[:0]
+	for _, a := range m.attendance {
+		if a.ShiftID != shiftID || a.VolunteerID != volunteerID {
+			kept = append(kept, a)
+		}
+	}
+	m.attendance = kept
+	return nil
+}
+
+// attendanceTestShiftID is a UUID, because attendance is recorded against a
+// shift's id and Postgres would refuse anything else.
+const attendanceTestShiftID = "7b2e3f4a-5d6c-4b7a-9988-776655443322"
+
+// attendanceTestStore is an allocated rota with one shift a week ago: Alice
+// led it and Bob served beside her. The date is relative because the handlers
+// read the real clock, and attendance is only recorded on a shift that has
+// happened.
+func attendanceTestStore() *mockStore {
+	date := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+	return &mockStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: date, End: date, ShiftCount: 1, AllocatedDatetime: "2026-01-01T09:00:00Z"}},
+		shifts:    []db.Shift{{ID: attendanceTestShiftID, RotaID: "rota-1", Date: date, StartAt: date + "T19:30:00", EndAt: date + "T21:30:00"}},
+		allocations: []db.Allocation{
+			{ID: "a1", ShiftID: attendanceTestShiftID, VolunteerID: "alice", Role: "Team lead"},
+			{ID: "a2", ShiftID: attendanceTestShiftID, VolunteerID: "bob", Role: "Service volunteer"},
+		},
+		availabilityRequests: []db.AvailabilityRequest{
+			{ID: "req-alice", RotaID: "rota-1", VolunteerID: "alice", Token: "tok-alice"},
+			{ID: "req-bob", RotaID: "rota-1", VolunteerID: "bob", Token: "tok-bob"},
+		},
+	}
+}
+
+func TestRecordAttendanceAsAdmin(t *testing.T) {
+	store := attendanceTestStore()
+	handler := newTestHandler(store, testVolunteers())
+	target := "/api/shifts/" + attendanceTestShiftID + "/attendance"
+
+	rec := doRequest(t, handler, http.MethodGet, target, "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var view shiftAttendanceResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &view))
+	require.Len(t, view.People, 2)
+	assert.Equal(t, "alice", view.People[0].VolunteerID)
+	assert.Empty(t, view.People[1].Status)
+
+	rec = doRequest(t, handler, http.MethodPut, target+"/bob", `{"status":"no_show"}`, adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &view))
+	assert.Equal(t, "no_show", view.People[1].Status)
+	assert.Equal(t, testAdminEmail, view.People[1].RecordedBy)
+	assert.NotEmpty(t, view.People[1].RecordedAt)
+
+	rec = doRequest(t, handler, http.MethodDelete, target+"/bob", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, store.attendance)
+}
+
+func TestRecordAttendanceOnTheLeadsLink(t *testing.T) {
+	store := attendanceTestStore()
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/attendance/tok-alice", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page leadAttendanceResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Shifts, 1)
+
+	rec = doRequest(t, handler, http.MethodPut, "/api/attendance/tok-alice/shifts/"+attendanceTestShiftID+"/bob", `{"status":"left_early"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, "left_early", page.Shifts[0].People[1].Status)
+	assert.Equal(t, "alice", store.attendance[0].RecordedBy)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/attendance/tok-bob", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Empty(t, page.Shifts, "Bob led nothing")
+	assert.NotNil(t, page.Shifts, "an empty list, not null")
+
+	rec = doRequest(t, handler, http.MethodPut, "/api/attendance/tok-bob/shifts/"+attendanceTestShiftID+"/alice", `{"status":"no_show"}`)
+	assert.Equal(t, http.StatusNotFound, rec.Code, "only the lead marks the shift")
+}
+
+// TestRecordAttendanceRefusals: what the endpoints are told when they ask for
+// something they cannot have.
+func TestRecordAttendanceRefusals(t *testing.T) {
+	target := "/api/shifts/" + attendanceTestShiftID + "/attendance"
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		admin    bool
+		wantCode int
+	}{
+		{name: "no status", method: http.MethodPut, target: target + "/bob", body: `{}`, admin: true, wantCode: http.StatusBadRequest},
+		{name: "unknown status", method: http.MethodPut, target: target + "/bob", body: `{"status":"late"}`, admin: true, wantCode: http.StatusBadRequest},
+		{name: "unknown field", method: http.MethodPut, target: target + "/bob", body: `{"status":"no_show","note":"x"}`, admin: true, wantCode: http.StatusBadRequest},
+		{name: "not on the shift", method: http.MethodPut, target: target + "/charlie", body: `{"status":"no_show"}`, admin: true, wantCode: http.StatusConflict},
+		{name: "unknown shift", method: http.MethodGet, target: "/api/shifts/shift-9/attendance", admin: true, wantCode: http.StatusNotFound},
+		{name: "unknown link", method: http.MethodGet, target: "/api/attendance/tok-nobody", wantCode: http.StatusNotFound},
+		{name: "admin only", method: http.MethodPut, target: target + "/bob", body: `{"status":"no_show"}`, wantCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := attendanceTestStore()
+			var cookies []*http.Cookie
+			if tt.admin {
+				cookies = append(cookies, adminCookie())
+			}
+			rec := doRequest(t, newTestHandler(store, testVolunteers()), tt.method, tt.target, tt.body, cookies...)
+
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Empty(t, store.attendance)
+		})
+	}
+}
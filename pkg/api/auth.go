@@ -38,10 +38,6 @@ type Authenticator struct {
 	adminEmails  map[string]struct{} // lowercased allowlist
 	secure       bool                // set the cookie Secure flag (prod only)
 	logger       *zap.Logger
-	// syncVolunteers runs an admin-triggered volunteer sync using the server's
-	// own service account credential. Injected by the composition root; nil
-	// disables the sync endpoint.
-	syncVolunteers VolunteerSyncFunc
 	// stubEmail, when non-empty, replaces the Google round-trip: login mints a
 	// session for this address directly. Set only by NewStubAuthenticator, which
 	// the dev environment alone can reach (see authstub.go).
@@ -50,6 +46,9 @@ type Authenticator struct {
 	// shared callback. Set by NewHandler, because the send needs the store and
 	// the roster and this type has neither; nil means no sending is wired up.
 	completeSend http.HandlerFunc
+	// rosterSync reports how fresh the roster is, for /auth/me. Set by
+	// EnableRosterSync; nil where the database is the roster.
+	rosterSync func() *rosterSyncStatusResponse
 }
 
 // isStubbed reports whether the Google round-trip has been replaced, which is
@@ -65,9 +64,8 @@ func (a *Authenticator) sameAdmin(x, y string) bool {
 }
 
 // NewAuthenticator builds an Authenticator. It performs OIDC provider discovery
-// against Google, so it makes a network call and can fail. syncVolunteers is
-// invoked by the sync endpoint to repopulate the roster from the sheet.
-func NewAuthenticator(ctx context.Context, webCfg *config.OAuthClientWebConfig, srv *config.ServerConfig, env string, logger *zap.Logger, syncVolunteers VolunteerSyncFunc) (*Authenticator, error) {
+// against Google, so it makes a network call and can fail.
+func NewAuthenticator(ctx context.Context, webCfg *config.OAuthClientWebConfig, srv *config.ServerConfig, env string, logger *zap.Logger) (*Authenticator, error) {
 	provider, err := oidc.NewProvider(ctx, googleIssuer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to discover OIDC provider: %w", err)
@@ -87,13 +85,12 @@ func NewAuthenticator(ctx context.Context, webCfg *config.OAuthClientWebConfig,
 	}
 
 	return &Authenticator{
-		oauth2Config:   oauth2Config,
-		verifier:       provider.Verifier(&oidc.Config{ClientID: webCfg.Web.ClientID}),
-		secret:         []byte(srv.SessionSecret),
-		adminEmails:    adminAllowlist(srv.AdminEmails),
-		secure:         env == "prod",
-		logger:         logger,
-		syncVolunteers: syncVolunteers,
+		oauth2Config: oauth2Config,
+		verifier:     provider.Verifier(&oidc.Config{ClientID: webCfg.Web.ClientID}),
+		secret:       []byte(srv.SessionSecret),
+		adminEmails:  adminAllowlist(srv.AdminEmails),
+		secure:       env == "prod",
+		logger:       logger,
 	}, nil
 }
 
@@ -113,10 +110,6 @@ func (a *Authenticator) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /auth/callback", a.handleCallback)
 	mux.HandleFunc("POST /auth/logout", a.handleLogout)
 	mux.HandleFunc("GET /auth/me", a.handleMe)
-	// Syncing repopulates the roster from the sheet with the server's service
-	// account; it requires an admin session but no OAuth round-trip, so it is a
-	// plain POST rather than a redirect dance.
-	mux.Handle("POST /auth/sync", a.requireAdmin(http.HandlerFunc(a.handleSync)))
 }
 
 // handleLogin starts the OIDC flow: stash a random state in a short-lived cookie
@@ -250,16 +243,26 @@ func (a *Authenticator) handleLogout(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type meResponse struct {
+	Email      string                    `json:"email"`
+	RosterSync *rosterSyncStatusResponse `json:"rosterSync,omitempty"`
+}
+
 // handleMe reports the logged-in admin's email, or 401 if there is no valid
-// admin session. Used by the frontend to show logged-in state.
+// admin session. Used by the frontend to show logged-in state, and, where the
+// sheet is the roster, how long ago it was read.
 func (a *Authenticator) handleMe(w http.ResponseWriter, r *http.Request) {
 	email, ok := a.adminFromRequest(r)
 	if !ok {
 		http.Error(w, "not authenticated", http.StatusUnauthorized)
 		return
 	}
+	resp := meResponse{Email: email}
+	if a.rosterSync != nil {
+		resp.RosterSync = a.rosterSync()
+	}
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	if err := json.NewEncoder(w).Encode(map[string]string{"email": email}); err != nil {
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		a.logger.Error("Failed to encode /auth/me response", zap.Error(err))
 	}
 }
@@ -21,15 +21,14 @@ import (
 // requests without it, so what an agent sees is the real gate rather than an
 // open door. Reaching this constructor needs a devMode block in config, which
 // only the dev environment may carry (internal/config.checkDevMode).
-func NewStubAuthenticator(dev *config.DevModeConfig, srv *config.ServerConfig, logger *zap.Logger, syncVolunteers VolunteerSyncFunc) (*Authenticator, error) {
+func NewStubAuthenticator(dev *config.DevModeConfig, srv *config.ServerConfig, logger *zap.Logger) (*Authenticator, error) {
 	a := &Authenticator{
 		secret:      []byte(srv.SessionSecret),
 		adminEmails: adminAllowlist(srv.AdminEmails),
 		// Dev runs over plain HTTP: a Secure cookie would be set and never sent back.
-		secure:         false,
-		logger:         logger,
-		syncVolunteers: syncVolunteers,
-		stubEmail:      dev.AdminEmail,
+		secure:    false,
+		logger:    logger,
+		stubEmail: dev.AdminEmail,
 	}
 
 	// A session for an address off the allowlist carries no authority, so login
@@ -52,23 +51,24 @@ func NewStubAuthenticator(dev *config.DevModeConfig, srv *config.ServerConfig, l
 // are all the real ones, so what an agent drives here is the real flow rather
 // than a mock of it.
 func NewStubMailer(logger *zap.Logger) MailerFunc {
-	return func(context.Context, *oauth2.Token) (services.GmailClient, error) {
+	return func(context.Context, *oauth2.Token) (services.MailClient, error) {
 		return stubMailer{logger: logger}, nil
 	}
 }
 
-// stubMailer logs what would have gone out. The body is logged in full because
-// it carries the volunteer's link, which is the one thing worth reading out of a
-// dev-stack send.
+// stubMailer logs what would have gone out. The text body is logged in full
+// because it carries the volunteer's link, which is the one thing worth reading
+// out of a dev-stack send; the HTML part says the same again, so it is left out.
 type stubMailer struct {
 	logger *zap.Logger
 }
 
-func (m stubMailer) SendEmail(to, subject, body string) error {
+func (m stubMailer) SendEmail(to, subject, text, html string) error {
 	m.logger.Warn("Dev mode: pretending to send an email",
 		zap.String("to", to),
 		zap.String("subject", subject),
-		zap.String("body", body))
+		zap.String("body", text),
+		zap.Bool("has_html", html != ""))
 	return nil
 }
 
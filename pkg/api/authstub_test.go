@@ -25,7 +25,7 @@ func testStubAuth(t *testing.T) *Authenticator {
 	a, err := NewStubAuthenticator(&config.DevModeConfig{
 		AdminEmail:    "admin@example.com",
 		VolunteersCSV: "test_data/volunteers.csv",
-	}, testServerConfig(), zap.NewNop(), nil)
+	}, testServerConfig(), zap.NewNop())
 	require.NoError(t, err)
 	return a
 }
@@ -96,7 +96,7 @@ func TestNewStubAuthenticator_RequiresAnAllowlistedEmail(t *testing.T) {
 	_, err := NewStubAuthenticator(&config.DevModeConfig{
 		AdminEmail:    "stranger@example.com",
 		VolunteersCSV: "test_data/volunteers.csv",
-	}, testServerConfig(), zap.NewNop(), nil)
+	}, testServerConfig(), zap.NewNop())
 
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "stranger@example.com")
@@ -112,7 +112,7 @@ func TestNewStubAuthenticator_FoldsGmailVariants(t *testing.T) {
 	a, err := NewStubAuthenticator(&config.DevModeConfig{
 		AdminEmail:    "jakechorley@gmail.com",
 		VolunteersCSV: "test_data/volunteers.csv",
-	}, srv, zap.NewNop(), nil)
+	}, srv, zap.NewNop())
 
 	require.NoError(t, err)
 	assert.True(t, a.isAdmin("jakechorley@gmail.com"))
@@ -35,7 +35,13 @@ type availabilityEntryResponse struct {
 	Replied           bool     `json:"replied"`
 	SubmittedAt       string   `json:"submittedAt,omitempty"`
 	AvailableShiftIDs []string `json:"availableShiftIds"`
-	CoveredBy         []string `json:"coveredBy,omitempty"`
+	// Absent when they gave no number, which is the common case.
+	PreferredShiftCount int `json:"preferredShiftCount,omitempty"`
+	// The shifts one of their Absences covers: a no, whatever
+	// availableShiftIds says. Always a list, like availableShiftIds, so the
+	// overlap between the two is one intersection.
+	AbsentShiftIDs []string `json:"absentShiftIds"`
+	CoveredBy      []string `json:"coveredBy,omitempty"`
 	// The Roles they hold on the roster, in priority order — what makes a round
 	// filterable by Role without a second request. Always a list, never null:
 	// a volunteer the roster has dropped holds none, and that is a fact about
@@ -46,12 +52,18 @@ type availabilityEntryResponse struct {
 // availabilityGroupResponse is a round at the grain allocation happens at. The
 // group's availability is the group rule already applied, so no client has to
 // re-derive it — the logic lives in one place (ADR 0004).
+//
+// preferredShiftCount is likewise the group's settled number — the least any
+// responder asked for — and absent when nobody gave one. absentShiftIds are the
+// shifts a member is away for, none of which is among availableShiftIds.
 type availabilityGroupResponse struct {
-	Key               string                      `json:"key"`
-	Name              string                      `json:"name"`
-	Replied           bool                        `json:"replied"`
-	AvailableShiftIDs []string                    `json:"availableShiftIds"`
-	Members           []availabilityEntryResponse `json:"members"`
+	Key                 string                      `json:"key"`
+	Name                string                      `json:"name"`
+	Replied             bool                        `json:"replied"`
+	AvailableShiftIDs   []string                    `json:"availableShiftIds"`
+	PreferredShiftCount int                         `json:"preferredShiftCount,omitempty"`
+	AbsentShiftIDs      []string                    `json:"absentShiftIds"`
+	Members             []availabilityEntryResponse `json:"members"`
 }
 
 // availabilityCoverageResponse is one shift's staffing picture: what it still
@@ -99,19 +111,29 @@ type availabilityFormResponse struct {
 	SelectedShiftIDs []string                    `json:"selectedShiftIds"`
 	Submitted        bool                        `json:"submitted"`
 	SubmittedAt      string                      `json:"submittedAt,omitempty"`
+	// 0 is "no preference". Always written, so the form's number field has a
+	// value to land on either way.
+	PreferredShiftCount int `json:"preferredShiftCount"`
 	// False when nothing said here can reach a rota — see AvailabilityForm.
 	// Always written, never omitempty: the client treats only an explicit false
 	// as the warning case, so a missing field stays quiet rather than telling
 	// every volunteer they have stopped.
 	Counts bool `json:"counts"`
+	// Their own Absences, and the shifts those cover, which the form shows as
+	// days away rather than offering as a yes.
+	Absences       []absenceResponse `json:"absences"`
+	AbsentShiftIDs []string          `json:"absentShiftIds"`
 }
 
 type mintRoundRequest struct {
 	RotaID string `json:"rotaId"`
 }
 
+// submitAvailabilityRequest is one generation. preferredShiftCount is optional:
+// absent or 0 is "no preference", and it may not exceed the shifts ticked.
 type submitAvailabilityRequest struct {
-	ShiftIDs []string `json:"shiftIds"`
+	ShiftIDs            []string `json:"shiftIds"`
+	PreferredShiftCount int      `json:"preferredShiftCount"`
 }
 
 // handleMintAvailabilityRound creates an availability request, with its own
@@ -184,7 +206,7 @@ func (h *Handler) handleSubmitAvailability(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	form, err := services.SubmitAvailability(r.Context(), h.store, h.volunteers, h.cfg, h.logger, r.PathValue("token"), req.ShiftIDs)
+	form, err := services.SubmitAvailability(r.Context(), h.store, h.volunteers, h.cfg, h.logger, r.PathValue("token"), req.ShiftIDs, req.PreferredShiftCount)
 	if err != nil {
 		h.writeServiceError(w, err)
 		return
@@ -249,22 +271,26 @@ func toRoundResponse(round *services.AvailabilityRound, r *http.Request) availab
 	}
 	for _, g := range round.Groups {
 		group := availabilityGroupResponse{
-			Key:               g.Key,
-			Name:              g.Name,
-			Replied:           g.Replied,
-			AvailableShiftIDs: g.AvailableShiftIDs,
-			Members:           make([]availabilityEntryResponse, 0, len(g.Members)),
+			Key:                 g.Key,
+			Name:                g.Name,
+			Replied:             g.Replied,
+			AvailableShiftIDs:   g.AvailableShiftIDs,
+			PreferredShiftCount: g.PreferredShiftCount,
+			AbsentShiftIDs:      g.AbsentShiftIDs,
+			Members:             make([]availabilityEntryResponse, 0, len(g.Members)),
 		}
 		for _, e := range g.Members {
 			member := availabilityEntryResponse{
-				VolunteerID:       e.VolunteerID,
-				VolunteerName:     e.VolunteerName,
-				Link:              availabilityLink(r, e.Token),
-				SentAt:            e.SentAt,
-				Replied:           e.Replied,
-				AvailableShiftIDs: e.AvailableShiftIDs,
-				CoveredBy:         e.CoveredBy,
-				Roles:             heldRoles(e.Roles),
+				VolunteerID:         e.VolunteerID,
+				VolunteerName:       e.VolunteerName,
+				Link:                availabilityLink(r, e.Token),
+				SentAt:              e.SentAt,
+				Replied:             e.Replied,
+				AvailableShiftIDs:   e.AvailableShiftIDs,
+				PreferredShiftCount: e.PreferredShiftCount,
+				AbsentShiftIDs:      idList(e.AbsentShiftIDs),
+				CoveredBy:           e.CoveredBy,
+				Roles:               heldRoles(e.Roles),
 			}
 			if !e.SubmittedAt.IsZero() {
 				member.SubmittedAt = e.SubmittedAt.UTC().Format(time.RFC3339)
@@ -278,12 +304,15 @@ func toRoundResponse(round *services.AvailabilityRound, r *http.Request) availab
 
 func toFormResponse(form *services.AvailabilityForm) availabilityFormResponse {
 	resp := availabilityFormResponse{
-		VolunteerName:    form.VolunteerName,
-		GroupMembers:     form.GroupMembers,
-		Shifts:           toShiftResponses(form.Shifts),
-		SelectedShiftIDs: form.SelectedShiftIDs,
-		Submitted:        form.Submitted,
-		Counts:           form.Counts,
+		VolunteerName:       form.VolunteerName,
+		GroupMembers:        form.GroupMembers,
+		Shifts:              toShiftResponses(form.Shifts),
+		SelectedShiftIDs:    form.SelectedShiftIDs,
+		Submitted:           form.Submitted,
+		Counts:              form.Counts,
+		PreferredShiftCount: form.PreferredShiftCount,
+		Absences:            toAbsenceResponses(form.Absences),
+		AbsentShiftIDs:      idList(form.AbsentShiftIDs),
 	}
 	if !form.SubmittedAt.IsZero() {
 		resp.SubmittedAt = form.SubmittedAt.UTC().Format(time.RFC3339)
@@ -304,3 +333,12 @@ func toShiftResponses(shifts []services.AvailabilityShift) []availabilityShiftRe
 	}
 	return out
 }
+
+// idList is ids as a list, never null: a volunteer with no days away has an
+// empty list of them, as heldRoles has it for Roles.
+func idList(ids []string) []string {
+	if ids == nil {
+		return []string{}
+	}
+	return ids
+}
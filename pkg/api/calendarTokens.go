@@ -0,0 +1,100 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// calendarTokenResponse is one volunteer's calendar feed as an admin hands it
+// out: the whole link rather than the bare token, since a link is the only
+// thing anybody does with one.
+type calendarTokenResponse struct {
+	VolunteerID string `json:"volunteerId"`
+	Link        string `json:"link"`
+	CreatedBy   string `json:"createdBy"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+type listCalendarTokensResponse struct {
+	CalendarTokens []calendarTokenResponse `json:"calendarTokens"`
+}
+
+// mintMissingCalendarTokensResponse is every token after the missing ones were
+// minted, and how many that was.
+type mintMissingCalendarTokensResponse struct {
+	Minted         int                     `json:"minted"`
+	CalendarTokens []calendarTokenResponse `json:"calendarTokens"`
+}
+
+// handleListCalendarTokens returns every volunteer's calendar link. A volunteer
+// without one is absent rather than listed with nothing.
+func (h *Handler) handleListCalendarTokens(w http.ResponseWriter, r *http.Request) {
+	tokens, err := h.calendarTokenResponses(r)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, listCalendarTokensResponse{CalendarTokens: tokens})
+}
+
+// handleMintMissingCalendarTokens gives everybody on the roster without a
+// calendar token one, which is moving the roster off the old id links.
+func (h *Handler) handleMintMissingCalendarTokens(w http.ResponseWriter, r *http.Request) {
+	minted, err := services.MintMissingCalendarTokens(r.Context(), h.store, h.volunteers, h.cfg, adminEmail(r.Context()), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	tokens, err := h.calendarTokenResponses(r)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, mintMissingCalendarTokensResponse{Minted: minted, CalendarTokens: tokens})
+}
+
+// handleMintCalendarToken gives one volunteer a new calendar token, replacing
+// theirs. 201 with the new link; 404 for somebody not on the roster.
+func (h *Handler) handleMintCalendarToken(w http.ResponseWriter, r *http.Request) {
+	token, err := services.MintCalendarToken(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("volunteerId"), adminEmail(r.Context()), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, toCalendarTokenResponse(r, *token))
+}
+
+// handleRevokeCalendarToken deletes one volunteer's calendar token. 204 on
+// success, 404 when they had none.
+func (h *Handler) handleRevokeCalendarToken(w http.ResponseWriter, r *http.Request) {
+	if err := services.RevokeCalendarToken(r.Context(), h.store, r.PathValue("volunteerId"), h.logger); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) calendarTokenResponses(r *http.Request) ([]calendarTokenResponse, error) {
+	tokens, err := services.ListCalendarTokens(r.Context(), h.store)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]calendarTokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		out = append(out, toCalendarTokenResponse(r, t))
+	}
+	return out, nil
+}
+
+func toCalendarTokenResponse(r *http.Request, t db.CalendarToken) calendarTokenResponse {
+	return calendarTokenResponse{
+		VolunteerID: t.VolunteerID,
+		Link:        calendarLink(r, t.Token),
+		CreatedBy:   t.CreatedBy,
+		CreatedAt:   t.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
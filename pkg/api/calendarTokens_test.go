@@ -0,0 +1,160 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The calendar token methods of mockStore: one token per volunteer, saving
+// replacing and inserting leaving an existing one alone, as the table does.
+func (m *mockStore) GetCalendarTokenByToken(_ context.Context, token string) (*db.CalendarToken, error) {
+	m.calendarTokensMu.Lock()
+	defer m.calendarTokensMu.Unlock()
+	for _, t := range m.calendarTokens {
+		if t.Token == token {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockStore) GetCalendarTokens(context.Context) ([]db.CalendarToken, error) {
+	m.calendarTokensMu.Lock()
+	defer m.calendarTokensMu.Unlock()
+	return append([]db.CalendarToken(nil), m.calendarTokens...), nil
+}
+
+func (m *mockStore) SaveCalendarToken(_ context.Context, t db.CalendarToken) (db.CalendarToken, error) {
+	m.calendarTokensMu.Lock()
+	defer m.calendarTokensMu.Unlock()
+	t.CreatedAt = time.Now()
+	for i := range m.calendarTokens {
+		if m.calendarTokens[i].VolunteerID == t.VolunteerID {
+			m.calendarTokens[i] = t
+			return t, nil
+		}
+	}
+	m.calendarTokens = append(m.calendarTokens, t)
+	return t, nil
+}
+
+func (m *mockStore) InsertCalendarTokenIfAbsent(_ context.Context, t db.CalendarToken) (bool, error) {
+	m.calendarTokensMu.Lock()
+	defer m.calendarTokensMu.Unlock()
+	for _, existing := range m.calendarTokens {
+		if existing.VolunteerID == t.VolunteerID {
+			return false, nil
+		}
+	}
+	t.CreatedAt = time.Now()
+	m.calendarTokens = append(m.calendarTokens, t)
+	return true, nil
+}
+
+func (m *mockStore) DeleteCalendarToken(_ context.Context, volunteerID string) (bool, error) {
+	m.calendarTokensMu.Lock()
+	defer m.calendarTokensMu.Unlock()
+	for i, t := range m.calendarTokens {
+		if t.VolunteerID == volunteerID {
+			m.calendarTokens = append(m.calendarTokens[:i], m.calendarTokens[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestCalendarTokenEndpoints(t *testing.T) {
+	store := &mockStore{}
+	handler := newTestHandler(store, testVolunteers())
+	// The same store with the legacy window shut, so a token that no longer
+	// answers cannot fall through to being read as a volunteer id.
+	closed := newTestHandlerWithConfig(store, testVolunteers(), closedLegacyCfg())
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/calendar-tokens/alice", "", adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var minted calendarTokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &minted))
+	assert.Equal(t, "alice", minted.VolunteerID)
+	assert.True(t, strings.HasPrefix(minted.Link, "http://example.com/calendars/"))
+	assert.NotContains(t, minted.Link, "alice", "the link says nothing about whose it is")
+
+	// The link answers with Alice's feed.
+	rec = doRequest(t, handler, http.MethodGet, strings.TrimPrefix(minted.Link, "http://example.com"), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "X-WR-CALNAME:Ilford Drop-In")
+
+	// Minting again replaces it, and the old link stops answering.
+	rec = doRequest(t, handler, http.MethodPost, "/api/calendar-tokens/alice", "", adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var rotated calendarTokenResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rotated))
+	assert.NotEqual(t, minted.Link, rotated.Link)
+	rec = doRequest(t, closed, http.MethodGet, strings.TrimPrefix(minted.Link, "http://example.com"), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Everybody else gets one; Alice keeps hers.
+	rec = doRequest(t, handler, http.MethodPost, "/api/calendar-tokens", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code)
+	var all mintMissingCalendarTokensResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &all))
+	assert.Equal(t, len(testVolunteers().volunteers)-1, all.Minted)
+	assert.Len(t, all.CalendarTokens, len(testVolunteers().volunteers))
+	assert.Contains(t, all.CalendarTokens, rotated)
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/calendar-tokens/alice", "", adminCookie())
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doRequest(t, closed, http.MethodGet, strings.TrimPrefix(rotated.Link, "http://example.com"), "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "a revoked link stops answering")
+	rec = doRequest(t, handler, http.MethodDelete, "/api/calendar-tokens/alice", "", adminCookie())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doRequest(t, handler, http.MethodPost, "/api/calendar-tokens/nobody", "", adminCookie())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCalendarTokenEndpointsAreAdminOnly(t *testing.T) {
+	handler := newTestHandler(&mockStore{}, testVolunteers())
+	for _, tt := range []struct{ method, path string }{
+		{http.MethodGet, "/api/calendar-tokens"},
+		{http.MethodPost, "/api/calendar-tokens"},
+		{http.MethodPost, "/api/calendar-tokens/alice"},
+		{http.MethodDelete, "/api/calendar-tokens/alice"},
+	} {
+		rec := doRequest(t, handler, tt.method, tt.path, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, tt.method+" "+tt.path)
+	}
+}
+
+// closedLegacyCfg is the test config with the migration window for id-addressed
+// calendar feeds already shut.
+func closedLegacyCfg() *config.Config {
+	cfg := *apiTestCfg
+	server := config.ServerConfig{}
+	if cfg.Server != nil {
+		server = *cfg.Server
+	}
+	server.LegacyCalendarLinksUntil = "2000-01-01"
+	cfg.Server = &server
+	return &cfg
+}
+
+// A feed asked for by volunteer id answers while the window is open and not
+// once it has closed.
+func TestCalendarEndpoint_LegacyWindow(t *testing.T) {
+	rec := doRequest(t, newTestHandler(&mockStore{}, testVolunteers()), http.MethodGet, "/calendars/alice.ics", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	closed := newTestHandlerWithConfig(&mockStore{}, testVolunteers(), closedLegacyCfg())
+	rec = doRequest(t, closed, http.MethodGet, "/calendars/alice.ics", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
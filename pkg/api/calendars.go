@@ -2,7 +2,9 @@ package api
 
 import (
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -10,14 +12,30 @@ import (
 	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
 )
 
+// handleCalendar is one volunteer's feed, addressed by their calendar token —
+// or, while the migration window is open, by their volunteer id, the way every
+// feed was before there were tokens.
 func (h *Handler) handleCalendar(w http.ResponseWriter, r *http.Request) {
 	filename := r.PathValue("filename")
-	volunteerID, ok := strings.CutSuffix(filename, ".ics")
-	if !ok || volunteerID == "" {
+	name, ok := strings.CutSuffix(filename, ".ics")
+	if !ok || name == "" {
 		h.writeError(w, http.StatusNotFound, "calendar not found")
 		return
 	}
 
+	volunteerID, legacy, err := services.ResolveCalendarFeed(r.Context(), h.store, h.cfg, name, time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	if legacy {
+		// Warn, not Info: each of these is a subscription that breaks when the
+		// window closes, and the volunteer id is who to hand a new link to.
+		h.logger.Warn("Calendar fetched by volunteer id rather than token",
+			zap.String("volunteer_id", volunteerID),
+			zap.String("user_agent", r.UserAgent()))
+	}
+
 	roles, err := services.RoleTable(r.Context(), h.store)
 	if err != nil {
 		h.writeServiceError(w, err)
@@ -56,7 +74,7 @@ func (h *Handler) handleCalendar(w http.ResponseWriter, r *http.Request) {
 	}
 
 	calendar, err := services.BuildVolunteerCalendar(
-		services.FilterShiftsByVolunteer(shifts, volunteerID),
+		services.VolunteerCalendarShifts(shifts, volunteerID, time.Now()),
 		*volunteer,
 		roles,
 		defaults,
@@ -67,6 +85,82 @@ func (h *Handler) handleCalendar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.writeCalendar(w, filename, calendar)
+}
+
+// handleDropInCalendar is every open shift and who is on it, for whoever keeps
+// the whole rota in their calendar rather than their own evenings of it.
+func (h *Handler) handleDropInCalendar(w http.ResponseWriter, r *http.Request) {
+	roles, shifts, defaults, ok := h.calendarShifts(w, r)
+	if !ok {
+		return
+	}
+
+	calendar, err := services.BuildDropInCalendar(shifts, roles, defaults, siteURL(r))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeCalendar(w, "all.ics", calendar)
+}
+
+// handleRoleCalendar is the shifts that need one Role, addressed by the Role's
+// ID so a subscription survives the Role being renamed.
+func (h *Handler) handleRoleCalendar(w http.ResponseWriter, r *http.Request) {
+	filename := r.PathValue("filename")
+	roleID, ok := strings.CutSuffix(filename, ".ics")
+	if !ok || roleID == "" {
+		h.writeError(w, http.StatusNotFound, "calendar not found")
+		return
+	}
+
+	roles, shifts, defaults, ok := h.calendarShifts(w, r)
+	if !ok {
+		return
+	}
+
+	role, found := roles.ByID(roleID)
+	if !found {
+		h.writeError(w, http.StatusNotFound, "role not found")
+		return
+	}
+
+	calendar, err := services.BuildRoleCalendar(shifts, role, roles, defaults, siteURL(r))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeCalendar(w, filename, calendar)
+}
+
+// calendarShifts reads what a feed that is not one volunteer's is built from:
+// the Roles, every shift, and the settings the shifts' times are read in. A
+// failure has been written to w when ok is false.
+func (h *Handler) calendarShifts(w http.ResponseWriter, r *http.Request) (model.Roles, []services.Shift, model.RotaDefaults, bool) {
+	roles, err := services.RoleTable(r.Context(), h.store)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return model.Roles{}, nil, model.RotaDefaults{}, false
+	}
+
+	shifts, err := services.ListShifts(r.Context(), h.store, h.volunteers, h.cfg, services.ListShiftsParams{}, h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return model.Roles{}, nil, model.RotaDefaults{}, false
+	}
+
+	defaults, err := services.RotaDefaults(r.Context(), h.store)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return model.Roles{}, nil, model.RotaDefaults{}, false
+	}
+
+	return roles, shifts, defaults, true
+}
+
+func (h *Handler) writeCalendar(w http.ResponseWriter, filename, calendar string) {
 	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
 	w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
 	if _, err := w.Write([]byte(calendar)); err != nil {
@@ -74,6 +168,13 @@ func (h *Handler) handleCalendar(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// calendarLink is a volunteer's calendar feed, absolute, for an email or an
+// admin to hand them. name is their calendar token, or their volunteer id for
+// the legacy address.
+func calendarLink(r *http.Request, name string) string {
+	return siteURL(r) + "/calendars/" + url.PathEscape(name) + ".ics"
+}
+
 func findVolunteerByID(volunteers []model.Volunteer, id string) *model.Volunteer {
 	for i := range volunteers {
 		if volunteers[i].ID == id {
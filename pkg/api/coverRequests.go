@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// createCoverRequestRequest is an admin asking for cover. replacing is the
+// volunteer dropping out, left out when the shift is simply short; role may be
+// left out alongside it, to mean theirs.
+type createCoverRequestRequest struct {
+	ShiftID   string `json:"shiftId"`
+	Role      string `json:"role"`
+	Replacing string `json:"replacing"`
+	Reason    string `json:"reason"`
+}
+
+type coverAnswerResponse struct {
+	VolunteerID   string `json:"volunteerId"`
+	VolunteerName string `json:"volunteerName"`
+	AnsweredAt    string `json:"answeredAt"`
+}
+
+// coverRequestResponse is one cover request as the admins' list shows it.
+// yes is always a list, never null: nobody having said yes yet is the
+// ordinary state of a request just made.
+type coverRequestResponse struct {
+	ID            string                `json:"id"`
+	ShiftID       string                `json:"shiftId"`
+	Date          string                `json:"date"`
+	Start         string                `json:"start"`
+	End           string                `json:"end"`
+	Role          string                `json:"role"`
+	Replacing     string                `json:"replacing,omitempty"`
+	ReplacingName string                `json:"replacingName,omitempty"`
+	Reason        string                `json:"reason"`
+	CreatedBy     string                `json:"createdBy"`
+	CreatedAt     string                `json:"createdAt"`
+	ExpiresAt     string                `json:"expiresAt"`
+	Expired       bool                  `json:"expired"`
+	Status        string                `json:"status"`
+	Asked         int                   `json:"asked"`
+	Emailed       int                   `json:"emailed"`
+	No            int                   `json:"no"`
+	Yes           []coverAnswerResponse `json:"yes"`
+}
+
+// coverFormResponse is what is behind a volunteer's cover link. answer is
+// absent until they have given one.
+type coverFormResponse struct {
+	FirstName string `json:"firstName"`
+	Date      string `json:"date"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Role      string `json:"role"`
+	Answer    string `json:"answer,omitempty"`
+}
+
+type coverAnswerRequest struct {
+	Answer string `json:"answer"`
+}
+
+// coverRequestLink builds the absolute URL of a volunteer's cover link, for
+// an email to hand them, as availabilityLink does theirs.
+func coverRequestLink(r *http.Request, token string) string {
+	return siteURL(r) + "/cover/" + url.PathEscape(token)
+}
+
+// handleCreateCoverRequest asks everyone who could cover a place whether they
+// can. It mints their links and emails nobody: the emails are a send, started
+// at /auth/gmail?mode=cover-request with the id this answers with.
+func (h *Handler) handleCreateCoverRequest(w http.ResponseWriter, r *http.Request) {
+	var req createCoverRequestRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	view, err := services.CreateCoverRequest(r.Context(), h.store, h.volunteers, h.cfg, services.CoverRequestParams{
+		ShiftID:    req.ShiftID,
+		Role:       req.Role,
+		Replacing:  req.Replacing,
+		Reason:     req.Reason,
+		AdminEmail: adminEmail(r.Context()),
+	}, time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, toCoverRequestResponse(*view))
+}
+
+// handleListCoverRequests is every open cover request, with who has said yes.
+func (h *Handler) handleListCoverRequests(w http.ResponseWriter, r *http.Request) {
+	views, err := services.ListCoverRequests(r.Context(), h.store, h.volunteers, h.cfg, time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]coverRequestResponse, 0, len(views))
+	for _, v := range views {
+		resp = append(resp, toCoverRequestResponse(v))
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleAcceptCoverAnswer puts a volunteer who said yes on the shift. It
+// answers as POST /alterations does, because it is one: the Cover is the
+// record of the change, and the admin who accepted is who made it.
+func (h *Handler) handleAcceptCoverAnswer(w http.ResponseWriter, r *http.Request) {
+	result, err := services.FillCoverRequest(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("id"), r.PathValue("volunteerId"), adminEmail(r.Context()), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, createAlterationResponse{
+		CoverID:     result.CoverID,
+		Alterations: toAlterationResponses(result.Alterations, result.DatesByShiftID),
+	})
+}
+
+// handleCancelCoverRequest stops asking. Its links stop answering.
+func (h *Handler) handleCancelCoverRequest(w http.ResponseWriter, r *http.Request) {
+	if err := services.CancelCoverRequest(r.Context(), h.store, r.PathValue("id"), adminEmail(r.Context()), h.logger); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCoverForm serves a volunteer's cover link: the shift, and what they
+// have said. Public, for the reason the availability form is — the link is
+// the identity. A link whose request has closed or whose shift has started
+// answers 410 with the reason, which the page shows as it is.
+func (h *Handler) handleCoverForm(w http.ResponseWriter, r *http.Request) {
+	form, err := services.GetCoverRequestForm(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toCoverFormResponse(form))
+}
+
+// handleAnswerCover records a yes or a no on a volunteer's cover link.
+func (h *Handler) handleAnswerCover(w http.ResponseWriter, r *http.Request) {
+	var req coverAnswerRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	form, err := services.AnswerCoverRequest(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), req.Answer, time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toCoverFormResponse(form))
+}
+
+func toCoverRequestResponse(v services.CoverRequestView) coverRequestResponse {
+	resp := coverRequestResponse{
+		ID:            v.ID,
+		ShiftID:       v.ShiftID,
+		Date:          v.Date,
+		Start:         v.Start,
+		End:           v.End,
+		Role:          v.Role,
+		Replacing:     v.Replacing,
+		ReplacingName: v.ReplacingName,
+		Reason:        v.Reason,
+		CreatedBy:     v.CreatedBy,
+		CreatedAt:     v.CreatedAt.UTC().Format(time.RFC3339),
+		ExpiresAt:     v.ExpiresAt.UTC().Format(time.RFC3339),
+		Expired:       v.Expired,
+		Status:        v.Status,
+		Asked:         v.Asked,
+		Emailed:       v.Emailed,
+		No:            v.No,
+		Yes:           make([]coverAnswerResponse, 0, len(v.Yes)),
+	}
+	for _, a := range v.Yes {
+		resp.Yes = append(resp.Yes, coverAnswerResponse{
+			VolunteerID:   a.VolunteerID,
+			VolunteerName: a.VolunteerName,
+			AnsweredAt:    a.AnsweredAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return resp
+}
+
+func toCoverFormResponse(form *services.CoverRequestForm) coverFormResponse {
+	return coverFormResponse{
+		FirstName: form.FirstName,
+		Date:      form.Date,
+		Start:     form.Start,
+		End:       form.End,
+		Role:      form.Role,
+		Answer:    form.Answer,
+	}
+}
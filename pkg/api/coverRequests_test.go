@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The cover-request methods of mockStore, holding the requests and their
+// links in memory with the conditions the real updates carry.
+func (m *mockStore) coverRequest(id string) *db.CoverRequest {
+	for i := range m.coverRequests {
+		if m.coverRequests[i].ID == id {
+			return &m.coverRequests[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) coverToken(token string) *db.CoverRequestToken {
+	for i := range m.coverTokens {
+		if m.coverTokens[i].Token == token {
+			return &m.coverTokens[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) InsertCoverRequest(_ context.Context, req db.CoverRequest, tokens []db.CoverRequestToken) error {
+	if m.insertErr != nil {
+		return m.insertErr
+	}
+	for _, r := range m.coverRequests {
+		if r.ShiftID == req.ShiftID && r.Role == req.Role && r.Replacing == req.Replacing && r.Status == db.CoverRequestOpen {
+			return db.ErrDuplicateCoverRequest
+		}
+	}
+	m.coverRequests = append(m.coverRequests, req)
+	m.coverTokens = append(m.coverTokens, tokens...)
+	return nil
+}
+
+func (m *mockStore) GetCoverRequest(_ context.Context, id string) (*db.CoverRequest, error) {
+	if req := m.coverRequest(id); req != nil {
+		found := *req
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *mockStore) GetCoverRequestsByStatus(_ context.Context, statuses []string) ([]db.CoverRequest, error) {
+	want := idSet(statuses)
+	var out []db.CoverRequest
+	for _, r := range m.coverRequests {
+		if want[r.Status] {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) GetCoverRequestToken(_ context.Context, token string) (*db.CoverRequestToken, error) {
+	if t := m.coverToken(token); t != nil {
+		found := *t
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *mockStore) GetCoverRequestTokens(_ context.Context, coverRequestID string) ([]db.CoverRequestToken, error) {
+	var out []db.CoverRequestToken
+	for _, t := range m.coverTokens {
+		if t.CoverRequestID == coverRequestID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) AnswerCoverRequest(_ context.Context, token, answer string) error {
+	t := m.coverToken(token)
+	if t == nil {
+		return db.ErrCoverRequestNotOpen
+	}
+	now := time.Now()
+	t.Answer, t.AnsweredAt = answer, &now
+	return nil
+}
+
+func (m *mockStore) MarkCoverRequestTokenSent(_ context.Context, token string) error {
+	if t := m.coverToken(token); t != nil {
+		now := time.Now()
+		t.SentAt = &now
+	}
+	return nil
+}
+
+func (m *mockStore) CancelCoverRequest(_ context.Context, id, adminEmail string) (bool, error) {
+	req := m.coverRequest(id)
+	if req == nil || req.Status != db.CoverRequestOpen {
+		return false, nil
+	}
+	now := time.Now()
+	req.Status, req.ClosedBy, req.ClosedAt = db.CoverRequestCancelled, adminEmail, &now
+	return true, nil
+}
+
+func (m *mockStore) FillCoverRequest(_ context.Context, id, coverID, adminEmail string) error {
+	req := m.coverRequest(id)
+	if req == nil || req.Status != db.CoverRequestOpen {
+		return db.ErrCoverRequestNotOpen
+	}
+	now := time.Now()
+	req.Status, req.CoverID, req.ClosedBy, req.ClosedAt = db.CoverRequestFilled, coverID, adminEmail, &now
+	return nil
+}
+
+// coverTestShiftID is a UUID, because a cover request is made against a
+// shift's id and Postgres would refuse anything else.
+const coverTestShiftID = "6a1f2e3d-4c5b-4a69-8877-665544332211"
+
+// coverTestStore is an allocated rota with one shift a week from now: Alice
+// leads it and Bob serves beside her. The date is relative because the
+// handlers read the real clock, and cover is only asked for a shift to come.
+func coverTestStore() *mockStore {
+	date := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	return &mockStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: date, End: date, ShiftCount: 1, AllocatedDatetime: "2026-01-01T09:00:00Z"}},
+		shifts:    []db.Shift{{ID: coverTestShiftID, RotaID: "rota-1", Date: date}},
+		allocations: []db.Allocation{
+			{ID: "a1", ShiftID: coverTestShiftID, VolunteerID: "alice", Role: "Team lead"},
+			{ID: "a2", ShiftID: coverTestShiftID, VolunteerID: "bob", Role: "Service volunteer"},
+		},
+	}
+}
+
+// TestCoverRequestLifecycle: an admin asks, the one volunteer who could
+// cover says yes on their link, and accepting it changes the rota and closes
+// the link.
+func TestCoverRequestLifecycle(t *testing.T) {
+	store := coverTestStore()
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/cover-requests",
+		`{"shiftId":"`+coverTestShiftID+`","replacing":"bob","reason":"Bob is away"}`, adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created coverRequestResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "Service volunteer", created.Role, "the Role is Bob's")
+	assert.Equal(t, "Bob Barnes", created.ReplacingName)
+	assert.Equal(t, 1, created.Asked, "only Charlie: Alice is on the shift already")
+	assert.NotNil(t, created.Yes, "nobody yet is an empty list, not null")
+
+	require.Len(t, store.coverTokens, 1)
+	token := store.coverTokens[0].Token
+	assert.Equal(t, "charlie", store.coverTokens[0].VolunteerID)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/cover/"+token, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var form coverFormResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &form))
+	assert.Equal(t, "Charlie", form.FirstName)
+	assert.Empty(t, form.Answer)
+
+	rec = doRequest(t, handler, http.MethodPost, "/api/cover/"+token, `{"answer":"yes"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &form))
+	assert.Equal(t, "yes", form.Answer)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/cover-requests", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var listed []coverRequestResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	require.Len(t, listed[0].Yes, 1)
+	assert.Equal(t, "Charlie Cole", listed[0].Yes[0].VolunteerName)
+
+	rec = doRequest(t, handler, http.MethodPost, "/api/cover-requests/"+created.ID+"/answers/charlie/acceptance", "", adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var changed createAlterationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &changed))
+	assert.NotEmpty(t, changed.CoverID)
+	require.NotNil(t, store.insertedCover)
+	assert.Equal(t, testAdminEmail, store.insertedCover.UserEmail)
+	assert.Equal(t, db.CoverRequestFilled, store.coverRequests[0].Status)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/cover/"+token, "")
+	assert.Equal(t, http.StatusGone, rec.Code, "a covered shift's links stop answering")
+	assert.Contains(t, rec.Body.String(), "covered")
+}
+
+func TestCancelCoverRequest(t *testing.T) {
+	store := coverTestStore()
+	store.coverRequests = []db.CoverRequest{{
+		ID: "2f9d3c1b-0000-4000-8000-000000000001", ShiftID: coverTestShiftID, Role: "Service volunteer",
+		Reason: "Short", Status: db.CoverRequestOpen, ExpiresAt: time.Now().Add(24 * time.Hour),
+	}}
+	store.coverTokens = []db.CoverRequestToken{{Token: "cover-charlie", CoverRequestID: store.coverRequests[0].ID, VolunteerID: "charlie"}}
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodDelete, "/api/cover-requests/"+store.coverRequests[0].ID, "", adminCookie())
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, db.CoverRequestCancelled, store.coverRequests[0].Status)
+	assert.Equal(t, testAdminEmail, store.coverRequests[0].ClosedBy)
+
+	rec = doRequest(t, handler, http.MethodPost, "/api/cover/cover-charlie", `{"answer":"yes"}`)
+	assert.Equal(t, http.StatusGone, rec.Code, rec.Body.String())
+	assert.Empty(t, store.coverTokens[0].Answer)
+}
+
+// TestCoverRequestRefusals: what the endpoints are told when they ask for
+// something they cannot have.
+func TestCoverRequestRefusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		admin    bool
+		wantCode int
+	}{
+		{name: "no reason", method: http.MethodPost, target: "/api/cover-requests", body: `{"shiftId":"` + coverTestShiftID + `","replacing":"bob"}`, admin: true, wantCode: http.StatusBadRequest},
+		{name: "unknown shift", method: http.MethodPost, target: "/api/cover-requests", body: `{"shiftId":"shift-9","role":"Team lead","reason":"x"}`, admin: true, wantCode: http.StatusNotFound},
+		{name: "replacing somebody not on", method: http.MethodPost, target: "/api/cover-requests", body: `{"shiftId":"` + coverTestShiftID + `","replacing":"charlie","reason":"x"}`, admin: true, wantCode: http.StatusConflict},
+		{name: "unknown field", method: http.MethodPost, target: "/api/cover-requests", body: `{"shiftId":"` + coverTestShiftID + `","volunteer":"bob","reason":"x"}`, admin: true, wantCode: http.StatusBadRequest},
+		{name: "unknown link", method: http.MethodGet, target: "/api/cover/tok-nobody", wantCode: http.StatusNotFound},
+		{name: "no such request", method: http.MethodDelete, target: "/api/cover-requests/2f9d3c1b-0000-4000-8000-000000000009", admin: true, wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := coverTestStore()
+			var cookies []*http.Cookie
+			if tt.admin {
+				cookies = append(cookies, adminCookie())
+			}
+			rec := doRequest(t, newTestHandler(store, testVolunteers()), tt.method, tt.target, tt.body, cookies...)
+
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Empty(t, store.coverRequests)
+		})
+	}
+}
+
+func TestCoverRequestAdminEndpointsAreAdminOnly(t *testing.T) {
+	handler := newTestHandler(coverTestStore(), testVolunteers())
+
+	for _, tt := range []struct{ method, target string }{
+		{http.MethodGet, "/api/cover-requests"},
+		{http.MethodPost, "/api/cover-requests"},
+		{http.MethodDelete, "/api/cover-requests/cover-1"},
+		{http.MethodPost, "/api/cover-requests/cover-1/answers/bob/acceptance"},
+	} {
+		rec := doRequest(t, handler, tt.method, tt.target, `{}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, tt.method+" "+tt.target)
+	}
+}
@@ -61,6 +61,39 @@ type draftRotaAllocationResponse struct {
 	// anybody on. Never null, so a rota nobody has solved for and one the solver
 	// could staff nobody on both read as an empty list rather than an absence.
 	Shifts []draftShiftResponse `json:"shifts"`
+	// EmptySeats says why each Seat the draft left empty was left empty, so
+	// "four Seats unfilled" comes with who to chase or which rule to relax.
+	// Never null, for the same reason as Shifts.
+	EmptySeats []emptySeatResponse `json:"emptySeats"`
+	// Conflict is why an INFEASIBLE draft found no rota, or null — for a rota
+	// that solved, and for an infeasible one the solver could not narrow down.
+	Conflict *conflictResponse `json:"conflict"`
+}
+
+// conflictResponse is the pins and rules that cannot all hold, and the
+// sentence that says so.
+type conflictResponse struct {
+	Constraints    []string              `json:"constraints"`
+	Preallocations []conflictPinResponse `json:"preallocations"`
+	Description    string                `json:"description"`
+}
+
+type conflictPinResponse struct {
+	ShiftID     string `json:"shiftId"`
+	Date        string `json:"date"`
+	VolunteerID string `json:"volunteerId"`
+	Name        string `json:"name"`
+	Role        string `json:"role"`
+}
+
+type emptySeatResponse struct {
+	ShiftID string `json:"shiftId"`
+	Role    string `json:"role"`
+	Count   int    `json:"count"`
+	// Reason is a stable code for a client to branch on; Detail is it as a
+	// sentence, for a client that does not.
+	Reason string `json:"reason"`
+	Detail string `json:"detail"`
 }
 
 type draftShiftResponse struct {
@@ -224,6 +257,8 @@ func draftStatus(status *services.DraftRotaAllocationStatus) draftRotaAllocation
 		Hash:             status.Hash,
 		SolveTimeSeconds: status.Diagnostics.SolveTimeSeconds,
 		Shifts:           draftShifts(status.Shifts),
+		EmptySeats:       emptySeats(status.EmptySeats),
+		Conflict:         conflict(status.Conflict),
 	}
 	// An unsolved rota carries no time, rather than the zero time formatted as
 	// the year 1: there is no moment to report.
@@ -251,3 +286,40 @@ func draftShifts(shifts []services.DraftShift) []draftShiftResponse {
 	}
 	return out
 }
+
+// emptySeats is the wire form of a draft's explained empty Seats.
+func emptySeats(seats []services.EmptySeat) []emptySeatResponse {
+	out := make([]emptySeatResponse, 0, len(seats))
+	for _, seat := range seats {
+		out = append(out, emptySeatResponse{
+			ShiftID: seat.ShiftID,
+			Role:    seat.Role,
+			Count:   seat.Count,
+			Reason:  seat.Reason,
+			Detail:  seat.Detail,
+		})
+	}
+	return out
+}
+
+// conflict is the wire form of an infeasible draft's conflict.
+func conflict(c *services.RotaConflict) *conflictResponse {
+	if c == nil {
+		return nil
+	}
+	pins := make([]conflictPinResponse, 0, len(c.Preallocations))
+	for _, pin := range c.Preallocations {
+		pins = append(pins, conflictPinResponse{
+			ShiftID:     pin.ShiftID,
+			Date:        pin.Date,
+			VolunteerID: pin.VolunteerID,
+			Name:        pin.Name,
+			Role:        pin.Role,
+		})
+	}
+	return &conflictResponse{
+		Constraints:    append([]string{}, c.Constraints...),
+		Preallocations: pins,
+		Description:    c.Description,
+	}
+}
@@ -57,6 +57,7 @@ func draftedRotaStore() *mockStore {
 			Success:         true,
 			SolverStatus:    "OPTIMAL",
 			Diagnostics:     []byte(`{}`),
+			EmptySeats:      []byte(`[{"shift_id":"shift-2","role":"Team lead","count":1,"reason":"max_frequency","detail":"Every available holder of Team lead already has the most shifts one person may work (1)."}]`),
 			InputsChangedAt: moved,
 			SeatsAsked:      10,
 			SeatsFilled:     2,
@@ -87,6 +88,17 @@ func TestGetDraftRotaAllocationReportsTheRotaItDrafted(t *testing.T) {
 	assert.Equal(t, "Bob", body.Shifts[0].Assignees[1].Name)
 	assert.Equal(t, 2, body.SeatsFilled)
 	assert.Equal(t, 10, body.SeatsAsked)
+	assert.Nil(t, body.Conflict, "a rota that solved has no conflict")
+
+	// And why the rest were left empty, keyed by Shift like the Seats filled.
+	require.Len(t, body.EmptySeats, 1)
+	assert.Equal(t, emptySeatResponse{
+		ShiftID: "shift-2",
+		Role:    "Team lead",
+		Count:   1,
+		Reason:  "max_frequency",
+		Detail:  "Every available holder of Team lead already has the most shifts one person may work (1).",
+	}, body.EmptySeats[0])
 }
 
 // A solve that staffed nobody — an infeasible one — is a draft with no Shifts
@@ -97,6 +109,10 @@ func TestGetDraftRotaAllocationWithAnInfeasibleDraft(t *testing.T) {
 	store.storedDrafts[0].Success = false
 	store.storedDrafts[0].SolverStatus = "INFEASIBLE"
 	store.storedDrafts[0].SeatsFilled = 0
+	store.storedDrafts[0].EmptySeats = []byte(`[]`)
+	store.storedDrafts[0].Diagnostics = []byte(`{"conflict":{"constraints":["no_back_to_back"],"preallocations":[` +
+		`{"shift_index":0,"date":"2026-08-02","volunteer_id":"alice","group_key":"Alice Adams","role":"Team lead"},` +
+		`{"shift_index":1,"date":"2026-08-09","volunteer_id":"alice","group_key":"Alice Adams","role":"Team lead"}]}}`)
 	store.draftSeats = nil
 
 	rec := doRequest(t, newTestHandler(store, testVolunteers()), http.MethodGet, "/api/draft-rota-allocation", "", adminCookie())
@@ -109,6 +125,16 @@ func TestGetDraftRotaAllocationWithAnInfeasibleDraft(t *testing.T) {
 	assert.Equal(t, "INFEASIBLE", body.SolverStatus)
 	assert.NotNil(t, body.Shifts)
 	assert.Empty(t, body.Shifts)
+	assert.NotNil(t, body.EmptySeats)
+	assert.Empty(t, body.EmptySeats, "no rota, so no gaps in one to explain")
+
+	// And why: the pins and the rule that cannot all hold, as something to undo.
+	require.NotNil(t, body.Conflict)
+	assert.Equal(t, []string{"no_back_to_back"}, body.Conflict.Constraints)
+	assert.Equal(t, "Preallocations of Alice on 2026-08-02 and Alice on 2026-08-09 conflict with no_back_to_back", body.Conflict.Description)
+	require.Len(t, body.Conflict.Preallocations, 2)
+	assert.Equal(t, "shift-1", body.Conflict.Preallocations[0].ShiftID)
+	assert.Equal(t, "Alice", body.Conflict.Preallocations[0].Name)
 }
 
 // A draft solved from the inputs as they stand is reported, not re-solved. This
@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// emailTemplateResponse is one email an admin can reword, and how it reads
+// now. Customised says whether that is an admin's wording or the default, which
+// is what decides whether the screen offers to put the default back.
+type emailTemplateResponse struct {
+	Kind        string `json:"kind"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Subject     string `json:"subject"`
+	Text        string `json:"text"`
+	HTML        string `json:"html"`
+	Customised  bool   `json:"customised"`
+}
+
+// emailTemplateVariable is one thing wording can say, listed beside the editor.
+type emailTemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// emailTemplateRequest is one email's wording, stated whole: a subject and a
+// text body with no HTML part is a wording in its own right, not a partial one.
+type emailTemplateRequest struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+// emailPreviewRequest is wording to try out on one volunteer. The deadline is
+// optional — it is only ever chosen at send time — and so is the rota, which
+// means the latest.
+type emailPreviewRequest struct {
+	emailTemplateRequest
+	VolunteerID string `json:"volunteerId"`
+	RotaID      string `json:"rotaId"`
+	Deadline    string `json:"deadline"`
+}
+
+// emailPreviewResponse is the email as that volunteer would receive it.
+type emailPreviewResponse struct {
+	To            string `json:"to"`
+	VolunteerName string `json:"volunteerName"`
+	Subject       string `json:"subject"`
+	Text          string `json:"text"`
+	HTML          string `json:"html"`
+}
+
+func toEmailTemplateResponses(saved model.EmailTemplates) []emailTemplateResponse {
+	out := make([]emailTemplateResponse, 0, len(model.EmailTemplateKinds))
+	for _, kind := range model.EmailTemplateKinds {
+		template, customised := saved.For(kind.Name)
+		out = append(out, toEmailTemplateResponse(kind, template, customised))
+	}
+	return out
+}
+
+func toEmailTemplateResponse(kind model.EmailTemplateKind, template model.EmailTemplate, customised bool) emailTemplateResponse {
+	return emailTemplateResponse{
+		Kind:        kind.Name,
+		Label:       kind.Label,
+		Description: kind.Description,
+		Subject:     template.Subject,
+		Text:        template.Text,
+		HTML:        template.HTML,
+		Customised:  customised,
+	}
+}
+
+func toEmailTemplateVariables() []emailTemplateVariable {
+	out := make([]emailTemplateVariable, 0, len(model.EmailTemplateVariables))
+	for _, v := range model.EmailTemplateVariables {
+		out = append(out, emailTemplateVariable{Name: v.Name, Description: v.Description})
+	}
+	return out
+}
+
+// handleSaveEmailTemplate writes one email's wording and answers with that
+// email as it now reads. Wording that names a variable there is not, or leaves
+// out the volunteer's link, is refused with the reason.
+func (h *Handler) handleSaveEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	var req emailTemplateRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	kind := r.PathValue("kind")
+	template, err := services.SaveEmailTemplate(r.Context(), h.store, kind, services.EmailTemplateParams{
+		Subject: req.Subject,
+		Text:    req.Text,
+		HTML:    req.HTML,
+	}, h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeEmailTemplate(w, kind, template, true)
+}
+
+// handleResetEmailTemplate puts one email back to its default wording and
+// answers with that wording.
+func (h *Handler) handleResetEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	kind := r.PathValue("kind")
+	template, err := services.ResetEmailTemplate(r.Context(), h.store, kind, h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeEmailTemplate(w, kind, template, false)
+}
+
+func (h *Handler) writeEmailTemplate(w http.ResponseWriter, kindName string, template model.EmailTemplate, customised bool) {
+	// The service has already refused a kind there is not, so this finds it.
+	kind, _ := model.FindEmailTemplateKind(kindName)
+	h.writeJSON(w, http.StatusOK, toEmailTemplateResponse(kind, template, customised))
+}
+
+// handlePreviewEmailTemplate fills wording in for one volunteer on the roster,
+// with their real link, and sends nothing. A POST because it carries the
+// wording being tried, which need not have been saved.
+func (h *Handler) handlePreviewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	var req emailPreviewRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	// A preview writes nothing, so it mints no calendar token: a volunteer who
+	// has none is shown the link they would have had before there were any,
+	// and the send itself mints theirs.
+	calendarTokens, err := services.CalendarTokensByVolunteer(r.Context(), h.store)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	preview, err := services.PreviewEmailTemplate(r.Context(), h.store, h.volunteers, h.cfg, services.EmailPreviewParams{
+		Kind: r.PathValue("kind"),
+		Template: services.EmailTemplateParams{
+			Subject: req.Subject,
+			Text:    req.Text,
+			HTML:    req.HTML,
+		},
+		VolunteerID: req.VolunteerID,
+		RotaID:      req.RotaID,
+		Deadline:    req.Deadline,
+		Link:        func(token string) string { return availabilityLink(r, token) },
+		CoverLink:   func(token string) string { return coverRequestLink(r, token) },
+		SwapLink:    func(token string) string { return swapLink(r, token) },
+		CalendarLink: func(volunteerID string) string {
+			if token, ok := calendarTokens[volunteerID]; ok {
+				return calendarLink(r, token)
+			}
+			return calendarLink(r, volunteerID)
+		},
+	})
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, emailPreviewResponse{
+		To:            preview.To,
+		VolunteerName: preview.VolunteerName,
+		Subject:       preview.Subject,
+		Text:          preview.Text,
+		HTML:          preview.HTML,
+	})
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The email-template methods of mockStore. Like the other sections they write
+// through to what the next read of the settings sees.
+func (m *mockStore) SaveEmailTemplate(_ context.Context, kind, template string) error {
+	if m.rotaDefaultsWriteErr != nil {
+		return m.rotaDefaultsWriteErr
+	}
+	return m.editEmailTemplates(func(templates map[string]json.RawMessage) {
+		templates[kind] = json.RawMessage(template)
+	})
+}
+
+func (m *mockStore) ResetEmailTemplate(_ context.Context, kind string) error {
+	if m.rotaDefaultsWriteErr != nil {
+		return m.rotaDefaultsWriteErr
+	}
+	return m.editEmailTemplates(func(templates map[string]json.RawMessage) {
+		delete(templates, kind)
+	})
+}
+
+func (m *mockStore) editEmailTemplates(edit func(map[string]json.RawMessage)) error {
+	updated := apiTestRotaDefaults
+	if m.rotaDefaults != nil {
+		updated = *m.rotaDefaults
+	}
+	templates := map[string]json.RawMessage{}
+	if updated.EmailTemplates != "" {
+		if err := json.Unmarshal([]byte(updated.EmailTemplates), &templates); err != nil {
+			return err
+		}
+	}
+	edit(templates)
+	document, err := json.Marshal(templates)
+	if err != nil {
+		return err
+	}
+	updated.EmailTemplates = string(document)
+	m.rotaDefaults = &updated
+	return nil
+}
+
+func emailTemplatesOf(t *testing.T, body []byte) map[string]emailTemplateResponse {
+	t.Helper()
+	var resp rotaDefaultsResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	out := make(map[string]emailTemplateResponse, len(resp.EmailTemplates))
+	for _, template := range resp.EmailTemplates {
+		out[template.Kind] = template
+	}
+	return out
+}
+
+// TestEmailTemplatesRoundTrip: the settings list every email with the wording
+// it goes out with, a save replaces one, and a reset puts its default back.
+func TestEmailTemplatesRoundTrip(t *testing.T) {
+	handler := newTestHandler(&mockStore{}, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/rota-defaults", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	templates := emailTemplatesOf(t, rec.Body.Bytes())
+	require.Contains(t, templates, "round")
+	require.Contains(t, templates, "reminder")
+	assert.False(t, templates["round"].Customised)
+	assert.Contains(t, templates["round"].Text, "{{.Link}}")
+
+	rec = doRequest(t, handler, http.MethodPut, "/api/rota-defaults/email-templates/round",
+		`{"subject":"Your link","text":"Hi {{.FirstName}} {{.Link}}","html":""}`, adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var saved emailTemplateResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &saved))
+	assert.Equal(t, "Your link", saved.Subject)
+	assert.True(t, saved.Customised)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/rota-defaults", "", adminCookie())
+	templates = emailTemplatesOf(t, rec.Body.Bytes())
+	assert.Equal(t, "Your link", templates["round"].Subject)
+	assert.False(t, templates["reminder"].Customised, "saving one email leaves the others alone")
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/rota-defaults/email-templates/round", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/rota-defaults", "", adminCookie())
+	templates = emailTemplatesOf(t, rec.Body.Bytes())
+	assert.False(t, templates["round"].Customised)
+}
+
+func TestSaveEmailTemplateRefusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{name: "misspelled variable", target: "/api/rota-defaults/email-templates/round", body: `{"subject":"S","text":"{{.FristName}} {{.Link}}"}`, wantCode: http.StatusBadRequest},
+		{name: "no link", target: "/api/rota-defaults/email-templates/reminder", body: `{"subject":"S","text":"Hello"}`, wantCode: http.StatusBadRequest},
+		{name: "unknown field", target: "/api/rota-defaults/email-templates/round", body: `{"subject":"S","text":"{{.Link}}","footer":"x"}`, wantCode: http.StatusBadRequest},
+		{name: "unknown email", target: "/api/rota-defaults/email-templates/newsletter", body: `{"subject":"S","text":"{{.Link}}"}`, wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockStore{}
+			rec := doRequest(t, newTestHandler(store, testVolunteers()), http.MethodPut, tt.target, tt.body, adminCookie())
+
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Nil(t, store.rotaDefaults, "nothing is saved")
+		})
+	}
+}
+
+func TestEmailTemplateEndpointsAreAdminOnly(t *testing.T) {
+	handler := newTestHandler(&mockStore{}, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodPut, "/api/rota-defaults/email-templates/round", `{"subject":"S","text":"{{.Link}}"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doRequest(t, handler, http.MethodDelete, "/api/rota-defaults/email-templates/round", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	rec = doRequest(t, handler, http.MethodPost, "/api/rota-defaults/email-templates/round/preview", `{"subject":"S","text":"{{.Link}}","volunteerId":"bob"}`)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// TestPreviewEmailTemplate: a preview is the unsaved wording filled in for a
+// real volunteer, link and all, with nothing sent or saved.
+func TestPreviewEmailTemplate(t *testing.T) {
+	store := sendTestStore()
+	volunteers := testVolunteers()
+	volunteers.volunteers[1].Email = "bob@example.com"
+	handler := newTestHandler(store, volunteers)
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/rota-defaults/email-templates/round/preview",
+		`{"subject":"Rota from {{.RotaStart}}","text":"Hi {{.FirstName}} {{.Link}} by {{.Deadline}}","html":"<a href=\"{{.Link}}\">go</a>","volunteerId":"bob","deadline":"Friday"}`,
+		adminCookie())
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var preview emailPreviewResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &preview))
+	assert.Equal(t, "bob@example.com", preview.To)
+	assert.Equal(t, "Rota from 2 August 2026", preview.Subject)
+	assert.Equal(t, "Hi Bob http://example.com/availability/tok-bob by Friday", preview.Text)
+	assert.Equal(t, `<a href="http://example.com/availability/tok-bob">go</a>`, preview.HTML)
+	assert.Nil(t, store.rotaDefaults, "a preview saves nothing")
+}
@@ -11,11 +11,12 @@ import (
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 
+	"github.com/jakechorley/ilford-drop-in/internal/config"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
 	"github.com/jakechorley/ilford-drop-in/pkg/utils"
 )
 
@@ -31,11 +32,27 @@ const gmailStatePrefix = "send."
 const gmailStateMaxAge = 10 * time.Minute
 
 // sendReturnPath is where the browser lands once a send has been started: the
-// Allocation tab, which is where the round is asked from (issue #145). The job
+// Allocation tab, which is where the round is asked from (issue #145). The send
 // id goes in the query so the page can pick the send back up — the redirect
 // returns immediately and the emails go out behind it.
 const sendReturnPath = "/admin/allocation"
 
+// allocationSendReturnPath is where an allocation send lands instead: the rota
+// page, which is where it is asked from. By the time a rota is allocated it has
+// left the Allocation tab, which is defining the next one. A cover send lands
+// there too, beside the change it was about, and so do a cover request send,
+// beside the shift it asks about, and a swap offer send.
+const allocationSendReturnPath = "/"
+
+// sendReturnPathFor is the page a send in mode is watched from.
+func sendReturnPathFor(mode services.SendMode) string {
+	switch mode {
+	case services.SendModeAllocation, services.SendModeCover, services.SendModeCoverRequest, services.SendModeSwapOffer:
+		return allocationSendReturnPath
+	}
+	return sendReturnPath
+}
+
 // gmailSendState is the pending send, carried through Google and back. It is
 // signed rather than stored: the round trip is the only thing that needs to
 // remember it, and a signature makes it unforgeable without a server-side table
@@ -44,12 +61,18 @@ const sendReturnPath = "/admin/allocation"
 // Nothing here is secret — it is the admin's own instruction coming back to
 // them — but every field is authority, so all of it is covered by the signature.
 type gmailSendState struct {
-	Email       string            `json:"email"`
-	Mode        services.SendMode `json:"mode"`
-	RotaID      string            `json:"rotaId,omitempty"`
-	Deadline    string            `json:"deadline"`
-	VolunteerID string            `json:"volunteerId,omitempty"`
-	Expiry      int64             `json:"exp"`
+	Email          string            `json:"email"`
+	Mode           services.SendMode `json:"mode"`
+	RotaID         string            `json:"rotaId,omitempty"`
+	Deadline       string            `json:"deadline"`
+	VolunteerID    string            `json:"volunteerId,omitempty"`
+	CoverID        string            `json:"coverId,omitempty"`
+	CoverRequestID string            `json:"coverRequestId,omitempty"`
+	SwapRequestID  string            `json:"swapRequestId,omitempty"`
+	// ResumeID names an interrupted send to carry on with, in place of the
+	// fields above: the send's record already says what it was.
+	ResumeID string `json:"resumeId,omitempty"`
+	Expiry   int64  `json:"exp"`
 }
 
 // signGmailState encodes a pending send as an OAuth state parameter.
@@ -136,22 +159,35 @@ func (a *Authenticator) gmailOAuthConfig() *oauth2.Config {
 // credential for sixty days that removing someone from adminEmails would not
 // revoke. It would also demand Gmail permission from an admin who only signed in
 // to look at a shift.
+//
+// Resuming an interrupted send comes through here too, as ?resume=<id>. The
+// grant that started it went out of scope with the process that was sending,
+// so carrying on needs a fresh one.
+//
+// Under a transport other than Gmail this is still where every send starts,
+// but there is no grant to ask for, so it goes straight to the send.
 func (h *Handler) handleGmailConsent(w http.ResponseWriter, r *http.Request) {
 	admin := adminEmail(r.Context())
 
 	state := gmailSendState{
-		Email:       admin,
-		Mode:        services.SendMode(r.URL.Query().Get("mode")),
-		RotaID:      r.URL.Query().Get("rotaId"),
-		Deadline:    r.URL.Query().Get("deadline"),
-		VolunteerID: r.URL.Query().Get("volunteerId"),
-		Expiry:      time.Now().Add(gmailStateMaxAge).Unix(),
+		Email:          admin,
+		Mode:           services.SendMode(r.URL.Query().Get("mode")),
+		RotaID:         r.URL.Query().Get("rotaId"),
+		Deadline:       r.URL.Query().Get("deadline"),
+		VolunteerID:    r.URL.Query().Get("volunteerId"),
+		CoverID:        r.URL.Query().Get("coverId"),
+		CoverRequestID: r.URL.Query().Get("coverRequestId"),
+		SwapRequestID:  r.URL.Query().Get("swapRequestId"),
+		Expiry:         time.Now().Add(gmailStateMaxAge).Unix(),
+	}
+	if resume := r.URL.Query().Get("resume"); resume != "" {
+		state = gmailSendState{Email: admin, ResumeID: resume, Expiry: state.Expiry}
 	}
 
 	// Rejected here rather than after the consent screen: sending someone to
 	// Google only to fail on the way back would ask them to approve access for
 	// an action that was never going to run.
-	if err := validateSendState(state); err != nil {
+	if err := h.validateSendState(r.Context(), &state); err != nil {
 		h.writeServiceError(w, err)
 		return
 	}
@@ -160,8 +196,13 @@ func (h *Handler) handleGmailConsent(w http.ResponseWriter, r *http.Request) {
 	// straight to the send keeps the whole flow — the deadline, the job, the
 	// per-volunteer report — exercisable on a checkout with no credentials.
 	if h.auth.isStubbed() {
-		h.auth.logger.Warn("Dev mode: starting an availability send with no Gmail grant and no real mail",
-			zap.String("mode", string(state.Mode)))
+		h.auth.logger.Warn("Dev mode: starting an availability send with no Gmail grant",
+			zap.String("mode", string(state.Mode)),
+			zap.String("transport", h.cfg.MailTransport()))
+		h.startSend(w, r, admin, nil, state)
+		return
+	}
+	if !h.sendNeedsGrant() {
 		h.startSend(w, r, admin, nil, state)
 		return
 	}
@@ -176,15 +217,50 @@ func (h *Handler) handleGmailConsent(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, h.auth.gmailAuthCodeURL(signed, admin), http.StatusFound)
 }
 
+// sendNeedsGrant reports whether a send has to go through Google's consent
+// screen first, which is only when it goes out through the admin's own Gmail.
+// A relay or an outbox sends with what the config gave it.
+func (h *Handler) sendNeedsGrant() bool {
+	return h.cfg.MailTransport() == config.MailTransportGmail
+}
+
 // validateSendState rejects an instruction that could not be carried out, before
-// anyone is asked to approve anything.
-func validateSendState(state gmailSendState) error {
+// anyone is asked to approve anything. A resume is checked against the send's
+// record, which is the only thing that knows whether it has stopped, and takes
+// its mode from there so the browser comes back to the page that watches it.
+func (h *Handler) validateSendState(ctx context.Context, state *gmailSendState) error {
+	if state.ResumeID != "" {
+		send, err := services.ResumableAvailabilitySend(ctx, h.store, state.ResumeID, state.Email, time.Now())
+		if err != nil {
+			return err
+		}
+		state.Mode = services.SendMode(send.Mode)
+		return nil
+	}
 	switch state.Mode {
 	case services.SendModeRound, services.SendModeReminder:
 	case services.SendModeResend:
 		if state.VolunteerID == "" {
 			return wrapInvalid("a resend needs the volunteer to resend to")
 		}
+	case services.SendModeAllocation:
+		// Nothing to answer, so there is no deadline to ask for.
+		return nil
+	case services.SendModeCover:
+		if state.CoverID == "" {
+			return wrapInvalid("a rota change send needs the change it is about")
+		}
+		return nil
+	case services.SendModeCoverRequest:
+		if state.CoverRequestID == "" {
+			return wrapInvalid("a cover request send needs the request it is asking about")
+		}
+		return nil
+	case services.SendModeSwapOffer:
+		if state.SwapRequestID == "" {
+			return wrapInvalid("a swap offer send needs the swap it is offering")
+		}
+		return nil
 	default:
 		return wrapInvalid("unknown send mode " + string(state.Mode))
 	}
@@ -229,51 +305,65 @@ func (h *Handler) completeGmailSend(w http.ResponseWriter, r *http.Request) {
 	// been sent, so this is a cancellation rather than an error.
 	if reason := r.URL.Query().Get("error"); reason != "" {
 		h.auth.logger.Info("Availability send cancelled at the consent screen", zap.String("reason", reason))
-		http.Redirect(w, r, sendReturnPath+"?sendError="+url.QueryEscape("Gmail access was not granted, so nothing was sent."), http.StatusFound)
+		http.Redirect(w, r, sendReturnPathFor(state.Mode)+"?sendError="+url.QueryEscape("Gmail access was not granted, so nothing was sent."), http.StatusFound)
 		return
 	}
 
 	token, err := h.auth.gmailOAuthConfig().Exchange(r.Context(), r.URL.Query().Get("code"))
 	if err != nil {
 		h.auth.logger.Warn("Gmail code exchange failed", zap.Error(err))
-		http.Redirect(w, r, sendReturnPath+"?sendError="+url.QueryEscape("Could not get permission to send mail, so nothing was sent."), http.StatusFound)
+		http.Redirect(w, r, sendReturnPathFor(state.Mode)+"?sendError="+url.QueryEscape("Could not get permission to send mail, so nothing was sent."), http.StatusFound)
 		return
 	}
 
 	h.startSend(w, r, admin, token, state)
 }
 
-// startSend registers the job, launches the send behind it, and redirects the
-// browser to the page that watches it.
+// startSend records the send, or claims the interrupted one being resumed,
+// launches it, and redirects the browser to the page that watches it.
 //
 // The redirect goes out before a single email does. A round is thirty-odd emails
 // at one every three seconds, so holding the response until the last one landed
 // would leave a blank tab for a minute and a half with nothing to say whether it
-// was working or hung.
+// was working or hung. The record is written first, so the send the page is
+// sent to watch exists by the time it asks.
 func (h *Handler) startSend(w http.ResponseWriter, r *http.Request, admin string, token *oauth2.Token, state gmailSendState) {
 	mailer, err := h.newMailer(r.Context(), token)
 	if err != nil {
 		h.logger.Error("Failed to build a mail client for the send", zap.Error(err))
-		http.Redirect(w, r, sendReturnPath+"?sendError="+url.QueryEscape("Could not reach Gmail, so nothing was sent."), http.StatusFound)
+		http.Redirect(w, r, sendReturnPathFor(state.Mode)+"?sendError="+url.QueryEscape("Could not reach Gmail, so nothing was sent."), http.StatusFound)
 		return
 	}
 
-	jobID := uuid.New().String()
-	h.sends.start(jobID, admin, state.Mode)
+	var send *db.AvailabilitySend
+	if state.ResumeID != "" {
+		send, err = services.ResumeAvailabilitySend(r.Context(), h.store, state.ResumeID, admin, time.Now(), h.logger)
+	} else {
+		send, err = services.BeginAvailabilitySend(r.Context(), h.store, admin, services.SendParams{
+			RotaID:         state.RotaID,
+			Mode:           state.Mode,
+			Deadline:       state.Deadline,
+			VolunteerID:    state.VolunteerID,
+			CoverID:        state.CoverID,
+			CoverRequestID: state.CoverRequestID,
+			SwapRequestID:  state.SwapRequestID,
+		}, h.logger)
+	}
+	if err != nil {
+		// Checked once already before the consent screen, so this is the rota
+		// or the send having moved while the admin was at Google.
+		h.logger.Warn("Availability send refused", zap.Error(err))
+		http.Redirect(w, r, sendReturnPathFor(state.Mode)+"?sendError="+url.QueryEscape(sendRefusal(err)), http.StatusFound)
+		return
+	}
 
 	// Built from the request that started the send, not from the callback's
 	// context: this is the address the app answers on, and it is what the
 	// volunteer has to be able to paste into a browser.
 	link := func(token string) string { return availabilityLink(r, token) }
-
-	params := services.SendParams{
-		RotaID:      state.RotaID,
-		Mode:        state.Mode,
-		Deadline:    state.Deadline,
-		VolunteerID: state.VolunteerID,
-		Link:        link,
-		Progress:    func(done, total int) { h.sends.progress(jobID, done, total) },
-	}
+	cover := func(token string) string { return coverRequestLink(r, token) }
+	swap := func(token string) string { return swapLink(r, token) }
+	calendar := func(token string) string { return calendarLink(r, token) }
 
 	go func() {
 		// Deliberately not the request's context: the browser is being
@@ -284,14 +374,20 @@ func (h *Handler) startSend(w http.ResponseWriter, r *http.Request, admin string
 		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), 15*time.Minute)
 		defer cancel()
 
-		report, err := services.SendAvailabilityEmails(ctx, h.store, h.volunteers, mailer, h.cfg, h.logger, params)
-		if err != nil {
-			h.logger.Error("Availability send failed", zap.String("job", jobID), zap.Error(err))
-		}
-		h.sends.finish(jobID, report, err)
+		services.RunAvailabilitySend(ctx, h.store, h.volunteers, mailer, h.cfg, h.logger, *send, link, cover, swap, calendar)
 	}()
 
-	http.Redirect(w, r, sendReturnPath+"?send="+url.QueryEscape(jobID), http.StatusFound)
+	http.Redirect(w, r, sendReturnPathFor(state.Mode)+"?send="+url.QueryEscape(send.ID), http.StatusFound)
+}
+
+// sendRefusal is the message the page shows for a send that could not start.
+// A refusal the service explained is shown as it is; anything else is not the
+// admin's to read, and is in the log.
+func sendRefusal(err error) string {
+	if errors.Is(err, services.ErrInvalidInput) || errors.Is(err, services.ErrNotFound) || errors.Is(err, services.ErrConflict) {
+		return "Nothing was sent: " + err.Error()
+	}
+	return "Could not start the send, so nothing was sent."
 }
 
 type sendEmailResponse struct {
@@ -301,47 +397,119 @@ type sendEmailResponse struct {
 	Error         string `json:"error,omitempty"`
 }
 
-// sendResponse is a send as the admin screen reads it: how far it has got while
-// it runs, and who it reached and failed on once it has finished.
+// sendSummaryResponse is a send as a round's history lists it.
+type sendSummaryResponse struct {
+	ID             string `json:"id"`
+	Mode           string `json:"mode"`
+	AdminEmail     string `json:"adminEmail"`
+	VolunteerID    string `json:"volunteerId,omitempty"`
+	CoverID        string `json:"coverId,omitempty"`
+	CoverRequestID string `json:"coverRequestId,omitempty"`
+	SwapRequestID  string `json:"swapRequestId,omitempty"`
+	// Status is "running", "interrupted" or "finished". Only an interrupted
+	// send can be resumed.
+	Status     string `json:"status"`
+	StartedAt  string `json:"startedAt"`
+	FinishedAt string `json:"finishedAt,omitempty"`
+	Done       int    `json:"done"`
+	Total      int    `json:"total"`
+	SentCount  int    `json:"sentCount"`
+	// FailedCount is the emails that did not go out; Error, when set, is why
+	// the send as a whole stopped short.
+	FailedCount int    `json:"failedCount"`
+	Error       string `json:"error,omitempty"`
+}
+
+// sendResponse is a send as the admin who started it reads it: how far it has
+// got while it runs, and who it reached and failed on as it goes.
 type sendResponse struct {
-	ID       string              `json:"id"`
-	Mode     string              `json:"mode"`
-	Done     int                 `json:"done"`
-	Total    int                 `json:"total"`
+	sendSummaryResponse
 	Finished bool                `json:"finished"`
 	Sent     []sendEmailResponse `json:"sent"`
 	Failed   []sendEmailResponse `json:"failed"`
-	Error    string              `json:"error,omitempty"`
 }
 
-// handleGetSend reports on a send in progress or just finished. Admin-gated, and
+func toSendSummaryResponse(s services.AvailabilitySendSummary) sendSummaryResponse {
+	resp := sendSummaryResponse{
+		ID:             s.ID,
+		Mode:           string(s.Mode),
+		AdminEmail:     s.AdminEmail,
+		VolunteerID:    s.VolunteerID,
+		CoverID:        s.CoverID,
+		CoverRequestID: s.CoverRequestID,
+		SwapRequestID:  s.SwapRequestID,
+		Status:         s.Status,
+		StartedAt:      s.StartedAt.UTC().Format(time.RFC3339),
+		Done:           s.Done(),
+		Total:          s.Total,
+		SentCount:      s.Sent,
+		FailedCount:    s.Failed,
+		Error:          s.Error,
+	}
+	if s.FinishedAt != nil {
+		resp.FinishedAt = s.FinishedAt.UTC().Format(time.RFC3339)
+	}
+	return resp
+}
+
+// handleGetSend reports on a send, running or long finished. Admin-gated, and
 // scoped to the admin who started it: a send lists every volunteer it reached
 // and every address it failed on.
 func (h *Handler) handleGetSend(w http.ResponseWriter, r *http.Request) {
-	snapshot, ok := h.sends.snapshot(r.PathValue("id"), adminEmail(r.Context()))
-	if !ok {
-		// Also the answer for a send that has aged out, which is indistinguishable
-		// from one that never existed and means the same thing to the page asking.
-		h.writeError(w, http.StatusNotFound, "no send with that id — it may have finished long enough ago to have been forgotten")
+	view, err := services.GetAvailabilitySend(r.Context(), h.store, r.PathValue("id"), adminEmail(r.Context()), time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
 		return
 	}
 
+	h.writeJSON(w, http.StatusOK, toSendResponse(*view))
+}
+
+func toSendResponse(view services.AvailabilitySendView) sendResponse {
 	resp := sendResponse{
-		ID:       snapshot.ID,
-		Mode:     string(snapshot.Mode),
-		Done:     snapshot.Done,
-		Total:    snapshot.Total,
-		Finished: snapshot.Finished,
-		Error:    snapshot.Err,
-		Sent:     make([]sendEmailResponse, 0, len(snapshot.Sent)),
-		Failed:   make([]sendEmailResponse, 0, len(snapshot.Failed)),
-	}
-	for _, s := range snapshot.Sent {
+		sendSummaryResponse: toSendSummaryResponse(view.AvailabilitySendSummary),
+		Finished:            view.Status == services.SendStatusFinished,
+		Sent:                make([]sendEmailResponse, 0, len(view.SentEmails)),
+		Failed:              make([]sendEmailResponse, 0, len(view.FailedEmails)),
+	}
+	for _, s := range view.SentEmails {
 		resp.Sent = append(resp.Sent, sendEmailResponse{VolunteerID: s.VolunteerID, VolunteerName: s.VolunteerName, Email: s.Email})
 	}
-	for _, f := range snapshot.Failed {
+	for _, f := range view.FailedEmails {
 		resp.Failed = append(resp.Failed, sendEmailResponse{VolunteerID: f.VolunteerID, VolunteerName: f.VolunteerName, Email: f.Email, Error: f.Error})
 	}
+	return resp
+}
+
+// handleListSends is a round's history of sends, newest first: ?rotaId= names
+// the round, and no rotaId means the latest, as it does for the round itself.
+func (h *Handler) handleListSends(w http.ResponseWriter, r *http.Request) {
+	summaries, err := services.ListAvailabilitySends(r.Context(), h.store, r.URL.Query().Get("rotaId"), time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]sendSummaryResponse, 0, len(summaries))
+	for _, s := range summaries {
+		resp = append(resp, toSendSummaryResponse(s))
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleListCoverNotifications is who was told about one rota change: its
+// sends, newest first, each naming the volunteers it reached and failed on.
+// Any admin can read it — it is the change's audit — so no addresses are in it.
+func (h *Handler) handleListCoverNotifications(w http.ResponseWriter, r *http.Request) {
+	views, err := services.ListCoverNotifications(r.Context(), h.store, r.PathValue("id"), time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
 
+	resp := make([]sendResponse, 0, len(views))
+	for _, v := range views {
+		resp = append(resp, toSendResponse(v))
+	}
 	h.writeJSON(w, http.StatusOK, resp)
 }
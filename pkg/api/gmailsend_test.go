@@ -9,11 +9,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 	"golang.org/x/oauth2"
 
+	"github.com/jakechorley/ilford-drop-in/internal/config"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 )
@@ -25,7 +27,7 @@ type recordingMailer struct {
 	sent []string
 }
 
-func (m *recordingMailer) SendEmail(to, _, _ string) error {
+func (m *recordingMailer) SendEmail(to, _, _, _ string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.sent = append(m.sent, to)
@@ -38,6 +40,118 @@ func (m *recordingMailer) recipients() []string {
 	return append([]string(nil), m.sent...)
 }
 
+// The recorded-send methods of mockStore. Sends are behaviour the service
+// tests cover; these only keep enough state for a send started through the
+// handler to be watched, listed and resumed through it.
+
+func (m *mockStore) sendByID(id string) *db.AvailabilitySend {
+	for i := range m.sends {
+		if m.sends[i].ID == id {
+			return &m.sends[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) InsertAvailabilitySend(_ context.Context, send db.AvailabilitySend) error {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	send.StartedAt, send.LastProgressAt = time.Now(), time.Now()
+	m.sends = append(m.sends, send)
+	return nil
+}
+
+func (m *mockStore) SetAvailabilitySendTotal(_ context.Context, id string, total int) error {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	if send := m.sendByID(id); send != nil {
+		send.Total, send.LastProgressAt = total, time.Now()
+	}
+	return nil
+}
+
+func (m *mockStore) RecordAvailabilitySendOutcome(_ context.Context, outcome db.AvailabilitySendOutcome) error {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	if send := m.sendByID(outcome.SendID); send != nil {
+		send.LastProgressAt = time.Now()
+		if outcome.Error == "" {
+			send.Sent++
+		} else {
+			send.Failed++
+		}
+	}
+	m.sendOutcomes = append(m.sendOutcomes, outcome)
+	return nil
+}
+
+func (m *mockStore) FinishAvailabilitySend(_ context.Context, id string, errText string) error {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	if send := m.sendByID(id); send != nil {
+		now := time.Now()
+		send.FinishedAt, send.Error = &now, errText
+	}
+	return nil
+}
+
+func (m *mockStore) ClaimAvailabilitySend(_ context.Context, id string, staleBefore time.Time) (bool, error) {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	send := m.sendByID(id)
+	if send == nil || send.FinishedAt != nil || !send.LastProgressAt.Before(staleBefore) {
+		return false, nil
+	}
+	send.LastProgressAt = time.Now()
+	return true, nil
+}
+
+func (m *mockStore) GetAvailabilitySend(_ context.Context, id string) (*db.AvailabilitySend, error) {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	if send := m.sendByID(id); send != nil {
+		copied := *send
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockStore) GetAvailabilitySendsByRotaID(_ context.Context, rotaID string) ([]db.AvailabilitySend, error) {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	var out []db.AvailabilitySend
+	for i := len(m.sends) - 1; i >= 0; i-- {
+		if m.sends[i].RotaID == rotaID {
+			out = append(out, m.sends[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) GetAvailabilitySendsByCoverID(_ context.Context, coverID string) ([]db.AvailabilitySend, error) {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	var out []db.AvailabilitySend
+	for i := len(m.sends) - 1; i >= 0; i-- {
+		if m.sends[i].CoverID == coverID {
+			out = append(out, m.sends[i])
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) GetAvailabilitySendOutcomes(_ context.Context, sendID string) ([]db.AvailabilitySendOutcome, error) {
+	m.sendsMu.Lock()
+	defer m.sendsMu.Unlock()
+	var out []db.AvailabilitySendOutcome
+	for _, o := range m.sendOutcomes {
+		if o.SendID == sendID {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
 // sendTestStore is a round of three volunteers, all holding links and none of
 // them sent.
 func sendTestStore() *mockStore {
@@ -58,7 +172,7 @@ func sendTestStore() *mockStore {
 // newSendTestHandler wires a handler whose identity provider and mailer are both
 // stubbed, which is the dev-mode shape: the OAuth round-trip is skipped and the
 // send runs for real against the store.
-func newSendTestHandler(store *mockStore, mailer services.GmailClient) http.Handler {
+func newSendTestHandler(store *mockStore, mailer services.MailClient) http.Handler {
 	auth := newTestAuthenticator()
 	// Dev mode: /auth/gmail goes straight to the send rather than to Google.
 	auth.stubEmail = testAdminEmail
@@ -68,7 +182,7 @@ func newSendTestHandler(store *mockStore, mailer services.GmailClient) http.Hand
 		volunteers.volunteers[i].Email = volunteers.volunteers[i].ID + "@example.com"
 	}
 
-	newMailer := func(context.Context, *oauth2.Token) (services.GmailClient, error) { return mailer, nil }
+	newMailer := func(context.Context, *oauth2.Token) (services.MailClient, error) { return mailer, nil }
 	return NewHandler(store, volunteers, apiTestCfg, auth, nil, newMailer, zap.NewNop()).Routes()
 }
 
@@ -181,6 +295,100 @@ func TestResendMailsOneVolunteer(t *testing.T) {
 	assert.Equal(t, "bob", resp.Sent[0].VolunteerID)
 }
 
+// TestAllocationSendMailsEveryoneOnTheRota: once a rota is allocated, the
+// allocation send needs no deadline, reaches the volunteers on it and nobody
+// else, and hands the browser back to the rota page it was started from.
+func TestAllocationSendMailsEveryoneOnTheRota(t *testing.T) {
+	store := sendTestStore()
+	store.rotations[0].AllocatedDatetime = "2026-07-30T09:00:00Z"
+	store.allocations = []db.Allocation{
+		{ID: "a1", ShiftID: "shift-1", Role: "team_lead", VolunteerID: "alice"},
+		{ID: "a2", ShiftID: "shift-2", Role: "service", VolunteerID: "alice"},
+		{ID: "a3", ShiftID: "shift-2", Role: "service", VolunteerID: "bob"},
+	}
+	mailer := &recordingMailer{}
+	handler := newSendTestHandler(store, mailer)
+
+	rec := doRequest(t, handler, http.MethodGet, "/auth/gmail?mode=allocation", "", adminCookie())
+	require.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, allocationSendReturnPath, location.Path)
+	require.NotEmpty(t, location.Query().Get("send"), rec.Header().Get("Location"))
+
+	resp := awaitSend(t, handler, location.Query().Get("send"))
+
+	assert.Equal(t, "allocation", resp.Mode)
+	assert.Empty(t, resp.Failed)
+	assert.ElementsMatch(t, []string{"alice@example.com", "bob@example.com"}, mailer.recipients())
+	for _, req := range store.availabilityRequests {
+		assert.Empty(t, req.SentAt, "telling %s their shifts is not asking for availability", req.VolunteerID)
+	}
+}
+
+// TestAllocationSendIsRefusedBeforeAllocation: there are no shifts to tell
+// anybody about yet, so the refusal comes back to the rota page unsent.
+func TestAllocationSendIsRefusedBeforeAllocation(t *testing.T) {
+	mailer := &recordingMailer{}
+	handler := newSendTestHandler(sendTestStore(), mailer)
+
+	rec := doRequest(t, handler, http.MethodGet, "/auth/gmail?mode=allocation", "", adminCookie())
+
+	require.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, allocationSendReturnPath, location.Path)
+	assert.NotEmpty(t, location.Query().Get("sendError"))
+	assert.Empty(t, mailer.recipients())
+}
+
+// TestCoverSendTellsTheVolunteersItMoved: a rota change is announced to the
+// people it moved and nobody else, lands back on the rota page, and is what
+// any admin reads afterwards to see who was told.
+func TestCoverSendTellsTheVolunteersItMoved(t *testing.T) {
+	const coverID = "6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b"
+	store := sendTestStore()
+	store.rotations[0].AllocatedDatetime = "2026-07-30T09:00:00Z"
+	store.allocations = []db.Allocation{
+		{ID: "a1", ShiftID: "shift-1", Role: "service", VolunteerID: "alice"},
+		{ID: "a2", ShiftID: "shift-2", Role: "service", VolunteerID: "bob"},
+	}
+	store.alterations = []db.Alteration{
+		{ID: "alt-1", ShiftID: "shift-1", Direction: "remove", VolunteerID: "alice", CoverID: coverID},
+		{ID: "alt-2", ShiftID: "shift-1", Direction: "add", VolunteerID: "charlie", CoverID: coverID},
+	}
+	mailer := &recordingMailer{}
+	handler := newSendTestHandler(store, mailer)
+
+	rec := doRequest(t, handler, http.MethodGet, "/auth/gmail?mode=cover&coverId="+coverID, "", adminCookie())
+	require.Equal(t, http.StatusFound, rec.Code, rec.Body.String())
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, allocationSendReturnPath, location.Path)
+	require.NotEmpty(t, location.Query().Get("send"), rec.Header().Get("Location"))
+
+	resp := awaitSend(t, handler, location.Query().Get("send"))
+
+	assert.Equal(t, "cover", resp.Mode)
+	assert.Equal(t, coverID, resp.CoverID)
+	assert.Empty(t, resp.Failed)
+	assert.ElementsMatch(t, []string{"alice@example.com", "charlie@example.com"}, mailer.recipients())
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/covers/"+coverID+"/notifications", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var notified []sendResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &notified))
+	require.Len(t, notified, 1)
+	assert.Equal(t, resp.ID, notified[0].ID)
+	assert.Len(t, notified[0].Sent, 2)
+	for _, to := range mailer.recipients() {
+		assert.NotContains(t, rec.Body.String(), to, "the audit names who was told, not where")
+	}
+
+	rec = doRequest(t, handler, http.MethodGet, "/auth/gmail?mode=cover", "", adminCookie())
+	assert.Equal(t, http.StatusBadRequest, rec.Code, "a cover send names its change")
+}
+
 // TestSendResultIsReadableOnlyByTheAdminWhoStartedIt: a finished send names
 // every volunteer it reached and every address it failed on, which belongs to
 // the admin who asked for it and to nobody else on the allowlist.
@@ -190,7 +398,7 @@ func TestSendResultIsReadableOnlyByTheAdminWhoStartedIt(t *testing.T) {
 	auth.adminEmails["other@example.com"] = struct{}{}
 
 	handler := NewHandler(sendTestStore(), testVolunteers(), apiTestCfg, auth, nil,
-		func(context.Context, *oauth2.Token) (services.GmailClient, error) { return &recordingMailer{}, nil },
+		func(context.Context, *oauth2.Token) (services.MailClient, error) { return &recordingMailer{}, nil },
 		zap.NewNop()).Routes()
 
 	jobID := startSendRequest(t, handler, "mode=round&deadline=Friday")
@@ -215,6 +423,103 @@ func TestUnknownSendIsNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, rec.Code)
 }
 
+// TestSendOutlivesTheHandlerThatRanIt: a deploy partway through the day must
+// not cost the admin the report of a send — it is read back from the store, not
+// from the process that sent it.
+func TestSendOutlivesTheHandlerThatRanIt(t *testing.T) {
+	store := sendTestStore()
+	jobID := startSendRequest(t, newSendTestHandler(store, &recordingMailer{}), "mode=round&deadline=Friday")
+	awaitSend(t, newSendTestHandler(store, &recordingMailer{}), jobID)
+
+	restarted := newSendTestHandler(store, &recordingMailer{})
+	rec := doRequest(t, restarted, http.MethodGet, "/api/availability-sends/"+jobID, "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp sendResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, services.SendStatusFinished, resp.Status)
+	assert.Len(t, resp.Sent, 3)
+}
+
+// TestSendHistoryListsTheRoundsSends: what an admin checks before sending a
+// reminder is whether somebody already has.
+func TestSendHistoryListsTheRoundsSends(t *testing.T) {
+	store := sendTestStore()
+	handler := newSendTestHandler(store, &recordingMailer{})
+	jobID := startSendRequest(t, handler, "mode=round&deadline=Friday")
+	awaitSend(t, handler, jobID)
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/availability-sends?rotaId=rota-1", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var history []sendSummaryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &history))
+	require.Len(t, history, 1)
+	assert.Equal(t, jobID, history[0].ID)
+	assert.Equal(t, testAdminEmail, history[0].AdminEmail)
+	assert.Equal(t, "finished", history[0].Status)
+	assert.Equal(t, 3, history[0].SentCount)
+	assert.NotEmpty(t, history[0].FinishedAt)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/availability-sends?rotaId=rota-1", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// interruptedSend is a round send whose process went away after emailing
+// alice: not finished, and quiet for longer than a running send ever is.
+func interruptedSend(store *mockStore) string {
+	id := uuid.New().String()
+	store.sends = append(store.sends, db.AvailabilitySend{
+		ID: id, RotaID: "rota-1", AdminEmail: testAdminEmail, Mode: "round", Deadline: "Friday",
+		StartedAt: time.Now().Add(-time.Hour), LastProgressAt: time.Now().Add(-time.Hour),
+		Total: 3, Sent: 1,
+	})
+	store.sendOutcomes = append(store.sendOutcomes, db.AvailabilitySendOutcome{
+		SendID: id, VolunteerID: "alice", VolunteerName: "Alice", Email: "alice@example.com",
+	})
+	store.availabilityRequests[0].SentAt = "2026-08-01T09:00:00Z"
+	return id
+}
+
+// TestResumeCarriesOnAnInterruptedSend: resuming goes back through the Gmail
+// grant, because the one the send started with died with its process, and
+// picks up at the first volunteer it had not reached.
+func TestResumeCarriesOnAnInterruptedSend(t *testing.T) {
+	store := sendTestStore()
+	id := interruptedSend(store)
+	mailer := &recordingMailer{}
+	handler := newSendTestHandler(store, mailer)
+
+	jobID := startSendRequest(t, handler, "resume="+id)
+	assert.Equal(t, id, jobID, "a resume carries on with the same send rather than starting another")
+	resp := awaitSend(t, handler, jobID)
+
+	assert.ElementsMatch(t, []string{"bob@example.com", "charlie@example.com"}, mailer.recipients())
+	assert.Len(t, resp.Sent, 3)
+	assert.Equal(t, 3, resp.Total)
+}
+
+// TestResumeIsRefusedBeforeTheConsentScreen: a send that is still going, or
+// is somebody else's, is not resumable, and nobody should approve Gmail access
+// to find that out.
+func TestResumeIsRefusedBeforeTheConsentScreen(t *testing.T) {
+	store := sendTestStore()
+	id := interruptedSend(store)
+	store.sends[0].LastProgressAt = time.Now()
+	mailer := &recordingMailer{}
+	handler := newSendTestHandler(store, mailer)
+
+	rec := doRequest(t, handler, http.MethodGet, "/auth/gmail?resume="+id, "", adminCookie())
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	store.sends[0].AdminEmail = "other@example.com"
+	store.sends[0].LastProgressAt = time.Now().Add(-time.Hour)
+	rec = doRequest(t, handler, http.MethodGet, "/auth/gmail?resume="+id, "", adminCookie())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	assert.Empty(t, mailer.recipients())
+}
+
 // TestGmailStateRoundTrips: the pending send survives the trip to Google and
 // back unchanged, which is the only reason it can be carried in a query
 // parameter at all.
@@ -291,6 +596,32 @@ func TestGmailConsentAsksOnlyForTheSendScope(t *testing.T) {
 	assert.True(t, isGmailState(query.Get("state")))
 }
 
+// TestSendSkipsConsentUnderAnotherTransport: with mail going out through a
+// relay there is no Gmail access to approve, so a signed-in admin's send starts
+// at once, outside dev mode, and the mailer is built without a token.
+func TestSendSkipsConsentUnderAnotherTransport(t *testing.T) {
+	cfg := &config.Config{Mail: &config.MailConfig{
+		Transport: config.MailTransportSMTP,
+		From:      "rota@example.com",
+		SMTP:      &config.SMTPConfig{Host: "smtp.example.com", Port: 587},
+	}}
+	volunteers := testVolunteers()
+	for i := range volunteers.volunteers {
+		volunteers.volunteers[i].Email = volunteers.volunteers[i].ID + "@example.com"
+	}
+	mailer := &recordingMailer{}
+	newMailer := func(_ context.Context, token *oauth2.Token) (services.MailClient, error) {
+		assert.Nil(t, token, "no grant was asked for, so there is no token to pass")
+		return mailer, nil
+	}
+	handler := NewHandler(sendTestStore(), volunteers, cfg, newTestAuthenticator(), nil, newMailer, zap.NewNop()).Routes()
+
+	resp := awaitSend(t, handler, startSendRequest(t, handler, "mode=round&deadline=Friday"))
+
+	assert.Len(t, resp.Sent, 3)
+	assert.ElementsMatch(t, []string{"alice@example.com", "bob@example.com", "charlie@example.com"}, mailer.recipients())
+}
+
 // TestSendCallbackRejectsAStateForAnotherAdmin: the signature proves the
 // instruction is ours, the session proves who is presenting it. A state captured
 // from one admin must not run in another's browser, under their Gmail account.
@@ -299,7 +630,7 @@ func TestSendCallbackRejectsAStateForAnotherAdmin(t *testing.T) {
 	auth.adminEmails["other@example.com"] = struct{}{}
 	mailer := &recordingMailer{}
 	handler := NewHandler(sendTestStore(), testVolunteers(), apiTestCfg, auth, nil,
-		func(context.Context, *oauth2.Token) (services.GmailClient, error) { return mailer, nil },
+		func(context.Context, *oauth2.Token) (services.MailClient, error) { return mailer, nil },
 		zap.NewNop()).Routes()
 
 	signed, err := signGmailState(testSecret, gmailSendState{
@@ -321,7 +652,7 @@ func TestSendCallbackRejectsAStateForAnotherAdmin(t *testing.T) {
 func TestSendCallbackRequiresASession(t *testing.T) {
 	mailer := &recordingMailer{}
 	handler := NewHandler(sendTestStore(), testVolunteers(), apiTestCfg, newTestAuthenticator(), nil,
-		func(context.Context, *oauth2.Token) (services.GmailClient, error) { return mailer, nil },
+		func(context.Context, *oauth2.Token) (services.MailClient, error) { return mailer, nil },
 		zap.NewNop()).Routes()
 
 	signed, err := signGmailState(testSecret, gmailSendState{
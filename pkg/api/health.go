@@ -20,16 +20,24 @@ const healthCheckTimeout = 2 * time.Second
 //
 // Readiness is a live database probe rather than a flag set at boot, because the
 // database is the one dependency whose loss makes every data-bearing route fail.
-// The endpoint is public and says nothing beyond up or down.
+// The endpoint is public and says nothing beyond up or down — and, where the
+// sheet is the roster, how fresh the roster is. A sheet that cannot be read
+// does not make the server unready: it serves the roster it last read, and a
+// monitor that wants to alarm on a stale one can read the time here.
 func (h *Handler) handleHealth(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
 	defer cancel()
 
 	if err := h.store.Ping(ctx); err != nil {
 		h.logger.Warn("Health check failed: database unreachable", zap.Error(err))
-		h.writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "unavailable"})
+		h.writeJSON(w, http.StatusServiceUnavailable, healthResponse{Status: "unavailable"})
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	h.writeJSON(w, http.StatusOK, healthResponse{Status: "ok", RosterSync: h.rosterSyncStatus()})
+}
+
+type healthResponse struct {
+	Status     string                    `json:"status"`
+	RosterSync *rosterSyncStatusResponse `json:"rosterSync,omitempty"`
 }
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// createPairingRuleRequest is one rule an admin is making about two volunteers:
+// kind "together" to try to put them on the same shifts, "apart" never to. The
+// order the two are named in means nothing.
+type createPairingRuleRequest struct {
+	VolunteerA string `json:"volunteerA"`
+	VolunteerB string `json:"volunteerB"`
+	Kind       string `json:"kind"`
+	Note       string `json:"note,omitempty"`
+}
+
+// pairingRuleResponse carries each volunteer by id and by name, as a Standing
+// Preallocation does, so a client can render one without holding the roster
+// beside it.
+type pairingRuleResponse struct {
+	ID         string `json:"id"`
+	Kind       string `json:"kind"`
+	VolunteerA string `json:"volunteerA"`
+	NameA      string `json:"nameA"`
+	VolunteerB string `json:"volunteerB"`
+	NameB      string `json:"nameB"`
+	Note       string `json:"note,omitempty"`
+}
+
+type listPairingRulesResponse struct {
+	PairingRules []pairingRuleResponse `json:"pairingRules"`
+}
+
+// handleListPairingRules returns every Pairing Rule, "apart" rules first.
+func (h *Handler) handleListPairingRules(w http.ResponseWriter, r *http.Request) {
+	views, err := services.ListPairingRules(r.Context(), h.store, h.volunteers, h.cfg, h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := listPairingRulesResponse{PairingRules: make([]pairingRuleResponse, 0, len(views))}
+	for _, v := range views {
+		resp.PairingRules = append(resp.PairingRules, toPairingRuleResponse(v))
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleCreatePairingRule adds one. Validation lives in the service;
+// rejections map to 400/404/409 via writeServiceError.
+func (h *Handler) handleCreatePairingRule(w http.ResponseWriter, r *http.Request) {
+	var req createPairingRuleRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	view, err := services.AddPairingRule(r.Context(), h.store, h.volunteers, h.cfg, services.AddPairingRuleParams{
+		VolunteerA: req.VolunteerA,
+		VolunteerB: req.VolunteerB,
+		Kind:       req.Kind,
+		Note:       req.Note,
+	}, h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, toPairingRuleResponse(*view))
+}
+
+// handleDeletePairingRule removes one by id. 204 on success, 404 when it has
+// already gone.
+func (h *Handler) handleDeletePairingRule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := services.DeletePairingRule(r.Context(), h.store, id, h.logger); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toPairingRuleResponse(v services.PairingRuleView) pairingRuleResponse {
+	return pairingRuleResponse{
+		ID:         v.ID,
+		Kind:       v.Kind,
+		VolunteerA: v.VolunteerA,
+		NameA:      v.NameA,
+		VolunteerB: v.VolunteerB,
+		NameB:      v.NameB,
+		Note:       v.Note,
+	}
+}
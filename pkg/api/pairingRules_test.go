@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// pairingVolunteers is a roster with names, which the grouping rule keys
+// ungrouped volunteers on, and one couple.
+func pairingVolunteers() *mockVolunteerClient {
+	return &mockVolunteerClient{
+		volunteers: []model.Volunteer{
+			{ID: "alice", FirstName: "Alice", LastName: "Smith", DisplayName: "Alice", Status: "Active"},
+			{ID: "bob", FirstName: "Bob", LastName: "Jones", DisplayName: "Bob", Status: "Active"},
+			{ID: "mum", FirstName: "Mary", LastName: "Khan", DisplayName: "Mary", GroupKey: "khans", Status: "Active"},
+			{ID: "dad", FirstName: "Omar", LastName: "Khan", DisplayName: "Omar", GroupKey: "khans", Status: "Active"},
+		},
+	}
+}
+
+// pairingRuleJSON is the wire shape of one Pairing Rule, so tests read the
+// same fields a client does.
+type pairingRuleJSON struct {
+	ID         string `json:"id"`
+	Kind       string `json:"kind"`
+	VolunteerA string `json:"volunteerA"`
+	NameA      string `json:"nameA"`
+	VolunteerB string `json:"volunteerB"`
+	NameB      string `json:"nameB"`
+	Note       string `json:"note"`
+}
+
+func TestCreatePairingRuleEndpoint(t *testing.T) {
+	store := &mockStore{}
+	body := `{"volunteerA":"bob","volunteerB":"alice","kind":"apart","note":" safeguarding "}`
+
+	rec := doRequest(t, newTestHandler(store, pairingVolunteers()), http.MethodPost, "/api/pairing-rules", body, adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	require.Len(t, store.insertedPairingRules, 1)
+	assert.Equal(t, "alice", store.insertedPairingRules[0].VolunteerA, "the pair is stored in one order whichever way it was named")
+	assert.Equal(t, "bob", store.insertedPairingRules[0].VolunteerB)
+	assert.Equal(t, "apart", store.insertedPairingRules[0].Kind)
+	assert.Equal(t, "safeguarding", store.insertedPairingRules[0].Note)
+
+	var created pairingRuleJSON
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "Alice", created.NameA)
+	assert.Equal(t, "Bob", created.NameB)
+}
+
+func TestCreatePairingRuleEndpoint_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		setup    func(*mockStore)
+		wantCode int
+	}{
+		{name: "malformed json", body: `{`, wantCode: http.StatusBadRequest},
+		{name: "unknown field", body: `{"volunteerA":"alice","volunteerB":"bob","kind":"apart","shifts":2}`, wantCode: http.StatusBadRequest},
+		{name: "unknown kind", body: `{"volunteerA":"alice","volunteerB":"bob","kind":"sometimes"}`, wantCode: http.StatusBadRequest},
+		{name: "one volunteer twice", body: `{"volunteerA":"alice","volunteerB":"alice","kind":"together"}`, wantCode: http.StatusBadRequest},
+		{name: "only one volunteer", body: `{"volunteerA":"alice","kind":"together"}`, wantCode: http.StatusBadRequest},
+		{name: "unknown volunteer", body: `{"volunteerA":"alice","volunteerB":"ghost","kind":"apart"}`, wantCode: http.StatusNotFound},
+		{name: "one group", body: `{"volunteerA":"mum","volunteerB":"dad","kind":"apart"}`, wantCode: http.StatusBadRequest},
+		{
+			name: "pair already has a rule",
+			body: `{"volunteerA":"alice","volunteerB":"bob","kind":"together"}`,
+			setup: func(s *mockStore) {
+				s.pairingWriteErr = db.ErrDuplicatePairingRule
+			},
+			wantCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockStore{}
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+			rec := doRequest(t, newTestHandler(store, pairingVolunteers()), http.MethodPost, "/api/pairing-rules", tt.body, adminCookie())
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Empty(t, store.insertedPairingRules)
+		})
+	}
+}
+
+func TestListPairingRulesEndpoint(t *testing.T) {
+	store := &mockStore{pairingRules: []db.PairingRule{
+		{ID: "pr-1", VolunteerA: "alice", VolunteerB: "mum", Kind: "together"},
+		{ID: "pr-2", VolunteerA: "bob", VolunteerB: "dad", Kind: "apart", Note: "safeguarding"},
+	}}
+
+	rec := doRequest(t, newTestHandler(store, pairingVolunteers()), http.MethodGet, "/api/pairing-rules", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		PairingRules []pairingRuleJSON `json:"pairingRules"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.PairingRules, 2)
+	assert.Equal(t, pairingRuleJSON{
+		ID: "pr-2", Kind: "apart", VolunteerA: "bob", NameA: "Bob", VolunteerB: "dad", NameB: "Omar", Note: "safeguarding",
+	}, resp.PairingRules[0], "an apart rule lists first")
+	assert.Equal(t, "Mary", resp.PairingRules[1].NameB)
+}
+
+func TestListPairingRulesEndpoint_Empty(t *testing.T) {
+	rec := doRequest(t, newTestHandler(&mockStore{}, pairingVolunteers()), http.MethodGet, "/api/pairing-rules", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"pairingRules":[]}`, rec.Body.String())
+}
+
+func TestDeletePairingRuleEndpoint(t *testing.T) {
+	store := &mockStore{pairingRules: []db.PairingRule{
+		{ID: "pr-1", VolunteerA: "alice", VolunteerB: "bob", Kind: "apart"},
+	}}
+
+	handler := newTestHandler(store, pairingVolunteers())
+	rec := doRequest(t, handler, http.MethodDelete, "/api/pairing-rules/pr-1", "", adminCookie())
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, []string{"pr-1"}, store.deletedPairingRuleIDs)
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/pairing-rules/pr-1", "", adminCookie())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// Every verb is admin-only: an "apart" rule is usually a safeguarding decision.
+func TestPairingRulesRequireAdmin(t *testing.T) {
+	handler := newTestHandler(&mockStore{}, pairingVolunteers())
+
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, "/api/pairing-rules", ""},
+		{http.MethodPost, "/api/pairing-rules", `{"volunteerA":"alice","volunteerB":"bob","kind":"apart"}`},
+		{http.MethodDelete, "/api/pairing-rules/pr-1", ""},
+	} {
+		rec := doRequest(t, handler, tc.method, tc.path, tc.body)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, "%s %s", tc.method, tc.path)
+	}
+}
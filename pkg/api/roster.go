@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// RosterFetchFunc reads the roster from wherever it was kept before the
+// database: the volunteer sheet, with the server's own service account, or the
+// dev CSV. Injected by the composition root so the handler can import and sync
+// a roster without importing the Sheets client.
+type RosterFetchFunc func(ctx context.Context) ([]model.Volunteer, error)
+
+// EnableRosterImport turns on POST /volunteers/import, reading the roster it
+// copies through fetch. Without it the endpoint answers 503: a server that
+// cannot reach the sheet has nothing to import from.
+func (h *Handler) EnableRosterImport(fetch RosterFetchFunc) {
+	h.fetchRoster = fetch
+}
+
+// volunteerRequest is a volunteer as an admin states them, for a creation and
+// an edit alike. state is active, onboarding, paused or left; pausedUntil is
+// "YYYY-MM-DD", and only for paused. roles are named.
+type volunteerRequest struct {
+	FirstName   string   `json:"firstName"`
+	LastName    string   `json:"lastName"`
+	Roles       []string `json:"roles"`
+	State       string   `json:"state"`
+	PausedUntil string   `json:"pausedUntil,omitempty"`
+	Gender      string   `json:"gender"`
+	Email       string   `json:"email"`
+	Group       string   `json:"group"`
+}
+
+func (r volunteerRequest) params() services.VolunteerParams {
+	return services.VolunteerParams{
+		FirstName:   r.FirstName,
+		LastName:    r.LastName,
+		Roles:       r.Roles,
+		State:       r.State,
+		PausedUntil: r.PausedUntil,
+		Gender:      r.Gender,
+		Email:       r.Email,
+		Group:       r.Group,
+	}
+}
+
+type importRosterResponse struct {
+	Imported int `json:"imported"`
+}
+
+// decodeVolunteerRequest reads a volunteerRequest, writing the 400 itself
+// when it cannot.
+func (h *Handler) decodeVolunteerRequest(w http.ResponseWriter, r *http.Request) (volunteerRequest, bool) {
+	var req volunteerRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return req, false
+	}
+	return req, true
+}
+
+// handleCreateVolunteer adds somebody to a roster kept in the database. 201
+// with them as the roster now reads them; 409 when the roster is the sheet.
+func (h *Handler) handleCreateVolunteer(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeVolunteerRequest(w, r)
+	if !ok {
+		return
+	}
+	created, err := services.CreateVolunteer(r.Context(), h.store, h.cfg, req.params(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, toVolunteerResponse(*created, time.Now()))
+}
+
+// handleUpdateVolunteer rewrites one volunteer on a roster kept in the
+// database.
+func (h *Handler) handleUpdateVolunteer(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.decodeVolunteerRequest(w, r)
+	if !ok {
+		return
+	}
+	updated, err := services.UpdateVolunteer(r.Context(), h.store, h.cfg, r.PathValue("id"), req.params(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, toVolunteerResponse(*updated, time.Now()))
+}
+
+// handleDeleteVolunteer removes somebody added by mistake. 204, or 409 for
+// anybody a rota names — they are marked left instead.
+func (h *Handler) handleDeleteVolunteer(w http.ResponseWriter, r *http.Request) {
+	if err := services.DeleteVolunteer(r.Context(), h.store, h.cfg, r.PathValue("id"), h.logger); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleImportRoster copies the sheet's roster (the dev CSV's, in dev mode)
+// into an empty roster in the database. A sheet that cannot be read is a 502,
+// as a failed sync is; a roster with anybody on it already is a 409.
+func (h *Handler) handleImportRoster(w http.ResponseWriter, r *http.Request) {
+	if h.fetchRoster == nil {
+		h.logger.Error("Roster import requested but no roster to import from is configured")
+		h.writeError(w, http.StatusServiceUnavailable, "roster import unavailable")
+		return
+	}
+
+	fetched, err := h.fetchRoster(r.Context())
+	if err != nil {
+		h.logger.Warn("Failed to read the roster to import", zap.Error(err))
+		h.writeError(w, http.StatusBadGateway, "could not read the roster to import")
+		return
+	}
+
+	count, err := services.ImportRoster(r.Context(), h.store, fetched, h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.logger.Info("Roster imported", zap.Int("count", count), zap.String("by", adminEmail(r.Context())))
+	h.writeJSON(w, http.StatusOK, importRosterResponse{Imported: count})
+}
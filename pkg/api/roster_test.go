@@ -0,0 +1,232 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The roster methods of mockStore, over a slice in insertion order.
+func (m *mockStore) GetVolunteers(context.Context) ([]db.Volunteer, error) {
+	return m.roster, nil
+}
+
+func (m *mockStore) GetVolunteerByID(_ context.Context, id string) (*db.Volunteer, error) {
+	for i := range m.roster {
+		if m.roster[i].ID == id {
+			return &m.roster[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockStore) InsertVolunteer(_ context.Context, volunteer db.Volunteer) error {
+	m.roster = append(m.roster, volunteer)
+	return nil
+}
+
+func (m *mockStore) UpdateVolunteer(_ context.Context, volunteer db.Volunteer) (bool, error) {
+	for i := range m.roster {
+		if m.roster[i].ID == volunteer.ID {
+			m.roster[i] = volunteer
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockStore) DeleteVolunteerByID(_ context.Context, id string) (bool, error) {
+	if m.history[id] {
+		return false, db.ErrVolunteerHasHistory
+	}
+	for i := range m.roster {
+		if m.roster[i].ID == id {
+			m.roster = append(m.roster[:i], m.roster[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockStore) ImportVolunteers(_ context.Context, volunteers []db.Volunteer) error {
+	if len(m.roster) > 0 {
+		return db.ErrRosterNotEmpty
+	}
+	m.roster = append(m.roster, volunteers...)
+	return nil
+}
+
+var databaseRosterCfg = &config.Config{Roster: &config.RosterConfig{Source: config.RosterSourceDatabase}}
+
+// newImportHandler is newTestHandlerWithConfig with the import turned on,
+// reading the roster it copies from fetch.
+func newImportHandler(store *mockStore, cfg *config.Config, fetch RosterFetchFunc) http.Handler {
+	h := NewHandler(store, &mockVolunteerClient{}, cfg, newTestAuthenticator(), nil, nil, zap.NewNop())
+	h.EnableRosterImport(fetch)
+	return h.Routes()
+}
+
+func TestListVolunteersEndpoint_ReportsTheSource(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  *config.Config
+		want string
+	}{
+		{name: "unset", cfg: apiTestCfg, want: "sheet"},
+		{name: "database", cfg: databaseRosterCfg, want: "database"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := doRequest(t, newTestHandlerWithConfig(&mockStore{}, rosterVolunteers(), tc.cfg), http.MethodGet, "/api/volunteers", "", adminCookie())
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+			var resp struct {
+				Source string `json:"source"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.want, resp.Source)
+		})
+	}
+}
+
+func TestCreateVolunteerEndpoint(t *testing.T) {
+	store := &mockStore{}
+	handler := newTestHandlerWithConfig(store, &mockVolunteerClient{}, databaseRosterCfg)
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/volunteers",
+		`{"firstName":"Alice","lastName":"Adams","roles":["Service volunteer"],"state":"paused","pausedUntil":"2999-01-01","gender":"Female","email":"alice@example.com","group":""}`,
+		adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created struct {
+		ID          string   `json:"id"`
+		FirstName   string   `json:"firstName"`
+		Email       string   `json:"email"`
+		Roles       []string `json:"roles"`
+		State       string   `json:"state"`
+		PausedUntil string   `json:"pausedUntil"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "Alice", created.FirstName)
+	assert.Equal(t, "alice@example.com", created.Email)
+	assert.Equal(t, []string{"Service volunteer"}, created.Roles)
+	assert.Equal(t, "paused", created.State)
+	assert.Equal(t, "2999-01-01", created.PausedUntil)
+
+	require.Len(t, store.roster, 1)
+	assert.Equal(t, "Paused until 2999-01-01", store.roster[0].Status, "stored as the sheet spells it")
+	assert.Equal(t, []string{"role-service-volunteer"}, store.roster[0].RoleIDs)
+}
+
+func TestCreateVolunteerEndpoint_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  *config.Config
+		body string
+		want int
+	}{
+		{name: "the sheet is the roster", cfg: apiTestCfg, body: `{"firstName":"Alice","state":"active"}`, want: http.StatusConflict},
+		{name: "no first name", cfg: databaseRosterCfg, body: `{"firstName":"","state":"active"}`, want: http.StatusBadRequest},
+		{name: "unknown role", cfg: databaseRosterCfg, body: `{"firstName":"Alice","state":"active","roles":["Chef"]}`, want: http.StatusBadRequest},
+		{name: "unknown field", cfg: databaseRosterCfg, body: `{"firstName":"Alice","state":"active","status":"Active"}`, want: http.StatusBadRequest},
+		{name: "not JSON", cfg: databaseRosterCfg, body: `nope`, want: http.StatusBadRequest},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &mockStore{}
+			rec := doRequest(t, newTestHandlerWithConfig(store, &mockVolunteerClient{}, tc.cfg), http.MethodPost, "/api/volunteers", tc.body, adminCookie())
+			assert.Equal(t, tc.want, rec.Code, rec.Body.String())
+			assert.Empty(t, store.roster, "nothing is written")
+		})
+	}
+}
+
+func TestVolunteerEndpoints_RequireAdmin(t *testing.T) {
+	handler := newTestHandlerWithConfig(&mockStore{}, &mockVolunteerClient{}, databaseRosterCfg)
+	for _, req := range []struct{ method, target string }{
+		{http.MethodPost, "/api/volunteers"},
+		{http.MethodPut, "/api/volunteers/v1"},
+		{http.MethodDelete, "/api/volunteers/v1"},
+		{http.MethodPost, "/api/volunteers/import"},
+	} {
+		rec := doRequest(t, handler, req.method, req.target, `{}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, req.method+" "+req.target)
+	}
+}
+
+func TestUpdateVolunteerEndpoint(t *testing.T) {
+	store := &mockStore{roster: []db.Volunteer{{ID: "v1", FirstName: "Alice", Status: "Active"}}}
+	handler := newTestHandlerWithConfig(store, &mockVolunteerClient{}, databaseRosterCfg)
+
+	rec := doRequest(t, handler, http.MethodPut, "/api/volunteers/v1", `{"firstName":"Alice","lastName":"Jones","state":"left"}`, adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "Left", store.roster[0].Status)
+	assert.Equal(t, "Jones", store.roster[0].LastName)
+
+	rec = doRequest(t, handler, http.MethodPut, "/api/volunteers/nobody", `{"firstName":"No","state":"active"}`, adminCookie())
+	assert.Equal(t, http.StatusNotFound, rec.Code, rec.Body.String())
+}
+
+func TestDeleteVolunteerEndpoint(t *testing.T) {
+	store := &mockStore{
+		roster:  []db.Volunteer{{ID: "v1", FirstName: "Alice"}, {ID: "v2", FirstName: "Bob"}},
+		history: map[string]bool{"v1": true},
+	}
+	handler := newTestHandlerWithConfig(store, &mockVolunteerClient{}, databaseRosterCfg)
+
+	rec := doRequest(t, handler, http.MethodDelete, "/api/volunteers/v1", "", adminCookie())
+	assert.Equal(t, http.StatusConflict, rec.Code, "somebody on a rota is marked left, not deleted")
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/volunteers/v2", "", adminCookie())
+	assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Len(t, store.roster, 1)
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/volunteers/v2", "", adminCookie())
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestImportRosterEndpoint(t *testing.T) {
+	sheet := func(context.Context) ([]model.Volunteer, error) {
+		return []model.Volunteer{
+			{ID: "alice", FirstName: "Alice", Status: "Active", Roles: []string{"Team lead"}},
+			{ID: "bob", FirstName: "Bob", Status: "Left"},
+		}, nil
+	}
+
+	t.Run("into an empty roster", func(t *testing.T) {
+		store := &mockStore{}
+		rec := doRequest(t, newImportHandler(store, apiTestCfg, sheet), http.MethodPost, "/api/volunteers/import", "", adminCookie())
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.JSONEq(t, `{"imported":2}`, rec.Body.String())
+		require.Len(t, store.roster, 2)
+		assert.Equal(t, []string{"role-team-lead"}, store.roster[0].RoleIDs)
+	})
+
+	t.Run("into one with somebody on it", func(t *testing.T) {
+		store := &mockStore{roster: []db.Volunteer{{ID: "carol", FirstName: "Carol"}}}
+		rec := doRequest(t, newImportHandler(store, databaseRosterCfg, sheet), http.MethodPost, "/api/volunteers/import", "", adminCookie())
+		assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+		assert.Len(t, store.roster, 1)
+	})
+
+	t.Run("the sheet cannot be read", func(t *testing.T) {
+		store := &mockStore{}
+		failing := func(context.Context) ([]model.Volunteer, error) { return nil, errors.New("quota exceeded") }
+		rec := doRequest(t, newImportHandler(store, databaseRosterCfg, failing), http.MethodPost, "/api/volunteers/import", "", adminCookie())
+		assert.Equal(t, http.StatusBadGateway, rec.Code)
+		assert.Empty(t, store.roster)
+	})
+
+	t.Run("nothing to import from", func(t *testing.T) {
+		rec := doRequest(t, newTestHandlerWithConfig(&mockStore{}, &mockVolunteerClient{}, databaseRosterCfg), http.MethodPost, "/api/volunteers/import", "", adminCookie())
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	})
+}
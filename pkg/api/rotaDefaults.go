@@ -37,6 +37,14 @@ type rotaDefaultsResponse struct {
 	// list in Go is the only one (ADR 0006).
 	AllocationSettings    allocationSettingsResponse `json:"allocationSettings"`
 	SwitchableConstraints []switchableConstraint     `json:"switchableConstraints"`
+	// EmailTemplates is every email an admin can reword, with the wording it
+	// goes out with now, and EmailTemplateVariables is what that wording can
+	// say. Sent for the reason the registry of rules is: the list lives in Go.
+	EmailTemplates         []emailTemplateResponse `json:"emailTemplates"`
+	EmailTemplateVariables []emailTemplateVariable `json:"emailTemplateVariables"`
+	// SwapsNeedApproval is whether a swap a volunteer takes waits for an admin
+	// before the rota changes. Unset reads as false.
+	SwapsNeedApproval bool `json:"swapsNeedApproval"`
 }
 
 // switchableConstraint is one optional allocator rule as the screen needs it:
@@ -55,9 +63,17 @@ type switchableConstraint struct {
 // Enabled carries an entry for every rule in the registry, including the ones
 // nobody has answered — the rule that an unanswered constraint is off is
 // settled here rather than in the client, so there is one place it is stated.
+//
+// The fairness horizon is sent as stored, zeros included: no rotas and no
+// months is the previous rota alone, and the screen says so in those words.
 type allocationSettingsResponse struct {
-	Enabled      map[string]bool `json:"enabled"`
-	MaxFrequency float64         `json:"maxFrequency"`
+	Enabled        map[string]bool `json:"enabled"`
+	MaxFrequency   float64         `json:"maxFrequency"`
+	FairnessRotas  int             `json:"fairnessRotas"`
+	FairnessMonths int             `json:"fairnessMonths"`
+	FairnessDecay  float64         `json:"fairnessDecay"`
+
+	FairnessSkipsNoShows bool `json:"fairnessSkipsNoShows"`
 }
 
 // allocationSettingsRequest is the allocation-settings section of the settings
@@ -65,8 +81,13 @@ type allocationSettingsResponse struct {
 // shows every rule at once, and a partial write could not express switching one
 // off.
 type allocationSettingsRequest struct {
-	Enabled      map[string]bool `json:"enabled"`
-	MaxFrequency float64         `json:"maxFrequency"`
+	Enabled        map[string]bool `json:"enabled"`
+	MaxFrequency   float64         `json:"maxFrequency"`
+	FairnessRotas  int             `json:"fairnessRotas"`
+	FairnessMonths int             `json:"fairnessMonths"`
+	FairnessDecay  float64         `json:"fairnessDecay"`
+
+	FairnessSkipsNoShows bool `json:"fairnessSkipsNoShows"`
 }
 
 // seatResponse is one line of a Shape: this many of this Role.
@@ -217,12 +238,15 @@ func toRotaDefaultsResponse(defaults model.RotaDefaults, shape model.Shape) rota
 	}
 
 	return rotaDefaultsResponse{
-		ShiftStartTime:        defaults.ShiftStartTime,
-		ShiftEndTime:          defaults.ShiftEndTime,
-		ShiftTimezone:         defaults.Timezone(),
-		DefaultShape:          toSeatResponses(shape),
-		AllocationSettings:    toAllocationSettingsResponse(defaults.AllocationSettings),
-		SwitchableConstraints: constraints,
+		ShiftStartTime:         defaults.ShiftStartTime,
+		ShiftEndTime:           defaults.ShiftEndTime,
+		ShiftTimezone:          defaults.Timezone(),
+		DefaultShape:           toSeatResponses(shape),
+		AllocationSettings:     toAllocationSettingsResponse(defaults.AllocationSettings),
+		SwitchableConstraints:  constraints,
+		EmailTemplates:         toEmailTemplateResponses(defaults.EmailTemplates),
+		EmailTemplateVariables: toEmailTemplateVariables(),
+		SwapsNeedApproval:      defaults.SwapsNeedApproval,
 	}
 }
 
@@ -234,7 +258,15 @@ func toAllocationSettingsResponse(settings model.AllocationSettings) allocationS
 		enabled[c.Name] = settings.IsEnabled(c.Name)
 	}
 
-	return allocationSettingsResponse{Enabled: enabled, MaxFrequency: settings.MaxFrequency}
+	return allocationSettingsResponse{
+		Enabled:        enabled,
+		MaxFrequency:   settings.MaxFrequency,
+		FairnessRotas:  settings.FairnessRotas,
+		FairnessMonths: settings.FairnessMonths,
+		FairnessDecay:  settings.FairnessDecay,
+
+		FairnessSkipsNoShows: settings.FairnessSkipsNoShows,
+	}
 }
 
 // handleSaveAllocationSettings writes which optional allocator rules apply and
@@ -251,8 +283,13 @@ func (h *Handler) handleSaveAllocationSettings(w http.ResponseWriter, r *http.Re
 	}
 
 	settings, err := services.SaveAllocationSettings(r.Context(), h.store, services.AllocationSettingsParams{
-		Enabled:      req.Enabled,
-		MaxFrequency: req.MaxFrequency,
+		Enabled:        req.Enabled,
+		MaxFrequency:   req.MaxFrequency,
+		FairnessRotas:  req.FairnessRotas,
+		FairnessMonths: req.FairnessMonths,
+		FairnessDecay:  req.FairnessDecay,
+
+		FairnessSkipsNoShows: req.FairnessSkipsNoShows,
 	}, h.logger)
 	if err != nil {
 		h.writeServiceError(w, err)
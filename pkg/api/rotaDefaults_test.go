@@ -215,10 +215,29 @@ func TestSaveAllocationSettingsEndpoint(t *testing.T) {
 	assert.JSONEq(t, `{
 		"enabled": {"max_frequency": true, "male_required": true,
 		            "no_back_to_back": false, "one_shift_per_month": false},
-		"maxFrequency": 0.5
+		"maxFrequency": 0.5,
+		"fairnessRotas": 0, "fairnessMonths": 0, "fairnessDecay": 0,
+		"fairnessSkipsNoShows": false
 	}`, rec.Body.String())
 }
 
+func TestSaveAllocationSettingsCarriesTheFairnessHorizon(t *testing.T) {
+	store := &mockStore{rotaDefaults: &db.RotaDefaults{}}
+
+	rec := doRequest(t, newTestHandler(store, testVolunteers()), http.MethodPut,
+		"/api/rota-defaults/allocation-settings",
+		`{"enabled":{},"fairnessRotas":3,"fairnessDecay":0.5}`, adminCookie())
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Len(t, store.savedAllocationSettings, 1)
+	assert.JSONEq(t, `{"fairnessRotas":3,"fairnessDecay":0.5}`, store.savedAllocationSettings[0])
+
+	var body allocationSettingsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, 3, body.FairnessRotas)
+	assert.Equal(t, 0.5, body.FairnessDecay)
+}
+
 // Saving one section of the settings leaves the others alone — the store is
 // told about this section only.
 func TestSaveAllocationSettingsLeavesTheShiftTimesAlone(t *testing.T) {
@@ -240,6 +259,7 @@ func TestSaveAllocationSettingsRejectsBadInput(t *testing.T) {
 		"frequency on with no value": `{"enabled":{"max_frequency":true}}`,
 		"frequency out of range":     `{"enabled":{"max_frequency":true},"maxFrequency":4}`,
 		"unknown field":              `{"enabled":{},"maxAllocationFrequency":0.5}`,
+		"rotas and months both":      `{"enabled":{},"fairnessRotas":3,"fairnessMonths":6}`,
 		"not json":                   `nonsense`,
 	}
 
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// handleExportRota serves a rota as a download — CSV unless ?format says json
+// or xlsx. It is built as publishing builds it, Alterations applied, so the
+// file says who is on now, not who the allocation first put there. The rota
+// is built before anything is written, so a failure is an error response
+// rather than half a file.
+func (h *Handler) handleExportRota(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = services.RotaExportCSV
+	}
+	if err := services.CheckRotaExportFormat(format); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	rota, err := services.BuildPublishedRota(r.Context(), h.store, h.volunteers, h.cfg, h.logger, r.PathValue("id"))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", services.RotaExportContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="`+services.RotaExportFilename(rota, format)+`"`)
+	if err := services.WriteRotaExport(w, rota, format); err != nil {
+		h.logger.Error("Failed to write rota export", zap.String("rota_id", r.PathValue("id")), zap.Error(err))
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+	"github.com/jakechorley/ilford-drop-in/pkg/utils/xlsx"
+)
+
+func TestExportRota(t *testing.T) {
+	handler := newTestHandler(activityTestStore(), testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/rotations/rota-1/export", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="rota-2026-03-01.csv"`, rec.Header().Get("Content-Disposition"))
+	rows, err := csv.NewReader(strings.NewReader(rec.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Date", rows[0][0])
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/rotations/rota-1/export?format=json", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var export services.RotaExport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &export))
+	require.Len(t, export.Shifts, 1)
+	assert.Equal(t, "2026-03-01", export.Shifts[0].Date)
+	assert.Len(t, export.Shifts[0].Roles["Team lead"], 1)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/rotations/rota-1/export?format=xlsx", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, xlsx.ContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "rota-2026-03-01.xlsx")
+}
+
+func TestExportRotaRefusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		admin    bool
+		wantCode int
+	}{
+		{name: "not an admin", target: "/api/rotations/rota-1/export", wantCode: http.StatusUnauthorized},
+		{name: "no such rota", target: "/api/rotations/rota-9/export", admin: true, wantCode: http.StatusNotFound},
+		{name: "unknown format", target: "/api/rotations/rota-1/export?format=pdf", admin: true, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestHandler(activityTestStore(), testVolunteers())
+			var cookies []*http.Cookie
+			if tt.admin {
+				cookies = append(cookies, adminCookie())
+			}
+			rec := doRequest(t, handler, http.MethodGet, tt.target, "", cookies...)
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+		})
+	}
+}
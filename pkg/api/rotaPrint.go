@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// handlePrintRota serves a rota as a PDF to print. Inline rather than an
+// attachment: it is opened to be printed, and the browser's viewer is where
+// the print button is.
+func (h *Handler) handlePrintRota(w http.ResponseWriter, r *http.Request) {
+	overview, err := services.BuildRotaOverview(r.Context(), h.store, h.volunteers, h.cfg, h.logger, r.PathValue("id"))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteRotaOverviewPDF(&buf, overview); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writePDF(w, services.RotaOverviewFilename(overview), buf.Bytes())
+}
+
+// handleSignInSheet serves a shift's sign-in sheet as a PDF to print, with
+// ?walkIns blank lines for people nobody was expecting.
+func (h *Handler) handleSignInSheet(w http.ResponseWriter, r *http.Request) {
+	walkIns := services.DefaultWalkInRows
+	if raw := r.URL.Query().Get("walkIns"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "walkIns must be a number")
+			return
+		}
+		walkIns = n
+	}
+
+	sheet, err := services.BuildSignInSheet(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("id"))
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteSignInSheetPDF(&buf, sheet, walkIns); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+	h.writePDF(w, services.SignInSheetFilename(sheet), buf.Bytes())
+}
+
+// writePDF sends a finished PDF. The document is drawn into memory first —
+// it is a few kilobytes — so that a failure drawing it is an error response
+// rather than half a file.
+func (h *Handler) writePDF(w http.ResponseWriter, filename string, body []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `inline; filename="`+filename+`"`)
+	if _, err := w.Write(body); err != nil {
+		h.logger.Error("Failed to write PDF", zap.String("filename", filename), zap.Error(err))
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintRota(t *testing.T) {
+	handler := newTestHandler(activityTestStore(), testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/rotations/rota-1/print", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `inline; filename="rota-2026-03-01.pdf"`, rec.Header().Get("Content-Disposition"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "%PDF-"))
+}
+
+func TestSignInSheet(t *testing.T) {
+	handler := newTestHandler(attendanceTestStore(), testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/shifts/"+attendanceTestShiftID+"/sign-in-sheet", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "(Alice Adams)")
+	assert.Contains(t, rec.Body.String(), "(Walk-ins)")
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/shifts/"+attendanceTestShiftID+"/sign-in-sheet?walkIns=0", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.NotContains(t, rec.Body.String(), "(Walk-ins)")
+}
+
+func TestPrintRefusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		admin    bool
+		wantCode int
+	}{
+		{name: "rota, not an admin", target: "/api/rotations/rota-1/print", wantCode: http.StatusUnauthorized},
+		{name: "no such rota", target: "/api/rotations/rota-9/print", admin: true, wantCode: http.StatusNotFound},
+		{name: "sheet, not an admin", target: "/api/shifts/" + attendanceTestShiftID + "/sign-in-sheet", wantCode: http.StatusUnauthorized},
+		{name: "no such shift", target: "/api/shifts/shift-9/sign-in-sheet", admin: true, wantCode: http.StatusNotFound},
+		{name: "walk-ins not a number", target: "/api/shifts/" + attendanceTestShiftID + "/sign-in-sheet?walkIns=lots", admin: true, wantCode: http.StatusBadRequest},
+		{name: "walk-ins negative", target: "/api/shifts/" + attendanceTestShiftID + "/sign-in-sheet?walkIns=-1", admin: true, wantCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cookies []*http.Cookie
+			if tt.admin {
+				cookies = append(cookies, adminCookie())
+			}
+			rec := doRequest(t, newTestHandler(attendanceTestStore(), testVolunteers()), http.MethodGet, tt.target, "", cookies...)
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -13,6 +13,10 @@ type shiftResponse struct {
 	// external language everywhere else, but identity is the UUID (ADR 0001).
 	ID   string `json:"id"`
 	Date string `json:"date"`
+	// RotaID is the rota the shift belongs to. Shifts are listed by date
+	// across rotas, and this is how a client gets from one to its rota as a
+	// whole: to print it, or export it.
+	RotaID string `json:"rotaId"`
 	// Start and End are when the shift runs, as the shift itself holds them:
 	// local wall-clock times in the drop-in's own zone, "2026-01-11T19:30:00",
 	// with no offset on the end of them.
@@ -81,6 +85,7 @@ func (h *Handler) handleListShifts(w http.ResponseWriter, r *http.Request) {
 		resp.Shifts = append(resp.Shifts, shiftResponse{
 			ID:        shift.ID,
 			Date:      shift.Date,
+			RotaID:    shift.RotaID,
 			Start:     shift.StartAt,
 			End:       shift.EndAt,
 			Closed:    shift.Closed,
@@ -0,0 +1,259 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// swapLink builds the absolute URL of a volunteer's swap page, for a swap
+// offer email to hand them. The token is their availability link's.
+func swapLink(r *http.Request, token string) string {
+	return siteURL(r) + "/swaps/" + url.PathEscape(token)
+}
+
+// swapShiftResponse is one of the volunteer's own upcoming shifts. requestId
+// and requestStatus are their live ask to give it up, absent when there is
+// none; takenBy is the first name of whoever has taken it while it waits for
+// an admin.
+type swapShiftResponse struct {
+	ShiftID       string `json:"shiftId"`
+	Date          string `json:"date"`
+	Start         string `json:"start"`
+	End           string `json:"end"`
+	Role          string `json:"role,omitempty"`
+	RequestID     string `json:"requestId,omitempty"`
+	RequestStatus string `json:"requestStatus,omitempty"`
+	TakenBy       string `json:"takenBy,omitempty"`
+}
+
+// swapOfferResponse is somebody else's shift this volunteer could take. from
+// is a first name only: the page is behind a link anyone holding it can read,
+// and a first name is all a volunteer needs to know whose shift it is.
+type swapOfferResponse struct {
+	RequestID string `json:"requestId"`
+	ShiftID   string `json:"shiftId"`
+	Date      string `json:"date"`
+	Start     string `json:"start"`
+	End       string `json:"end"`
+	Role      string `json:"role,omitempty"`
+	From      string `json:"from"`
+}
+
+// swapPageResponse is what is behind a volunteer's link once their rota is
+// allocated. Both lists are always lists, never null: having no shifts left
+// and being offered none are ordinary states for the page to say.
+type swapPageResponse struct {
+	VolunteerName string              `json:"volunteerName"`
+	Shifts        []swapShiftResponse `json:"shifts"`
+	Offers        []swapOfferResponse `json:"offers"`
+	// Whether a shift taken now waits for an admin before the rota changes, so
+	// the page can say so before anybody taps, not after.
+	NeedsApproval bool `json:"needsApproval"`
+	// Whether the volunteer holds the leading Role, so the page can point
+	// them at the shifts they have attendance to record on.
+	LeadsShifts bool `json:"leadsShifts"`
+}
+
+type requestSwapRequest struct {
+	ShiftID string `json:"shiftId"`
+}
+
+// swapRequestResponse is one live swap as the admins' list shows it. Names
+// travel alongside the ids because the list is read by a person, and the ids
+// because an answer to it names the request, not the people.
+type swapRequestResponse struct {
+	ID            string `json:"id"`
+	ShiftID       string `json:"shiftId"`
+	Date          string `json:"date"`
+	Role          string `json:"role,omitempty"`
+	Status        string `json:"status"`
+	VolunteerID   string `json:"volunteerId"`
+	VolunteerName string `json:"volunteerName"`
+	TakenBy       string `json:"takenBy,omitempty"`
+	TakenByName   string `json:"takenByName,omitempty"`
+	RequestedAt   string `json:"requestedAt"`
+	TakenAt       string `json:"takenAt,omitempty"`
+}
+
+// swapSettingsRequest is the swaps section of the settings screen. One
+// switch, stated whole like every other section.
+type swapSettingsRequest struct {
+	SwapsNeedApproval bool `json:"swapsNeedApproval"`
+}
+
+// handleSwapPage serves the volunteer's swap page: their upcoming shifts, and
+// the ones other volunteers have asked to give up that they could take.
+//
+// Public, on the same token as the availability form — the link a volunteer
+// was sent to say when they could work is the one they come back to when they
+// no longer can, so there is no second link to hand out or lose. The form and
+// this page never answer for the same rota at once: the form turns a link away
+// once its rota is allocated, and this turns it away until then.
+func (h *Handler) handleSwapPage(w http.ResponseWriter, r *http.Request) {
+	page, err := services.GetSwapPage(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), time.Now())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toSwapPageResponse(page))
+}
+
+// handleRequestSwap asks to give up one of the link's shifts, and answers with
+// the page as it now stands.
+func (h *Handler) handleRequestSwap(w http.ResponseWriter, r *http.Request) {
+	var req requestSwapRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	page, err := services.RequestSwap(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), req.ShiftID, time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, toSwapPageResponse(page))
+}
+
+// handleWithdrawSwap takes an ask back. Only the link's own: a request id
+// belonging to somebody else is not found, which is what it is to this link.
+func (h *Handler) handleWithdrawSwap(w http.ResponseWriter, r *http.Request) {
+	page, err := services.WithdrawSwap(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), r.PathValue("id"), time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toSwapPageResponse(page))
+}
+
+// handleAcceptSwap takes a shift on offer. The first to accept gets it; anyone
+// after is told somebody else has. Without approval the rota has changed by
+// the time this answers — the shift is among the link's own on the page it
+// returns — and with approval it is waiting for an admin.
+func (h *Handler) handleAcceptSwap(w http.ResponseWriter, r *http.Request) {
+	page, err := services.AcceptSwap(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("token"), r.PathValue("id"), time.Now(), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, toSwapPageResponse(page))
+}
+
+// handleListSwapRequests is every live swap, for the admins: on offer, and
+// taken and waiting for one of them.
+func (h *Handler) handleListSwapRequests(w http.ResponseWriter, r *http.Request) {
+	views, err := services.ListSwapRequests(r.Context(), h.store, h.volunteers, h.cfg)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]swapRequestResponse, 0, len(views))
+	for _, v := range views {
+		item := swapRequestResponse{
+			ID:            v.ID,
+			ShiftID:       v.ShiftID,
+			Date:          v.Date,
+			Role:          v.Role,
+			Status:        v.Status,
+			VolunteerID:   v.VolunteerID,
+			VolunteerName: v.VolunteerName,
+			TakenBy:       v.TakenBy,
+			TakenByName:   v.TakenByName,
+			RequestedAt:   v.RequestedAt.UTC().Format(time.RFC3339),
+		}
+		if v.TakenAt != nil {
+			item.TakenAt = v.TakenAt.UTC().Format(time.RFC3339)
+		}
+		resp = append(resp, item)
+	}
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+// handleApproveSwap makes a waiting swap, as the admin approving it. It
+// answers as POST /alterations does, because it is one: the Cover is the
+// record of the change, and the admin who said yes is who made it.
+func (h *Handler) handleApproveSwap(w http.ResponseWriter, r *http.Request) {
+	result, err := services.ApproveSwap(r.Context(), h.store, h.volunteers, h.cfg, r.PathValue("id"), adminEmail(r.Context()), h.logger)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, createAlterationResponse{
+		CoverID:     result.CoverID,
+		Alterations: toAlterationResponses(result.Alterations, result.DatesByShiftID),
+	})
+}
+
+// handleDeclineSwap refuses a waiting swap. The asker keeps the shift.
+func (h *Handler) handleDeclineSwap(w http.ResponseWriter, r *http.Request) {
+	if err := services.DeclineSwap(r.Context(), h.store, r.PathValue("id"), adminEmail(r.Context()), h.logger); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleSaveSwapSettings writes whether a taken swap waits for an admin.
+func (h *Handler) handleSaveSwapSettings(w http.ResponseWriter, r *http.Request) {
+	var req swapSettingsRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+
+	if err := services.SaveSwapSettings(r.Context(), h.store, req.SwapsNeedApproval, h.logger); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, swapSettingsRequest(req))
+}
+
+func toSwapPageResponse(page *services.SwapPage) swapPageResponse {
+	resp := swapPageResponse{
+		VolunteerName: page.VolunteerName,
+		Shifts:        make([]swapShiftResponse, 0, len(page.Shifts)),
+		Offers:        make([]swapOfferResponse, 0, len(page.Offers)),
+		NeedsApproval: page.NeedsApproval,
+		LeadsShifts:   page.LeadsShifts,
+	}
+	for _, s := range page.Shifts {
+		resp.Shifts = append(resp.Shifts, swapShiftResponse{
+			ShiftID:       s.ShiftID,
+			Date:          s.Date,
+			Start:         s.Start,
+			End:           s.End,
+			Role:          s.Role,
+			RequestID:     s.RequestID,
+			RequestStatus: s.RequestStatus,
+			TakenBy:       s.TakenBy,
+		})
+	}
+	for _, o := range page.Offers {
+		resp.Offers = append(resp.Offers, swapOfferResponse{
+			RequestID: o.RequestID,
+			ShiftID:   o.ShiftID,
+			Date:      o.Date,
+			Start:     o.Start,
+			End:       o.End,
+			Role:      o.Role,
+			From:      o.From,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,281 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The swap-request methods of mockStore, holding the requests in memory with
+// the conditions the real updates carry, so a second take or a decline of a
+// swap already made fails here as it would there.
+func (m *mockStore) swapRequest(id string) *db.SwapRequest {
+	for i := range m.swapRequests {
+		if m.swapRequests[i].ID == id {
+			return &m.swapRequests[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockStore) InsertSwapRequest(_ context.Context, req db.SwapRequest) error {
+	if m.insertErr != nil {
+		return m.insertErr
+	}
+	for _, r := range m.swapRequests {
+		if r.ShiftID == req.ShiftID && r.VolunteerID == req.VolunteerID && (r.Status == db.SwapOpen || r.Status == db.SwapTaken) {
+			return db.ErrDuplicateSwapRequest
+		}
+	}
+	req.RequestedAt = time.Now()
+	m.swapRequests = append(m.swapRequests, req)
+	return nil
+}
+
+func (m *mockStore) GetSwapRequest(_ context.Context, id string) (*db.SwapRequest, error) {
+	if req := m.swapRequest(id); req != nil {
+		found := *req
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *mockStore) GetSwapRequestsByStatus(_ context.Context, statuses []string) ([]db.SwapRequest, error) {
+	want := idSet(statuses)
+	var out []db.SwapRequest
+	for _, r := range m.swapRequests {
+		if want[r.Status] {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) TakeSwapRequest(_ context.Context, id, volunteerID string) (bool, error) {
+	req := m.swapRequest(id)
+	if req == nil || req.Status != db.SwapOpen {
+		return false, nil
+	}
+	req.Status, req.TakenBy = db.SwapTaken, volunteerID
+	return true, nil
+}
+
+func (m *mockStore) ReopenSwapRequest(_ context.Context, id string) error {
+	if req := m.swapRequest(id); req != nil && req.Status == db.SwapTaken {
+		req.Status, req.TakenBy = db.SwapOpen, ""
+	}
+	return nil
+}
+
+func (m *mockStore) DeclineSwapRequest(_ context.Context, id, adminEmail string) (bool, error) {
+	req := m.swapRequest(id)
+	if req == nil || req.Status != db.SwapTaken {
+		return false, nil
+	}
+	req.Status, req.DecidedBy = db.SwapDeclined, adminEmail
+	return true, nil
+}
+
+func (m *mockStore) WithdrawSwapRequest(_ context.Context, id, volunteerID string) (bool, error) {
+	req := m.swapRequest(id)
+	if req == nil || req.VolunteerID != volunteerID || (req.Status != db.SwapOpen && req.Status != db.SwapTaken) {
+		return false, nil
+	}
+	req.Status = db.SwapWithdrawn
+	return true, nil
+}
+
+func (m *mockStore) CompleteSwapRequest(_ context.Context, id, coverID string) error {
+	req := m.swapRequest(id)
+	if req == nil || req.Status != db.SwapTaken {
+		return db.ErrSwapRequestNotTaken
+	}
+	req.Status, req.CoverID = db.SwapDone, coverID
+	return nil
+}
+
+func (m *mockStore) SaveSwapsNeedApproval(_ context.Context, needApproval bool) error {
+	if m.rotaDefaultsWriteErr != nil {
+		return m.rotaDefaultsWriteErr
+	}
+	updated := apiTestRotaDefaults
+	if m.rotaDefaults != nil {
+		updated = *m.rotaDefaults
+	}
+	updated.SwapsNeedApproval = needApproval
+	m.rotaDefaults = &updated
+	return nil
+}
+
+// swapTestStore is an allocated rota with one shift a week from now, which
+// Alice leads. The dates are relative because the handlers read the real
+// clock, and a swap is only ever of a shift still to come.
+func swapTestStore() *mockStore {
+	date := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	return &mockStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: date, End: date, ShiftCount: 1, AllocatedDatetime: "2026-01-01T09:00:00Z"}},
+		shifts:    []db.Shift{{ID: "shift-1", RotaID: "rota-1", Date: date}},
+		allocations: []db.Allocation{
+			{ID: "a1", ShiftID: "shift-1", VolunteerID: "alice", Role: "Team lead"},
+		},
+		availabilityRequests: []db.AvailabilityRequest{
+			{ID: "req-alice", RotaID: "rota-1", VolunteerID: "alice", Token: "tok-alice"},
+			{ID: "req-bob", RotaID: "rota-1", VolunteerID: "bob", Token: "tok-bob"},
+		},
+	}
+}
+
+func swapPageOf(t *testing.T, body []byte) swapPageResponse {
+	t.Helper()
+	var page swapPageResponse
+	require.NoError(t, json.Unmarshal(body, &page))
+	return page
+}
+
+// TestSwapRequestLifecycle: a volunteer asks through their link, the admins
+// see it, and withdrawing takes it back off everyone's list.
+func TestSwapRequestLifecycle(t *testing.T) {
+	store := swapTestStore()
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/swaps/tok-alice", "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	page := swapPageOf(t, rec.Body.Bytes())
+	require.Len(t, page.Shifts, 1)
+	assert.Equal(t, "Team lead", page.Shifts[0].Role)
+	assert.Empty(t, page.Shifts[0].RequestStatus)
+	assert.NotNil(t, page.Offers, "no offers is an empty list, not null")
+
+	rec = doRequest(t, handler, http.MethodPost, "/api/swaps/tok-alice/requests", `{"shiftId":"shift-1"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	page = swapPageOf(t, rec.Body.Bytes())
+	require.Len(t, page.Shifts, 1)
+	assert.Equal(t, db.SwapOpen, page.Shifts[0].RequestStatus)
+	requestID := page.Shifts[0].RequestID
+	require.NotEmpty(t, requestID)
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/swap-requests", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var listed []swapRequestResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	assert.Equal(t, requestID, listed[0].ID)
+	assert.Equal(t, "Alice Adams", listed[0].VolunteerName)
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/swaps/tok-bob/requests/"+requestID, "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "one link cannot withdraw another's ask")
+
+	rec = doRequest(t, handler, http.MethodDelete, "/api/swaps/tok-alice/requests/"+requestID, "")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Empty(t, swapPageOf(t, rec.Body.Bytes()).Shifts[0].RequestStatus)
+	assert.Equal(t, db.SwapWithdrawn, store.swapRequests[0].Status)
+}
+
+// TestApproveSwap: approving is a rota change in the admin's name, answered
+// as POST /alterations answers, and a swap can be answered only once.
+func TestApproveSwap(t *testing.T) {
+	store := swapTestStore()
+	store.swapRequests = []db.SwapRequest{{
+		ID: "swap-1", ShiftID: "shift-1", VolunteerID: "alice", Role: "Team lead", Status: db.SwapTaken, TakenBy: "bob",
+	}}
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/swap-requests/swap-1/approval", "", adminCookie())
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var resp createAlterationResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.NotEmpty(t, resp.CoverID)
+
+	require.NotNil(t, store.insertedCover)
+	assert.Equal(t, testAdminEmail, store.insertedCover.UserEmail)
+	assert.Equal(t, db.SwapDone, store.swapRequests[0].Status)
+
+	rec = doRequest(t, handler, http.MethodPost, "/api/swap-requests/swap-1/refusal", "", adminCookie())
+	assert.Equal(t, http.StatusConflict, rec.Code, "a swap already made is not waiting for an answer")
+}
+
+func TestDeclineSwap(t *testing.T) {
+	store := swapTestStore()
+	store.swapRequests = []db.SwapRequest{{
+		ID: "swap-1", ShiftID: "shift-1", VolunteerID: "alice", Role: "Team lead", Status: db.SwapTaken, TakenBy: "bob",
+	}}
+	handler := newTestHandler(store, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/swap-requests/swap-1/refusal", "", adminCookie())
+	require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Equal(t, db.SwapDeclined, store.swapRequests[0].Status)
+	assert.Equal(t, testAdminEmail, store.swapRequests[0].DecidedBy)
+	assert.Nil(t, store.insertedCover)
+}
+
+// TestSwapPageRefusals: what the public link is told when it asks for
+// something it cannot have.
+func TestSwapPageRefusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+	}{
+		{name: "unknown link", method: http.MethodGet, target: "/api/swaps/tok-nobody", wantCode: http.StatusNotFound},
+		{name: "somebody else's shift", method: http.MethodPost, target: "/api/swaps/tok-bob/requests", body: `{"shiftId":"shift-1"}`, wantCode: http.StatusConflict},
+		{name: "unknown field", method: http.MethodPost, target: "/api/swaps/tok-alice/requests", body: `{"shiftId":"shift-1","reason":"x"}`, wantCode: http.StatusBadRequest},
+		{name: "no such offer", method: http.MethodPost, target: "/api/swaps/tok-bob/offers/swap-9/acceptance", wantCode: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := swapTestStore()
+			rec := doRequest(t, newTestHandler(store, testVolunteers()), tt.method, tt.target, tt.body)
+
+			assert.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			assert.Empty(t, store.swapRequests)
+		})
+	}
+}
+
+// TestSwapPageWaitsForAllocation: before its rota is allocated a link is an
+// availability form, and nothing on it is anybody's to swap yet.
+func TestSwapPageWaitsForAllocation(t *testing.T) {
+	store := swapTestStore()
+	store.rotations[0].AllocatedDatetime = ""
+
+	rec := doRequest(t, newTestHandler(store, testVolunteers()), http.MethodGet, "/api/swaps/tok-alice", "")
+
+	assert.Equal(t, http.StatusConflict, rec.Code, rec.Body.String())
+}
+
+func TestSwapSettingsRoundTrip(t *testing.T) {
+	handler := newTestHandler(&mockStore{}, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodPut, "/api/rota-defaults/swap-settings", `{"swapsNeedApproval":true}`, adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	rec = doRequest(t, handler, http.MethodGet, "/api/rota-defaults", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp rotaDefaultsResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.True(t, resp.SwapsNeedApproval)
+}
+
+func TestSwapAdminEndpointsAreAdminOnly(t *testing.T) {
+	handler := newTestHandler(swapTestStore(), testVolunteers())
+
+	for _, tt := range []struct{ method, target string }{
+		{http.MethodGet, "/api/swap-requests"},
+		{http.MethodPost, "/api/swap-requests/swap-1/approval"},
+		{http.MethodPost, "/api/swap-requests/swap-1/refusal"},
+		{http.MethodPut, "/api/rota-defaults/swap-settings"},
+	} {
+		rec := doRequest(t, handler, tt.method, tt.target, `{"swapsNeedApproval":true}`)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, tt.method+" "+tt.target)
+	}
+}
@@ -1,37 +1,196 @@
 package api
 
 import (
-	"context"
+	"encoding/json"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
 )
 
-// VolunteerSyncFunc repopulates the volunteer roster from the volunteer sheet,
-// using the server's own service account credential. Injected by the
-// composition root so the Authenticator can trigger a sync without importing
-// the Sheets client. A nil value disables the sync endpoint.
-type VolunteerSyncFunc func(ctx context.Context) error
-
-// handleSync repopulates the volunteer roster from the sheet. It is gated by
-// requireAdmin, so only a logged-in admin reaches it. Unlike login there is no
-// OAuth round-trip: the server reads the sheet with its own service account, so
-// the admin only needs to be authorised — no token is taken from them. Reads
-// with the current (pre-sync) roster keep working while a sync is in flight,
-// since the store swaps the slice wholesale only once the fetch succeeds.
-func (a *Authenticator) handleSync(w http.ResponseWriter, r *http.Request) {
-	if a.syncVolunteers == nil {
-		a.logger.Error("Sync requested but no sync function configured")
-		http.Error(w, "sync unavailable", http.StatusServiceUnavailable)
+// RosterCache is the in-memory roster a sync replaces: NewVolunteerStore's.
+// An interface so the composition root can hand it over without this package
+// exporting the store type.
+type RosterCache interface {
+	Snapshot() ([]model.Volunteer, uint64)
+	ReplaceIfUnchanged(generation uint64, volunteers []model.Volunteer) bool
+	RecordFailedSync()
+	RecordSuccessfulRead()
+	SyncStatus() RosterSyncStatus
+}
+
+// syncPreviewTTL is how long a preview can be confirmed for. The sheet is
+// being edited while an admin reads the diff; confirmed an hour later, the
+// preview would apply an hour-old sheet that nobody is looking at.
+const syncPreviewTTL = 15 * time.Minute
+
+// rosterSyncs is a sync in two steps: a preview that reads the sheet and says
+// what would change, and a confirm that applies exactly what was previewed.
+// The confirm does not read the sheet again — what an admin agreed to is the
+// roster they were shown, not whatever the sheet holds by the time they click.
+//
+// There is one preview slot. A second preview replaces the first, and a
+// confirm names the preview it agrees to, so nobody can confirm a diff they
+// did not see.
+type rosterSyncs struct {
+	fetch RosterFetchFunc
+	cache RosterCache
+
+	mu      sync.Mutex
+	preview *syncPreview
+}
+
+type syncPreview struct {
+	id         string
+	takenAt    time.Time
+	generation uint64
+	volunteers []model.Volunteer
+	diff       services.RosterDiff
+}
+
+// EnableRosterSync turns on the sync endpoints, reading the sheet through
+// fetch into cache. Left off where the database is the roster: there is
+// nothing to sync it from, and they answer 503.
+func (h *Handler) EnableRosterSync(fetch RosterFetchFunc, cache RosterCache) {
+	h.syncs = &rosterSyncs{fetch: fetch, cache: cache}
+	// /auth/me is the Authenticator's, but how fresh the roster is only the
+	// Handler knows, as with completeSend.
+	h.auth.rosterSync = h.rosterSyncStatus
+}
+
+type syncPreviewResponse struct {
+	PreviewID string              `json:"previewId"`
+	Diff      services.RosterDiff `json:"diff"`
+}
+
+type applySyncRequest struct {
+	PreviewID string `json:"previewId"`
+}
+
+type applySyncResponse struct {
+	Diff services.RosterDiff `json:"diff"`
+}
+
+type rosterSyncResponse struct {
+	ID       string              `json:"id"`
+	SyncedBy string              `json:"syncedBy"`
+	SyncedAt string              `json:"syncedAt"`
+	Diff     services.RosterDiff `json:"diff"`
+}
+
+type listRosterSyncsResponse struct {
+	Syncs []rosterSyncResponse `json:"syncs"`
+}
+
+// rosterSyncHistory is how many applied syncs the volunteers screen lists.
+const rosterSyncHistory = 20
+
+// handlePreviewSync reads the sheet with the server's own service account and
+// returns how the roster would change, applying nothing. No token is taken
+// from the admin, who only needs to be one. A sheet that cannot be read is a
+// 502.
+func (h *Handler) handlePreviewSync(w http.ResponseWriter, r *http.Request) {
+	if h.syncs == nil {
+		h.logger.Error("Sync requested but no sync is configured")
+		h.writeError(w, http.StatusServiceUnavailable, "sync unavailable")
+		return
+	}
+
+	fetched, err := h.syncs.fetch(r.Context())
+	if err != nil {
+		h.syncs.cache.RecordFailedSync()
+		h.logger.Warn("Volunteer sync preview failed", zap.Error(err))
+		h.writeError(w, http.StatusBadGateway, "could not read the volunteer sheet")
+		return
+	}
+
+	h.syncs.cache.RecordSuccessfulRead()
+	current, generation := h.syncs.cache.Snapshot()
+	preview := &syncPreview{
+		id:         uuid.New().String(),
+		takenAt:    time.Now(),
+		generation: generation,
+		volunteers: fetched,
+		diff:       services.DiffRoster(current, fetched),
+	}
+
+	h.syncs.mu.Lock()
+	h.syncs.preview = preview
+	h.syncs.mu.Unlock()
+
+	h.writeJSON(w, http.StatusOK, syncPreviewResponse{PreviewID: preview.id, Diff: preview.diff})
+}
+
+// handleApplySync replaces the roster with the one a preview read, and records
+// the diff as who applied it. 409 for a preview that is not the latest, has
+// expired, or was taken of a roster that has since been replaced: the diff the
+// admin read is then not the change they would make, so they preview again.
+//
+// The roster is held in memory, so it is replaced before the record is
+// written. A record that fails to write is logged rather than undoing a sync
+// the admin confirmed.
+func (h *Handler) handleApplySync(w http.ResponseWriter, r *http.Request) {
+	if h.syncs == nil {
+		h.logger.Error("Sync requested but no sync is configured")
+		h.writeError(w, http.StatusServiceUnavailable, "sync unavailable")
 		return
 	}
 
-	if err := a.syncVolunteers(r.Context()); err != nil {
-		a.logger.Warn("Volunteer sync failed", zap.Error(err))
-		http.Error(w, "sync failed", http.StatusBadGateway)
+	var req applySyncRequest
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil || req.PreviewID == "" {
+		h.writeError(w, http.StatusBadRequest, "a sync is confirmed by the previewId its preview returned")
 		return
 	}
 
-	a.logger.Info("Volunteers synced", zap.String("by", adminEmail(r.Context())))
-	w.WriteHeader(http.StatusNoContent)
+	h.syncs.mu.Lock()
+	preview := h.syncs.preview
+	if preview == nil || preview.id != req.PreviewID || time.Since(preview.takenAt) > syncPreviewTTL {
+		h.syncs.mu.Unlock()
+		h.writeError(w, http.StatusConflict, "that preview is out of date; preview the sync again")
+		return
+	}
+	if !h.syncs.cache.ReplaceIfUnchanged(preview.generation, preview.volunteers) {
+		h.syncs.preview = nil
+		h.syncs.mu.Unlock()
+		h.writeError(w, http.StatusConflict, "the roster has changed since that preview; preview the sync again")
+		return
+	}
+	h.syncs.preview = nil
+	h.syncs.mu.Unlock()
+
+	by := adminEmail(r.Context())
+	h.logger.Info("Volunteers synced", zap.String("by", by), zap.Int("count", len(preview.volunteers)))
+	if err := services.RecordRosterSync(r.Context(), h.store, preview.diff, by, h.logger); err != nil {
+		h.logger.Error("Roster synced but the sync was not recorded", zap.Error(err))
+	}
+
+	h.writeJSON(w, http.StatusOK, applySyncResponse{Diff: preview.diff})
+}
+
+// handleListSyncs returns the most recent applied syncs, newest first: who
+// replaced the roster, when, and what it changed.
+func (h *Handler) handleListSyncs(w http.ResponseWriter, r *http.Request) {
+	records, err := services.ListRosterSyncs(r.Context(), h.store, rosterSyncHistory)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resp := listRosterSyncsResponse{Syncs: make([]rosterSyncResponse, 0, len(records))}
+	for _, s := range records {
+		resp.Syncs = append(resp.Syncs, rosterSyncResponse{
+			ID:       s.ID,
+			SyncedBy: s.SyncedBy,
+			SyncedAt: s.SyncedAt.UTC().Format(time.RFC3339),
+			Diff:     s.Diff,
+		})
+	}
+	h.writeJSON(w, http.StatusOK, resp)
 }
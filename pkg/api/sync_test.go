@@ -2,76 +2,242 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
 )
 
-// newSyncTestAuthenticator builds an Authenticator wired for the sync path: the
-// admin allowlist and session secret used by adminCookie, plus the injected
-// sync function. No OAuth config is needed — sync no longer runs an OAuth
-// round-trip.
-func newSyncTestAuthenticator(syncFn VolunteerSyncFunc) *Authenticator {
-	return &Authenticator{
-		secret:         testSecret,
-		adminEmails:    map[string]struct{}{testAdminEmail: {}},
-		logger:         zap.NewNop(),
-		syncVolunteers: syncFn,
+// The roster sync methods of mockStore, newest first.
+func (m *mockStore) InsertRosterSync(_ context.Context, sync db.RosterSync) error {
+	m.rosterSyncs = append([]db.RosterSync{sync}, m.rosterSyncs...)
+	return nil
+}
+
+func (m *mockStore) GetRosterSyncs(_ context.Context, limit int) ([]db.RosterSync, error) {
+	if len(m.rosterSyncs) > limit {
+		return m.rosterSyncs[:limit], nil
 	}
+	return m.rosterSyncs, nil
+}
+
+// fakeSheet is a sheet whose roster a test can edit between a preview and a
+// confirm, and which counts how often it is read.
+type fakeSheet struct {
+	volunteers []model.Volunteer
+	err        error
+	reads      int
+}
+
+func (s *fakeSheet) fetch(context.Context) ([]model.Volunteer, error) {
+	s.reads++
+	return s.volunteers, s.err
+}
+
+// newSyncTestHandler is the handler with a sync wired from sheet into a cache
+// already holding Alice, which it returns beside the handler.
+func newSyncTestHandler(store *mockStore, sheet *fakeSheet) (http.Handler, *volunteerStore) {
+	cache := NewVolunteerStore()
+	cache.Replace([]model.Volunteer{{ID: "alice", FirstName: "Alice", Roles: []string{"Team lead"}, Status: "Active"}})
+
+	h := NewHandler(store, cache, apiTestCfg, newTestAuthenticator(), nil, nil, zap.NewNop())
+	h.EnableRosterSync(sheet.fetch, cache)
+	return h.Routes(), cache
+}
+
+// editedSheet is the sheet after somebody's edit: Alice demoted, Bob added
+// with a Role the app does not know.
+func editedSheet() *fakeSheet {
+	return &fakeSheet{volunteers: []model.Volunteer{
+		{ID: "alice", FirstName: "Alice", Status: "Active"},
+		{ID: "bob", FirstName: "Bob", Status: "Active", UnknownRoles: []string{"Kitchen"}},
+	}}
+}
+
+type previewBody struct {
+	PreviewID string `json:"previewId"`
+	Diff      struct {
+		Added []struct {
+			ID string `json:"id"`
+		} `json:"added"`
+		Removed []struct {
+			ID string `json:"id"`
+		} `json:"removed"`
+		Changed []struct {
+			ID        string   `json:"id"`
+			RolesLost []string `json:"rolesLost"`
+		} `json:"changed"`
+		UnknownRoles []struct {
+			VolunteerID string `json:"volunteerId"`
+			Value       string `json:"value"`
+		} `json:"unknownRoles"`
+	} `json:"diff"`
 }
 
-func syncTestHandler(a *Authenticator) http.Handler {
-	mux := http.NewServeMux()
-	a.registerRoutes(mux)
-	return mux
+func preview(t *testing.T, handler http.Handler) previewBody {
+	t.Helper()
+	rec := doRequest(t, handler, http.MethodPost, "/api/volunteers/sync/preview", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body previewBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return body
+}
+
+func confirm(t *testing.T, handler http.Handler, previewID string) int {
+	t.Helper()
+	rec := doRequest(t, handler, http.MethodPost, "/api/volunteers/sync", `{"previewId":"`+previewID+`"}`, adminCookie())
+	return rec.Code
+}
+
+func cachedIDs(t *testing.T, cache *volunteerStore) []string {
+	t.Helper()
+	volunteers, _ := cache.Snapshot()
+	ids := make([]string, 0, len(volunteers))
+	for _, v := range volunteers {
+		ids = append(ids, v.ID)
+	}
+	return ids
 }
 
 func TestSync_RequiresAdmin(t *testing.T) {
-	called := false
-	a := newSyncTestAuthenticator(func(context.Context) error {
-		called = true
-		return nil
-	})
+	sheet := editedSheet()
+	handler, _ := newSyncTestHandler(&mockStore{}, sheet)
 
-	rec := doRequest(t, syncTestHandler(a), http.MethodPost, "/auth/sync", "")
-	assert.Equal(t, http.StatusUnauthorized, rec.Code, "syncing without an admin session must be rejected")
-	assert.False(t, called, "sync must not run without a verified admin session")
+	for _, req := range []struct{ method, target string }{
+		{http.MethodPost, "/api/volunteers/sync/preview"},
+		{http.MethodPost, "/api/volunteers/sync"},
+		{http.MethodGet, "/api/volunteers/syncs"},
+	} {
+		rec := doRequest(t, handler, req.method, req.target, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, req.method+" "+req.target)
+	}
+	assert.Zero(t, sheet.reads, "the sheet must not be read without a verified admin session")
 }
 
-func TestSync_Success(t *testing.T) {
-	called := false
-	a := newSyncTestAuthenticator(func(context.Context) error {
-		called = true
-		return nil
-	})
+// A preview reads the sheet and says what would change, and changes nothing.
+func TestSync_PreviewAppliesNothing(t *testing.T) {
+	handler, cache := newSyncTestHandler(&mockStore{}, editedSheet())
 
-	rec := doRequest(t, syncTestHandler(a), http.MethodPost, "/auth/sync", "", adminCookie())
-	assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
-	assert.True(t, called, "an admin sync must run the sync function")
+	body := preview(t, handler)
+	assert.NotEmpty(t, body.PreviewID)
+	require.Len(t, body.Diff.Added, 1)
+	assert.Equal(t, "bob", body.Diff.Added[0].ID)
+	assert.Empty(t, body.Diff.Removed)
+	require.Len(t, body.Diff.Changed, 1)
+	assert.Equal(t, []string{"Team lead"}, body.Diff.Changed[0].RolesLost)
+	require.Len(t, body.Diff.UnknownRoles, 1)
+	assert.Equal(t, "Kitchen", body.Diff.UnknownRoles[0].Value)
+
+	assert.Equal(t, []string{"alice"}, cachedIDs(t, cache))
 }
 
-func TestSync_Failure(t *testing.T) {
-	a := newSyncTestAuthenticator(func(context.Context) error {
-		return errors.New("sheets access denied")
+// A confirm applies what was previewed — not the sheet as it is by then — and
+// records it.
+func TestSync_ConfirmAppliesThePreview(t *testing.T) {
+	store := &mockStore{}
+	sheet := editedSheet()
+	handler, cache := newSyncTestHandler(store, sheet)
+
+	body := preview(t, handler)
+	sheet.volunteers = append(sheet.volunteers, model.Volunteer{ID: "carol", FirstName: "Carol"})
+
+	require.Equal(t, http.StatusOK, confirm(t, handler, body.PreviewID))
+	assert.Equal(t, []string{"alice", "bob"}, cachedIDs(t, cache), "the roster the admin was shown")
+	assert.Equal(t, 1, sheet.reads, "a confirm does not read the sheet again")
+
+	require.Len(t, store.rosterSyncs, 1)
+	assert.Equal(t, testAdminEmail, store.rosterSyncs[0].SyncedBy)
+	assert.Equal(t, 1, store.rosterSyncs[0].Added)
+	assert.Equal(t, 1, store.rosterSyncs[0].Changed)
+
+	assert.Equal(t, http.StatusConflict, confirm(t, handler, body.PreviewID), "a preview is confirmed once")
+}
+
+func TestSync_ConfirmRefusesAStalePreview(t *testing.T) {
+	t.Run("a preview that is not the latest", func(t *testing.T) {
+		store := &mockStore{}
+		handler, cache := newSyncTestHandler(store, editedSheet())
+
+		first := preview(t, handler)
+		second := preview(t, handler)
+		assert.Equal(t, http.StatusConflict, confirm(t, handler, first.PreviewID))
+		assert.Equal(t, []string{"alice"}, cachedIDs(t, cache))
+		assert.Empty(t, store.rosterSyncs)
+
+		assert.Equal(t, http.StatusOK, confirm(t, handler, second.PreviewID))
 	})
 
-	rec := doRequest(t, syncTestHandler(a), http.MethodPost, "/auth/sync", "", adminCookie())
+	t.Run("a roster replaced since", func(t *testing.T) {
+		store := &mockStore{}
+		handler, cache := newSyncTestHandler(store, editedSheet())
+
+		body := preview(t, handler)
+		cache.Replace([]model.Volunteer{{ID: "dan", FirstName: "Dan"}})
+		assert.Equal(t, http.StatusConflict, confirm(t, handler, body.PreviewID))
+		assert.Equal(t, []string{"dan"}, cachedIDs(t, cache))
+		assert.Empty(t, store.rosterSyncs)
+	})
+
+	t.Run("no preview named", func(t *testing.T) {
+		handler, _ := newSyncTestHandler(&mockStore{}, editedSheet())
+		rec := doRequest(t, handler, http.MethodPost, "/api/volunteers/sync", "", adminCookie())
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "a sync is never applied unseen")
+	})
+}
+
+func TestSync_SheetUnreadable(t *testing.T) {
+	handler, cache := newSyncTestHandler(&mockStore{}, &fakeSheet{err: errors.New("sheets access denied")})
+
+	rec := doRequest(t, handler, http.MethodPost, "/api/volunteers/sync/preview", "", adminCookie())
 	assert.Equal(t, http.StatusBadGateway, rec.Code, "a failed sheet fetch must surface as an upstream error")
+	assert.Equal(t, []string{"alice"}, cachedIDs(t, cache))
 }
 
 func TestSync_NotConfigured(t *testing.T) {
-	a := newSyncTestAuthenticator(nil)
+	handler := newTestHandler(&mockStore{}, &mockVolunteerClient{})
 
-	rec := doRequest(t, syncTestHandler(a), http.MethodPost, "/auth/sync", "", adminCookie())
-	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "with no sync function wired the endpoint is unavailable")
+	rec := doRequest(t, handler, http.MethodPost, "/api/volunteers/sync/preview", "", adminCookie())
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code, "with no sync wired the endpoint is unavailable")
+	rec = doRequest(t, handler, http.MethodPost, "/api/volunteers/sync", `{"previewId":"x"}`, adminCookie())
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
 }
 
 func TestSync_RejectsGet(t *testing.T) {
-	a := newSyncTestAuthenticator(func(context.Context) error { return nil })
+	handler, _ := newSyncTestHandler(&mockStore{}, editedSheet())
 
-	rec := doRequest(t, syncTestHandler(a), http.MethodGet, "/auth/sync", "", adminCookie())
-	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "sync mutates state, so only POST is allowed")
+	rec := doRequest(t, handler, http.MethodGet, "/api/volunteers/sync/preview", "", adminCookie())
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code, "a preview claims the one slot, so only POST is allowed")
+}
+
+func TestListSyncsEndpoint(t *testing.T) {
+	store := &mockStore{}
+	handler, _ := newSyncTestHandler(store, editedSheet())
+	require.Equal(t, http.StatusOK, confirm(t, handler, preview(t, handler).PreviewID))
+
+	rec := doRequest(t, handler, http.MethodGet, "/api/volunteers/syncs", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var body struct {
+		Syncs []struct {
+			SyncedBy string `json:"syncedBy"`
+			SyncedAt string `json:"syncedAt"`
+			Diff     struct {
+				Added []struct {
+					ID string `json:"id"`
+				} `json:"added"`
+			} `json:"diff"`
+		} `json:"syncs"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Syncs, 1)
+	assert.Equal(t, testAdminEmail, body.Syncs[0].SyncedBy)
+	require.Len(t, body.Syncs[0].Diff.Added, 1)
+	assert.Equal(t, "bob", body.Syncs[0].Diff.Added[0].ID)
 }
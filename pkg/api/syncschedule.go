@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
+)
+
+// scheduledSyncBy is who a timed sync is recorded as having applied, where an
+// admin's is recorded by their email.
+const scheduledSyncBy = "scheduled sync"
+
+// rosterSyncMaxBackoff is the longest a failing timed sync waits before trying
+// the sheet again, unless the interval itself is longer. A Sheets outage or an
+// exhausted quota is not helped by asking every interval, but a roster left a
+// day stale because the wait kept doubling is worse than the quota spent.
+const rosterSyncMaxBackoff = 6 * time.Hour
+
+// RunScheduledSyncs re-reads the sheet every interval until ctx is done,
+// replacing the roster with whatever it holds. It blocks, so the composition
+// root runs it in its own goroutine. A read that fails leaves the roster as it
+// was and waits twice as long before the next, up to rosterSyncMaxBackoff; the
+// first that succeeds goes back to the interval.
+//
+// A timed sync is applied without a preview — there is nobody to confirm it —
+// so it is the operator's choice to configure one. What it changes is recorded
+// as an admin's sync is, so the roster's history still says who moved it; a
+// sync that changes nothing is not, or the list of recent syncs would be only
+// the timer's.
+func (h *Handler) RunScheduledSyncs(ctx context.Context, interval time.Duration) {
+	if h.syncs == nil {
+		h.logger.Error("Scheduled roster sync requested but no sync is configured")
+		return
+	}
+	h.logger.Info("Roster syncs scheduled", zap.Duration("interval", interval))
+
+	failures := 0
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		if err := h.scheduledSync(ctx); err != nil {
+			failures++
+			wait := syncBackoff(interval, failures)
+			h.logger.Warn("Scheduled roster sync failed; keeping the roster as it was",
+				zap.Error(err), zap.Int("consecutive_failures", failures), zap.Duration("retry_in", wait))
+			timer.Reset(wait)
+			continue
+		}
+		failures = 0
+		timer.Reset(interval)
+	}
+}
+
+// syncBackoff is how long to wait after the given run of consecutive failures:
+// the interval doubled for each, capped at rosterSyncMaxBackoff or the
+// interval, whichever is longer.
+func syncBackoff(interval time.Duration, failures int) time.Duration {
+	ceiling := max(interval, rosterSyncMaxBackoff)
+	wait := interval
+	for i := 0; i < failures && wait < ceiling; i++ {
+		wait *= 2
+	}
+	return min(wait, ceiling)
+}
+
+// scheduledSync is one timed read of the sheet. A roster replaced while it
+// read — an admin confirming a sync — is left alone: it is as fresh as this
+// one, and was looked at. The snapshot is taken before the read for that
+// reason; taken after, it would already include the admin's sync, and the
+// timer would overwrite it with a read older than theirs.
+func (h *Handler) scheduledSync(ctx context.Context) error {
+	current, generation := h.syncs.cache.Snapshot()
+
+	fetched, err := h.syncs.fetch(ctx)
+	if err != nil {
+		h.syncs.cache.RecordFailedSync()
+		return err
+	}
+
+	diff := services.DiffRoster(current, fetched)
+	if !h.syncs.cache.ReplaceIfUnchanged(generation, fetched) {
+		// The sheet was read fine, which is what the status reports on, even
+		// though the roster it read is not the one kept.
+		h.syncs.cache.RecordSuccessfulRead()
+		h.logger.Info("Scheduled roster sync skipped: the roster was replaced while the sheet was read")
+		return nil
+	}
+
+	h.logger.Info("Volunteers synced", zap.String("by", scheduledSyncBy), zap.Int("count", len(fetched)))
+	if diff.Empty() {
+		return nil
+	}
+	if err := services.RecordRosterSync(ctx, h.store, diff, scheduledSyncBy, h.logger); err != nil {
+		h.logger.Error("Roster synced but the sync was not recorded", zap.Error(err))
+	}
+	return nil
+}
+
+// How the last read of the sheet went, as rosterSyncStatusResponse says it.
+const (
+	syncOutcomeOK     = "ok"
+	syncOutcomeFailed = "failed"
+	// syncOutcomeNone is a sheet nobody has tried to read yet.
+	syncOutcomeNone = "none"
+)
+
+// rosterSyncStatusResponse is how fresh the roster is, for GET /health and
+// /auth/me. Times are RFC 3339, and null for never.
+type rosterSyncStatusResponse struct {
+	LastSyncedAt  *string `json:"lastSyncedAt"`
+	LastAttemptAt *string `json:"lastAttemptAt"`
+	Outcome       string  `json:"outcome"`
+}
+
+// rosterSyncStatus reports how fresh the roster is, or nil where the database
+// is the roster and there is no sync to report on.
+func (h *Handler) rosterSyncStatus() *rosterSyncStatusResponse {
+	if h.syncs == nil {
+		return nil
+	}
+	status := h.syncs.cache.SyncStatus()
+
+	resp := &rosterSyncStatusResponse{
+		LastSyncedAt:  optionalTime(status.LastSyncedAt),
+		LastAttemptAt: optionalTime(status.LastAttemptAt),
+		Outcome:       syncOutcomeOK,
+	}
+	switch {
+	case status.LastAttemptAt.IsZero():
+		resp.Outcome = syncOutcomeNone
+	case status.LastFailed:
+		resp.Outcome = syncOutcomeFailed
+	}
+	return resp
+}
+
+func optionalTime(t time.Time) *string {
+	if t.IsZero() {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+)
+
+// newScheduledSyncHandler is newSyncTestHandler's handler before its routes
+// are taken, for the timed sync, which is no route.
+func newScheduledSyncHandler(store *mockStore, sheet *fakeSheet) (*Handler, *volunteerStore) {
+	cache := NewVolunteerStore()
+	cache.Replace([]model.Volunteer{{ID: "alice", FirstName: "Alice", Roles: []string{"Team lead"}, Status: "Active"}})
+
+	h := NewHandler(store, cache, apiTestCfg, newTestAuthenticator(), nil, nil, zap.NewNop())
+	h.EnableRosterSync(sheet.fetch, cache)
+	return h, cache
+}
+
+func TestSyncBackoff(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{name: "first failure doubles", interval: time.Hour, failures: 1, want: 2 * time.Hour},
+		{name: "second doubles again", interval: time.Hour, failures: 2, want: 4 * time.Hour},
+		{name: "capped", interval: time.Hour, failures: 3, want: rosterSyncMaxBackoff},
+		{name: "stays capped", interval: time.Hour, failures: 80, want: rosterSyncMaxBackoff},
+		{name: "a long interval is never shortened", interval: 12 * time.Hour, failures: 2, want: 12 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, syncBackoff(tt.interval, tt.failures))
+		})
+	}
+}
+
+// Nobody confirms a timed sync, so the record of it is the only say anybody
+// has in what it did.
+func TestScheduledSync_ReplacesAndRecords(t *testing.T) {
+	store := &mockStore{}
+	h, cache := newScheduledSyncHandler(store, editedSheet())
+
+	require.NoError(t, h.scheduledSync(context.Background()))
+
+	assert.ElementsMatch(t, []string{"alice", "bob"}, cachedIDs(t, cache))
+	require.Len(t, store.rosterSyncs, 1)
+	assert.Equal(t, scheduledSyncBy, store.rosterSyncs[0].SyncedBy)
+	assert.Equal(t, 1, store.rosterSyncs[0].Added)
+	assert.Equal(t, 1, store.rosterSyncs[0].Changed)
+}
+
+// Four syncs a day that change nothing would push every admin's out of the
+// recent list.
+func TestScheduledSync_NothingChangedIsNotRecorded(t *testing.T) {
+	store := &mockStore{}
+	h, cache := newScheduledSyncHandler(store, &fakeSheet{volunteers: []model.Volunteer{
+		{ID: "alice", FirstName: "Alice", Roles: []string{"Team lead"}, Status: "Active"},
+	}})
+	before := cache.SyncStatus().LastSyncedAt
+
+	require.NoError(t, h.scheduledSync(context.Background()))
+
+	assert.Empty(t, store.rosterSyncs)
+	assert.False(t, cache.SyncStatus().LastSyncedAt.Before(before), "the roster is fresh all the same")
+}
+
+func TestScheduledSync_SheetUnreadable(t *testing.T) {
+	store := &mockStore{}
+	h, cache := newScheduledSyncHandler(store, &fakeSheet{err: errors.New("quota exceeded")})
+
+	require.Error(t, h.scheduledSync(context.Background()))
+
+	assert.Equal(t, []string{"alice"}, cachedIDs(t, cache), "the roster is kept")
+	assert.True(t, cache.SyncStatus().LastFailed)
+	assert.Empty(t, store.rosterSyncs)
+}
+
+// An admin who confirms a sync while the timer is reading the sheet keeps
+// theirs: the timer's read is no fresher, and nobody looked at it.
+func TestScheduledSync_AdminSyncDuringTheRead(t *testing.T) {
+	store := &mockStore{}
+	cache := NewVolunteerStore()
+	cache.Replace([]model.Volunteer{{ID: "alice", FirstName: "Alice", Status: "Active"}})
+	cache.RecordFailedSync()
+
+	h := NewHandler(store, cache, apiTestCfg, newTestAuthenticator(), nil, nil, zap.NewNop())
+	h.EnableRosterSync(func(context.Context) ([]model.Volunteer, error) {
+		cache.Replace([]model.Volunteer{{ID: "carol", FirstName: "Carol", Status: "Active"}})
+		cache.RecordFailedSync()
+		return []model.Volunteer{{ID: "bob", FirstName: "Bob", Status: "Active"}}, nil
+	}, cache)
+
+	require.NoError(t, h.scheduledSync(context.Background()))
+
+	assert.Equal(t, []string{"carol"}, cachedIDs(t, cache), "the admin's sync is kept")
+	assert.Empty(t, store.rosterSyncs, "nothing the timer read was applied")
+	assert.False(t, cache.SyncStatus().LastFailed, "the sheet was read fine")
+}
+
+// A preview open when the timer fires was of a roster that is no longer in
+// use; confirming it would undo the timed sync unseen.
+func TestScheduledSync_StalesAnOpenPreview(t *testing.T) {
+	sheet := editedSheet()
+	h, _ := newScheduledSyncHandler(&mockStore{}, sheet)
+	routes := h.Routes()
+
+	id := preview(t, routes).PreviewID
+	require.NoError(t, h.scheduledSync(context.Background()))
+
+	assert.Equal(t, http.StatusConflict, confirm(t, routes, id))
+}
+
+type syncStatusBody struct {
+	RosterSync *struct {
+		LastSyncedAt  *string `json:"lastSyncedAt"`
+		LastAttemptAt *string `json:"lastAttemptAt"`
+		Outcome       string  `json:"outcome"`
+	} `json:"rosterSync"`
+}
+
+// The UI says how stale the roster is from /auth/me; whatever watches the
+// server reads the same from /health.
+func TestRosterSyncStatus_HealthAndMe(t *testing.T) {
+	h, cache := newScheduledSyncHandler(&mockStore{}, &fakeSheet{})
+	routes := h.Routes()
+
+	for _, path := range []string{"/health", "/auth/me"} {
+		rec := doRequest(t, routes, http.MethodGet, path, "", adminCookie())
+		require.Equal(t, http.StatusOK, rec.Code, path)
+
+		var body syncStatusBody
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.NotNil(t, body.RosterSync, path)
+		assert.Equal(t, syncOutcomeOK, body.RosterSync.Outcome, path)
+		require.NotNil(t, body.RosterSync.LastSyncedAt, path)
+		_, err := time.Parse(time.RFC3339, *body.RosterSync.LastSyncedAt)
+		assert.NoError(t, err, path)
+	}
+
+	cache.RecordFailedSync()
+	rec := doRequest(t, routes, http.MethodGet, "/health", "")
+	require.Equal(t, http.StatusOK, rec.Code, "a stale roster is still served")
+	var body syncStatusBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, syncOutcomeFailed, body.RosterSync.Outcome)
+	assert.NotNil(t, body.RosterSync.LastSyncedAt, "the roster in use was still synced once")
+}
+
+// Where the database is the roster there is no sync to be stale.
+func TestRosterSyncStatus_AbsentWithoutASync(t *testing.T) {
+	handler := newTestHandler(&mockStore{}, testVolunteers())
+
+	rec := doRequest(t, handler, http.MethodGet, "/auth/me", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"email":"`+testAdminEmail+`"}`, rec.Body.String())
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"sync"
+	"time"
 
 	"github.com/jakechorley/ilford-drop-in/internal/config"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
@@ -9,13 +10,30 @@ import (
 
 // volunteerStore holds the volunteer roster in memory. The roster is populated
 // from the volunteer sheet using the server's service account (see sync.go and
-// cmd/server/main.go): once at startup and again on each admin-triggered sync,
-// and served verbatim in between. It never fetches on its own, so between syncs
-// a volunteer added to the sheet 404s until an admin syncs — an accepted
-// trade-off (see docs/oidc_admin_sync_plan.md).
+// cmd/server/main.go): once at startup, again on each sync an admin previews
+// and confirms, and on a timer where one is configured, and served verbatim in
+// between. It never fetches on its own, so between syncs a volunteer added to
+// the sheet 404s until the next one — an accepted trade-off (see
+// docs/oidc_admin_sync_plan.md), which is why it keeps how stale it is.
 type volunteerStore struct {
 	mu     sync.RWMutex
 	cached []model.Volunteer
+	// generation counts the replacements, so a sync previewed against one
+	// roster can tell it is no longer the roster in use.
+	generation uint64
+	status     RosterSyncStatus
+}
+
+// RosterSyncStatus is how fresh the cached roster is: when a sync last
+// replaced it, and whether the most recent attempt to read the sheet worked.
+// A failed read leaves the roster as it was, so LastSyncedAt can be well
+// behind LastAttemptAt.
+type RosterSyncStatus struct {
+	// LastSyncedAt is zero until a read of the sheet first succeeds.
+	LastSyncedAt time.Time
+	// LastAttemptAt is zero until the sheet is first read, successfully or not.
+	LastAttemptAt time.Time
+	LastFailed    bool
 }
 
 // NewVolunteerStore returns an empty volunteer store. It satisfies
@@ -36,10 +54,66 @@ func (s *volunteerStore) ListVolunteers(_ *config.Config, _ model.Roles) ([]mode
 	return s.cached, nil
 }
 
-// Replace swaps the whole roster for the freshly synced one. It is the only way
-// data enters the store.
+// Replace swaps the whole roster for the freshly synced one. With
+// ReplaceIfUnchanged it is the only way data enters the store.
 func (s *volunteerStore) Replace(volunteers []model.Volunteer) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.cached = volunteers
+	s.generation++
+	s.markSynced(time.Now())
+}
+
+// Snapshot returns the roster with the generation it is, for a preview to be
+// confirmed against.
+func (s *volunteerStore) Snapshot() ([]model.Volunteer, uint64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cached, s.generation
+}
+
+// ReplaceIfUnchanged swaps the roster only if it is still the generation a
+// preview was taken of, reporting whether it did. A roster replaced in between
+// — another admin's sync, say — is not the one the preview's diff was against.
+func (s *volunteerStore) ReplaceIfUnchanged(generation uint64, volunteers []model.Volunteer) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return false
+	}
+	s.cached = volunteers
+	s.generation++
+	s.markSynced(time.Now())
+	return true
+}
+
+// RecordFailedSync notes a read of the sheet that failed, leaving the roster
+// as the last one that did not.
+func (s *volunteerStore) RecordFailedSync() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastAttemptAt = time.Now()
+	s.status.LastFailed = true
+}
+
+// RecordSuccessfulRead notes a read of the sheet that worked but did not
+// replace the roster — a preview, or a timed sync that lost to an admin's. The
+// roster is no fresher, but the sheet is readable again.
+func (s *volunteerStore) RecordSuccessfulRead() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status.LastAttemptAt = time.Now()
+	s.status.LastFailed = false
+}
+
+// SyncStatus reports how fresh the roster is.
+func (s *volunteerStore) SyncStatus() RosterSyncStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status
+}
+
+// markSynced is called with the lock held.
+func (s *volunteerStore) markSynced(at time.Time) {
+	s.status = RosterSyncStatus{LastSyncedAt: at, LastAttemptAt: at}
 }
@@ -42,3 +42,58 @@ func TestVolunteerStore_ReplaceOverwritesWholesale(t *testing.T) {
 	require.Len(t, volunteers, 1, "a sync replaces the roster, it does not merge")
 	assert.Equal(t, "carol", volunteers[0].ID)
 }
+
+// A preview is confirmed against the roster it was taken of, and nothing else.
+func TestVolunteerStore_ReplaceIfUnchanged(t *testing.T) {
+	store := NewVolunteerStore()
+	store.Replace([]model.Volunteer{{ID: "alice"}})
+
+	_, previewed := store.Snapshot()
+	store.Replace([]model.Volunteer{{ID: "bob"}})
+	assert.False(t, store.ReplaceIfUnchanged(previewed, []model.Volunteer{{ID: "carol"}}), "the roster moved since the preview")
+
+	volunteers, current := store.Snapshot()
+	assert.Equal(t, "bob", volunteers[0].ID)
+	assert.True(t, store.ReplaceIfUnchanged(current, []model.Volunteer{{ID: "carol"}}))
+
+	volunteers, _ = store.Snapshot()
+	assert.Equal(t, "carol", volunteers[0].ID)
+}
+
+// A failed read keeps the roster and the time it was synced, but says so.
+func TestVolunteerStore_SyncStatus(t *testing.T) {
+	store := NewVolunteerStore()
+	assert.Zero(t, store.SyncStatus(), "nothing has been read yet")
+
+	store.RecordFailedSync()
+	status := store.SyncStatus()
+	assert.True(t, status.LastFailed)
+	assert.True(t, status.LastSyncedAt.IsZero(), "never synced")
+	assert.False(t, status.LastAttemptAt.IsZero())
+
+	store.Replace([]model.Volunteer{{ID: "alice"}})
+	synced := store.SyncStatus()
+	assert.False(t, synced.LastFailed)
+	assert.False(t, synced.LastSyncedAt.IsZero())
+
+	store.RecordFailedSync()
+	status = store.SyncStatus()
+	assert.True(t, status.LastFailed)
+	assert.Equal(t, synced.LastSyncedAt, status.LastSyncedAt, "a failure does not move the last sync")
+
+	volunteers, _ := store.Snapshot()
+	assert.Equal(t, "alice", volunteers[0].ID, "nor the roster")
+}
+
+// A read that works clears a failure without claiming the roster is fresher.
+func TestVolunteerStore_RecordSuccessfulRead(t *testing.T) {
+	store := NewVolunteerStore()
+	store.Replace([]model.Volunteer{{ID: "alice"}})
+	synced := store.SyncStatus().LastSyncedAt
+	store.RecordFailedSync()
+
+	store.RecordSuccessfulRead()
+	status := store.SyncStatus()
+	assert.False(t, status.LastFailed)
+	assert.Equal(t, synced, status.LastSyncedAt)
+}
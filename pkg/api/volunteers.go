@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/services"
 )
 
@@ -25,18 +27,38 @@ import (
 // (allocator.GenderMale), and so does anything counting the roster.
 // Roles are every Role the volunteer holds, in priority order — a volunteer
 // holds a set, not one, and which of them they fill is decided per shift.
+//
+// State is the volunteer's state today (model.VolunteerState), so a pause that
+// has ended reads as active without the sheet being touched; pausedUntil is
+// only on a pause still running. Status is the sheet's own cell, and only on an
+// unrecognised state — it is what an admin has to find and put right. Active
+// is the one question most screens ask of all that: are they volunteering?
+//
+// FirstName, LastName and Email are what editing a roster kept in the
+// database starts from; the screens that only show somebody read the names
+// above.
 type volunteerResponse struct {
-	ID       string   `json:"id"`
-	Name     string   `json:"name"`
-	FullName string   `json:"fullName"`
-	Roles    []string `json:"roles"`
-	Group    string   `json:"group,omitempty"`
-	Gender   string   `json:"gender,omitempty"`
-	Active   bool     `json:"active"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	FullName    string   `json:"fullName"`
+	FirstName   string   `json:"firstName"`
+	LastName    string   `json:"lastName,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	Roles       []string `json:"roles"`
+	Group       string   `json:"group,omitempty"`
+	Gender      string   `json:"gender,omitempty"`
+	Active      bool     `json:"active"`
+	State       string   `json:"state"`
+	PausedUntil string   `json:"pausedUntil,omitempty"`
+	Status      string   `json:"status,omitempty"`
 }
 
+// listVolunteersResponse carries where the roster is read from, "sheet" or
+// "database", because that decides what the screen offers: a sync for the
+// sheet, editing for the database.
 type listVolunteersResponse struct {
 	Volunteers []volunteerResponse `json:"volunteers"`
+	Source     string              `json:"source"`
 }
 
 // heldRoles renders the Roles a volunteer holds, keeping [] rather than null
@@ -72,19 +94,13 @@ func (h *Handler) handleListVolunteers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp := listVolunteersResponse{Volunteers: make([]volunteerResponse, 0, len(volunteers))}
+	now := time.Now()
+	resp := listVolunteersResponse{
+		Volunteers: make([]volunteerResponse, 0, len(volunteers)),
+		Source:     h.cfg.RosterSource(),
+	}
 	for _, v := range volunteers {
-		resp.Volunteers = append(resp.Volunteers, volunteerResponse{
-			ID: v.ID,
-			// TrimSpace, not a bare join: a volunteer with no surname on the
-			// sheet would otherwise get a trailing space.
-			Name:     v.DisplayName,
-			FullName: strings.TrimSpace(v.FirstName + " " + v.LastName),
-			Roles:    heldRoles(v.Roles),
-			Group:    v.GroupKey,
-			Gender:   v.Gender,
-			Active:   strings.EqualFold(v.Status, "Active"),
-		})
+		resp.Volunteers = append(resp.Volunteers, toVolunteerResponse(v, now))
 	}
 
 	// Sheet order is not meaningful; sort so the ordering is stable. Keyed on the
@@ -100,3 +116,32 @@ func (h *Handler) handleListVolunteers(w http.ResponseWriter, r *http.Request) {
 
 	h.writeJSON(w, http.StatusOK, resp)
 }
+
+// toVolunteerResponse renders one volunteer as they stand on the day now falls
+// on.
+func toVolunteerResponse(v model.Volunteer, now time.Time) volunteerResponse {
+	status := v.Lifecycle()
+	state := status.On(now)
+	entry := volunteerResponse{
+		ID: v.ID,
+		// TrimSpace, not a bare join: a volunteer with no surname on the
+		// sheet would otherwise get a trailing space.
+		Name:      v.DisplayName,
+		FullName:  strings.TrimSpace(v.FirstName + " " + v.LastName),
+		FirstName: v.FirstName,
+		LastName:  v.LastName,
+		Email:     v.Email,
+		Roles:     heldRoles(v.Roles),
+		Group:     v.GroupKey,
+		Gender:    v.Gender,
+		Active:    state.Volunteering(),
+		State:     string(state),
+	}
+	switch state {
+	case model.StatePaused:
+		entry.PausedUntil = status.Until
+	case model.StateUnrecognised:
+		entry.Status = v.Status
+	}
+	return entry
+}
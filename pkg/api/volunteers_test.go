@@ -25,13 +25,16 @@ func rosterVolunteers() *mockVolunteerClient {
 }
 
 type volunteerBody struct {
-	ID       string   `json:"id"`
-	Name     string   `json:"name"`
-	FullName string   `json:"fullName"`
-	Roles    []string `json:"roles"`
-	Group    string   `json:"group"`
-	Gender   string   `json:"gender"`
-	Active   bool     `json:"active"`
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	FullName    string   `json:"fullName"`
+	Roles       []string `json:"roles"`
+	Group       string   `json:"group"`
+	Gender      string   `json:"gender"`
+	Active      bool     `json:"active"`
+	State       string   `json:"state"`
+	PausedUntil string   `json:"pausedUntil"`
+	Status      string   `json:"status"`
 }
 
 func decodeVolunteers(t *testing.T, body []byte) []volunteerBody {
@@ -56,14 +59,56 @@ func TestListVolunteersEndpoint(t *testing.T) {
 	assert.Equal(t, volunteerBody{
 		ID: "alice", Name: "Alice", FullName: "Alice Adams",
 		Roles:  []string{"Team lead", "Service volunteer"},
-		Gender: "Female", Active: true,
+		Gender: "Female", Active: true, State: "active",
 	}, volunteers[0], "every Role held comes back, in priority order")
 	assert.Equal(t, volunteerBody{
 		ID: "bob", Name: "Bob", FullName: "Bob Smith",
-		Roles: []string{"Service volunteer"}, Group: "smith-family", Gender: "Male", Active: true,
+		Roles: []string{"Service volunteer"}, Group: "smith-family", Gender: "Male", Active: true, State: "active",
 	}, volunteers[1])
 }
 
+// Each state comes back by name, with what an admin needs beside it: the day a
+// pause ends, and the cell the app could not read.
+func TestListVolunteersEndpoint_States(t *testing.T) {
+	client := &mockVolunteerClient{
+		volunteers: []model.Volunteer{
+			{ID: "charlie", FirstName: "Charlie", Status: "Left"},
+			{ID: "diana", FirstName: "Diana", Status: "Paused until 2999-01-01"},
+			{ID: "emma", FirstName: "Emma", Status: "Actve"},
+			{ID: "fred", FirstName: "Fred", Status: "Onboarding"},
+		},
+	}
+	rec := doRequest(t, newTestHandler(&mockStore{}, client), http.MethodGet, "/api/volunteers", "", adminCookie())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	byID := make(map[string]volunteerBody)
+	for _, v := range decodeVolunteers(t, rec.Body.Bytes()) {
+		byID[v.ID] = v
+	}
+
+	tests := []struct {
+		id          string
+		active      bool
+		state       string
+		pausedUntil string
+		status      string
+	}{
+		{id: "charlie", state: "left"},
+		{id: "diana", state: "paused", pausedUntil: "2999-01-01"},
+		{id: "emma", state: "unrecognised", status: "Actve"},
+		{id: "fred", active: true, state: "onboarding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got := byID[tt.id]
+			assert.Equal(t, tt.active, got.Active)
+			assert.Equal(t, tt.state, got.State)
+			assert.Equal(t, tt.pausedUntil, got.PausedUntil)
+			assert.Equal(t, tt.status, got.Status)
+		})
+	}
+}
+
 // TestListVolunteersFullNameAlongsideDisplayName proves the two names are both
 // carried and are not the same thing: name is the shortest form that stays
 // unambiguous (what a rota chip shows), fullName is always first plus last (what
@@ -150,7 +195,7 @@ func TestListVolunteersIncludesInactive(t *testing.T) {
 func TestListVolunteersEmptyRoster(t *testing.T) {
 	rec := doRequest(t, newTestHandler(&mockStore{}, &mockVolunteerClient{}), http.MethodGet, "/api/volunteers", "", adminCookie())
 	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
-	assert.JSONEq(t, `{"volunteers":[]}`, rec.Body.String())
+	assert.JSONEq(t, `{"volunteers":[],"source":"sheet"}`, rec.Body.String())
 }
 
 // TestListVolunteersRequiresAdmin proves the roster is admin-only: it exposes
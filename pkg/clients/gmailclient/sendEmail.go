@@ -6,13 +6,16 @@ import (
 	"time"
 
 	"google.golang.org/api/gmail/v1"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/clients/mailclient"
 )
 
 const EMAIL_INTERVAL = 3 * time.Second
 
-// SendEmail sends an email with the specified subject and body
+// SendEmail sends an email with the specified subject and body, with an HTML
+// part alongside the text when html is not empty.
 // Throttles requests to respect Gmail API rate limits
-func (c *Client) SendEmail(to, subject, body string) error {
+func (c *Client) SendEmail(to, subject, text, html string) error {
 	c.sendMutex.Lock()
 	defer c.sendMutex.Unlock()
 
@@ -25,11 +28,14 @@ func (c *Client) SendEmail(to, subject, body string) error {
 		}
 	}
 
-	// Create the email message
-	message := fmt.Sprintf("To: %s\r\nSubject: %s\r\n\r\n%s", to, subject, body)
+	// Create the email message. No From: Gmail writes the account sending it.
+	message, err := mailclient.Compose("", to, subject, text, html, time.Now())
+	if err != nil {
+		return err
+	}
 
 	// Encode the message in base64
-	encodedMessage := base64.URLEncoding.EncodeToString([]byte(message))
+	encodedMessage := base64.URLEncoding.EncodeToString(message)
 
 	// Create the Gmail message
 	gmailMessage := &gmail.Message{
@@ -37,7 +43,7 @@ func (c *Client) SendEmail(to, subject, body string) error {
 	}
 
 	// Send the message
-	_, err := c.service.Users.Messages.Send("me", gmailMessage).Do()
+	_, err = c.service.Users.Messages.Send("me", gmailMessage).Do()
 	if err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
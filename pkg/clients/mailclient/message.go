@@ -0,0 +1,87 @@
+// Package mailclient sends availability email without Google: through an SMTP
+// relay, or into a directory of files for dev. It also writes the message every
+// transport sends, Gmail's included, so what lands in the outbox is byte for
+// byte what a relay or Gmail would have been handed.
+package mailclient
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Compose writes one email as RFC 5322 wants it: CRLF line endings, a Date,
+// and the subject encoded when it is not plain ASCII — a volunteer's name in
+// it is enough to make it not.
+//
+// html is optional. Without it the message is plain text; with it the two go
+// as multipart/alternative, text first, so a client that cannot show HTML —
+// or a volunteer who has told theirs not to — still reads the whole email.
+//
+// from may be empty, for Gmail, which writes the sender itself from the
+// account that is sending.
+func Compose(from, to, subject, text, html string, now time.Time) ([]byte, error) {
+	if _, err := mail.ParseAddress(to); err != nil {
+		return nil, fmt.Errorf("invalid recipient address %q: %w", to, err)
+	}
+
+	var msg bytes.Buffer
+	if from != "" {
+		fmt.Fprintf(&msg, "From: %s\r\n", from)
+	}
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	// A line break in a subject would end the header and start another, so
+	// one that reaches here is flattened rather than trusted.
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", oneLine(subject)))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	if html == "" {
+		writePart(&msg, "text/plain", text)
+		return msg.Bytes(), nil
+	}
+
+	boundary, err := newBoundary()
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", boundary)
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	writePart(&msg, "text/plain", text)
+	fmt.Fprintf(&msg, "--%s\r\n", boundary)
+	writePart(&msg, "text/html", html)
+	fmt.Fprintf(&msg, "--%s--\r\n", boundary)
+	return msg.Bytes(), nil
+}
+
+// writePart writes one body with the headers that describe it.
+func writePart(msg *bytes.Buffer, contentType, body string) {
+	fmt.Fprintf(msg, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	msg.WriteString("\r\n")
+
+	// A bare LF is what the composed bodies carry, and some relays refuse one.
+	normalised := strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+	msg.WriteString(normalised)
+	if !strings.HasSuffix(normalised, "\r\n") {
+		msg.WriteString("\r\n")
+	}
+}
+
+// newBoundary is random rather than fixed, so no body can happen to contain it.
+func newBoundary() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate a mime boundary: %w", err)
+	}
+	return "drop-in-" + hex.EncodeToString(b), nil
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,57 @@
+package mailclient
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// OutboxClient writes each email to a file instead of sending it. It is the dev
+// stack's transport: the whole send runs for real — selection, wording, the
+// sent stamps, the recorded outcomes — and what would have gone out can be
+// opened in any mail client, links and all.
+type OutboxClient struct {
+	dir  string
+	from string
+	now  func() time.Time
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewOutboxClient builds a client writing into dir, creating it if it is
+// missing so a fresh checkout needs nothing made by hand.
+func NewOutboxClient(dir, from string) (*OutboxClient, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail outbox %s: %w", dir, err)
+	}
+	return &OutboxClient{dir: dir, from: from, now: time.Now}, nil
+}
+
+// unsafeFileChars is anything in an address that does not belong in a file
+// name on every platform the dev stack runs on.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]`)
+
+// SendEmail writes one email as an .eml file named for when it was written and
+// who it was for, so a directory listing reads in send order.
+func (c *OutboxClient) SendEmail(to, subject, text, html string) error {
+	now := c.now()
+	msg, err := Compose(c.from, to, subject, text, html, now)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.seq++
+	seq := c.seq
+	c.mu.Unlock()
+
+	name := fmt.Sprintf("%s-%03d-%s.eml", now.UTC().Format("20060102T150405"), seq, unsafeFileChars.ReplaceAllString(to, "_"))
+	if err := os.WriteFile(filepath.Join(c.dir, name), msg, 0o644); err != nil {
+		return fmt.Errorf("failed to write email to the outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package mailclient
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOutboxWritesOneFilePerEmail: the dev outbox is only useful if every email
+// the send made is there to open, in the order it was made, exactly as a relay
+// would have been handed it.
+func TestOutboxWritesOneFilePerEmail(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	client, err := NewOutboxClient(dir, "rota@example.com")
+	require.NoError(t, err, "a missing outbox is created, not refused")
+	client.now = func() time.Time { return time.Date(2026, 8, 1, 9, 30, 0, 0, time.UTC) }
+
+	require.NoError(t, client.SendEmail("alice@example.com", "Availability for August", "Hello Alice\nhttps://example.com/a/tok", ""))
+	require.NoError(t, client.SendEmail("bob@example.com", "Availability for August", "Hello Bob", ""))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "20260801T093000-001-alice@example.com.eml", entries[0].Name())
+	assert.Equal(t, "20260801T093000-002-bob@example.com.eml", entries[1].Name())
+
+	raw, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	msg := string(raw)
+	assert.Contains(t, msg, "From: rota@example.com\r\n")
+	assert.Contains(t, msg, "To: alice@example.com\r\n")
+	assert.Contains(t, msg, "Subject: Availability for August\r\n")
+	assert.True(t, strings.HasSuffix(msg, "\r\n\r\nHello Alice\r\nhttps://example.com/a/tok\r\n"), msg)
+}
+
+func TestOutboxRefusesAnUnusableAddress(t *testing.T) {
+	client, err := NewOutboxClient(t.TempDir(), "rota@example.com")
+	require.NoError(t, err)
+
+	assert.Error(t, client.SendEmail("not an address", "Subject", "Body", ""))
+}
+
+func TestComposeMessageEncodesANonASCIISubject(t *testing.T) {
+	msg, err := Compose("rota@example.com", "zoe@example.com", "Availability for Zoë", "Body", "", time.Now())
+	require.NoError(t, err)
+
+	assert.Contains(t, string(msg), "Subject: =?utf-8?q?Availability_for_Zo=C3=AB?=\r\n")
+}
+
+// TestComposeSendsHTMLAlongsideTheText: with an HTML body the email carries
+// both, text first, so a client that will not show HTML still reads all of it.
+func TestComposeSendsHTMLAlongsideTheText(t *testing.T) {
+	msg, err := Compose("rota@example.com", "zoe@example.com", "Subject", "Hi Zoe", "<p>Hi Zoe</p>", time.Now())
+	require.NoError(t, err)
+
+	raw := string(msg)
+	assert.Contains(t, raw, "Content-Type: multipart/alternative; boundary=")
+	text := strings.Index(raw, "Content-Type: text/plain")
+	html := strings.Index(raw, "Content-Type: text/html")
+	require.NotEqual(t, -1, text)
+	require.NotEqual(t, -1, html)
+	assert.Less(t, text, html, "the text part comes first")
+	assert.Contains(t, raw, "<p>Hi Zoe</p>")
+}
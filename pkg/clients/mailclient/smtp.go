@@ -0,0 +1,115 @@
+package mailclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+)
+
+// dialTimeout bounds reaching the relay. A send records a heartbeat after
+// every email, and a relay that never answers must fail its email well before
+// the send would be taken for one whose server went away.
+const dialTimeout = 30 * time.Second
+
+// SMTPClient sends through a relay. It opens a connection per email rather
+// than holding one for the whole send: a send is thirty-odd emails, which is
+// nothing to a relay, and a connection the relay dropped halfway through would
+// otherwise fail every email after it.
+type SMTPClient struct {
+	relay config.SMTPConfig
+	from  string
+	now   func() time.Time
+}
+
+// NewSMTPClient builds a client for the configured relay, sending as from.
+func NewSMTPClient(relay config.SMTPConfig, from string) *SMTPClient {
+	return &SMTPClient{relay: relay, from: from, now: time.Now}
+}
+
+// SendEmail sends one email, with an HTML part when html is not empty.
+func (c *SMTPClient) SendEmail(to, subject, text, html string) error {
+	msg, err := Compose(c.from, to, subject, text, html, c.now())
+	if err != nil {
+		return err
+	}
+
+	client, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if c.relay.Username != "" {
+		auth := smtp.PlainAuth("", c.relay.Username, c.relay.Password, c.relay.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp relay refused the credentials: %w", err)
+		}
+	}
+
+	if err := client.Mail(c.from); err != nil {
+		return fmt.Errorf("smtp relay refused the sender %s: %w", c.from, err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("smtp relay refused the recipient %s: %w", to, err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp relay refused the message: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write the message to the smtp relay: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp relay did not accept the message: %w", err)
+	}
+	return client.Quit()
+}
+
+// dial connects to the relay and secures the connection the configured way.
+// STARTTLS is required, not opportunistic, when it is asked for: a relay that
+// stops offering it must not quietly receive credentials and links in the clear.
+func (c *SMTPClient) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(c.relay.Host, strconv.Itoa(c.relay.Port))
+	tlsConfig := &tls.Config{ServerName: c.relay.Host, MinVersion: tls.VersionTLS12}
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	var conn net.Conn
+	var err error
+	if c.relay.SecurityMode() == config.SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach smtp relay %s: %w", addr, err)
+	}
+	// One deadline over the whole conversation, for the same reason as the
+	// dial timeout.
+	if err := conn.SetDeadline(time.Now().Add(2 * dialTimeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set a deadline on the smtp connection: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, c.relay.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp relay %s did not greet us: %w", addr, err)
+	}
+
+	if c.relay.SecurityMode() == config.SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("smtp relay %s does not offer STARTTLS; set mail.smtp.security if that is expected", addr)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("STARTTLS with smtp relay %s failed: %w", addr, err)
+		}
+	}
+	return client, nil
+}
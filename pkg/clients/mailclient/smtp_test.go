@@ -0,0 +1,135 @@
+package mailclient
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+)
+
+// fakeRelay is the least of an SMTP server that net/smtp will talk to: it
+// accepts every command, keeps what it was sent, and offers no extensions —
+// STARTTLS included, which is what security "none" is for.
+type fakeRelay struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	commands []string
+	data     []string
+}
+
+func newFakeRelay(t *testing.T) *fakeRelay {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	relay := &fakeRelay{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go relay.serve()
+	return relay
+}
+
+func (f *fakeRelay) config() config.SMTPConfig {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return config.SMTPConfig{Host: addr.IP.String(), Port: addr.Port, Security: config.SMTPSecurityNone}
+}
+
+func (f *fakeRelay) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRelay) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake relay ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		f.mu.Lock()
+		f.commands = append(f.commands, line)
+		f.mu.Unlock()
+
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			reply("250 fake relay")
+		case "DATA":
+			reply("354 go ahead")
+			var body strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				body.WriteString(l)
+			}
+			f.mu.Lock()
+			f.data = append(f.data, body.String())
+			f.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (f *fakeRelay) received() ([]string, []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.commands...), append([]string(nil), f.data...)
+}
+
+func TestSMTPClientHandsTheRelayTheMessage(t *testing.T) {
+	relay := newFakeRelay(t)
+	client := NewSMTPClient(relay.config(), "rota@example.com")
+
+	require.NoError(t, client.SendEmail("alice@example.com", "Availability for August", "Hello Alice", ""))
+
+	commands, data := relay.received()
+	assert.Contains(t, commands, "MAIL FROM:<rota@example.com>")
+	assert.Contains(t, commands, "RCPT TO:<alice@example.com>")
+	require.Len(t, data, 1)
+	assert.Contains(t, data[0], "To: alice@example.com\r\n")
+	assert.Contains(t, data[0], "\r\n\r\nHello Alice\r\n")
+}
+
+// TestSMTPClientRequiresSTARTTLSWhenAskedFor: a relay that does not offer it
+// must fail the email, not be handed links and credentials in the clear.
+func TestSMTPClientRequiresSTARTTLSWhenAskedFor(t *testing.T) {
+	relay := newFakeRelay(t)
+	cfg := relay.config()
+	cfg.Security = config.SMTPSecurityStartTLS
+	cfg.Username, cfg.Password = "rota", "secret"
+
+	err := NewSMTPClient(cfg, "rota@example.com").SendEmail("alice@example.com", "Subject", "Body", "")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "STARTTLS")
+	commands, _ := relay.received()
+	for _, c := range commands {
+		assert.False(t, strings.HasPrefix(c, "AUTH"), "credentials went out before the connection was secured")
+		assert.False(t, strings.HasPrefix(c, "MAIL"), "the message went out unsecured")
+	}
+}
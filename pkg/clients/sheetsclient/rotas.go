@@ -15,6 +15,9 @@ const closedCell = "CLOSED"
 // PublishedRotaRow represents a single row in the published rota
 type PublishedRotaRow struct {
 	Date string // Format: "Mon Jan 02 2006"
+	// ShiftDate is the shift's date as data rather than display, for exports
+	// that are read by something other than a person.
+	ShiftDate string // Format: "2006-01-02"
 	// Closed is a shift that did not run. It is rendered in the row's first
 	// column after the date, which is where the sheet has always shown it.
 	Closed bool
@@ -156,7 +159,24 @@ func (c *Client) writeRotaData(spreadsheetID, tabTitle string, publishedRota *Pu
 }
 
 // rotaValues lays the rota out as the cells that go on the tab: rows 1-2 empty,
-// row 3 the header, row 4+ one row per shift.
+// row 3 the header, row 4+ one row per shift, as Table lays them out.
+func rotaValues(publishedRota *PublishedRota) [][]interface{} {
+	table := publishedRota.Table()
+	allRows := make([][]interface{}, 0, len(table)+2)
+	allRows = append(allRows, []interface{}{}, []interface{}{})
+	for _, row := range table {
+		cells := make([]interface{}, len(row))
+		for i, cell := range row {
+			cells[i] = cell
+		}
+		allRows = append(allRows, cells)
+	}
+	return allRows
+}
+
+// Table lays the rota out as a grid: a header row, then one row per shift. It
+// is the sheet's layout, and what every export of the rota writes, so a
+// download reads exactly as the published tab does.
 //
 // Columns are Date, then each configured Role in priority order, then any
 // unknown-Role columns, then the two hand-typed trailing ones. One person per
@@ -164,33 +184,34 @@ func (c *Client) writeRotaData(spreadsheetID, tabTitle string, publishedRota *Pu
 // needs of it, and always at least one even when nothing fills it, so a rota of
 // nothing but closed shifts still has somewhere to say so. Unknown-Role columns
 // exist only when somebody is in one.
-func rotaValues(publishedRota *PublishedRota) [][]interface{} {
-	roleWidths := make(map[string]int, len(publishedRota.RoleNames))
-	for _, name := range publishedRota.RoleNames {
+func (p *PublishedRota) Table() [][]string {
+	roleWidths := make(map[string]int, len(p.RoleNames))
+	for _, name := range p.RoleNames {
 		roleWidths[name] = 1
-		for _, row := range publishedRota.Rows {
+		for _, row := range p.Rows {
 			if len(row.Roles[name]) > roleWidths[name] {
 				roleWidths[name] = len(row.Roles[name])
 			}
 		}
 	}
 	unknownWidth := 0
-	for _, row := range publishedRota.Rows {
+	for _, row := range p.Rows {
 		if len(row.UnknownRole) > unknownWidth {
 			unknownWidth = len(row.UnknownRole)
 		}
 	}
 
-	header := []interface{}{"Date"}
-	for _, name := range publishedRota.RoleNames {
+	header := []string{"Date"}
+	for _, name := range p.RoleNames {
 		header = append(header, headings(name, roleWidths[name])...)
 	}
 	header = append(header, headings(unknownRoleHeading, unknownWidth)...)
 	header = append(header, "Hot food", "Collection")
 
-	dataRows := make([][]interface{}, 0, len(publishedRota.Rows))
-	for _, row := range publishedRota.Rows {
-		sheetRow := []interface{}{row.Date}
+	table := make([][]string, 0, len(p.Rows)+1)
+	table = append(table, header)
+	for _, row := range p.Rows {
+		cells := []string{row.Date}
 		closedCellWritten := false
 		fill := func(names []string, width int) {
 			for i := 0; i < width; i++ {
@@ -198,35 +219,33 @@ func rotaValues(publishedRota *PublishedRota) [][]interface{} {
 				case row.Closed && !closedCellWritten:
 					// The closure is announced in the row's first cell, whatever
 					// column that turns out to be.
-					sheetRow = append(sheetRow, closedCell)
+					cells = append(cells, closedCell)
 					closedCellWritten = true
 				case i < len(names):
-					sheetRow = append(sheetRow, names[i])
+					cells = append(cells, names[i])
 				default:
-					sheetRow = append(sheetRow, "")
+					cells = append(cells, "")
 				}
 			}
 		}
-		for _, name := range publishedRota.RoleNames {
+		for _, name := range p.RoleNames {
 			fill(row.Roles[name], roleWidths[name])
 		}
 		fill(row.UnknownRole, unknownWidth)
-		sheetRow = append(sheetRow, row.HotFood, row.Collection)
-		dataRows = append(dataRows, sheetRow)
+		cells = append(cells, row.HotFood, row.Collection)
+		table = append(table, cells)
 	}
-
-	allRows := [][]interface{}{{}, {}, header}
-	return append(allRows, dataRows...)
+	return table
 }
 
 // headings names one group of columns: the group's own name when it is a
 // single column, numbered when there are several. Returns nothing for a group
 // of no columns, which is how an unused Unknown role group disappears.
-func headings(name string, width int) []interface{} {
+func headings(name string, width int) []string {
 	if width == 1 {
-		return []interface{}{name}
+		return []string{name}
 	}
-	out := make([]interface{}, 0, width)
+	out := make([]string, 0, width)
 	for i := 0; i < width; i++ {
 		out = append(out, fmt.Sprintf("%s %d", name, i+1))
 	}
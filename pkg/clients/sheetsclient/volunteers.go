@@ -172,25 +172,43 @@ func ParseVolunteers(raw [][]interface{}, roles model.Roles) ([]model.Volunteer,
 			continue
 		}
 
+		held, unknown := heldRoles(getField("Roles", row), roles)
 		volunteer := model.Volunteer{
-			ID:        getField("Unique ID", row),
-			FirstName: firstName,
-			LastName:  getField("Last name", row),
-			Roles:     heldRoles(getField("Roles", row), roles),
-			Status:    getField("Status", row),
-			Gender:    getField("Sex/Gender", row),
-			Email:     getField("Email", row),
-			GroupKey:  groupKey(getField("Group key", row)),
+			ID:           getField("Unique ID", row),
+			FirstName:    firstName,
+			LastName:     getField("Last name", row),
+			Roles:        held,
+			UnknownRoles: unknown,
+			Status:       getField("Status", row),
+			Gender:       getField("Sex/Gender", row),
+			Email:        getField("Email", row),
+			GroupKey:     groupKey(getField("Group key", row)),
 		}
 
 		volunteers = append(volunteers, volunteer)
 	}
 
 	warnUnheldRoles(volunteers, roles)
+	warnUnrecognisedStatuses(volunteers)
 
 	return volunteers, nil
 }
 
+// warnUnrecognisedStatuses reports each volunteer whose Status cell is not one
+// of the states the app knows (model.VolunteerState). Such a volunteer is left
+// out of rounds and allocation, which is what an unknown status has always
+// meant — but a typo in "Active" used to mean it silently, and this is the sync
+// saying so.
+func warnUnrecognisedStatuses(volunteers []model.Volunteer) {
+	for _, v := range volunteers {
+		if v.Lifecycle().State == model.StateUnrecognised {
+			slog.Warn("volunteer's Status is not one the app knows, so they are treated as not volunteering; use Active, Onboarding, Left or \"Paused until <date>\"",
+				"volunteer_id", v.ID,
+				"status", v.Status)
+		}
+	}
+}
+
 // warnUnheldRoles reports a Role nobody on the roster holds. On its own that is
 // only unusual — a Role can be created before anyone is given it — but it is
 // also exactly what a Role renamed in the app and not in the sheet looks like,
@@ -225,8 +243,13 @@ func groupKey(cell string) string {
 // priority order — so the first is the one a caller showing a single Role
 // wants, whatever order the chips were picked in. Duplicates collapse for the
 // same reason: the answer is a set.
-func heldRoles(cell string, roles model.Roles) []string {
+//
+// The values naming no Role come back as well, in the order written and once
+// each: skipped from what is held, but what a sync preview shows an admin as
+// the edit that did not take.
+func heldRoles(cell string, roles model.Roles) (held, unknown []string) {
 	picked := make(map[string]bool)
+	skipped := make(map[string]bool)
 
 	for _, value := range splitRoleCell(cell) {
 		// The dropdown writes no padding, but a hand-typed cell has whatever
@@ -239,19 +262,22 @@ func heldRoles(cell string, roles model.Roles) []string {
 		if _, known := roles.ByName(value); !known {
 			slog.Warn("volunteer sheet names a Role the app does not know; ignoring it",
 				"role", value)
+			if !skipped[value] {
+				skipped[value] = true
+				unknown = append(unknown, value)
+			}
 			continue
 		}
 		picked[value] = true
 	}
 
-	var held []string
 	for _, role := range roles.ByPriority() {
 		if picked[role.Name] {
 			held = append(held, role.Name)
 		}
 	}
 
-	return held
+	return held, unknown
 }
 
 // splitRoleCell unpacks a multi-select cell into the values it holds. The cell
@@ -198,6 +198,7 @@ func TestParseVolunteers_UnknownRoleValueIsSkipped(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, volunteers, 1)
 	assert.Equal(t, []string{"Service volunteer"}, volunteers[0].Roles)
+	assert.Equal(t, []string{"Food collector"}, volunteers[0].UnknownRoles, "kept aside for the sync preview to show")
 }
 
 // The columns Roles used to live in. Neither the pre-S1 `Role` dropdown nor the
@@ -341,6 +342,22 @@ func TestParseVolunteers_NoWarningWhenEveryRoleIsHeld(t *testing.T) {
 	assert.Empty(t, logged.String())
 }
 
+func TestParseVolunteers_WarnsOfAnUnrecognisedStatus(t *testing.T) {
+	logged := captureRosterWarnings(t)
+
+	raw := [][]any{
+		row("Unique ID", "First name", "Last name", "Status", "Sex/Gender", "Email", "Group key", "Roles"),
+		row("XYZ", "Emma", "Welder", "Actve", "Female", "emma@example.com", "", "Team lead, Service volunteer"),
+		row("ABC", "Sara", "Jones", "Paused until 2026-03-01", "Female", "sara@example.com", "", "Team lead, Service volunteer"),
+	}
+
+	_, err := ParseVolunteers(raw, twoRoles())
+	require.NoError(t, err)
+
+	assert.Contains(t, logged.String(), "volunteer_id=XYZ status=Actve")
+	assert.NotContains(t, logged.String(), "ABC")
+}
+
 // captureRosterWarnings redirects the default slog logger into a buffer for one
 // test. The roster parser reports what it could not match through it, and those
 // warnings are the only signal a sheet and the app have drifted apart.
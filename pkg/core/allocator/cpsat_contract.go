@@ -27,11 +27,16 @@ type CpsatMember struct {
 
 // CpsatGroup is an allocation unit (couples/families allocated together)
 // with availability already resolved to shift indices.
+//
+// PreferredShiftCount is how many shifts the group would like to work, 0 for no
+// preference. It is a soft term (pyallocator's preferred_frequency): shifts
+// past it cost the objective rather than being forbidden.
 type CpsatGroup struct {
 	GroupKey                  string        `json:"group_key"`
 	Members                   []CpsatMember `json:"members"`
 	AvailableShiftIndices     []int         `json:"available_shift_indices"`
 	HistoricalAllocationCount int           `json:"historical_allocation_count"`
+	PreferredShiftCount       int           `json:"preferred_shift_count"`
 }
 
 // CpsatRole is one configured Role. It carries no ceiling: a Shift's Shape
@@ -67,9 +72,37 @@ type CpsatShift struct {
 }
 
 // CpsatHistoricalShift is a past shift with Go-derived group keys.
+//
+// Weight is how much the shift counts towards fairness, in (0, 1]: 1 for the
+// previous rota, less for an older one when the Allocation Settings decay the
+// horizon. Only fairness reads it. A group's HistoricalAllocationCount stays
+// the plain count of the shifts naming it, and each engine takes 1 - Weight
+// off that count for every one of them, so an input with every weight 1 — or
+// with no historical shifts at all — weighs history exactly as before.
 type CpsatHistoricalShift struct {
 	Date      string   `json:"date"`
 	GroupKeys []string `json:"group_keys"`
+	Weight    float64  `json:"weight"`
+}
+
+// Pairing Rule kinds, spelled as the contract and the database both spell them.
+const (
+	// PairTogether is "try to put these two on together": a preference
+	// (pyallocator's pair_together), worth something on every shift the two
+	// share and never a reason to leave a Seat empty.
+	PairTogether = "together"
+	// PairApart is "never put these two on the same shift": a rule
+	// (pyallocator's never_together), broken by nothing, a pin included.
+	PairApart = "apart"
+)
+
+// CpsatPairRule is one Pairing Rule between two volunteers, by id. It names
+// volunteers rather than groups because that is what an admin decided about:
+// keeping one member of a family away from somebody keeps the family away.
+type CpsatPairRule struct {
+	VolunteerA string `json:"volunteer_a"`
+	VolunteerB string `json:"volunteer_b"`
+	Kind       string `json:"kind"`
 }
 
 // CpsatInput is the full problem sent to Python on stdin.
@@ -84,6 +117,10 @@ type CpsatInput struct {
 	Shifts             []CpsatShift           `json:"shifts"`
 	Groups             []CpsatGroup           `json:"groups"`
 	HistoricalShifts   []CpsatHistoricalShift `json:"historical_shifts"`
+	// PairRules are the Pairing Rules between volunteers who reach the solver,
+	// as PairRulesFor leaves them. Both engines refuse one naming somebody
+	// who is not in Groups.
+	PairRules []CpsatPairRule `json:"pair_rules"`
 }
 
 // CpsatAssignment is one filled Seat: who is in it, and what Role it is.
@@ -113,6 +150,28 @@ type CpsatDiagnostics struct {
 	NumGroups          int      `json:"num_groups"`
 	NumVariables       int      `json:"num_variables"`
 	ConstraintsApplied []string `json:"constraints_applied"`
+	// Conflict is set only on an INFEASIBLE run: why there is no rota.
+	Conflict *CpsatConflict `json:"conflict,omitempty"`
+}
+
+// CpsatConflict is a minimal set of the run's rules and pins that cannot all
+// hold: drop any one of them and the rest can be met. Constraints are the
+// solver's own constraint names; Preallocations is empty when the rules
+// conflict among themselves with no pin involved.
+type CpsatConflict struct {
+	Constraints    []string           `json:"constraints"`
+	Preallocations []CpsatConflictPin `json:"preallocations"`
+}
+
+// CpsatConflictPin is a volunteer pin named in a conflict. GroupKey is carried
+// because a pin forces its whole group onto the shift, so the group is what the
+// other rules in the conflict are counting.
+type CpsatConflictPin struct {
+	ShiftIndex  int    `json:"shift_index"`
+	Date        string `json:"date"`
+	VolunteerID string `json:"volunteer_id"`
+	GroupKey    string `json:"group_key"`
+	Role        string `json:"role"`
 }
 
 // CpsatOutput is the solved rota returned by Python on stdout.
@@ -168,6 +227,7 @@ func BuildCpsatInput(
 		Shifts:             make([]CpsatShift, len(initialised)),
 		Groups:             make([]CpsatGroup, len(volunteerState.VolunteerGroups)),
 		HistoricalShifts:   make([]CpsatHistoricalShift, len(historicalShifts)),
+		PairRules:          []CpsatPairRule{},
 	}
 
 	for i, shift := range initialised {
@@ -206,13 +266,18 @@ func BuildCpsatInput(
 			groupKeys[j] = group.GroupKey
 		}
 		sort.Strings(groupKeys)
+		weight := shift.HistoryWeight
+		if weight == 0 {
+			weight = 1
+		}
 		input.HistoricalShifts[i] = CpsatHistoricalShift{
 			Date:      shift.Date,
 			GroupKeys: groupKeys,
+			Weight:    weight,
 		}
 	}
-	// The contract requires historical shifts sorted ascending by date;
-	// only the last one matters in v1 (back-to-back boundary).
+	// The contract requires historical shifts sorted ascending by date: the
+	// last one is the back-to-back boundary.
 	sort.Slice(input.HistoricalShifts, func(i, j int) bool {
 		return input.HistoricalShifts[i].Date < input.HistoricalShifts[j].Date
 	})
@@ -220,6 +285,39 @@ func BuildCpsatInput(
 	return input, nil
 }
 
+// PairRulesFor keeps the Pairing Rules that mean something to this input: both
+// volunteers reached the solver. A rule naming somebody who left, or who never
+// answered, is about a pair who cannot both be on a shift this rota anyway.
+//
+// A "together" rule between two members of one group is dropped as well — the
+// grouping already puts them on together, and the preference would only
+// reward the group for working more. An "apart" rule between two members of
+// one group is kept, because it still means what it says: that group cannot
+// work at all. The admin screen refuses to make one, but the roster is a
+// Google Sheet and a group can form under a rule made before it.
+func PairRulesFor(input *CpsatInput, rules []CpsatPairRule) []CpsatPairRule {
+	groupOf := make(map[string]string)
+	for _, group := range input.Groups {
+		for _, member := range group.Members {
+			groupOf[member.ID] = group.GroupKey
+		}
+	}
+
+	kept := []CpsatPairRule{}
+	for _, rule := range rules {
+		a, okA := groupOf[rule.VolunteerA]
+		b, okB := groupOf[rule.VolunteerB]
+		if !okA || !okB {
+			continue
+		}
+		if rule.Kind == PairTogether && a == b {
+			continue
+		}
+		kept = append(kept, rule)
+	}
+	return kept
+}
+
 // CpsatOutputToShifts rebuilds Shift values from the solver output so
 // persistence (convertToDBAllocations) and printing reuse the existing
 // code paths.
@@ -0,0 +1,350 @@
+package allocator
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Why a Seat was left empty. The values are the wire's as well as this
+// package's: they are stored with a draft and read by the admin screen, which
+// words its own sentence from them where it wants to.
+const (
+	// EmptySeatNoHolder: nobody in the problem holds the Role at all. Only
+	// volunteers who answered are in the problem, so this is as often "the one
+	// holder never replied" as "nobody on the roster holds it".
+	EmptySeatNoHolder = "no_holder"
+	// EmptySeatNoAvailableHolder: holders exist, but none is available that day.
+	EmptySeatNoAvailableHolder = "no_available_holder"
+	// EmptySeatHoldersOnShift: every available holder is already working the
+	// shift, in another Seat. A person fills one Seat on a Shift.
+	EmptySeatHoldersOnShift = "holders_already_on_shift"
+	// EmptySeatGroupDoesNotFit: every available holder comes with group-mates,
+	// and the Seats left cannot take the whole group.
+	EmptySeatGroupDoesNotFit = "group_does_not_fit"
+	// EmptySeatNotChosen: an available holder broke no rule, and the solver left
+	// the Seat empty anyway — placing them elsewhere scored better.
+	EmptySeatNotChosen = "not_chosen"
+	// EmptySeatSeveralReasons: available holders were kept off the Seat, but not
+	// all by the same rule. Detail counts them by rule.
+	EmptySeatSeveralReasons = "several_reasons"
+)
+
+// The switchable rules double as reasons, under their own names: "every
+// available holder hit max_frequency" is said as max_frequency. So do
+// never_together, the one fundamental rule that is about particular people,
+// and the one preference that can keep a holder off a Seat on its own account,
+// preferred_frequency: they already have as many shifts as they asked for.
+
+// EmptySeat is Count Seats of one Role on one shift that the solve left empty,
+// and why. Seats of one Role on one shift are interchangeable, so they share
+// one reason rather than each repeating it.
+type EmptySeat struct {
+	ShiftIndex int
+	Date       string
+	Role       string
+	Count      int
+	// Reason is one of the EmptySeat* constants or a switchable rule's name.
+	Reason string
+	// Detail is Reason as a sentence an admin can act on.
+	Detail string
+}
+
+// ExplainEmptySeats says, for every Seat a successful solve left empty on an
+// open shift, why.
+//
+// It reads nothing but the contract, so it explains either engine's answer the
+// same way, and it explains rather than re-solves: each available holder of the
+// Role is checked against the rules in force, and the Seat is put down to the
+// rule that kept them off it. When every holder was kept off by the same rule,
+// that is the reason; when they were kept off by different ones, the reason
+// says so and the detail counts them.
+//
+// An infeasible solve has no empty Seats to explain — there is no rota — so it
+// gets none.
+func ExplainEmptySeats(input *CpsatInput, output *CpsatOutput) []EmptySeat {
+	if input == nil || output == nil || !output.Success {
+		return nil
+	}
+
+	state := newSolvedState(input, output)
+
+	var empty []EmptySeat
+	for _, shift := range input.Shifts {
+		if shift.Closed {
+			continue
+		}
+		for _, role := range sortedShape(shift.Shape, input.Roles) {
+			count := role.Count - state.occupants[shift.Index][role.Role]
+			if count <= 0 {
+				continue
+			}
+			reason, detail := state.explain(shift, role.Role)
+			empty = append(empty, EmptySeat{
+				ShiftIndex: shift.Index,
+				Date:       shift.Date,
+				Role:       role.Role,
+				Count:      count,
+				Reason:     reason,
+				Detail:     detail,
+			})
+		}
+	}
+	return empty
+}
+
+// solvedState is the solve's answer read back as facts the rules are phrased
+// in: who works which shift, how full each shift is, and who is male.
+type solvedState struct {
+	input    *CpsatInput
+	enabled  map[string]bool
+	shiftsBy map[int]CpsatShift
+	// worked is the shifts each group works in this rota, by group key.
+	worked map[string]map[int]bool
+	// occupants counts filled Seats per shift index and Role, customs included.
+	occupants map[int]map[string]int
+	males     map[int]int
+	isMale    map[string]bool
+	// lastHistorical and historicalMonths are what no_back_to_back and
+	// one_shift_per_month read from the rotas before this one.
+	lastHistorical   map[string]bool
+	historicalMonths map[string]map[string]bool
+	// groupOf and apart are what never_together reads: each volunteer's group
+	// key, and their "apart" partners.
+	groupOf map[string]string
+	apart   map[string][]string
+}
+
+func newSolvedState(input *CpsatInput, output *CpsatOutput) *solvedState {
+	state := &solvedState{
+		input:            input,
+		enabled:          make(map[string]bool),
+		shiftsBy:         make(map[int]CpsatShift, len(input.Shifts)),
+		worked:           make(map[string]map[int]bool),
+		occupants:        make(map[int]map[string]int),
+		males:            make(map[int]int),
+		isMale:           make(map[string]bool),
+		lastHistorical:   make(map[string]bool),
+		historicalMonths: make(map[string]map[string]bool),
+		groupOf:          make(map[string]string),
+		apart:            make(map[string][]string),
+	}
+	for _, name := range input.EnabledConstraints {
+		state.enabled[name] = true
+	}
+	for _, shift := range input.Shifts {
+		state.shiftsBy[shift.Index] = shift
+	}
+	for _, group := range input.Groups {
+		for _, member := range group.Members {
+			state.isMale[member.ID] = member.Gender == GenderMale
+			state.groupOf[member.ID] = group.GroupKey
+		}
+	}
+	for _, rule := range input.PairRules {
+		if rule.Kind == PairApart {
+			state.apart[rule.VolunteerA] = append(state.apart[rule.VolunteerA], rule.VolunteerB)
+			state.apart[rule.VolunteerB] = append(state.apart[rule.VolunteerB], rule.VolunteerA)
+		}
+	}
+	for _, shift := range output.Shifts {
+		state.occupants[shift.Index] = make(map[string]int)
+		for _, assignment := range shift.Assignments {
+			state.occupants[shift.Index][assignment.Role]++
+			if state.isMale[assignment.VolunteerID] {
+				state.males[shift.Index]++
+			}
+		}
+		for _, key := range shift.AllocatedGroupKeys {
+			if state.worked[key] == nil {
+				state.worked[key] = make(map[int]bool)
+			}
+			state.worked[key][shift.Index] = true
+		}
+	}
+	if len(input.HistoricalShifts) > 0 {
+		for _, key := range input.HistoricalShifts[len(input.HistoricalShifts)-1].GroupKeys {
+			state.lastHistorical[key] = true
+		}
+	}
+	for _, shift := range input.HistoricalShifts {
+		for _, key := range shift.GroupKeys {
+			if state.historicalMonths[key] == nil {
+				state.historicalMonths[key] = make(map[string]bool)
+			}
+			state.historicalMonths[key][monthOf(shift.Date)] = true
+		}
+	}
+	return state
+}
+
+// explain finds why a Role's Seats on a shift were left empty.
+func (s *solvedState) explain(shift CpsatShift, role string) (string, string) {
+	holders := 0
+	blockedBy := make(map[string]int)
+	for _, group := range s.input.Groups {
+		if !groupHolds(group, role) {
+			continue
+		}
+		holders++
+		if !slices.Contains(group.AvailableShiftIndices, shift.Index) {
+			continue
+		}
+		blockedBy[s.blocker(group, shift)]++
+	}
+
+	switch {
+	case holders == 0:
+		return EmptySeatNoHolder, fmt.Sprintf("Nobody who answered holds %s.", role)
+	case len(blockedBy) == 0:
+		return EmptySeatNoAvailableHolder, fmt.Sprintf("No holder of %s is available that day.", role)
+	case len(blockedBy) == 1:
+		for reason := range blockedBy {
+			return reason, s.sentence(reason, role)
+		}
+	}
+
+	reasons := make([]string, 0, len(blockedBy))
+	for reason := range blockedBy {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	counts := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		counts = append(counts, fmt.Sprintf("%d by %s", blockedBy[reason], reason))
+	}
+	return EmptySeatSeveralReasons, fmt.Sprintf(
+		"The available holders of %s were kept off it by more than one rule: %s.",
+		role, strings.Join(counts, ", "))
+}
+
+// blocker is the first rule that kept an available group off the shift, or
+// EmptySeatNotChosen when none did.
+func (s *solvedState) blocker(group CpsatGroup, shift CpsatShift) string {
+	worked := s.worked[group.GroupKey]
+	index := shift.Index
+
+	if worked[index] {
+		return EmptySeatHoldersOnShift
+	}
+	for _, member := range group.Members {
+		for _, partner := range s.apart[member.ID] {
+			if partnerGroup := s.groupOf[partner]; partnerGroup == group.GroupKey || s.worked[partnerGroup][index] {
+				return "never_together"
+			}
+		}
+	}
+	if s.enabled["max_frequency"] && len(worked) >= s.input.MaxAllocationCount {
+		return "max_frequency"
+	}
+	if s.enabled["no_back_to_back"] {
+		if worked[index-1] || worked[index+1] || (index == 0 && s.lastHistorical[group.GroupKey]) {
+			return "no_back_to_back"
+		}
+	}
+	if s.enabled["one_shift_per_month"] {
+		month := monthOf(shift.Date)
+		if s.historicalMonths[group.GroupKey][month] {
+			return "one_shift_per_month"
+		}
+		for other := range worked {
+			if monthOf(s.shiftsBy[other].Date) == month {
+				return "one_shift_per_month"
+			}
+		}
+	}
+
+	free := s.freeSeats(shift)
+	if len(group.Members) > free {
+		return EmptySeatGroupDoesNotFit
+	}
+	if s.enabled["male_required"] && s.males[index] == 0 && !groupHasMale(group) && free-len(group.Members) < 1 {
+		return "male_required"
+	}
+	if group.PreferredShiftCount > 0 && len(worked) >= group.PreferredShiftCount {
+		return "preferred_frequency"
+	}
+	return EmptySeatNotChosen
+}
+
+// freeSeats is every empty Seat on the shift, whatever its Role.
+func (s *solvedState) freeSeats(shift CpsatShift) int {
+	free := 0
+	for _, seat := range shift.Shape {
+		free += seat.Count
+	}
+	for _, filled := range s.occupants[shift.Index] {
+		free -= filled
+	}
+	return max(0, free)
+}
+
+// sentence words a single reason for a Role's empty Seats.
+func (s *solvedState) sentence(reason, role string) string {
+	switch reason {
+	case EmptySeatHoldersOnShift:
+		return fmt.Sprintf("Every available holder of %s is already working this shift in another Seat.", role)
+	case "max_frequency":
+		return fmt.Sprintf("Every available holder of %s already has the most shifts one person may work (%d).", role, s.input.MaxAllocationCount)
+	case "no_back_to_back":
+		return fmt.Sprintf("Every available holder of %s works the shift before or after.", role)
+	case "one_shift_per_month":
+		return fmt.Sprintf("Every available holder of %s already works a shift that month.", role)
+	case "never_together":
+		return fmt.Sprintf("Every available holder of %s is to be kept apart from somebody on this shift.", role)
+	case EmptySeatGroupDoesNotFit:
+		return fmt.Sprintf("Every available holder of %s comes with group-mates, and the Seats left cannot take them all.", role)
+	case "male_required":
+		return "Kept open so a male volunteer can be added by hand."
+	case "preferred_frequency":
+		return fmt.Sprintf("Every available holder of %s already has as many shifts as they asked for.", role)
+	default:
+		return fmt.Sprintf("A holder of %s was available, but the solver placed them elsewhere.", role)
+	}
+}
+
+// sortedShape is a shift's Seats per Role, in Role priority order, with a Role
+// asked for twice in the Shape counted once.
+func sortedShape(shape []CpsatSeat, roles []CpsatRole) []CpsatSeat {
+	counts := make(map[string]int)
+	for _, seat := range shape {
+		counts[seat.Role] += seat.Count
+	}
+	ordered := make([]CpsatSeat, 0, len(counts))
+	for _, role := range roles {
+		if count, ok := counts[role.Name]; ok {
+			ordered = append(ordered, CpsatSeat{Role: role.Name, Count: count})
+			delete(counts, role.Name)
+		}
+	}
+	// A Role the shape names but the roles list does not still has Seats; they
+	// go last, by name.
+	rest := make([]string, 0, len(counts))
+	for role := range counts {
+		rest = append(rest, role)
+	}
+	sort.Strings(rest)
+	for _, role := range rest {
+		ordered = append(ordered, CpsatSeat{Role: role, Count: counts[role]})
+	}
+	return ordered
+}
+
+func groupHolds(group CpsatGroup, role string) bool {
+	for _, member := range group.Members {
+		if holds(member, role) {
+			return true
+		}
+	}
+	return false
+}
+
+func groupHasMale(group CpsatGroup) bool {
+	for _, member := range group.Members {
+		if member.Gender == GenderMale {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+package allocator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// solvedAs is an answer to input with the given shifts filled; every other
+// shift is left empty. Each entry is the group keys working the shift, and
+// each member is seated in the first Role they hold.
+func solvedAs(input *CpsatInput, worked map[int][]string) *CpsatOutput {
+	groups := make(map[string]CpsatGroup, len(input.Groups))
+	for _, group := range input.Groups {
+		groups[group.GroupKey] = group
+	}
+	output := &CpsatOutput{SolverStatus: "FEASIBLE", Success: true}
+	for _, shift := range input.Shifts {
+		out := CpsatOutputShift{Index: shift.Index, Date: shift.Date, Closed: shift.Closed}
+		for _, key := range worked[shift.Index] {
+			out.AllocatedGroupKeys = append(out.AllocatedGroupKeys, key)
+			for _, member := range groups[key].Members {
+				out.Assignments = append(out.Assignments, CpsatAssignment{VolunteerID: member.ID, Role: member.Roles[0]})
+			}
+		}
+		output.Shifts = append(output.Shifts, out)
+	}
+	return output
+}
+
+func TestExplainEmptySeats(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   func() *CpsatInput
+		worked  map[int][]string
+		reason  string
+		contain string
+	}{
+		{
+			name: "nobody holds the Role",
+			input: func() *CpsatInput {
+				return goTestInput([]CpsatGroup{individual("amy", "Female", servers, 0)}, "2026-01-04")
+			},
+			reason:  EmptySeatNoHolder,
+			contain: "Nobody who answered holds Team lead",
+		},
+		{
+			name: "no holder is available",
+			input: func() *CpsatInput {
+				return goTestInput([]CpsatGroup{individual("lead", "Female", leads, 1)}, "2026-01-04", "2026-01-11")
+			},
+			worked:  map[int][]string{1: {"lead"}},
+			reason:  EmptySeatNoAvailableHolder,
+			contain: "No holder of Team lead is available",
+		},
+		{
+			name: "every holder hit max_frequency",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{individual("lead", "Female", leads, 0, 1)}, "2026-01-04", "2026-01-11")
+				input.MaxAllocationCount = 1
+				input.EnabledConstraints = []string{"max_frequency"}
+				return input
+			},
+			worked:  map[int][]string{1: {"lead"}},
+			reason:  "max_frequency",
+			contain: "most shifts one person may work (1)",
+		},
+		{
+			name: "no_back_to_back kept the holder off",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{individual("lead", "Female", leads, 0, 1)}, "2026-01-04", "2026-01-11")
+				input.EnabledConstraints = []string{"no_back_to_back"}
+				return input
+			},
+			worked:  map[int][]string{1: {"lead"}},
+			reason:  "no_back_to_back",
+			contain: "the shift before or after",
+		},
+		{
+			name: "no_back_to_back reads the last historical shift",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{individual("lead", "Female", leads, 0)}, "2026-01-04")
+				input.EnabledConstraints = []string{"no_back_to_back"}
+				input.HistoricalShifts = []CpsatHistoricalShift{{Date: "2025-12-28", GroupKeys: []string{"lead"}}}
+				return input
+			},
+			reason: "no_back_to_back",
+		},
+		{
+			name: "one_shift_per_month kept the holder off",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{individual("lead", "Female", leads, 0, 2)}, "2026-01-04", "2026-01-11", "2026-01-18")
+				input.EnabledConstraints = []string{"one_shift_per_month"}
+				return input
+			},
+			worked:  map[int][]string{2: {"lead"}},
+			reason:  "one_shift_per_month",
+			contain: "already works a shift that month",
+		},
+		{
+			name: "the holder is kept apart from somebody on the shift",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{
+					individual("lead", "Female", leads, 0),
+					individual("sam", "Female", servers, 0),
+				}, "2026-01-04")
+				input.PairRules = []CpsatPairRule{{VolunteerA: "lead", VolunteerB: "sam", Kind: PairApart}}
+				return input
+			},
+			worked:  map[int][]string{0: {"sam"}},
+			reason:  "never_together",
+			contain: "kept apart from somebody on this shift",
+		},
+		{
+			name: "the holder's group does not fit",
+			input: func() *CpsatInput {
+				return goTestInput([]CpsatGroup{
+					{
+						GroupKey: "family",
+						Members: []CpsatMember{
+							{ID: "mum", Roles: leads},
+							{ID: "dad", Roles: servers},
+						},
+						AvailableShiftIndices: []int{0},
+					},
+					individual("sam", "Female", servers, 0),
+					individual("kit", "Female", servers, 0),
+				}, "2026-01-04")
+			},
+			worked:  map[int][]string{0: {"sam", "kit"}},
+			reason:  EmptySeatGroupDoesNotFit,
+			contain: "cannot take them all",
+		},
+		{
+			name: "male_required held the Seat open",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{
+					individual("lead", "Female", leads, 0),
+					individual("sam", "Female", servers, 0),
+					individual("kit", "Female", servers, 0),
+				}, "2026-01-04")
+				input.EnabledConstraints = []string{"male_required"}
+				return input
+			},
+			worked:  map[int][]string{0: {"sam", "kit"}},
+			reason:  "male_required",
+			contain: "male volunteer can be added by hand",
+		},
+		{
+			name: "the holder has the shifts they asked for",
+			input: func() *CpsatInput {
+				lead := individual("lead", "Female", leads, 0, 1)
+				lead.PreferredShiftCount = 1
+				return goTestInput([]CpsatGroup{lead}, "2026-01-04", "2026-01-11")
+			},
+			worked:  map[int][]string{1: {"lead"}},
+			reason:  "preferred_frequency",
+			contain: "as many shifts as they asked for",
+		},
+		{
+			name: "an available holder broke no rule",
+			input: func() *CpsatInput {
+				return goTestInput([]CpsatGroup{individual("lead", "Female", leads, 0)}, "2026-01-04")
+			},
+			reason:  EmptySeatNotChosen,
+			contain: "placed them elsewhere",
+		},
+		{
+			name: "holders kept off by different rules",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{
+					individual("ann", "Female", leads, 0, 2, 3),
+					individual("bea", "Female", leads, 0, 1),
+				}, "2026-01-04", "2026-01-11", "2026-01-18", "2026-01-25")
+				input.MaxAllocationCount = 2
+				input.EnabledConstraints = []string{"max_frequency", "no_back_to_back"}
+				return input
+			},
+			worked:  map[int][]string{1: {"bea"}, 2: {"ann"}, 3: {"ann"}},
+			reason:  EmptySeatSeveralReasons,
+			contain: "1 by max_frequency, 1 by no_back_to_back",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input()
+			empty := ExplainEmptySeats(input, solvedAs(input, tt.worked))
+
+			var lead *EmptySeat
+			for i := range empty {
+				if empty[i].ShiftIndex == 0 && empty[i].Role == "Team lead" {
+					lead = &empty[i]
+				}
+			}
+			require.NotNil(t, lead, "the Team lead Seat on the first shift should be explained")
+			assert.Equal(t, 1, lead.Count)
+			assert.Equal(t, tt.reason, lead.Reason)
+			assert.Contains(t, lead.Detail, tt.contain)
+		})
+	}
+}
+
+func TestExplainEmptySeats_CountsSeatsPerRoleAndSkipsFullOrClosedShifts(t *testing.T) {
+	input := goTestInput([]CpsatGroup{
+		individual("lead", "Female", leads, 0, 1),
+		individual("amy", "Female", servers, 0, 1),
+	}, "2026-01-04", "2026-01-11", "2026-01-18")
+	input.Shifts[1].Shape = []CpsatSeat{{Role: "Team lead", Count: 1}}
+	input.Shifts[2].Closed = true
+
+	empty := ExplainEmptySeats(input, solvedAs(input, map[int][]string{0: {"lead"}, 1: {"lead"}}))
+
+	require.Len(t, empty, 1, "only shift 0's Service volunteer Seats are empty")
+	assert.Equal(t, 0, empty[0].ShiftIndex)
+	assert.Equal(t, "2026-01-04", empty[0].Date)
+	assert.Equal(t, "Service volunteer", empty[0].Role)
+	assert.Equal(t, 2, empty[0].Count)
+}
+
+func TestExplainEmptySeats_NothingForAnInfeasibleSolve(t *testing.T) {
+	input := goTestInput([]CpsatGroup{individual("lead", "Female", leads, 0)}, "2026-01-04")
+
+	assert.Empty(t, ExplainEmptySeats(input, &CpsatOutput{SolverStatus: "INFEASIBLE"}))
+	assert.Empty(t, ExplainEmptySeats(input, nil))
+}
@@ -0,0 +1,137 @@
+package allocator
+
+import "slices"
+
+// diagnoseGoInput explains an INFEASIBLE answer from the in-process engine the
+// way pyallocator's diagnosis explains CP-SAT's: a minimal set of the run's
+// pins and switchable rules that cannot all hold.
+//
+// The search is the same deletion pass. Every volunteer pin and every
+// switchable rule the run applied starts in the set; each is dropped in turn,
+// and stays dropped if the rest still cannot be placed. What is left is
+// minimal — drop any one member and the rest can be placed — though not necessarily
+// the smallest such set.
+//
+// Only pins can make this engine's answer INFEASIBLE (the greedy fill never
+// places anything a rule forbids), so each check is the pin phase alone and
+// costs next to nothing. never_together joins the switchable rules as a
+// candidate when the run has any "apart" rule, because pyallocator guards
+// every constraint it applies and so names it too. A conflict with no rule
+// left in it is pins the Shape has no room for, which is seat_capacity's to
+// forbid, and is named as that.
+//
+// Returns nil when the input is not infeasible after all, which it never is
+// when called from RunGoAllocator.
+func diagnoseGoInput(input *CpsatInput) *CpsatConflict {
+	var pins []CpsatConflictPin
+	for _, shift := range input.Shifts {
+		for _, pin := range shift.Preallocations {
+			if pin.VolunteerID == "" {
+				continue // a custom entry is not a decision; it only takes a Seat
+			}
+			pins = append(pins, CpsatConflictPin{
+				ShiftIndex:  shift.Index,
+				Date:        shift.Date,
+				VolunteerID: pin.VolunteerID,
+				Role:        pin.Role,
+			})
+		}
+	}
+	var rules []string
+	// First, as the fundamentals come before the switchable rules in
+	// pyallocator's list.
+	if slices.ContainsFunc(input.PairRules, func(rule CpsatPairRule) bool { return rule.Kind == PairApart }) {
+		rules = append(rules, "never_together")
+	}
+	for _, name := range input.EnabledConstraints {
+		if goSwitchableConstraints[name] && !slices.Contains(rules, name) {
+			rules = append(rules, name)
+		}
+	}
+
+	if pinsHold(input, pins, rules) {
+		return nil
+	}
+
+	// Rules before pins, as pyallocator orders its items, so where more than
+	// one minimal set exists the two engines lean the same way: towards
+	// keeping the rule and naming the pins that break it.
+	for i := 0; i < len(rules); {
+		without := append(append([]string(nil), rules[:i]...), rules[i+1:]...)
+		if !pinsHold(input, pins, without) {
+			rules = without
+			continue
+		}
+		i++
+	}
+	for i := 0; i < len(pins); {
+		without := append(append([]CpsatConflictPin(nil), pins[:i]...), pins[i+1:]...)
+		if !pinsHold(input, without, rules) {
+			pins = without
+			continue
+		}
+		i++
+	}
+
+	groupOf := make(map[string]string)
+	for _, group := range input.Groups {
+		for _, member := range group.Members {
+			groupOf[member.ID] = group.GroupKey
+		}
+	}
+	for i := range pins {
+		pins[i].GroupKey = groupOf[pins[i].VolunteerID]
+	}
+
+	constraints := rules
+	if len(constraints) == 0 {
+		constraints = []string{"seat_capacity"}
+	}
+	if pins == nil {
+		pins = []CpsatConflictPin{}
+	}
+	return &CpsatConflict{Constraints: constraints, Preallocations: pins}
+}
+
+// pinsHold reports whether the given volunteer pins can all be placed under the
+// given rules: switchable ones by name, and the "apart" Pairing Rules only while
+// never_together is among them. Custom pins always stay: they are not
+// decisions, only Seats already taken.
+func pinsHold(input *CpsatInput, pins []CpsatConflictPin, rules []string) bool {
+	kept := make(map[int]map[string]bool)
+	for _, pin := range pins {
+		if kept[pin.ShiftIndex] == nil {
+			kept[pin.ShiftIndex] = make(map[string]bool)
+		}
+		kept[pin.ShiftIndex][pin.VolunteerID] = true
+	}
+
+	trial := *input
+	trial.EnabledConstraints = rules
+	if !slices.Contains(rules, "never_together") {
+		trial.PairRules = nil
+		for _, rule := range input.PairRules {
+			if rule.Kind != PairApart {
+				trial.PairRules = append(trial.PairRules, rule)
+			}
+		}
+	}
+	trial.Shifts = make([]CpsatShift, len(input.Shifts))
+	for i, shift := range input.Shifts {
+		shift.Preallocations = nil
+		for _, pin := range input.Shifts[i].Preallocations {
+			if pin.VolunteerID == "" || kept[shift.Index][pin.VolunteerID] {
+				shift.Preallocations = append(shift.Preallocations, pin)
+			}
+		}
+		trial.Shifts[i] = shift
+	}
+
+	problem, err := newGoProblem(&trial)
+	if err != nil {
+		// A subset of a valid input is valid; this would be a bug, and the
+		// safe reading of one is "this subset is not the conflict".
+		return true
+	}
+	return problem.placePins()
+}
@@ -0,0 +1,104 @@
+package allocator
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pin(volunteerID, role string) []CpsatPreallocation {
+	return []CpsatPreallocation{{VolunteerID: volunteerID, Role: role}}
+}
+
+func TestRunGoAllocator_ExplainsAnInfeasibleRota(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       func() *CpsatInput
+		constraints []string
+		pins        []string // "volunteer@shift index"
+	}{
+		{
+			name: "pins on consecutive shifts under no_back_to_back",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{
+					individual("ann", "Female", servers, 0, 1),
+					individual("bea", "Female", servers, 0, 1),
+				}, "2026-11-01", "2026-11-08")
+				input.EnabledConstraints = []string{"max_frequency", "no_back_to_back"}
+				input.Shifts[0].Preallocations = append(pin("ann", "Service volunteer"), pin("bea", "Service volunteer")...)
+				input.Shifts[1].Preallocations = pin("ann", "Service volunteer")
+				return input
+			},
+			constraints: []string{"no_back_to_back"},
+			pins:        []string{"ann@0", "ann@1"},
+		},
+		{
+			name: "more pins than the cap allows",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{individual("ann", "Female", servers, 0, 1, 2)}, "2026-11-01", "2026-11-08", "2026-11-15")
+				input.MaxAllocationCount = 2
+				input.EnabledConstraints = []string{"max_frequency"}
+				for i := range input.Shifts {
+					input.Shifts[i].Preallocations = pin("ann", "Service volunteer")
+				}
+				return input
+			},
+			constraints: []string{"max_frequency"},
+			pins:        []string{"ann@0", "ann@1", "ann@2"},
+		},
+		{
+			name: "a pair pinned together who are to be kept apart",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{
+					individual("ann", "Female", servers, 0),
+					individual("bea", "Female", servers, 0),
+				}, "2026-11-01")
+				input.EnabledConstraints = []string{"no_back_to_back"}
+				input.PairRules = []CpsatPairRule{{VolunteerA: "ann", VolunteerB: "bea", Kind: PairApart}}
+				input.Shifts[0].Preallocations = append(pin("ann", "Service volunteer"), pin("bea", "Service volunteer")...)
+				return input
+			},
+			constraints: []string{"never_together"},
+			pins:        []string{"ann@0", "bea@0"},
+		},
+		{
+			name: "two pins to one Seat",
+			input: func() *CpsatInput {
+				input := goTestInput([]CpsatGroup{
+					individual("ann", "Female", leads, 0),
+					individual("bea", "Female", leads, 0),
+				}, "2026-11-01")
+				input.Shifts[0].Preallocations = append(pin("ann", "Team lead"), pin("bea", "Team lead")...)
+				return input
+			},
+			constraints: []string{"seat_capacity"},
+			pins:        []string{"ann@0", "bea@0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := solveGo(t, tt.input())
+
+			require.False(t, output.Success)
+			conflict := output.Diagnostics.Conflict
+			require.NotNil(t, conflict)
+			assert.Equal(t, tt.constraints, conflict.Constraints)
+			pins := make([]string, 0, len(conflict.Preallocations))
+			for _, p := range conflict.Preallocations {
+				pins = append(pins, fmt.Sprintf("%s@%d", p.VolunteerID, p.ShiftIndex))
+				assert.Equal(t, p.VolunteerID, p.GroupKey, "individuals are their own group")
+			}
+			assert.Equal(t, tt.pins, pins)
+		})
+	}
+}
+
+func TestRunGoAllocator_AFeasibleRotaHasNoConflict(t *testing.T) {
+	output := solveGo(t, goTestInput([]CpsatGroup{individual("ann", "Female", leads, 0)}, "2026-11-01"))
+
+	require.True(t, output.Success)
+	assert.Nil(t, output.Diagnostics.Conflict)
+}
@@ -0,0 +1,747 @@
+package allocator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// This file is the in-process fallback to pyallocator: a greedy solver that
+// reads the same CpsatInput and answers with the same CpsatOutput, so nothing
+// either side of the contract can tell which engine ran.
+//
+// It is a fallback rather than a second opinion. CP-SAT searches for the best
+// rota; this builds a good one a Seat at a time, scoring each placement with the
+// same weights pyallocator's preferences use, and never revisits a placement it
+// has made. What it must never do is produce a rota CP-SAT would call illegal,
+// so every fundamental constraint and every switchable one pyallocator knows is
+// honoured exactly — only the objective is approximated.
+
+// The rules this engine applies, named as pyallocator names them so
+// Diagnostics.ConstraintsApplied reads the same whichever engine ran.
+var (
+	goFundamentalConstraints = []string{
+		"no_duplicate_allocation",
+		"grouping",
+		"availability",
+		"seat_capacity",
+		"closed_shifts",
+		"preallocations",
+		"never_together",
+	}
+	goSwitchableConstraints = map[string]bool{
+		"max_frequency":       true,
+		"male_required":       true,
+		"no_back_to_back":     true,
+		"one_shift_per_month": true,
+	}
+)
+
+// The weights of pyallocator's preferences (preferences/*.py), so that a
+// placement this engine prefers is one CP-SAT's objective would prefer too.
+const (
+	goEvenFillWeight    = 60
+	goPriorityBand      = goEvenFillWeight + 1
+	goSpreadMalesWeight = 30
+	goFairnessWeight    = 20
+	// goPreferredFrequencyWeight is a cost, not a reward: what each shift past
+	// a group's preferred count takes off the objective.
+	goPreferredFrequencyWeight = 40
+	// goPairTogetherWeight is what each shift two volunteers with a "together"
+	// rule share is worth.
+	goPairTogetherWeight = 25
+)
+
+// Solver statuses, in CP-SAT's words. A greedy solve proves nothing optimal,
+// so success is only ever FEASIBLE.
+const (
+	goStatusFeasible   = "FEASIBLE"
+	goStatusInfeasible = "INFEASIBLE"
+)
+
+// RunGoAllocator solves the problem in-process, for a host with no Python.
+//
+// Its errors match RunCpsatAllocator's: an input the solver cannot make sense of
+// (a pin naming nobody, a pin to a closed shift) is an error, and a rota the
+// constraints rule out is a well-formed INFEASIBLE output with Success=false.
+// The context is honoured between placements, since a solve here never waits on
+// anything but itself.
+func RunGoAllocator(ctx context.Context, input *CpsatInput, logger *zap.Logger) (*CpsatOutput, error) {
+	started := time.Now()
+
+	problem, err := newGoProblem(input)
+	if err != nil {
+		return nil, fmt.Errorf("go allocator rejected its input: %w", err)
+	}
+
+	logger.Debug("Running in-process allocator",
+		zap.Int("groups", len(problem.groups)),
+		zap.Int("shifts", len(problem.shifts)),
+		zap.Strings("constraints", problem.constraintsApplied()))
+
+	objective, feasible, err := problem.solve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &CpsatOutput{
+		SolverStatus:   goStatusFeasible,
+		Success:        true,
+		ObjectiveValue: objective,
+		Shifts:         problem.outputShifts(),
+		Diagnostics: CpsatDiagnostics{
+			SolveTimeSeconds:   time.Since(started).Seconds(),
+			NumGroups:          len(problem.groups),
+			ConstraintsApplied: problem.constraintsApplied(),
+		},
+	}
+	if !feasible {
+		// An infeasible CP-SAT run reports its shifts with nobody in them, and
+		// so does this: a half-built rota is not an answer.
+		output.SolverStatus = goStatusInfeasible
+		output.Success = false
+		output.ObjectiveValue = 0
+		output.Shifts = problem.emptyShifts()
+		output.Diagnostics.Conflict = diagnoseGoInput(input)
+	}
+	return output, nil
+}
+
+// goGroup is one allocation unit and what this solve has done with it so far.
+type goGroup struct {
+	key     string
+	members []CpsatMember
+	males   int
+	// available is the shifts the group answered yes to, plus the ones a pin
+	// settled for it (withPreallocatedAvailability).
+	available map[int]bool
+	// history is the group's historical allocations as fairness weighs them:
+	// HistoricalAllocationCount, less what the horizon's decay takes off the
+	// older shifts.
+	history float64
+	// allocated is the shifts this rota has put the group on, as a set.
+	allocated map[int]bool
+	// preferred is how many shifts the group would like, 0 for no preference.
+	preferred int
+	// historicalMonths is every YYYY-MM the group already worked in history.
+	historicalMonths map[string]bool
+	// workedLastShift says the group was on the previous rota's final shift.
+	workedLastShift bool
+}
+
+// goShift is one shift and the Seats taken on it so far.
+type goShift struct {
+	spec CpsatShift
+	// capacity is the Seats each Role offers the solver: the Shape's count, less
+	// the custom pins already sitting in them.
+	capacity map[string]int
+	customs  map[string]int
+	// occupants is how many of capacity volunteers now fill, per Role.
+	occupants map[string]int
+	males     int
+	// seated is who sits on the shift and in which Role, by volunteer id.
+	seated map[string]string
+}
+
+type goProblem struct {
+	input          *CpsatInput
+	groups         []*goGroup
+	shifts         []*goShift
+	bands          map[string]int
+	enabled        map[string]bool
+	maxAllocations int
+	// pinnedRoles is the Role each pinned volunteer fills, keyed by volunteer
+	// id then shift index. It is also a grant: a pinned volunteer may fill the
+	// Role there whether or not they hold it, as in pyallocator's may_fill.
+	pinnedRoles map[string]map[int]string
+	// pinnedGroups is the pinned groups of each shift, by shift index, in key
+	// order so pins are placed the same way every run.
+	pinnedGroups map[int][]*goGroup
+	// apart and together are the Pairing Rules, as each volunteer's partners
+	// of that kind by volunteer id, in both directions.
+	apart    map[string][]string
+	together map[string][]string
+}
+
+// newGoProblem validates the input the way pyallocator's Problem does and
+// builds the state the solve works on.
+func newGoProblem(input *CpsatInput) (*goProblem, error) {
+	problem := &goProblem{
+		input:          input,
+		bands:          priorityBands(input.Roles),
+		enabled:        make(map[string]bool),
+		maxAllocations: input.MaxAllocationCount,
+		pinnedRoles:    make(map[string]map[int]string),
+		pinnedGroups:   make(map[int][]*goGroup),
+		apart:          make(map[string][]string),
+		together:       make(map[string][]string),
+	}
+	for _, name := range input.EnabledConstraints {
+		// A name this engine does not know selects nothing, as it selects
+		// nothing in pyallocator (ADR 0006).
+		if goSwitchableConstraints[name] {
+			problem.enabled[name] = true
+		}
+	}
+
+	lastHistorical := make(map[string]bool)
+	if len(input.HistoricalShifts) > 0 {
+		for _, key := range input.HistoricalShifts[len(input.HistoricalShifts)-1].GroupKeys {
+			lastHistorical[key] = true
+		}
+	}
+	historicalMonths := make(map[string]map[string]bool)
+	// A zero Weight is one nobody set — the field is newer than the contract
+	// — and counts in full, as pyallocator counts a missing one.
+	discount := make(map[string]float64)
+	for _, shift := range input.HistoricalShifts {
+		weight := shift.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 || weight > 1 {
+			return nil, fmt.Errorf("historical shift '%s' has weight %v, outside (0, 1]", shift.Date, shift.Weight)
+		}
+		for _, key := range shift.GroupKeys {
+			if historicalMonths[key] == nil {
+				historicalMonths[key] = make(map[string]bool)
+			}
+			historicalMonths[key][monthOf(shift.Date)] = true
+			discount[key] += 1 - weight
+		}
+	}
+
+	groupByMember := make(map[string]*goGroup)
+	for _, group := range input.Groups {
+		if len(group.Members) == 0 {
+			return nil, fmt.Errorf("group '%s' has no members", group.GroupKey)
+		}
+		g := &goGroup{
+			key:              group.GroupKey,
+			members:          group.Members,
+			available:        make(map[int]bool, len(group.AvailableShiftIndices)),
+			history:          max(float64(group.HistoricalAllocationCount)-discount[group.GroupKey], 0),
+			allocated:        make(map[int]bool),
+			preferred:        group.PreferredShiftCount,
+			historicalMonths: historicalMonths[group.GroupKey],
+			workedLastShift:  lastHistorical[group.GroupKey],
+		}
+		for _, index := range group.AvailableShiftIndices {
+			g.available[index] = true
+		}
+		for _, member := range group.Members {
+			if _, seen := groupByMember[member.ID]; seen {
+				return nil, fmt.Errorf("volunteer id '%s' appears in more than one group", member.ID)
+			}
+			groupByMember[member.ID] = g
+			if member.Gender == GenderMale {
+				g.males++
+			}
+		}
+		problem.groups = append(problem.groups, g)
+	}
+
+	for _, spec := range input.Shifts {
+		shift := &goShift{
+			spec:      spec,
+			capacity:  make(map[string]int),
+			customs:   make(map[string]int),
+			occupants: make(map[string]int),
+			seated:    make(map[string]string),
+		}
+		if spec.Closed && len(spec.Preallocations) > 0 {
+			return nil, fmt.Errorf("shift %d is closed but has preallocations", spec.Index)
+		}
+		for _, seat := range spec.Shape {
+			shift.capacity[seat.Role] += seat.Count
+		}
+		for _, pin := range spec.Preallocations {
+			if pin.VolunteerID == "" {
+				shift.customs[pin.Role]++
+				continue
+			}
+			group, known := groupByMember[pin.VolunteerID]
+			if !known {
+				return nil, fmt.Errorf("preallocated volunteer '%s' on shift %d does not match any volunteer", pin.VolunteerID, spec.Index)
+			}
+			if shift.capacity[pin.Role] < 1 {
+				return nil, fmt.Errorf("preallocated volunteer '%s' on shift %d is pinned to role '%s', which that shift has no Seat for", pin.VolunteerID, spec.Index, pin.Role)
+			}
+			if problem.pinnedRoles[pin.VolunteerID] == nil {
+				problem.pinnedRoles[pin.VolunteerID] = make(map[int]string)
+			}
+			problem.pinnedRoles[pin.VolunteerID][spec.Index] = pin.Role
+			if !containsGroup(problem.pinnedGroups[spec.Index], group) {
+				problem.pinnedGroups[spec.Index] = append(problem.pinnedGroups[spec.Index], group)
+			}
+		}
+		for role, customs := range shift.customs {
+			shift.capacity[role] = max(0, shift.capacity[role]-customs)
+		}
+		problem.shifts = append(problem.shifts, shift)
+	}
+	for _, rule := range input.PairRules {
+		if rule.VolunteerA == rule.VolunteerB {
+			return nil, fmt.Errorf("pair rule names volunteer '%s' twice", rule.VolunteerA)
+		}
+		for _, id := range []string{rule.VolunteerA, rule.VolunteerB} {
+			if _, known := groupByMember[id]; !known {
+				return nil, fmt.Errorf("pair rule volunteer '%s' does not match any volunteer", id)
+			}
+		}
+		var partners map[string][]string
+		switch rule.Kind {
+		case PairApart:
+			partners = problem.apart
+		case PairTogether:
+			partners = problem.together
+		default:
+			return nil, fmt.Errorf("pair rule between '%s' and '%s' has unknown kind '%s'", rule.VolunteerA, rule.VolunteerB, rule.Kind)
+		}
+		partners[rule.VolunteerA] = append(partners[rule.VolunteerA], rule.VolunteerB)
+		partners[rule.VolunteerB] = append(partners[rule.VolunteerB], rule.VolunteerA)
+	}
+	for index := range problem.pinnedGroups {
+		sort.Slice(problem.pinnedGroups[index], func(i, j int) bool {
+			return problem.pinnedGroups[index][i].key < problem.pinnedGroups[index][j].key
+		})
+	}
+
+	return problem, nil
+}
+
+// solve places the pins, then fills Seats greedily until no placement is left
+// that every rule allows. It reports the objective the placements scored and
+// whether the pins left a legal rota at all.
+func (p *goProblem) solve(ctx context.Context) (int, bool, error) {
+	if !p.placePins() {
+		return 0, false, nil
+	}
+
+	objective := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, false, fmt.Errorf("go allocator interrupted: %w", err)
+		}
+
+		var (
+			bestGroup   *goGroup
+			bestShift   *goShift
+			bestSeating map[string]string
+			bestScore   int
+		)
+		for _, group := range p.groups {
+			for _, shift := range p.shifts {
+				if !p.mayWork(group, shift) {
+					continue
+				}
+				seating, seatScore, ok := p.seatGroup(shift, group, false)
+				if !ok || !p.keepsMaleSeat(shift, group, seating) {
+					continue
+				}
+				score := seatScore + p.fairnessScore(group) + p.malesScore(shift, group) + p.pairScore(shift, group) + len(group.members) - p.preferenceCost(group)
+				if score <= 0 {
+					// Only a preferred count can make a placement cost more
+					// than it earns, and CP-SAT would leave the Seat empty
+					// rather than make it.
+					continue
+				}
+				// Strictly greater, so ties go to the first group in input order
+				// and its earliest shift, which keeps the solve deterministic —
+				// allocating confirms a draft by the hash of its output (ADR 0008).
+				if bestGroup == nil || score > bestScore {
+					bestGroup, bestShift, bestSeating, bestScore = group, shift, seating, score
+				}
+			}
+		}
+		if bestGroup == nil {
+			return objective, true, nil
+		}
+		p.place(bestShift, bestGroup, bestSeating)
+		objective += bestScore
+	}
+}
+
+// placePins places every pinned group and reports whether the rules can live
+// with them. Pins go first, and unconditionally: they are decisions already
+// taken, so the only question about them is whether the rules allow them.
+func (p *goProblem) placePins() bool {
+	for _, shift := range p.shifts {
+		for _, group := range p.pinnedGroups[shift.spec.Index] {
+			seating, _, ok := p.seatGroup(shift, group, true)
+			if !ok {
+				return false
+			}
+			p.place(shift, group, seating)
+		}
+	}
+	return p.pinsAreLegal()
+}
+
+// mayWork applies every rule about whether a group works a shift, as opposed to
+// which Seats its members would take there.
+func (p *goProblem) mayWork(group *goGroup, shift *goShift) bool {
+	index := shift.spec.Index
+	if shift.spec.Closed || group.allocated[index] || !group.available[index] {
+		return false
+	}
+	if p.keptApart(group, shift) {
+		return false
+	}
+	if p.enabled["max_frequency"] && len(group.allocated)+1 > p.maxAllocations {
+		return false
+	}
+	if p.enabled["no_back_to_back"] {
+		if group.allocated[index-1] || group.allocated[index+1] {
+			return false
+		}
+		if index == 0 && group.workedLastShift {
+			return false
+		}
+	}
+	if p.enabled["one_shift_per_month"] && p.worksInMonth(group, monthOf(shift.spec.Date)) {
+		return false
+	}
+	return true
+}
+
+// pinsAreLegal checks the placed pins against the switchable rules. A pin is
+// placed whatever they say, so a pin they forbid is a rota they forbid.
+func (p *goProblem) pinsAreLegal() bool {
+	for _, group := range p.groups {
+		if p.enabled["max_frequency"] && len(group.allocated) > p.maxAllocations {
+			return false
+		}
+		if p.enabled["no_back_to_back"] {
+			for index := range group.allocated {
+				if group.allocated[index+1] || (index == 0 && group.workedLastShift) {
+					return false
+				}
+			}
+		}
+		if p.enabled["one_shift_per_month"] {
+			months := make(map[string]bool)
+			for index := range group.allocated {
+				month := monthOf(p.shifts[index].spec.Date)
+				if months[month] || group.historicalMonths[month] {
+					return false
+				}
+				months[month] = true
+			}
+		}
+	}
+	if p.enabled["male_required"] {
+		for _, shift := range p.shifts {
+			if !shift.spec.Closed && shift.males == 0 && !hasOpenSeat(shift, nil) {
+				return false
+			}
+		}
+	}
+	// never_together is fundamental, so it is checked whatever was enabled.
+	for _, shift := range p.shifts {
+		for id := range shift.seated {
+			for _, partner := range p.apart[id] {
+				if _, on := shift.seated[partner]; on {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// keptApart applies never_together: a group may not join a shift that has one
+// of its members' "apart" partners on it, nor work at all when two of its own
+// members are to be kept apart.
+func (p *goProblem) keptApart(group *goGroup, shift *goShift) bool {
+	for _, member := range group.members {
+		for _, partner := range p.apart[member.ID] {
+			if _, on := shift.seated[partner]; on || groupHasMember(group, partner) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// worksInMonth reports whether the group already works the month, in history or
+// in this rota.
+func (p *goProblem) worksInMonth(group *goGroup, month string) bool {
+	if group.historicalMonths[month] {
+		return true
+	}
+	for index := range group.allocated {
+		if monthOf(p.shifts[index].spec.Date) == month {
+			return true
+		}
+	}
+	return false
+}
+
+// keepsMaleSeat applies male_required: a shift with no male on it keeps a Seat
+// open so one can be added by hand.
+func (p *goProblem) keepsMaleSeat(shift *goShift, group *goGroup, seating map[string]string) bool {
+	if !p.enabled["male_required"] || shift.males > 0 || group.males > 0 {
+		return true
+	}
+	return hasOpenSeat(shift, seating)
+}
+
+// hasOpenSeat reports whether some Role on the shift would still have a Seat
+// free once seating is placed.
+func hasOpenSeat(shift *goShift, seating map[string]string) bool {
+	taking := make(map[string]int)
+	for _, role := range seating {
+		taking[role]++
+	}
+	for role, capacity := range shift.capacity {
+		if shift.occupants[role]+taking[role] < capacity {
+			return true
+		}
+	}
+	return false
+}
+
+// seatGroup finds the best Seats for a group's members on a shift: one each, in
+// a Role they may fill, within the Role's remaining Seats. It returns the Role
+// by volunteer id and the even-fill score of those Seats, or false when the
+// group cannot all be seated.
+//
+// Members pinned to the shift take their pinned Role. Their group-mates come too
+// — a group works a shift together or not at all — and take whichever Seats
+// they may fill. Groups are a handful of people, so every seating is tried.
+func (p *goProblem) seatGroup(shift *goShift, group *goGroup, pinned bool) (map[string]string, int, bool) {
+	taken := make(map[string]int)
+	current := make(map[string]string, len(group.members))
+	var best map[string]string
+	bestScore := -1
+
+	var try func(i, score int)
+	try = func(i, score int) {
+		if i == len(group.members) {
+			if score > bestScore {
+				best = make(map[string]string, len(current))
+				for id, role := range current {
+					best[id] = role
+				}
+				bestScore = score
+			}
+			return
+		}
+		member := group.members[i]
+		for _, role := range p.rolesFor(shift, member, pinned) {
+			if shift.occupants[role]+taken[role] >= shift.capacity[role] {
+				continue
+			}
+			seatNumber := shift.customs[role] + shift.occupants[role] + taken[role] + 1
+			taken[role]++
+			current[member.ID] = role
+			try(i+1, score+p.bands[role]+goEvenFillWeight/seatNumber)
+			delete(current, member.ID)
+			taken[role]--
+		}
+	}
+	try(0, 0)
+
+	if best == nil {
+		return nil, 0, false
+	}
+	return best, bestScore, true
+}
+
+// rolesFor lists the Roles a member may take on a shift, in priority order. A
+// member pinned there may take only their pinned Role, and only while the pins
+// are being placed — once placed they are already seated.
+func (p *goProblem) rolesFor(shift *goShift, member CpsatMember, pinned bool) []string {
+	if role, ok := p.pinnedRoles[member.ID][shift.spec.Index]; ok && pinned {
+		return []string{role}
+	}
+	var roles []string
+	for _, role := range p.input.Roles {
+		if shift.capacity[role.Name] > 0 && holds(member, role.Name) {
+			roles = append(roles, role.Name)
+		}
+	}
+	return roles
+}
+
+// fairnessScore is the fairness preference's weight for the group's next
+// allocation: the more it has worked, historically and in this rota, the less
+// another one is worth. History is weighted, so the division is a real one,
+// truncated as pyallocator truncates it; with every weight 1 it is the integer
+// division it always was.
+func (p *goProblem) fairnessScore(group *goGroup) int {
+	return int(float64(goFairnessWeight) / (group.history + float64(len(group.allocated)+1)))
+}
+
+// preferenceCost is the preferred_frequency weight of the group's next
+// allocation: nothing until it has worked as many shifts as it would like, and
+// the full weight for every one after.
+func (p *goProblem) preferenceCost(group *goGroup) int {
+	if group.preferred > 0 && len(group.allocated) >= group.preferred {
+		return goPreferredFrequencyWeight
+	}
+	return 0
+}
+
+// pairScore is the pair_together weight of adding the group to a shift: one
+// goPairTogetherWeight for each "together" partner of its members already on
+// it. A greedy fill can only reward the second of the two to be placed, so the
+// first goes where it would have gone anyway and the second follows.
+func (p *goProblem) pairScore(shift *goShift, group *goGroup) int {
+	score := 0
+	for _, member := range group.members {
+		for _, partner := range p.together[member.ID] {
+			if _, on := shift.seated[partner]; on {
+				score += goPairTogetherWeight
+			}
+		}
+	}
+	return score
+}
+
+// malesScore is the spread_males weight of adding the group's males to a shift:
+// the first male on a shift is worth the most.
+func (p *goProblem) malesScore(shift *goShift, group *goGroup) int {
+	score := 0
+	for k := 1; k <= group.males; k++ {
+		score += goSpreadMalesWeight / (shift.males + k)
+	}
+	return score
+}
+
+// place records a group working a shift in the Seats given.
+func (p *goProblem) place(shift *goShift, group *goGroup, seating map[string]string) {
+	for id, role := range seating {
+		shift.seated[id] = role
+		shift.occupants[role]++
+	}
+	shift.males += group.males
+	group.allocated[shift.spec.Index] = true
+}
+
+// outputShifts renders the solved rota in pyallocator's order: volunteers in
+// group input order then member order, then the custom pins.
+func (p *goProblem) outputShifts() []CpsatOutputShift {
+	out := make([]CpsatOutputShift, len(p.shifts))
+	for i, shift := range p.shifts {
+		assignments := []CpsatAssignment{}
+		groupKeys := []string{}
+		for _, group := range p.groups {
+			if !group.allocated[shift.spec.Index] {
+				continue
+			}
+			groupKeys = append(groupKeys, group.key)
+			for _, member := range group.members {
+				assignments = append(assignments, CpsatAssignment{
+					VolunteerID: member.ID,
+					Role:        shift.seated[member.ID],
+				})
+			}
+		}
+		for _, pin := range shift.spec.Preallocations {
+			if pin.Custom != "" {
+				assignments = append(assignments, CpsatAssignment{Custom: pin.Custom, Role: pin.Role})
+			}
+		}
+		out[i] = CpsatOutputShift{
+			Index:              shift.spec.Index,
+			Date:               shift.spec.Date,
+			Closed:             shift.spec.Closed,
+			Assignments:        assignments,
+			AllocatedGroupKeys: groupKeys,
+		}
+	}
+	return out
+}
+
+// emptyShifts is every shift with nobody on it, for an infeasible answer.
+func (p *goProblem) emptyShifts() []CpsatOutputShift {
+	out := make([]CpsatOutputShift, len(p.shifts))
+	for i, shift := range p.shifts {
+		out[i] = CpsatOutputShift{
+			Index:              shift.spec.Index,
+			Date:               shift.spec.Date,
+			Closed:             shift.spec.Closed,
+			Assignments:        []CpsatAssignment{},
+			AllocatedGroupKeys: []string{},
+		}
+	}
+	return out
+}
+
+// constraintsApplied names the rules this run applied: the fundamentals, then
+// the switchable rules it was sent, in the order it was sent them.
+func (p *goProblem) constraintsApplied() []string {
+	applied := append([]string(nil), goFundamentalConstraints...)
+	for _, name := range p.input.EnabledConstraints {
+		if p.enabled[name] {
+			applied = append(applied, name)
+		}
+	}
+	return applied
+}
+
+// priorityBands is even_fill's band per Role: the highest-priority Roles in the
+// highest band, so a scarce Role's Seat is filled before an ordinary one.
+// Banded by the priority rather than by position, so Roles given the same
+// priority are peers.
+func priorityBands(roles []CpsatRole) map[string]int {
+	priorities := make([]int, 0, len(roles))
+	seen := make(map[int]bool)
+	for _, role := range roles {
+		if !seen[role.Priority] {
+			seen[role.Priority] = true
+			priorities = append(priorities, role.Priority)
+		}
+	}
+	sort.Ints(priorities)
+
+	rank := make(map[int]int, len(priorities))
+	for i, priority := range priorities {
+		rank[priority] = i
+	}
+	bands := make(map[string]int, len(roles))
+	for _, role := range roles {
+		bands[role.Name] = (len(priorities) - 1 - rank[role.Priority]) * goPriorityBand
+	}
+	return bands
+}
+
+func holds(member CpsatMember, role string) bool {
+	for _, held := range member.Roles {
+		if held == role {
+			return true
+		}
+	}
+	return false
+}
+
+func groupHasMember(group *goGroup, id string) bool {
+	for _, member := range group.members {
+		if member.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsGroup(groups []*goGroup, group *goGroup) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// monthOf is an ISO date's YYYY-MM.
+func monthOf(date string) string {
+	if len(date) < 7 {
+		return date
+	}
+	return date[:7]
+}
@@ -0,0 +1,466 @@
+package allocator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// goTestRoles are the two Roles S1 ships with: Team lead ahead of Service
+// volunteer.
+var goTestRoles = []CpsatRole{
+	{Name: "Team lead", Priority: 1},
+	{Name: "Service volunteer", Priority: 2},
+}
+
+// goTestInput is a rota of open shifts on the dates given, each asking for one
+// Team lead and two Service volunteers, with nothing switched on.
+func goTestInput(groups []CpsatGroup, dates ...string) *CpsatInput {
+	shifts := make([]CpsatShift, len(dates))
+	for i, date := range dates {
+		shifts[i] = CpsatShift{
+			Index: i,
+			Date:  date,
+			Shape: []CpsatSeat{
+				{Role: "Team lead", Count: 1},
+				{Role: "Service volunteer", Count: 2},
+			},
+			Preallocations: []CpsatPreallocation{},
+		}
+	}
+	return &CpsatInput{
+		MaxAllocationCount: len(dates),
+		Roles:              goTestRoles,
+		Shifts:             shifts,
+		Groups:             groups,
+	}
+}
+
+// individual is a group of one, available on the shifts given.
+func individual(id, gender string, roles []string, available ...int) CpsatGroup {
+	return CpsatGroup{
+		GroupKey:              id,
+		Members:               []CpsatMember{{ID: id, FirstName: id, Gender: gender, Roles: roles}},
+		AvailableShiftIndices: available,
+	}
+}
+
+var (
+	leads    = []string{"Team lead", "Service volunteer"}
+	servers  = []string{"Service volunteer"}
+	everyday = []int{0, 1, 2, 3}
+)
+
+// seatsOf reads a solved shift as "Role:id" entries, for assertions that do
+// not care about order.
+func seatsOf(shift CpsatOutputShift) []string {
+	seats := make([]string, 0, len(shift.Assignments))
+	for _, a := range shift.Assignments {
+		who := a.VolunteerID
+		if who == "" {
+			who = a.Custom
+		}
+		seats = append(seats, a.Role+":"+who)
+	}
+	return seats
+}
+
+func solveGo(t *testing.T, input *CpsatInput) *CpsatOutput {
+	t.Helper()
+	output, err := RunGoAllocator(context.Background(), input, zap.NewNop())
+	require.NoError(t, err)
+	return output
+}
+
+func TestRunGoAllocator_FillsSeatsVolunteersMayFill(t *testing.T) {
+	input := goTestInput([]CpsatGroup{
+		individual("lead", "Female", leads, 0),
+		individual("a", "Female", servers, 0),
+		individual("b", "Female", servers, 0),
+		individual("c", "Female", servers, 0),
+	}, "2026-08-02")
+
+	output := solveGo(t, input)
+
+	require.True(t, output.Success)
+	assert.Equal(t, "FEASIBLE", output.SolverStatus)
+	require.Len(t, output.Shifts, 1)
+	seats := seatsOf(output.Shifts[0])
+	assert.Contains(t, seats, "Team lead:lead", "the one holder of the scarce Role takes its Seat")
+	assert.Len(t, seats, 3, "no Role is oversubscribed")
+}
+
+func TestRunGoAllocator_HonoursAvailabilityAndClosedShifts(t *testing.T) {
+	input := goTestInput([]CpsatGroup{
+		individual("a", "Female", servers, 1),
+		individual("b", "Female", servers, 0, 1, 2),
+	}, "2026-08-02", "2026-08-09", "2026-08-16")
+	input.Shifts[2].Closed = true
+
+	output := solveGo(t, input)
+
+	require.True(t, output.Success)
+	assert.ElementsMatch(t, []string{"Service volunteer:b"}, seatsOf(output.Shifts[0]))
+	assert.ElementsMatch(t, []string{"Service volunteer:a", "Service volunteer:b"}, seatsOf(output.Shifts[1]))
+	assert.Empty(t, output.Shifts[2].Assignments, "nobody works a closed shift")
+}
+
+func TestRunGoAllocator_GroupWorksTogetherOrNotAtAll(t *testing.T) {
+	couple := CpsatGroup{
+		GroupKey: "couple",
+		Members: []CpsatMember{
+			{ID: "x", Roles: servers},
+			{ID: "y", Roles: servers},
+		},
+		AvailableShiftIndices: []int{0},
+	}
+	input := goTestInput([]CpsatGroup{couple, individual("solo", "Female", servers, 0)}, "2026-08-02")
+
+	output := solveGo(t, input)
+
+	seats := seatsOf(output.Shifts[0])
+	assert.Len(t, seats, 2, "two Service volunteer Seats")
+	assert.ElementsMatch(t, []string{"Service volunteer:x", "Service volunteer:y"}, seats,
+		"the couple fills both rather than being split")
+	assert.Equal(t, []string{"couple"}, output.Shifts[0].AllocatedGroupKeys)
+}
+
+func TestRunGoAllocator_PlacesPinsAndTheirGroupMates(t *testing.T) {
+	couple := CpsatGroup{
+		GroupKey: "couple",
+		Members: []CpsatMember{
+			{ID: "x", Roles: servers},
+			{ID: "y", Roles: servers},
+		},
+		AvailableShiftIndices: []int{0},
+	}
+	input := goTestInput([]CpsatGroup{couple, individual("lead", "Female", leads, 0)}, "2026-08-02")
+	input.Shifts[0].Preallocations = []CpsatPreallocation{
+		// Pinned to a Role they do not hold: a pin grants it for the shift.
+		{VolunteerID: "x", Role: "Team lead"},
+		{Custom: "St John's team", Role: "Service volunteer"},
+	}
+
+	output := solveGo(t, input)
+
+	require.True(t, output.Success)
+	assert.ElementsMatch(t, []string{
+		"Team lead:x",
+		"Service volunteer:y",
+		"Service volunteer:St John's team",
+	}, seatsOf(output.Shifts[0]))
+}
+
+func TestRunGoAllocator_SwitchableConstraints(t *testing.T) {
+	dates := []string{"2026-08-02", "2026-08-09", "2026-08-16", "2026-08-23"}
+
+	tests := []struct {
+		name    string
+		enabled []string
+		max     int
+		history []CpsatHistoricalShift
+		assert  func(t *testing.T, worked []int)
+	}{
+		{
+			name:   "nothing switched on works every shift",
+			assert: func(t *testing.T, worked []int) { assert.Equal(t, []int{0, 1, 2, 3}, worked) },
+		},
+		{
+			name:    "max_frequency caps the shifts",
+			enabled: []string{"max_frequency"},
+			max:     2,
+			assert:  func(t *testing.T, worked []int) { assert.Len(t, worked, 2) },
+		},
+		{
+			name:    "no_back_to_back skips neighbours and the previous rota's last shift",
+			enabled: []string{"no_back_to_back"},
+			history: []CpsatHistoricalShift{{Date: "2026-07-26", GroupKeys: []string{"a"}}},
+			assert:  func(t *testing.T, worked []int) { assert.Equal(t, []int{1, 3}, worked) },
+		},
+		{
+			name:    "one_shift_per_month allows one August shift",
+			enabled: []string{"one_shift_per_month"},
+			assert:  func(t *testing.T, worked []int) { assert.Len(t, worked, 1) },
+		},
+		{
+			name:    "one_shift_per_month counts a month already worked",
+			enabled: []string{"one_shift_per_month"},
+			history: []CpsatHistoricalShift{{Date: "2026-08-01", GroupKeys: []string{"a"}}},
+			assert:  func(t *testing.T, worked []int) { assert.Empty(t, worked) },
+		},
+		{
+			name:    "an unknown rule selects nothing",
+			enabled: []string{"withdrawn_rule"},
+			assert:  func(t *testing.T, worked []int) { assert.Len(t, worked, 4) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := goTestInput([]CpsatGroup{individual("a", "Female", servers, everyday...)}, dates...)
+			input.EnabledConstraints = tt.enabled
+			input.HistoricalShifts = tt.history
+			if tt.max > 0 {
+				input.MaxAllocationCount = tt.max
+			}
+
+			output := solveGo(t, input)
+
+			require.True(t, output.Success)
+			worked := []int{}
+			for _, shift := range output.Shifts {
+				if len(shift.Assignments) > 0 {
+					worked = append(worked, shift.Index)
+				}
+			}
+			tt.assert(t, worked)
+		})
+	}
+}
+
+// male_required never fills the last Seat on a shift with no male on it, so one
+// can be added by hand.
+func TestRunGoAllocator_MaleRequiredKeepsASeatOpen(t *testing.T) {
+	input := goTestInput([]CpsatGroup{
+		individual("lead", "Female", leads, 0),
+		individual("a", "Female", servers, 0),
+		individual("b", "Female", servers, 0),
+	}, "2026-08-02")
+	input.EnabledConstraints = []string{"male_required"}
+
+	output := solveGo(t, input)
+
+	require.True(t, output.Success)
+	assert.Len(t, output.Shifts[0].Assignments, 2, "three Seats, one kept open")
+	assert.Contains(t, output.Diagnostics.ConstraintsApplied, "male_required")
+}
+
+// A pin is placed whatever the rules say, so a pin they forbid is a rota they
+// forbid — a well-formed INFEASIBLE answer, as CP-SAT gives.
+func TestRunGoAllocator_PinsARuleForbidsAreInfeasible(t *testing.T) {
+	input := goTestInput([]CpsatGroup{individual("a", "Female", servers, 0, 1)}, "2026-08-02", "2026-08-09")
+	input.EnabledConstraints = []string{"no_back_to_back"}
+	input.Shifts[0].Preallocations = []CpsatPreallocation{{VolunteerID: "a", Role: "Service volunteer"}}
+	input.Shifts[1].Preallocations = []CpsatPreallocation{{VolunteerID: "a", Role: "Service volunteer"}}
+
+	output := solveGo(t, input)
+
+	assert.False(t, output.Success)
+	assert.Equal(t, "INFEASIBLE", output.SolverStatus)
+	for _, shift := range output.Shifts {
+		assert.Empty(t, shift.Assignments, "an infeasible answer staffs nothing")
+	}
+}
+
+func TestRunGoAllocator_RejectsInputItCannotReadAsAProblem(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(input *CpsatInput)
+		want string
+	}{
+		{
+			name: "pin naming nobody",
+			edit: func(input *CpsatInput) {
+				input.Shifts[0].Preallocations = []CpsatPreallocation{{VolunteerID: "ghost", Role: "Team lead"}}
+			},
+			want: "does not match any volunteer",
+		},
+		{
+			name: "pin to a Role the shift has no Seat for",
+			edit: func(input *CpsatInput) {
+				input.Shifts[0].Shape = []CpsatSeat{{Role: "Service volunteer", Count: 2}}
+				input.Shifts[0].Preallocations = []CpsatPreallocation{{VolunteerID: "a", Role: "Team lead"}}
+			},
+			want: "no Seat for",
+		},
+		{
+			name: "pair rule naming nobody",
+			edit: func(input *CpsatInput) {
+				input.PairRules = []CpsatPairRule{{VolunteerA: "a", VolunteerB: "ghost", Kind: PairApart}}
+			},
+			want: "does not match any volunteer",
+		},
+		{
+			name: "pair rule naming one volunteer twice",
+			edit: func(input *CpsatInput) {
+				input.PairRules = []CpsatPairRule{{VolunteerA: "a", VolunteerB: "a", Kind: PairApart}}
+			},
+			want: "names volunteer 'a' twice",
+		},
+		{
+			name: "pair rule of an unknown kind",
+			edit: func(input *CpsatInput) {
+				input.Groups = append(input.Groups, individual("b", "Female", servers, 0))
+				input.PairRules = []CpsatPairRule{{VolunteerA: "a", VolunteerB: "b", Kind: "sometimes"}}
+			},
+			want: "unknown kind 'sometimes'",
+		},
+		{
+			name: "pin on a closed shift",
+			edit: func(input *CpsatInput) {
+				input.Shifts[0].Closed = true
+				input.Shifts[0].Preallocations = []CpsatPreallocation{{VolunteerID: "a", Role: "Service volunteer"}}
+			},
+			want: "closed but has preallocations",
+		},
+		{
+			name: "historical shift weighing more than a shift",
+			edit: func(input *CpsatInput) {
+				input.HistoricalShifts = []CpsatHistoricalShift{{Date: "2026-07-26", GroupKeys: []string{"a"}, Weight: 2}}
+			},
+			want: "outside (0, 1]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := goTestInput([]CpsatGroup{individual("a", "Female", servers, 0)}, "2026-08-02")
+			tt.edit(input)
+
+			_, err := RunGoAllocator(context.Background(), input, zap.NewNop())
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+// Allocating confirms a draft by the hash of its output (ADR 0008), so the same
+// input must give the same rota every time.
+func TestRunGoAllocator_IsDeterministic(t *testing.T) {
+	groups := []CpsatGroup{
+		individual("lead", "Male", leads, everyday...),
+		individual("a", "Female", servers, everyday...),
+		individual("b", "Male", servers, 0, 2),
+		individual("c", "Female", servers, 1, 3),
+		individual("d", "Female", leads, 0, 1),
+	}
+	input := goTestInput(groups, "2026-08-02", "2026-08-09", "2026-08-16", "2026-08-23")
+
+	first := solveGo(t, input)
+	for range 5 {
+		again := solveGo(t, input)
+		assert.Equal(t, first.Shifts, again.Shifts)
+	}
+}
+
+// Fairness reaches for whoever has worked least.
+func TestRunGoAllocator_PrefersTheLessWorked(t *testing.T) {
+	busy := individual("busy", "Female", servers, 0)
+	busy.HistoricalAllocationCount = 6
+	fresh := individual("fresh", "Female", servers, 0)
+	input := goTestInput([]CpsatGroup{busy, fresh}, "2026-08-02")
+	input.Shifts[0].Shape = []CpsatSeat{{Role: "Service volunteer", Count: 1}}
+
+	output := solveGo(t, input)
+
+	assert.Equal(t, []string{"Service volunteer:fresh"}, seatsOf(output.Shifts[0]))
+}
+
+// Older history counts for less once the horizon decays it: three shifts from
+// long ago can weigh less than one from the previous rota.
+func TestRunGoAllocator_WeighsDecayedHistoryLess(t *testing.T) {
+	longAgo := individual("longago", "Female", servers, 0)
+	longAgo.HistoricalAllocationCount = 3
+	recent := individual("recent", "Female", servers, 0)
+	recent.HistoricalAllocationCount = 1
+	input := goTestInput([]CpsatGroup{longAgo, recent}, "2026-08-02")
+	input.Shifts[0].Shape = []CpsatSeat{{Role: "Service volunteer", Count: 1}}
+	input.HistoricalShifts = []CpsatHistoricalShift{
+		{Date: "2026-03-01", GroupKeys: []string{"longago"}, Weight: 0.1},
+		{Date: "2026-03-08", GroupKeys: []string{"longago"}, Weight: 0.1},
+		{Date: "2026-03-15", GroupKeys: []string{"longago"}, Weight: 0.1},
+		{Date: "2026-07-26", GroupKeys: []string{"recent"}, Weight: 1},
+	}
+
+	output := solveGo(t, input)
+	assert.Equal(t, []string{"Service volunteer:longago"}, seatsOf(output.Shifts[0]))
+
+	// Counted in full, the same history makes longago the busier of the two.
+	for i := range input.HistoricalShifts {
+		input.HistoricalShifts[i].Weight = 1
+	}
+	output = solveGo(t, input)
+	assert.Equal(t, []string{"Service volunteer:recent"}, seatsOf(output.Shifts[0]))
+}
+
+// A preferred count steers the next shift to somebody else where somebody else
+// can take it, and is not a cap where nobody can: the first Seat of a shift is
+// worth more than the preference costs.
+func TestRunGoAllocator_HonoursAPreferredShiftCount(t *testing.T) {
+	choosy := individual("choosy", "Female", servers, 0, 1)
+	choosy.PreferredShiftCount = 1
+	// Worked plenty already, so fairness alone would hand shift 1 to choosy.
+	veteran := individual("veteran", "Female", servers, 1)
+	veteran.HistoricalAllocationCount = 5
+	input := goTestInput([]CpsatGroup{choosy, veteran}, "2026-08-02", "2026-08-09")
+	for i := range input.Shifts {
+		input.Shifts[i].Shape = []CpsatSeat{{Role: "Service volunteer", Count: 1}}
+	}
+
+	output := solveGo(t, input)
+	assert.Equal(t, []string{"Service volunteer:choosy"}, seatsOf(output.Shifts[0]))
+	assert.Equal(t, []string{"Service volunteer:veteran"}, seatsOf(output.Shifts[1]))
+
+	// With nobody else to take it, choosy still works the second shift.
+	input.Groups = []CpsatGroup{choosy}
+	output = solveGo(t, input)
+	assert.Equal(t, []string{"Service volunteer:choosy"}, seatsOf(output.Shifts[1]))
+}
+
+// An "apart" rule is never broken, even where keeping the two apart leaves a
+// Seat empty that either could fill.
+func TestRunGoAllocator_KeepsAPairApart(t *testing.T) {
+	input := goTestInput([]CpsatGroup{
+		individual("ann", "Female", servers, 0, 1),
+		individual("bea", "Female", servers, 0),
+	}, "2026-08-02", "2026-08-09")
+	input.PairRules = []CpsatPairRule{{VolunteerA: "ann", VolunteerB: "bea", Kind: PairApart}}
+
+	output := solveGo(t, input)
+
+	require.True(t, output.Success)
+	assert.NotSubset(t, output.Shifts[0].AllocatedGroupKeys, []string{"ann", "bea"})
+	assert.Contains(t, output.Diagnostics.ConstraintsApplied, "never_together")
+
+	// Two members of one group kept apart means the group cannot work at all.
+	input = goTestInput([]CpsatGroup{{
+		GroupKey:              "family",
+		Members:               []CpsatMember{{ID: "mum", Roles: servers}, {ID: "dad", Roles: servers}},
+		AvailableShiftIndices: []int{0},
+	}}, "2026-08-02")
+	input.PairRules = []CpsatPairRule{{VolunteerA: "dad", VolunteerB: "mum", Kind: PairApart}}
+
+	output = solveGo(t, input)
+
+	require.True(t, output.Success)
+	assert.Empty(t, output.Shifts[0].Assignments)
+}
+
+// A "together" rule steers the second of the two onto the first one's shift,
+// over the shift spread_males alone would have sent them to.
+func TestRunGoAllocator_PutsAPairOnTogether(t *testing.T) {
+	input := goTestInput([]CpsatGroup{
+		individual("ann", "Male", servers, 0),
+		individual("bea", "Male", servers, 0, 1),
+	}, "2026-08-02", "2026-08-09")
+	for i := range input.Shifts {
+		input.Shifts[i].Shape = []CpsatSeat{{Role: "Service volunteer", Count: 2}}
+	}
+	// The same Seat is left on either shift, so only the males and the rule
+	// tell them apart.
+	input.Shifts[1].Preallocations = []CpsatPreallocation{{Custom: "Scouts", Role: "Service volunteer"}}
+	input.MaxAllocationCount = 1
+	input.EnabledConstraints = []string{"max_frequency"}
+
+	output := solveGo(t, input)
+	assert.Equal(t, []string{"ann"}, output.Shifts[0].AllocatedGroupKeys)
+	assert.Equal(t, []string{"bea"}, output.Shifts[1].AllocatedGroupKeys)
+
+	input.PairRules = []CpsatPairRule{{VolunteerA: "ann", VolunteerB: "bea", Kind: PairTogether}}
+	output = solveGo(t, input)
+	assert.Equal(t, []string{"ann", "bea"}, output.Shifts[0].AllocatedGroupKeys)
+}
@@ -129,6 +129,12 @@ type Shift struct {
 	// it fills. Volunteers, custom entries and leads all travel together: they
 	// are the same thing, a Seat spoken for before the solve.
 	Preallocations []Preallocation
+
+	// HistoryWeight is how much a *historical* shift counts towards fairness:
+	// 1 for the previous rota, less for older ones when the Allocation
+	// Settings decay them. Zero reads as 1, so history built without a
+	// weight counts the way it always did.
+	HistoryWeight float64
 }
 
 // IsAvailable returns true if the group is available for the given shift
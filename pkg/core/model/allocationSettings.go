@@ -1,6 +1,9 @@
 package model
 
-import "slices"
+import (
+	"math"
+	"slices"
+)
 
 // SwitchableConstraint is one optional allocator rule an admin can switch on.
 //
@@ -85,8 +88,44 @@ type AllocationSettings struct {
 	// its own field here — a change to the document, which is a change to no
 	// schema at all.
 	MaxFrequency float64 `json:"maxFrequency,omitempty"`
+
+	// FairnessRotas and FairnessMonths are how far back the fairness
+	// preference looks when it asks who has worked a lot lately: the last so
+	// many rotas, or every rota with a shift in the last so many months. At
+	// most one is set, and neither means the previous rota alone — which is
+	// what fairness read before either existed, so a deployment that has
+	// never answered them allocates exactly as it always did.
+	//
+	// Months are offered alongside rotas because a rota is not a fixed
+	// length: "the last three rotas" is six weeks at one drop-in and six
+	// months at another.
+	FairnessRotas  int `json:"fairnessRotas,omitempty"`
+	FairnessMonths int `json:"fairnessMonths,omitempty"`
+	// FairnessDecay is how much less a shift counts for each rota further
+	// back than the previous one: a shift two rotas back counts FairnessDecay
+	// of one in the previous rota, three back FairnessDecay squared. Between
+	// 0 and 1; zero when unset, which reads as no decay at all.
+	FairnessDecay float64 `json:"fairnessDecay,omitempty"`
+	// FairnessSkipsNoShows leaves a shift somebody was recorded as not
+	// turning up to out of their history: it was not a shift they worked, so
+	// it neither counts towards how much they have done lately nor makes the
+	// next rota's first shift back-to-back with it. Off unless an admin says,
+	// because a deployment that records no attendance has nothing for it to
+	// read and one that has only just started would see history change under
+	// it.
+	FairnessSkipsNoShows bool `json:"fairnessSkipsNoShows,omitempty"`
 }
 
+// The furthest back the fairness horizon reaches. History is read on every
+// solve and each rota of it is a round trip, so the ceiling is about keeping a
+// solve quick rather than about fairness — a year of rotas at a fortnightly
+// drop-in, or two years of months, is already further back than anybody
+// remembers who worked what.
+const (
+	MaxFairnessRotas  = 12
+	MaxFairnessMonths = 24
+)
+
 // MaxFrequencyConstraint is the one switchable rule that carries a value as
 // well as a switch, named here so the places that special-case it say which
 // rule they mean.
@@ -175,3 +214,37 @@ func (s AllocationSettings) MaxAllocationCount(shiftCount int) int {
 func validFrequency(frequency float64) bool {
 	return frequency > 0 && frequency <= 1
 }
+
+// HistoryRotas is how many rotas before the one being allocated fairness looks
+// back over, when the horizon is counted in rotas: the admin's answer, or the
+// previous rota alone when they have not given one. It is zero when the
+// horizon is counted in months instead — see HistoryMonths.
+func (s AllocationSettings) HistoryRotas() int {
+	if s.FairnessMonths > 0 {
+		return 0
+	}
+	if s.FairnessRotas < 1 {
+		return 1
+	}
+	return min(s.FairnessRotas, MaxFairnessRotas)
+}
+
+// HistoryMonths is how many months before the rota being allocated fairness
+// looks back over, or zero when the horizon is counted in rotas.
+func (s AllocationSettings) HistoryMonths() int {
+	return min(max(s.FairnessMonths, 0), MaxFairnessMonths)
+}
+
+// HistoryWeight is how much a past shift counts towards fairness, by how many
+// rotas further back than the previous one it sits: 1 for the previous rota,
+// and FairnessDecay once more for each rota before it.
+//
+// A decay an admin could not have meant — unset, or outside (0, 1] — reads as
+// none, so every shift in the horizon counts in full, as every shift in the
+// previous rota always did.
+func (s AllocationSettings) HistoryWeight(age int) float64 {
+	if s.FairnessDecay <= 0 || s.FairnessDecay > 1 || age <= 0 {
+		return 1
+	}
+	return math.Pow(s.FairnessDecay, float64(age))
+}
@@ -127,3 +127,50 @@ func TestMaxAllocationCountNeverFallsToZero(t *testing.T) {
 
 	require.Equal(t, 1, settings.MaxAllocationCount(4))
 }
+
+// Settings that say nothing about the fairness horizon look back at the
+// previous rota alone, at full weight — what fairness read before there was a
+// horizon to set, so an upgrade changes nobody's rota.
+func TestUnansweredFairnessHorizonIsThePreviousRota(t *testing.T) {
+	var settings model.AllocationSettings
+
+	assert.Equal(t, 1, settings.HistoryRotas())
+	assert.Zero(t, settings.HistoryMonths())
+	assert.Equal(t, 1.0, settings.HistoryWeight(0))
+	assert.Equal(t, 1.0, settings.HistoryWeight(3))
+}
+
+func TestFairnessHorizon(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings model.AllocationSettings
+		rotas    int
+		months   int
+	}{
+		{name: "in rotas", settings: model.AllocationSettings{FairnessRotas: 3}, rotas: 3},
+		{name: "in months", settings: model.AllocationSettings{FairnessMonths: 6}, months: 6},
+		{name: "months win over rotas", settings: model.AllocationSettings{FairnessRotas: 3, FairnessMonths: 6}, months: 6},
+		{name: "rotas past the ceiling", settings: model.AllocationSettings{FairnessRotas: 50}, rotas: model.MaxFairnessRotas},
+		{name: "months past the ceiling", settings: model.AllocationSettings{FairnessMonths: 50}, months: model.MaxFairnessMonths},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.rotas, tt.settings.HistoryRotas())
+			assert.Equal(t, tt.months, tt.settings.HistoryMonths())
+		})
+	}
+}
+
+// Each rota further back counts the decay once more. A decay nobody could have
+// meant counts every shift in full rather than none of them.
+func TestHistoryWeight(t *testing.T) {
+	settings := model.AllocationSettings{FairnessDecay: 0.5}
+
+	assert.Equal(t, 1.0, settings.HistoryWeight(0))
+	assert.Equal(t, 0.5, settings.HistoryWeight(1))
+	assert.Equal(t, 0.25, settings.HistoryWeight(2))
+
+	assert.Equal(t, 1.0, model.AllocationSettings{FairnessDecay: 1.5}.HistoryWeight(2))
+	assert.Equal(t, 1.0, model.AllocationSettings{FairnessDecay: -0.5}.HistoryWeight(2))
+}
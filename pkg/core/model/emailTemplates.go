@@ -0,0 +1,369 @@
+package model
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+)
+
+// The emails an admin can reword, by the name each is stored under. A kind is
+// which email it is, not what it says: the words are the template, and a kind
+// nobody has reworded reads with the default below.
+const (
+	EmailTemplateRound        = "round"
+	EmailTemplateReminder     = "reminder"
+	EmailTemplateAllocation   = "allocation"
+	EmailTemplateCover        = "cover"
+	EmailTemplateCoverRequest = "coverRequest"
+	EmailTemplateSwapOffer    = "swapOffer"
+)
+
+// EmailTemplate is one email's wording: a subject, a plain-text body and,
+// optionally, an HTML body sent alongside it. All three are Go templates over
+// EmailTemplateData — "{{.FirstName}}", "{{.Link}}" — the text ones as
+// text/template, the HTML one as html/template so a volunteer's name cannot
+// write markup into it.
+//
+// The text body is required and the HTML one is not. Text is what every mail
+// client can show, and the part a volunteer who has switched HTML off reads, so
+// an email is never without it; an empty HTML body sends the text alone.
+type EmailTemplate struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// EmailTemplateKind is one email an admin can reword, as the settings screen
+// names it. The list below is the authority on which there are, the way
+// SwitchableConstraints is for the allocator's rules: a kind arriving is a line
+// here, and the screen draws whatever it is sent.
+type EmailTemplateKind struct {
+	Name        string
+	Label       string
+	Description string
+	// RequiresLink is set on an email whose whole point is the volunteer's
+	// link, so wording that leaves it out is refused rather than sent.
+	RequiresLink bool
+}
+
+// EmailTemplateKinds is every email an admin can reword, in the order the
+// settings screen lists them.
+var EmailTemplateKinds = []EmailTemplateKind{
+	{
+		Name:         EmailTemplateRound,
+		Label:        "Availability request",
+		Description:  "Sent when a round goes out, and when one volunteer's link is resent: their link to say which shifts they can do.",
+		RequiresLink: true,
+	},
+	{
+		Name:         EmailTemplateReminder,
+		Label:        "Availability reminder",
+		Description:  "Sent to volunteers who were asked and have not answered, and whose group has not answered for them.",
+		RequiresLink: true,
+	},
+	{
+		Name:        EmailTemplateAllocation,
+		Label:       "Allocated shifts",
+		Description: "Sent once a rota is allocated, to everyone on it: the shifts they have been given, and their calendar link.",
+		// Nothing to answer, so no availability link to insist on. The shifts
+		// are the point, and the calendar link is a convenience.
+	},
+	{
+		Name:        EmailTemplateCover,
+		Label:       "Rota change",
+		Description: "Sent, when the admin making a change asks for it, to the volunteers it moves: the shifts they have been put on and taken off.",
+	},
+	{
+		Name:         EmailTemplateCoverRequest,
+		Label:        "Cover request",
+		Description:  "Sent when an admin asks for cover on an allocated rota, to everyone holding the Role: the shift, and their link to say whether they can do it.",
+		RequiresLink: true,
+	},
+	{
+		Name:         EmailTemplateSwapOffer,
+		Label:        "Swap offer",
+		Description:  "Sent, when an admin asks for it, to everyone who could take a shift a volunteer has offered up: the shift, who is giving it up, and their link to take it.",
+		RequiresLink: true,
+	},
+}
+
+// FindEmailTemplateKind looks up an email there is a template for by name.
+func FindEmailTemplateKind(name string) (EmailTemplateKind, bool) {
+	for _, kind := range EmailTemplateKinds {
+		if kind.Name == name {
+			return kind, true
+		}
+	}
+	return EmailTemplateKind{}, false
+}
+
+// EmailTemplateData is what a template can say. Every field is ready to print:
+// the dates are already written the way a sentence wants them, so a template
+// never has to format one.
+type EmailTemplateData struct {
+	// FirstName is the volunteer's, as the roster has it.
+	FirstName string
+	// Link is the volunteer's own availability page. It is their identity — a
+	// template that leaves it out sends an email nobody can act on. Empty in
+	// the allocation email, which is sent once the links have closed; in the
+	// cover request email it is their link to answer that request, and in the
+	// swap offer email their swap page.
+	Link string
+	// Deadline is the admin's words for when answers are wanted by, quoted as
+	// given (ADR 0004).
+	Deadline string
+	// ShiftDates is every date the rota runs, closures left out, e.g.
+	// "Sunday 2 August".
+	ShiftDates []string
+	// RotaStart and RotaEnd are the first and last dates the rota spans, e.g.
+	// "2 August 2026".
+	RotaStart string
+	RotaEnd   string
+	// Shifts is what the volunteer has been given, in date order. Only the
+	// allocation email fills it in: before allocation nobody has any.
+	Shifts []EmailTemplateShift
+	// CalendarLink is the volunteer's calendar feed, for the allocation and
+	// rota change emails.
+	CalendarLink string
+	// Added and Removed are the shifts one rota change put the volunteer on
+	// and took them off, for the rota change email. A swap fills in both.
+	Added   []EmailTemplateShift
+	Removed []EmailTemplateShift
+	// CoverShift is the shift a cover request asks the volunteer to cover, for
+	// the cover request email.
+	CoverShift EmailTemplateShift
+	// SwapShift is the shift a swap offer puts on offer, in the Role whoever
+	// takes it takes, and SwapFrom the first name of the volunteer giving it
+	// up, for the swap offer email.
+	SwapShift EmailTemplateShift
+	SwapFrom  string
+}
+
+// EmailTemplateShift is one shift a volunteer has been given, ready to print.
+type EmailTemplateShift struct {
+	// Date is written the way ShiftDates are, e.g. "Sunday 2 August".
+	Date string
+	// Times is when it runs, e.g. "18:30–21:00". Empty when nobody has said.
+	Times string
+	// Role is what they are doing on it. Empty for a Role the app no longer
+	// recognises, which is better left unsaid than named wrongly.
+	Role string
+}
+
+// EmailTemplateVariable is one thing a template can say, as the settings
+// screen lists it beside the editor.
+type EmailTemplateVariable struct {
+	// Name is how it is written in a template, braces and all.
+	Name        string
+	Description string
+}
+
+// EmailTemplateVariables is every field of EmailTemplateData, spelled the way
+// a template uses it. Kept beside the struct so the two are edited together.
+var EmailTemplateVariables = []EmailTemplateVariable{
+	{Name: "{{.FirstName}}", Description: "The volunteer's first name"},
+	{Name: "{{.Link}}", Description: "The volunteer's own availability link, or in the cover request email their link to answer it, or in the swap offer email their swap page"},
+	{Name: "{{.Deadline}}", Description: "The deadline typed when sending"},
+	{Name: "{{join .ShiftDates \", \"}}", Description: "Every date the rota runs, e.g. Sunday 2 August, Sunday 9 August"},
+	{Name: "{{range .ShiftDates}}…{{.}}…{{end}}", Description: "The same dates one at a time, to put each on its own line"},
+	{Name: "{{.RotaStart}}", Description: "The first date the rota spans, e.g. 2 August 2026"},
+	{Name: "{{.RotaEnd}}", Description: "The last date the rota spans"},
+	{Name: "{{range .Shifts}}…{{.Date}} {{.Times}} {{.Role}}…{{end}}", Description: "Allocated shifts email only: each shift the volunteer has been given, its times and their Role"},
+	{Name: "{{.CalendarLink}}", Description: "Allocated shifts and rota change emails: the volunteer's calendar feed, to subscribe to"},
+	{Name: "{{range .Added}}…{{.Date}} {{.Times}} {{.Role}}…{{end}}", Description: "Rota change email only: each shift the change put the volunteer on"},
+	{Name: "{{range .Removed}}…{{.Date}}…{{end}}", Description: "Rota change email only: each shift the change took the volunteer off"},
+	{Name: "{{.CoverShift.Date}} {{.CoverShift.Times}} {{.CoverShift.Role}}", Description: "Cover request email only: the shift cover is wanted for, its times and the Role"},
+	{Name: "{{.SwapShift.Date}} {{.SwapShift.Times}} {{.SwapShift.Role}}", Description: "Swap offer email only: the shift on offer, its times and the Role"},
+	{Name: "{{.SwapFrom}}", Description: "Swap offer email only: the first name of the volunteer giving the shift up"},
+}
+
+// ExampleEmailTemplateData is a volunteer and a rota that do not exist, for
+// checking a template works before anyone receives it. Every field is filled,
+// so a template that uses one cannot pass by finding it empty.
+var ExampleEmailTemplateData = EmailTemplateData{
+	FirstName:  "Sam",
+	Link:       "https://drop-in.example/availability/example",
+	Deadline:   "Friday 7 August",
+	ShiftDates: []string{"Sunday 2 August", "Sunday 9 August"},
+	RotaStart:  "2 August 2026",
+	RotaEnd:    "9 August 2026",
+	Shifts: []EmailTemplateShift{
+		{Date: "Sunday 2 August", Times: "18:30–21:00", Role: "Team Lead"},
+		{Date: "Sunday 9 August", Times: "18:30–21:00", Role: "Volunteer"},
+	},
+	CalendarLink: "https://drop-in.example/calendars/example.ics",
+	Added:        []EmailTemplateShift{{Date: "Sunday 16 August", Times: "18:30–21:00", Role: "Volunteer"}},
+	Removed:      []EmailTemplateShift{{Date: "Sunday 9 August", Times: "18:30–21:00"}},
+	CoverShift:   EmailTemplateShift{Date: "Sunday 9 August", Times: "18:30–21:00", Role: "Volunteer"},
+	SwapShift:    EmailTemplateShift{Date: "Sunday 9 August", Times: "18:30–21:00", Role: "Volunteer"},
+	SwapFrom:     "Alex",
+}
+
+// DefaultEmailTemplates is what each email says until an admin rewords it.
+// The text bodies are the wording the drop-in has always sent.
+var DefaultEmailTemplates = map[string]EmailTemplate{
+	EmailTemplateRound: {
+		Subject: "Ilford drop-in availability (please complete by {{.Deadline}})",
+		Text: "Hey {{.FirstName}}\n\nPlease use this link to let us know your availability.\n{{.Link}}\n\n" +
+			"Deadline for responses is {{.Deadline}} when we will create the rota.\n" +
+			"You can change your response as many times as you like before the deadline.\n\n" +
+			"Thanks\nThe Ilford drop-in team\n",
+		HTML: "<p>Hey {{.FirstName}}</p>\n" +
+			"<p>Please use this link to let us know your availability for {{.RotaStart}} to {{.RotaEnd}}.<br>\n" +
+			"<a href=\"{{.Link}}\">Give your availability</a></p>\n" +
+			"<p>Deadline for responses is {{.Deadline}} when we will create the rota.<br>\n" +
+			"You can change your response as many times as you like before the deadline.</p>\n" +
+			"<p>Thanks<br>\nThe Ilford drop-in team</p>\n",
+	},
+	EmailTemplateReminder: {
+		Subject: "Reminder: Ilford drop-in availability (please complete by {{.Deadline}})",
+		Text: "Hey {{.FirstName}}\n\nThis is a reminder to please let us know your availability.\n{{.Link}}\n\n" +
+			"Deadline for responses is {{.Deadline}} when we will create the rota.\n" +
+			"You can change your response as many times as you like before the deadline.\n\n" +
+			"Thanks\nThe Ilford drop-in team\n",
+		HTML: "<p>Hey {{.FirstName}}</p>\n" +
+			"<p>This is a reminder to please let us know your availability for {{.RotaStart}} to {{.RotaEnd}}.<br>\n" +
+			"<a href=\"{{.Link}}\">Give your availability</a></p>\n" +
+			"<p>Deadline for responses is {{.Deadline}} when we will create the rota.<br>\n" +
+			"You can change your response as many times as you like before the deadline.</p>\n" +
+			"<p>Thanks<br>\nThe Ilford drop-in team</p>\n",
+	},
+	EmailTemplateAllocation: {
+		Subject: "Your Ilford drop-in shifts, {{.RotaStart}} to {{.RotaEnd}}",
+		Text: "Hey {{.FirstName}}\n\nThe rota for {{.RotaStart}} to {{.RotaEnd}} is out. You are on:\n" +
+			"{{range .Shifts}}\n- {{.Date}}{{if .Times}}, {{.Times}}{{end}}{{if .Role}} ({{.Role}}){{end}}{{end}}\n\n" +
+			"To have your shifts in your own calendar, and kept up to date if anything changes, subscribe to:\n{{.CalendarLink}}\n\n" +
+			"If you can no longer make one, please let us know as soon as you can.\n\n" +
+			"Thanks\nThe Ilford drop-in team\n",
+		HTML: "<p>Hey {{.FirstName}}</p>\n" +
+			"<p>The rota for {{.RotaStart}} to {{.RotaEnd}} is out. You are on:</p>\n" +
+			"<ul>\n{{range .Shifts}}<li>{{.Date}}{{if .Times}}, {{.Times}}{{end}}{{if .Role}} ({{.Role}}){{end}}</li>\n{{end}}</ul>\n" +
+			"<p>To have your shifts in your own calendar, and kept up to date if anything changes, " +
+			"<a href=\"{{.CalendarLink}}\">subscribe to your calendar</a>.</p>\n" +
+			"<p>If you can no longer make one, please let us know as soon as you can.</p>\n" +
+			"<p>Thanks<br>\nThe Ilford drop-in team</p>\n",
+	},
+	EmailTemplateCover: {
+		Subject: "A change to your Ilford drop-in shifts",
+		Text: "Hey {{.FirstName}}\n\nThe rota has changed.\n" +
+			"{{if .Added}}\nYou are now on:{{range .Added}}\n- {{.Date}}{{if .Times}}, {{.Times}}{{end}}{{if .Role}} ({{.Role}}){{end}}{{end}}\n{{end}}" +
+			"{{if .Removed}}\nYou are no longer on:{{range .Removed}}\n- {{.Date}}{{end}}\n{{end}}" +
+			"\nYour calendar link has the rota as it now stands:\n{{.CalendarLink}}\n\n" +
+			"If this is not what you expected, please let us know.\n\n" +
+			"Thanks\nThe Ilford drop-in team\n",
+		HTML: "<p>Hey {{.FirstName}}</p>\n" +
+			"<p>The rota has changed.</p>\n" +
+			"{{if .Added}}<p>You are now on:</p>\n<ul>\n{{range .Added}}<li>{{.Date}}{{if .Times}}, {{.Times}}{{end}}{{if .Role}} ({{.Role}}){{end}}</li>\n{{end}}</ul>\n{{end}}" +
+			"{{if .Removed}}<p>You are no longer on:</p>\n<ul>\n{{range .Removed}}<li>{{.Date}}</li>\n{{end}}</ul>\n{{end}}" +
+			"<p><a href=\"{{.CalendarLink}}\">Your calendar</a> has the rota as it now stands.</p>\n" +
+			"<p>If this is not what you expected, please let us know.</p>\n" +
+			"<p>Thanks<br>\nThe Ilford drop-in team</p>\n",
+	},
+	EmailTemplateCoverRequest: {
+		Subject: "Can you cover the Ilford drop-in on {{.CoverShift.Date}}?",
+		Text: "Hey {{.FirstName}}\n\nWe are short on {{.CoverShift.Date}}{{if .CoverShift.Times}}, {{.CoverShift.Times}}{{end}}" +
+			"{{if .CoverShift.Role}} and need a {{.CoverShift.Role}}{{end}}. Could you do it?\n\n" +
+			"Please use this link to say yes or no:\n{{.Link}}\n\n" +
+			"Saying yes does not put you on the rota: we will let you know if we do.\n\n" +
+			"Thanks\nThe Ilford drop-in team\n",
+		HTML: "<p>Hey {{.FirstName}}</p>\n" +
+			"<p>We are short on {{.CoverShift.Date}}{{if .CoverShift.Times}}, {{.CoverShift.Times}}{{end}}" +
+			"{{if .CoverShift.Role}} and need a {{.CoverShift.Role}}{{end}}. Could you do it?</p>\n" +
+			"<p><a href=\"{{.Link}}\">Say whether you can</a></p>\n" +
+			"<p>Saying yes does not put you on the rota: we will let you know if we do.</p>\n" +
+			"<p>Thanks<br>\nThe Ilford drop-in team</p>\n",
+	},
+	EmailTemplateSwapOffer: {
+		Subject: "A shift on {{.SwapShift.Date}} is up for grabs",
+		Text: "Hey {{.FirstName}}\n\n{{.SwapFrom}} can no longer make {{.SwapShift.Date}}{{if .SwapShift.Times}}, {{.SwapShift.Times}}{{end}}" +
+			"{{if .SwapShift.Role}} as {{.SwapShift.Role}}{{end}}, and you said you were free that day. Could you take it?\n\n" +
+			"If you can, take it here:\n{{.Link}}\n\n" +
+			"The first person to take it gets it, so it may have gone by the time you look.\n\n" +
+			"Thanks\nThe Ilford drop-in team\n",
+		HTML: "<p>Hey {{.FirstName}}</p>\n" +
+			"<p>{{.SwapFrom}} can no longer make {{.SwapShift.Date}}{{if .SwapShift.Times}}, {{.SwapShift.Times}}{{end}}" +
+			"{{if .SwapShift.Role}} as {{.SwapShift.Role}}{{end}}, and you said you were free that day. Could you take it?</p>\n" +
+			"<p><a href=\"{{.Link}}\">Take the shift</a></p>\n" +
+			"<p>The first person to take it gets it, so it may have gone by the time you look.</p>\n" +
+			"<p>Thanks<br>\nThe Ilford drop-in team</p>\n",
+	},
+}
+
+// EmailTemplates is the wording an admin has saved, keyed by kind. A kind
+// missing from it has not been reworded.
+type EmailTemplates map[string]EmailTemplate
+
+// For is the wording a kind is sent with — the admin's when they have saved
+// one, the default otherwise — and whether it is the admin's.
+func (t EmailTemplates) For(kind string) (EmailTemplate, bool) {
+	if saved, ok := t[kind]; ok {
+		return saved, true
+	}
+	return DefaultEmailTemplates[kind], false
+}
+
+// RenderedEmail is a template filled in: what one volunteer is sent.
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// templateFuncs is the little a template can do beyond printing a field.
+// join is here because a list of dates in a sentence is the one thing every
+// reworded email wants, and range cannot put commas between them.
+var templateFuncs = map[string]any{
+	"join": strings.Join,
+}
+
+// Render fills the template in for one volunteer.
+//
+// A template that names a field there is not, or is not a template at all,
+// is an error rather than an email with a hole in it. Saving one checks this
+// against ExampleEmailTemplateData, so a send only meets it for a template
+// that got into the database some other way.
+func (t EmailTemplate) Render(data EmailTemplateData) (RenderedEmail, error) {
+	subject, err := renderText("subject", t.Subject, data)
+	if err != nil {
+		return RenderedEmail{}, err
+	}
+	text, err := renderText("text body", t.Text, data)
+	if err != nil {
+		return RenderedEmail{}, err
+	}
+
+	var html string
+	if strings.TrimSpace(t.HTML) != "" {
+		parsed, err := htmltemplate.New("html").Funcs(templateFuncs).Parse(t.HTML)
+		if err != nil {
+			return RenderedEmail{}, fmt.Errorf("the HTML body is not a template this app can read: %w", err)
+		}
+		var out bytes.Buffer
+		if err := parsed.Execute(&out, data); err != nil {
+			return RenderedEmail{}, fmt.Errorf("the HTML body could not be filled in: %w", err)
+		}
+		html = out.String()
+	}
+
+	// A subject is one line whatever the template made of it: a line break in
+	// a header ends it.
+	return RenderedEmail{
+		Subject: strings.Join(strings.Fields(subject), " "),
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
+
+func renderText(which, source string, data EmailTemplateData) (string, error) {
+	parsed, err := texttemplate.New(which).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("the %s is not a template this app can read: %w", which, err)
+	}
+	var out bytes.Buffer
+	if err := parsed.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("the %s could not be filled in: %w", which, err)
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,45 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+)
+
+// TestRenderEscapesTheHTMLPartOnly: a name is data, not markup, in the HTML
+// part — and in the text part it is printed exactly as the roster has it.
+func TestRenderEscapesTheHTMLPartOnly(t *testing.T) {
+	template := model.EmailTemplate{
+		Subject: "Hi {{.FirstName}}",
+		Text:    "Hi {{.FirstName}}",
+		HTML:    "<p>Hi {{.FirstName}}</p>",
+	}
+
+	rendered, err := template.Render(model.EmailTemplateData{FirstName: "<b>Sam</b> & co"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hi <b>Sam</b> & co", rendered.Text)
+	assert.Equal(t, "<p>Hi &lt;b&gt;Sam&lt;/b&gt; &amp; co</p>", rendered.HTML)
+}
+
+// TestRenderKeepsTheSubjectOnOneLine: a line break in a header would end it,
+// so a subject that renders across lines is folded onto one.
+func TestRenderKeepsTheSubjectOnOneLine(t *testing.T) {
+	rendered, err := model.EmailTemplate{Subject: "Rota\n{{range .ShiftDates}}{{.}}\n{{end}}", Text: "x"}.
+		Render(model.EmailTemplateData{ShiftDates: []string{"Sunday 2 August", "Sunday 9 August"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Rota Sunday 2 August Sunday 9 August", rendered.Subject)
+	assert.Empty(t, rendered.HTML, "no HTML template means no HTML part")
+}
+
+func TestEmailTemplatesForFallsBackToTheDefault(t *testing.T) {
+	var none model.EmailTemplates
+	template, customised := none.For(model.EmailTemplateReminder)
+
+	assert.False(t, customised)
+	assert.Equal(t, model.DefaultEmailTemplates[model.EmailTemplateReminder], template)
+}
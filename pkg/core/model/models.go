@@ -9,11 +9,17 @@ type Volunteer struct {
 	// Roles are the jobs this volunteer will do, in priority order. Holding a
 	// Role is what makes someone eligible for its Seats — there is no
 	// open-to-all Role and no mapping from one Role to another.
-	Roles    []string
-	Status   string
-	Gender   string
-	Email    string
-	GroupKey string // Empty string if no group
+	Roles []string
+	// UnknownRoles are the values the roster's Roles cell held that name no
+	// Role the app knows, in the order they were written. They are skipped
+	// from Roles — nobody is eligible by them — and kept only so a sync can
+	// show an admin what it skipped. Always empty for a roster kept in the
+	// database, which stores Roles by id.
+	UnknownRoles []string
+	Status       string
+	Gender       string
+	Email        string
+	GroupKey     string // Empty string if no group
 }
 
 // Holds reports whether the volunteer may be allocated to the named Role.
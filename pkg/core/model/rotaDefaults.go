@@ -46,6 +46,14 @@ type RotaDefaults struct {
 	// AllocationSettings is which optional allocator rules apply. The zero
 	// value means every rule off, which is where a deployment starts.
 	AllocationSettings AllocationSettings
+	// EmailTemplates is the wording an admin has saved for the emails the
+	// drop-in sends. Empty means every email reads as its default.
+	EmailTemplates EmailTemplates
+	// SwapsNeedApproval is whether a swap two volunteers arrange between
+	// themselves waits for an admin before the rota changes. Off by default:
+	// a volunteer who has found their own cover has done what an admin would
+	// have asked them to.
+	SwapsNeedApproval bool
 }
 
 // Timezone is the zone the shift times are read in: the one an admin chose, or
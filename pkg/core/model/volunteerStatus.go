@@ -0,0 +1,119 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// VolunteerState is where a volunteer is in their time with the drop-in, as the
+// roster sheet's Status column says it.
+//
+// The column used to be read as one question — does it say "Active"? — so a
+// typo, a "Paused" somebody meant to undo, or a newcomer still being shown the
+// ropes all dropped the volunteer from rounds and allocation without anybody
+// being told. The states are now a fixed set, and a cell that says none of
+// them is its own state rather than a quiet "no".
+type VolunteerState string
+
+const (
+	// StateActive is volunteering in the ordinary way.
+	StateActive VolunteerState = "active"
+	// StatePaused is away until a date, and back from it without anybody
+	// having to edit the sheet again.
+	StatePaused VolunteerState = "paused"
+	// StateOnboarding is volunteering while still new: sent rounds and
+	// allocated, but not in every Role they hold (see
+	// services.allocatableVolunteers).
+	StateOnboarding VolunteerState = "onboarding"
+	// StateLeft has stopped volunteering. They stay on the sheet, and so on the
+	// roster, because past rotas name them.
+	StateLeft VolunteerState = "left"
+	// StateUnrecognised is a Status cell that says none of the above. It is
+	// treated as not volunteering, as it always was, and reported at sync so
+	// an admin can put the cell right.
+	StateUnrecognised VolunteerState = "unrecognised"
+)
+
+// Volunteering reports whether the state is one rounds and allocation include.
+func (s VolunteerState) Volunteering() bool {
+	return s == StateActive || s == StateOnboarding
+}
+
+// Ongoing reports whether the state is one that volunteers now or will again
+// without anybody editing the sheet: a pause ends on its own, leaving is for
+// good.
+func (s VolunteerState) Ongoing() bool {
+	return s.Volunteering() || s == StatePaused
+}
+
+// pausedPrefix starts a Paused cell; the date the pause ends follows it.
+const pausedPrefix = "paused until "
+
+// pausedUntilLayouts are the spellings of a pause's end date the sheet may use:
+// the app's own, and the one a UK spreadsheet fills in by default.
+var pausedUntilLayouts = []string{time.DateOnly, "02/01/2006", "2/1/2006"}
+
+// VolunteerStatus is one Status cell, read.
+type VolunteerStatus struct {
+	State VolunteerState
+	// Until is the day a Paused volunteer is back, "2006-01-02". Empty for
+	// every other state.
+	Until string
+}
+
+// ParseVolunteerStatus reads a Status cell. Case and surrounding space do not
+// matter; anything else does. A pause without a date it ends on is
+// unrecognised rather than indefinite: "paused until somebody remembers" is
+// the state this replaced, and it is the one that loses volunteers.
+func ParseVolunteerStatus(cell string) VolunteerStatus {
+	text := strings.ToLower(strings.Join(strings.Fields(cell), " "))
+	switch text {
+	case string(StateActive):
+		return VolunteerStatus{State: StateActive}
+	case string(StateOnboarding):
+		return VolunteerStatus{State: StateOnboarding}
+	case string(StateLeft):
+		return VolunteerStatus{State: StateLeft}
+	}
+
+	if rest, ok := strings.CutPrefix(text, pausedPrefix); ok {
+		for _, layout := range pausedUntilLayouts {
+			if until, err := time.Parse(layout, rest); err == nil {
+				return VolunteerStatus{State: StatePaused, Until: until.Format(time.DateOnly)}
+			}
+		}
+	}
+	return VolunteerStatus{State: StateUnrecognised}
+}
+
+// Cell is the status as the roster sheet would spell it, which is how a roster
+// kept in the database stores it too, so that one parser reads both:
+// "Active", "Onboarding", "Left", or "Paused until 2006-01-02". An
+// unrecognised state has no spelling of its own and is empty.
+func (s VolunteerStatus) Cell() string {
+	switch s.State {
+	case StateActive:
+		return "Active"
+	case StateOnboarding:
+		return "Onboarding"
+	case StateLeft:
+		return "Left"
+	case StatePaused:
+		return "Paused until " + s.Until
+	}
+	return ""
+}
+
+// On is the state in force on the day date falls on: a pause whose end has
+// come is Active.
+func (s VolunteerStatus) On(date time.Time) VolunteerState {
+	if s.State == StatePaused && date.Format(time.DateOnly) >= s.Until {
+		return StateActive
+	}
+	return s.State
+}
+
+// Lifecycle is the volunteer's Status cell, read.
+func (v Volunteer) Lifecycle() VolunteerStatus {
+	return ParseVolunteerStatus(v.Status)
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVolunteerStatus(t *testing.T) {
+	tests := []struct {
+		cell string
+		want VolunteerStatus
+	}{
+		{cell: "Active", want: VolunteerStatus{State: StateActive}},
+		{cell: "  ACTIVE ", want: VolunteerStatus{State: StateActive}},
+		{cell: "Onboarding", want: VolunteerStatus{State: StateOnboarding}},
+		{cell: "left", want: VolunteerStatus{State: StateLeft}},
+		{cell: "Paused until 2026-03-01", want: VolunteerStatus{State: StatePaused, Until: "2026-03-01"}},
+		{cell: "paused  until 01/03/2026", want: VolunteerStatus{State: StatePaused, Until: "2026-03-01"}},
+		{cell: "Paused until 1/3/2026", want: VolunteerStatus{State: StatePaused, Until: "2026-03-01"}},
+		{cell: "Paused", want: VolunteerStatus{State: StateUnrecognised}},
+		{cell: "Paused until Easter", want: VolunteerStatus{State: StateUnrecognised}},
+		{cell: "Actve", want: VolunteerStatus{State: StateUnrecognised}},
+		{cell: "Inactive", want: VolunteerStatus{State: StateUnrecognised}},
+		{cell: "", want: VolunteerStatus{State: StateUnrecognised}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cell, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseVolunteerStatus(tt.cell))
+		})
+	}
+}
+
+// A pause ends on its date without anybody touching the sheet.
+func TestVolunteerStatus_On(t *testing.T) {
+	paused := VolunteerStatus{State: StatePaused, Until: "2026-03-01"}
+
+	assert.Equal(t, StatePaused, paused.On(time.Date(2026, 2, 28, 23, 0, 0, 0, time.UTC)))
+	assert.Equal(t, StateActive, paused.On(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, StateOnboarding, VolunteerStatus{State: StateOnboarding}.On(time.Now()))
+
+	assert.True(t, StateActive.Volunteering())
+	assert.True(t, StateOnboarding.Volunteering())
+	assert.False(t, StatePaused.Volunteering())
+	assert.False(t, StateLeft.Volunteering())
+	assert.False(t, StateUnrecognised.Volunteering())
+
+	assert.True(t, StatePaused.Ongoing(), "a pause ends on its own")
+	assert.True(t, StateOnboarding.Ongoing())
+	assert.False(t, StateLeft.Ongoing())
+	assert.False(t, StateUnrecognised.Ongoing())
+}
+
+// A status written back out reads as the one it was.
+func TestVolunteerStatus_Cell(t *testing.T) {
+	for _, status := range []VolunteerStatus{
+		{State: StateActive},
+		{State: StateOnboarding},
+		{State: StateLeft},
+		{State: StatePaused, Until: "2026-03-01"},
+	} {
+		assert.Equal(t, status, ParseVolunteerStatus(status.Cell()), status.Cell())
+	}
+	assert.Empty(t, VolunteerStatus{State: StateUnrecognised}.Cell())
+}
@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// An Absence is a stretch of days a volunteer has said in advance they are
+// away: "abroad all of December".
+//
+// It used to be something the volunteer had to remember when the round came
+// out, and untick December on every rota it touched. Written down once — by an
+// admin, or by the volunteer on their own availability page — it is a hard no
+// for every shift it covers: the group rule reads it as a no that overrides a
+// yes (buildAvailabilityGroup), so the round's coverage and the solve agree
+// about it, and the response grid flags the shifts where an answer said yes
+// regardless.
+//
+// Like a Pairing Rule it is a standing fact about a person rather than about a
+// rota, so it is edited by removing it and adding the one that was meant, and
+// changing one makes the rota in flight's draft dirty.
+
+// AbsenceStore is what reading and editing Absences needs. Roles come with it
+// only because reading the roster takes them.
+type AbsenceStore interface {
+	RoleStore
+	AbsenceReader
+	GetAbsenceByID(ctx context.Context, id string) (*db.Absence, error)
+	InsertAbsence(ctx context.Context, absence db.Absence) (db.Absence, error)
+	DeleteAbsenceByID(ctx context.Context, id string) (bool, error)
+}
+
+// AbsenceView is one Absence as a screen reads it: the volunteer by id and by
+// name, and the days, "2006-01-02", both inclusive.
+type AbsenceView struct {
+	ID          string
+	VolunteerID string
+	Name        string
+	From        string
+	To          string
+	Note        string
+	// CreatedBy is the admin who recorded it, empty when the volunteer did.
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// AddAbsenceParams is one stretch of days away. The volunteer is implied by the
+// link on a volunteer's own page, and ignored there.
+type AddAbsenceParams struct {
+	VolunteerID string
+	From        string
+	To          string
+	Note        string
+}
+
+// ListAbsences reads every Absence not over by now — the ones under way and
+// the ones to come — with the volunteer resolved to a name, soonest first.
+func ListAbsences(
+	ctx context.Context,
+	store AbsenceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	now time.Time,
+	logger *zap.Logger,
+) ([]AbsenceView, error) {
+	rows, err := store.GetAbsencesFrom(ctx, now.Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch absences: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteersByID := make(map[string]model.Volunteer, len(volunteers))
+	for _, v := range volunteers {
+		volunteersByID[v.ID] = v
+	}
+
+	views := make([]AbsenceView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, toAbsenceView(row, rosterName(row.VolunteerID, volunteersByID, logger)))
+	}
+	sort.Slice(views, func(i, j int) bool {
+		a, b := views[i], views[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	return views, nil
+}
+
+// AddAbsence validates and records one an admin is making for a volunteer.
+//
+// The volunteer must be on the roster, though not necessarily volunteering: a
+// Paused volunteer who says they will also be away the month after they are
+// back is exactly who this is for.
+func AddAbsence(
+	ctx context.Context,
+	store AbsenceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	params AddAbsenceParams,
+	createdBy string,
+	now time.Time,
+	logger *zap.Logger,
+) (*AbsenceView, error) {
+	if params.VolunteerID == "" {
+		return nil, wrapf(ErrInvalidInput, "an absence needs a volunteer")
+	}
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteer, ok := findVolunteer(volunteers, params.VolunteerID)
+	if !ok {
+		return nil, wrapf(ErrNotFound, "volunteer %s not found", params.VolunteerID)
+	}
+
+	saved, err := insertAbsence(ctx, store, params, createdBy, now)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Absence recorded",
+		zap.String("id", saved.ID),
+		zap.String("volunteer_id", saved.VolunteerID),
+		zap.String("from", saved.From),
+		zap.String("to", saved.To),
+		zap.String("by", createdBy))
+
+	view := toAbsenceView(saved, displayName(volunteer))
+	return &view, nil
+}
+
+// DeleteAbsence removes one.
+func DeleteAbsence(ctx context.Context, store AbsenceStore, id string, logger *zap.Logger) error {
+	deleted, err := store.DeleteAbsenceByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete absence %s: %w", id, err)
+	}
+	if !deleted {
+		return wrapf(ErrNotFound, "absence %s not found", id)
+	}
+
+	logger.Info("Absence deleted", zap.String("id", id))
+	return nil
+}
+
+// VolunteerAbsenceStore is what a volunteer editing their own Absences through
+// their link needs: the link resolved, and the Absences.
+type VolunteerAbsenceStore interface {
+	AvailabilityStore
+	AbsenceStore
+}
+
+// AddOwnAbsence records an Absence for the volunteer a link belongs to, and
+// returns their form as it now stands. A link only works while its rota is
+// open, as the form does, so the two cannot disagree about whether it is
+// usable.
+func AddOwnAbsence(
+	ctx context.Context,
+	database VolunteerAbsenceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+	token string,
+	params AddAbsenceParams,
+	now time.Time,
+) (*AvailabilityForm, error) {
+	request, rota, shifts, volunteers, err := resolveToken(ctx, database, volunteerClient, cfg, logger, token)
+	if err != nil {
+		return nil, err
+	}
+
+	params.VolunteerID = request.VolunteerID
+	saved, err := insertAbsence(ctx, database, params, "", now)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Absence recorded by the volunteer",
+		zap.String("id", saved.ID),
+		zap.String("volunteer_id", saved.VolunteerID),
+		zap.String("from", saved.From),
+		zap.String("to", saved.To))
+
+	return readForm(ctx, database, request, rota, shifts, volunteers)
+}
+
+// DeleteOwnAbsence removes one of the link holder's own Absences and returns
+// their form as it now stands. Somebody else's is not found rather than
+// forbidden: a link is the whole of a volunteer's identity, and it says nothing
+// about anybody else's plans, including that they have any.
+func DeleteOwnAbsence(
+	ctx context.Context,
+	database VolunteerAbsenceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+	token string,
+	absenceID string,
+) (*AvailabilityForm, error) {
+	request, rota, shifts, volunteers, err := resolveToken(ctx, database, volunteerClient, cfg, logger, token)
+	if err != nil {
+		return nil, err
+	}
+
+	absence, err := database.GetAbsenceByID(ctx, absenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch absence %s: %w", absenceID, err)
+	}
+	if absence == nil || absence.VolunteerID != request.VolunteerID {
+		return nil, wrapf(ErrNotFound, "absence %s not found", absenceID)
+	}
+	if err := DeleteAbsence(ctx, database, absenceID, logger); err != nil {
+		return nil, err
+	}
+
+	return readForm(ctx, database, request, rota, shifts, volunteers)
+}
+
+// insertAbsence checks the days and writes the row.
+//
+// An Absence that is already over is refused: it can change nothing, and it is
+// far likelier to be a year typed wrong than a record somebody wants kept.
+func insertAbsence(ctx context.Context, store AbsenceStore, params AddAbsenceParams, createdBy string, now time.Time) (db.Absence, error) {
+	from, err := time.Parse(time.DateOnly, strings.TrimSpace(params.From))
+	if err != nil {
+		return db.Absence{}, wrapf(ErrInvalidInput, "from must be a date as YYYY-MM-DD, not %q", params.From)
+	}
+	to, err := time.Parse(time.DateOnly, strings.TrimSpace(params.To))
+	if err != nil {
+		return db.Absence{}, wrapf(ErrInvalidInput, "to must be a date as YYYY-MM-DD, not %q", params.To)
+	}
+	if to.Before(from) {
+		return db.Absence{}, wrapf(ErrInvalidInput, "an absence cannot end (%s) before it starts (%s)", to.Format(time.DateOnly), from.Format(time.DateOnly))
+	}
+	if to.Format(time.DateOnly) < now.Format(time.DateOnly) {
+		return db.Absence{}, wrapf(ErrInvalidInput, "that absence ended on %s, so there is nothing for it to change", to.Format(time.DateOnly))
+	}
+
+	saved, err := store.InsertAbsence(ctx, db.Absence{
+		ID:          uuid.New().String(),
+		VolunteerID: params.VolunteerID,
+		From:        from.Format(time.DateOnly),
+		To:          to.Format(time.DateOnly),
+		Note:        strings.TrimSpace(params.Note),
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return db.Absence{}, fmt.Errorf("failed to save absence: %w", err)
+	}
+	return saved, nil
+}
+
+// absentShiftIDs is, for each volunteer with an Absence covering one of the
+// shifts, the shifts it covers, in shift order. Closed shifts are left out:
+// nobody is asked about them, so there is nothing for an Absence to overrule.
+func absentShiftIDs(absences []db.Absence, shifts []AvailabilityShift) map[string][]string {
+	out := make(map[string][]string)
+	for _, shift := range shifts {
+		if shift.Closed {
+			continue
+		}
+		away := make(map[string]bool)
+		for _, a := range absences {
+			if a.From <= shift.Date && shift.Date <= a.To && !away[a.VolunteerID] {
+				away[a.VolunteerID] = true
+				out[a.VolunteerID] = append(out[a.VolunteerID], shift.ID)
+			}
+		}
+	}
+	return out
+}
+
+// AbsenceReader is the one read of Absences a round, a form and a solve need.
+type AbsenceReader interface {
+	GetAbsencesFrom(ctx context.Context, date string) ([]db.Absence, error)
+}
+
+// shiftAbsences reads the Absences not over by the first of the shifts, and
+// which shifts each volunteer is away for. Shifts come in date order, so
+// nothing over before the first of them can cover one.
+func shiftAbsences(ctx context.Context, store AbsenceReader, shifts []AvailabilityShift) (map[string][]string, []db.Absence, error) {
+	if len(shifts) == 0 {
+		return map[string][]string{}, nil, nil
+	}
+	absences, err := store.GetAbsencesFrom(ctx, shifts[0].Date)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch absences: %w", err)
+	}
+	return absentShiftIDs(absences, shifts), absences, nil
+}
+
+func toAbsenceView(a db.Absence, name string) AbsenceView {
+	return AbsenceView{
+		ID:          a.ID,
+		VolunteerID: a.VolunteerID,
+		Name:        name,
+		From:        a.From,
+		To:          a.To,
+		Note:        a.Note,
+		CreatedBy:   a.CreatedBy,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// ownAbsences is one volunteer's Absences from among everybody's, in the order
+// they came, which is soonest first.
+func ownAbsences(absences []db.Absence, volunteerID string) []AbsenceView {
+	out := make([]AbsenceView, 0)
+	for _, a := range absences {
+		if a.VolunteerID == volunteerID {
+			out = append(out, toAbsenceView(a, ""))
+		}
+	}
+	return out
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+func (m *mockAvailabilityStore) GetAbsencesFrom(_ context.Context, date string) ([]db.Absence, error) {
+	var out []db.Absence
+	for _, a := range m.absences {
+		if a.To >= date {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) GetAbsenceByID(_ context.Context, id string) (*db.Absence, error) {
+	for i := range m.absences {
+		if m.absences[i].ID == id {
+			return &m.absences[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAvailabilityStore) InsertAbsence(_ context.Context, absence db.Absence) (db.Absence, error) {
+	absence.CreatedAt = time.Date(2026, 7, 30, 12, 0, 0, 0, time.UTC)
+	m.absences = append(m.absences, absence)
+	return absence, nil
+}
+
+func (m *mockAvailabilityStore) DeleteAbsenceByID(_ context.Context, id string) (bool, error) {
+	for i := range m.absences {
+		if m.absences[i].ID == id {
+			m.absences = append(m.absences[:i], m.absences[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// absenceNow is the day the tests below are run on: before the fixture's rota,
+// so an Absence over any of it is still to come.
+var absenceNow = time.Date(2026, 7, 20, 10, 0, 0, 0, time.UTC)
+
+func TestAddAbsence_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  AddAbsenceParams
+		wantErr error
+	}{
+		{name: "no volunteer", params: AddAbsenceParams{From: "2026-08-01", To: "2026-08-31"}, wantErr: ErrInvalidInput},
+		{name: "unknown volunteer", params: AddAbsenceParams{VolunteerID: "nobody", From: "2026-08-01", To: "2026-08-31"}, wantErr: ErrNotFound},
+		{name: "not a date", params: AddAbsenceParams{VolunteerID: "michael", From: "August", To: "2026-08-31"}, wantErr: ErrInvalidInput},
+		{name: "ends before it starts", params: AddAbsenceParams{VolunteerID: "michael", From: "2026-08-31", To: "2026-08-01"}, wantErr: ErrInvalidInput},
+		{name: "already over", params: AddAbsenceParams{VolunteerID: "michael", From: "2026-07-01", To: "2026-07-19"}, wantErr: ErrInvalidInput},
+		// Somebody who has stopped may still say when they will be away.
+		{name: "volunteer who has stopped", params: AddAbsenceParams{VolunteerID: "gone", From: "2026-08-01", To: "2026-08-01"}},
+		{name: "under way", params: AddAbsenceParams{VolunteerID: "michael", From: "2026-07-01", To: "2026-07-20"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, cfg := availabilityFixture()
+			view, err := AddAbsence(context.Background(), store, availabilityVolunteers(), cfg, tt.params, "admin@example.com", absenceNow, zap.NewNop())
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, store.absences, "nothing is written")
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, store.absences, 1)
+			assert.Equal(t, "admin@example.com", store.absences[0].CreatedBy)
+			assert.Equal(t, tt.params.From, view.From)
+		})
+	}
+}
+
+func TestListAbsences_SoonestFirstWithNames(t *testing.T) {
+	store, cfg := availabilityFixture()
+	store.absences = []db.Absence{
+		{ID: "a1", VolunteerID: "michael", From: "2026-12-01", To: "2026-12-31", Note: "abroad"},
+		{ID: "a2", VolunteerID: "emma", From: "2026-08-09", To: "2026-08-09"},
+		{ID: "a3", VolunteerID: "aaliyah", From: "2026-06-01", To: "2026-06-30"},
+		{ID: "a4", VolunteerID: "left-the-sheet", From: "2026-09-01", To: "2026-09-07"},
+	}
+
+	views, err := ListAbsences(context.Background(), store, availabilityVolunteers(), cfg, absenceNow, zap.NewNop())
+	require.NoError(t, err)
+
+	require.Len(t, views, 3, "an absence that is over is not listed")
+	assert.Equal(t, "Emma", views[0].Name)
+	assert.Equal(t, "left-the-sheet", views[1].Name, "somebody off the roster is named by their id")
+	assert.Equal(t, "Michael", views[2].Name)
+	assert.Equal(t, "abroad", views[2].Note)
+}
+
+func TestDeleteAbsence_NotFound(t *testing.T) {
+	store, _ := availabilityFixture()
+	err := DeleteAbsence(context.Background(), store, "missing", zap.NewNop())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+// A volunteer adding an Absence through their own link gets it recorded as
+// theirs, and the shift it covers comes off their form's ticks.
+func TestAddOwnAbsence_UnticksTheShiftsItCovers(t *testing.T) {
+	store, cfg := availabilityFixture()
+	round := mintRound(t, store, availabilityVolunteers(), cfg)
+
+	form, err := AddOwnAbsence(context.Background(), store, availabilityVolunteers(), cfg, zap.NewNop(),
+		tokenFor(t, round, "michael"), AddAbsenceParams{VolunteerID: "emma", From: "2026-08-05", To: "2026-08-12", Note: "wedding"}, absenceNow)
+	require.NoError(t, err)
+
+	require.Len(t, store.absences, 1)
+	assert.Equal(t, "michael", store.absences[0].VolunteerID, "the link says whose it is, not the body")
+	assert.Empty(t, store.absences[0].CreatedBy)
+
+	require.Len(t, form.Absences, 1)
+	assert.Equal(t, "wedding", form.Absences[0].Note)
+	assert.Equal(t, []string{"shift-2"}, form.AbsentShiftIDs)
+	assert.Equal(t, []string{"shift-1"}, form.SelectedShiftIDs, "an Absence is not offered as a yes")
+}
+
+// Somebody else's Absence is not there to be removed through a link.
+func TestDeleteOwnAbsence_OnlyTheirOwn(t *testing.T) {
+	store, cfg := availabilityFixture()
+	round := mintRound(t, store, availabilityVolunteers(), cfg)
+	store.absences = []db.Absence{
+		{ID: "emmas", VolunteerID: "emma", From: "2026-08-01", To: "2026-08-31"},
+		{ID: "michaels", VolunteerID: "michael", From: "2026-08-01", To: "2026-08-31"},
+	}
+	token := tokenFor(t, round, "michael")
+
+	_, err := DeleteOwnAbsence(context.Background(), store, availabilityVolunteers(), cfg, zap.NewNop(), token, "emmas")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	form, err := DeleteOwnAbsence(context.Background(), store, availabilityVolunteers(), cfg, zap.NewNop(), token, "michaels")
+	require.NoError(t, err)
+	assert.Empty(t, form.Absences)
+	assert.Empty(t, form.AbsentShiftIDs)
+	require.Len(t, store.absences, 1)
+	assert.Equal(t, "emmas", store.absences[0].ID)
+}
+
+// The round carries each volunteer's days away, and a yes on one of them
+// reaches neither the group's answer nor the coverage counted from it.
+func TestRoundTreatsAnAbsenceAsNo(t *testing.T) {
+	store, cfg := availabilityFixture()
+	volunteers := availabilityVolunteers()
+	round := mintRound(t, store, volunteers, cfg)
+	_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
+		tokenFor(t, round, "aaliyah"), []string{"shift-1", "shift-2"}, 0)
+	require.NoError(t, err)
+	store.absences = []db.Absence{{ID: "a1", VolunteerID: "aaliyah", From: "2026-08-09", To: "2026-08-20"}}
+
+	round, err = GetAvailabilityRound(context.Background(), store, volunteers, cfg, zap.NewNop(), "")
+	require.NoError(t, err)
+
+	var group AvailabilityGroup
+	for _, g := range round.Groups {
+		if g.Key == "individual:aaliyah" {
+			group = g
+		}
+	}
+	require.Len(t, group.Members, 1)
+	assert.Equal(t, []string{"shift-1", "shift-2"}, group.Members[0].AvailableShiftIDs, "what she said is kept")
+	assert.Equal(t, []string{"shift-2"}, group.Members[0].AbsentShiftIDs, "the closed shift is nothing to be away for")
+	assert.Equal(t, []string{"shift-1"}, group.AvailableShiftIDs)
+	assert.Equal(t, []string{"shift-2"}, group.AbsentShiftIDs)
+}
+
+// A group is placed whole, so one member's Absence is the group's no, even
+// when that member never answered and the other said yes.
+func TestBuildAvailabilityGroup_AbsenceOfAMemberWhoDidNotReply(t *testing.T) {
+	shifts := []AvailabilityShift{{ID: "s1", Date: "2026-08-02"}, {ID: "s2", Date: "2026-08-09"}}
+	group := buildAvailabilityGroup("smiths", []AvailabilityEntry{
+		{VolunteerID: "michael", VolunteerName: "Michael", Replied: true, AvailableShiftIDs: []string{"s1", "s2"}},
+		{VolunteerID: "emma", VolunteerName: "Emma", AbsentShiftIDs: []string{"s2"}},
+	}, shifts)
+
+	assert.Equal(t, []string{"s1"}, group.AvailableShiftIDs)
+	assert.Equal(t, []string{"s2"}, group.AbsentShiftIDs)
+}
+
+func TestAbsentShiftIDs(t *testing.T) {
+	shifts := []AvailabilityShift{
+		{ID: "s1", Date: "2026-08-02"},
+		{ID: "s2", Date: "2026-08-09"},
+		{ID: "s3", Date: "2026-08-16", Closed: true},
+		{ID: "s4", Date: "2026-08-23"},
+	}
+	got := absentShiftIDs([]db.Absence{
+		{VolunteerID: "alice", From: "2026-08-02", To: "2026-08-02"},
+		{VolunteerID: "alice", From: "2026-08-01", To: "2026-08-10"}, // overlaps the one above
+		{VolunteerID: "bob", From: "2026-08-10", To: "2026-08-30"},
+		{VolunteerID: "carol", From: "2026-09-01", To: "2026-09-30"},
+	}, shifts)
+
+	assert.Equal(t, map[string][]string{
+		"alice": {"s1", "s2"},
+		"bob":   {"s4"},
+	}, got)
+}
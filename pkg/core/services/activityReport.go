@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services/utils"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// ActivityReportStore is what the activity report reads: the allocated rotas
+// and their shifts, who was put on them and moved about since, and each
+// rota's round.
+type ActivityReportStore interface {
+	RoleStore
+	roundStatusStore
+	GetRotations(ctx context.Context) ([]db.Rotation, error)
+	GetShiftsByRotaID(ctx context.Context, rotaID string) ([]db.Shift, error)
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+}
+
+// ActivityReportParams is the date range a report covers, both ends inclusive
+// and both spelled "2006-01-02".
+type ActivityReportParams struct {
+	From string
+	To   string
+}
+
+func (p ActivityReportParams) validate() error {
+	from, err := time.Parse("2006-01-02", p.From)
+	if err != nil {
+		return wrapf(ErrInvalidInput, "from %q is not a date — write it as 2026-08-02", p.From)
+	}
+	to, err := time.Parse("2006-01-02", p.To)
+	if err != nil {
+		return wrapf(ErrInvalidInput, "to %q is not a date — write it as 2026-08-02", p.To)
+	}
+	if to.Before(from) {
+		return wrapf(ErrInvalidInput, "the range ends on %s, before it starts on %s", p.To, p.From)
+	}
+	return nil
+}
+
+// RoleShifts is how many shifts somebody worked in one Role.
+type RoleShifts struct {
+	Role   string `json:"role"`
+	Shifts int    `json:"shifts"`
+}
+
+// VolunteerActivity is one volunteer's part in the rotas a report covers.
+//
+// ShiftsAllocated is what the allocator gave them; AlterationsIn and
+// AlterationsOut are the times they were since put on a shift or taken off
+// one; ShiftsWorked is where that left them — the rota as it now stands — and
+// Roles splits it by the Role they held. The two shift counts differ by the
+// Alterations, so a volunteer who swaps a lot still reads honestly.
+//
+// RoundsAsked and Responses are the rounds in the range they were sent and
+// how many they answered by the time their rota was allocated, which is the
+// answer allocation went on. LastShift is their latest shift in the range as
+// the rota stands, empty when they had none.
+//
+// The JSON names are the CSV's columns, so the CLI's two outputs agree; the
+// HTTP API has its own shape, in the API's spelling.
+type VolunteerActivity struct {
+	VolunteerID     string       `json:"volunteer_id"`
+	VolunteerName   string       `json:"volunteer_name"`
+	Active          bool         `json:"active"`
+	ShiftsAllocated int          `json:"shifts_allocated"`
+	AlterationsIn   int          `json:"alterations_in"`
+	AlterationsOut  int          `json:"alterations_out"`
+	ShiftsWorked    int          `json:"shifts_worked"`
+	Roles           []RoleShifts `json:"roles"`
+	RoundsAsked     int          `json:"rounds_asked"`
+	Responses       int          `json:"responses"`
+	LastShift       string       `json:"last_shift"`
+}
+
+// ActivityReport is every volunteer's activity over a date range, ordered by
+// name. Rotas is how many allocated rotas the range reached into.
+type ActivityReport struct {
+	From       string              `json:"from"`
+	To         string              `json:"to"`
+	Rotas      int                 `json:"rotas"`
+	Volunteers []VolunteerActivity `json:"volunteers"`
+}
+
+// BuildActivityReport reads every allocated rota that overlaps the range and
+// counts, per volunteer, what the range held for them.
+//
+// Only the shifts inside the range count, and only open ones: a closed shift
+// had nobody on it. A rota's round counts when any of its shifts falls in the
+// range, since the round asked about all of them at once.
+//
+// Everyone active on the roster is listed, whether or not they did anything —
+// the point is to spot who has lapsed, and nobody lapses by being left off the
+// report. Somebody since made inactive appears only where the range has
+// something of theirs. Custom entries are not volunteers and are not counted.
+func BuildActivityReport(
+	ctx context.Context,
+	store ActivityReportStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	params ActivityReportParams,
+	logger *zap.Logger,
+) (*ActivityReport, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	rotations, err := store.GetRotations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rotations: %w", err)
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+
+	tally := newActivityTally()
+	rotas := 0
+	for _, rota := range rotations {
+		if rota.AllocatedDatetime == "" || rota.End < params.From || rota.Start > params.To {
+			continue
+		}
+		counted, err := tally.addRota(ctx, store, rota, params)
+		if err != nil {
+			return nil, fmt.Errorf("rota %s: %w", rota.ID, err)
+		}
+		if counted {
+			rotas++
+		}
+	}
+
+	report := &ActivityReport{From: params.From, To: params.To, Rotas: rotas}
+	for _, v := range roster {
+		active := utils.IsActive(v)
+		activity, seen := tally.byVolunteer[v.ID]
+		if !active && !seen {
+			continue
+		}
+		if activity == nil {
+			activity = &VolunteerActivity{}
+		}
+		activity.VolunteerID = v.ID
+		activity.VolunteerName = volunteerName(v)
+		activity.Active = active
+		activity.Roles = tally.roleShifts(v.ID, roles)
+		report.Volunteers = append(report.Volunteers, *activity)
+		delete(tally.byVolunteer, v.ID)
+	}
+	// Whoever is left has dropped off the roster altogether, and is still
+	// somebody who worked the range: they are listed under their id.
+	for id, activity := range tally.byVolunteer {
+		activity.VolunteerID = id
+		activity.VolunteerName = id
+		activity.Roles = tally.roleShifts(id, roles)
+		report.Volunteers = append(report.Volunteers, *activity)
+	}
+	sort.Slice(report.Volunteers, func(i, j int) bool {
+		a, b := report.Volunteers[i], report.Volunteers[j]
+		if a.VolunteerName != b.VolunteerName {
+			return a.VolunteerName < b.VolunteerName
+		}
+		return a.VolunteerID < b.VolunteerID
+	})
+
+	logger.Debug("Built activity report",
+		zap.String("from", params.From),
+		zap.String("to", params.To),
+		zap.Int("rotas", rotas),
+		zap.Int("volunteers", len(report.Volunteers)))
+
+	return report, nil
+}
+
+// activityTally accumulates the report one rota at a time.
+type activityTally struct {
+	byVolunteer map[string]*VolunteerActivity
+	// roleCounts is [volunteerID][role] -> shifts worked in it.
+	roleCounts map[string]map[string]int
+}
+
+func newActivityTally() *activityTally {
+	return &activityTally{
+		byVolunteer: make(map[string]*VolunteerActivity),
+		roleCounts:  make(map[string]map[string]int),
+	}
+}
+
+func (t *activityTally) of(volunteerID string) *VolunteerActivity {
+	if a, ok := t.byVolunteer[volunteerID]; ok {
+		return a
+	}
+	a := &VolunteerActivity{}
+	t.byVolunteer[volunteerID] = a
+	return a
+}
+
+// addRota counts one allocated rota's shifts in the range and its round. It
+// reports whether the rota had any shift in the range at all; one that did not
+// only overlapped it on paper, and its round is not counted either.
+func (t *activityTally) addRota(ctx context.Context, store ActivityReportStore, rota db.Rotation, params ActivityReportParams) (bool, error) {
+	shifts, err := store.GetShiftsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch shifts: %w", err)
+	}
+	inRange := make(map[string]db.Shift)
+	for _, s := range shifts {
+		if !s.Closed && s.Date >= params.From && s.Date <= params.To {
+			inRange[s.ID] = s
+		}
+	}
+	if len(inRange) == 0 {
+		return false, nil
+	}
+	ids := make([]string, 0, len(inRange))
+	for id := range inRange {
+		ids = append(ids, id)
+	}
+
+	allocations, err := store.GetAllocationsByShiftIDs(ctx, ids)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+	for _, a := range allocations {
+		if a.VolunteerID != "" {
+			t.of(a.VolunteerID).ShiftsAllocated++
+		}
+	}
+
+	alterations, err := store.GetAlterationsByShiftIDs(ctx, ids)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch alterations: %w", err)
+	}
+	for _, alt := range alterations {
+		if alt.VolunteerID == "" {
+			continue
+		}
+		switch alt.Direction {
+		case "add":
+			t.of(alt.VolunteerID).AlterationsIn++
+		case "remove":
+			t.of(alt.VolunteerID).AlterationsOut++
+		}
+	}
+
+	byShiftID := make(map[string][]db.Allocation)
+	for _, a := range allocations {
+		byShiftID[a.ShiftID] = append(byShiftID[a.ShiftID], a)
+	}
+	for shiftID, onShift := range utils.ApplyAlterations(byShiftID, alterations) {
+		date := inRange[shiftID].Date
+		for _, a := range onShift {
+			if a.VolunteerID == "" {
+				continue
+			}
+			activity := t.of(a.VolunteerID)
+			activity.ShiftsWorked++
+			if date > activity.LastShift {
+				activity.LastShift = date
+			}
+			if t.roleCounts[a.VolunteerID] == nil {
+				t.roleCounts[a.VolunteerID] = make(map[string]int)
+			}
+			t.roleCounts[a.VolunteerID][a.Role]++
+		}
+	}
+
+	statuses, err := rotaResponseStatuses(ctx, store, rota)
+	if err != nil {
+		return false, err
+	}
+	for volunteerID, status := range statuses {
+		activity := t.of(volunteerID)
+		activity.RoundsAsked++
+		if status.Status == "available" || status.Status == "no_availability" {
+			activity.Responses++
+		}
+	}
+	return true, nil
+}
+
+// roleShifts is a volunteer's shifts by Role, in the order the Roles' Seats
+// are filled so every row of the report reads the same way round. A Role the
+// table no longer has — or an Alteration that carried none — follows, by name.
+func (t *activityTally) roleShifts(volunteerID string, roles model.Roles) []RoleShifts {
+	counts := t.roleCounts[volunteerID]
+	if len(counts) == 0 {
+		return nil
+	}
+	out := make([]RoleShifts, 0, len(counts))
+	listed := make(map[string]bool, len(counts))
+	for _, role := range roles.ByPriority() {
+		if n := counts[role.Name]; n > 0 {
+			out = append(out, RoleShifts{Role: role.Name, Shifts: n})
+			listed[role.Name] = true
+		}
+	}
+	var others []string
+	for role := range counts {
+		if !listed[role] {
+			others = append(others, role)
+		}
+	}
+	sort.Strings(others)
+	for _, role := range others {
+		out = append(out, RoleShifts{Role: role, Shifts: counts[role]})
+	}
+	return out
+}
+
+// activityCSVHeader is the report's columns, in the order WriteActivityReportCSV
+// writes them.
+var activityCSVHeader = []string{
+	"volunteer_id", "volunteer_name", "active",
+	"shifts_allocated", "alterations_in", "alterations_out", "shifts_worked",
+	"roles", "rounds_asked", "responses", "last_shift",
+}
+
+// WriteActivityReportCSV writes the report one row per volunteer, for a
+// spreadsheet. The Roles worked share one cell — "Team lead: 3; Service
+// volunteer: 1" — because the set of Roles is the deployment's own, and a
+// column per Role would make the file's shape change with the Roles table. A
+// Role with no name is written as "(none)".
+func WriteActivityReportCSV(w io.Writer, report *ActivityReport) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(activityCSVHeader); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, v := range report.Volunteers {
+		worked := make([]string, 0, len(v.Roles))
+		for _, r := range v.Roles {
+			role := r.Role
+			if role == "" {
+				role = "(none)"
+			}
+			worked = append(worked, fmt.Sprintf("%s: %d", role, r.Shifts))
+		}
+		row := []string{
+			v.VolunteerID,
+			v.VolunteerName,
+			strconv.FormatBool(v.Active),
+			strconv.Itoa(v.ShiftsAllocated),
+			strconv.Itoa(v.AlterationsIn),
+			strconv.Itoa(v.AlterationsOut),
+			strconv.Itoa(v.ShiftsWorked),
+			strings.Join(worked, "; "),
+			strconv.Itoa(v.RoundsAsked),
+			strconv.Itoa(v.Responses),
+			v.LastShift,
+		}
+		if err := out.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	out.Flush()
+	if err := out.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// activityStore is swapStore with Sara covering Emma on the last shift, so
+// the report has an Alteration each way to count.
+func activityStore() *mockAvailabilityStore {
+	store := swapStore()
+	store.alterations = []db.Alteration{
+		{ID: "alt-1", ShiftID: "shift-2", Direction: "remove", VolunteerID: "emma", Role: "Service volunteer", CoverID: "cover-1", SetTime: "2026-08-05T10:00:00Z"},
+		{ID: "alt-2", ShiftID: "shift-2", Direction: "add", VolunteerID: "sara", Role: "Service volunteer", CoverID: "cover-1", SetTime: "2026-08-05T10:00:00Z"},
+	}
+	return store
+}
+
+func buildActivityReport(t *testing.T, store *mockAvailabilityStore, from, to string) *ActivityReport {
+	t.Helper()
+	report, err := BuildActivityReport(context.Background(), store, swapVolunteers(), sendTestCfg, ActivityReportParams{From: from, To: to}, zap.NewNop())
+	require.NoError(t, err)
+	return report
+}
+
+func activityOf(report *ActivityReport, volunteerID string) *VolunteerActivity {
+	for i := range report.Volunteers {
+		if report.Volunteers[i].VolunteerID == volunteerID {
+			return &report.Volunteers[i]
+		}
+	}
+	return nil
+}
+
+func TestActivityReport(t *testing.T) {
+	report := buildActivityReport(t, activityStore(), "2026-07-26", "2026-08-09")
+	assert.Equal(t, 1, report.Rotas)
+
+	names := make([]string, 0, len(report.Volunteers))
+	for _, v := range report.Volunteers {
+		names = append(names, v.VolunteerName)
+	}
+	assert.Equal(t, []string{"Emma Williams", "Michael Smith", "Priya Shah", "Sara Ali", "Tom Jones"}, names,
+		"Tom is inactive but was asked, so the range has something of his")
+
+	tests := []struct {
+		volunteerID string
+		want        VolunteerActivity
+	}{
+		{volunteerID: "sara", want: VolunteerActivity{
+			ShiftsAllocated: 2, AlterationsIn: 1, ShiftsWorked: 3,
+			Roles:       []RoleShifts{{Role: "Service volunteer", Shifts: 3}},
+			RoundsAsked: 1, Responses: 1, LastShift: "2026-08-09",
+		}},
+		{volunteerID: "emma", want: VolunteerActivity{
+			ShiftsAllocated: 1, AlterationsOut: 1,
+			RoundsAsked: 1, Responses: 1,
+		}},
+		{volunteerID: "michael", want: VolunteerActivity{
+			ShiftsAllocated: 1, ShiftsWorked: 1,
+			Roles:       []RoleShifts{{Role: "Team lead", Shifts: 1}},
+			RoundsAsked: 1, Responses: 1, LastShift: "2026-08-02",
+		}},
+		{volunteerID: "priya", want: VolunteerActivity{RoundsAsked: 1, Responses: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.volunteerID, func(t *testing.T) {
+			got := activityOf(report, tt.volunteerID)
+			require.NotNil(t, got)
+			assert.True(t, got.Active)
+			got.VolunteerID, got.VolunteerName, got.Active = "", "", false
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+	assert.False(t, activityOf(report, "tom").Active)
+}
+
+// TestActivityReportRange: only the shifts inside the range count, and a
+// range nothing was allocated in still lists everyone active, at nought.
+func TestActivityReportRange(t *testing.T) {
+	report := buildActivityReport(t, activityStore(), "2026-08-01", "2026-08-05")
+	sara := activityOf(report, "sara")
+	assert.Equal(t, 1, sara.ShiftsWorked)
+	assert.Equal(t, 0, sara.AlterationsIn, "the cover was on a shift after the range")
+	assert.Equal(t, "2026-08-02", sara.LastShift)
+	assert.Equal(t, 1, sara.RoundsAsked, "the round asked about this shift too")
+
+	report = buildActivityReport(t, activityStore(), "2026-09-01", "2026-09-30")
+	assert.Equal(t, 0, report.Rotas)
+	assert.Len(t, report.Volunteers, 4, "everyone active, and not Tom")
+	assert.Nil(t, activityOf(report, "tom"))
+	assert.Equal(t, VolunteerActivity{VolunteerID: "emma", VolunteerName: "Emma Williams", Active: true}, *activityOf(report, "emma"))
+}
+
+func TestActivityReportRefusesABadRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+	}{
+		{name: "no from", to: "2026-08-09"},
+		{name: "not a date", from: "2026-08-01", to: "9 August"},
+		{name: "backwards", from: "2026-08-09", to: "2026-08-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BuildActivityReport(context.Background(), activityStore(), swapVolunteers(), sendTestCfg, ActivityReportParams{From: tt.from, To: tt.to}, zap.NewNop())
+			assert.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
+func TestWriteActivityReportCSV(t *testing.T) {
+	report := &ActivityReport{Volunteers: []VolunteerActivity{{
+		VolunteerID: "michael", VolunteerName: "Michael Smith", Active: true,
+		ShiftsAllocated: 4, AlterationsOut: 1, ShiftsWorked: 3,
+		Roles:       []RoleShifts{{Role: "Team lead", Shifts: 2}, {Role: "Service volunteer", Shifts: 1}},
+		RoundsAsked: 2, Responses: 1, LastShift: "2026-08-02",
+	}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteActivityReportCSV(&buf, report))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "volunteer_id", rows[0][0])
+	assert.Equal(t, []string{
+		"michael", "Michael Smith", "true", "4", "0", "1", "3",
+		"Team lead: 2; Service volunteer: 1", "2", "1", "2026-08-02",
+	}, rows[1])
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jakechorley/ilford-drop-in/internal/config"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/allocator"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
@@ -172,6 +173,26 @@ func TestAllocateRotaInFlightRefusesARotaThatHasMoved(t *testing.T) {
 	assert.Equal(t, 2, len(outcome.Solve.Shifts), "which the admin is shown, to confirm instead")
 }
 
+// A deployment configured for the in-process engine drafts and allocates with
+// no Python at all: the python flag names nothing that exists, and the rota is
+// still allocated.
+func TestAllocateRotaInFlightWithTheGoEngine(t *testing.T) {
+	store, volunteers := allocatableRota()
+	cfg := &config.Config{Allocator: &config.AllocatorConfig{Engine: config.AllocatorEngineGo}}
+	noPython := filepath.Join(t.TempDir(), "no-such-python")
+
+	shown, err := SolveDraftRotaAllocation(context.Background(), store, volunteers, cfg, zap.NewNop(), noPython)
+	require.NoError(t, err)
+	require.True(t, shown.Success)
+
+	outcome, err := AllocateRotaInFlight(context.Background(), store, volunteers, cfg, zap.NewNop(), shown.Hash, noPython)
+
+	require.NoError(t, err)
+	require.NotNil(t, outcome)
+	assert.True(t, outcome.Allocated, "the engine is deterministic, so the rota confirmed is the rota solved")
+	assert.NotEmpty(t, store.insertedAllocations)
+}
+
 // An infeasible solve is not a rota, so there is nothing to allocate. It is
 // still stored as the draft: it is the answer as things stand, and the screen
 // that refused the allocation is the one that has to explain why.
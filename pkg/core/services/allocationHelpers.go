@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -36,6 +37,9 @@ type SolveRotaStore interface {
 	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
 	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
 	GetPreallocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Preallocation, error)
+	GetPairingRules(ctx context.Context) ([]db.PairingRule, error)
+	GetAttendanceByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Attendance, error)
+	AbsenceReader
 }
 
 // AllocateRotaStore is what allocating the rota in flight needs: everything
@@ -63,26 +67,36 @@ type AllocateRotaStore interface {
 // opposite encoding: it unioned unavailability where this intersects
 // availability, which is the same rule read backwards.
 //
-// orderedShiftIDs must be in the solver's shift order, since that is what an
-// index means to it.
+// orderedShifts must be in the solver's shift order, since that is what an
+// index means to it, and carry their dates, since that is what an Absence is
+// read against. An Absence is a no whatever was answered, and the group rule
+// is where that is decided too. pausedShiftIDs are the shifts each volunteer
+// is still paused for (see allocatableVolunteers), and are a no the same way.
+//
+// The second map is each group's preferred shift count, from the same answers
+// by the same rule's sibling (buildAvailabilityGroup), holding only the groups
+// that gave one. It is read here rather than separately because it is part of
+// the answer: a count from one generation beside shifts from another would be
+// a preference nobody expressed.
 func fetchGroupAvailability(
 	ctx context.Context,
 	database SolveRotaStore,
 	rotaID string,
 	activeVolunteers []allocator.Volunteer,
-	orderedShiftIDs []string,
+	orderedShifts []AvailabilityShift,
+	pausedShiftIDs map[string][]string,
 	logger *zap.Logger,
-) (map[string][]int, error) {
+) (map[string][]int, map[string]int, error) {
 	requests, err := database.GetAvailabilityRequestsByRotaID(ctx, rotaID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch availability requests: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch availability requests: %w", err)
 	}
 	if len(requests) == 0 {
 		// No rota id in it. This is the state every rota is in from the moment
 		// it is defined until its round is minted, so the message is read on the
 		// Allocation tab as a matter of course rather than in a log — and there
 		// is only one rota it could be about (issue #145).
-		return nil, wrapf(ErrInvalidInput, "nobody has been asked about this rota yet - start the availability round below, and the draft will solve from the answers as they come in")
+		return nil, nil, wrapf(ErrInvalidInput, "nobody has been asked about this rota yet - start the availability round below, and the draft will solve from the answers as they come in")
 	}
 
 	requestIDs := make([]string, 0, len(requests))
@@ -93,7 +107,13 @@ func fetchGroupAvailability(
 	// answer on record is an answer that still counts.
 	latest, err := database.GetLatestAvailability(ctx, requestIDs, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read availability: %w", err)
+		return nil, nil, fmt.Errorf("failed to read availability: %w", err)
+	}
+
+	// The shifts come in the solver's order, which is date order.
+	absent, _, err := shiftAbsences(ctx, database, orderedShifts)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	volunteersByID := make(map[string]allocator.Volunteer, len(activeVolunteers))
@@ -115,10 +135,14 @@ func fetchGroupAvailability(
 
 		generation, replied := latest[request.ID]
 		entry := AvailabilityEntry{
-			VolunteerID:       request.VolunteerID,
-			VolunteerName:     strings.TrimSpace(volunteer.FirstName + " " + volunteer.LastName),
-			Replied:           replied,
-			AvailableShiftIDs: make([]string, 0, len(generation.Answers)),
+			VolunteerID:         request.VolunteerID,
+			VolunteerName:       strings.TrimSpace(volunteer.FirstName + " " + volunteer.LastName),
+			Replied:             replied,
+			AvailableShiftIDs:   make([]string, 0, len(generation.Answers)),
+			PreferredShiftCount: generation.PreferredShiftCount,
+			// A shift somebody is still paused for is one they are away
+			// for, as far as the group they are in is concerned.
+			AbsentShiftIDs: slices.Concat(absent[request.VolunteerID], pausedShiftIDs[request.VolunteerID]),
 		}
 		for _, answer := range generation.Answers {
 			entry.AvailableShiftIDs = append(entry.AvailableShiftIDs, answer.ShiftID)
@@ -132,16 +156,15 @@ func fetchGroupAvailability(
 	// left false throughout because the allocator resolves closure itself, from
 	// the config overrides — and a volunteer cannot say yes to a closed shift in
 	// the first place, so nothing here can leak one in.
-	shiftOrder := make([]AvailabilityShift, len(orderedShiftIDs))
-	indexByShiftID := make(map[string]int, len(orderedShiftIDs))
-	for i, id := range orderedShiftIDs {
-		shiftOrder[i] = AvailabilityShift{ID: id}
-		indexByShiftID[id] = i
+	indexByShiftID := make(map[string]int, len(orderedShifts))
+	for i, shift := range orderedShifts {
+		indexByShiftID[shift.ID] = i
 	}
 
 	availability := make(map[string][]int, len(entriesByGroup))
+	preferred := make(map[string]int)
 	for key, entries := range entriesByGroup {
-		group := buildAvailabilityGroup(key, entries, shiftOrder)
+		group := buildAvailabilityGroup(key, entries, orderedShifts)
 		if !group.Replied {
 			// Nobody in the group answered. Absent from the map is how that is
 			// told apart from a group that answered "none of these".
@@ -152,9 +175,66 @@ func fetchGroupAvailability(
 			indices = append(indices, indexByShiftID[shiftID])
 		}
 		availability[key] = indices
+		if group.PreferredShiftCount > 0 {
+			preferred[key] = group.PreferredShiftCount
+		}
 	}
 
-	return availability, nil
+	return availability, preferred, nil
+}
+
+// allocatableVolunteers is the roster as the allocator is to see it over the
+// rota's shifts: everybody volunteering on any of them, with an Onboarding
+// volunteer offered only the last Role they hold. pausedShiftIDs are, by
+// volunteer, the open shifts they are not yet back for, which the solve treats
+// the way it treats an Absence.
+//
+// Lifecycle is judged on the shifts' own dates rather than the day the solve
+// runs: a rota is allocated weeks before it starts, and somebody paused until
+// its first Sunday is on it, while somebody paused until its third is on it
+// only from then.
+//
+// Roles are filled in priority order, so the first a shift fills are the ones
+// it cannot run without — a Team lead, say. Somebody still being shown the
+// ropes should not be made that person by a solver that cannot know they are
+// new, even if the sheet already has them down for it for when they are ready;
+// the last Role they hold is the one any shift has others beside them in. An
+// Admin who wants them in another is free to pin them there.
+func allocatableVolunteers(volunteers []model.Volunteer, shifts []AvailabilityShift) (result []model.Volunteer, pausedShiftIDs map[string][]string) {
+	result = make([]model.Volunteer, 0, len(volunteers))
+	pausedShiftIDs = make(map[string][]string)
+	for _, v := range volunteers {
+		status := v.Lifecycle()
+		var state model.VolunteerState
+		var paused []string
+		for _, shift := range shifts {
+			date, err := time.Parse(time.DateOnly, shift.Date)
+			if err != nil {
+				continue
+			}
+			on := status.On(date)
+			if !on.Volunteering() {
+				if !shift.Closed {
+					paused = append(paused, shift.ID)
+				}
+				continue
+			}
+			if state == "" {
+				state = on
+			}
+		}
+		if state == "" {
+			continue
+		}
+		if state == model.StateOnboarding && len(v.Roles) > 1 {
+			v.Roles = v.Roles[len(v.Roles)-1:]
+		}
+		if len(paused) > 0 {
+			pausedShiftIDs[v.ID] = paused
+		}
+		result = append(result, v)
+	}
+	return result, pausedShiftIDs
 }
 
 // convertToAllocatorVolunteers converts model.Volunteer to allocator.Volunteer
@@ -210,55 +290,137 @@ func convertToDBAllocations(shiftIDByDate map[string]string, shifts []*allocator
 	return allocations, nil
 }
 
-// buildHistoricalShifts fetches allocations from the previous rota, applies that
-// rota's alterations (covers/swaps) so history reflects who actually worked, and
-// builds historical shift objects sorted ascending by date. Only includes Date
-// and AllocatedGroups fields. Callers pass ALL volunteers (inactive included) so
-// shifts worked by now-inactive volunteers keep their groups — dropping them
-// would shift the back-to-back boundary onto an earlier date. Allocations whose
-// volunteer id is unknown (deleted from the sheet) and custom entries are
-// skipped; a date is still emitted even if no groups remain.
+// dropNoShows takes everybody recorded as a no-show off the shifts they did
+// not turn up to. Left early is still a shift worked, and is kept. Every shift
+// it was given is in what it returns, if only with nobody on it: a shift where
+// nobody turned up still ran on its date, and history's back-to-back boundary
+// is read from the dates.
+func dropNoShows(
+	ctx context.Context,
+	database SolveRotaStore,
+	allocationsByShiftID map[string][]db.Allocation,
+	shiftIDs []string,
+) (map[string][]db.Allocation, error) {
+	recorded, err := database.GetAttendanceByShiftIDs(ctx, shiftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attendance: %w", err)
+	}
+	noShow := make(map[string]map[string]bool)
+	for _, a := range recorded {
+		if a.Status != db.AttendanceNoShow {
+			continue
+		}
+		if noShow[a.ShiftID] == nil {
+			noShow[a.ShiftID] = make(map[string]bool)
+		}
+		noShow[a.ShiftID][a.VolunteerID] = true
+	}
+
+	kept := make(map[string][]db.Allocation, len(allocationsByShiftID))
+	for shiftID, allocations := range allocationsByShiftID {
+		kept[shiftID] = make([]db.Allocation, 0, len(allocations))
+		for _, a := range allocations {
+			if a.VolunteerID != "" && noShow[shiftID][a.VolunteerID] {
+				continue
+			}
+			kept[shiftID] = append(kept[shiftID], a)
+		}
+	}
+	return kept, nil
+}
+
+// buildHistoricalShifts fetches allocations from the rotas inside the fairness
+// horizon, applies their alterations (covers/swaps) so history reflects who
+// actually worked, and builds historical shift objects sorted ascending by
+// date. Only includes Date, AllocatedGroups and HistoryWeight. Callers pass ALL
+// volunteers (inactive included) so shifts worked by now-inactive volunteers
+// keep their groups — dropping them would shift the back-to-back boundary onto
+// an earlier date. Allocations whose volunteer id is unknown (deleted from the
+// sheet) and custom entries are skipped; a date is still emitted even if no
+// groups remain.
+//
+// The horizon is the Allocation Settings' (model.AllocationSettings
+// HistoryRotas/HistoryMonths): the previous rota alone unless an admin has said
+// otherwise, which is all history ever was. Only fairness reads further back
+// than the previous rota — no_back_to_back reads the last historical shift and
+// one_shift_per_month the months the target rota shares with it, and a longer
+// horizon changes neither — so each shift carries the weight fairness gives
+// it, by how many rotas back it sits.
+//
+// With FairnessSkipsNoShows on, a volunteer recorded as a no-show is taken off
+// that shift before any of this reads it (dropNoShows).
 func buildHistoricalShifts(
 	ctx context.Context,
 	database SolveRotaStore,
 	allRotations []db.Rotation,
 	targetRota *db.Rotation,
 	volunteers []allocator.Volunteer,
+	settings model.AllocationSettings,
 	logger *zap.Logger,
 ) ([]*allocator.Shift, error) {
-	// Find the previous rota (the one before the target rota)
-	previousRota := findPreviousRotation(allRotations, targetRota)
-	if previousRota == nil {
+	previousRotas := findPreviousRotations(allRotations, targetRota)
+	if len(previousRotas) == 0 {
 		logger.Info("No previous rota found, historical shifts will be empty")
 		return []*allocator.Shift{}, nil
 	}
 
-	logger.Debug("Found previous rota",
-		zap.String("id", previousRota.ID),
-		zap.String("start", previousRota.Start))
-
-	// Read the previous rota's shifts to scope its allocations/alterations by id
-	// and to recover each shift's date for the historical output (ADR 0001).
-	previousRotaShifts, err := database.GetShiftsByRotaID(ctx, previousRota.ID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+	// Counted in months, the horizon is a date: shifts before it are out,
+	// whichever rota they belong to. Counted in rotas, it is that many of the
+	// most recent ones, whole.
+	var cutoff string
+	if months := settings.HistoryMonths(); months > 0 {
+		start, err := time.Parse("2006-01-02", targetRota.Start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse rota start %q: %w", targetRota.Start, err)
+		}
+		cutoff = start.AddDate(0, -months, 0).Format("2006-01-02")
+	} else if rotas := settings.HistoryRotas(); len(previousRotas) > rotas {
+		previousRotas = previousRotas[:rotas]
 	}
-	shiftIDs := make([]string, len(previousRotaShifts))
-	dateByShiftID := make(map[string]string, len(previousRotaShifts))
-	for i, s := range previousRotaShifts {
-		shiftIDs[i] = s.ID
-		dateByShiftID[s.ID] = s.Date
+
+	// Read each rota's shifts to scope its allocations/alterations by id and to
+	// recover each shift's date for the historical output (ADR 0001). Newest
+	// first, so a rota's position is its age.
+	var shiftIDs []string
+	dateByShiftID := make(map[string]string)
+	weightByShiftID := make(map[string]float64)
+	for age, rota := range previousRotas {
+		rotaShifts, err := database.GetShiftsByRotaID(ctx, rota.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+		}
+		for _, s := range rotaShifts {
+			if s.Date < cutoff {
+				continue
+			}
+			shiftIDs = append(shiftIDs, s.ID)
+			dateByShiftID[s.ID] = s.Date
+			weightByShiftID[s.ID] = settings.HistoryWeight(age)
+		}
+
+		logger.Debug("Read historical rota",
+			zap.String("id", rota.ID),
+			zap.String("start", rota.Start),
+			zap.Int("age", age))
+
+		// Rotas do not overlap, so once one starts before the cutoff every
+		// older one ends before it.
+		if rota.Start < cutoff {
+			break
+		}
 	}
 
-	// Fetch the previous rota's allocations
+	// Fetch the horizon's allocations
 	previousRotaAllocations, err := database.GetAllocationsByShiftIDs(ctx, shiftIDs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
 	}
-	logger.Debug("Fetched allocations from previous rota", zap.Int("count", len(previousRotaAllocations)))
+	logger.Debug("Fetched historical allocations",
+		zap.Int("rotas", len(previousRotas)),
+		zap.Int("count", len(previousRotaAllocations)))
 
 	if len(previousRotaAllocations) == 0 {
-		logger.Info("No allocations found in previous rota")
+		logger.Info("No allocations found in previous rotas")
 		return []*allocator.Shift{}, nil
 	}
 
@@ -269,7 +431,7 @@ func buildHistoricalShifts(
 		allocationsByShiftID[allocation.ShiftID] = append(allocationsByShiftID[allocation.ShiftID], allocation)
 	}
 
-	// Apply the previous rota's alterations so history reflects who
+	// Apply the horizon's alterations so history reflects who
 	// actually worked (covers and swaps), not the rota as first published.
 	previousRotaAlterations, err := database.GetAlterationsByShiftIDs(ctx, shiftIDs)
 	if err != nil {
@@ -278,6 +440,15 @@ func buildHistoricalShifts(
 	logger.Debug("Applying alterations to historical shifts", zap.Int("count", len(previousRotaAlterations)))
 	allocationsByShiftID = utils.ApplyAlterations(allocationsByShiftID, previousRotaAlterations)
 
+	// A shift somebody was recorded as not turning up to is, when an admin
+	// has said so, not one they worked either.
+	if settings.FairnessSkipsNoShows {
+		allocationsByShiftID, err = dropNoShows(ctx, database, allocationsByShiftID, shiftIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Build a map of volunteers by ID for quick lookup
 	volunteersByID := make(map[string]allocator.Volunteer)
 	for _, vol := range volunteers {
@@ -312,10 +483,11 @@ func buildHistoricalShifts(
 			allocatedGroups = append(allocatedGroups, group)
 		}
 
-		// Create the historical shift with only Date and AllocatedGroups
+		// Create the historical shift with only Date, AllocatedGroups and HistoryWeight
 		historicalShifts = append(historicalShifts, &allocator.Shift{
 			Date:            dateByShiftID[shiftID],
 			AllocatedGroups: allocatedGroups,
+			HistoryWeight:   weightByShiftID[shiftID],
 		})
 	}
 
@@ -465,18 +637,16 @@ func checkPreallocationsResolve(
 	return nil
 }
 
-// findPreviousRotation finds the rotation immediately before the target rotation
-func findPreviousRotation(rotations []db.Rotation, targetRota *db.Rotation) *db.Rotation {
+// findPreviousRotations finds the rotations that start before the target
+// rotation, most recent first.
+func findPreviousRotations(rotations []db.Rotation, targetRota *db.Rotation) []db.Rotation {
 	targetDate, err := time.Parse("2006-01-02", targetRota.Start)
 	if err != nil {
 		return nil
 	}
 
-	var previousRota *db.Rotation
-	var previousDate time.Time
-
-	for i := range rotations {
-		rota := &rotations[i]
+	var previous []db.Rotation
+	for _, rota := range rotations {
 		if rota.ID == targetRota.ID {
 			continue
 		}
@@ -488,13 +658,13 @@ func findPreviousRotation(rotations []db.Rotation, targetRota *db.Rotation) *db.
 
 		// Only consider rotas that start before the target rota
 		if rotaDate.Before(targetDate) {
-			// If this is our first match or it's more recent than our current previous
-			if previousRota == nil || rotaDate.After(previousDate) {
-				previousRota = rota
-				previousDate = rotaDate
-			}
+			previous = append(previous, rota)
 		}
 	}
 
-	return previousRota
+	// The dates parsed, so they are ISO and compare as strings.
+	sort.SliceStable(previous, func(i, j int) bool {
+		return previous[i].Start > previous[j].Start
+	})
+	return previous
 }
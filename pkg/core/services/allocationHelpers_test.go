@@ -120,6 +120,9 @@ type mockAllocateRotaStore struct {
 	allocations              []db.Allocation
 	alterations              []db.Alteration
 	manualPreallocations     []db.Preallocation
+	pairingRules             []db.PairingRule
+	attendance               []db.Attendance
+	absences                 []db.Absence
 	insertedAllocations      []db.Allocation
 	storedDrafts             []db.DraftRotaAllocation
 	storedDraftSeats         [][]db.DraftAllocation
@@ -210,6 +213,31 @@ func (m *mockAllocateRotaStore) GetAlterationsByShiftIDs(ctx context.Context, sh
 	return filtered, nil
 }
 
+func (m *mockAllocateRotaStore) GetPairingRules(context.Context) ([]db.PairingRule, error) {
+	return m.pairingRules, nil
+}
+
+func (m *mockAllocateRotaStore) GetAbsencesFrom(_ context.Context, date string) ([]db.Absence, error) {
+	var out []db.Absence
+	for _, a := range m.absences {
+		if a.To >= date {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAllocateRotaStore) GetAttendanceByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Attendance, error) {
+	want := idSet(shiftIDs)
+	var filtered []db.Attendance
+	for _, a := range m.attendance {
+		if want[a.ShiftID] {
+			filtered = append(filtered, a)
+		}
+	}
+	return filtered, nil
+}
+
 func (m *mockAllocateRotaStore) GetPreallocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Preallocation, error) {
 	if m.getPreallocationsErr != nil {
 		return nil, m.getPreallocationsErr
@@ -397,6 +425,76 @@ func TestFilterActiveVols(t *testing.T) {
 	assert.Equal(t, "charlie", active[1].ID)
 }
 
+func TestAllocatableVolunteers(t *testing.T) {
+	volunteers := []model.Volunteer{
+		{ID: "alice", Status: "Active", Roles: []string{"Team lead", "Service volunteer"}},
+		{ID: "bob", Status: "Onboarding", Roles: []string{"Team lead", "Service volunteer"}},
+		{ID: "charlie", Status: "Paused until 2026-03-01", Roles: []string{"Service volunteer"}},
+		{ID: "diana", Status: "Paused until 2026-02-01", Roles: []string{"Service volunteer"}},
+		{ID: "emma", Status: "Left", Roles: []string{"Service volunteer"}},
+	}
+	shifts := []AvailabilityShift{{ID: "s1", Date: "2026-02-15"}, {ID: "s2", Date: "2026-02-22"}}
+
+	allocatable, paused := allocatableVolunteers(volunteers, shifts)
+	require.Len(t, allocatable, 3)
+	assert.Equal(t, []string{"alice", "bob", "diana"}, utils.GetVolunteerIDs(allocatable))
+	assert.Equal(t, []string{"Team lead", "Service volunteer"}, allocatable[0].Roles)
+	assert.Equal(t, []string{"Service volunteer"}, allocatable[1].Roles, "somebody onboarding is not made the lead")
+	assert.Equal(t, []string{"Team lead", "Service volunteer"}, volunteers[1].Roles, "the roster itself is left alone")
+	assert.Empty(t, paused)
+}
+
+// A rota is solved weeks before it starts. What counts is whether somebody is
+// back by its shifts, not whether they are back on the day an Admin solves it.
+func TestAllocatableVolunteers_PauseAgainstTheRota(t *testing.T) {
+	shifts := []AvailabilityShift{
+		{ID: "s1", Date: "2026-03-01"},
+		{ID: "s2", Date: "2026-03-08", Closed: true},
+		{ID: "s3", Date: "2026-03-15"},
+		{ID: "s4", Date: "2026-03-22"},
+	}
+
+	tests := []struct {
+		name       string
+		status     string
+		wantIn     bool
+		wantPaused []string
+	}{
+		{name: "back before the rota starts", status: "Paused until 2026-02-20", wantIn: true},
+		{name: "back on its first shift", status: "Paused until 2026-03-01", wantIn: true},
+		{name: "back part way through", status: "Paused until 2026-03-15", wantIn: true, wantPaused: []string{"s1"}},
+		{name: "back after its last shift", status: "Paused until 2026-04-01", wantIn: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volunteers := []model.Volunteer{{ID: "alice", Status: tt.status, Roles: []string{"Service volunteer"}}}
+
+			allocatable, paused := allocatableVolunteers(volunteers, shifts)
+			if !tt.wantIn {
+				assert.Empty(t, allocatable)
+				return
+			}
+			require.Len(t, allocatable, 1)
+			assert.Equal(t, tt.wantPaused, paused["alice"], "a closed shift is nobody's to be away for")
+		})
+	}
+}
+
+// A shift somebody is still paused for is a no, however they answered: they
+// may well have said yes to the whole rota before their pause was put in.
+func TestFetchGroupAvailability_PausedShiftsAreANo(t *testing.T) {
+	store := availabilityRound(map[string][]string{"alice": availabilityShiftIDs})
+	volunteers := []allocator.Volunteer{{ID: "alice", FirstName: "Alice", LastName: "Smith"}}
+	paused := map[string][]string{"alice": {availabilityShiftIDs[0]}}
+
+	availability, _, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", volunteers, availabilityShiftOrder(), paused, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.NotContains(t, availability["Alice Smith"], 0)
+	assert.Contains(t, availability["Alice Smith"], 1)
+}
+
 func TestBuildHistoricalShifts_SkipsUnknownVolunteers(t *testing.T) {
 	// Allocations whose volunteer id no longer exists in the sheet are
 	// skipped, but the shift itself is still emitted. (Inactive volunteers
@@ -439,7 +537,7 @@ func TestBuildHistoricalShifts_SkipsUnknownVolunteers(t *testing.T) {
 	targetRota := &db.Rotation{ID: "rota-1", Start: "2025-01-05", ShiftCount: 2}
 
 	// Call buildHistoricalShifts
-	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, volunteers, logger)
+	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, volunteers, model.AllocationSettings{}, logger)
 	require.NoError(t, err)
 
 	// Assertions
@@ -503,7 +601,7 @@ func TestBuildHistoricalShifts_KeepsShiftsWithNoKnownVolunteers(t *testing.T) {
 
 	targetRota := &db.Rotation{ID: "rota-1", Start: "2025-01-05", ShiftCount: 3}
 
-	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, volunteers, logger)
+	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, volunteers, model.AllocationSettings{}, logger)
 	require.NoError(t, err)
 	require.Len(t, historicalShifts, 3)
 
@@ -551,7 +649,7 @@ func TestBuildHistoricalShifts_AppliesAlterations(t *testing.T) {
 
 	targetRota := &db.Rotation{ID: "rota-1", Start: "2025-01-05", ShiftCount: 2}
 
-	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, logger)
+	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, model.AllocationSettings{}, logger)
 	require.NoError(t, err)
 	require.Len(t, historicalShifts, 2)
 
@@ -598,7 +696,7 @@ func TestBuildHistoricalShifts_UngroupedVolunteersAreSeparateGroups(t *testing.T
 
 	targetRota := &db.Rotation{ID: "rota-1", Start: "2025-01-05", ShiftCount: 1}
 
-	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, volunteers, logger)
+	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, volunteers, model.AllocationSettings{}, logger)
 	require.NoError(t, err)
 	require.Len(t, historicalShifts, 1)
 
@@ -615,6 +713,87 @@ func TestBuildHistoricalShifts_UngroupedVolunteersAreSeparateGroups(t *testing.T
 	}
 }
 
+// A no-show is a shift somebody did not work, and with the setting on history
+// says so; left early still counts, and with the setting off nothing recorded
+// moves history at all.
+func TestBuildHistoricalShifts_NoShows(t *testing.T) {
+	store := &mockAllocateRotaStore{
+		rotations: []db.Rotation{
+			{ID: "rota-0", Start: "2024-12-01", ShiftCount: 1},
+			{ID: "rota-1", Start: "2025-01-05", ShiftCount: 1},
+		},
+		shifts: shiftsOnDates("rota-0", "2024-12-01"),
+		allocations: []db.Allocation{
+			{ID: "alloc-1", ShiftID: "2024-12-01", VolunteerID: "alice", Role: "Service volunteer"},
+			{ID: "alloc-2", ShiftID: "2024-12-01", VolunteerID: "bob", Role: "Service volunteer"},
+			{ID: "alloc-3", ShiftID: "2024-12-01", VolunteerID: "dave", Role: "Service volunteer"},
+		},
+		attendance: []db.Attendance{
+			{ShiftID: "2024-12-01", VolunteerID: "alice", Status: db.AttendanceNoShow},
+			{ShiftID: "2024-12-01", VolunteerID: "bob", Status: db.AttendanceLeftEarly},
+		},
+	}
+	volunteers := []allocator.Volunteer{
+		{ID: "alice", FirstName: "Alice", LastName: "A"},
+		{ID: "bob", FirstName: "Bob", LastName: "B"},
+		{ID: "dave", FirstName: "Dave", LastName: "D"},
+	}
+	targetRota := &db.Rotation{ID: "rota-1", Start: "2025-01-05", ShiftCount: 1}
+
+	tests := []struct {
+		name     string
+		settings model.AllocationSettings
+		want     []string
+	}{
+		{name: "off", settings: model.AllocationSettings{}, want: []string{"Alice A", "Bob B", "Dave D"}},
+		{name: "on", settings: model.AllocationSettings{FairnessSkipsNoShows: true}, want: []string{"Bob B", "Dave D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			historicalShifts, err := buildHistoricalShifts(context.Background(), store, store.rotations, targetRota, volunteers, tt.settings, zap.NewNop())
+			require.NoError(t, err)
+			require.Len(t, historicalShifts, 1)
+
+			var keys []string
+			for _, group := range historicalShifts[0].AllocatedGroups {
+				keys = append(keys, group.GroupKey)
+			}
+			assert.ElementsMatch(t, tt.want, keys)
+		})
+	}
+}
+
+// A shift nobody turned up to still happened, and keeps its date in history
+// with nobody on it, as a shift nobody was allocated to does.
+func TestBuildHistoricalShifts_EverybodyANoShow(t *testing.T) {
+	store := &mockAllocateRotaStore{
+		rotations: []db.Rotation{
+			{ID: "rota-0", Start: "2024-12-01", ShiftCount: 2},
+			{ID: "rota-1", Start: "2025-01-05", ShiftCount: 1},
+		},
+		shifts: shiftsOnDates("rota-0", "2024-12-01", "2024-12-08"),
+		allocations: []db.Allocation{
+			{ID: "alloc-1", ShiftID: "2024-12-01", VolunteerID: "bob", Role: "Service volunteer"},
+			{ID: "alloc-2", ShiftID: "2024-12-08", VolunteerID: "alice", Role: "Service volunteer"},
+		},
+		attendance: []db.Attendance{
+			{ShiftID: "2024-12-08", VolunteerID: "alice", Status: db.AttendanceNoShow},
+		},
+	}
+	volunteers := []allocator.Volunteer{
+		{ID: "alice", FirstName: "Alice", LastName: "A"},
+		{ID: "bob", FirstName: "Bob", LastName: "B"},
+	}
+	targetRota := &db.Rotation{ID: "rota-1", Start: "2025-01-05", ShiftCount: 1}
+	settings := model.AllocationSettings{FairnessSkipsNoShows: true}
+
+	historicalShifts, err := buildHistoricalShifts(context.Background(), store, store.rotations, targetRota, volunteers, settings, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, historicalShifts, 2)
+	assert.Equal(t, "2024-12-08", historicalShifts[1].Date)
+	assert.Empty(t, historicalShifts[1].AllocatedGroups)
+}
+
 func TestBuildHistoricalShifts_NoPreviousRota(t *testing.T) {
 	// Test that buildHistoricalShifts returns empty array when there's no previous rota
 	ctx := context.Background()
@@ -631,7 +810,7 @@ func TestBuildHistoricalShifts_NoPreviousRota(t *testing.T) {
 		{ID: "alice", FirstName: "Alice", LastName: "A", Gender: "Female"},
 	}
 
-	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, logger)
+	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, model.AllocationSettings{}, logger)
 	require.NoError(t, err)
 	assert.Empty(t, historicalShifts, "Should have no historical shifts when there's no previous rota")
 }
@@ -654,7 +833,7 @@ func TestBuildHistoricalShifts_NoPreviousAllocations(t *testing.T) {
 		{ID: "alice", FirstName: "Alice", LastName: "A", Gender: "Female"},
 	}
 
-	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, logger)
+	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, model.AllocationSettings{}, logger)
 	require.NoError(t, err)
 	assert.Empty(t, historicalShifts, "Should have no historical shifts when previous rota has no allocations")
 }
@@ -684,7 +863,7 @@ func TestBuildHistoricalShifts_CustomEntriesIgnored(t *testing.T) {
 
 	targetRota := &db.Rotation{ID: "rota-1", Start: "2025-01-05", ShiftCount: 1}
 
-	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, logger)
+	historicalShifts, err := buildHistoricalShifts(ctx, store, store.rotations, targetRota, activeVolunteers, model.AllocationSettings{}, logger)
 	require.NoError(t, err)
 	require.Len(t, historicalShifts, 1)
 
@@ -693,10 +872,108 @@ func TestBuildHistoricalShifts_CustomEntriesIgnored(t *testing.T) {
 	assert.Equal(t, "Alice A", historicalShifts[0].AllocatedGroups[0].GroupKey)
 }
 
+// historyRotas is four monthly rotas of two shifts each, Alice on every one,
+// before the rota being allocated.
+func historyRotas() *mockAllocateRotaStore {
+	store := &mockAllocateRotaStore{
+		rotations: []db.Rotation{
+			{ID: "rota-0", Start: "2024-10-06", ShiftCount: 2},
+			{ID: "rota-1", Start: "2024-11-03", ShiftCount: 2},
+			{ID: "rota-2", Start: "2024-12-01", ShiftCount: 2},
+			{ID: "rota-3", Start: "2025-01-05", ShiftCount: 2},
+			{ID: "rota-4", Start: "2025-02-02", ShiftCount: 2},
+		},
+	}
+	for _, rota := range store.rotations[:4] {
+		start, _ := time.Parse("2006-01-02", rota.Start)
+		dates := []string{rota.Start, start.AddDate(0, 0, 7).Format("2006-01-02")}
+		store.shifts = append(store.shifts, shiftsOnDates(rota.ID, dates...)...)
+		for _, date := range dates {
+			store.allocations = append(store.allocations, db.Allocation{
+				ID: "alloc-" + date, ShiftID: date, VolunteerID: "alice", Role: "Service volunteer",
+			})
+		}
+	}
+	return store
+}
+
+func TestBuildHistoricalShifts_FairnessHorizon(t *testing.T) {
+	volunteers := []allocator.Volunteer{
+		{ID: "alice", FirstName: "Alice", LastName: "A", Gender: "Female"},
+	}
+	targetRota := &db.Rotation{ID: "rota-4", Start: "2025-02-02", ShiftCount: 2}
+
+	tests := []struct {
+		name     string
+		settings model.AllocationSettings
+		weights  map[string]float64
+	}{
+		{
+			name:     "unset is the previous rota alone",
+			settings: model.AllocationSettings{},
+			weights:  map[string]float64{"2025-01-05": 1, "2025-01-12": 1},
+		},
+		{
+			name:     "the last so many rotas",
+			settings: model.AllocationSettings{FairnessRotas: 2},
+			weights: map[string]float64{
+				"2024-12-01": 1, "2024-12-08": 1,
+				"2025-01-05": 1, "2025-01-12": 1,
+			},
+		},
+		{
+			name:     "the last so many months, cutting a rota in half",
+			settings: model.AllocationSettings{FairnessMonths: 2},
+			weights: map[string]float64{
+				"2024-12-08": 1,
+				"2025-01-05": 1, "2025-01-12": 1,
+			},
+		},
+		{
+			name:     "each rota further back counts the decay once more",
+			settings: model.AllocationSettings{FairnessRotas: 3, FairnessDecay: 0.5},
+			weights: map[string]float64{
+				"2024-11-03": 0.25, "2024-11-10": 0.25,
+				"2024-12-01": 0.5, "2024-12-08": 0.5,
+				"2025-01-05": 1, "2025-01-12": 1,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := historyRotas()
+
+			historicalShifts, err := buildHistoricalShifts(
+				context.Background(), store, store.rotations, targetRota, volunteers, tt.settings, zap.NewNop())
+			require.NoError(t, err)
+
+			weights := make(map[string]float64, len(historicalShifts))
+			for i, shift := range historicalShifts {
+				weights[shift.Date] = shift.HistoryWeight
+				if i > 0 {
+					assert.Less(t, historicalShifts[i-1].Date, shift.Date, "sorted ascending by date")
+				}
+			}
+			assert.Equal(t, tt.weights, weights)
+		})
+	}
+}
+
 // availabilityShiftIDs are the shift ids of a three-shift rota, in the order
 // the solver indexes them.
 var availabilityShiftIDs = []string{"2026-08-02", "2026-08-09", "2026-08-16"}
 
+// availabilityShiftOrder is those shifts as the solver orders them, each id
+// being its own date.
+func availabilityShiftOrder() []AvailabilityShift {
+	shifts := make([]AvailabilityShift, len(availabilityShiftIDs))
+	for i, id := range availabilityShiftIDs {
+		shifts[i] = AvailabilityShift{ID: id, Date: id}
+	}
+	return shifts
+}
+
 // availabilityRound sets a store up with one minted request per volunteer and
 // the generations behind them, so the group rule can be exercised against the
 // store the allocator now reads.
@@ -758,8 +1035,8 @@ func TestFetchGroupAvailability_GroupRule(t *testing.T) {
 		{ID: "silent", FirstName: "Silent", LastName: "Brown"},
 	}
 
-	availability, err := fetchGroupAvailability(
-		context.Background(), store, "rota-1", volunteers, availabilityShiftIDs, zap.NewNop())
+	availability, _, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", volunteers, availabilityShiftOrder(), nil, zap.NewNop())
 	require.NoError(t, err)
 
 	assert.Equal(t, []int{0}, availability["couple_me"])
@@ -776,8 +1053,8 @@ func TestFetchGroupAvailability_AnsweredWithNothing(t *testing.T) {
 	store := availabilityRound(map[string][]string{"nobody": {}})
 	volunteers := []allocator.Volunteer{{ID: "nobody", FirstName: "No", LastName: "Body"}}
 
-	availability, err := fetchGroupAvailability(
-		context.Background(), store, "rota-1", volunteers, availabilityShiftIDs, zap.NewNop())
+	availability, _, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", volunteers, availabilityShiftOrder(), nil, zap.NewNop())
 	require.NoError(t, err)
 
 	indices, present := availability["No Body"]
@@ -799,8 +1076,8 @@ func TestFetchGroupAvailability_IgnoresInactiveVolunteers(t *testing.T) {
 		{ID: "michael", FirstName: "Michael", LastName: "Smith", GroupKey: "couple_me"},
 	}
 
-	availability, err := fetchGroupAvailability(
-		context.Background(), store, "rota-1", volunteers, availabilityShiftIDs, zap.NewNop())
+	availability, _, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", volunteers, availabilityShiftOrder(), nil, zap.NewNop())
 	require.NoError(t, err)
 
 	assert.Equal(t, []int{0, 1}, availability["couple_me"],
@@ -815,13 +1092,48 @@ func TestFetchGroupAvailability_IndicesFollowShiftOrder(t *testing.T) {
 	})
 	volunteers := []allocator.Volunteer{{ID: "vol", FirstName: "Vol", LastName: "Unteer"}}
 
-	availability, err := fetchGroupAvailability(
-		context.Background(), store, "rota-1", volunteers, availabilityShiftIDs, zap.NewNop())
+	availability, _, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", volunteers, availabilityShiftOrder(), nil, zap.NewNop())
 	require.NoError(t, err)
 
 	assert.Equal(t, []int{0, 2}, availability["Vol Unteer"])
 }
 
+// A group's preferred shift count is the least any responder asked for, and a
+// group where nobody gave one is left out, which is how "no preference" reaches
+// the solver.
+func TestFetchGroupAvailability_PreferredShiftCount(t *testing.T) {
+	store := availabilityRound(map[string][]string{
+		"michael": availabilityShiftIDs,
+		"emma":    availabilityShiftIDs,
+		"jack":    availabilityShiftIDs,
+		"kate":    availabilityShiftIDs,
+		"lonely":  availabilityShiftIDs,
+	})
+	prefer := func(volunteerID string, count int) {
+		generation := store.generations["req-"+volunteerID]
+		generation.PreferredShiftCount = count
+		store.generations["req-"+volunteerID] = generation
+	}
+	prefer("michael", 2)
+	prefer("emma", 1)
+	prefer("jack", 2) // Kate gave no number, which does not lift Jack's
+
+	volunteers := []allocator.Volunteer{
+		{ID: "michael", FirstName: "Michael", LastName: "Smith", GroupKey: "couple_me"},
+		{ID: "emma", FirstName: "Emma", LastName: "Williams", GroupKey: "couple_me"},
+		{ID: "jack", FirstName: "Jack", LastName: "Green", GroupKey: "couple_jk"},
+		{ID: "kate", FirstName: "Kate", LastName: "Green", GroupKey: "couple_jk"},
+		{ID: "lonely", FirstName: "Lonely", LastName: "Jones"},
+	}
+
+	_, preferred, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", volunteers, availabilityShiftOrder(), nil, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]int{"couple_me": 1, "couple_jk": 2}, preferred)
+}
+
 // Allocating a rota nobody was asked about is an operator mistake, not an empty
 // result: it would otherwise solve against silence and produce an empty rota.
 //
@@ -832,9 +1144,35 @@ func TestFetchGroupAvailability_IndicesFollowShiftOrder(t *testing.T) {
 func TestFetchGroupAvailability_NoRoundMinted(t *testing.T) {
 	store := availabilityRound(nil)
 
-	_, err := fetchGroupAvailability(
-		context.Background(), store, "rota-1", nil, availabilityShiftIDs, zap.NewNop())
+	_, _, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", nil, availabilityShiftOrder(), nil, zap.NewNop())
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "availability round")
 	assert.NotContains(t, err.Error(), "rota-1", "no row id in a message an admin reads")
 }
+
+// An Absence is a hard no: it overrides the yes its volunteer gave, and it
+// speaks for their group whether or not they answered.
+func TestFetchGroupAvailability_AbsenceOverridesYes(t *testing.T) {
+	store := availabilityRound(map[string][]string{
+		"michael": availabilityShiftIDs,
+		"emma":    nil,
+		"lonely":  availabilityShiftIDs,
+	})
+	store.absences = []db.Absence{
+		{ID: "a1", VolunteerID: "emma", From: "2026-08-09", To: "2026-08-09"},
+		{ID: "a2", VolunteerID: "lonely", From: "2026-08-10", To: "2026-12-31"},
+	}
+	volunteers := []allocator.Volunteer{
+		{ID: "michael", FirstName: "Michael", LastName: "Smith", GroupKey: "couple_me"},
+		{ID: "emma", FirstName: "Emma", LastName: "Williams", GroupKey: "couple_me"},
+		{ID: "lonely", FirstName: "Lonely", LastName: "Jones"},
+	}
+
+	availability, _, err := fetchGroupAvailability(
+		context.Background(), store, "rota-1", volunteers, availabilityShiftOrder(), nil, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{0, 2}, availability["couple_me"], "Emma is away on the 9th though Michael said yes for both")
+	assert.Equal(t, []int{0, 1}, availability["Lonely Jones"])
+}
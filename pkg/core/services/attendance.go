@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// AttendanceStore is what recording attendance needs: the shift and who was
+// on it as the rota finally stood, what has been recorded of them, and — for
+// a Team lead recording on their own link — the link and its rota.
+type AttendanceStore interface {
+	RoleStore
+	RotaDefaultsStore
+	GetRotations(ctx context.Context) ([]db.Rotation, error)
+	GetShiftsByRotaID(ctx context.Context, rotaID string) ([]db.Shift, error)
+	GetShiftByID(ctx context.Context, id string) (*db.ShiftInRange, error)
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+	GetAvailabilityRequestByToken(ctx context.Context, token string) (*db.AvailabilityRequest, error)
+	GetAttendanceByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Attendance, error)
+	RecordAttendance(ctx context.Context, a db.Attendance) error
+	ClearAttendance(ctx context.Context, shiftID, volunteerID string) error
+}
+
+// AttendanceParams is somebody saying whether one volunteer turned up to one
+// shift. An empty Status clears what was recorded, for an answer given
+// against the wrong person.
+type AttendanceParams struct {
+	ShiftID     string
+	VolunteerID string
+	Status      string
+	// RecordedBy is an admin's email, or the Team lead's volunteer id on
+	// their link.
+	RecordedBy string
+}
+
+// AttendanceEntry is one volunteer on a shift that has happened, and what has
+// been recorded of them. Status is empty until somebody says.
+type AttendanceEntry struct {
+	VolunteerID    string
+	VolunteerName  string
+	Role           string
+	Status         string
+	RecordedBy     string
+	RecordedByName string
+	RecordedAt     *time.Time
+}
+
+// ShiftAttendance is a shift that has happened and everyone who was on it, as
+// the rota finally stood: the allocation with every change applied, so the
+// person recorded against is the person who was meant to be there. Custom
+// entries are left out — there is nobody on the roster to hold to them.
+type ShiftAttendance struct {
+	ShiftID string
+	Date    string
+	Start   string
+	End     string
+	People  []AttendanceEntry
+}
+
+// LeadAttendancePage is what a Team lead sees behind their link: the shifts
+// of its rota they led that have started, most recent first, each with its
+// volunteers to mark.
+type LeadAttendancePage struct {
+	VolunteerName string
+	Role          string
+	Shifts        []ShiftAttendance
+}
+
+// attendanceStatuses are the answers attendance can be recorded as.
+var attendanceStatuses = map[string]bool{
+	db.AttendanceAttended:  true,
+	db.AttendanceNoShow:    true,
+	db.AttendanceLeftEarly: true,
+}
+
+// attendanceContext is what a shift's attendance is worked out from, read
+// once per request.
+type attendanceContext struct {
+	roles      model.Roles
+	defaults   model.RotaDefaults
+	volunteers map[string]model.Volunteer
+}
+
+func loadAttendanceContext(
+	ctx context.Context,
+	store AttendanceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+) (*attendanceContext, error) {
+	defaults, err := RotaDefaults(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	return &attendanceContext{roles: roles, defaults: defaults, volunteers: volunteersByID(roster)}, nil
+}
+
+// started reports whether a shift has begun, which is when there is anything
+// to say about who turned up.
+func (c *attendanceContext) started(shift db.Shift, now time.Time) (bool, error) {
+	start, _, err := c.defaults.ShiftInstants(shift.StartAt, shift.EndAt)
+	if err != nil {
+		return false, fmt.Errorf("shift %s has no start time to judge attendance against: %w", shift.ID, err)
+	}
+	return !now.Before(start), nil
+}
+
+// view puts a shift, who was on it and what has been recorded together. The
+// volunteers are in the order their Roles' Seats are filled, so the Team lead
+// reads first, and by name within a Role.
+func (c *attendanceContext) view(shift db.Shift, onShift []db.Allocation, recorded []db.Attendance) ShiftAttendance {
+	byVolunteer := make(map[string]db.Attendance, len(recorded))
+	for _, a := range recorded {
+		byVolunteer[a.VolunteerID] = a
+	}
+
+	priority := make(map[string]int)
+	for i, r := range c.roles.ByPriority() {
+		priority[r.Name] = i
+	}
+
+	view := ShiftAttendance{ShiftID: shift.ID, Date: shift.Date, Start: shift.StartAt, End: shift.EndAt, People: []AttendanceEntry{}}
+	for _, a := range onShift {
+		if a.VolunteerID == "" {
+			continue
+		}
+		entry := AttendanceEntry{
+			VolunteerID:   a.VolunteerID,
+			VolunteerName: nameOf(c.volunteers, a.VolunteerID),
+			Role:          a.Role,
+		}
+		if r, ok := byVolunteer[a.VolunteerID]; ok {
+			recordedAt := r.RecordedAt
+			entry.Status = r.Status
+			entry.RecordedBy = r.RecordedBy
+			entry.RecordedByName = nameOf(c.volunteers, r.RecordedBy)
+			entry.RecordedAt = &recordedAt
+		}
+		view.People = append(view.People, entry)
+	}
+	sort.SliceStable(view.People, func(i, j int) bool {
+		pi, pj := priority[view.People[i].Role], priority[view.People[j].Role]
+		if pi != pj {
+			return pi < pj
+		}
+		return view.People[i].VolunteerName < view.People[j].VolunteerName
+	})
+	return view
+}
+
+// attendanceOn reads the shifts' effective rota and their attendance, keyed by
+// shift id.
+func attendanceOn(ctx context.Context, store AttendanceStore, shiftIDs []string) (map[string][]db.Allocation, map[string][]db.Attendance, error) {
+	onShift, err := effectiveAllocations(ctx, store, shiftIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	recorded, err := store.GetAttendanceByShiftIDs(ctx, shiftIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read attendance: %w", err)
+	}
+	byShift := make(map[string][]db.Attendance)
+	for _, a := range recorded {
+		byShift[a.ShiftID] = append(byShift[a.ShiftID], a)
+	}
+	return onShift, byShift, nil
+}
+
+// pastShift is one shift by id, refused unless there is attendance to record
+// on it: allocated, open, and started.
+func (c *attendanceContext) pastShift(ctx context.Context, store AttendanceStore, shiftID string, now time.Time) (*db.ShiftInRange, error) {
+	if _, err := uuid.Parse(shiftID); err != nil {
+		return nil, wrapf(ErrNotFound, "shift %s not found", shiftID)
+	}
+	shift, err := store.GetShiftByID(ctx, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up shift %s: %w", shiftID, err)
+	}
+	if shift == nil {
+		return nil, wrapf(ErrNotFound, "shift %s not found", shiftID)
+	}
+	if !shift.Allocated {
+		return nil, wrapf(ErrConflict, "the rota for %s has not been allocated, so nobody was on the shift", shift.Date)
+	}
+	if shift.Closed {
+		return nil, wrapf(ErrConflict, "the drop-in was closed on %s", shift.Date)
+	}
+	started, err := c.started(shift.Shift, now)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, wrapf(ErrConflict, "the shift on %s has not started yet, so there is no attendance to record", shift.Date)
+	}
+	return shift, nil
+}
+
+// GetShiftAttendance is one shift that has happened, for an admin: everyone
+// who was on it and what has been recorded of them.
+func GetShiftAttendance(
+	ctx context.Context,
+	store AttendanceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	shiftID string,
+	now time.Time,
+) (*ShiftAttendance, error) {
+	c, err := loadAttendanceContext(ctx, store, volunteerClient, cfg)
+	if err != nil {
+		return nil, err
+	}
+	shift, err := c.pastShift(ctx, store, shiftID, now)
+	if err != nil {
+		return nil, err
+	}
+	onShift, recorded, err := attendanceOn(ctx, store, []string{shift.ID})
+	if err != nil {
+		return nil, err
+	}
+	view := c.view(shift.Shift, onShift[shift.ID], recorded[shift.ID])
+	return &view, nil
+}
+
+// RecordAttendance is an admin saying whether a volunteer turned up to a
+// shift that has happened, and answers with the shift as it now stands.
+func RecordAttendance(
+	ctx context.Context,
+	store AttendanceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	params AttendanceParams,
+	now time.Time,
+	logger *zap.Logger,
+) (*ShiftAttendance, error) {
+	c, err := loadAttendanceContext(ctx, store, volunteerClient, cfg)
+	if err != nil {
+		return nil, err
+	}
+	shift, err := c.pastShift(ctx, store, params.ShiftID, now)
+	if err != nil {
+		return nil, err
+	}
+	return c.record(ctx, store, shift.Shift, params, logger)
+}
+
+// record writes one answer on a shift already known to have happened, once
+// the volunteer is known to have been on it, and reads the shift back.
+//
+// Only somebody on the shift as it finally stood can be recorded against.
+// Whoever was taken off it was not expected, and a no-show held against them
+// would count a change of plan as a failure to turn up.
+func (c *attendanceContext) record(
+	ctx context.Context,
+	store AttendanceStore,
+	shift db.Shift,
+	params AttendanceParams,
+	logger *zap.Logger,
+) (*ShiftAttendance, error) {
+	if params.Status != "" && !attendanceStatuses[params.Status] {
+		return nil, wrapf(ErrInvalidInput, "attendance is %q, %q or %q, not %q",
+			db.AttendanceAttended, db.AttendanceNoShow, db.AttendanceLeftEarly, params.Status)
+	}
+
+	onShift, _, err := attendanceOn(ctx, store, []string{shift.ID})
+	if err != nil {
+		return nil, err
+	}
+	if !isOnShift(onShift[shift.ID], params.VolunteerID) {
+		return nil, wrapf(ErrConflict, "volunteer %s was not on the shift on %s", params.VolunteerID, shift.Date)
+	}
+
+	if params.Status == "" {
+		if err := store.ClearAttendance(ctx, shift.ID, params.VolunteerID); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := store.RecordAttendance(ctx, db.Attendance{
+			ShiftID:     shift.ID,
+			VolunteerID: params.VolunteerID,
+			Status:      params.Status,
+			RecordedBy:  params.RecordedBy,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("Attendance recorded",
+		zap.String("shift_id", shift.ID),
+		zap.String("volunteer_id", params.VolunteerID),
+		zap.String("status", params.Status),
+		zap.String("recorded_by", params.RecordedBy))
+
+	onShift, recorded, err := attendanceOn(ctx, store, []string{shift.ID})
+	if err != nil {
+		return nil, err
+	}
+	view := c.view(shift, onShift[shift.ID], recorded[shift.ID])
+	return &view, nil
+}
+
+// leadContext is a Team lead's link resolved to the shifts they led.
+type leadContext struct {
+	*attendanceContext
+	request *db.AvailabilityRequest
+	role    string
+	// led are the link's rota's shifts that have started with its volunteer
+	// on them in the leading Role, most recent first.
+	led      []db.Shift
+	onShift  map[string][]db.Allocation
+	recorded map[string][]db.Attendance
+}
+
+// loadLeadContext resolves a link to the shifts its volunteer led.
+//
+// The link is the volunteer's availability link, as the swap page's is: the
+// one thing a Team lead already holds that says who they are. Leading is
+// holding the first Role by priority on the shift — the Seat filled first is
+// the one whoever is in charge sits in — so a lead can mark the shifts they
+// ran and nobody else's.
+func loadLeadContext(
+	ctx context.Context,
+	store AttendanceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token string,
+	now time.Time,
+) (*leadContext, error) {
+	request, err := store.GetAvailabilityRequestByToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up attendance link: %w", err)
+	}
+	if request == nil {
+		return nil, wrapf(ErrNotFound, "no attendance page for this link")
+	}
+	rota, err := findRotation(ctx, store, request.RotaID)
+	if err != nil {
+		return nil, err
+	}
+	if rota.AllocatedDatetime == "" {
+		return nil, wrapf(ErrConflict, "the rota for this link has not been allocated yet")
+	}
+
+	c, err := loadAttendanceContext(ctx, store, volunteerClient, cfg)
+	if err != nil {
+		return nil, err
+	}
+	lc := &leadContext{attendanceContext: c, request: request}
+	ordered := c.roles.ByPriority()
+	if len(ordered) == 0 {
+		return lc, nil
+	}
+	lc.role = ordered[0].Name
+
+	rotaShifts, err := store.GetShiftsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+	}
+	var past []db.Shift
+	var pastIDs []string
+	for _, s := range rotaShifts {
+		if s.Closed {
+			continue
+		}
+		started, err := c.started(s, now)
+		if err != nil {
+			return nil, err
+		}
+		if started {
+			past = append(past, s)
+			pastIDs = append(pastIDs, s.ID)
+		}
+	}
+
+	lc.onShift, lc.recorded, err = attendanceOn(ctx, store, pastIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range past {
+		for _, a := range lc.onShift[s.ID] {
+			if a.VolunteerID == request.VolunteerID && a.Role == lc.role {
+				lc.led = append(lc.led, s)
+				break
+			}
+		}
+	}
+	sort.Slice(lc.led, func(i, j int) bool { return lc.led[i].Date > lc.led[j].Date })
+	return lc, nil
+}
+
+func (lc *leadContext) page() *LeadAttendancePage {
+	page := &LeadAttendancePage{
+		VolunteerName: nameOf(lc.volunteers, lc.request.VolunteerID),
+		Role:          lc.role,
+		Shifts:        []ShiftAttendance{},
+	}
+	for _, s := range lc.led {
+		page.Shifts = append(page.Shifts, lc.view(s, lc.onShift[s.ID], lc.recorded[s.ID]))
+	}
+	return page
+}
+
+// GetLeadAttendance resolves a Team lead's link to the shifts they led.
+// Somebody who led none sees an empty page rather than a refusal: the link is
+// theirs, there is just nothing on it for them to do.
+func GetLeadAttendance(
+	ctx context.Context,
+	store AttendanceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token string,
+	now time.Time,
+) (*LeadAttendancePage, error) {
+	lc, err := loadLeadContext(ctx, store, volunteerClient, cfg, token, now)
+	if err != nil {
+		return nil, err
+	}
+	return lc.page(), nil
+}
+
+// RecordLeadAttendance is a Team lead saying on their link whether somebody
+// turned up to a shift they led, and answers with their page as it now
+// stands. The answer is recorded as theirs, by volunteer id.
+func RecordLeadAttendance(
+	ctx context.Context,
+	store AttendanceStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token string,
+	params AttendanceParams,
+	now time.Time,
+	logger *zap.Logger,
+) (*LeadAttendancePage, error) {
+	lc, err := loadLeadContext(ctx, store, volunteerClient, cfg, token, now)
+	if err != nil {
+		return nil, err
+	}
+	var shift *db.Shift
+	for i := range lc.led {
+		if lc.led[i].ID == params.ShiftID {
+			shift = &lc.led[i]
+		}
+	}
+	if shift == nil {
+		return nil, wrapf(ErrNotFound, "shift %s is not one this link led", params.ShiftID)
+	}
+
+	params.RecordedBy = lc.request.VolunteerID
+	if _, err := lc.record(ctx, store, *shift, params, logger); err != nil {
+		return nil, err
+	}
+
+	lc.onShift, lc.recorded, err = attendanceOn(ctx, store, shiftIDsOf(lc.led))
+	if err != nil {
+		return nil, err
+	}
+	return lc.page(), nil
+}
+
+func shiftIDsOf(shifts []db.Shift) []string {
+	ids := make([]string, len(shifts))
+	for i, s := range shifts {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+// isOnShift reports whether a volunteer is among a shift's allocations.
+func isOnShift(allocations []db.Allocation, volunteerID string) bool {
+	for _, a := range allocations {
+		if a.VolunteerID != "" && a.VolunteerID == volunteerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+func (m *mockAvailabilityStore) GetAttendanceByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Attendance, error) {
+	want := idSet(shiftIDs)
+	var out []db.Attendance
+	for _, a := range m.attendance {
+		if want[a.ShiftID] {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) RecordAttendance(_ context.Context, a db.Attendance) error {
+	a.RecordedAt = time.Now()
+	for i := range m.attendance {
+		if m.attendance[i].ShiftID == a.ShiftID && m.attendance[i].VolunteerID == a.VolunteerID {
+			m.attendance[i] = a
+			return nil
+		}
+	}
+	m.attendance = append(m.attendance, a)
+	return nil
+}
+
+func (m *mockAvailabilityStore) ClearAttendance(_ context.Context, shiftID, volunteerID string) error {
+	kept := m.attendance[:0]
+	for _, a := range m.attendance {
+		if a.ShiftID != shiftID || a.VolunteerID != volunteerID {
+			kept = append(kept, a)
+		}
+	}
+	m.attendance = kept
+	return nil
+}
+
+// The swap store's shifts under UUIDs, because attendance is recorded against
+// a shift's id and Postgres would refuse anything else.
+const (
+	attendanceShift0 = "0a7e1d2c-0000-4000-8000-000000000000"
+	attendanceShift1 = "0a7e1d2c-0000-4000-8000-000000000001"
+	attendanceShift2 = "0a7e1d2c-0000-4000-8000-000000000002"
+)
+
+// attendanceNow is the morning after Michael led the second shift: the first
+// two have happened and the third is a week off.
+var attendanceNow = time.Date(2026, 8, 3, 9, 0, 0, 0, time.UTC)
+
+// attendanceStore is swapStore with every shift timed and under its UUID.
+// Sara worked the first shift alone; Michael led the second with Sara beside
+// him; Emma is on the third.
+func attendanceStore() *mockAvailabilityStore {
+	store := swapStore()
+	ids := map[string]string{"shift-0": attendanceShift0, "shift-1": attendanceShift1, "shift-2": attendanceShift2}
+	for i := range store.shifts {
+		s := &store.shifts[i]
+		s.ID = ids[s.ID]
+		s.StartAt, s.EndAt = s.Date+"T18:30:00", s.Date+"T21:00:00"
+	}
+	for i := range store.allocations {
+		store.allocations[i].ShiftID = ids[store.allocations[i].ShiftID]
+	}
+	return store
+}
+
+func recordAttendance(store *mockAvailabilityStore, shiftID, volunteerID, status string) (*ShiftAttendance, error) {
+	return RecordAttendance(context.Background(), store, swapVolunteers(), sendTestCfg, AttendanceParams{
+		ShiftID:     shiftID,
+		VolunteerID: volunteerID,
+		Status:      status,
+		RecordedBy:  "admin@example.com",
+	}, attendanceNow, zap.NewNop())
+}
+
+func TestRecordAttendance(t *testing.T) {
+	store := attendanceStore()
+
+	view, err := GetShiftAttendance(context.Background(), store, swapVolunteers(), sendTestCfg, attendanceShift1, attendanceNow)
+	require.NoError(t, err)
+	require.Len(t, view.People, 2)
+	assert.Equal(t, "michael", view.People[0].VolunteerID, "the Team lead reads first")
+	assert.Empty(t, view.People[1].Status, "nothing is recorded until somebody says")
+
+	view, err = recordAttendance(store, attendanceShift1, "sara", db.AttendanceNoShow)
+	require.NoError(t, err)
+	assert.Equal(t, db.AttendanceNoShow, view.People[1].Status)
+	assert.Equal(t, "admin@example.com", view.People[1].RecordedBy)
+	require.NotNil(t, view.People[1].RecordedAt)
+
+	// A correction replaces the answer rather than adding a second.
+	_, err = recordAttendance(store, attendanceShift1, "sara", db.AttendanceLeftEarly)
+	require.NoError(t, err)
+	require.Len(t, store.attendance, 1)
+	assert.Equal(t, db.AttendanceLeftEarly, store.attendance[0].Status)
+
+	view, err = recordAttendance(store, attendanceShift1, "sara", "")
+	require.NoError(t, err)
+	assert.Empty(t, store.attendance)
+	assert.Empty(t, view.People[1].Status)
+}
+
+func TestRecordAttendanceRefusals(t *testing.T) {
+	tests := []struct {
+		name        string
+		shiftID     string
+		volunteerID string
+		status      string
+		wantErr     error
+	}{
+		{name: "no such shift", shiftID: "shift-1", volunteerID: "sara", status: db.AttendanceAttended, wantErr: ErrNotFound},
+		{name: "shift to come", shiftID: attendanceShift2, volunteerID: "emma", status: db.AttendanceAttended, wantErr: ErrConflict},
+		{name: "not on the shift", shiftID: attendanceShift1, volunteerID: "emma", status: db.AttendanceNoShow, wantErr: ErrConflict},
+		{name: "unknown status", shiftID: attendanceShift1, volunteerID: "sara", status: "late", wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := attendanceStore()
+			_, err := recordAttendance(store, tt.shiftID, tt.volunteerID, tt.status)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, store.attendance)
+		})
+	}
+}
+
+// Somebody swapped off a shift was not expected at it, so there is nothing
+// to hold against them; whoever took their place is who is marked.
+func TestRecordAttendanceFollowsTheRotaAsItStood(t *testing.T) {
+	store := attendanceStore()
+	store.alterations = []db.Alteration{
+		{ID: "alt-1", ShiftID: attendanceShift1, Direction: "remove", VolunteerID: "sara", Role: "Service volunteer", CoverID: "cover-1"},
+		{ID: "alt-2", ShiftID: attendanceShift1, Direction: "add", VolunteerID: "emma", Role: "Service volunteer", CoverID: "cover-1"},
+	}
+
+	_, err := recordAttendance(store, attendanceShift1, "sara", db.AttendanceNoShow)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	view, err := recordAttendance(store, attendanceShift1, "emma", db.AttendanceAttended)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"michael", "emma"}, []string{view.People[0].VolunteerID, view.People[1].VolunteerID})
+}
+
+func TestLeadAttendance(t *testing.T) {
+	store := attendanceStore()
+	ctx := context.Background()
+
+	page, err := GetLeadAttendance(ctx, store, swapVolunteers(), sendTestCfg, "tok-michael", attendanceNow)
+	require.NoError(t, err)
+	assert.Equal(t, "Team lead", page.Role)
+	require.Len(t, page.Shifts, 1, "the one shift Michael led that has happened")
+	assert.Equal(t, attendanceShift1, page.Shifts[0].ShiftID)
+
+	page, err = RecordLeadAttendance(ctx, store, swapVolunteers(), sendTestCfg, "tok-michael", AttendanceParams{
+		ShiftID: attendanceShift1, VolunteerID: "sara", Status: db.AttendanceAttended,
+	}, attendanceNow, zap.NewNop())
+	require.NoError(t, err)
+	sara := page.Shifts[0].People[1]
+	assert.Equal(t, db.AttendanceAttended, sara.Status)
+	assert.Equal(t, "michael", sara.RecordedBy, "recorded as the lead's")
+	assert.Equal(t, "Michael Smith", sara.RecordedByName)
+
+	_, err = RecordLeadAttendance(ctx, store, swapVolunteers(), sendTestCfg, "tok-michael", AttendanceParams{
+		ShiftID: attendanceShift0, VolunteerID: "sara", Status: db.AttendanceNoShow,
+	}, attendanceNow, zap.NewNop())
+	assert.ErrorIs(t, err, ErrNotFound, "Michael did not lead the first shift")
+
+	page, err = GetLeadAttendance(ctx, store, swapVolunteers(), sendTestCfg, "tok-sara", attendanceNow)
+	require.NoError(t, err)
+	assert.Empty(t, page.Shifts, "Sara led nothing")
+
+	_, err = GetLeadAttendance(ctx, store, swapVolunteers(), sendTestCfg, "tok-nobody", attendanceNow)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestSwapPageSaysWhoLeads(t *testing.T) {
+	store := swapStore()
+
+	page, err := GetSwapPage(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-michael", swapNow)
+	require.NoError(t, err)
+	assert.True(t, page.LeadsShifts)
+
+	page, err = GetSwapPage(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-sara", swapNow)
+	require.NoError(t, err)
+	assert.False(t, page.LeadsShifts)
+}
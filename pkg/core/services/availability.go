@@ -33,10 +33,13 @@ type AvailabilityStore interface {
 	// goes out rather than once they all have.
 	MarkAvailabilityRequestSent(ctx context.Context, id string) error
 	GetLatestAvailability(ctx context.Context, requestIDs []string, cutoff *time.Time) (map[string]db.AvailabilityGeneration, error)
-	InsertAvailabilityResponse(ctx context.Context, requestID string, answers []db.ShiftAnswer) (*db.AvailabilityGeneration, error)
+	InsertAvailabilityResponse(ctx context.Context, requestID string, answers []db.ShiftAnswer, preferredShiftCount int) (*db.AvailabilityGeneration, error)
 	// Pins hold seats the answers coming in do not have to fill, so the round's
 	// coverage cannot be read without them.
 	GetPreallocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Preallocation, error)
+	// An Absence is a no whatever the answer says, so neither the round nor
+	// the form can be read without them either.
+	AbsenceReader
 }
 
 // AvailabilityShift is one of a rota's shifts as both the volunteer's form and
@@ -72,7 +75,13 @@ type AvailabilityEntry struct {
 	// The shifts the latest generation said yes to. Empty for a volunteer who
 	// replied "none of these", which Replied still reports as an answer.
 	AvailableShiftIDs []string
-	CoveredBy         []string
+	// How many of those shifts they would like, 0 when they did not say.
+	PreferredShiftCount int
+	// The shifts one of their Absences covers, in shift order. A no whatever
+	// AvailableShiftIDs says, and whether or not they have replied — the
+	// overlap between the two is what the response grid flags.
+	AbsentShiftIDs []string
+	CoveredBy      []string
 	// The Roles they hold on the roster, in priority order. Not a fact about the
 	// round — it is carried here so a reader can ask "which of these people
 	// could lead" without fetching the roster and joining it back. Empty for a
@@ -111,6 +120,10 @@ type AvailabilityForm struct {
 	SelectedShiftIDs []string
 	Submitted        bool
 	SubmittedAt      time.Time // zero until they submit
+	// PreferredShiftCount is how many of the selected shifts they would like to
+	// work, 0 for "no preference" — which is also the landing state, since
+	// asking for fewer is something a volunteer opts into.
+	PreferredShiftCount int
 	// Counts is false when nothing said here can reach a rota: the volunteer
 	// has stopped, or is off the roster altogether. Allocation only ever sees
 	// active volunteers, and a round leaves the rest out, so without this the
@@ -119,6 +132,13 @@ type AvailabilityForm struct {
 	// It does not gate the answer. The likeliest cause is a roster nobody has
 	// updated, and the answer is worth having the moment that is fixed.
 	Counts bool
+	// Absences are the volunteer's own, from the rota's first shift on — the
+	// ones that touch it and the ones after it — soonest first.
+	Absences []AbsenceView
+	// AbsentShiftIDs are the shifts those Absences cover. They are never in
+	// SelectedShiftIDs: an Absence is a no whatever the form says, so it does
+	// not offer a yes.
+	AbsentShiftIDs []string
 }
 
 // MintAvailabilityRound creates an availability request, with its own link, for
@@ -158,7 +178,12 @@ func MintAvailabilityRound(
 		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
 	}
 
-	if _, err := mintRequestsFor(ctx, database, logger, rota.ID, volunteers); err != nil {
+	shiftDates, err := rotaShiftDates(ctx, database, rota.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := mintRequestsFor(ctx, database, logger, rota.ID, shiftDates, volunteers); err != nil {
 		return nil, err
 	}
 
@@ -173,8 +198,13 @@ type MintRequestsStore interface {
 }
 
 // mintRequestsFor writes an availability request, with its own link, for every
-// active volunteer on the roster who does not already hold one for this rota.
-// It returns how many were created.
+// volunteer on the roster who is volunteering on any of the rota's shiftDates
+// and does not already hold one for this rota. It returns how many were
+// created.
+//
+// The lifecycle is judged on the rota's dates, not the day the round opens: a
+// round goes out weeks ahead, and somebody paused until its third Sunday is
+// back for that one and the rest, so is asked about them.
 //
 // This is the whole of minting: the roster read is the caller's, because the
 // two callers come by it differently — a round read has the volunteers in hand
@@ -185,11 +215,12 @@ func mintRequestsFor(
 	database MintRequestsStore,
 	logger *zap.Logger,
 	rotaID string,
+	shiftDates []time.Time,
 	volunteers []model.Volunteer,
 ) (int, error) {
 	// Only active volunteers are asked. Someone who has stopped volunteering is
 	// kept on the roster but is not part of a round.
-	active := utils.FilterActiveVolunteers(volunteers)
+	active := utils.FilterActiveOnAny(volunteers, shiftDates)
 	requests := make([]db.AvailabilityRequest, 0, len(active))
 	for _, v := range active {
 		token, err := pkgutils.RandomToken()
@@ -267,12 +298,29 @@ func GetAvailabilityForm(
 		return nil, err
 	}
 
+	return readForm(ctx, database, request, rota, shifts, volunteers)
+}
+
+// readForm reads what the form shows beyond the link itself — the latest
+// answer, and the volunteer's Absences — and builds it.
+func readForm(
+	ctx context.Context,
+	database AvailabilityStore,
+	request *db.AvailabilityRequest,
+	rota *db.Rotation,
+	shifts []AvailabilityShift,
+	volunteers []model.Volunteer,
+) (*AvailabilityForm, error) {
 	latest, err := database.GetLatestAvailability(ctx, []string{request.ID}, rotaCutoff(rota))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read availability: %w", err)
 	}
+	absent, absences, err := shiftAbsences(ctx, database, shifts)
+	if err != nil {
+		return nil, err
+	}
 
-	return buildForm(request, shifts, volunteers, latest[request.ID]), nil
+	return buildForm(request, shifts, volunteers, latest[request.ID], ownAbsences(absences, request.VolunteerID), absent[request.VolunteerID]), nil
 }
 
 // SubmitAvailability records one complete generation for a link and returns the
@@ -282,6 +330,11 @@ func GetAvailabilityForm(
 // no, so a partial write would silently record unavailability (ADR 0004). There
 // is no idempotency key — a duplicate submission writes another generation with
 // a later timestamp, and latest-wins makes the outcome identical.
+//
+// preferredShiftCount is "free for all four but only want two": how many of the
+// shifts said yes to they would like to work, or 0 for no preference. It goes
+// with the answers as part of the same generation, so resubmitting without it
+// clears it, as resubmitting without a shift clears that.
 func SubmitAvailability(
 	ctx context.Context,
 	database AvailabilityStore,
@@ -290,6 +343,7 @@ func SubmitAvailability(
 	logger *zap.Logger,
 	token string,
 	shiftIDs []string,
+	preferredShiftCount int,
 ) (*AvailabilityForm, error) {
 	request, rota, shifts, volunteers, err := resolveToken(ctx, database, volunteerClient, cfg, logger, token)
 	if err != nil {
@@ -300,18 +354,40 @@ func SubmitAvailability(
 	if err != nil {
 		return nil, err
 	}
+	if err := validatePreferredShiftCount(preferredShiftCount, answers); err != nil {
+		return nil, err
+	}
 
-	generation, err := database.InsertAvailabilityResponse(ctx, request.ID, answers)
+	generation, err := database.InsertAvailabilityResponse(ctx, request.ID, answers, preferredShiftCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to record availability response: %w", err)
 	}
+	absent, absences, err := shiftAbsences(ctx, database, shifts)
+	if err != nil {
+		return nil, err
+	}
 
 	logger.Info("Recorded availability response",
 		zap.String("rota_id", rota.ID),
 		zap.String("volunteer_id", request.VolunteerID),
-		zap.Int("shifts_available", len(answers)))
+		zap.Int("shifts_available", len(answers)),
+		zap.Int("preferred_shift_count", preferredShiftCount))
 
-	return buildForm(request, shifts, volunteers, *generation), nil
+	return buildForm(request, shifts, volunteers, *generation, ownAbsences(absences, request.VolunteerID), absent[request.VolunteerID]), nil
+}
+
+// validatePreferredShiftCount refuses a count the answers cannot meet. Asking
+// for more shifts than were said yes to is a client that has gone wrong — the
+// form offers no more than were ticked — and storing it would read as a
+// preference the solver can never honour, which is not what was meant either.
+func validatePreferredShiftCount(preferredShiftCount int, answers []db.ShiftAnswer) error {
+	if preferredShiftCount < 0 {
+		return wrapf(ErrInvalidInput, "preferred shift count cannot be negative, got %d", preferredShiftCount)
+	}
+	if preferredShiftCount > len(answers) {
+		return wrapf(ErrInvalidInput, "preferred shift count %d is more than the %d shifts said yes to", preferredShiftCount, len(answers))
+	}
+	return nil
 }
 
 // validateAnswers turns the submitted shift ids into the generation's positive
@@ -395,22 +471,34 @@ func resolveToken(
 
 // buildForm assembles the volunteer's view. generation is the zero value when
 // they have not replied, which is what selects the opt-out landing state.
+// absentShiftIDs are the shifts their Absences cover, which are left unticked
+// either way.
 func buildForm(
 	request *db.AvailabilityRequest,
 	shifts []AvailabilityShift,
 	volunteers []model.Volunteer,
 	generation db.AvailabilityGeneration,
+	absences []AbsenceView,
+	absentShiftIDs []string,
 ) *AvailabilityForm {
 	form := &AvailabilityForm{
-		Shifts:    shifts,
-		Submitted: generation.ResponseID != "",
+		Shifts:         shifts,
+		Submitted:      generation.ResponseID != "",
+		Absences:       absences,
+		AbsentShiftIDs: absentShiftIDs,
+	}
+	away := make(map[string]bool, len(absentShiftIDs))
+	for _, id := range absentShiftIDs {
+		away[id] = true
 	}
 
 	volunteer, known := findVolunteer(volunteers, request.VolunteerID)
 	if known {
 		form.VolunteerName = volunteerName(volunteer)
-		form.GroupMembers = partnerNames(groupPartners(volunteers, volunteer, utils.IsActive), volunteerName)
-		form.Counts = utils.IsActive(volunteer)
+		dates := availabilityShiftDates(shifts)
+		activeOnTheRota := func(v model.Volunteer) bool { return utils.IsActiveOnAny(v, dates) }
+		form.GroupMembers = partnerNames(groupPartners(volunteers, volunteer, activeOnTheRota), volunteerName)
+		form.Counts = activeOnTheRota(volunteer)
 	} else {
 		// A volunteer dropped from the sheet mid-round still holds a working
 		// link; degrade to the id rather than 404 a link that was legitimately
@@ -420,11 +508,12 @@ func buildForm(
 
 	if form.Submitted {
 		form.SubmittedAt = generation.SubmittedAt
+		form.PreferredShiftCount = generation.PreferredShiftCount
 		// Intersect with the open shifts rather than echoing the stored rows: a
 		// shift closed since they answered must not come back pre-ticked.
 		open := make(map[string]bool, len(shifts))
 		for _, s := range shifts {
-			if !s.Closed {
+			if !s.Closed && !away[s.ID] {
 				open[s.ID] = true
 			}
 		}
@@ -443,7 +532,7 @@ func buildForm(
 	// indistinguishable from a genuine "I can't do any of these".
 	form.SelectedShiftIDs = make([]string, 0, len(shifts))
 	for _, s := range shifts {
-		if !s.Closed {
+		if !s.Closed && !away[s.ID] {
 			form.SelectedShiftIDs = append(form.SelectedShiftIDs, s.ID)
 		}
 	}
@@ -451,7 +540,7 @@ func buildForm(
 }
 
 // allocatableRequests drops the requests belonging to somebody who cannot be
-// allocated: off the roster, or no longer volunteering.
+// allocated: off the roster, or volunteering on none of the rota's shiftDates.
 //
 // Minting asks only the active roster, so this is about the gap between then
 // and now — a volunteer who stops after their link goes out keeps a request
@@ -467,6 +556,7 @@ func buildForm(
 func allocatableRequests(
 	requests []db.AvailabilityRequest,
 	volunteers []model.Volunteer,
+	shiftDates []time.Time,
 	logger *zap.Logger,
 ) []db.AvailabilityRequest {
 	out := make([]db.AvailabilityRequest, 0, len(requests))
@@ -477,7 +567,7 @@ func allocatableRequests(
 				zap.String("volunteer_id", r.VolunteerID))
 			continue
 		}
-		if !utils.IsActive(volunteer) {
+		if !utils.IsActiveOnAny(volunteer, shiftDates) {
 			logger.Debug("Availability request for a volunteer who has stopped",
 				zap.String("volunteer_id", r.VolunteerID))
 			continue
@@ -507,7 +597,7 @@ func buildRound(
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch availability requests: %w", err)
 	}
-	requests = allocatableRequests(requests, volunteers, logger)
+	requests = allocatableRequests(requests, volunteers, availabilityShiftDates(shifts), logger)
 
 	requestIDs := make([]string, 0, len(requests))
 	for _, r := range requests {
@@ -517,6 +607,10 @@ func buildRound(
 	if err != nil {
 		return nil, fmt.Errorf("failed to read availability: %w", err)
 	}
+	absent, _, err := shiftAbsences(ctx, database, shifts)
+	if err != nil {
+		return nil, err
+	}
 
 	// Who has replied is settled before any entry is built, because covered-by
 	// asks about other people's rows.
@@ -537,12 +631,14 @@ func buildRound(
 			SentAt:            r.SentAt,
 			Replied:           hasReplied,
 			AvailableShiftIDs: make([]string, 0, len(generation.Answers)),
+			AbsentShiftIDs:    absent[r.VolunteerID],
 		}
 		for _, a := range generation.Answers {
 			entry.AvailableShiftIDs = append(entry.AvailableShiftIDs, a.ShiftID)
 		}
 		if hasReplied {
 			entry.SubmittedAt = generation.SubmittedAt
+			entry.PreferredShiftCount = generation.PreferredShiftCount
 		}
 
 		// Known, and still volunteering: allocatableRequests dropped everyone
@@ -668,3 +764,16 @@ func findVolunteer(volunteers []model.Volunteer, id string) (model.Volunteer, bo
 	}
 	return model.Volunteer{}, false
 }
+
+// availabilityShiftDates is the days a round's shifts fall on, which is what a
+// volunteer's lifecycle is judged against. A date that does not parse names no
+// day anybody could be back for, and is left out.
+func availabilityShiftDates(shifts []AvailabilityShift) []time.Time {
+	dates := make([]time.Time, 0, len(shifts))
+	for _, s := range shifts {
+		if date, err := time.Parse(time.DateOnly, s.Date); err == nil {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
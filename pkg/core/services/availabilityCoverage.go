@@ -3,6 +3,7 @@ package services
 import (
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/services/utils"
@@ -66,12 +67,21 @@ type RoleCoverage struct {
 // AvailableShiftIDs is the group rule of ADR 0004 applied: the intersection over
 // the members who answered. Empty for a group nobody has answered for, which
 // Replied is what distinguishes from a group that answered "none of these".
+//
+// PreferredShiftCount is the group's "only want N", settled the way its
+// availability is: the group works as one, so it works no more than its most
+// sparing responder would like. 0 when no responder gave a number.
+//
+// AbsentShiftIDs are the shifts any member has an Absence over, answered or
+// not. None of them is ever in AvailableShiftIDs.
 type AvailabilityGroup struct {
-	Key               string
-	Name              string // the members' names, as the group is addressed on screen
-	Replied           bool
-	AvailableShiftIDs []string
-	Members           []AvailabilityEntry
+	Key                 string
+	Name                string // the members' names, as the group is addressed on screen
+	Replied             bool
+	AvailableShiftIDs   []string
+	PreferredShiftCount int
+	AbsentShiftIDs      []string
+	Members             []AvailabilityEntry
 }
 
 // shiftSeats is what config and pins have already settled about one date, before
@@ -156,7 +166,8 @@ func buildShiftSeats(
 // (ADR 0004): the members of a group that said yes are all available, including
 // the ones who never answered themselves. A volunteer who has stopped
 // volunteering since the round was minted still holds a link and still appears
-// on the roster, but cannot be allocated, so they are not counted here.
+// on the roster, but cannot be allocated, so they are not counted here; nor is
+// one on the shifts before a pause ends.
 func buildCoverage(
 	shifts []AvailabilityShift,
 	groups []AvailabilityGroup,
@@ -179,6 +190,9 @@ func buildCoverage(
 			continue
 		}
 
+		// A volunteer paused until partway through the rota is counted from the
+		// shift they are back for.
+		date, _ := time.Parse(time.DateOnly, shift.Date)
 		available := make(map[string]int, len(byPriority))
 		for _, group := range groups {
 			if !group.availableOn(shift.ID) {
@@ -186,7 +200,7 @@ func buildCoverage(
 			}
 			for _, member := range group.Members {
 				volunteer, known := volunteersByID[member.VolunteerID]
-				if !known || !utils.IsActive(volunteer) {
+				if !known || !utils.IsActiveOn(volunteer, date) {
 					continue
 				}
 				// A pinned volunteer is already counted on the other side of
@@ -271,6 +285,11 @@ func buildAvailabilityGroups(
 // yes. That is the intersection over the responders, and the exact dual of the
 // union-of-unavailability the Forms path computed — the semantics are unchanged,
 // only the encoding flipped (ADR 0004).
+//
+// An Absence is a no that comes before all of that. It counts whether or not
+// its volunteer has answered — a group is placed whole, so a partner's yes
+// would otherwise put somebody abroad on the rota — and it overrides a yes the
+// volunteer gave themselves, which is usually an answer from before they knew.
 func buildAvailabilityGroup(key string, members []AvailabilityEntry, shifts []AvailabilityShift) AvailabilityGroup {
 	sort.Slice(members, func(i, j int) bool {
 		if members[i].VolunteerName != members[j].VolunteerName {
@@ -281,9 +300,14 @@ func buildAvailabilityGroup(key string, members []AvailabilityEntry, shifts []Av
 
 	names := make([]string, 0, len(members))
 	responders := 0
+	preferred := 0
 	saidYes := make(map[string]int, len(shifts))
+	away := make(map[string]bool)
 	for _, member := range members {
 		names = append(names, member.VolunteerName)
+		for _, shiftID := range member.AbsentShiftIDs {
+			away[shiftID] = true
+		}
 		if !member.Replied {
 			continue
 		}
@@ -291,6 +315,9 @@ func buildAvailabilityGroup(key string, members []AvailabilityEntry, shifts []Av
 		for _, shiftID := range member.AvailableShiftIDs {
 			saidYes[shiftID]++
 		}
+		if member.PreferredShiftCount > 0 && (preferred == 0 || member.PreferredShiftCount < preferred) {
+			preferred = member.PreferredShiftCount
+		}
 	}
 
 	group := AvailabilityGroup{
@@ -298,18 +325,23 @@ func buildAvailabilityGroup(key string, members []AvailabilityEntry, shifts []Av
 		Name:    strings.Join(names, " & "),
 		Replied: responders > 0,
 		Members: members,
+		// The least any responder asked for. Nobody who left it blank is
+		// asking for more than that, only not asking for fewer.
+		PreferredShiftCount: preferred,
 		// Never nil: an empty answer and no answer are different things, and
 		// both have to serialise as a list rather than a null.
 		AvailableShiftIDs: []string{},
-	}
-	if !group.Replied {
-		return group
+		AbsentShiftIDs:    []string{},
 	}
 
 	// In shift order, so a caller can line the ids up against the dates without
 	// sorting them again.
 	for _, shift := range shifts {
-		if !shift.Closed && saidYes[shift.ID] == responders {
+		if away[shift.ID] {
+			group.AbsentShiftIDs = append(group.AbsentShiftIDs, shift.ID)
+			continue
+		}
+		if group.Replied && !shift.Closed && saidYes[shift.ID] == responders {
 			group.AvailableShiftIDs = append(group.AvailableShiftIDs, shift.ID)
 		}
 	}
@@ -61,7 +61,7 @@ func groupOf(t *testing.T, round *AvailabilityRound, key string) AvailabilityGro
 func answer(t *testing.T, store *mockAvailabilityStore, volunteers *mockVolunteerClient, cfg *config.Config, round *AvailabilityRound, volunteerID string, shiftIDs ...string) {
 	t.Helper()
 	_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
-		tokenFor(t, round, volunteerID), shiftIDs)
+		tokenFor(t, round, volunteerID), shiftIDs, 0)
 	require.NoError(t, err)
 }
 
@@ -3,7 +3,10 @@ package services
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/jakechorley/ilford-drop-in/internal/config"
@@ -12,9 +15,9 @@ import (
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 )
 
-// SendMode selects which of a round's requests a send covers and what the email
-// says. The three differ only in those two things — everything after choosing
-// the recipients is one loop — so they are a mode rather than three services.
+// SendMode selects who a send emails and what the email says. The modes differ
+// only in those two things — everything after choosing the recipients is one
+// loop — so they are a mode rather than four services.
 type SendMode string
 
 const (
@@ -29,8 +32,53 @@ const (
 	// answer to a bounce or a deleted email, so "already sent" is the normal
 	// case rather than a reason to refuse.
 	SendModeResend SendMode = "resend"
+	// SendModeAllocation tells everyone on an allocated rota the shifts they
+	// have been given. It is the one mode sent after allocation rather than
+	// before, and the one with no availability link in it: its recipients are
+	// the rota's allocations, not the round's requests. Sending it again sends
+	// everyone theirs again — there is no stamp to top up against, and after
+	// a round of changes that is what an admin wants.
+	SendModeAllocation SendMode = "allocation"
+	// SendModeCover tells the volunteers one rota change moved what it did to
+	// them: the shifts it put them on and took them off. It is the optional
+	// step after a change is recorded, and its record is the change's audit of
+	// who was told.
+	SendModeCover SendMode = "cover"
+	// SendModeCoverRequest asks the volunteers one cover request was minted
+	// for whether they can cover its shift, each with their own link to answer
+	// on. Like the availability modes it carries a link; unlike them it is sent
+	// after allocation, and about one shift. Sending it again reaches only the
+	// people who have not answered, which is what chasing one is.
+	SendModeCoverRequest SendMode = "cover-request"
+	// SendModeSwapOffer tells everyone who could take a shift a volunteer has
+	// asked to give up that it is on offer, each with their swap page to take
+	// it on. A swap request offers the shift on those pages and nowhere else,
+	// so without this it goes only to whoever happens to look. Sending it again
+	// tells them all again: the first to take it ends the offer, so everybody
+	// still being asked is somebody who has not.
+	SendModeSwapOffer SendMode = "swap-offer"
 )
 
+// asksForAvailability reports whether the mode's emails carry an availability
+// link, which is what needs a deadline to quote and a request to stamp. The
+// other modes are sent about a rota that has been decided.
+func (m SendMode) asksForAvailability() bool {
+	return m == SendModeRound || m == SendModeReminder || m == SendModeResend
+}
+
+// carriesCalendarLink reports whether the mode's emails hand the volunteer
+// their calendar feed: the ones about a decided rota, bar a cover request,
+// which asks rather than tells.
+func (m SendMode) carriesCalendarLink() bool {
+	return m == SendModeAllocation || m == SendModeCover
+}
+
+// carriesLink reports whether the mode's emails carry a link for the volunteer
+// to answer on: an availability link, a cover request's, or their swap page.
+func (m SendMode) carriesLink() bool {
+	return m.asksForAvailability() || m == SendModeCoverRequest || m == SendModeSwapOffer
+}
+
 // SendParams is one send. Deadline is the date the email quotes and nothing
 // else: it is not stored, not shown on the site, and not enforced — allocation
 // is the real cutoff (ADR 0004).
@@ -39,17 +87,39 @@ const (
 // in because the address the app answers on is a property of the request being
 // served, not of the round.
 type SendParams struct {
-	RotaID      string // empty means the latest rota
-	Mode        SendMode
-	Deadline    string
-	VolunteerID string // SendModeResend only
-	Link        func(token string) string
+	// RotaID empty means the latest rota, or for SendModeAllocation the latest
+	// allocated one.
+	RotaID         string
+	Mode           SendMode
+	Deadline       string // the modes that ask for availability only
+	VolunteerID    string // SendModeResend only
+	CoverID        string // SendModeCover only: the rota change to tell people about
+	CoverRequestID string // SendModeCoverRequest only: the cover request to ask about
+	SwapRequestID  string // SendModeSwapOffer only: the swap request to offer
+	Link           func(token string) string
+	// CoverLink turns a cover request's token into its page, as Link does an
+	// availability request's.
+	CoverLink func(token string) string
+	// SwapLink turns an availability request's token into the volunteer's
+	// swap page, which is the same link answering once its rota is out.
+	SwapLink func(token string) string
+	// CalendarLink turns a volunteer's calendar token into their feed, for
+	// the modes sent about a decided rota. Passed in for the reason Link is.
+	CalendarLink func(token string) string
+	// Admin is who the send is for. A calendar token the send mints for a
+	// volunteer who had none is recorded as theirs.
+	Admin string
 	// Progress, when set, is called once the recipients are known and again
 	// after each email. A send is slow enough — Gmail is throttled to one email
 	// every three seconds — that a caller needs to show it moving, and this is
 	// the only place that knows both how many were selected and how far it has
 	// got.
 	Progress func(done, total int)
+	// SendID, when set, is the recorded send this run belongs to: its total
+	// and every outcome are written against it as they happen, and volunteers
+	// it already holds an outcome for are skipped, which is what resuming an
+	// interrupted send is.
+	SendID string
 }
 
 // SentEmail is one email that went out, named the way an admin would recognise
@@ -85,35 +155,63 @@ type SendReport struct {
 // not cost the other twenty-eight volunteers their email. sent_at is stamped per
 // volunteer as each send succeeds, never in a batch at the end — the stamp is
 // what makes a send resumable, so it has to be true at every point during a
-// send that takes a minute and a half, not just after it.
+// send that takes a minute and a half, not just after it. The recorded send's
+// outcomes are written the same way, for the same reason.
 func SendAvailabilityEmails(
 	ctx context.Context,
-	database AvailabilityStore,
+	database AvailabilitySendStore,
 	volunteerClient VolunteerClient,
-	mailer GmailClient,
+	mailer MailClient,
 	cfg *config.Config,
 	logger *zap.Logger,
 	params SendParams,
 ) (*SendReport, error) {
-	if params.Deadline == "" {
+	asking := params.Mode.asksForAvailability()
+	if params.Deadline == "" && asking {
 		return nil, wrapf(ErrInvalidInput, "a deadline is required: it is quoted in the subject and the body of every email")
 	}
-	if params.Link == nil {
+	if params.Link == nil && asking {
 		return nil, fmt.Errorf("send params carry no link builder")
 	}
+	if params.CoverLink == nil && params.Mode == SendModeCoverRequest {
+		return nil, fmt.Errorf("send params carry no cover link builder")
+	}
+	if params.SwapLink == nil && params.Mode == SendModeSwapOffer {
+		return nil, fmt.Errorf("send params carry no swap link builder")
+	}
+	if params.CalendarLink == nil && params.Mode.carriesCalendarLink() {
+		return nil, fmt.Errorf("send params carry no calendar link builder")
+	}
+	if params.CoverID == "" && params.Mode == SendModeCover {
+		return nil, wrapf(ErrInvalidInput, "a rota change send needs the change it is about")
+	}
+	if params.CoverRequestID == "" && params.Mode == SendModeCoverRequest {
+		return nil, wrapf(ErrInvalidInput, "a cover request send needs the request it is asking about")
+	}
+	if params.SwapRequestID == "" && params.Mode == SendModeSwapOffer {
+		return nil, wrapf(ErrInvalidInput, "a swap offer send needs the swap it is offering")
+	}
 
-	rota, err := resolveRota(ctx, database, params.RotaID)
+	rota, err := resolveSendRota(ctx, database, params.RotaID, params.Mode)
 	if err != nil {
 		return nil, err
 	}
-	// Links stop working at allocation, so every email would carry a dead one.
-	if rota.AllocatedDatetime != "" {
-		return nil, wrapf(ErrConflict, "rota %s is already allocated, so its availability links no longer work", rota.ID)
-	}
 
-	requests, err := database.GetAvailabilityRequestsByRotaID(ctx, rota.ID)
+	// The wording is read once per send and checked before the first email,
+	// so wording that cannot be filled in stops the send whole rather than
+	// failing it thirty times over.
+	defaults, err := RotaDefaults(ctx, database)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch availability requests: %w", err)
+		return nil, err
+	}
+	kind := sendTemplateKind(params.Mode)
+	template, _ := defaults.EmailTemplates.For(kind)
+	if _, err := template.Render(model.ExampleEmailTemplateData); err != nil {
+		return nil, wrapf(ErrInvalidInput, "the %s email cannot be sent as worded - fix it on the settings screen: %v", kind, err)
+	}
+	shifts, err := database.GetShiftsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
 	}
 
 	roles, err := RoleTable(ctx, database)
@@ -126,40 +224,116 @@ func SendAvailabilityEmails(
 		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
 	}
 
-	recipients, err := selectRecipients(ctx, database, rota, requests, volunteers, params)
+	var recipients []recipient
+	switch params.Mode {
+	case SendModeAllocation:
+		recipients, err = selectAllocatedRecipients(ctx, database, shifts, volunteers, roles, defaults)
+	case SendModeCover:
+		recipients, err = selectCoverRecipients(ctx, database, params.CoverID, volunteers, roles, defaults)
+	case SendModeCoverRequest:
+		recipients, err = selectCoverRequestRecipients(ctx, database, params.CoverRequestID, volunteers, defaults, time.Now())
+	case SendModeSwapOffer:
+		recipients, err = selectSwapOfferRecipients(ctx, database, rota, params.SwapRequestID, volunteers, roles, defaults, time.Now())
+	default:
+		var requests []db.AvailabilityRequest
+		requests, err = database.GetAvailabilityRequestsByRotaID(ctx, rota.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch availability requests: %w", err)
+		}
+		recipients, err = selectRecipients(ctx, database, rota, shifts, requests, volunteers, params)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	recipients, already, err := skipRecorded(ctx, database, params.SendID, recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	// Minted before the first email rather than as each goes, so a send that
+	// cannot write them stops whole instead of mailing half the rota a link
+	// and half none.
+	if params.Mode.carriesCalendarLink() {
+		ids := make([]string, 0, len(recipients))
+		for _, r := range recipients {
+			ids = append(ids, r.volunteer.ID)
+		}
+		tokens, _, err := ensureCalendarTokens(ctx, database, ids, params.Admin)
+		if err != nil {
+			return nil, err
+		}
+		for i := range recipients {
+			recipients[i].calendarToken = tokens[recipients[i].volunteer.ID]
+		}
+	}
+	total := already + len(recipients)
+
 	report := &SendReport{Mode: params.Mode, Sent: []SentEmail{}, Failed: []FailedEmail{}}
 	logger.Info("Sending availability emails",
 		zap.String("rota_id", rota.ID),
 		zap.String("mode", string(params.Mode)),
-		zap.Int("recipients", len(recipients)))
+		zap.String("send_id", params.SendID),
+		zap.Int("recipients", len(recipients)),
+		zap.Int("already_done", already))
 
 	progress := func() {
 		if params.Progress != nil {
-			params.Progress(len(report.Sent)+len(report.Failed), len(recipients))
+			params.Progress(already+len(report.Sent)+len(report.Failed), total)
 		}
 	}
 	// Reported before the first email, so a caller showing a send knows how many
 	// are coming rather than watching a bar with no end.
+	if params.SendID != "" {
+		if err := database.SetAvailabilitySendTotal(ctx, params.SendID, total); err != nil {
+			return nil, err
+		}
+	}
 	progress()
 
+	// record writes an outcome against the recorded send. A failure to write
+	// one does not stop the send: the email has already gone or failed, and
+	// the cost of a missing row is only that a resume would try that volunteer
+	// again.
+	record := func(outcome db.AvailabilitySendOutcome) {
+		if params.SendID == "" {
+			return
+		}
+		outcome.SendID = params.SendID
+		if err := database.RecordAvailabilitySendOutcome(ctx, outcome); err != nil {
+			logger.Error("Failed to record an availability send outcome",
+				zap.String("send_id", params.SendID),
+				zap.String("volunteer_id", outcome.VolunteerID),
+				zap.Error(err))
+		}
+	}
+
 	// Every outcome goes through one of these two, so nothing can be recorded
 	// without the progress count moving with it.
 	fail := func(f FailedEmail) {
 		report.Failed = append(report.Failed, f)
+		record(db.AvailabilitySendOutcome{VolunteerID: f.VolunteerID, VolunteerName: f.VolunteerName, Email: f.Email, Error: f.Error})
 		progress()
 	}
 	succeed := func(s SentEmail) {
 		report.Sent = append(report.Sent, s)
+		record(db.AvailabilitySendOutcome{VolunteerID: s.VolunteerID, VolunteerName: s.VolunteerName, Email: s.Email})
 		progress()
 	}
 
 	for _, r := range recipients {
 		name := volunteerName(r.volunteer)
 
+		if r.offRoster {
+			// On the rota, but gone from the roster since: they will not hear
+			// about it, which an admin has to know.
+			fail(FailedEmail{
+				VolunteerID:   r.volunteer.ID,
+				VolunteerName: r.volunteer.ID,
+				Error:         "on the rota but not on the roster, so there is no address to send to",
+			})
+			continue
+		}
 		if r.volunteer.Email == "" {
 			// Nothing to send to. Reported rather than skipped: an admin who
 			// saw nothing would believe the round reached everybody.
@@ -171,10 +345,18 @@ func SendAvailabilityEmails(
 			continue
 		}
 
-		link := params.Link(r.request.Token)
-		subject, body := composeEmail(params.Mode, r.volunteer.FirstName, link, params.Deadline)
+		email, err := template.Render(r.templateData(rota, shifts, params))
+		if err != nil {
+			fail(FailedEmail{
+				VolunteerID:   r.volunteer.ID,
+				VolunteerName: name,
+				Email:         r.volunteer.Email,
+				Error:         "the email could not be worded: " + err.Error(),
+			})
+			continue
+		}
 
-		if err := mailer.SendEmail(r.volunteer.Email, subject, body); err != nil {
+		if err := mailer.SendEmail(r.volunteer.Email, email.Subject, email.Text, email.HTML); err != nil {
 			logger.Warn("Failed to send availability email",
 				zap.String("volunteer_id", r.volunteer.ID),
 				zap.Error(err))
@@ -189,8 +371,9 @@ func SendAvailabilityEmails(
 
 		// A reminder does not re-ask, so it leaves the stamp where it is: moving
 		// it would make a later round send believe this volunteer's original
-		// invitation went out today.
-		if params.Mode != SendModeReminder {
+		// invitation went out today. The emails about a decided rota carry no
+		// link, so there is no request to stamp.
+		if params.Mode == SendModeRound || params.Mode == SendModeResend {
 			if err := database.MarkAvailabilityRequestSent(ctx, r.request.ID); err != nil {
 				// The email is already gone, so this cannot be undone. Report it
 				// as a failure so the admin knows the row is out of step: the
@@ -208,6 +391,15 @@ func SendAvailabilityEmails(
 				continue
 			}
 		}
+		if params.Mode == SendModeCoverRequest {
+			if err := database.MarkCoverRequestTokenSent(ctx, r.coverToken); err != nil {
+				// Only the admin's count of who has been emailed is out of
+				// step; the link works either way.
+				logger.Error("Sent a cover request email but failed to stamp sent_at",
+					zap.String("volunteer_id", r.volunteer.ID),
+					zap.Error(err))
+			}
+		}
 
 		succeed(SentEmail{
 			VolunteerID:   r.volunteer.ID,
@@ -226,10 +418,526 @@ func SendAvailabilityEmails(
 }
 
 // recipient pairs a request with the volunteer it belongs to, which every send
-// needs together: the token comes from one and the address from the other.
+// needs together: the token comes from one and the address from the other. The
+// emails about a decided rota have no request; they carry shifts instead.
 type recipient struct {
 	request   db.AvailabilityRequest
 	volunteer model.Volunteer
+	// shifts are the ones the email tells the volunteer they are on: all of
+	// theirs in an allocation email, the ones a change added in a cover one.
+	shifts []model.EmailTemplateShift
+	// removed are the shifts a change took the volunteer off.
+	removed []model.EmailTemplateShift
+	// coverToken is the volunteer's link to a cover request, and coverShift
+	// the shift it asks about, in a cover request email.
+	coverToken string
+	coverShift model.EmailTemplateShift
+	// swapShift is the shift on offer, and swapFrom who is giving it up, in a
+	// swap offer email. The link is the request's, to the swap page.
+	swapShift model.EmailTemplateShift
+	swapFrom  string
+	// calendarToken addresses the volunteer's calendar feed, in the emails
+	// about a decided rota.
+	calendarToken string
+	// offRoster is a volunteer on the rota the roster no longer holds. Only
+	// the volunteer's id is known.
+	offRoster bool
+}
+
+// templateData is what this recipient's email can say.
+func (r recipient) templateData(rota *db.Rotation, shifts []db.Shift, params SendParams) model.EmailTemplateData {
+	switch params.Mode {
+	case SendModeAllocation:
+		data := emailTemplateData(rota, shifts, r.volunteer, "", "")
+		data.Shifts = r.shifts
+		data.CalendarLink = params.CalendarLink(r.calendarToken)
+		return data
+	case SendModeCover:
+		data := emailTemplateData(rota, shifts, r.volunteer, "", "")
+		data.Added, data.Removed = r.shifts, r.removed
+		data.CalendarLink = params.CalendarLink(r.calendarToken)
+		return data
+	case SendModeCoverRequest:
+		data := emailTemplateData(rota, shifts, r.volunteer, params.CoverLink(r.coverToken), "")
+		data.CoverShift = r.coverShift
+		return data
+	case SendModeSwapOffer:
+		data := emailTemplateData(rota, shifts, r.volunteer, params.SwapLink(r.request.Token), "")
+		data.SwapShift, data.SwapFrom = r.swapShift, r.swapFrom
+		return data
+	}
+	return emailTemplateData(rota, shifts, r.volunteer, params.Link(r.request.Token), params.Deadline)
+}
+
+// resolveSendRota finds the rota a send is for and refuses one the mode cannot
+// be sent for: availability links stop working at allocation, and before it
+// nobody has any shifts to be told about.
+//
+// Asked for no rota in particular, an allocation send means the latest rota
+// that has been allocated. The latest rota is usually the next one, in flight,
+// by the time an admin thinks to tell people about the one before.
+//
+// A change can be made to any rota, so a cover send is for whichever rota its
+// change was in, allocated or not. A cover request send is for the rota its
+// shift is in, which the request was refused for unless it was allocated, and
+// so is a swap offer send, whose shift nobody could have asked to give up
+// before it was.
+func resolveSendRota(ctx context.Context, database AvailabilityStore, rotaID string, mode SendMode) (*db.Rotation, error) {
+	if mode == SendModeCover || mode == SendModeCoverRequest || mode == SendModeSwapOffer {
+		return resolveRota(ctx, database, rotaID)
+	}
+	if mode == SendModeAllocation && rotaID == "" {
+		rotations, err := database.GetRotations(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch rotations: %w", err)
+		}
+		allocated := make([]db.Rotation, 0, len(rotations))
+		for _, r := range rotations {
+			if r.AllocatedDatetime != "" {
+				allocated = append(allocated, r)
+			}
+		}
+		if len(allocated) == 0 {
+			return nil, wrapf(ErrConflict, "no rota has been allocated yet, so nobody has any shifts to be told about")
+		}
+		return utils.FindLatestRotation(allocated), nil
+	}
+
+	rota, err := resolveRota(ctx, database, rotaID)
+	if err != nil {
+		return nil, err
+	}
+	if mode == SendModeAllocation {
+		if rota.AllocatedDatetime == "" {
+			return nil, wrapf(ErrConflict, "rota %s has not been allocated yet, so nobody has any shifts to be told about", rota.ID)
+		}
+		return rota, nil
+	}
+	// Links stop working at allocation, so every email would carry a dead one.
+	if rota.AllocatedDatetime != "" {
+		return nil, wrapf(ErrConflict, "rota %s is already allocated, so its availability links no longer work", rota.ID)
+	}
+	return rota, nil
+}
+
+// selectAllocatedRecipients is everyone on an allocated rota, each with the
+// shifts they have been given — the allocations as they stand now, alterations
+// applied, so a send after a swap tells people the rota they will work rather
+// than the one the solver made. Closed shifts and custom entries are left out:
+// nobody works the one, and there is nobody to email for the other.
+//
+// Recipients come in roster order, then anybody allocated who has left the
+// roster since, so a report reads the same way the volunteer list does.
+// Inactive volunteers are included: they are on the rota all the same.
+func selectAllocatedRecipients(
+	ctx context.Context,
+	database AvailabilitySendStore,
+	shifts []db.Shift,
+	volunteers []model.Volunteer,
+	roles model.Roles,
+	defaults model.RotaDefaults,
+) ([]recipient, error) {
+	given, err := allocatedShifts(ctx, database, shifts, roles, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := inRosterOrder(volunteers, keys(given))
+	for i := range recipients {
+		recipients[i].shifts = given[recipients[i].volunteer.ID]
+	}
+	return recipients, nil
+}
+
+// selectCoverRecipients is everyone one rota change moved, each with the
+// shifts it put them on and took them off. A swap moves both people twice, so
+// each gets one email with both halves in it rather than two. Custom entries
+// are left out, as they are from an allocation email: there is nobody to
+// email.
+//
+// Every cover has at least one alteration, so finding none is finding no such
+// change.
+func selectCoverRecipients(
+	ctx context.Context,
+	database AvailabilitySendStore,
+	coverID string,
+	volunteers []model.Volunteer,
+	roles model.Roles,
+	defaults model.RotaDefaults,
+) ([]recipient, error) {
+	alterations, err := coverAlterations(ctx, database, coverID)
+	if err != nil {
+		return nil, err
+	}
+
+	shiftsByID := make(map[string]*db.ShiftInRange)
+	added := make(map[string][]model.EmailTemplateShift)
+	removed := make(map[string][]model.EmailTemplateShift)
+	moved := make(map[string]bool)
+	for _, a := range alterations {
+		if a.VolunteerID == "" {
+			continue
+		}
+		shift, ok := shiftsByID[a.ShiftID]
+		if !ok {
+			shift, err = database.GetShiftByID(ctx, a.ShiftID)
+			if err != nil {
+				return nil, err
+			}
+			if shift == nil {
+				return nil, fmt.Errorf("rota change %s names shift %s, which does not exist", coverID, a.ShiftID)
+			}
+			shiftsByID[a.ShiftID] = shift
+		}
+
+		given := model.EmailTemplateShift{
+			Date:  weekdayDate(shift.Date),
+			Times: shiftTimes(shift.Shift, defaults),
+		}
+		moved[a.VolunteerID] = true
+		if a.Direction == "remove" {
+			removed[a.VolunteerID] = append(removed[a.VolunteerID], given)
+			continue
+		}
+		// Named the way an allocation email names it, for the same reason.
+		if known, ok := roles.ByName(a.Role); ok {
+			given.Role = known.Name
+		}
+		added[a.VolunteerID] = append(added[a.VolunteerID], given)
+	}
+
+	recipients := inRosterOrder(volunteers, moved)
+	for i := range recipients {
+		id := recipients[i].volunteer.ID
+		recipients[i].shifts, recipients[i].removed = added[id], removed[id]
+	}
+	return recipients, nil
+}
+
+// selectCoverRequestRecipients is everyone a cover request asked who has yet
+// to answer, each with their link and the shift it is about. Somebody who has
+// said yes or no has nothing more to be asked, so sending again chases the
+// silent. A request that has been closed, or whose shift has started, is
+// refused: every email would carry a dead link.
+func selectCoverRequestRecipients(
+	ctx context.Context,
+	database AvailabilitySendStore,
+	coverRequestID string,
+	volunteers []model.Volunteer,
+	defaults model.RotaDefaults,
+	now time.Time,
+) ([]recipient, error) {
+	req, shift, err := liveCoverRequest(ctx, database, coverRequestID, now)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := database.GetCoverRequestTokens(ctx, coverRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	asked := model.EmailTemplateShift{
+		Date:  weekdayDate(shift.Date),
+		Times: shiftTimes(shift.Shift, defaults),
+		Role:  req.Role,
+	}
+	tokenOf := make(map[string]string, len(tokens))
+	for _, t := range tokens {
+		if t.Answer == "" {
+			tokenOf[t.VolunteerID] = t.Token
+		}
+	}
+	recipients := inRosterOrder(volunteers, keys(tokenOf))
+	for i := range recipients {
+		recipients[i].coverToken = tokenOf[recipients[i].volunteer.ID]
+		recipients[i].coverShift = asked
+	}
+	return recipients, nil
+}
+
+// liveCoverRequest reads a cover request that can still be asked about, with
+// its shift. A malformed id cannot name one, and Postgres would refuse to
+// compare it.
+func liveCoverRequest(ctx context.Context, database AvailabilitySendStore, id string, now time.Time) (*db.CoverRequest, *db.ShiftInRange, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil, wrapf(ErrNotFound, "cover request %s not found", id)
+	}
+	req, err := database.GetCoverRequest(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if req == nil {
+		return nil, nil, wrapf(ErrNotFound, "cover request %s not found", id)
+	}
+	if req.Status != db.CoverRequestOpen {
+		return nil, nil, wrapf(ErrConflict, "cover request %s is %s, so nobody needs asking", id, req.Status)
+	}
+	if !now.Before(req.ExpiresAt) {
+		return nil, nil, wrapf(ErrConflict, "the shift cover request %s is for has started, so its links no longer work", id)
+	}
+	shift, err := database.GetShiftByID(ctx, req.ShiftID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shift == nil {
+		return nil, nil, wrapf(ErrNotFound, "the shift for cover request %s no longer exists", id)
+	}
+	return req, shift, nil
+}
+
+// selectSwapOfferRecipients is everyone who could take a shift on offer, by
+// the rule the swap page offers it by (swapContext.canTake), each with the
+// availability request whose token opens their swap page. Somebody the rule
+// accepts but who holds no link on the rota was never asked about it, and so
+// has no page to take it on; they are left out rather than reported.
+func selectSwapOfferRecipients(
+	ctx context.Context,
+	database AvailabilitySendStore,
+	rota *db.Rotation,
+	swapRequestID string,
+	volunteers []model.Volunteer,
+	roles model.Roles,
+	defaults model.RotaDefaults,
+	now time.Time,
+) ([]recipient, error) {
+	req, shift, err := liveSwapRequest(ctx, database, swapRequestID, defaults, now)
+	if err != nil {
+		return nil, err
+	}
+	onShift, err := effectiveAllocations(ctx, database, []string{shift.ID})
+	if err != nil {
+		return nil, err
+	}
+	available, err := roundAvailability(ctx, database, rota)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := database.GetAvailabilityRequestsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch availability requests: %w", err)
+	}
+
+	c := &swapContext{
+		volunteers: volunteersByID(volunteers),
+		roles:      roles,
+		shifts:     []db.Shift{shift.Shift},
+		onShift:    onShift,
+		available:  available,
+	}
+	requestOf := make(map[string]db.AvailabilityRequest)
+	for _, r := range requests {
+		if r.VolunteerID != req.VolunteerID && c.canTake(r.VolunteerID, shift.ID, req.Role) {
+			requestOf[r.VolunteerID] = r
+		}
+	}
+
+	offered := model.EmailTemplateShift{
+		Date:  weekdayDate(shift.Date),
+		Times: shiftTimes(shift.Shift, defaults),
+		Role:  req.Role,
+	}
+	recipients := inRosterOrder(volunteers, keys(requestOf))
+	for i := range recipients {
+		recipients[i].request = requestOf[recipients[i].volunteer.ID]
+		recipients[i].swapShift = offered
+		recipients[i].swapFrom = c.firstName(req.VolunteerID)
+	}
+	return recipients, nil
+}
+
+// liveSwapRequest reads a swap request that is still on offer, with its shift.
+// One taken, settled or withdrawn, or whose shift has been and gone, is
+// refused: nobody could take it from the email. A malformed id cannot name
+// one, and Postgres would refuse to compare it.
+func liveSwapRequest(ctx context.Context, database AvailabilitySendStore, id string, defaults model.RotaDefaults, now time.Time) (*db.SwapRequest, *db.ShiftInRange, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil, wrapf(ErrNotFound, "swap request %s not found", id)
+	}
+	req, err := database.GetSwapRequest(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read swap request %s: %w", id, err)
+	}
+	if req == nil {
+		return nil, nil, wrapf(ErrNotFound, "swap request %s not found", id)
+	}
+	if req.Status != db.SwapOpen {
+		return nil, nil, wrapf(ErrConflict, "swap request %s is %s, so it is no longer on offer", id, req.Status)
+	}
+	shift, err := database.GetShiftByID(ctx, req.ShiftID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shift == nil {
+		return nil, nil, wrapf(ErrNotFound, "the shift for swap request %s no longer exists", id)
+	}
+	today, err := localDate(now, defaults)
+	if err != nil {
+		return nil, nil, err
+	}
+	if shift.Date < today {
+		return nil, nil, wrapf(ErrConflict, "the shift swap request %s offers has already happened", id)
+	}
+	return req, shift, nil
+}
+
+// coverAlterations reads the alterations one rota change recorded. A
+// malformed id cannot name a change, and Postgres would refuse to compare it.
+func coverAlterations(ctx context.Context, database AvailabilitySendStore, coverID string) ([]db.Alteration, error) {
+	if _, err := uuid.Parse(coverID); err != nil {
+		return nil, wrapf(ErrNotFound, "rota change %s not found", coverID)
+	}
+	alterations, err := database.GetAlterationsByCoverID(ctx, coverID)
+	if err != nil {
+		return nil, err
+	}
+	if len(alterations) == 0 {
+		return nil, wrapf(ErrNotFound, "rota change %s not found", coverID)
+	}
+	return alterations, nil
+}
+
+// inRosterOrder makes a recipient of each volunteer in ids: those on the
+// roster in roster order, so a report reads the way the volunteer list does,
+// then anybody the roster no longer holds, by id.
+func inRosterOrder(volunteers []model.Volunteer, ids map[string]bool) []recipient {
+	remaining := make(map[string]bool, len(ids))
+	for id := range ids {
+		remaining[id] = true
+	}
+
+	recipients := make([]recipient, 0, len(ids))
+	for _, v := range volunteers {
+		if remaining[v.ID] {
+			recipients = append(recipients, recipient{volunteer: v})
+			delete(remaining, v.ID)
+		}
+	}
+	gone := make([]string, 0, len(remaining))
+	for id := range remaining {
+		gone = append(gone, id)
+	}
+	sort.Strings(gone)
+	for _, id := range gone {
+		recipients = append(recipients, recipient{volunteer: model.Volunteer{ID: id}, offRoster: true})
+	}
+	return recipients
+}
+
+// keys is the set of a map's keys.
+func keys[V any](m map[string]V) map[string]bool {
+	set := make(map[string]bool, len(m))
+	for k := range m {
+		set[k] = true
+	}
+	return set
+}
+
+// allocatedShifts is every volunteer's shifts on a rota, keyed by volunteer id,
+// in the order the shifts are given.
+func allocatedShifts(
+	ctx context.Context,
+	database AvailabilitySendStore,
+	shifts []db.Shift,
+	roles model.Roles,
+	defaults model.RotaDefaults,
+) (map[string][]model.EmailTemplateShift, error) {
+	shiftIDs := make([]string, 0, len(shifts))
+	for _, s := range shifts {
+		shiftIDs = append(shiftIDs, s.ID)
+	}
+	allocations, err := database.GetAllocationsByShiftIDs(ctx, shiftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+	alterations, err := database.GetAlterationsByShiftIDs(ctx, shiftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch alterations: %w", err)
+	}
+
+	byShift := make(map[string][]db.Allocation)
+	for _, a := range allocations {
+		byShift[a.ShiftID] = append(byShift[a.ShiftID], a)
+	}
+	byShift = utils.ApplyAlterations(byShift, alterations)
+
+	given := make(map[string][]model.EmailTemplateShift)
+	for _, shift := range shifts {
+		if shift.Closed {
+			continue
+		}
+		for _, a := range byShift[shift.ID] {
+			if a.VolunteerID == "" {
+				continue
+			}
+			// A Role the app no longer recognises is left unsaid, as the
+			// calendar feed does, rather than named as something nobody does.
+			role := ""
+			if known, ok := roles.ByName(a.Role); ok {
+				role = known.Name
+			}
+			given[a.VolunteerID] = append(given[a.VolunteerID], model.EmailTemplateShift{
+				Date:  weekdayDate(shift.Date),
+				Times: shiftTimes(shift, defaults),
+				Role:  role,
+			})
+		}
+	}
+	return given, nil
+}
+
+// shiftTimes is when a shift runs, the way an email says it: "18:30–21:00".
+// A shift carries its own times (ADR 0007); one minted before there were any
+// reads as the Rota Defaults, and with neither it says nothing.
+func shiftTimes(shift db.Shift, defaults model.RotaDefaults) string {
+	start, end := clockTime(shift.StartAt), clockTime(shift.EndAt)
+	if start == "" || end == "" {
+		if !defaults.HasShiftTimes() {
+			return ""
+		}
+		start, end = defaults.ShiftStartTime, defaults.ShiftEndTime
+	}
+	return start + "–" + end
+}
+
+// clockTime is the time of day in a stored shift timestamp, or empty.
+func clockTime(timestamp string) string {
+	parsed, err := time.Parse(model.ShiftTimestampLayout, timestamp)
+	if err != nil {
+		return ""
+	}
+	return parsed.Format(model.ShiftTimeLayout)
+}
+
+// skipRecorded drops the recipients a recorded send already holds an outcome
+// for, and reports how many that was. A round send would skip the ones that
+// went out anyway, by their sent_at; this is what also skips the failures, and
+// every recipient of a reminder or a resend, which nothing else remembers.
+//
+// A failure is not retried by resuming: it was reported, and a fresh round
+// send is how an admin asks for another go at it.
+func skipRecorded(ctx context.Context, database AvailabilitySendStore, sendID string, recipients []recipient) ([]recipient, int, error) {
+	if sendID == "" {
+		return recipients, 0, nil
+	}
+	outcomes, err := database.GetAvailabilitySendOutcomes(ctx, sendID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read what send %s has already done: %w", sendID, err)
+	}
+	if len(outcomes) == 0 {
+		return recipients, 0, nil
+	}
+
+	done := make(map[string]bool, len(outcomes))
+	for _, o := range outcomes {
+		done[o.VolunteerID] = true
+	}
+	remaining := make([]recipient, 0, len(recipients))
+	for _, r := range recipients {
+		if !done[r.volunteer.ID] {
+			remaining = append(remaining, r)
+		}
+	}
+	return remaining, len(outcomes), nil
 }
 
 // selectRecipients applies the mode's rule for who gets an email. It is the only
@@ -239,6 +947,7 @@ func selectRecipients(
 	ctx context.Context,
 	database AvailabilityStore,
 	rota *db.Rotation,
+	shifts []db.Shift,
 	requests []db.AvailabilityRequest,
 	volunteers []model.Volunteer,
 	params SendParams,
@@ -262,13 +971,20 @@ func selectRecipients(
 		return nil, err
 	}
 
+	shiftDates, err := utils.ShiftDatesFromShifts(shifts)
+	if err != nil {
+		return nil, err
+	}
+
 	recipients := make([]recipient, 0, len(requests))
 	for _, r := range requests {
 		volunteer, known := findVolunteer(volunteers, r.VolunteerID)
-		// Someone off the roster has no address, and someone who has stopped
-		// volunteering should not be chased for a rota they are not on. Neither
-		// is a failure to report — the round simply is not about them.
-		if !known || !utils.IsActive(volunteer) {
+		// Someone off the roster has no address, and someone who is not
+		// volunteering on any of the rota's shifts should not be chased for a
+		// rota they are not on. Neither is a failure to report — the round
+		// simply is not about them. Somebody paused until partway through it
+		// is back for the rest, so is asked.
+		if !known || !utils.IsActiveOnAny(volunteer, shiftDates) {
 			continue
 		}
 
@@ -333,17 +1049,3 @@ func groupsThatHaveAnswered(
 	}
 	return answered, nil
 }
-
-// composeEmail writes the email for a mode. A reminder differs only in saying it
-// is one — the link, the deadline and the promise that answers can be changed
-// are the same message either way.
-func composeEmail(mode SendMode, firstName, link, deadline string) (subject, body string) {
-	if mode == SendModeReminder {
-		return fmt.Sprintf("Reminder: Ilford drop-in availability (please complete by %s)", deadline),
-			fmt.Sprintf("Hey %s\n\nThis is a reminder to please let us know your availability.\n%s\n\nDeadline for responses is %s when we will create the rota.\nYou can change your response as many times as you like before the deadline.\n\nThanks\nThe Ilford drop-in team\n",
-				firstName, link, deadline)
-	}
-	return fmt.Sprintf("Ilford drop-in availability (please complete by %s)", deadline),
-		fmt.Sprintf("Hey %s\n\nPlease use this link to let us know your availability.\n%s\n\nDeadline for responses is %s when we will create the rota.\nYou can change your response as many times as you like before the deadline.\n\nThanks\nThe Ilford drop-in team\n",
-			firstName, link, deadline)
-}
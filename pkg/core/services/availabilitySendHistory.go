@@ -0,0 +1,468 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// A send is recorded in Postgres as it runs — that it started, how many it
+// selected, and what happened to each email — so a deploy partway through a
+// round costs the admin nothing but a button press. Before, the progress and
+// the report lived in the process doing the sending, and a restart lost both:
+// the page watching the send answered 404, and nobody could say who had been
+// emailed except by reading sent_at row by row.
+
+// Where a send has got to. Only "finished" is stored; the other two are read
+// off the heartbeat, because a process that has gone away cannot say so.
+const (
+	SendStatusRunning     = "running"
+	SendStatusInterrupted = "interrupted"
+	SendStatusFinished    = "finished"
+)
+
+// SendStallAfter is how long an unfinished send may go without recording
+// anything before it is taken to have stopped. Emails go out one every three
+// seconds, and even a slow Gmail answers each well inside this, so a send this
+// quiet is one whose process is gone rather than one that is thinking.
+const SendStallAfter = 2 * time.Minute
+
+// AvailabilitySendStore is what recording, resuming and reading back a send
+// needs, on top of everything sending one already does.
+type AvailabilitySendStore interface {
+	AvailabilityStore
+	// The emails about a decided rota hand each volunteer their calendar
+	// feed, minting the token it is addressed by for anybody without one.
+	CalendarTokenStore
+	// The wording of the emails is a setting.
+	RotaDefaultsStore
+	// An allocation email tells volunteers the rota as it stands, so it reads
+	// the allocations with their alterations applied.
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+	// A rota change email tells volunteers what one change did, which is its
+	// alterations and the shifts they name.
+	GetAlterationsByCoverID(ctx context.Context, coverID string) ([]db.Alteration, error)
+	GetShiftByID(ctx context.Context, id string) (*db.ShiftInRange, error)
+	// A cover request email asks the volunteers the request was minted for,
+	// each on their own link, and stamps it as it goes.
+	GetCoverRequest(ctx context.Context, id string) (*db.CoverRequest, error)
+	GetCoverRequestTokens(ctx context.Context, coverRequestID string) ([]db.CoverRequestToken, error)
+	MarkCoverRequestTokenSent(ctx context.Context, token string) error
+	// A swap offer email goes to whoever the swap page would offer the shift
+	// to, which is read from the request and the rota as it stands.
+	GetSwapRequest(ctx context.Context, id string) (*db.SwapRequest, error)
+	InsertAvailabilitySend(ctx context.Context, send db.AvailabilitySend) error
+	SetAvailabilitySendTotal(ctx context.Context, id string, total int) error
+	RecordAvailabilitySendOutcome(ctx context.Context, outcome db.AvailabilitySendOutcome) error
+	FinishAvailabilitySend(ctx context.Context, id string, errText string) error
+	// Claiming is one conditional write, so two tabs resuming the same send
+	// cannot both win.
+	ClaimAvailabilitySend(ctx context.Context, id string, staleBefore time.Time) (bool, error)
+	GetAvailabilitySend(ctx context.Context, id string) (*db.AvailabilitySend, error)
+	GetAvailabilitySendsByRotaID(ctx context.Context, rotaID string) ([]db.AvailabilitySend, error)
+	GetAvailabilitySendsByCoverID(ctx context.Context, coverID string) ([]db.AvailabilitySend, error)
+	GetAvailabilitySendOutcomes(ctx context.Context, sendID string) ([]db.AvailabilitySendOutcome, error)
+}
+
+// AvailabilitySendSummary is one send as a round's history lists it: who
+// started it, in which mode, and how it went, without the names.
+type AvailabilitySendSummary struct {
+	ID             string
+	RotaID         string
+	Mode           SendMode
+	AdminEmail     string
+	Deadline       string
+	VolunteerID    string // SendModeResend only
+	CoverID        string // SendModeCover only
+	CoverRequestID string // SendModeCoverRequest only
+	SwapRequestID  string // SendModeSwapOffer only
+	Status         string
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+	Total          int
+	Sent           int
+	Failed         int
+	// Error is why the send stopped short, when it did. A failed email is not
+	// this — that is one of Failed.
+	Error string
+}
+
+// Done is how many of the selected volunteers the send has dealt with.
+func (s AvailabilitySendSummary) Done() int {
+	return s.Sent + s.Failed
+}
+
+// AvailabilitySendView is one send with every outcome it recorded, which is
+// what the admin who started it reads while it runs and afterwards.
+type AvailabilitySendView struct {
+	AvailabilitySendSummary
+	SentEmails   []SentEmail
+	FailedEmails []FailedEmail
+}
+
+// BeginAvailabilitySend records a send as started and returns it, before a
+// single email goes out. It is separate from running the send because the
+// caller redirects the admin to watch it the moment it exists, and the row is
+// what they watch.
+//
+// The instruction is checked here rather than left for the send to trip over,
+// so a deadline forgotten or a rota allocated — or, for an allocation send, not
+// allocated yet, and for a cover send, a change that moved nobody with an
+// address — is refused to the admin's face rather than reported on a progress
+// page.
+func BeginAvailabilitySend(
+	ctx context.Context,
+	store AvailabilitySendStore,
+	admin string,
+	params SendParams,
+	logger *zap.Logger,
+) (*db.AvailabilitySend, error) {
+	switch params.Mode {
+	case SendModeRound, SendModeReminder, SendModeAllocation:
+	case SendModeResend:
+		if params.VolunteerID == "" {
+			return nil, wrapf(ErrInvalidInput, "a resend needs the volunteer to resend to")
+		}
+	case SendModeCover:
+		if params.CoverID == "" {
+			return nil, wrapf(ErrInvalidInput, "a rota change send needs the change it is about")
+		}
+		rotaID, err := coverRotaID(ctx, store, params.CoverID)
+		if err != nil {
+			return nil, err
+		}
+		params.RotaID = rotaID
+	case SendModeCoverRequest:
+		if params.CoverRequestID == "" {
+			return nil, wrapf(ErrInvalidInput, "a cover request send needs the request it is asking about")
+		}
+		_, shift, err := liveCoverRequest(ctx, store, params.CoverRequestID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		params.RotaID = shift.RotaID
+	case SendModeSwapOffer:
+		if params.SwapRequestID == "" {
+			return nil, wrapf(ErrInvalidInput, "a swap offer send needs the swap it is offering")
+		}
+		defaults, err := RotaDefaults(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		_, shift, err := liveSwapRequest(ctx, store, params.SwapRequestID, defaults, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		params.RotaID = shift.RotaID
+	default:
+		return nil, wrapf(ErrInvalidInput, "unknown send mode %q", params.Mode)
+	}
+	// The emails about a decided rota have nothing to answer, so they quote
+	// no deadline.
+	if !params.Mode.asksForAvailability() {
+		params.Deadline = ""
+	} else if strings.TrimSpace(params.Deadline) == "" {
+		return nil, wrapf(ErrInvalidInput, "a deadline is required: it is quoted in the subject and the body of every email")
+	}
+
+	rota, err := resolveSendRota(ctx, store, params.RotaID, params.Mode)
+	if err != nil {
+		return nil, err
+	}
+
+	send := db.AvailabilitySend{
+		ID:         uuid.New().String(),
+		RotaID:     rota.ID,
+		AdminEmail: admin,
+		Mode:       string(params.Mode),
+		Deadline:   params.Deadline,
+	}
+	if params.Mode == SendModeResend {
+		send.VolunteerID = params.VolunteerID
+	}
+	if params.Mode == SendModeCover {
+		send.CoverID = params.CoverID
+	}
+	if params.Mode == SendModeCoverRequest {
+		send.CoverRequestID = params.CoverRequestID
+	}
+	if params.Mode == SendModeSwapOffer {
+		send.SwapRequestID = params.SwapRequestID
+	}
+	if err := store.InsertAvailabilitySend(ctx, send); err != nil {
+		return nil, fmt.Errorf("failed to record the send: %w", err)
+	}
+
+	logger.Info("Availability send recorded",
+		zap.String("send_id", send.ID),
+		zap.String("rota_id", send.RotaID),
+		zap.String("mode", send.Mode))
+	return &send, nil
+}
+
+// coverRotaID is the rota a cover send is recorded against: the one its change
+// was made in. A swap can cross two rotas, and the first volunteer's shift
+// settles it — the send is read back by its change either way. A change that
+// only moved custom entries has nobody to tell, so it is refused.
+func coverRotaID(ctx context.Context, store AvailabilitySendStore, coverID string) (string, error) {
+	alterations, err := coverAlterations(ctx, store, coverID)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range alterations {
+		if a.VolunteerID == "" {
+			continue
+		}
+		shift, err := store.GetShiftByID(ctx, a.ShiftID)
+		if err != nil {
+			return "", err
+		}
+		if shift == nil {
+			return "", fmt.Errorf("rota change %s names shift %s, which does not exist", coverID, a.ShiftID)
+		}
+		return shift.RotaID, nil
+	}
+	return "", wrapf(ErrConflict, "rota change %s only moved custom entries, so there is nobody to email", coverID)
+}
+
+// ResumableAvailabilitySend returns admin's send if it can be resumed now: it
+// is theirs, it is not finished, and it has stopped moving. It is asked before
+// the admin is sent to approve Gmail access, so nobody grants access for a
+// resume that was never going to run.
+func ResumableAvailabilitySend(ctx context.Context, store AvailabilitySendStore, id, admin string, now time.Time) (*db.AvailabilitySend, error) {
+	send, err := ownSend(ctx, store, id, admin)
+	if err != nil {
+		return nil, err
+	}
+	switch sendStatus(*send, now) {
+	case SendStatusFinished:
+		return nil, wrapf(ErrConflict, "send %s has finished, so there is nothing to resume - start a new send instead", id)
+	case SendStatusRunning:
+		return nil, wrapf(ErrConflict, "send %s is still running", id)
+	}
+	return send, nil
+}
+
+// ResumeAvailabilitySend claims admin's interrupted send so it can be run
+// again from where it stopped. Losing the claim to another tab that resumed
+// it first reads the same as finding it running, because by then it is.
+func ResumeAvailabilitySend(
+	ctx context.Context,
+	store AvailabilitySendStore,
+	id, admin string,
+	now time.Time,
+	logger *zap.Logger,
+) (*db.AvailabilitySend, error) {
+	send, err := ResumableAvailabilitySend(ctx, store, id, admin, now)
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := store.ClaimAvailabilitySend(ctx, id, now.Add(-SendStallAfter))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, wrapf(ErrConflict, "send %s is still running", id)
+	}
+
+	logger.Info("Resuming an interrupted availability send",
+		zap.String("send_id", send.ID),
+		zap.String("rota_id", send.RotaID),
+		zap.String("mode", send.Mode))
+	return send, nil
+}
+
+// RunAvailabilitySend sends a recorded send's emails and marks it finished,
+// with the reason when it stopped short. It runs a resumed send exactly as it
+// runs a fresh one: what has been done already is read back off the record
+// rather than carried in.
+func RunAvailabilitySend(
+	ctx context.Context,
+	store AvailabilitySendStore,
+	volunteerClient VolunteerClient,
+	mailer MailClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+	send db.AvailabilitySend,
+	link func(token string) string,
+	coverLink func(token string) string,
+	swapLink func(token string) string,
+	calendarLink func(token string) string,
+) {
+	params := SendParams{
+		RotaID:         send.RotaID,
+		Mode:           SendMode(send.Mode),
+		Deadline:       send.Deadline,
+		VolunteerID:    send.VolunteerID,
+		CoverID:        send.CoverID,
+		CoverRequestID: send.CoverRequestID,
+		SwapRequestID:  send.SwapRequestID,
+		Link:           link,
+		CoverLink:      coverLink,
+		SwapLink:       swapLink,
+		CalendarLink:   calendarLink,
+		Admin:          send.AdminEmail,
+		SendID:         send.ID,
+	}
+
+	var errText string
+	if _, err := SendAvailabilityEmails(ctx, store, volunteerClient, mailer, cfg, logger, params); err != nil {
+		logger.Error("Availability send failed", zap.String("send_id", send.ID), zap.Error(err))
+		errText = err.Error()
+	}
+
+	// Not the send's own context, which may be the one that just ran out: the
+	// send is over either way, and a record left unfinished would offer a
+	// resume of something that stopped for a reason.
+	if err := store.FinishAvailabilitySend(context.WithoutCancel(ctx), send.ID, errText); err != nil {
+		logger.Error("Failed to mark an availability send finished",
+			zap.String("send_id", send.ID),
+			zap.Error(err))
+	}
+}
+
+// GetAvailabilitySend reads one of admin's sends with everything it recorded.
+//
+// A send lists every volunteer it reached and every address it failed on, so
+// it belongs to the admin who started it. Anybody else's reads as not found
+// rather than forbidden: to them, it is not a send that exists.
+func GetAvailabilitySend(ctx context.Context, store AvailabilitySendStore, id, admin string, now time.Time) (*AvailabilitySendView, error) {
+	send, err := ownSend(ctx, store, id, admin)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := store.GetAvailabilitySendOutcomes(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &AvailabilitySendView{
+		AvailabilitySendSummary: summariseSend(*send, now),
+		SentEmails:              []SentEmail{},
+		FailedEmails:            []FailedEmail{},
+	}
+	for _, o := range outcomes {
+		if o.Error == "" {
+			view.SentEmails = append(view.SentEmails, SentEmail{VolunteerID: o.VolunteerID, VolunteerName: o.VolunteerName, Email: o.Email})
+			continue
+		}
+		view.FailedEmails = append(view.FailedEmails, FailedEmail{VolunteerID: o.VolunteerID, VolunteerName: o.VolunteerName, Email: o.Email, Error: o.Error})
+	}
+	return view, nil
+}
+
+// ListAvailabilitySends reads a round's history of sends, newest first. Every
+// admin's sends are listed — "somebody sent the reminder yesterday" is exactly
+// what the next admin needs to know — but as counts, without the names.
+func ListAvailabilitySends(ctx context.Context, store AvailabilitySendStore, rotaID string, now time.Time) ([]AvailabilitySendSummary, error) {
+	rota, err := resolveRota(ctx, store, rotaID)
+	if err != nil {
+		return nil, err
+	}
+	sends, err := store.GetAvailabilitySendsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]AvailabilitySendSummary, 0, len(sends))
+	for _, send := range sends {
+		summaries = append(summaries, summariseSend(send, now))
+	}
+	return summaries, nil
+}
+
+// ListCoverNotifications reads who was told about one rota change: every send
+// made for it, newest first, each with the volunteers it reached and failed
+// on. It is the change's audit, so unlike a send's own report it is every
+// admin's to read — and for that reason it names people without their
+// addresses, which stay with the admin who sent.
+//
+// A change nobody was told about has no sends, which is an answer rather than
+// a miss.
+func ListCoverNotifications(ctx context.Context, store AvailabilitySendStore, coverID string, now time.Time) ([]AvailabilitySendView, error) {
+	if _, err := coverAlterations(ctx, store, coverID); err != nil {
+		return nil, err
+	}
+	sends, err := store.GetAvailabilitySendsByCoverID(ctx, coverID)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]AvailabilitySendView, 0, len(sends))
+	for _, send := range sends {
+		outcomes, err := store.GetAvailabilitySendOutcomes(ctx, send.ID)
+		if err != nil {
+			return nil, err
+		}
+		view := AvailabilitySendView{
+			AvailabilitySendSummary: summariseSend(send, now),
+			SentEmails:              []SentEmail{},
+			FailedEmails:            []FailedEmail{},
+		}
+		for _, o := range outcomes {
+			if o.Error == "" {
+				view.SentEmails = append(view.SentEmails, SentEmail{VolunteerID: o.VolunteerID, VolunteerName: o.VolunteerName})
+				continue
+			}
+			view.FailedEmails = append(view.FailedEmails, FailedEmail{VolunteerID: o.VolunteerID, VolunteerName: o.VolunteerName, Error: o.Error})
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// ownSend reads a send that must be admin's. A malformed id is a miss like any
+// other: it cannot name a send, and Postgres would refuse to compare it.
+func ownSend(ctx context.Context, store AvailabilitySendStore, id, admin string) (*db.AvailabilitySend, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, wrapf(ErrNotFound, "send %s not found", id)
+	}
+	send, err := store.GetAvailabilitySend(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if send == nil || !strings.EqualFold(send.AdminEmail, admin) {
+		return nil, wrapf(ErrNotFound, "send %s not found", id)
+	}
+	return send, nil
+}
+
+func summariseSend(send db.AvailabilitySend, now time.Time) AvailabilitySendSummary {
+	return AvailabilitySendSummary{
+		ID:             send.ID,
+		RotaID:         send.RotaID,
+		Mode:           SendMode(send.Mode),
+		AdminEmail:     send.AdminEmail,
+		Deadline:       send.Deadline,
+		VolunteerID:    send.VolunteerID,
+		CoverID:        send.CoverID,
+		CoverRequestID: send.CoverRequestID,
+		SwapRequestID:  send.SwapRequestID,
+		Status:         sendStatus(send, now),
+		StartedAt:      send.StartedAt,
+		FinishedAt:     send.FinishedAt,
+		Total:          send.Total,
+		Sent:           send.Sent,
+		Failed:         send.Failed,
+		Error:          send.Error,
+	}
+}
+
+// sendStatus reads where a send has got to off its record.
+func sendStatus(send db.AvailabilitySend, now time.Time) string {
+	if send.FinishedAt != nil {
+		return SendStatusFinished
+	}
+	if now.Sub(send.LastProgressAt) > SendStallAfter {
+		return SendStatusInterrupted
+	}
+	return SendStatusRunning
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The recorded-send half of mockAvailabilityStore. Like the real store it
+// counts a send's outcomes when the send is read, keeps the later of two
+// outcomes for one volunteer, and only lets an unfinished, stalled send be
+// claimed.
+
+func (m *mockAvailabilityStore) findSend(id string) (*db.AvailabilitySend, error) {
+	for i := range m.sends {
+		if m.sends[i].ID == id {
+			return &m.sends[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no availability send with id %s", id)
+}
+
+func (m *mockAvailabilityStore) InsertAvailabilitySend(_ context.Context, send db.AvailabilitySend) error {
+	now := time.Now()
+	send.StartedAt, send.LastProgressAt = now, now
+	m.sends = append(m.sends, send)
+	return nil
+}
+
+func (m *mockAvailabilityStore) SetAvailabilitySendTotal(_ context.Context, id string, total int) error {
+	send, err := m.findSend(id)
+	if err != nil {
+		return err
+	}
+	send.Total, send.LastProgressAt = total, time.Now()
+	return nil
+}
+
+func (m *mockAvailabilityStore) RecordAvailabilitySendOutcome(_ context.Context, outcome db.AvailabilitySendOutcome) error {
+	send, err := m.findSend(outcome.SendID)
+	if err != nil {
+		return err
+	}
+	outcome.RecordedAt = time.Now()
+	send.LastProgressAt = outcome.RecordedAt
+	for i := range m.outcomes {
+		if m.outcomes[i].SendID == outcome.SendID && m.outcomes[i].VolunteerID == outcome.VolunteerID {
+			m.outcomes[i] = outcome
+			return nil
+		}
+	}
+	m.outcomes = append(m.outcomes, outcome)
+	return nil
+}
+
+func (m *mockAvailabilityStore) FinishAvailabilitySend(_ context.Context, id string, errText string) error {
+	send, err := m.findSend(id)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	send.FinishedAt, send.LastProgressAt, send.Error = &now, now, errText
+	return nil
+}
+
+func (m *mockAvailabilityStore) ClaimAvailabilitySend(_ context.Context, id string, staleBefore time.Time) (bool, error) {
+	send, err := m.findSend(id)
+	if err != nil {
+		return false, nil
+	}
+	if send.FinishedAt != nil || !send.LastProgressAt.Before(staleBefore) {
+		return false, nil
+	}
+	send.LastProgressAt = time.Now()
+	return true, nil
+}
+
+func (m *mockAvailabilityStore) counted(send db.AvailabilitySend) db.AvailabilitySend {
+	send.Sent, send.Failed = 0, 0
+	for _, o := range m.outcomes {
+		if o.SendID != send.ID {
+			continue
+		}
+		if o.Error == "" {
+			send.Sent++
+		} else {
+			send.Failed++
+		}
+	}
+	return send
+}
+
+func (m *mockAvailabilityStore) GetAvailabilitySend(_ context.Context, id string) (*db.AvailabilitySend, error) {
+	send, err := m.findSend(id)
+	if err != nil {
+		return nil, nil
+	}
+	counted := m.counted(*send)
+	return &counted, nil
+}
+
+func (m *mockAvailabilityStore) GetAvailabilitySendsByRotaID(_ context.Context, rotaID string) ([]db.AvailabilitySend, error) {
+	var out []db.AvailabilitySend
+	for _, send := range m.sends {
+		if send.RotaID == rotaID {
+			out = append(out, m.counted(send))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) GetAvailabilitySendsByCoverID(_ context.Context, coverID string) ([]db.AvailabilitySend, error) {
+	var out []db.AvailabilitySend
+	for _, send := range m.sends {
+		if send.CoverID == coverID {
+			out = append(out, m.counted(send))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) GetAvailabilitySendOutcomes(_ context.Context, sendID string) ([]db.AvailabilitySendOutcome, error) {
+	var out []db.AvailabilitySendOutcome
+	for _, o := range m.outcomes {
+		if o.SendID == sendID {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+
+const sendAdmin = "admin@example.com"
+
+// beginAndRun records a send and runs it to the end, the way the handler does
+// once the admin has granted Gmail access.
+func beginAndRun(t *testing.T, store *mockAvailabilityStore, mailer *mockMailer, params SendParams) *db.AvailabilitySend {
+	t.Helper()
+	send, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, params, zap.NewNop())
+	require.NoError(t, err)
+	RunAvailabilitySend(context.Background(), store, sendVolunteers(), mailer, sendTestCfg, zap.NewNop(), *send, params.Link, params.CoverLink, params.SwapLink, params.CalendarLink)
+	return send
+}
+
+// stall makes a send look like one whose process went away mid-send.
+func stall(t *testing.T, store *mockAvailabilityStore, id string) {
+	t.Helper()
+	send, err := store.findSend(id)
+	require.NoError(t, err)
+	send.LastProgressAt = time.Now().Add(-2 * SendStallAfter)
+}
+
+// TestRunAvailabilitySendRecordsEveryOutcome: the report an admin reads is the
+// record, not the process, so every email has to land in it with the send
+// marked finished at the end.
+func TestRunAvailabilitySendRecordsEveryOutcome(t *testing.T) {
+	store := sendStore()
+	mailer := &mockMailer{failFor: "sara@example.com"}
+
+	send := beginAndRun(t, store, mailer, sendParams(SendModeRound))
+
+	view, err := GetAvailabilitySend(context.Background(), store, send.ID, sendAdmin, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "rota-1", view.RotaID, "no rota named means the latest")
+	assert.Equal(t, SendStatusFinished, view.Status)
+	assert.Equal(t, 3, view.Total)
+	assert.Equal(t, 3, view.Done())
+	assert.Len(t, view.SentEmails, 2)
+	require.Len(t, view.FailedEmails, 1)
+	assert.Equal(t, "sara", view.FailedEmails[0].VolunteerID)
+	assert.Equal(t, "mailbox full", view.FailedEmails[0].Error)
+	assert.Empty(t, view.Error)
+}
+
+// TestResumeAvailabilitySendCarriesOnWhereItStopped: a deploy partway through
+// a reminder must not re-remind the people it already reached. A reminder
+// leaves sent_at alone, so only the record can say who they were.
+func TestResumeAvailabilitySendCarriesOnWhereItStopped(t *testing.T) {
+	store := sendStore()
+	for i := range store.requests {
+		store.requests[i].SentAt = "2026-07-30T09:00:00Z"
+	}
+	ctx := context.Background()
+
+	send, err := BeginAvailabilitySend(ctx, store, sendAdmin, sendParams(SendModeReminder), zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, store.SetAvailabilitySendTotal(ctx, send.ID, 3))
+	require.NoError(t, store.RecordAvailabilitySendOutcome(ctx, db.AvailabilitySendOutcome{
+		SendID: send.ID, VolunteerID: "michael", VolunteerName: "Michael Smith", Email: "michael@example.com",
+	}))
+	stall(t, store, send.ID)
+
+	resumed, err := ResumeAvailabilitySend(ctx, store, send.ID, sendAdmin, time.Now(), zap.NewNop())
+	require.NoError(t, err)
+	mailer := &mockMailer{}
+	RunAvailabilitySend(ctx, store, sendVolunteers(), mailer, sendTestCfg, zap.NewNop(), *resumed, sendParams(SendModeReminder).Link, nil, nil, nil)
+
+	assert.ElementsMatch(t, []string{"emma@example.com", "sara@example.com"}, mailer.recipients())
+	view, err := GetAvailabilitySend(ctx, store, send.ID, sendAdmin, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, SendStatusFinished, view.Status)
+	assert.Equal(t, 3, view.Total, "the total still counts the volunteers done before the interruption")
+	assert.Len(t, view.SentEmails, 3)
+}
+
+func TestResumeAvailabilitySendRefusals(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, store *mockAvailabilityStore, id string)
+		admin   string
+		want    error
+	}{
+		{
+			name:    "still running",
+			prepare: func(*testing.T, *mockAvailabilityStore, string) {},
+			admin:   sendAdmin,
+			want:    ErrConflict,
+		},
+		{
+			name: "already finished",
+			prepare: func(t *testing.T, store *mockAvailabilityStore, id string) {
+				require.NoError(t, store.FinishAvailabilitySend(context.Background(), id, ""))
+				stall(t, store, id)
+			},
+			admin: sendAdmin,
+			want:  ErrConflict,
+		},
+		{
+			// The mail would go out as somebody else, through a grant they
+			// never gave, so another admin's send is not one they can see.
+			name:    "another admin's send",
+			prepare: stall,
+			admin:   "other@example.com",
+			want:    ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := sendStore()
+			send, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, sendParams(SendModeRound), zap.NewNop())
+			require.NoError(t, err)
+			tt.prepare(t, store, send.ID)
+
+			_, err = ResumeAvailabilitySend(context.Background(), store, send.ID, tt.admin, time.Now(), zap.NewNop())
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+// TestResumeAvailabilitySendIsClaimedOnce: two tabs resuming the same send
+// must not both send it.
+func TestResumeAvailabilitySendIsClaimedOnce(t *testing.T) {
+	store := sendStore()
+	send, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, sendParams(SendModeRound), zap.NewNop())
+	require.NoError(t, err)
+	stall(t, store, send.ID)
+
+	_, err = ResumeAvailabilitySend(context.Background(), store, send.ID, sendAdmin, time.Now(), zap.NewNop())
+	require.NoError(t, err)
+	_, err = ResumeAvailabilitySend(context.Background(), store, send.ID, sendAdmin, time.Now(), zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestBeginAvailabilitySendRefusesWhatCouldNotRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		params func() SendParams
+		store  func() *mockAvailabilityStore
+		want   error
+	}{
+		{
+			name:   "no deadline",
+			params: func() SendParams { p := sendParams(SendModeRound); p.Deadline = " "; return p },
+			want:   ErrInvalidInput,
+		},
+		{
+			name:   "unknown mode",
+			params: func() SendParams { return sendParams("everyone") },
+			want:   ErrInvalidInput,
+		},
+		{
+			name:   "resend to nobody",
+			params: func() SendParams { return sendParams(SendModeResend) },
+			want:   ErrInvalidInput,
+		},
+		{
+			name:   "allocated rota",
+			params: func() SendParams { return sendParams(SendModeRound) },
+			store: func() *mockAvailabilityStore {
+				store := sendStore()
+				store.rotations[0].AllocatedDatetime = "2026-08-01T10:00:00Z"
+				return store
+			},
+			want: ErrConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := sendStore()
+			if tt.store != nil {
+				store = tt.store()
+			}
+			_, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, tt.params(), zap.NewNop())
+			assert.ErrorIs(t, err, tt.want)
+			assert.Empty(t, store.sends, "nothing is recorded for a send that could not run")
+		})
+	}
+}
+
+// TestGetAvailabilitySendIsTheStartersOnly: a send names everybody it reached
+// and every address it failed on.
+func TestGetAvailabilitySendIsTheStartersOnly(t *testing.T) {
+	store := sendStore()
+	send := beginAndRun(t, store, &mockMailer{}, sendParams(SendModeRound))
+
+	_, err := GetAvailabilitySend(context.Background(), store, send.ID, "other@example.com", time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = GetAvailabilitySend(context.Background(), store, "not-a-uuid", sendAdmin, time.Now())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = GetAvailabilitySend(context.Background(), store, send.ID, "Admin@Example.com", time.Now())
+	assert.NoError(t, err, "an address is the same admin whatever its case")
+}
+
+// TestListAvailabilitySendsReadsTheStatusOffTheHeartbeat: nothing marks a send
+// interrupted; it is one that stopped moving without finishing.
+func TestListAvailabilitySendsReadsTheStatusOffTheHeartbeat(t *testing.T) {
+	store := sendStore()
+	ctx := context.Background()
+
+	finished := beginAndRun(t, store, &mockMailer{}, sendParams(SendModeRound))
+	interrupted, err := BeginAvailabilitySend(ctx, store, "other@example.com", sendParams(SendModeReminder), zap.NewNop())
+	require.NoError(t, err)
+	stall(t, store, interrupted.ID)
+	running, err := BeginAvailabilitySend(ctx, store, sendAdmin, sendParams(SendModeReminder), zap.NewNop())
+	require.NoError(t, err)
+
+	summaries, err := ListAvailabilitySends(ctx, store, "rota-1", time.Now())
+	require.NoError(t, err)
+
+	status := make(map[string]string, len(summaries))
+	for _, s := range summaries {
+		status[s.ID] = s.Status
+	}
+	assert.Equal(t, map[string]string{
+		finished.ID:    SendStatusFinished,
+		interrupted.ID: SendStatusInterrupted,
+		running.ID:     SendStatusRunning,
+	}, status, "every admin's sends are in the history")
+}
@@ -27,16 +27,17 @@ type sentMail struct {
 	to      string
 	subject string
 	body    string
+	html    string
 }
 
-func (m *mockMailer) SendEmail(to, subject, body string) error {
+func (m *mockMailer) SendEmail(to, subject, body, html string) error {
 	if to == m.failFor {
 		if m.failWith != nil {
 			return m.failWith
 		}
 		return errors.New("mailbox full")
 	}
-	m.sent = append(m.sent, sentMail{to: to, subject: subject, body: body})
+	m.sent = append(m.sent, sentMail{to: to, subject: subject, body: body, html: html})
 	return nil
 }
 
@@ -81,9 +82,12 @@ var sendTestCfg = &config.Config{}
 
 func sendParams(mode SendMode) SendParams {
 	return SendParams{
-		Mode:     mode,
-		Deadline: "Friday 7 August",
-		Link:     func(token string) string { return "https://drop-in.example/availability/" + token },
+		Mode:         mode,
+		Deadline:     "Friday 7 August",
+		Link:         func(token string) string { return "https://drop-in.example/availability/" + token },
+		CoverLink:    func(token string) string { return "https://drop-in.example/cover/" + token },
+		SwapLink:     func(token string) string { return "https://drop-in.example/swaps/" + token },
+		CalendarLink: func(id string) string { return "https://drop-in.example/calendars/" + id + ".ics" },
 	}
 }
 
@@ -315,3 +319,284 @@ func TestSendReportsNobodyToEmailWithoutFailing(t *testing.T) {
 	assert.Empty(t, report.Failed)
 	assert.Empty(t, mailer.sent)
 }
+
+func (m *mockAvailabilityStore) GetAllocationsByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Allocation, error) {
+	want := make(map[string]bool, len(shiftIDs))
+	for _, id := range shiftIDs {
+		want[id] = true
+	}
+	var out []db.Allocation
+	for _, a := range m.allocations {
+		if want[a.ShiftID] {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) GetAlterationsByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Alteration, error) {
+	want := make(map[string]bool, len(shiftIDs))
+	for _, id := range shiftIDs {
+		want[id] = true
+	}
+	var out []db.Alteration
+	for _, a := range m.alterations {
+		if want[a.ShiftID] {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) GetAlterationsByCoverID(_ context.Context, coverID string) ([]db.Alteration, error) {
+	var out []db.Alteration
+	for _, a := range m.alterations {
+		if a.CoverID == coverID {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) GetShiftByID(_ context.Context, id string) (*db.ShiftInRange, error) {
+	for _, s := range m.shifts {
+		if s.ID != id {
+			continue
+		}
+		found := db.ShiftInRange{Shift: s}
+		for _, r := range m.rotations {
+			if r.ID == s.RotaID {
+				found.Allocated = r.AllocatedDatetime != ""
+			}
+		}
+		return &found, nil
+	}
+	return nil, nil
+}
+
+// allocatedSendStore is sendStore's rota allocated: Michael leads the first
+// shift with Sara, Sara works the second, a visiting group is on the second
+// too, and "left" is on it but has gone from the roster since. Emma was added
+// to the second by a swap after allocation.
+func allocatedSendStore() *mockAvailabilityStore {
+	store := sendStore()
+	store.rotations[0].AllocatedDatetime = "2026-07-31T09:00:00Z"
+	store.shifts[0].StartAt, store.shifts[0].EndAt = "2026-08-02T18:30:00", "2026-08-02T21:00:00"
+	store.allocations = []db.Allocation{
+		{ID: "a1", ShiftID: "shift-1", VolunteerID: "michael", Role: "Team lead"},
+		{ID: "a2", ShiftID: "shift-1", VolunteerID: "sara", Role: "Service volunteer"},
+		{ID: "a3", ShiftID: "shift-2", VolunteerID: "sara", Role: "Service volunteer"},
+		{ID: "a4", ShiftID: "shift-2", CustomEntry: "Visiting group", Role: "Service volunteer"},
+		{ID: "a5", ShiftID: "shift-2", VolunteerID: "left", Role: "Service volunteer"},
+	}
+	store.alterations = []db.Alteration{
+		{ID: "alt-1", ShiftID: "shift-2", Direction: "add", VolunteerID: "emma", Role: "Service volunteer", SetTime: "2026-08-01T10:00:00Z"},
+	}
+	return store
+}
+
+// TestSendAllocationTellsEveryoneTheirShifts: each volunteer on the rota hears
+// about their own shifts — the rota as it stands after changes, with the times
+// and the Role — and a volunteer the roster has lost is reported rather than
+// dropped.
+func TestSendAllocationTellsEveryoneTheirShifts(t *testing.T) {
+	store := allocatedSendStore()
+	store.calendarTokens = []db.CalendarToken{{VolunteerID: "sara", Token: "cal-sara"}}
+	mailer := &mockMailer{}
+	params := sendParams(SendModeAllocation)
+	params.Deadline = ""
+	params.Admin = "admin@example.com"
+
+	report := send(t, store, mailer, params)
+
+	assert.Equal(t, []string{"michael@example.com", "emma@example.com", "sara@example.com"}, mailer.recipients(),
+		"in roster order, and the custom entry is nobody to email")
+	assert.Len(t, report.Sent, 3)
+	require.Len(t, report.Failed, 1)
+	assert.Equal(t, "left", report.Failed[0].VolunteerID)
+	assert.Contains(t, report.Failed[0].Error, "not on the roster")
+
+	sara := mailer.sent[2]
+	assert.Contains(t, sara.subject, "2 August 2026 to 9 August 2026")
+	assert.Contains(t, sara.body, "- Sunday 2 August, 18:30–21:00 (Service volunteer)")
+	assert.Contains(t, sara.body, "- Sunday 9 August (Service volunteer)", "a shift with no times says none")
+	assert.Contains(t, sara.body, "https://drop-in.example/calendars/cal-sara.ics", "the calendar token she already had")
+	assert.NotContains(t, sara.body, "availability/", "the links have closed, so none is sent")
+	assert.Contains(t, sara.html, `href="https://drop-in.example/calendars/cal-sara.ics"`)
+
+	// Anybody without a calendar token is minted one before their email, in
+	// the name of the admin sending it.
+	michael := store.calendarTokenOf("michael")
+	require.NotEmpty(t, michael)
+	assert.Contains(t, mailer.sent[0].body, "https://drop-in.example/calendars/"+michael+".ics")
+	assert.Equal(t, "admin@example.com", store.calendarTokens[1].CreatedBy)
+
+	emma := mailer.sent[1]
+	assert.Contains(t, emma.body, "Sunday 9 August")
+	assert.NotContains(t, emma.body, "Sunday 2 August", "only the shifts she is on")
+
+	for _, req := range store.requests {
+		assert.Empty(t, req.SentAt, "an allocation email stamps no availability request")
+	}
+}
+
+// TestSendAllocationReadsTheRotaDefaultTimes: a shift minted before the drop-in
+// had times reads the Rota Defaults' rather than none.
+func TestSendAllocationReadsTheRotaDefaultTimes(t *testing.T) {
+	store := allocatedSendStore()
+	store.defaults = db.RotaDefaults{ShiftStartTime: "19:00", ShiftEndTime: "21:30"}
+	mailer := &mockMailer{}
+
+	send(t, store, mailer, sendParams(SendModeAllocation))
+
+	sara := mailer.sent[2]
+	assert.Contains(t, sara.body, "- Sunday 2 August, 18:30–21:00", "a shift's own times win")
+	assert.Contains(t, sara.body, "- Sunday 9 August, 19:00–21:30")
+}
+
+// TestSendAllocationPicksTheLatestAllocatedRota: by the time an admin tells
+// people about a rota, the next one is usually in flight, and it is not the
+// one they mean.
+func TestSendAllocationPicksTheLatestAllocatedRota(t *testing.T) {
+	store := allocatedSendStore()
+	store.rotations = append(store.rotations, db.Rotation{ID: "rota-2", Start: "2026-08-16", End: "2026-08-23", ShiftCount: 2})
+	mailer := &mockMailer{}
+
+	report := send(t, store, mailer, sendParams(SendModeAllocation))
+
+	assert.Len(t, report.Sent, 3)
+}
+
+// TestSendModesAndAllocation: the availability modes stop at allocation and the
+// allocation mode starts there, and only the availability modes ask for a
+// deadline.
+func TestSendModesAndAllocation(t *testing.T) {
+	tests := []struct {
+		name      string
+		allocated bool
+		mode      SendMode
+		deadline  string
+		wantErr   error
+	}{
+		{name: "allocation send after allocation", allocated: true, mode: SendModeAllocation},
+		{name: "allocation send before allocation", mode: SendModeAllocation, wantErr: ErrConflict},
+		{name: "round after allocation", allocated: true, mode: SendModeRound, deadline: "Friday", wantErr: ErrConflict},
+		{name: "round without a deadline", mode: SendModeRound, wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := allocatedSendStore()
+			if !tt.allocated {
+				store.rotations[0].AllocatedDatetime = ""
+			}
+			params := sendParams(tt.mode)
+			params.Deadline = tt.deadline
+
+			recorded, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, params, zap.NewNop())
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, store.sends)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "rota-1", recorded.RotaID)
+			assert.Empty(t, recorded.Deadline)
+		})
+	}
+}
+
+// swapCoverID is the rota change coverSendStore records.
+const swapCoverID = "6f1c2a9e-3b4d-4e5f-8a7b-9c0d1e2f3a4b"
+
+// coverSendStore is allocatedSendStore after Sara and Emma swapped: Sara off
+// the first shift and onto the second, Emma the other way round. The same
+// change took the visiting group off the second.
+func coverSendStore() *mockAvailabilityStore {
+	store := allocatedSendStore()
+	store.alterations = append(store.alterations,
+		db.Alteration{ID: "alt-2", ShiftID: "shift-1", Direction: "remove", VolunteerID: "sara", CoverID: swapCoverID},
+		db.Alteration{ID: "alt-3", ShiftID: "shift-1", Direction: "add", VolunteerID: "emma", Role: "Service volunteer", CoverID: swapCoverID},
+		db.Alteration{ID: "alt-4", ShiftID: "shift-2", Direction: "remove", VolunteerID: "emma", CoverID: swapCoverID},
+		db.Alteration{ID: "alt-5", ShiftID: "shift-2", Direction: "add", VolunteerID: "sara", Role: "Service volunteer", CoverID: swapCoverID},
+		db.Alteration{ID: "alt-6", ShiftID: "shift-2", Direction: "remove", CustomValue: "Visiting group", CoverID: swapCoverID},
+	)
+	return store
+}
+
+// TestSendCoverTellsEachVolunteerWhatChanged: a swap moves two people twice,
+// and each hears both halves of their own in one email — nobody else on the
+// rota hears anything. The send is recorded against the change, which is what
+// every admin reads back to see who was told.
+func TestSendCoverTellsEachVolunteerWhatChanged(t *testing.T) {
+	store := coverSendStore()
+	mailer := &mockMailer{}
+	params := sendParams(SendModeCover)
+	params.CoverID = swapCoverID
+
+	recorded := beginAndRun(t, store, mailer, params)
+
+	assert.Equal(t, "rota-1", recorded.RotaID)
+	assert.Equal(t, swapCoverID, recorded.CoverID)
+	assert.Empty(t, recorded.Deadline, "nothing to answer, so no deadline")
+	assert.Equal(t, []string{"emma@example.com", "sara@example.com"}, mailer.recipients(),
+		"in roster order, and the custom entry is nobody to email")
+
+	sara := mailer.sent[1]
+	assert.Contains(t, sara.body, "You are now on:\n- Sunday 9 August (Service volunteer)")
+	assert.Contains(t, sara.body, "You are no longer on:\n- Sunday 2 August")
+	assert.Contains(t, sara.body, "https://drop-in.example/calendars/"+store.calendarTokenOf("sara")+".ics")
+	assert.NotContains(t, sara.body, "availability/")
+	assert.Contains(t, sara.html, "<li>Sunday 9 August (Service volunteer)</li>")
+
+	emma := mailer.sent[0]
+	assert.Contains(t, emma.body, "- Sunday 2 August, 18:30–21:00 (Service volunteer)")
+
+	notified, err := ListCoverNotifications(context.Background(), store, swapCoverID, time.Now())
+	require.NoError(t, err)
+	require.Len(t, notified, 1)
+	assert.Equal(t, recorded.ID, notified[0].ID)
+	require.Len(t, notified[0].SentEmails, 2)
+	for _, s := range notified[0].SentEmails {
+		assert.NotEmpty(t, s.VolunteerName)
+		assert.Empty(t, s.Email, "the audit names who was told, not where")
+	}
+}
+
+// TestBeginCoverSend: a cover send is refused to the admin's face when there
+// is no such change, or when the change moved nobody who could be emailed.
+func TestBeginCoverSend(t *testing.T) {
+	customOnly := "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d"
+	tests := []struct {
+		name    string
+		coverID string
+		wantErr error
+	}{
+		{name: "a swap", coverID: swapCoverID},
+		{name: "no change named", coverID: "", wantErr: ErrInvalidInput},
+		{name: "not an id", coverID: "yesterday", wantErr: ErrNotFound},
+		{name: "no such change", coverID: "11111111-2222-4333-8444-555555555555", wantErr: ErrNotFound},
+		{name: "only custom entries moved", coverID: customOnly, wantErr: ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := coverSendStore()
+			store.alterations = append(store.alterations,
+				db.Alteration{ID: "alt-7", ShiftID: "shift-1", Direction: "add", CustomValue: "Church group", CoverID: customOnly})
+			params := sendParams(SendModeCover)
+			params.CoverID = tt.coverID
+
+			recorded, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, params, zap.NewNop())
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, store.sends)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "rota-1", recorded.RotaID)
+		})
+	}
+}
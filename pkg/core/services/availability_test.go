@@ -16,7 +16,7 @@ import (
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 )
 
-// mockAvailabilityStore is an in-memory AvailabilityStore. It reproduces the two
+// mockAvailabilityStore is an in-memory AvailabilitySendStore. It reproduces the two
 // behaviours the service leans on rather than mocking them away: minting skips a
 // volunteer who already holds a request, and reads take the newest generation.
 type mockAvailabilityStore struct {
@@ -29,6 +29,46 @@ type mockAvailabilityStore struct {
 	generations []db.AvailabilityGeneration
 	pins        []db.Preallocation
 	nextID      int
+
+	// sends and outcomes are the recorded sends; their methods live with the
+	// tests that read them, in availabilitySendHistory_test.go.
+	sends    []db.AvailabilitySend
+	outcomes []db.AvailabilitySendOutcome
+
+	// defaults is the settings record a send reads its wording from. Zero
+	// means nothing reworded, so every email reads as its default.
+	defaults db.RotaDefaults
+
+	// allocations and alterations are an allocated rota, for the send that
+	// tells volunteers their shifts.
+	allocations []db.Allocation
+	alterations []db.Alteration
+
+	// swaps and covers are volunteers' swap requests and the rota changes
+	// that made them; their methods live in swapRequests_test.go.
+	swaps  []db.SwapRequest
+	covers []db.Cover
+
+	// coverRequests and coverTokens are cover requests and the links minted
+	// for them; their methods live in coverRequests_test.go.
+	coverRequests []db.CoverRequest
+	coverTokens   []db.CoverRequestToken
+
+	// attendance is who turned up to the shifts that have happened; its
+	// methods live in attendance_test.go.
+	attendance []db.Attendance
+
+	// calendarTokens address the volunteers' calendar feeds; their methods
+	// live in calendarTokens_test.go.
+	calendarTokens []db.CalendarToken
+
+	// absences are volunteers' days away; their methods live in
+	// absences_test.go.
+	absences []db.Absence
+}
+
+func (m *mockAvailabilityStore) GetRotaDefaults(context.Context) (db.RotaDefaults, error) {
+	return m.defaults, nil
 }
 
 func (m *mockAvailabilityStore) GetPreallocationsByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Preallocation, error) {
@@ -132,13 +172,14 @@ func (m *mockAvailabilityStore) GetLatestAvailability(_ context.Context, request
 	return latest, nil
 }
 
-func (m *mockAvailabilityStore) InsertAvailabilityResponse(_ context.Context, requestID string, answers []db.ShiftAnswer) (*db.AvailabilityGeneration, error) {
+func (m *mockAvailabilityStore) InsertAvailabilityResponse(_ context.Context, requestID string, answers []db.ShiftAnswer, preferredShiftCount int) (*db.AvailabilityGeneration, error) {
 	m.nextID++
 	generation := db.AvailabilityGeneration{
-		RequestID:   requestID,
-		ResponseID:  "response-" + string(rune('a'+m.nextID)),
-		SubmittedAt: time.Date(2026, 7, 30, 12, 0, m.nextID, 0, time.UTC),
-		Answers:     answers,
+		RequestID:           requestID,
+		ResponseID:          "response-" + string(rune('a'+m.nextID)),
+		SubmittedAt:         time.Date(2026, 7, 30, 12, 0, m.nextID, 0, time.UTC),
+		Answers:             answers,
+		PreferredShiftCount: preferredShiftCount,
 	}
 	m.generations = append(m.generations, generation)
 	return &generation, nil
@@ -346,7 +387,7 @@ func TestSubmitStillAcceptsAnAnswerThatCannotCount(t *testing.T) {
 	}
 
 	form, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
-		token, []string{"shift-1"})
+		token, []string{"shift-1"}, 0)
 	require.NoError(t, err)
 	assert.True(t, form.Submitted)
 	assert.False(t, form.Counts, "and the confirmation still says it cannot count")
@@ -362,7 +403,7 @@ func TestAvailabilityFormShowsTheSubmittedState(t *testing.T) {
 	round := mintRound(t, store, volunteers, cfg)
 	token := tokenFor(t, round, "michael")
 
-	_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{"shift-2"})
+	_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{"shift-2"}, 0)
 	require.NoError(t, err)
 
 	form, err := GetAvailabilityForm(context.Background(), store, volunteers, cfg, zap.NewNop(), token)
@@ -372,7 +413,7 @@ func TestAvailabilityFormShowsTheSubmittedState(t *testing.T) {
 	assert.Equal(t, []string{"shift-2"}, form.SelectedShiftIDs)
 
 	// Resubmitting appends a generation and the latest wins wholesale.
-	_, err = SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{"shift-1"})
+	_, err = SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{"shift-1"}, 0)
 	require.NoError(t, err)
 
 	form, err = GetAvailabilityForm(context.Background(), store, volunteers, cfg, zap.NewNop(), token)
@@ -388,7 +429,7 @@ func TestSubmitNothingIsAnAnswer(t *testing.T) {
 	round := mintRound(t, store, volunteers, cfg)
 	token := tokenFor(t, round, "michael")
 
-	form, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, nil)
+	form, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, nil, 0)
 	require.NoError(t, err)
 	assert.True(t, form.Submitted)
 	assert.Empty(t, form.SelectedShiftIDs)
@@ -403,6 +444,52 @@ func TestSubmitNothingIsAnAnswer(t *testing.T) {
 	}
 }
 
+// TestSubmitRecordsAPreferredShiftCount: "free for all four but only want two"
+// comes back on the form, and on the round for the member and their group. It
+// is part of the generation, so a later answer without one clears it.
+func TestSubmitRecordsAPreferredShiftCount(t *testing.T) {
+	store, cfg := availabilityFixture()
+	volunteers := availabilityVolunteers()
+	round := mintRound(t, store, volunteers, cfg)
+	token := tokenFor(t, round, "michael")
+
+	form, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
+		token, []string{"shift-1", "shift-2"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, form.PreferredShiftCount)
+
+	updated, err := GetAvailabilityRound(context.Background(), store, volunteers, cfg, zap.NewNop(), "")
+	require.NoError(t, err)
+	for _, group := range updated.Groups {
+		for _, member := range group.Members {
+			if member.VolunteerID == "michael" {
+				assert.Equal(t, 1, member.PreferredShiftCount)
+				assert.Equal(t, 1, group.PreferredShiftCount, "his group works as one, so it works no more than he would like")
+			}
+		}
+	}
+
+	_, err = SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
+		token, []string{"shift-1", "shift-2"}, 0)
+	require.NoError(t, err)
+	form, err = GetAvailabilityForm(context.Background(), store, volunteers, cfg, zap.NewNop(), token)
+	require.NoError(t, err)
+	assert.Zero(t, form.PreferredShiftCount)
+}
+
+// TestSubmitRejectsAPreferredShiftCountTheAnswersCannotMeet: the form never
+// offers more than was ticked, so a count past that is a broken client.
+func TestSubmitRejectsAPreferredShiftCountTheAnswersCannotMeet(t *testing.T) {
+	store, cfg := availabilityFixture()
+	volunteers := availabilityVolunteers()
+	token := tokenFor(t, mintRound(t, store, volunteers, cfg), "michael")
+
+	for _, count := range []int{-1, 2} {
+		_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{"shift-1"}, count)
+		require.ErrorIs(t, err, ErrInvalidInput, "a count of %d must be refused", count)
+	}
+}
+
 // TestSubmitRejectsShiftsNotOnOffer: the form never offers a closed shift or one
 // from another rota, so a submission naming either is a broken client. Quietly
 // dropping it would record an answer the volunteer did not give.
@@ -412,7 +499,7 @@ func TestSubmitRejectsShiftsNotOnOffer(t *testing.T) {
 	token := tokenFor(t, mintRound(t, store, volunteers, cfg), "michael")
 
 	for _, shiftID := range []string{"shift-3", "shift-elsewhere"} {
-		_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{shiftID})
+		_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{shiftID}, 0)
 		require.ErrorIs(t, err, ErrInvalidInput, "shift %s must be refused", shiftID)
 	}
 }
@@ -429,7 +516,7 @@ func TestAvailabilityLinkStopsAtAllocation(t *testing.T) {
 	_, err := GetAvailabilityForm(context.Background(), store, volunteers, cfg, zap.NewNop(), token)
 	require.ErrorIs(t, err, ErrGone)
 
-	_, err = SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{"shift-1"})
+	_, err = SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(), token, []string{"shift-1"}, 0)
 	require.ErrorIs(t, err, ErrGone)
 }
 
@@ -452,7 +539,7 @@ func TestRoundReportsGroupCover(t *testing.T) {
 	round := mintRound(t, store, volunteers, cfg)
 
 	_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
-		tokenFor(t, round, "emma"), []string{"shift-1"})
+		tokenFor(t, round, "emma"), []string{"shift-1"}, 0)
 	require.NoError(t, err)
 
 	updated, err := GetAvailabilityRound(context.Background(), store, volunteers, cfg, zap.NewNop(), "")
@@ -486,7 +573,7 @@ func TestRoundDropsAVolunteerWhoHasStopped(t *testing.T) {
 
 	// Aaliyah answers, and then stops volunteering.
 	_, err := SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
-		tokenFor(t, round, "aaliyah"), []string{"shift-1"})
+		tokenFor(t, round, "aaliyah"), []string{"shift-1"}, 0)
 	require.NoError(t, err)
 	for i := range volunteers.volunteers {
 		if volunteers.volunteers[i].ID == "aaliyah" {
@@ -530,3 +617,51 @@ func TestRoundDropsAVolunteerOffTheRoster(t *testing.T) {
 	// Emma is left alone in the group she shared with Michael.
 	assert.Len(t, roundEntries(updated), 2)
 }
+
+// TestRoundAsksAVolunteerBackPartWayThrough: somebody paused until the
+// rota's second Sunday is back for it and the ones after, so they are asked,
+// listed, and told their answer counts — and are counted available from the
+// day they are back, not before. The rota is years ahead so the pause is still
+// running today, which is the day this used to be judged on.
+func TestRoundAsksAVolunteerBackPartWayThrough(t *testing.T) {
+	store := &mockAvailabilityStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: "2036-08-03", End: "2036-08-17", ShiftCount: 3}},
+		shifts: []db.Shift{
+			{ID: "shift-1", RotaID: "rota-1", Date: "2036-08-03"},
+			{ID: "shift-2", RotaID: "rota-1", Date: "2036-08-10"},
+			{ID: "shift-3", RotaID: "rota-1", Date: "2036-08-17"},
+		},
+	}
+	volunteers := &mockVolunteerClient{volunteers: []model.Volunteer{
+		{ID: "nina", FirstName: "Nina", LastName: "Osei", DisplayName: "Nina", Status: "Paused until 2036-08-06", Roles: []string{"Service volunteer"}},
+		{ID: "omar", FirstName: "Omar", LastName: "Aziz", DisplayName: "Omar", Status: "Paused until 2036-09-01", Roles: []string{"Service volunteer"}},
+	}}
+	cfg := &config.Config{}
+
+	round := mintRound(t, store, volunteers, cfg)
+	entries := roundEntries(round)
+	require.Len(t, entries, 1, "somebody back only after the rota is not asked about it")
+	assert.Equal(t, "nina", entries[0].VolunteerID)
+
+	token := tokenFor(t, round, "nina")
+	form, err := GetAvailabilityForm(context.Background(), store, volunteers, cfg, zap.NewNop(), token)
+	require.NoError(t, err)
+	assert.True(t, form.Counts)
+
+	_, err = SubmitAvailability(context.Background(), store, volunteers, cfg, zap.NewNop(),
+		token, []string{"shift-1", "shift-2", "shift-3"}, 0)
+	require.NoError(t, err)
+	updated, err := GetAvailabilityRound(context.Background(), store, volunteers, cfg, zap.NewNop(), "")
+	require.NoError(t, err)
+	require.Len(t, roundEntries(updated), 1)
+
+	available := map[string]int{}
+	for _, shift := range updated.Shifts {
+		for _, role := range shift.Roles {
+			if role.Role == "Service volunteer" {
+				available[shift.ShiftID] = role.Available
+			}
+		}
+	}
+	assert.Equal(t, map[string]int{"shift-1": 0, "shift-2": 1, "shift-3": 1}, available)
+}
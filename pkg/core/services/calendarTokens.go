@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	pkgutils "github.com/jakechorley/ilford-drop-in/pkg/utils"
+)
+
+// A Calendar Token is the secret a volunteer's calendar feed is addressed by.
+//
+// The feed used to be addressed by the volunteer's "Unique ID" from the sheet.
+// That is on every chip of the public rota page, so anybody could subscribe to
+// anybody's evenings, and there was nothing an admin could do about a URL that
+// had got out short of renumbering the volunteer. A token is random, and it is
+// a row: minting a new one is revoking the old.
+//
+// The ID-addressed URLs are still out there, in calendar apps the app cannot
+// reach, so they keep answering for a migration window the operator closes
+// (config.LegacyCalendarLinksOpen). Every fetch through one is logged with the
+// volunteer it was for, which is the list of people still to hand a new link.
+
+// CalendarTokenStore is what minting and resolving Calendar Tokens needs. Roles
+// come with it only because reading the roster takes them.
+type CalendarTokenStore interface {
+	RoleStore
+	GetCalendarTokenByToken(ctx context.Context, token string) (*db.CalendarToken, error)
+	GetCalendarTokens(ctx context.Context) ([]db.CalendarToken, error)
+	SaveCalendarToken(ctx context.Context, token db.CalendarToken) (db.CalendarToken, error)
+	InsertCalendarTokenIfAbsent(ctx context.Context, token db.CalendarToken) (bool, error)
+	DeleteCalendarToken(ctx context.Context, volunteerID string) (bool, error)
+}
+
+// ListCalendarTokens reads every volunteer's token.
+func ListCalendarTokens(ctx context.Context, store CalendarTokenStore) ([]db.CalendarToken, error) {
+	tokens, err := store.GetCalendarTokens(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// CalendarTokensByVolunteer is every volunteer's token keyed by their id, for
+// building the links an email hands out.
+func CalendarTokensByVolunteer(ctx context.Context, store CalendarTokenStore) (map[string]string, error) {
+	tokens, err := ListCalendarTokens(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	byVolunteer := make(map[string]string, len(tokens))
+	for _, t := range tokens {
+		byVolunteer[t.VolunteerID] = t.Token
+	}
+	return byVolunteer, nil
+}
+
+// MintCalendarToken gives a volunteer on the roster a new token, replacing any
+// they had. Replacing is the point when there is one: it is how an admin
+// revokes a feed URL that has got out while leaving the volunteer a working
+// one to be handed.
+func MintCalendarToken(
+	ctx context.Context,
+	store CalendarTokenStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	volunteerID string,
+	mintedBy string,
+	logger *zap.Logger,
+) (*db.CalendarToken, error) {
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	if _, ok := findVolunteer(volunteers, volunteerID); !ok {
+		return nil, wrapf(ErrNotFound, "volunteer %s not found", volunteerID)
+	}
+
+	token, err := pkgutils.RandomToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate calendar token: %w", err)
+	}
+	saved, err := store.SaveCalendarToken(ctx, db.CalendarToken{
+		VolunteerID: volunteerID,
+		Token:       token,
+		CreatedBy:   mintedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The token itself is never logged: it is the whole of the secret.
+	logger.Info("Calendar token minted",
+		zap.String("volunteer_id", volunteerID),
+		zap.String("by", mintedBy))
+	return &saved, nil
+}
+
+// MintMissingCalendarTokens gives every volunteer on the roster without a
+// token one, and leaves those who have one alone — somebody already subscribed
+// keeps the URL they subscribed to. It reports how many it minted.
+//
+// It is what an admin runs to move the roster off ID-addressed feeds, and what
+// a send about a decided rota runs before its emails, so every calendar link
+// it hands out is a token.
+func MintMissingCalendarTokens(
+	ctx context.Context,
+	store CalendarTokenStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	mintedBy string,
+	logger *zap.Logger,
+) (int, error) {
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return 0, err
+	}
+	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+
+	ids := make([]string, 0, len(volunteers))
+	for _, v := range volunteers {
+		ids = append(ids, v.ID)
+	}
+	_, minted, err := ensureCalendarTokens(ctx, store, ids, mintedBy)
+	if err != nil {
+		return minted, err
+	}
+
+	if minted > 0 {
+		logger.Info("Calendar tokens minted for the roster",
+			zap.Int("count", minted),
+			zap.String("by", mintedBy))
+	}
+	return minted, nil
+}
+
+// RevokeCalendarToken deletes a volunteer's token, so their feed URL stops
+// answering and nothing replaces it until an admin mints one.
+func RevokeCalendarToken(ctx context.Context, store CalendarTokenStore, volunteerID string, logger *zap.Logger) error {
+	deleted, err := store.DeleteCalendarToken(ctx, volunteerID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return wrapf(ErrNotFound, "volunteer %s has no calendar token", volunteerID)
+	}
+
+	logger.Info("Calendar token revoked", zap.String("volunteer_id", volunteerID))
+	return nil
+}
+
+// ResolveCalendarFeed is the volunteer a calendar feed was asked for by name —
+// the part of the URL before ".ics" — and whether it was asked for the old
+// way, by the volunteer's id.
+//
+// A token is tried first. Anything that is not one is taken for a volunteer id
+// while the migration window is open, and is not found once it has closed. The
+// caller logs a legacy fetch: it has the request, which says who is asking.
+func ResolveCalendarFeed(ctx context.Context, store CalendarTokenStore, cfg *config.Config, name string, now time.Time) (string, bool, error) {
+	token, err := store.GetCalendarTokenByToken(ctx, name)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to look up calendar token: %w", err)
+	}
+	if token != nil {
+		return token.VolunteerID, false, nil
+	}
+	if !cfg.LegacyCalendarLinksOpen(now) {
+		return "", false, wrapf(ErrNotFound, "calendar not found")
+	}
+	return name, true, nil
+}
+
+// ensureCalendarTokens is the token of each of the volunteers, minting one for
+// anybody without, and how many it minted. The map it returns holds everybody
+// else's as well.
+//
+// Two callers minting for the same volunteer at once is settled by the table:
+// the second insert does nothing, and the tokens are read again so the loser
+// hands out the winner's.
+func ensureCalendarTokens(ctx context.Context, store CalendarTokenStore, volunteerIDs []string, mintedBy string) (map[string]string, int, error) {
+	tokens, err := CalendarTokensByVolunteer(ctx, store)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	minted, raced := 0, false
+	for _, id := range volunteerIDs {
+		if _, ok := tokens[id]; ok {
+			continue
+		}
+		token, err := pkgutils.RandomToken()
+		if err != nil {
+			return nil, minted, fmt.Errorf("failed to generate calendar token: %w", err)
+		}
+		inserted, err := store.InsertCalendarTokenIfAbsent(ctx, db.CalendarToken{
+			VolunteerID: id,
+			Token:       token,
+			CreatedBy:   mintedBy,
+		})
+		if err != nil {
+			return nil, minted, err
+		}
+		if inserted {
+			tokens[id] = token
+			minted++
+		} else {
+			raced = true
+		}
+	}
+
+	if raced {
+		tokens, err = CalendarTokensByVolunteer(ctx, store)
+		if err != nil {
+			return nil, minted, err
+		}
+	}
+	return tokens, minted, nil
+}
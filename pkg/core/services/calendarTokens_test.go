@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The calendar token methods of mockAvailabilityStore: one token per
+// volunteer, saving replacing and inserting leaving an existing one alone, as
+// the table does.
+func (m *mockAvailabilityStore) GetCalendarTokenByToken(_ context.Context, token string) (*db.CalendarToken, error) {
+	for _, t := range m.calendarTokens {
+		if t.Token == token {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAvailabilityStore) GetCalendarTokens(context.Context) ([]db.CalendarToken, error) {
+	return m.calendarTokens, nil
+}
+
+func (m *mockAvailabilityStore) SaveCalendarToken(_ context.Context, t db.CalendarToken) (db.CalendarToken, error) {
+	t.CreatedAt = time.Now()
+	for i := range m.calendarTokens {
+		if m.calendarTokens[i].VolunteerID == t.VolunteerID {
+			m.calendarTokens[i] = t
+			return t, nil
+		}
+	}
+	m.calendarTokens = append(m.calendarTokens, t)
+	return t, nil
+}
+
+func (m *mockAvailabilityStore) InsertCalendarTokenIfAbsent(_ context.Context, t db.CalendarToken) (bool, error) {
+	for _, existing := range m.calendarTokens {
+		if existing.VolunteerID == t.VolunteerID {
+			return false, nil
+		}
+	}
+	t.CreatedAt = time.Now()
+	m.calendarTokens = append(m.calendarTokens, t)
+	return true, nil
+}
+
+func (m *mockAvailabilityStore) DeleteCalendarToken(_ context.Context, volunteerID string) (bool, error) {
+	for i, t := range m.calendarTokens {
+		if t.VolunteerID == volunteerID {
+			m.calendarTokens = append(m.calendarTokens[:i], m.calendarTokens[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// calendarTokenOf is the volunteer's token in the store, or "" for none.
+func (m *mockAvailabilityStore) calendarTokenOf(volunteerID string) string {
+	for _, t := range m.calendarTokens {
+		if t.VolunteerID == volunteerID {
+			return t.Token
+		}
+	}
+	return ""
+}
+
+func TestMintCalendarToken(t *testing.T) {
+	ctx := context.Background()
+	store := &mockAvailabilityStore{}
+
+	first, err := MintCalendarToken(ctx, store, sendVolunteers(), sendTestCfg, "sara", "admin@example.com", zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "sara", first.VolunteerID)
+	assert.Equal(t, "admin@example.com", first.CreatedBy)
+	assert.NotContains(t, first.Token, "sara")
+
+	second, err := MintCalendarToken(ctx, store, sendVolunteers(), sendTestCfg, "sara", "admin@example.com", zap.NewNop())
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Token, second.Token, "minting again is rotating")
+	require.Len(t, store.calendarTokens, 1)
+
+	_, err = MintCalendarToken(ctx, store, sendVolunteers(), sendTestCfg, "nobody", "admin@example.com", zap.NewNop())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMintMissingCalendarTokens(t *testing.T) {
+	ctx := context.Background()
+	store := &mockAvailabilityStore{calendarTokens: []db.CalendarToken{{VolunteerID: "sara", Token: "tok-sara"}}}
+
+	minted, err := MintMissingCalendarTokens(ctx, store, sendVolunteers(), sendTestCfg, "admin@example.com", zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, len(sendVolunteers().volunteers)-1, minted)
+	assert.Equal(t, "tok-sara", store.calendarTokenOf("sara"), "somebody already subscribed keeps their link")
+
+	minted, err = MintMissingCalendarTokens(ctx, store, sendVolunteers(), sendTestCfg, "admin@example.com", zap.NewNop())
+	require.NoError(t, err)
+	assert.Zero(t, minted)
+}
+
+func TestRevokeCalendarToken(t *testing.T) {
+	store := &mockAvailabilityStore{calendarTokens: []db.CalendarToken{{VolunteerID: "sara", Token: "tok-sara"}}}
+
+	require.NoError(t, RevokeCalendarToken(context.Background(), store, "sara", zap.NewNop()))
+	assert.Empty(t, store.calendarTokens)
+	assert.ErrorIs(t, RevokeCalendarToken(context.Background(), store, "sara", zap.NewNop()), ErrNotFound)
+}
+
+func TestResolveCalendarFeed(t *testing.T) {
+	store := &mockAvailabilityStore{calendarTokens: []db.CalendarToken{{VolunteerID: "sara", Token: "tok-sara"}}}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	closed := &config.Config{Server: &config.ServerConfig{LegacyCalendarLinksUntil: "2026-10-01"}}
+
+	tests := []struct {
+		name       string
+		cfg        *config.Config
+		feed       string
+		wantID     string
+		wantLegacy bool
+		wantErr    error
+	}{
+		{name: "token", cfg: sendTestCfg, feed: "tok-sara", wantID: "sara"},
+		{name: "token, window closed", cfg: closed, feed: "tok-sara", wantID: "sara"},
+		{name: "volunteer id, window open", cfg: sendTestCfg, feed: "emma", wantID: "emma", wantLegacy: true},
+		{name: "volunteer id, window closed", cfg: closed, feed: "emma", wantErr: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, legacy, err := ResolveCalendarFeed(context.Background(), store, tt.cfg, tt.feed, now)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantID, id)
+			assert.Equal(t, tt.wantLegacy, legacy)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -41,6 +42,13 @@ type ChangeRotaParams struct {
 	// would be checked against is frozen the moment the rota is allocated, so
 	// refusing would leave an extra pair of hands unrecordable (issue #185).
 	Role string
+	// SwapRequestID is the volunteer's swap request this change makes, if it
+	// is one. It is marked done in the change's own transaction, so a swap can
+	// neither be made twice nor be on the rota and still look unsettled.
+	SwapRequestID string
+	// CoverRequestID is the cover request this change fills, if it is one.
+	// It is closed in the change's own transaction, for the same reason.
+	CoverRequestID string
 }
 
 // ChangeRotaResult contains the result of a rota change. Alterations are keyed
@@ -213,6 +221,22 @@ func ChangeRota(
 		if err := store.InsertCoverAndAlterations(ctx, cover, alterations); err != nil {
 			return fmt.Errorf("failed to insert cover and alterations: %w", err)
 		}
+		if params.SwapRequestID != "" {
+			if err := store.CompleteSwapRequest(ctx, params.SwapRequestID, coverID); err != nil {
+				if errors.Is(err, db.ErrSwapRequestNotTaken) {
+					return wrapf(ErrConflict, "%v", err)
+				}
+				return err
+			}
+		}
+		if params.CoverRequestID != "" {
+			if err := store.FillCoverRequest(ctx, params.CoverRequestID, coverID, params.UserEmail); err != nil {
+				if errors.Is(err, db.ErrCoverRequestNotOpen) {
+					return wrapf(ErrConflict, "%v", err)
+				}
+				return err
+			}
+		}
 		return nil
 	})
 	if err != nil {
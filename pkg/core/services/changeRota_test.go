@@ -84,6 +84,14 @@ func (m *mockChangeRotaStore) InsertCoverAndAlterations(ctx context.Context, cov
 	return nil
 }
 
+func (m *mockChangeRotaStore) CompleteSwapRequest(context.Context, string, string) error {
+	return nil
+}
+
+func (m *mockChangeRotaStore) FillCoverRequest(context.Context, string, string, string) error {
+	return nil
+}
+
 // mockChangeRotaVolClient implements VolunteerClient for changeRota tests
 type mockChangeRotaVolClient struct {
 	volunteers []model.Volunteer
@@ -21,9 +21,14 @@ type VolunteerClient interface {
 	ListVolunteers(cfg *config.Config, roles model.Roles) ([]model.Volunteer, error)
 }
 
-// GmailClient sends one email. The server builds one per send from the token an
-// admin has just granted, so the interface is deliberately smaller than a mail
-// client: nothing here outlives the request that made it.
-type GmailClient interface {
-	SendEmail(to, subject, body string) error
+// MailClient sends one email. It is Gmail, as the admin who pressed send, by
+// default; a deployment can choose an SMTP relay or a directory of files
+// instead (config's mail block). The server builds one per send either way, so
+// the interface is deliberately smaller than a mail client: nothing here
+// outlives the send that made it.
+//
+// Every email has a plain-text body. html is the optional part alongside it,
+// empty for an email that has none.
+type MailClient interface {
+	SendEmail(to, subject, text, html string) error
 }
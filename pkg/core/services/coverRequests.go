@@ -0,0 +1,548 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services/utils"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	pkgutils "github.com/jakechorley/ilford-drop-in/pkg/utils"
+)
+
+// CoverRequestStore is what asking for cover needs: the shift and who is on
+// it now, the requests and their links, and — because filling one is an
+// ordinary rota change — everything one of those does.
+type CoverRequestStore interface {
+	ChangeRotaStore
+	RotaDefaultsStore
+	GetShiftByID(ctx context.Context, id string) (*db.ShiftInRange, error)
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+	InsertCoverRequest(ctx context.Context, req db.CoverRequest, tokens []db.CoverRequestToken) error
+	GetCoverRequest(ctx context.Context, id string) (*db.CoverRequest, error)
+	GetCoverRequestsByStatus(ctx context.Context, statuses []string) ([]db.CoverRequest, error)
+	GetCoverRequestToken(ctx context.Context, token string) (*db.CoverRequestToken, error)
+	GetCoverRequestTokens(ctx context.Context, coverRequestID string) ([]db.CoverRequestToken, error)
+	AnswerCoverRequest(ctx context.Context, token, answer string) error
+	CancelCoverRequest(ctx context.Context, id, adminEmail string) (bool, error)
+}
+
+// The answers a volunteer can give on their cover link.
+const (
+	CoverAnswerYes = "yes"
+	CoverAnswerNo  = "no"
+)
+
+// CoverRequestParams is an admin asking for cover. Replacing is the volunteer
+// dropping out, empty when the shift is simply short; Role is the one whoever
+// covers takes, and may be left empty when Replacing is set, to mean theirs.
+type CoverRequestParams struct {
+	ShiftID    string
+	Role       string
+	Replacing  string
+	Reason     string
+	AdminEmail string
+}
+
+// CoverAnswer is one volunteer's answer to a cover request.
+type CoverAnswer struct {
+	VolunteerID   string
+	VolunteerName string
+	AnsweredAt    time.Time
+}
+
+// CoverRequestView is one cover request as an admin reads it. Yes is everyone
+// who said they could, first to answer first; the rest are counted rather
+// than named, because a no and a silence ask nothing of the admin.
+type CoverRequestView struct {
+	ID            string
+	ShiftID       string
+	Date          string
+	Start         string
+	End           string
+	Role          string
+	Replacing     string
+	ReplacingName string
+	Reason        string
+	CreatedBy     string
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	// Expired is past the shift's start: the links have stopped answering,
+	// but the yeses they collected can still be acted on.
+	Expired bool
+	Status  string
+	CoverID string
+	Asked   int
+	Emailed int
+	No      int
+	Yes     []CoverAnswer
+}
+
+// CoverRequestForm is what a volunteer sees behind their cover link: the one
+// shift, and what they have said about it so far. Answer is empty until they
+// say.
+type CoverRequestForm struct {
+	FirstName string
+	Date      string
+	Start     string
+	End       string
+	Role      string
+	Answer    string
+}
+
+// CreateCoverRequest asks everyone who could cover a place on an allocated
+// rota whether they can: a link each for every active volunteer holding the
+// Role who is not already on the shift. Nobody is emailed here — that is a
+// send in its own mode, which needs the admin's Gmail — so the request exists,
+// and its links work, from the moment it is made.
+//
+// It is for after allocation. Before it, a gap is the allocator's to fill and
+// the round's links are how people say they are free.
+func CreateCoverRequest(
+	ctx context.Context,
+	store CoverRequestStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	params CoverRequestParams,
+	now time.Time,
+	logger *zap.Logger,
+) (*CoverRequestView, error) {
+	params.Reason = strings.TrimSpace(params.Reason)
+	if params.Reason == "" {
+		return nil, wrapf(ErrInvalidInput, "a reason is required: it is recorded against the change that fills the gap")
+	}
+	if _, err := uuid.Parse(params.ShiftID); err != nil {
+		return nil, wrapf(ErrNotFound, "shift %s not found", params.ShiftID)
+	}
+	shift, err := store.GetShiftByID(ctx, params.ShiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up shift %s: %w", params.ShiftID, err)
+	}
+	if shift == nil {
+		return nil, wrapf(ErrNotFound, "shift %s not found", params.ShiftID)
+	}
+	if !shift.Allocated {
+		return nil, wrapf(ErrConflict, "the rota for %s has not been allocated yet, so its availability round is still the way to find people", shift.Date)
+	}
+	if shift.Closed {
+		return nil, wrapf(ErrConflict, "the drop-in is closed on %s", shift.Date)
+	}
+
+	defaults, err := RotaDefaults(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	// The links stop answering when the shift begins: a yes that arrives once
+	// the doors are open is news to nobody.
+	expiresAt, _, err := defaults.ShiftInstants(shift.StartAt, shift.EndAt)
+	if err != nil {
+		return nil, fmt.Errorf("shift %s has no start time to ask for cover against: %w", shift.ID, err)
+	}
+	if !now.Before(expiresAt) {
+		return nil, wrapf(ErrConflict, "the shift on %s has already started", shift.Date)
+	}
+
+	onShift, err := effectiveAllocations(ctx, store, []string{shift.ID})
+	if err != nil {
+		return nil, err
+	}
+	working := make(map[string]db.Allocation)
+	for _, a := range onShift[shift.ID] {
+		if a.VolunteerID != "" {
+			working[a.VolunteerID] = a
+		}
+	}
+	if params.Replacing != "" {
+		place, ok := working[params.Replacing]
+		if !ok {
+			return nil, wrapf(ErrConflict, "volunteer %s is not on the shift on %s", params.Replacing, shift.Date)
+		}
+		if params.Role == "" {
+			params.Role = place.Role
+		}
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	role, ok := roles.ByName(params.Role)
+	if !ok {
+		return nil, wrapf(ErrInvalidInput, "unknown role %q: cover is asked of the people holding a Role", params.Role)
+	}
+	params.Role = role.Name
+
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	req := db.CoverRequest{
+		ID:        uuid.New().String(),
+		ShiftID:   shift.ID,
+		Role:      params.Role,
+		Replacing: params.Replacing,
+		Reason:    params.Reason,
+		CreatedBy: params.AdminEmail,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+		Status:    db.CoverRequestOpen,
+	}
+	// Asked of whoever is volunteering on the day itself, so somebody whose
+	// pause ends before it is asked too.
+	date, err := time.Parse(time.DateOnly, shift.Date)
+	if err != nil {
+		return nil, fmt.Errorf("shift %s has an unparseable date %q: %w", shift.ID, shift.Date, err)
+	}
+	var tokens []db.CoverRequestToken
+	for _, v := range roster {
+		if !utils.IsActiveOn(v, date) || !v.Holds(params.Role) {
+			continue
+		}
+		if _, already := working[v.ID]; already {
+			continue
+		}
+		token, err := pkgutils.RandomToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate cover request token: %w", err)
+		}
+		tokens = append(tokens, db.CoverRequestToken{Token: token, CoverRequestID: req.ID, VolunteerID: v.ID})
+	}
+	if len(tokens) == 0 {
+		return nil, wrapf(ErrConflict, "nobody else holds the %s Role, so there is nobody to ask", params.Role)
+	}
+
+	if err := store.InsertCoverRequest(ctx, req, tokens); err != nil {
+		if errors.Is(err, db.ErrDuplicateCoverRequest) {
+			return nil, wrapf(ErrConflict, "%v", err)
+		}
+		return nil, fmt.Errorf("failed to record cover request: %w", err)
+	}
+
+	logger.Info("Cover requested",
+		zap.String("cover_request_id", req.ID),
+		zap.String("shift_id", shift.ID),
+		zap.String("role", req.Role),
+		zap.String("replacing", req.Replacing),
+		zap.Int("asked", len(tokens)))
+
+	volunteers := volunteersByID(roster)
+	return coverRequestView(req, shift, tokens, volunteers, now), nil
+}
+
+func volunteersByID(roster []model.Volunteer) map[string]model.Volunteer {
+	volunteers := make(map[string]model.Volunteer, len(roster))
+	for _, v := range roster {
+		volunteers[v.ID] = v
+	}
+	return volunteers
+}
+
+// coverRequestView puts a request, its shift and its links together for an
+// admin.
+func coverRequestView(
+	req db.CoverRequest,
+	shift *db.ShiftInRange,
+	tokens []db.CoverRequestToken,
+	volunteers map[string]model.Volunteer,
+	now time.Time,
+) *CoverRequestView {
+	view := &CoverRequestView{
+		ID:        req.ID,
+		ShiftID:   req.ShiftID,
+		Role:      req.Role,
+		Replacing: req.Replacing,
+		Reason:    req.Reason,
+		CreatedBy: req.CreatedBy,
+		CreatedAt: req.CreatedAt,
+		ExpiresAt: req.ExpiresAt,
+		Expired:   !now.Before(req.ExpiresAt),
+		Status:    req.Status,
+		CoverID:   req.CoverID,
+		Asked:     len(tokens),
+		Yes:       []CoverAnswer{},
+	}
+	if shift != nil {
+		view.Date, view.Start, view.End = shift.Date, shift.StartAt, shift.EndAt
+	}
+	if req.Replacing != "" {
+		view.ReplacingName = nameOf(volunteers, req.Replacing)
+	}
+	for _, t := range tokens {
+		if t.SentAt != nil {
+			view.Emailed++
+		}
+		switch t.Answer {
+		case CoverAnswerYes:
+			view.Yes = append(view.Yes, CoverAnswer{
+				VolunteerID:   t.VolunteerID,
+				VolunteerName: nameOf(volunteers, t.VolunteerID),
+				AnsweredAt:    *t.AnsweredAt,
+			})
+		case CoverAnswerNo:
+			view.No++
+		}
+	}
+	return view
+}
+
+// ListCoverRequests is every open cover request, oldest first, with who has
+// said yes to each.
+func ListCoverRequests(
+	ctx context.Context,
+	store CoverRequestStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	now time.Time,
+) ([]CoverRequestView, error) {
+	requests, err := store.GetCoverRequestsByStatus(ctx, []string{db.CoverRequestOpen})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cover requests: %w", err)
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteers := volunteersByID(roster)
+
+	views := make([]CoverRequestView, 0, len(requests))
+	for _, req := range requests {
+		shift, err := store.GetShiftByID(ctx, req.ShiftID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up shift %s: %w", req.ShiftID, err)
+		}
+		tokens, err := store.GetCoverRequestTokens(ctx, req.ID)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *coverRequestView(req, shift, tokens, volunteers, now))
+	}
+	return views, nil
+}
+
+// coverLink resolves a volunteer's cover link to its request, refusing one
+// that has stopped answering. An unknown token is ErrNotFound; a request that
+// is filled, cancelled or past its shift's start is ErrGone, each with a
+// message the volunteer can read, because they are being told they are late
+// rather than wrong.
+func coverLink(ctx context.Context, store CoverRequestStore, token string, now time.Time) (*db.CoverRequestToken, *db.CoverRequest, error) {
+	link, err := store.GetCoverRequestToken(ctx, token)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to look up cover link: %w", err)
+	}
+	if link == nil {
+		return nil, nil, wrapf(ErrNotFound, "no cover request for this link")
+	}
+	req, err := store.GetCoverRequest(ctx, link.CoverRequestID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read cover request %s: %w", link.CoverRequestID, err)
+	}
+	if req == nil {
+		return nil, nil, wrapf(ErrNotFound, "no cover request for this link")
+	}
+	switch {
+	case req.Status == db.CoverRequestFilled:
+		return nil, nil, wrapf(ErrGone, "this shift has been covered - thank you for looking")
+	case req.Status == db.CoverRequestCancelled:
+		return nil, nil, wrapf(ErrGone, "cover is no longer needed for this shift")
+	case !now.Before(req.ExpiresAt):
+		return nil, nil, wrapf(ErrGone, "this shift has already started")
+	}
+	return link, req, nil
+}
+
+// GetCoverRequestForm resolves a volunteer's cover link to the shift it asks
+// about.
+func GetCoverRequestForm(
+	ctx context.Context,
+	store CoverRequestStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token string,
+	now time.Time,
+) (*CoverRequestForm, error) {
+	link, req, err := coverLink(ctx, store, token, now)
+	if err != nil {
+		return nil, err
+	}
+	shift, err := store.GetShiftByID(ctx, req.ShiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up shift %s: %w", req.ShiftID, err)
+	}
+	if shift == nil {
+		return nil, wrapf(ErrGone, "the shift this link asked about no longer exists")
+	}
+
+	form := &CoverRequestForm{
+		Date:   shift.Date,
+		Start:  shift.StartAt,
+		End:    shift.EndAt,
+		Role:   req.Role,
+		Answer: link.Answer,
+	}
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	if v, ok := findVolunteer(roster, link.VolunteerID); ok {
+		form.FirstName = v.FirstName
+	}
+	return form, nil
+}
+
+// AnswerCoverRequest records a volunteer's yes or no on their cover link. A
+// yes puts nobody on the rota: it tells the admins who to ask, and an admin
+// puts one of them on. Changing their mind is allowed until then.
+func AnswerCoverRequest(
+	ctx context.Context,
+	store CoverRequestStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token, answer string,
+	now time.Time,
+	logger *zap.Logger,
+) (*CoverRequestForm, error) {
+	if answer != CoverAnswerYes && answer != CoverAnswerNo {
+		return nil, wrapf(ErrInvalidInput, "answer must be %q or %q", CoverAnswerYes, CoverAnswerNo)
+	}
+	link, _, err := coverLink(ctx, store, token, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.AnswerCoverRequest(ctx, token, answer); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Cover request answered",
+		zap.String("cover_request_id", link.CoverRequestID),
+		zap.String("volunteer_id", link.VolunteerID),
+		zap.String("answer", answer))
+
+	return GetCoverRequestForm(ctx, store, volunteerClient, cfg, token, now)
+}
+
+// FillCoverRequest puts a volunteer who said yes on the shift: the one click
+// that turns an answer into an Alteration. It is an ordinary rota change —
+// the volunteer in, in the request's Role, and whoever was dropping out off —
+// recorded as a Cover that closes the request in the same transaction.
+//
+// A request past its shift's start can still be filled: the admin may be
+// recording, after the fact, who came.
+func FillCoverRequest(
+	ctx context.Context,
+	store CoverRequestStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	id, volunteerID, adminEmail string,
+	logger *zap.Logger,
+) (*ChangeRotaResult, error) {
+	req, err := openCoverRequest(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := store.GetCoverRequestTokens(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	said := false
+	for _, t := range tokens {
+		if t.VolunteerID == volunteerID && t.Answer == CoverAnswerYes {
+			said = true
+		}
+	}
+	if !said {
+		return nil, wrapf(ErrConflict, "volunteer %s has not said they can cover this shift", volunteerID)
+	}
+
+	shift, err := store.GetShiftByID(ctx, req.ShiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up shift %s: %w", req.ShiftID, err)
+	}
+	if shift == nil {
+		return nil, wrapf(ErrNotFound, "the shift for cover request %s no longer exists", id)
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteers := volunteersByID(roster)
+
+	result, err := ChangeRota(ctx, store, volunteerClient, cfg, ChangeRotaParams{
+		Date:           shift.Date,
+		In:             volunteerID,
+		Out:            req.Replacing,
+		Role:           req.Role,
+		Reason:         fmt.Sprintf("%s answered a cover request: %s", nameOf(volunteers, volunteerID), req.Reason),
+		UserEmail:      adminEmail,
+		CoverRequestID: req.ID,
+	}, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Cover request filled",
+		zap.String("cover_request_id", id),
+		zap.String("volunteer_id", volunteerID),
+		zap.String("admin_email", adminEmail),
+		zap.String("cover_id", result.CoverID))
+	return result, nil
+}
+
+// CancelCoverRequest stops asking. The links stop answering, and whoever
+// opens one is told cover is no longer needed.
+func CancelCoverRequest(ctx context.Context, store CoverRequestStore, id, adminEmail string, logger *zap.Logger) error {
+	if _, err := openCoverRequest(ctx, store, id); err != nil {
+		return err
+	}
+	cancelled, err := store.CancelCoverRequest(ctx, id, adminEmail)
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return wrapf(ErrConflict, "that cover request was closed while it was being cancelled")
+	}
+	logger.Info("Cover request cancelled",
+		zap.String("cover_request_id", id),
+		zap.String("admin_email", adminEmail))
+	return nil
+}
+
+// openCoverRequest reads a request an admin is acting on, refusing one that
+// is already closed. A malformed id is a miss like any other.
+func openCoverRequest(ctx context.Context, store CoverRequestStore, id string) (*db.CoverRequest, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, wrapf(ErrNotFound, "cover request %s not found", id)
+	}
+	req, err := store.GetCoverRequest(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cover request %s: %w", id, err)
+	}
+	if req == nil {
+		return nil, wrapf(ErrNotFound, "cover request %s not found", id)
+	}
+	if req.Status != db.CoverRequestOpen {
+		return nil, wrapf(ErrConflict, "that cover request is %s", req.Status)
+	}
+	return req, nil
+}
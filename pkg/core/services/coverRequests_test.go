@@ -0,0 +1,539 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+func (m *mockAvailabilityStore) coverRequest(id string) *db.CoverRequest {
+	for i := range m.coverRequests {
+		if m.coverRequests[i].ID == id {
+			return &m.coverRequests[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockAvailabilityStore) coverToken(token string) *db.CoverRequestToken {
+	for i := range m.coverTokens {
+		if m.coverTokens[i].Token == token {
+			return &m.coverTokens[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockAvailabilityStore) InsertCoverRequest(_ context.Context, req db.CoverRequest, tokens []db.CoverRequestToken) error {
+	for _, r := range m.coverRequests {
+		if r.ShiftID == req.ShiftID && r.Role == req.Role && r.Replacing == req.Replacing && r.Status == db.CoverRequestOpen {
+			return db.ErrDuplicateCoverRequest
+		}
+	}
+	m.coverRequests = append(m.coverRequests, req)
+	m.coverTokens = append(m.coverTokens, tokens...)
+	return nil
+}
+
+func (m *mockAvailabilityStore) GetCoverRequest(_ context.Context, id string) (*db.CoverRequest, error) {
+	if req := m.coverRequest(id); req != nil {
+		found := *req
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *mockAvailabilityStore) GetCoverRequestsByStatus(_ context.Context, statuses []string) ([]db.CoverRequest, error) {
+	var out []db.CoverRequest
+	for _, r := range m.coverRequests {
+		for _, status := range statuses {
+			if r.Status == status {
+				out = append(out, r)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) GetCoverRequestToken(_ context.Context, token string) (*db.CoverRequestToken, error) {
+	if t := m.coverToken(token); t != nil {
+		found := *t
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *mockAvailabilityStore) GetCoverRequestTokens(_ context.Context, coverRequestID string) ([]db.CoverRequestToken, error) {
+	var out []db.CoverRequestToken
+	for _, t := range m.coverTokens {
+		if t.CoverRequestID == coverRequestID {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// AnswerCoverRequest stamps each answer a minute after the last, as the
+// database's clock would, so the order they came in is the order they read.
+func (m *mockAvailabilityStore) AnswerCoverRequest(_ context.Context, token, answer string) error {
+	t := m.coverToken(token)
+	if t == nil {
+		return assert.AnError
+	}
+	answered := time.Date(2026, 8, 1, 10, len(m.coverTokens), 0, 0, time.UTC)
+	for _, other := range m.coverTokens {
+		if other.AnsweredAt != nil && !answered.After(*other.AnsweredAt) {
+			answered = other.AnsweredAt.Add(time.Minute)
+		}
+	}
+	t.Answer, t.AnsweredAt = answer, &answered
+	return nil
+}
+
+func (m *mockAvailabilityStore) MarkCoverRequestTokenSent(_ context.Context, token string) error {
+	if t := m.coverToken(token); t != nil {
+		now := time.Now()
+		t.SentAt = &now
+	}
+	return nil
+}
+
+func (m *mockAvailabilityStore) CancelCoverRequest(_ context.Context, id, adminEmail string) (bool, error) {
+	req := m.coverRequest(id)
+	if req == nil || req.Status != db.CoverRequestOpen {
+		return false, nil
+	}
+	closed := time.Now()
+	req.Status, req.ClosedBy, req.ClosedAt = db.CoverRequestCancelled, adminEmail, &closed
+	return true, nil
+}
+
+func (m *mockAvailabilityStore) FillCoverRequest(_ context.Context, id, coverID, adminEmail string) error {
+	req := m.coverRequest(id)
+	if req == nil || req.Status != db.CoverRequestOpen {
+		return db.ErrCoverRequestNotOpen
+	}
+	closed := time.Now()
+	req.Status, req.CoverID, req.ClosedBy, req.ClosedAt = db.CoverRequestFilled, coverID, adminEmail, &closed
+	return nil
+}
+
+// coverShiftID stands in for swapStore's shift-1, because a cover request is
+// made against a shift's id and ids are UUIDs.
+const coverShiftID = "3b5e6c1a-9a43-4f0e-8a55-2f3c4d5e6f70"
+
+// coverStore is swapStore with its first upcoming shift — Michael leading,
+// Sara beside him, 18:30 on Sunday 2 August — under a real id.
+func coverStore() *mockAvailabilityStore {
+	store := swapStore()
+	store.shifts[1].ID = coverShiftID
+	for i := range store.allocations {
+		if store.allocations[i].ShiftID == "shift-1" {
+			store.allocations[i].ShiftID = coverShiftID
+		}
+	}
+	return store
+}
+
+func coverParams(edit func(p *CoverRequestParams)) CoverRequestParams {
+	p := CoverRequestParams{
+		ShiftID:    coverShiftID,
+		Replacing:  "sara",
+		Reason:     "Sara is unwell",
+		AdminEmail: "admin@example.com",
+	}
+	if edit != nil {
+		edit(&p)
+	}
+	return p
+}
+
+func requestCover(t *testing.T, store *mockAvailabilityStore, params CoverRequestParams) *CoverRequestView {
+	t.Helper()
+	view, err := CreateCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, params, swapNow, zap.NewNop())
+	require.NoError(t, err)
+	return view
+}
+
+// coverTokenFor is the link a cover request handed one volunteer.
+func coverTokenFor(t *testing.T, store *mockAvailabilityStore, volunteerID string) string {
+	t.Helper()
+	for _, tok := range store.coverTokens {
+		if tok.VolunteerID == volunteerID {
+			return tok.Token
+		}
+	}
+	t.Fatalf("%s was not asked", volunteerID)
+	return ""
+}
+
+func answerCover(t *testing.T, store *mockAvailabilityStore, token, answer string) *CoverRequestForm {
+	t.Helper()
+	form, err := AnswerCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, token, answer, swapNow, zap.NewNop())
+	require.NoError(t, err)
+	return form
+}
+
+// TestCoverRequestAsksEveryoneWhoCouldCover: everyone active in the Role who
+// is not already on the shift gets a link — not the volunteer dropping out,
+// not the one beside them, and not somebody who has stopped volunteering.
+func TestCoverRequestAsksEveryoneWhoCouldCover(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    CoverRequestParams
+		wantRole  string
+		wantAsked []string
+	}{
+		{
+			name:      "replacing a volunteer takes their Role",
+			params:    coverParams(nil),
+			wantRole:  "Service volunteer",
+			wantAsked: []string{"emma"},
+		},
+		{
+			name:      "replacing the team lead",
+			params:    coverParams(func(p *CoverRequestParams) { p.Replacing = "michael" }),
+			wantRole:  "Team lead",
+			wantAsked: []string{"priya"},
+		},
+		{
+			name:      "a shift simply short names its Role",
+			params:    coverParams(func(p *CoverRequestParams) { p.Replacing, p.Role = "", "Service volunteer" }),
+			wantRole:  "Service volunteer",
+			wantAsked: []string{"emma"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := coverStore()
+
+			view := requestCover(t, store, tt.params)
+
+			assert.Equal(t, tt.wantRole, view.Role)
+			assert.Equal(t, len(tt.wantAsked), view.Asked)
+			var asked []string
+			for _, tok := range store.coverTokens {
+				assert.NotEmpty(t, tok.Token)
+				asked = append(asked, tok.VolunteerID)
+			}
+			assert.Equal(t, tt.wantAsked, asked)
+
+			require.Len(t, store.coverRequests, 1)
+			req := store.coverRequests[0]
+			assert.Equal(t, db.CoverRequestOpen, req.Status)
+			assert.Equal(t, "admin@example.com", req.CreatedBy)
+			assert.Equal(t, time.Date(2026, 8, 2, 17, 30, 0, 0, time.UTC), req.ExpiresAt.UTC(),
+				"the links stop at the shift's start, in the drop-in's own time")
+		})
+	}
+}
+
+// TestCoverRequestAsksAVolunteerBackByThen: a pause is judged on the shift's
+// own date, so somebody back by the day is asked and somebody back after it is
+// not — which leaves nobody to ask.
+func TestCoverRequestAsksAVolunteerBackByThen(t *testing.T) {
+	tests := []struct {
+		status  string
+		wantErr error
+	}{
+		{status: "Paused until 2036-08-02"},
+		{status: "Paused until 2036-08-03", wantErr: ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			store := coverStore()
+			now := aheadOfToday(store)
+			volunteers := swapVolunteers()
+			volunteers.volunteers[1].Status = tt.status // Emma
+
+			_, err := CreateCoverRequest(context.Background(), store, volunteers, sendTestCfg, coverParams(nil), now, zap.NewNop())
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, coverTokenFor(t, store, "emma"))
+		})
+	}
+}
+
+// TestCoverRequestRefusals: what an admin may not ask for, and how they are
+// told.
+func TestCoverRequestRefusals(t *testing.T) {
+	unallocated := coverStore()
+	unallocated.rotations[0].AllocatedDatetime = ""
+	lateStore := coverStore()
+
+	tests := []struct {
+		name    string
+		store   *mockAvailabilityStore
+		params  CoverRequestParams
+		now     time.Time
+		wantErr error
+	}{
+		{
+			name:    "no reason",
+			params:  coverParams(func(p *CoverRequestParams) { p.Reason = "  " }),
+			wantErr: ErrInvalidInput,
+		},
+		{
+			name:    "a shift id that is not one",
+			params:  coverParams(func(p *CoverRequestParams) { p.ShiftID = "shift-1" }),
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "a shift that does not exist",
+			params:  coverParams(func(p *CoverRequestParams) { p.ShiftID = "00000000-0000-4000-8000-000000000000" }),
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "a rota not allocated yet",
+			store:   unallocated,
+			params:  coverParams(nil),
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "a shift that has started",
+			store:   lateStore,
+			params:  coverParams(nil),
+			now:     time.Date(2026, 8, 2, 17, 30, 0, 0, time.UTC),
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "replacing somebody not on the shift",
+			params:  coverParams(func(p *CoverRequestParams) { p.Replacing = "emma" }),
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "a Role that does not exist",
+			params:  coverParams(func(p *CoverRequestParams) { p.Replacing, p.Role = "", "Cook" }),
+			wantErr: ErrInvalidInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			if store == nil {
+				store = coverStore()
+			}
+			now := tt.now
+			if now.IsZero() {
+				now = swapNow
+			}
+
+			_, err := CreateCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, tt.params, now, zap.NewNop())
+
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Empty(t, store.coverRequests)
+		})
+	}
+}
+
+// TestCoverRequestRefusesNobodyToAskAndAskingTwice: a Role whose every other holder is
+// already on the shift has nobody to ask, and asking twice for one place is
+// refused rather than collecting two sets of answers.
+func TestCoverRequestRefusesNobodyToAskAndAskingTwice(t *testing.T) {
+	store := coverStore()
+	store.allocations = append(store.allocations, db.Allocation{ID: "a9", ShiftID: coverShiftID, VolunteerID: "emma", Role: "Service volunteer"})
+	_, err := CreateCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, coverParams(nil), swapNow, zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict, "everyone else who serves is on already")
+
+	store = coverStore()
+	requestCover(t, store, coverParams(nil))
+	_, err = CreateCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, coverParams(nil), swapNow, zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Len(t, store.coverRequests, 1)
+}
+
+// TestAnswerCoverRequestLetsAVolunteerChangeTheirMind: the link shows the
+// shift and the answer they gave, and a second answer replaces the first.
+func TestAnswerCoverRequestLetsAVolunteerChangeTheirMind(t *testing.T) {
+	store := coverStore()
+	requestCover(t, store, coverParams(nil))
+	token := coverTokenFor(t, store, "emma")
+
+	form, err := GetCoverRequestForm(context.Background(), store, swapVolunteers(), sendTestCfg, token, swapNow)
+	require.NoError(t, err)
+	assert.Equal(t, "Emma", form.FirstName)
+	assert.Equal(t, "2026-08-02", form.Date)
+	assert.Equal(t, "2026-08-02T18:30:00", form.Start)
+	assert.Equal(t, "Service volunteer", form.Role)
+	assert.Empty(t, form.Answer)
+
+	form = answerCover(t, store, token, CoverAnswerNo)
+	assert.Equal(t, CoverAnswerNo, form.Answer)
+	form = answerCover(t, store, token, CoverAnswerYes)
+	assert.Equal(t, CoverAnswerYes, form.Answer)
+
+	views, err := ListCoverRequests(context.Background(), store, swapVolunteers(), sendTestCfg, swapNow)
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, "Sara Ali", views[0].ReplacingName)
+	assert.Equal(t, 0, views[0].No)
+	require.Len(t, views[0].Yes, 1)
+	assert.Equal(t, "Emma Williams", views[0].Yes[0].VolunteerName)
+
+	_, err = AnswerCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, token, "maybe", swapNow, zap.NewNop())
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Empty(t, store.covers, "a yes puts nobody on the rota by itself")
+}
+
+// TestFillCoverRequestChangesTheRota: accepting a yes is a rota change — the
+// volunteer on, the one dropping out off — and closes the request, so the
+// other links stop answering.
+func TestFillCoverRequestChangesTheRota(t *testing.T) {
+	store := coverStore()
+	view := requestCover(t, store, coverParams(nil))
+	token := coverTokenFor(t, store, "emma")
+	answerCover(t, store, token, CoverAnswerYes)
+
+	result, err := FillCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, view.ID, "emma", "admin@example.com", zap.NewNop())
+	require.NoError(t, err)
+
+	require.Len(t, store.covers, 1)
+	assert.Equal(t, result.CoverID, store.covers[0].ID)
+	assert.Equal(t, "admin@example.com", store.covers[0].UserEmail)
+	assert.Equal(t, "Emma Williams answered a cover request: Sara is unwell", store.covers[0].Reason)
+	require.Len(t, store.alterations, 2)
+	moves := map[string]string{}
+	for _, a := range store.alterations {
+		assert.Equal(t, coverShiftID, a.ShiftID)
+		moves[a.VolunteerID] = a.Direction
+	}
+	assert.Equal(t, map[string]string{"emma": "add", "sara": "remove"}, moves)
+
+	req := store.coverRequest(view.ID)
+	assert.Equal(t, db.CoverRequestFilled, req.Status)
+	assert.Equal(t, result.CoverID, req.CoverID)
+
+	_, err = GetCoverRequestForm(context.Background(), store, swapVolunteers(), sendTestCfg, token, swapNow)
+	assert.ErrorIs(t, err, ErrGone)
+	_, err = FillCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, view.ID, "emma", "admin@example.com", zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict, "a request is filled once")
+}
+
+// TestFillCoverRequestNeedsAYes: nobody is put on a shift they did not say
+// they could do.
+func TestFillCoverRequestNeedsAYes(t *testing.T) {
+	store := coverStore()
+	view := requestCover(t, store, coverParams(nil))
+	answerCover(t, store, coverTokenFor(t, store, "emma"), CoverAnswerNo)
+
+	_, err := FillCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, view.ID, "emma", "admin@example.com", zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = FillCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, view.ID, "priya", "admin@example.com", zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict, "somebody never asked")
+	assert.Empty(t, store.covers)
+}
+
+// TestCoverLinksStopAnswering: a link whose request has been cancelled, or
+// whose shift has started, tells the volunteer why rather than taking an
+// answer nobody will read.
+func TestCoverLinksStopAnswering(t *testing.T) {
+	tests := []struct {
+		name    string
+		close   func(t *testing.T, store *mockAvailabilityStore, id string)
+		now     time.Time
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name: "cancelled",
+			close: func(t *testing.T, store *mockAvailabilityStore, id string) {
+				require.NoError(t, CancelCoverRequest(context.Background(), store, id, "admin@example.com", zap.NewNop()))
+			},
+			now:     swapNow,
+			wantErr: ErrGone,
+			wantMsg: "no longer needed",
+		},
+		{
+			name:    "the shift has started",
+			close:   func(*testing.T, *mockAvailabilityStore, string) {},
+			now:     time.Date(2026, 8, 2, 18, 0, 0, 0, time.UTC),
+			wantErr: ErrGone,
+			wantMsg: "already started",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := coverStore()
+			view := requestCover(t, store, coverParams(nil))
+			token := coverTokenFor(t, store, "emma")
+			tt.close(t, store, view.ID)
+
+			_, err := GetCoverRequestForm(context.Background(), store, swapVolunteers(), sendTestCfg, token, tt.now)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			_, err = AnswerCoverRequest(context.Background(), store, swapVolunteers(), sendTestCfg, token, CoverAnswerYes, tt.now, zap.NewNop())
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := GetCoverRequestForm(context.Background(), coverStore(), swapVolunteers(), sendTestCfg, "tok-nobody", swapNow)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+// TestCancelCoverRequestTwice: a request is closed once.
+func TestCancelCoverRequestTwice(t *testing.T) {
+	store := coverStore()
+	view := requestCover(t, store, coverParams(nil))
+
+	require.NoError(t, CancelCoverRequest(context.Background(), store, view.ID, "admin@example.com", zap.NewNop()))
+	assert.Equal(t, "admin@example.com", store.coverRequest(view.ID).ClosedBy)
+
+	err := CancelCoverRequest(context.Background(), store, view.ID, "admin@example.com", zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict)
+	err = CancelCoverRequest(context.Background(), store, "cover-1", "admin@example.com", zap.NewNop())
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	views, err := ListCoverRequests(context.Background(), store, swapVolunteers(), sendTestCfg, swapNow)
+	require.NoError(t, err)
+	assert.Empty(t, views)
+}
+
+// TestSendCoverRequestChasesOnlyTheUnanswered: the emails carry each
+// volunteer's own cover link and the shift, and a second send leaves alone
+// everyone who has already said.
+func TestSendCoverRequestChasesOnlyTheUnanswered(t *testing.T) {
+	store := allocatedSendStore()
+	shift := store.shifts[0]
+	shift.ID = coverShiftID
+	store.shifts[0] = shift
+	requestID := "8d7c6b5a-4321-4f00-9e00-0123456789ab"
+	store.coverRequests = []db.CoverRequest{{
+		ID: requestID, ShiftID: coverShiftID, Role: "Service volunteer", Reason: "Short",
+		Status: db.CoverRequestOpen, ExpiresAt: time.Now().Add(48 * time.Hour),
+	}}
+	store.coverTokens = []db.CoverRequestToken{
+		{Token: "cover-emma", CoverRequestID: requestID, VolunteerID: "emma"},
+		{Token: "cover-michael", CoverRequestID: requestID, VolunteerID: "michael"},
+	}
+	params := sendParams(SendModeCoverRequest)
+	params.CoverRequestID = requestID
+
+	send := beginAndRun(t, store, &mockMailer{}, params)
+	assert.Equal(t, requestID, send.CoverRequestID)
+	assert.Equal(t, "rota-1", send.RotaID)
+
+	answered := time.Now()
+	store.coverToken("cover-michael").Answer = CoverAnswerNo
+	store.coverToken("cover-michael").AnsweredAt = &answered
+	mailer := &mockMailer{}
+	beginAndRun(t, store, mailer, params)
+
+	assert.Equal(t, []string{"emma@example.com"}, mailer.recipients(), "michael has said, so is not chased")
+	require.Len(t, mailer.sent, 1)
+	assert.Contains(t, mailer.sent[0].subject, "2 August")
+	assert.Contains(t, mailer.sent[0].body, "https://drop-in.example/cover/cover-emma")
+	assert.NotContains(t, mailer.sent[0].body, "availability/")
+	assert.NotNil(t, store.coverToken("cover-emma").SentAt)
+}
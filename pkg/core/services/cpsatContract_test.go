@@ -77,9 +77,13 @@ func TestCpsatInputContractGolden(t *testing.T) {
 			}},
 			AvailableShiftIndices:     []int{0, 2},
 			HistoricalAllocationCount: 3,
+			PreferredShiftCount:       1,
 		}},
 		HistoricalShifts: []allocator.CpsatHistoricalShift{{
-			Date: "2026-06-29", GroupKeys: []string{"couple_x"},
+			Date: "2026-06-29", GroupKeys: []string{"couple_x"}, Weight: 0.5,
+		}},
+		PairRules: []allocator.CpsatPairRule{{
+			VolunteerA: "vol-1", VolunteerB: "vol-9", Kind: allocator.PairApart,
 		}},
 	}
 
@@ -110,9 +114,11 @@ func TestCpsatInputContractGolden(t *testing.T) {
 				"roles": ["Service volunteer"]
 			}],
 			"available_shift_indices": [0, 2],
-			"historical_allocation_count": 3
+			"historical_allocation_count": 3,
+			"preferred_shift_count": 1
 		}],
-		"historical_shifts": [{"date": "2026-06-29", "group_keys": ["couple_x"]}]
+		"historical_shifts": [{"date": "2026-06-29", "group_keys": ["couple_x"], "weight": 0.5}],
+		"pair_rules": [{"volunteer_a": "vol-1", "volunteer_b": "vol-9", "kind": "apart"}]
 	}`
 
 	got, err := json.Marshal(input)
@@ -282,7 +288,7 @@ func TestBuildCpsatInput_HistoryKeysMatchCurrentRotaKeys(t *testing.T) {
 
 	targetRota := &db.Rotation{ID: "rota-1", Start: "2026-07-13", ShiftCount: 1}
 	historical, err := buildHistoricalShifts(
-		context.Background(), store, store.rotations, targetRota, volunteers, zap.NewNop())
+		context.Background(), store, store.rotations, targetRota, volunteers, model.AllocationSettings{}, zap.NewNop())
 	require.NoError(t, err)
 
 	groupAvailability := map[string][]int{
@@ -306,6 +312,7 @@ func TestBuildCpsatInput_HistoryKeysMatchCurrentRotaKeys(t *testing.T) {
 	last := input.HistoricalShifts[len(input.HistoricalShifts)-1]
 	require.Equal(t, "2026-07-06", last.Date)
 	assert.ElementsMatch(t, []string{"couple_ab", "Diana Green", "Eve Hall"}, last.GroupKeys)
+	assert.Equal(t, 1.0, last.Weight, "the previous rota counts in full")
 
 	// Every group in the problem is on that boundary, so none may take shift 0.
 	require.Len(t, input.Groups, 3)
@@ -423,6 +430,28 @@ func TestBuildCpsatInput_DoesNotMutateCallerAvailability(t *testing.T) {
 	assert.Equal(t, map[string][]int{"Alice Smith": {1}}, groupAvailability)
 }
 
+// Only rules between two volunteers who reached the solver go to it, and a
+// "together" rule inside one group says nothing the grouping does not.
+func TestPairRulesFor(t *testing.T) {
+	input := &allocator.CpsatInput{Groups: []allocator.CpsatGroup{
+		{GroupKey: "couple", Members: []allocator.CpsatMember{{ID: "mum"}, {ID: "dad"}}},
+		{GroupKey: "Alice Smith", Members: []allocator.CpsatMember{{ID: "alice"}}},
+	}}
+
+	kept := allocator.PairRulesFor(input, []allocator.CpsatPairRule{
+		{VolunteerA: "alice", VolunteerB: "mum", Kind: allocator.PairTogether},
+		{VolunteerA: "alice", VolunteerB: "left", Kind: allocator.PairApart},
+		{VolunteerA: "dad", VolunteerB: "mum", Kind: allocator.PairTogether},
+		{VolunteerA: "dad", VolunteerB: "mum", Kind: allocator.PairApart},
+	})
+
+	assert.Equal(t, []allocator.CpsatPairRule{
+		{VolunteerA: "alice", VolunteerB: "mum", Kind: allocator.PairTogether},
+		{VolunteerA: "dad", VolunteerB: "mum", Kind: allocator.PairApart},
+	}, kept)
+	assert.NotNil(t, allocator.PairRulesFor(input, nil), "an empty list goes over the wire as [], not null")
+}
+
 func TestCpsatOutputToAllocatorShifts(t *testing.T) {
 	volunteers := []allocator.Volunteer{
 		{ID: "alice", FirstName: "Alice", LastName: "Smith", DisplayName: "Alice", Gender: "Female", GroupKey: "couple_ab"},
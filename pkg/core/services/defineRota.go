@@ -11,6 +11,7 @@ import (
 
 	"github.com/jakechorley/ilford-drop-in/internal/config"
 	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services/utils"
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 )
 
@@ -276,7 +277,7 @@ func DefineRota(
 		Rotation:       rotation,
 		Shifts:         shifts,
 		Preallocations: preallocations,
-		Asked:          openRound(ctx, database, volunteerClient, cfg, logger, rotation.ID),
+		Asked:          openRound(ctx, database, volunteerClient, cfg, logger, rotation.ID, shifts),
 	}, nil
 }
 
@@ -297,6 +298,7 @@ func openRound(
 	cfg *config.Config,
 	logger *zap.Logger,
 	rotaID string,
+	shifts []db.Shift,
 ) int {
 	roles, err := RoleTable(ctx, database)
 	if err != nil {
@@ -312,7 +314,14 @@ func openRound(
 		return 0
 	}
 
-	asked, err := mintRequestsFor(ctx, database, logger, rotaID, volunteers)
+	shiftDates, err := utils.ShiftDatesFromShifts(shifts)
+	if err != nil {
+		logger.Warn("Defined the rota but could not read its shift dates to open its round",
+			zap.String("rotation_id", rotaID), zap.Error(err))
+		return 0
+	}
+
+	asked, err := mintRequestsFor(ctx, database, logger, rotaID, shiftDates, volunteers)
 	if err != nil {
 		logger.Warn("Defined the rota but could not open its round",
 			zap.String("rotation_id", rotaID), zap.Error(err))
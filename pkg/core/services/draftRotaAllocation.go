@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -73,6 +74,129 @@ type DraftRotaAllocationStatus struct {
 	// A Shift the solver left empty is absent rather than present and empty —
 	// there is nothing to say about it that the rota page does not already say.
 	Shifts []DraftShift
+	// EmptySeats is every Seat the draft left empty on an open Shift, and why,
+	// in date order. "Four Seats unfilled" says somebody needs chasing; this
+	// says who, or which rule to relax. Empty for an infeasible solve, which
+	// left no rota to have gaps in.
+	EmptySeats []EmptySeat
+	// Conflict is why an INFEASIBLE draft found no rota: the pins and rules
+	// that cannot all hold. Nil for a rota that solved, and for an infeasible
+	// one the solver could not narrow down.
+	Conflict *RotaConflict
+}
+
+// RotaConflict is a minimal set of pins and rules that cannot all hold —
+// undo any one and the rest can be met — so every member is something an
+// admin could change, and none is a bystander. Constraints are the solver's
+// own rule names, the same ones the Allocation Settings switch.
+type RotaConflict struct {
+	Constraints    []string
+	Preallocations []ConflictPin
+	// Description is the conflict as one sentence, e.g. "Preallocation of Ann
+	// on 2026-11-01 conflicts with no_back_to_back".
+	Description string
+}
+
+// ConflictPin is a Preallocation named in a conflict, keyed by Shift id like
+// everything else a draft reports, and named against the roster.
+type ConflictPin struct {
+	ShiftID     string
+	Date        string
+	VolunteerID string
+	Name        string
+	Role        string
+}
+
+// describeConflict names a solver's conflict against the rota and roster.
+// Nil in, nil out, so callers pass whatever the diagnostics carried.
+func describeConflict(
+	conflict *allocator.CpsatConflict,
+	shiftIDByDate map[string]string,
+	volunteersByID map[string]model.Volunteer,
+) *RotaConflict {
+	if conflict == nil {
+		return nil
+	}
+
+	described := &RotaConflict{
+		Constraints:    append([]string{}, conflict.Constraints...),
+		Preallocations: make([]ConflictPin, 0, len(conflict.Preallocations)),
+	}
+	pinned := make([]string, 0, len(conflict.Preallocations))
+	for _, pin := range conflict.Preallocations {
+		// A volunteer who has since left the roster is still named, by id:
+		// the pin is what needs undoing, and it names them that way too.
+		name := pin.VolunteerID
+		if volunteer, ok := volunteersByID[pin.VolunteerID]; ok && volunteer.DisplayName != "" {
+			name = volunteer.DisplayName
+		}
+		described.Preallocations = append(described.Preallocations, ConflictPin{
+			ShiftID:     shiftIDByDate[pin.Date],
+			Date:        pin.Date,
+			VolunteerID: pin.VolunteerID,
+			Name:        name,
+			Role:        pin.Role,
+		})
+		pinned = append(pinned, fmt.Sprintf("%s on %s", name, pin.Date))
+	}
+
+	rules := joinAnd(described.Constraints)
+	switch {
+	case len(pinned) == 1:
+		described.Description = fmt.Sprintf("Preallocation of %s conflicts with %s", pinned[0], rules)
+	case len(pinned) > 1:
+		described.Description = fmt.Sprintf("Preallocations of %s conflict with %s", joinAnd(pinned), rules)
+	case len(described.Constraints) == 1:
+		described.Description = fmt.Sprintf("The rule %s cannot be met", rules)
+	default:
+		described.Description = fmt.Sprintf("The rules %s cannot all be met together", rules)
+	}
+	return described
+}
+
+// joinAnd lists items the way a sentence does: "a", "a and b", "a, b and c".
+func joinAnd(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// EmptySeat is Count Seats of one Role on one Shift that a draft left empty,
+// and the reason allocator.ExplainEmptySeats gives for it: one of its EmptySeat*
+// codes or the name of the switchable rule that kept every candidate off, plus
+// that reason as a sentence.
+//
+// The JSON tags are the stored form. A draft's explanations are kept beside it
+// rather than worked out again on each read, because working them out needs the
+// solver's input, and that has moved on by the time anyone reads the draft.
+type EmptySeat struct {
+	ShiftID string `json:"shift_id"`
+	Role    string `json:"role"`
+	Count   int    `json:"count"`
+	Reason  string `json:"reason"`
+	Detail  string `json:"detail"`
+}
+
+// explainEmptySeats explains a solve's empty Seats and keys them onto the
+// Shifts they belong to. Never nil, so a stored draft with no gaps reads back
+// as an empty list rather than as one nobody explained.
+func explainEmptySeats(input *allocator.CpsatInput, output *allocator.CpsatOutput, shiftIDByDate map[string]string) []EmptySeat {
+	explained := allocator.ExplainEmptySeats(input, output)
+	empty := make([]EmptySeat, 0, len(explained))
+	for _, seat := range explained {
+		empty = append(empty, EmptySeat{
+			ShiftID: shiftIDByDate[seat.Date],
+			Role:    seat.Role,
+			Count:   seat.Count,
+			Reason:  seat.Reason,
+			Detail:  seat.Detail,
+		})
+	}
+	return empty
 }
 
 // DraftShift is one Shift's draft Seats. Keyed by Shift id rather than date,
@@ -199,7 +323,12 @@ func storeSolveAsDraft(
 		return nil, fmt.Errorf("failed to encode solver diagnostics: %w", err)
 	}
 
-	draft := solve.draft(solvedAt, diagnostics)
+	emptySeats, err := json.Marshal(solve.emptySeats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the empty seats: %w", err)
+	}
+
+	draft := solve.draft(solvedAt, diagnostics, emptySeats)
 	if err := database.ReplaceDraftRotaAllocation(ctx, draft, seats); err != nil {
 		return nil, fmt.Errorf("failed to store the draft rota allocation: %w", err)
 	}
@@ -241,7 +370,9 @@ func (s *rotaSolve) status(solvedAt time.Time, allocations []db.Allocation, logg
 		// again: the Sheet is a network call, and one read a solve cannot
 		// disagree with itself about is worth more than a fresher spelling of
 		// somebody's name.
-		Shifts: draftShifts(s.shifts, allocations, s.volunteersByID, s.roles, logger),
+		Shifts:     draftShifts(s.shifts, allocations, s.volunteersByID, s.roles, logger),
+		EmptySeats: s.emptySeats,
+		Conflict:   describeConflict(s.output.Diagnostics.Conflict, s.shiftIDByDate, s.volunteersByID),
 	}
 }
 
@@ -253,7 +384,7 @@ func (s *rotaSolve) status(solvedAt time.Time, allocations []db.Allocation, logg
 // one taken now. That is what makes a draft read as dirty when something moved
 // while the solver was running: erring that way costs a re-solve, and the other
 // way loses the change entirely (issue #142).
-func (s *rotaSolve) draft(solvedAt time.Time, diagnostics []byte) db.DraftRotaAllocation {
+func (s *rotaSolve) draft(solvedAt time.Time, diagnostics, emptySeats []byte) db.DraftRotaAllocation {
 	return db.DraftRotaAllocation{
 		RotaID:          s.rota.ID,
 		SolvedAt:        solvedAt,
@@ -261,6 +392,7 @@ func (s *rotaSolve) draft(solvedAt time.Time, diagnostics []byte) db.DraftRotaAl
 		SolverStatus:    s.output.SolverStatus,
 		ObjectiveValue:  s.output.ObjectiveValue,
 		Diagnostics:     diagnostics,
+		EmptySeats:      emptySeats,
 		InputsChangedAt: s.rota.InputsChangedAt,
 		SeatsAsked:      s.seatsAsked(),
 		SeatsFilled:     s.seatsFilled(),
@@ -337,6 +469,15 @@ func DraftRotaAllocationInFlight(
 	if err := json.Unmarshal(draft.Diagnostics, &status.Diagnostics); err != nil {
 		status.Diagnostics = allocator.CpsatDiagnostics{}
 	}
+	// The explanations are this layer's own JSON, so one that will not parse is
+	// a draft stored before they were kept (or a bug), and reads as unexplained
+	// rather than failing the read.
+	status.EmptySeats = []EmptySeat{}
+	if len(draft.EmptySeats) > 0 {
+		if err := json.Unmarshal(draft.EmptySeats, &status.EmptySeats); err != nil {
+			status.EmptySeats = []EmptySeat{}
+		}
+	}
 
 	shifts, err := database.GetShiftsByRotaID(ctx, rota.ID)
 	if err != nil {
@@ -377,6 +518,15 @@ func DraftRotaAllocationInFlight(
 		})
 	}
 	status.Shifts = draftShifts(shifts, allocations, volunteersByID, roles, logger)
+
+	// The conflict travelled inside the stored diagnostics, and is named here
+	// against the rota and roster as they stand now — a pin's Shift is still
+	// that Shift, and a renamed volunteer reads under their new name.
+	shiftIDByDate := make(map[string]string, len(shifts))
+	for _, shift := range shifts {
+		shiftIDByDate[shift.Date] = shift.ID
+	}
+	status.Conflict = describeConflict(status.Diagnostics.Conflict, shiftIDByDate, volunteersByID)
 	// Derived from the Seats rather than stored beside them, so a draft's
 	// fingerprint can never disagree with the draft it fingerprints. It is the
 	// same function the solve on the allocate path hashes its answer with, over
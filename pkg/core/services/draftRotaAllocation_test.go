@@ -284,7 +284,7 @@ func TestASolveCarriesTheRotasInputsStamp(t *testing.T) {
 		},
 	}
 
-	draft := solve.draft(solvedAt, []byte(`{}`))
+	draft := solve.draft(solvedAt, []byte(`{}`), []byte(`[]`))
 
 	assert.Equal(t, "rota-1", draft.RotaID)
 	assert.True(t, draft.InputsChangedAt.Equal(moved), "the stamp the solve began from, not the moment it ended")
@@ -293,3 +293,127 @@ func TestASolveCarriesTheRotasInputsStamp(t *testing.T) {
 	assert.Equal(t, 4, draft.SeatsAsked, "the open Shift's four Seats; the closed one asks for nobody")
 	assert.Equal(t, 3, draft.SeatsFilled)
 }
+
+// A draft says why it left each Seat empty, and says the same thing when it is
+// read back: the explanation needs the solver's input, so it is stored with the
+// draft rather than worked out again from inputs that have since moved.
+func TestDraftRotaAllocationExplainsItsEmptySeats(t *testing.T) {
+	store, volunteers := allocatableRota()
+	solved := solvedRota(map[string][]allocator.CpsatAssignment{
+		"2026-08-02": {{VolunteerID: "vol-1", Role: "Team lead"}, {VolunteerID: "vol-2", Role: "Service volunteer"}},
+	})
+
+	shown, err := SolveDraftRotaAllocation(context.Background(), store, volunteers, testCfg, zap.NewNop(), stubSolver(t, solved))
+	require.NoError(t, err)
+
+	var lead *EmptySeat
+	for i, seat := range shown.EmptySeats {
+		if seat.ShiftID == "2026-08-09" && seat.Role == "Team lead" {
+			lead = &shown.EmptySeats[i]
+		}
+	}
+	require.NotNil(t, lead, "the second Sunday's team lead Seat was left empty")
+	assert.Equal(t, 1, lead.Count)
+	assert.Equal(t, allocator.EmptySeatNotChosen, lead.Reason, "Ada was available and broke no rule")
+	assert.NotEmpty(t, lead.Detail)
+
+	readBack, err := DraftRotaAllocationInFlight(context.Background(), store, volunteers, testCfg, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, shown.EmptySeats, readBack.EmptySeats)
+}
+
+// A draft stored before empty Seats were explained reads back as explaining
+// none, rather than failing the read.
+func TestDraftRotaAllocationStoredWithoutExplanations(t *testing.T) {
+	store := &mockAllocateRotaStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: "2026-08-02", ShiftCount: 2}},
+		shifts:    sundayShifts("rota-1", "2026-08-02", 2),
+		storedDrafts: []db.DraftRotaAllocation{{
+			RotaID:       "rota-1",
+			Success:      true,
+			SolverStatus: "OPTIMAL",
+			Diagnostics:  []byte(`{}`),
+		}},
+	}
+
+	status, err := DraftRotaAllocationInFlight(context.Background(), store, &mockVolClient{}, testCfg, zap.NewNop())
+
+	require.NoError(t, err)
+	assert.NotNil(t, status.EmptySeats)
+	assert.Empty(t, status.EmptySeats)
+}
+
+// An infeasible draft says which pins and rules cannot all hold, named against
+// the rota and roster, and says it again when it is read back.
+func TestDraftRotaAllocationNamesTheConflictBehindAnInfeasibleRota(t *testing.T) {
+	store, volunteers := allocatableRota()
+	volunteers.volunteers[0].DisplayName = "Ada"
+	infeasible := allocator.CpsatOutput{
+		SolverStatus: "INFEASIBLE",
+		Diagnostics: allocator.CpsatDiagnostics{Conflict: &allocator.CpsatConflict{
+			Constraints: []string{"no_back_to_back"},
+			Preallocations: []allocator.CpsatConflictPin{
+				{ShiftIndex: 0, Date: "2026-08-02", VolunteerID: "vol-1", GroupKey: "Ada Active", Role: "Team lead"},
+				{ShiftIndex: 1, Date: "2026-08-09", VolunteerID: "vol-1", GroupKey: "Ada Active", Role: "Team lead"},
+			},
+		}},
+	}
+
+	shown, err := SolveDraftRotaAllocation(context.Background(), store, volunteers, testCfg, zap.NewNop(), stubSolver(t, infeasible))
+	require.NoError(t, err)
+
+	require.NotNil(t, shown.Conflict)
+	assert.Equal(t, []string{"no_back_to_back"}, shown.Conflict.Constraints)
+	assert.Equal(t, "Preallocations of Ada on 2026-08-02 and Ada on 2026-08-09 conflict with no_back_to_back", shown.Conflict.Description)
+	require.Len(t, shown.Conflict.Preallocations, 2)
+	assert.Equal(t, "2026-08-09", shown.Conflict.Preallocations[1].ShiftID, "keyed by Shift like the rest of the draft")
+
+	readBack, err := DraftRotaAllocationInFlight(context.Background(), store, volunteers, testCfg, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, shown.Conflict, readBack.Conflict)
+}
+
+func TestDescribeConflict(t *testing.T) {
+	volunteers := map[string]model.Volunteer{"ann": {ID: "ann", DisplayName: "Ann"}}
+	pinOn := func(id, date string) allocator.CpsatConflictPin {
+		return allocator.CpsatConflictPin{VolunteerID: id, Date: date, Role: "Team lead"}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		conflict *allocator.CpsatConflict
+		want     string
+	}{
+		{
+			name:     "one pin against one rule",
+			conflict: &allocator.CpsatConflict{Constraints: []string{"no_back_to_back"}, Preallocations: []allocator.CpsatConflictPin{pinOn("ann", "2026-11-01")}},
+			want:     "Preallocation of Ann on 2026-11-01 conflicts with no_back_to_back",
+		},
+		{
+			name: "a volunteer no longer on the roster is named by id",
+			conflict: &allocator.CpsatConflict{
+				Constraints:    []string{"seat_capacity"},
+				Preallocations: []allocator.CpsatConflictPin{pinOn("ann", "2026-11-01"), pinOn("gone", "2026-11-01")},
+			},
+			want: "Preallocations of Ann on 2026-11-01 and gone on 2026-11-01 conflict with seat_capacity",
+		},
+		{
+			name:     "a rule on its own",
+			conflict: &allocator.CpsatConflict{Constraints: []string{"male_required"}},
+			want:     "The rule male_required cannot be met",
+		},
+		{
+			name:     "rules against each other",
+			conflict: &allocator.CpsatConflict{Constraints: []string{"max_frequency", "male_required", "one_shift_per_month"}},
+			want:     "The rules max_frequency, male_required and one_shift_per_month cannot all be met together",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			described := describeConflict(tc.conflict, map[string]string{"2026-11-01": "shift-1"}, volunteers)
+			require.NotNil(t, described)
+			assert.Equal(t, tc.want, described.Description)
+		})
+	}
+
+	assert.Nil(t, describeConflict(nil, nil, nil), "a rota that solved has no conflict")
+}
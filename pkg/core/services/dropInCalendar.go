@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	ics "github.com/arran4/golang-ical"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+)
+
+// BuildDropInCalendar renders every shift as one subscribable iCal feed:
+// the drop-in's whole rota, for whoever keeps an eye on all of it rather than
+// on their own evenings. Pure (no I/O); callers pass the listing as
+// ListShifts returns it.
+//
+// Each event names everybody on the shift, by Role, which is the point of the
+// feed — a coordinator reading their week wants who is in on Sunday, not just
+// that there is a Sunday. A shift whose rota is not allocated yet is still an
+// event: the evening is happening whoever turns out to be on it, and saying so
+// is more use than a gap that fills in later.
+//
+// A closed shift is written as a cancellation of its event, as it is in a
+// volunteer's own feed, rather than left out: a client that already holds the
+// evening may keep it when the feed stops mentioning it. Times, reminders,
+// SEQUENCE and DTSTAMP are what BuildVolunteerCalendar gives them, and UIDs are
+// keyed on the date the same way, so a client updates an event in place as the
+// rota changes under it.
+func BuildDropInCalendar(shifts []Shift, roles model.Roles, defaults model.RotaDefaults, rotaURL string) (string, error) {
+	cal := newCalendar("Ilford Drop-In", defaults)
+
+	for _, shift := range shifts {
+		uid := fmt.Sprintf("all-%s@ilford-drop-in", shift.Date)
+		if shift.Closed {
+			if err := addCancelledEvent(cal, uid, shift, defaults, rotaURL); err != nil {
+				return "", err
+			}
+			continue
+		}
+		description := rotaShiftDescription(shift, roles, rotaURL)
+		if err := addShiftEvent(cal, uid, shift, "Ilford Drop-In shift", description, defaults, rotaURL); err != nil {
+			return "", err
+		}
+	}
+
+	// RFC 5545 requires CRLF line endings regardless of platform
+	return cal.Serialize(ics.WithNewLineWindows), nil
+}
+
+// BuildRoleCalendar renders the shifts that need the Role as a feed of their
+// own — every shift a Team lead is wanted on, say — for the people who
+// answer for that Role across the rota rather than for one evening of it.
+//
+// A shift needs the Role if its Shape asks for it or if somebody is on in it:
+// an alteration can add a Role the Shape never asked for, and the feed should
+// not pretend that person is not there. The summary says who is on in the
+// Role, since that is the one name a subscriber reads the feed for; the
+// description lists everyone, as the whole-drop-in feed does.
+//
+// A closed shift that asks for the Role is a cancellation of its event, as it
+// is in the whole-drop-in feed. UIDs carry the Role's ID rather than its name,
+// so renaming a Role neither duplicates nor drops anybody's events.
+func BuildRoleCalendar(shifts []Shift, role model.Role, roles model.Roles, defaults model.RotaDefaults, rotaURL string) (string, error) {
+	cal := newCalendar("Ilford Drop-In — "+role.Name, defaults)
+
+	for _, shift := range shifts {
+		names, asked := roleCalendarNames(shift, role)
+		if !asked {
+			continue
+		}
+		uid := fmt.Sprintf("role-%s-%s@ilford-drop-in", role.ID, shift.Date)
+		if shift.Closed {
+			if err := addCancelledEvent(cal, uid, shift, defaults, rotaURL); err != nil {
+				return "", err
+			}
+			continue
+		}
+
+		var summary string
+		switch {
+		case len(names) > 0:
+			summary = fmt.Sprintf("Ilford Drop-In shift (%s: %s)", role.Name, strings.Join(names, ", "))
+		case shift.Allocated:
+			summary = fmt.Sprintf("Ilford Drop-In shift (%s: nobody yet)", role.Name)
+		default:
+			summary = fmt.Sprintf("Ilford Drop-In shift (%s)", role.Name)
+		}
+
+		description := rotaShiftDescription(shift, roles, rotaURL)
+		if err := addShiftEvent(cal, uid, shift, summary, description, defaults, rotaURL); err != nil {
+			return "", err
+		}
+	}
+
+	// RFC 5545 requires CRLF line endings regardless of platform
+	return cal.Serialize(ics.WithNewLineWindows), nil
+}
+
+// roleCalendarNames is who is on the shift in the Role, and whether the shift needs
+// the Role at all. A Seat is matched by ID where both sides carry one and by
+// name otherwise, because a Role built without a database in scope has no ID.
+func roleCalendarNames(shift Shift, role model.Role) ([]string, bool) {
+	asked := false
+	for _, seat := range shift.Shape {
+		if seat.Count <= 0 {
+			continue
+		}
+		if (seat.Role.ID != "" && role.ID != "" && seat.Role.ID == role.ID) || seat.Role.Name == role.Name {
+			asked = true
+			break
+		}
+	}
+
+	var names []string
+	for _, a := range shift.Assignees {
+		if a.Role == role.Name {
+			names = append(names, a.Name)
+		}
+	}
+	return names, asked || len(names) > 0
+}
+
+// rotaShiftDescription is the body of one event in a feed that is not any one
+// volunteer's: everybody on the shift, in the order the listing sorts them —
+// the Roles filled first, first — and where to go to read the rota.
+func rotaShiftDescription(shift Shift, roles model.Roles, rotaURL string) string {
+	var lines []string
+
+	switch {
+	case !shift.Allocated:
+		// Not "nobody is on": an unallocated rota has people, the app just
+		// has not said who yet, and a draft is not for volunteers (ADR 0008).
+		lines = append(lines, "Who is on is decided when the rota is allocated.")
+	case len(shift.Assignees) == 0:
+		lines = append(lines, "Nobody is on this shift yet.")
+	default:
+		lines = append(lines, "On this shift:")
+		for _, a := range shift.Assignees {
+			if _, ok := roles.ByName(a.Role); ok {
+				lines = append(lines, a.Name+" — "+a.Role)
+			} else {
+				lines = append(lines, a.Name)
+			}
+		}
+	}
+
+	if rotaURL != "" {
+		lines = append(lines, "", "The whole rota: "+rotaURL)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+)
+
+// dropInCalendarShifts is a fortnight and a bit: an allocated shift with Alice
+// leading and Bob serving, a closed one that asked for a Team lead, and one
+// whose rota is not allocated yet but asks for a Team lead.
+func dropInCalendarShifts(t *testing.T) []Shift {
+	t.Helper()
+	teamLead, _ := testRoles.ByName("Team lead")
+	serviceVolunteer, _ := testRoles.ByName("Service volunteer")
+
+	allocated := calendarShift(t, "2026-01-12")
+	allocated.Allocated = true
+	allocated.Shape = model.Shape{{Role: teamLead, Count: 1}, {Role: serviceVolunteer, Count: 1}}
+	allocated.Assignees = []ShiftAssignee{
+		{VolunteerID: "alice", Name: "Alice", Role: "Team lead"},
+		{VolunteerID: "bob", Name: "Bob", Role: "Service volunteer"},
+	}
+
+	closed := calendarShift(t, "2026-01-19")
+	closed.Closed = true
+	closed.ClosureChanges = 1
+	closed.Shape = model.Shape{{Role: teamLead, Count: 1}}
+
+	unallocated := calendarShift(t, "2026-01-26")
+	unallocated.Shape = model.Shape{{Role: teamLead, Count: 1}}
+
+	return []Shift{allocated, closed, unallocated}
+}
+
+func TestBuildDropInCalendar(t *testing.T) {
+	out, err := BuildDropInCalendar(dropInCalendarShifts(t), testRoles, calendarTestDefaults, calendarTestURL)
+	require.NoError(t, err)
+	out = unfolded(out)
+
+	assert.Contains(t, out, "X-WR-CALNAME:Ilford Drop-In\r\n")
+	assert.Contains(t, out, "X-WR-TIMEZONE:Europe/London")
+	assert.Equal(t, 3, strings.Count(out, "BEGIN:VEVENT"))
+	assert.Equal(t, 1, strings.Count(out, "STATUS:CANCELLED"), "the closed shift is cancelled, not left out")
+	assert.Contains(t, out, "UID:all-2026-01-12@ilford-drop-in")
+	assert.Contains(t, out, "UID:all-2026-01-19@ilford-drop-in")
+	assert.Contains(t, out, "UID:all-2026-01-26@ilford-drop-in")
+	assert.Contains(t, out, "The drop-in is closed on this date.")
+	assert.Contains(t, out, "DTSTART:20260112T193000Z")
+	assert.Contains(t, out, `DESCRIPTION:On this shift:\nAlice — Team lead\nBob — Service volunteer`)
+	assert.Contains(t, out, "Who is on is decided when the rota is allocated.")
+	assert.Equal(t, 4, strings.Count(out, "BEGIN:VALARM"), "two reminders on each event")
+}
+
+func TestBuildRoleCalendar(t *testing.T) {
+	teamLead, _ := testRoles.ByName("Team lead")
+	serviceVolunteer, _ := testRoles.ByName("Service volunteer")
+
+	tests := []struct {
+		name       string
+		role       model.Role
+		wantEvents int
+		wantLines  []string
+	}{
+		{
+			name:       "team lead",
+			role:       teamLead,
+			wantEvents: 3,
+			wantLines: []string{
+				"UID:role-role-team-lead-2026-01-12@ilford-drop-in",
+				"UID:role-role-team-lead-2026-01-19@ilford-drop-in",
+				"STATUS:CANCELLED",
+				"SUMMARY:Ilford Drop-In shift (Team lead: Alice)",
+				"SUMMARY:Ilford Drop-In shift (Team lead)",
+			},
+		},
+		{
+			name:       "service volunteer, asked for only where allocated",
+			role:       serviceVolunteer,
+			wantEvents: 1,
+			wantLines:  []string{"SUMMARY:Ilford Drop-In shift (Service volunteer: Bob)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := BuildRoleCalendar(dropInCalendarShifts(t), tt.role, testRoles, calendarTestDefaults, calendarTestURL)
+			require.NoError(t, err)
+			out = unfolded(out)
+
+			assert.Contains(t, out, "X-WR-CALNAME:Ilford Drop-In — "+tt.role.Name)
+			assert.Equal(t, tt.wantEvents, strings.Count(out, "BEGIN:VEVENT"))
+			for _, line := range tt.wantLines {
+				assert.Contains(t, out, line+"\r\n")
+			}
+		})
+	}
+}
+
+// Somebody an alteration put on in a Role the Shape never asked for is still
+// on, and the Role's feed says so; an allocated shift that asks for the Role
+// but has nobody in it says that instead.
+func TestBuildRoleCalendar_ShapeAndAssigneesBothCount(t *testing.T) {
+	teamLead, _ := testRoles.ByName("Team lead")
+
+	added := calendarShift(t, "2026-02-02")
+	added.Allocated = true
+	added.Assignees = []ShiftAssignee{{CustomEntry: "Visiting lead", Name: "Visiting lead", Role: "Team lead"}}
+
+	short := calendarShift(t, "2026-02-09")
+	short.Allocated = true
+	short.Shape = model.Shape{{Role: teamLead, Count: 1}}
+
+	out, err := BuildRoleCalendar([]Shift{added, short}, teamLead, testRoles, calendarTestDefaults, calendarTestURL)
+	require.NoError(t, err)
+	out = unfolded(out)
+
+	assert.Contains(t, out, "SUMMARY:Ilford Drop-In shift (Team lead: Visiting lead)")
+	assert.Contains(t, out, "SUMMARY:Ilford Drop-In shift (Team lead: nobody yet)")
+	assert.Contains(t, out, "Nobody is on this shift yet.")
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The emails the drop-in sends are worded on the Settings screen rather than
+// in this package. The wording is a template per email, held with the Rota
+// Defaults; an email nobody has reworded reads as its default in
+// model.DefaultEmailTemplates, which is the wording that used to be written
+// here.
+
+// EmailTemplateParams is one email's wording as an admin states it.
+type EmailTemplateParams struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// validate turns an admin's wording into the template to store, or says why it
+// will not.
+//
+// It fills the template in against a volunteer who does not exist, which is
+// the only way to find a variable that is misspelled: text/template parses
+// "{{.FristName}}" happily and only fails when it looks the field up. A
+// template that passes here can be sent to anybody.
+func (p EmailTemplateParams) validate(kind model.EmailTemplateKind) (model.EmailTemplate, error) {
+	template := model.EmailTemplate{
+		Subject: strings.TrimSpace(p.Subject),
+		Text:    p.Text,
+		HTML:    p.HTML,
+	}
+	if strings.TrimSpace(template.HTML) == "" {
+		template.HTML = ""
+	}
+
+	if template.Subject == "" {
+		return model.EmailTemplate{}, wrapf(ErrInvalidInput, "an email needs a subject")
+	}
+	if strings.TrimSpace(template.Text) == "" {
+		return model.EmailTemplate{}, wrapf(ErrInvalidInput,
+			"an email needs a plain-text body: it is what every mail client can show, and what a volunteer with HTML switched off reads")
+	}
+
+	rendered, err := template.Render(model.ExampleEmailTemplateData)
+	if err != nil {
+		return model.EmailTemplate{}, wrapf(ErrInvalidInput, "%v", err)
+	}
+
+	if kind.RequiresLink {
+		link := model.ExampleEmailTemplateData.Link
+		if !strings.Contains(rendered.Text, link) {
+			return model.EmailTemplate{}, wrapf(ErrInvalidInput,
+				"the plain-text body has to include {{.Link}} - it is the volunteer's only way to answer")
+		}
+		if rendered.HTML != "" && !strings.Contains(rendered.HTML, link) {
+			return model.EmailTemplate{}, wrapf(ErrInvalidInput,
+				"the HTML body has to include {{.Link}} - it is the volunteer's only way to answer")
+		}
+	}
+	return template, nil
+}
+
+// emailTemplateKind looks up the email an admin named, refusing one there is no
+// template for.
+func emailTemplateKind(name string) (model.EmailTemplateKind, error) {
+	kind, ok := model.FindEmailTemplateKind(name)
+	if !ok {
+		return model.EmailTemplateKind{}, wrapf(ErrNotFound, "there is no %q email to reword", name)
+	}
+	return kind, nil
+}
+
+// SaveEmailTemplate writes the wording of one email and returns it as stored.
+func SaveEmailTemplate(
+	ctx context.Context,
+	store RotaDefaultsWriteStore,
+	kindName string,
+	params EmailTemplateParams,
+	logger *zap.Logger,
+) (model.EmailTemplate, error) {
+	kind, err := emailTemplateKind(kindName)
+	if err != nil {
+		return model.EmailTemplate{}, err
+	}
+	template, err := params.validate(kind)
+	if err != nil {
+		return model.EmailTemplate{}, err
+	}
+
+	document, err := json.Marshal(template)
+	if err != nil {
+		return model.EmailTemplate{}, fmt.Errorf("failed to encode the %s email template: %w", kind.Name, err)
+	}
+	if err := store.SaveEmailTemplate(ctx, kind.Name, string(document)); err != nil {
+		return model.EmailTemplate{}, err
+	}
+
+	logger.Info("Email template saved",
+		zap.String("kind", kind.Name),
+		zap.Bool("has_html", template.HTML != ""))
+	return template, nil
+}
+
+// ResetEmailTemplate puts one email back to its default wording, and returns
+// that wording.
+func ResetEmailTemplate(ctx context.Context, store RotaDefaultsWriteStore, kindName string, logger *zap.Logger) (model.EmailTemplate, error) {
+	kind, err := emailTemplateKind(kindName)
+	if err != nil {
+		return model.EmailTemplate{}, err
+	}
+	if err := store.ResetEmailTemplate(ctx, kind.Name); err != nil {
+		return model.EmailTemplate{}, err
+	}
+
+	logger.Info("Email template reset to its default", zap.String("kind", kind.Name))
+	return model.DefaultEmailTemplates[kind.Name], nil
+}
+
+// parseEmailTemplates reads the stored document, and answers "nothing
+// reworded" for anything it cannot make sense of — the rule
+// parseAllocationSettings follows, for the same reason: a document this build
+// cannot read must not take down the screen an admin would fix it on, and the
+// defaults are a safe thing to send.
+//
+// Wording for an email this build does not have is dropped. It would never be
+// sent, and the screen has no kind to show it under.
+func parseEmailTemplates(document string) model.EmailTemplates {
+	if document == "" {
+		return nil
+	}
+
+	var stored map[string]model.EmailTemplate
+	if err := json.Unmarshal([]byte(document), &stored); err != nil {
+		return nil
+	}
+
+	templates := make(model.EmailTemplates, len(stored))
+	for name, template := range stored {
+		if _, known := model.FindEmailTemplateKind(name); known {
+			templates[name] = template
+		}
+	}
+	return templates
+}
+
+// sendTemplateKind is which email a send mode sends. A resend is the original
+// invitation again, so it is worded as one.
+func sendTemplateKind(mode SendMode) string {
+	switch mode {
+	case SendModeReminder:
+		return model.EmailTemplateReminder
+	case SendModeAllocation:
+		return model.EmailTemplateAllocation
+	case SendModeCover:
+		return model.EmailTemplateCover
+	case SendModeCoverRequest:
+		return model.EmailTemplateCoverRequest
+	case SendModeSwapOffer:
+		return model.EmailTemplateSwapOffer
+	}
+	return model.EmailTemplateRound
+}
+
+// emailTemplateData is what a template can say about one volunteer's email for
+// one rota.
+func emailTemplateData(rota *db.Rotation, shifts []db.Shift, volunteer model.Volunteer, link, deadline string) model.EmailTemplateData {
+	dates := make([]string, 0, len(shifts))
+	for _, shift := range shifts {
+		if shift.Closed {
+			continue
+		}
+		dates = append(dates, weekdayDate(shift.Date))
+	}
+
+	return model.EmailTemplateData{
+		FirstName:  volunteer.FirstName,
+		Link:       link,
+		Deadline:   deadline,
+		ShiftDates: dates,
+		RotaStart:  readableDate(rota.Start),
+		RotaEnd:    readableDate(rota.End),
+	}
+}
+
+// weekdayDate is a shift's date the way an email names it: "Sunday 2 August".
+// The year is left off because a rota is weeks long and the email is read in
+// the weeks before it.
+func weekdayDate(date string) string {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return parsed.Format("Monday 2 January")
+}
+
+// EmailPreviewParams is one email to preview: wording that may not be saved
+// yet, filled in for a real volunteer on a real rota.
+type EmailPreviewParams struct {
+	Kind        string
+	Template    EmailTemplateParams
+	VolunteerID string
+	RotaID      string // empty means the latest rota
+	// Deadline is quoted as typed. Empty fills in an example, since nothing is
+	// being sent and the deadline is only ever chosen at send time.
+	Deadline     string
+	Link         func(token string) string
+	CoverLink    func(token string) string
+	SwapLink     func(token string) string
+	CalendarLink func(volunteerID string) string
+}
+
+// EmailPreview is an email as one volunteer would receive it.
+type EmailPreview struct {
+	To            string
+	VolunteerName string
+	model.RenderedEmail
+}
+
+// previewToken stands in for the link of a volunteer who has not been given
+// one for the rota — somebody who joined the roster after the round was
+// minted, say. The preview still shows where the link would sit.
+const previewToken = "preview"
+
+// PreviewEmailTemplate fills wording in for one volunteer on the roster, the
+// way a send would, without sending anything. It is checked the way a save is,
+// so a preview that renders is wording that will save.
+//
+// The allocation email is previewed against the latest allocated rota, and a
+// volunteer with no shifts on it — or a deployment with no allocated rota — is
+// shown the example ones: a preview listing nothing would not show what the
+// wording does with a list. The rota change email has no change to preview
+// against, so it is always shown the example one, and the cover request and
+// swap offer emails likewise the example shift. The swap offer's link is the
+// volunteer's own swap page, which is their availability link's token.
+func PreviewEmailTemplate(
+	ctx context.Context,
+	store AvailabilitySendStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	params EmailPreviewParams,
+) (*EmailPreview, error) {
+	kind, err := emailTemplateKind(params.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if params.Link == nil || params.CoverLink == nil || params.SwapLink == nil || params.CalendarLink == nil {
+		return nil, fmt.Errorf("preview params carry no link builder")
+	}
+	template, err := params.Template.validate(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	allocation := kind.Name == model.EmailTemplateAllocation
+	var rota *db.Rotation
+	if allocation {
+		rota, err = resolveSendRota(ctx, store, params.RotaID, SendModeAllocation)
+		// Nothing allocated yet is no reason to refuse a preview: the rota in
+		// hand is shown with the example shifts.
+		if errors.Is(err, ErrConflict) {
+			rota, err = resolveRota(ctx, store, params.RotaID)
+		}
+	} else {
+		rota, err = resolveRota(ctx, store, params.RotaID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := store.GetShiftsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteer, known := findVolunteer(volunteers, params.VolunteerID)
+	if !known {
+		return nil, wrapf(ErrNotFound, "volunteer %s is not on the roster", params.VolunteerID)
+	}
+
+	token := previewToken
+	requests, err := store.GetAvailabilityRequestsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch availability requests: %w", err)
+	}
+	for _, r := range requests {
+		if r.VolunteerID == volunteer.ID {
+			token = r.Token
+			break
+		}
+	}
+
+	deadline := strings.TrimSpace(params.Deadline)
+	if deadline == "" {
+		deadline = model.ExampleEmailTemplateData.Deadline
+	}
+
+	data := emailTemplateData(rota, shifts, volunteer, params.Link(token), deadline)
+	if allocation {
+		defaults, err := RotaDefaults(ctx, store)
+		if err != nil {
+			return nil, err
+		}
+		given, err := allocatedShifts(ctx, store, shifts, roles, defaults)
+		if err != nil {
+			return nil, err
+		}
+		data.Link, data.Deadline = "", ""
+		data.Shifts = given[volunteer.ID]
+		if len(data.Shifts) == 0 {
+			data.Shifts = model.ExampleEmailTemplateData.Shifts
+		}
+		data.CalendarLink = params.CalendarLink(volunteer.ID)
+	}
+	if kind.Name == model.EmailTemplateCover {
+		data.Link, data.Deadline = "", ""
+		data.Added = model.ExampleEmailTemplateData.Added
+		data.Removed = model.ExampleEmailTemplateData.Removed
+		data.CalendarLink = params.CalendarLink(volunteer.ID)
+	}
+	if kind.Name == model.EmailTemplateCoverRequest {
+		data.Link, data.Deadline = params.CoverLink(previewToken), ""
+		data.CoverShift = model.ExampleEmailTemplateData.CoverShift
+	}
+	if kind.Name == model.EmailTemplateSwapOffer {
+		data.Link, data.Deadline = params.SwapLink(token), ""
+		data.SwapShift = model.ExampleEmailTemplateData.SwapShift
+		data.SwapFrom = model.ExampleEmailTemplateData.SwapFrom
+	}
+
+	rendered, err := template.Render(data)
+	if err != nil {
+		return nil, wrapf(ErrInvalidInput, "%v", err)
+	}
+	return &EmailPreview{
+		To:            volunteer.Email,
+		VolunteerName: volunteerName(volunteer),
+		RenderedEmail: rendered,
+	}, nil
+}
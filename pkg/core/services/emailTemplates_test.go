@@ -0,0 +1,277 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The email-template methods of stubRotaDefaultsStore, which merge one key into
+// the stored document the way the real store does.
+func (s *stubRotaDefaultsStore) SaveEmailTemplate(_ context.Context, kind, template string) error {
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	return s.editEmailTemplates(func(templates map[string]json.RawMessage) {
+		templates[kind] = json.RawMessage(template)
+	})
+}
+
+func (s *stubRotaDefaultsStore) ResetEmailTemplate(_ context.Context, kind string) error {
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	return s.editEmailTemplates(func(templates map[string]json.RawMessage) {
+		delete(templates, kind)
+	})
+}
+
+func (s *stubRotaDefaultsStore) editEmailTemplates(edit func(map[string]json.RawMessage)) error {
+	templates := map[string]json.RawMessage{}
+	if s.defaults.EmailTemplates != "" {
+		if err := json.Unmarshal([]byte(s.defaults.EmailTemplates), &templates); err != nil {
+			return err
+		}
+	}
+	edit(templates)
+	document, err := json.Marshal(templates)
+	if err != nil {
+		return err
+	}
+	s.defaults.EmailTemplates = string(document)
+	return nil
+}
+
+func TestSaveEmailTemplateRefusesWordingThatCannotBeSent(t *testing.T) {
+	valid := EmailTemplateParams{
+		Subject: "Availability for {{.RotaStart}}",
+		Text:    "Hi {{.FirstName}}\n{{.Link}}\n",
+		HTML:    `<p>Hi {{.FirstName}}, <a href="{{.Link}}">answer here</a></p>`,
+	}
+
+	tests := []struct {
+		name    string
+		kind    string
+		edit    func(p *EmailTemplateParams)
+		wantErr error
+		wantMsg string
+	}{
+		{name: "valid", kind: model.EmailTemplateRound, edit: func(*EmailTemplateParams) {}},
+		{name: "no HTML is fine", kind: model.EmailTemplateReminder, edit: func(p *EmailTemplateParams) { p.HTML = "  " }},
+		{name: "unknown email", kind: "newsletter", edit: func(*EmailTemplateParams) {}, wantErr: ErrNotFound},
+		{name: "no subject", kind: model.EmailTemplateRound, edit: func(p *EmailTemplateParams) { p.Subject = " " }, wantErr: ErrInvalidInput, wantMsg: "subject"},
+		{name: "no text body", kind: model.EmailTemplateRound, edit: func(p *EmailTemplateParams) { p.Text = "" }, wantErr: ErrInvalidInput, wantMsg: "plain-text body"},
+		{name: "misspelled variable", kind: model.EmailTemplateRound, edit: func(p *EmailTemplateParams) { p.Text = "Hi {{.FristName}} {{.Link}}" }, wantErr: ErrInvalidInput, wantMsg: "FristName"},
+		{name: "not a template", kind: model.EmailTemplateRound, edit: func(p *EmailTemplateParams) { p.Subject = "Hi {{.FirstName" }, wantErr: ErrInvalidInput, wantMsg: "subject"},
+		{name: "text without the link", kind: model.EmailTemplateRound, edit: func(p *EmailTemplateParams) { p.Text = "Hi {{.FirstName}}" }, wantErr: ErrInvalidInput, wantMsg: "{{.Link}}"},
+		{name: "HTML without the link", kind: model.EmailTemplateReminder, edit: func(p *EmailTemplateParams) { p.HTML = "<p>Hi</p>" }, wantErr: ErrInvalidInput, wantMsg: "HTML body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &stubRotaDefaultsStore{}
+			params := valid
+			tt.edit(&params)
+
+			_, err := SaveEmailTemplate(context.Background(), store, tt.kind, params, zap.NewNop())
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.wantMsg)
+			assert.Empty(t, store.defaults.EmailTemplates, "a refused template must not be stored")
+		})
+	}
+}
+
+// TestEmailTemplatesSaveAndReset: a saved wording is what the email is sent
+// with from then on, and resetting it puts the default back.
+func TestEmailTemplatesSaveAndReset(t *testing.T) {
+	store := &stubRotaDefaultsStore{}
+	ctx := context.Background()
+
+	_, err := SaveEmailTemplate(ctx, store, model.EmailTemplateRound, EmailTemplateParams{
+		Subject: "Your rota link",
+		Text:    "{{.Link}}",
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	defaults, err := RotaDefaults(ctx, store)
+	require.NoError(t, err)
+	round, customised := defaults.EmailTemplates.For(model.EmailTemplateRound)
+	assert.True(t, customised)
+	assert.Equal(t, model.EmailTemplate{Subject: "Your rota link", Text: "{{.Link}}"}, round)
+	_, customised = defaults.EmailTemplates.For(model.EmailTemplateReminder)
+	assert.False(t, customised, "saving one email leaves the others on their defaults")
+
+	reset, err := ResetEmailTemplate(ctx, store, model.EmailTemplateRound, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, model.DefaultEmailTemplates[model.EmailTemplateRound], reset)
+
+	defaults, err = RotaDefaults(ctx, store)
+	require.NoError(t, err)
+	_, customised = defaults.EmailTemplates.For(model.EmailTemplateRound)
+	assert.False(t, customised)
+}
+
+// TestParseEmailTemplatesIsLenient: a document this build cannot read sends the
+// defaults rather than failing every page that reads the settings.
+func TestParseEmailTemplatesIsLenient(t *testing.T) {
+	assert.Nil(t, parseEmailTemplates(""))
+	assert.Nil(t, parseEmailTemplates("not json"))
+
+	parsed := parseEmailTemplates(`{"round":{"subject":"S","text":"T"},"newsletter":{"subject":"N","text":"N"}}`)
+	assert.Equal(t, model.EmailTemplates{model.EmailTemplateRound: {Subject: "S", Text: "T"}}, parsed,
+		"wording for an email this build does not have is dropped")
+}
+
+// TestEveryDefaultTemplateSaves: the defaults are held to the rules an admin's
+// wording is, or the first thing an admin does — save the default unchanged —
+// would be refused.
+func TestEveryDefaultTemplateSaves(t *testing.T) {
+	for _, kind := range model.EmailTemplateKinds {
+		template, ok := model.DefaultEmailTemplates[kind.Name]
+		require.True(t, ok, "%s has no default wording", kind.Name)
+
+		_, err := EmailTemplateParams{Subject: template.Subject, Text: template.Text, HTML: template.HTML}.validate(kind)
+		assert.NoError(t, err, kind.Name)
+	}
+}
+
+func TestPreviewEmailTemplateFillsInARealVolunteer(t *testing.T) {
+	store := sendStore()
+	store.shifts[1].Closed = true
+	params := EmailPreviewParams{
+		Kind: model.EmailTemplateRound,
+		Template: EmailTemplateParams{
+			Subject: "Availability for {{.RotaStart}} to {{.RotaEnd}}",
+			Text:    "Hi {{.FirstName}}, by {{.Deadline}}: {{join .ShiftDates \", \"}}\n{{.Link}}",
+			HTML:    `<p>Hi {{.FirstName}}</p><a href="{{.Link}}">answer</a>`,
+		},
+		VolunteerID:  "sara",
+		Link:         func(token string) string { return "https://drop-in.example/availability/" + token },
+		CoverLink:    func(token string) string { return "https://drop-in.example/cover/" + token },
+		SwapLink:     func(token string) string { return "https://drop-in.example/swaps/" + token },
+		CalendarLink: func(id string) string { return "https://drop-in.example/calendars/" + id + ".ics" },
+	}
+
+	preview, err := PreviewEmailTemplate(context.Background(), store, sendVolunteers(), sendTestCfg, params)
+	require.NoError(t, err)
+
+	assert.Equal(t, "sara@example.com", preview.To)
+	assert.Equal(t, "Sara Ali", preview.VolunteerName)
+	assert.Equal(t, "Availability for 2 August 2026 to 9 August 2026", preview.Subject)
+	assert.Equal(t, "Hi Sara, by Friday 7 August: Sunday 2 August\nhttps://drop-in.example/availability/tok-sara", preview.Text,
+		"the closed date is left out, and the volunteer's own link is used")
+	assert.Contains(t, preview.HTML, `href="https://drop-in.example/availability/tok-sara"`)
+
+	// Nothing was sent, so nothing is stamped.
+	for _, req := range store.requests {
+		assert.Empty(t, req.SentAt)
+	}
+}
+
+// TestPreviewAllocationEmail: the allocation email is previewed with the
+// volunteer's real shifts when the rota is allocated, and with the example
+// ones before anything is.
+func TestPreviewAllocationEmail(t *testing.T) {
+	template := model.DefaultEmailTemplates[model.EmailTemplateAllocation]
+	params := EmailPreviewParams{
+		Kind:         model.EmailTemplateAllocation,
+		Template:     EmailTemplateParams{Subject: template.Subject, Text: template.Text, HTML: template.HTML},
+		VolunteerID:  "emma",
+		Link:         func(token string) string { return "https://drop-in.example/availability/" + token },
+		CoverLink:    func(token string) string { return "https://drop-in.example/cover/" + token },
+		SwapLink:     func(token string) string { return "https://drop-in.example/swaps/" + token },
+		CalendarLink: func(id string) string { return "https://drop-in.example/calendars/" + id + ".ics" },
+	}
+
+	preview, err := PreviewEmailTemplate(context.Background(), allocatedSendStore(), sendVolunteers(), sendTestCfg, params)
+	require.NoError(t, err)
+	assert.Contains(t, preview.Text, "- Sunday 9 August (Service volunteer)")
+	assert.Contains(t, preview.Text, "https://drop-in.example/calendars/emma.ics")
+
+	preview, err = PreviewEmailTemplate(context.Background(), sendStore(), sendVolunteers(), sendTestCfg, params)
+	require.NoError(t, err, "nothing allocated yet is no reason to refuse")
+	assert.Contains(t, preview.Text, "Team Lead", "the example shifts stand in")
+}
+
+func TestPreviewEmailTemplateRefusals(t *testing.T) {
+	params := func(edit func(p *EmailPreviewParams)) EmailPreviewParams {
+		p := EmailPreviewParams{
+			Kind:         model.EmailTemplateReminder,
+			Template:     EmailTemplateParams{Subject: "S", Text: "{{.Link}}"},
+			VolunteerID:  "sara",
+			Link:         func(token string) string { return "https://drop-in.example/availability/" + token },
+			CoverLink:    func(token string) string { return "https://drop-in.example/cover/" + token },
+			SwapLink:     func(token string) string { return "https://drop-in.example/swaps/" + token },
+			CalendarLink: func(id string) string { return "https://drop-in.example/calendars/" + id + ".ics" },
+		}
+		edit(&p)
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		params  EmailPreviewParams
+		wantErr error
+	}{
+		{name: "volunteer not on the roster", params: params(func(p *EmailPreviewParams) { p.VolunteerID = "nobody" }), wantErr: ErrNotFound},
+		{name: "unknown email", params: params(func(p *EmailPreviewParams) { p.Kind = "newsletter" }), wantErr: ErrNotFound},
+		{name: "broken wording", params: params(func(p *EmailPreviewParams) { p.Template.Text = "{{.Nope}}" }), wantErr: ErrInvalidInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := PreviewEmailTemplate(context.Background(), sendStore(), sendVolunteers(), sendTestCfg, tt.params)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+// TestSendUsesTheSavedWording: a reworded email is what volunteers receive,
+// HTML part and all.
+func TestSendUsesTheSavedWording(t *testing.T) {
+	store := sendStore()
+	store.defaults = db.RotaDefaults{EmailTemplates: `{"round":{
+		"subject":"Rota {{.RotaStart}}, by {{.Deadline}}",
+		"text":"Hi {{.FirstName}} {{.Link}}",
+		"html":"<p>Hi {{.FirstName}} <a href=\"{{.Link}}\">here</a></p>"
+	}}`}
+	mailer := &mockMailer{}
+
+	send(t, store, mailer, sendParams(SendModeRound))
+
+	require.Len(t, mailer.sent, 3)
+	for _, mail := range mailer.sent {
+		assert.Equal(t, "Rota 2 August 2026, by Friday 7 August", mail.subject)
+		assert.Contains(t, mail.body, "https://drop-in.example/availability/tok-")
+		assert.Contains(t, mail.html, `<a href="https://drop-in.example/availability/tok-`)
+	}
+}
+
+// TestSendRefusesWordingThatCannotBeFilledIn: wording that got into the
+// database without being checked stops the send before the first email rather
+// than failing every one of them.
+func TestSendRefusesWordingThatCannotBeFilledIn(t *testing.T) {
+	store := sendStore()
+	store.defaults = db.RotaDefaults{EmailTemplates: `{"round":{"subject":"S","text":"{{.Nope}}"}}`}
+	mailer := &mockMailer{}
+
+	_, err := SendAvailabilityEmails(context.Background(), store, sendVolunteers(), mailer, sendTestCfg, zap.NewNop(), sendParams(SendModeRound))
+
+	require.ErrorIs(t, err, ErrInvalidInput)
+	assert.Empty(t, mailer.sent)
+	for _, req := range store.requests {
+		assert.Empty(t, req.SentAt)
+	}
+}
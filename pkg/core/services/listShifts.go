@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"slices"
 	"sort"
 	"time"
 
@@ -48,6 +49,9 @@ type ShiftAssignee struct {
 type Shift struct {
 	ID   string // UUID; how a client addresses the shift to change it
 	Date string // YYYY-MM-DD, the date the shift starts
+	// RotaID is the rota the shift belongs to, for what is done to a rota
+	// whole — exporting or printing it — from a page that lists shifts.
+	RotaID string
 	// StartAt and EndAt are the shift's own local wall-clock times,
 	// "2006-01-02T15:04:05", carrying no zone (ADR 0007). Both empty means a
 	// shift minted before an admin set the drop-in's times; readers that need a
@@ -67,7 +71,14 @@ type Shift struct {
 	Allocated       bool // rota's allocated_datetime is set; assignees are meaningful only when true
 	Assignees       []ShiftAssignee
 	AlterationCount int       // number of alterations recorded for the date
+	ClosureChanges  int       // times the shift has been closed or reopened
 	LastChanged     time.Time // latest alteration set_time for the date; zero if unaltered
+	// FormerVolunteerIDs is everybody the allocated shift has had on it — by
+	// allocation or by an Alteration adding them — who is not on it now, sorted.
+	// On a closed shift that is everybody it ever had. It is who a calendar owes
+	// a cancellation: their feed once carried the shift, and a client that
+	// simply stops seeing an event does not always drop it.
+	FormerVolunteerIDs []string
 }
 
 // ListShifts returns every minted shift in range (ADR 0001: the shift table is
@@ -137,6 +148,9 @@ func ListShifts(
 	for _, a := range allocations {
 		allocationsByShiftID[a.ShiftID] = append(allocationsByShiftID[a.ShiftID], a)
 	}
+	// Read before the alterations are applied, since applying one is what
+	// forgets whoever it removed.
+	everOn := volunteersEverOn(allocationsByShiftID, alterations)
 	allocationsByShiftID = utils.ApplyAlterations(allocationsByShiftID, alterations)
 
 	alterationCounts := make(map[string]int)
@@ -163,12 +177,14 @@ func ListShifts(
 		shift := Shift{
 			ID:              s.ID,
 			Date:            s.Date,
+			RotaID:          s.RotaID,
 			StartAt:         s.StartAt,
 			EndAt:           s.EndAt,
 			Closed:          s.Closed,
 			Shape:           shapes[s.ID],
 			Allocated:       s.Allocated,
 			AlterationCount: alterationCounts[s.ID],
+			ClosureChanges:  s.ClosureChanges,
 			LastChanged:     lastChanged[s.ID],
 		}
 
@@ -178,6 +194,9 @@ func ListShifts(
 		if shift.Allocated && !shift.Closed {
 			shift.Assignees = buildAssignees(allocationsByShiftID[s.ID], volunteersByID, roles, logger)
 		}
+		if shift.Allocated {
+			shift.FormerVolunteerIDs = formerVolunteers(everOn[s.ID], shift.Assignees)
+		}
 
 		shifts = append(shifts, shift)
 	}
@@ -186,23 +205,102 @@ func ListShifts(
 	return shifts, nil
 }
 
-// FilterShiftsByVolunteer returns the open shifts that include the given volunteer
-func FilterShiftsByVolunteer(shifts []Shift, volunteerID string) []Shift {
+// calendarCancellationRetention is how long after a shift's date a feed keeps
+// telling a former volunteer it is cancelled. A client polls every few hours,
+// so a day would do for one that is switched on; the rest is for the phone left
+// in a drawer over a holiday. After that the event is in the past whichever
+// way the client has it, and carrying it for ever would grow every feed with
+// every swap.
+const calendarCancellationRetention = 28 * 24 * time.Hour
+
+// VolunteerCalendarShifts is what a volunteer's feed carries: the open shifts
+// they are on, and the shifts they were on and no longer are — dropped by an
+// Alteration, or closed under them — until calendarCancellationRetention after
+// each one's date. BuildVolunteerCalendar writes the second kind as
+// cancellations.
+func VolunteerCalendarShifts(shifts []Shift, volunteerID string, now time.Time) []Shift {
 	filtered := make([]Shift, 0)
 	for _, s := range shifts {
-		if s.Closed {
+		if !s.Closed && onShift(s, volunteerID) {
+			filtered = append(filtered, s)
 			continue
 		}
-		for _, a := range s.Assignees {
-			if a.VolunteerID == volunteerID {
-				filtered = append(filtered, s)
-				break
-			}
+		if !slices.Contains(s.FormerVolunteerIDs, volunteerID) {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, s.Date)
+		if err != nil || date.Add(calendarCancellationRetention).Before(now) {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+	return filtered
+}
+
+// onShift reports whether the volunteer is among the shift's assignees.
+func onShift(shift Shift, volunteerID string) bool {
+	for _, a := range shift.Assignees {
+		if a.VolunteerID == volunteerID {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterShiftsByVolunteer returns the open shifts that include the given volunteer
+func FilterShiftsByVolunteer(shifts []Shift, volunteerID string) []Shift {
+	filtered := make([]Shift, 0)
+	for _, s := range shifts {
+		if !s.Closed && onShift(s, volunteerID) {
+			filtered = append(filtered, s)
 		}
 	}
 	return filtered
 }
 
+// volunteersEverOn is, per shift, every volunteer its base allocations or an
+// "add" Alteration put on it. Custom entries are left out: they have no feed.
+func volunteersEverOn(allocationsByShiftID map[string][]db.Allocation, alterations []db.Alteration) map[string]map[string]bool {
+	everOn := make(map[string]map[string]bool)
+	mark := func(shiftID, volunteerID string) {
+		if volunteerID == "" {
+			return
+		}
+		if everOn[shiftID] == nil {
+			everOn[shiftID] = make(map[string]bool)
+		}
+		everOn[shiftID][volunteerID] = true
+	}
+	for shiftID, allocations := range allocationsByShiftID {
+		for _, a := range allocations {
+			mark(shiftID, a.VolunteerID)
+		}
+	}
+	for _, alt := range alterations {
+		if alt.Direction == "add" {
+			mark(alt.ShiftID, alt.VolunteerID)
+		}
+	}
+	return everOn
+}
+
+// formerVolunteers is who of everOn is not among the assignees, sorted so a
+// feed built from it renders the same way every time.
+func formerVolunteers(everOn map[string]bool, assignees []ShiftAssignee) []string {
+	current := make(map[string]bool, len(assignees))
+	for _, a := range assignees {
+		current[a.VolunteerID] = true
+	}
+	var former []string
+	for id := range everOn {
+		if !current[id] {
+			former = append(former, id)
+		}
+	}
+	sort.Strings(former)
+	return former
+}
+
 // parseShiftDateBounds validates the optional from/to filters
 func parseShiftDateBounds(params ListShiftsParams) (from, to time.Time, err error) {
 	if params.From != "" {
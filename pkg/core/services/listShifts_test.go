@@ -283,6 +283,7 @@ func TestListShifts_AlterationsApplied(t *testing.T) {
 	assert.Equal(t, "charlie", shift.Assignees[0].VolunteerID)
 	assert.Equal(t, 2, shift.AlterationCount)
 	assert.Equal(t, time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC), shift.LastChanged)
+	assert.Equal(t, []string{"bob"}, shift.FormerVolunteerIDs)
 }
 
 func TestListShifts_DateFilters(t *testing.T) {
@@ -357,6 +358,8 @@ func TestListShifts_ClosedShift(t *testing.T) {
 
 	assert.True(t, shifts[0].Closed)
 	assert.Empty(t, shifts[0].Assignees)
+	assert.Equal(t, []string{"bob"}, shifts[0].FormerVolunteerIDs, "everybody a closed shift had is a former volunteer")
+	assert.Empty(t, shifts[1].FormerVolunteerIDs)
 	assert.False(t, shifts[1].Closed)
 	assert.Len(t, shifts[1].Assignees, 1)
 }
@@ -395,3 +398,46 @@ func TestFilterShiftsByVolunteer(t *testing.T) {
 
 	assert.Empty(t, FilterShiftsByVolunteer(shifts, "nobody"))
 }
+
+// Somebody taken off and put back on is on, not former; custom entries have no
+// feed to tell, so they are never former.
+func TestListShifts_FormerVolunteers(t *testing.T) {
+	store := &mockListShiftsStore{
+		allocations: []db.Allocation{
+			{ID: "a1", ShiftID: "2025-01-05", Role: "Service volunteer", VolunteerID: "bob"},
+			{ID: "a2", ShiftID: "2025-01-05", Role: "Service volunteer", VolunteerID: "alice"},
+			{ID: "a3", ShiftID: "2025-01-05", Role: "Service volunteer", CustomEntry: "St Mary's"},
+		},
+		alterations: []db.Alteration{
+			{ID: "alt1", ShiftID: "2025-01-05", Direction: "remove", VolunteerID: "bob", SetTime: "2025-01-01T10:00:00Z"},
+			{ID: "alt2", ShiftID: "2025-01-05", Direction: "add", VolunteerID: "charlie", SetTime: "2025-01-01T11:00:00Z"},
+			{ID: "alt3", ShiftID: "2025-01-05", Direction: "remove", VolunteerID: "charlie", SetTime: "2025-01-02T10:00:00Z"},
+			{ID: "alt4", ShiftID: "2025-01-05", Direction: "remove", CustomValue: "St Mary's", SetTime: "2025-01-02T11:00:00Z"},
+			{ID: "alt5", ShiftID: "2025-01-05", Direction: "remove", VolunteerID: "alice", SetTime: "2025-01-03T10:00:00Z"},
+			{ID: "alt6", ShiftID: "2025-01-05", Direction: "add", VolunteerID: "alice", SetTime: "2025-01-03T11:00:00Z"},
+		},
+	}
+
+	shifts, err := ListShifts(context.Background(), store, listShiftsVolunteers(), testCfg, ListShiftsParams{}, zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, shifts, 1)
+	assert.Equal(t, []string{"bob", "charlie"}, shifts[0].FormerVolunteerIDs)
+}
+
+func TestVolunteerCalendarShifts(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	shifts := []Shift{
+		{Date: "2025-01-05", FormerVolunteerIDs: []string{"alice"}},
+		{Date: "2025-02-05", FormerVolunteerIDs: []string{"alice"}},
+		{Date: "2025-03-02", Assignees: []ShiftAssignee{{VolunteerID: "alice"}}},
+		{Date: "2025-03-09", Closed: true, FormerVolunteerIDs: []string{"alice", "bob"}},
+		{Date: "2025-03-16", FormerVolunteerIDs: []string{"bob"}},
+	}
+
+	var dates []string
+	for _, s := range VolunteerCalendarShifts(shifts, "alice", now) {
+		dates = append(dates, s.Date)
+	}
+	assert.Equal(t, []string{"2025-02-05", "2025-03-02", "2025-03-09"}, dates,
+		"January's cancellation is past the retention window; Bob's is not Alice's")
+}
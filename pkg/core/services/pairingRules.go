@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/allocator"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// A Pairing Rule is what an admin knows about two volunteers that a group
+// cannot say: try to put these two on together, or never put them on the same
+// shift. A group is all-or-nothing — its members work every shift together or
+// none — which is right for a couple and wrong for two friends, and no use at
+// all for the opposite, which at the drop-in is most often a safeguarding
+// decision.
+//
+// "Together" is a preference the solver weighs against everything else it
+// wants; "apart" is a rule it never breaks, a pin included. Both are standing
+// facts about people rather than about a rota, so every solve reads them all,
+// and changing one makes the rota in flight's draft dirty.
+
+// PairingRuleStore is what reading and editing the Pairing Rules needs. Roles
+// come with it only because reading the roster takes them.
+type PairingRuleStore interface {
+	RoleStore
+	GetPairingRules(ctx context.Context) ([]db.PairingRule, error)
+	InsertPairingRule(ctx context.Context, rule db.PairingRule) error
+	DeletePairingRuleByID(ctx context.Context, id string) (bool, error)
+}
+
+// PairingRuleView is one Pairing Rule as the Volunteers screen reads it: the
+// two volunteers by id and by name, and which kind of rule it is.
+type PairingRuleView struct {
+	ID         string
+	Kind       string // allocator.PairTogether or allocator.PairApart
+	VolunteerA string
+	NameA      string
+	VolunteerB string
+	NameB      string
+	Note       string
+}
+
+// AddPairingRuleParams is one rule an admin is making about two volunteers.
+// The order they are named in means nothing.
+type AddPairingRuleParams struct {
+	VolunteerA string
+	VolunteerB string
+	Kind       string
+	Note       string
+}
+
+// ListPairingRules reads every rule with both volunteers resolved to names,
+// "apart" rules first — they are the ones an admin must not lose sight of —
+// then by name.
+func ListPairingRules(
+	ctx context.Context,
+	store PairingRuleStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+) ([]PairingRuleView, error) {
+	rows, err := store.GetPairingRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pairing rules: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteersByID := make(map[string]model.Volunteer, len(volunteers))
+	for _, v := range volunteers {
+		volunteersByID[v.ID] = v
+	}
+
+	views := make([]PairingRuleView, 0, len(rows))
+	for _, row := range rows {
+		views = append(views, PairingRuleView{
+			ID:         row.ID,
+			Kind:       row.Kind,
+			VolunteerA: row.VolunteerA,
+			NameA:      rosterName(row.VolunteerA, volunteersByID, logger),
+			VolunteerB: row.VolunteerB,
+			NameB:      rosterName(row.VolunteerB, volunteersByID, logger),
+			Note:       row.Note,
+		})
+	}
+
+	sort.Slice(views, func(i, j int) bool {
+		a, b := views[i], views[j]
+		if (a.Kind == allocator.PairApart) != (b.Kind == allocator.PairApart) {
+			return a.Kind == allocator.PairApart
+		}
+		if a.NameA != b.NameA {
+			return a.NameA < b.NameA
+		}
+		if a.NameB != b.NameB {
+			return a.NameB < b.NameB
+		}
+		return a.ID < b.ID
+	})
+	return views, nil
+}
+
+// AddPairingRule validates and records one.
+//
+// Both volunteers must be on the roster, though not necessarily active: a rule
+// about somebody who has stepped back is still a rule if they return, and an
+// "apart" rule in particular should not have to be remembered and made again.
+// Two members of one group are refused either way — together is what a group
+// already is, and apart would mean the group never works, which is a change to
+// the group rather than a rule about two of its members.
+func AddPairingRule(
+	ctx context.Context,
+	store PairingRuleStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	params AddPairingRuleParams,
+	logger *zap.Logger,
+) (*PairingRuleView, error) {
+	if params.Kind != allocator.PairTogether && params.Kind != allocator.PairApart {
+		return nil, wrapf(ErrInvalidInput, "kind must be %q or %q, not %q", allocator.PairTogether, allocator.PairApart, params.Kind)
+	}
+	if params.VolunteerA == "" || params.VolunteerB == "" {
+		return nil, wrapf(ErrInvalidInput, "a pairing rule needs two volunteers")
+	}
+	if params.VolunteerA == params.VolunteerB {
+		return nil, wrapf(ErrInvalidInput, "a pairing rule needs two different volunteers")
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	var pair [2]*model.Volunteer
+	for i, id := range []string{params.VolunteerA, params.VolunteerB} {
+		for j := range volunteers {
+			if volunteers[j].ID == id {
+				pair[i] = &volunteers[j]
+				break
+			}
+		}
+		if pair[i] == nil {
+			return nil, wrapf(ErrNotFound, "volunteer %s not found", id)
+		}
+	}
+	a, b := pair[0], pair[1]
+	// GroupKeyFor is the one place the grouping rule lives; asking it is what
+	// keeps this check agreeing with the solver about who is a group.
+	grouped := convertToAllocatorVolunteers([]model.Volunteer{*a, *b})
+	if allocator.GroupKeyFor(grouped[0]) == allocator.GroupKeyFor(grouped[1]) {
+		return nil, wrapf(ErrInvalidInput, "%s and %s are in the same group, so they always work together - change the group on the roster instead", a.DisplayName, b.DisplayName)
+	}
+
+	// One spelling per pair, so the table's unique index sees a rule named
+	// the other way round as the same rule. Byte order, which the table's
+	// CHECK compares under too (029), whatever the database's collation.
+	if b.ID < a.ID {
+		a, b = b, a
+	}
+	created := db.PairingRule{
+		ID:         uuid.New().String(),
+		VolunteerA: a.ID,
+		VolunteerB: b.ID,
+		Kind:       params.Kind,
+		Note:       strings.TrimSpace(params.Note),
+	}
+	if err := store.InsertPairingRule(ctx, created); err != nil {
+		if errors.Is(err, db.ErrDuplicatePairingRule) {
+			return nil, wrapf(ErrConflict, "%s and %s already have a pairing rule - remove it first to change it", a.DisplayName, b.DisplayName)
+		}
+		return nil, fmt.Errorf("failed to save pairing rule: %w", err)
+	}
+
+	logger.Info("Pairing rule recorded",
+		zap.String("id", created.ID),
+		zap.String("kind", created.Kind))
+
+	return &PairingRuleView{
+		ID:         created.ID,
+		Kind:       created.Kind,
+		VolunteerA: a.ID,
+		NameA:      a.DisplayName,
+		VolunteerB: b.ID,
+		NameB:      b.DisplayName,
+		Note:       created.Note,
+	}, nil
+}
+
+// DeletePairingRule removes one.
+func DeletePairingRule(ctx context.Context, store PairingRuleStore, id string, logger *zap.Logger) error {
+	deleted, err := store.DeletePairingRuleByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete pairing rule %s: %w", id, err)
+	}
+	if !deleted {
+		return wrapf(ErrNotFound, "pairing rule %s not found", id)
+	}
+
+	logger.Info("Pairing rule deleted", zap.String("id", id))
+	return nil
+}
+
+// rosterName is a volunteer's display name, or their raw id when the roster no
+// longer has them — a rule or an Absence about somebody who has left the sheet
+// is exactly the one an admin needs to see, to decide whether to remove it.
+func rosterName(volunteerID string, volunteersByID map[string]model.Volunteer, logger *zap.Logger) string {
+	volunteer, ok := volunteersByID[volunteerID]
+	if !ok || volunteer.DisplayName == "" {
+		logger.Warn("Volunteer not found in roster, using raw ID",
+			zap.String("volunteer_id", volunteerID))
+		return volunteerID
+	}
+	return volunteer.DisplayName
+}
+
+// contractPairRules reads the Pairing Rules as the solver's contract states
+// them. Which of them reach the solver is allocator.PairRulesFor's decision,
+// once grouping has decided who does.
+func contractPairRules(rules []db.PairingRule) []allocator.CpsatPairRule {
+	out := make([]allocator.CpsatPairRule, len(rules))
+	for i, rule := range rules {
+		out[i] = allocator.CpsatPairRule{
+			VolunteerA: rule.VolunteerA,
+			VolunteerB: rule.VolunteerB,
+			Kind:       rule.Kind,
+		}
+	}
+	return out
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/allocator"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// mockPairingStore implements PairingRuleStore over a slice.
+type mockPairingStore struct {
+	testRoleStore
+
+	rules     []db.PairingRule
+	insertErr error
+
+	inserted   []db.PairingRule
+	deletedIDs []string
+}
+
+func (m *mockPairingStore) GetPairingRules(context.Context) ([]db.PairingRule, error) {
+	return m.rules, nil
+}
+
+func (m *mockPairingStore) InsertPairingRule(_ context.Context, r db.PairingRule) error {
+	if m.insertErr != nil {
+		return m.insertErr
+	}
+	m.inserted = append(m.inserted, r)
+	m.rules = append(m.rules, r)
+	return nil
+}
+
+func (m *mockPairingStore) DeletePairingRuleByID(_ context.Context, id string) (bool, error) {
+	for i := range m.rules {
+		if m.rules[i].ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			m.deletedIDs = append(m.deletedIDs, id)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pairingVolunteers is the preallocation roster plus a couple, so a rule
+// between two members of one group has somebody to be refused for.
+func pairingVolunteers() *preallocVolClient {
+	client := preallocVolunteers()
+	client.volunteers = append(client.volunteers,
+		model.Volunteer{ID: "mum", FirstName: "Mary", LastName: "Khan", DisplayName: "Mary", GroupKey: "khans", Status: "Active"},
+		model.Volunteer{ID: "dad", FirstName: "Omar", LastName: "Khan", DisplayName: "Omar", GroupKey: "khans", Status: "Active"},
+	)
+	return client
+}
+
+func addPairing(t *testing.T, store *mockPairingStore, params AddPairingRuleParams) (*PairingRuleView, error) {
+	t.Helper()
+	return AddPairingRule(context.Background(), store, pairingVolunteers(), testCfg, params, zap.NewNop())
+}
+
+func TestAddPairingRule_HappyPath(t *testing.T) {
+	store := &mockPairingStore{}
+	view, err := addPairing(t, store, AddPairingRuleParams{
+		VolunteerA: "bob", VolunteerB: "alice", Kind: allocator.PairApart, Note: "  safeguarding ",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, store.inserted, 1)
+	assert.Equal(t, "alice", store.inserted[0].VolunteerA, "the pair is stored in one order, whichever way round it was named")
+	assert.Equal(t, "bob", store.inserted[0].VolunteerB)
+	assert.Equal(t, "safeguarding", store.inserted[0].Note)
+	assert.Equal(t, "Alice", view.NameA)
+	assert.Equal(t, "Bob", view.NameB)
+}
+
+// A rule about somebody who has stepped back still stands if they return.
+func TestAddPairingRule_InactiveVolunteer(t *testing.T) {
+	store := &mockPairingStore{}
+	_, err := addPairing(t, store, AddPairingRuleParams{VolunteerA: "alice", VolunteerB: "carol", Kind: allocator.PairApart})
+	require.NoError(t, err)
+	assert.Len(t, store.inserted, 1)
+}
+
+func TestAddPairingRule_Refusals(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  AddPairingRuleParams
+		wantErr error
+		contain string
+	}{
+		{
+			name:    "unknown kind",
+			params:  AddPairingRuleParams{VolunteerA: "alice", VolunteerB: "bob", Kind: "sometimes"},
+			wantErr: ErrInvalidInput,
+			contain: "kind must be",
+		},
+		{
+			name:    "only one volunteer",
+			params:  AddPairingRuleParams{VolunteerA: "alice", Kind: allocator.PairTogether},
+			wantErr: ErrInvalidInput,
+			contain: "needs two volunteers",
+		},
+		{
+			name:    "one volunteer twice",
+			params:  AddPairingRuleParams{VolunteerA: "alice", VolunteerB: "alice", Kind: allocator.PairTogether},
+			wantErr: ErrInvalidInput,
+			contain: "two different volunteers",
+		},
+		{
+			name:    "unknown volunteer",
+			params:  AddPairingRuleParams{VolunteerA: "alice", VolunteerB: "ghost", Kind: allocator.PairApart},
+			wantErr: ErrNotFound,
+			contain: "ghost not found",
+		},
+		{
+			name:    "two members of one group",
+			params:  AddPairingRuleParams{VolunteerA: "mum", VolunteerB: "dad", Kind: allocator.PairApart},
+			wantErr: ErrInvalidInput,
+			contain: "same group",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockPairingStore{}
+			_, err := addPairing(t, store, tt.params)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), tt.contain)
+			assert.Empty(t, store.inserted)
+		})
+	}
+}
+
+// A pair has one rule at most, so a second — the same or its opposite — is a
+// conflict the admin resolves by removing the first.
+func TestAddPairingRule_Duplicate(t *testing.T) {
+	store := &mockPairingStore{insertErr: db.ErrDuplicatePairingRule}
+	_, err := addPairing(t, store, AddPairingRuleParams{VolunteerA: "alice", VolunteerB: "bob", Kind: allocator.PairTogether})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Contains(t, err.Error(), "Alice and Bob already have a pairing rule")
+}
+
+// "Apart" rules list first, then by name; a volunteer the roster no longer has
+// is shown by id rather than dropped.
+func TestListPairingRules_Order(t *testing.T) {
+	store := &mockPairingStore{rules: []db.PairingRule{
+		{ID: "p1", VolunteerA: "alice", VolunteerB: "bob", Kind: allocator.PairTogether},
+		{ID: "p2", VolunteerA: "dan", VolunteerB: "mum", Kind: allocator.PairApart},
+		{ID: "p3", VolunteerA: "alice", VolunteerB: "gone", Kind: allocator.PairApart},
+	}}
+
+	views, err := ListPairingRules(context.Background(), store, pairingVolunteers(), testCfg, zap.NewNop())
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(views))
+	for _, v := range views {
+		ids = append(ids, v.ID)
+	}
+	assert.Equal(t, []string{"p3", "p2", "p1"}, ids)
+	assert.Equal(t, "gone", views[0].NameB)
+	assert.Equal(t, "Mary", views[1].NameB)
+}
+
+func TestDeletePairingRule(t *testing.T) {
+	store := &mockPairingStore{rules: []db.PairingRule{
+		{ID: "p1", VolunteerA: "alice", VolunteerB: "bob", Kind: allocator.PairApart},
+	}}
+
+	require.NoError(t, DeletePairingRule(context.Background(), store, "p1", zap.NewNop()))
+	assert.Equal(t, []string{"p1"}, store.deletedIDs)
+
+	err := DeletePairingRule(context.Background(), store, "p1", zap.NewNop())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -94,6 +94,10 @@ func AddPreallocation(
 	if params.RoleID == "" {
 		return nil, wrapf(ErrInvalidInput, "role is required")
 	}
+	date, err := time.Parse("2006-01-02", params.Date)
+	if err != nil {
+		return nil, wrapf(ErrInvalidInput, "invalid date format %q: expected YYYY-MM-DD", params.Date)
+	}
 	roles, err := RoleTable(ctx, store)
 	if err != nil {
 		return nil, err
@@ -122,8 +126,10 @@ func AddPreallocation(
 		if vol == nil {
 			return nil, wrapf(ErrNotFound, "volunteer %s not found", params.VolunteerID)
 		}
-		if len(utils.FilterActiveVolunteers([]model.Volunteer{*vol})) == 0 {
-			return nil, wrapf(ErrInvalidInput, "volunteer %s is not active", params.VolunteerID)
+		// Judged on the pin's own date: somebody paused until before it is
+		// back by then.
+		if !utils.IsActiveOn(*vol, date) {
+			return nil, wrapf(ErrInvalidInput, "volunteer %s is not active on %s", params.VolunteerID, params.Date)
 		}
 		// By name, because the roster is a Google Sheet that spells a Role out
 		// in a cell. The id is what the pin is stored under; the name is how
@@ -135,10 +141,6 @@ func AddPreallocation(
 	}
 
 	// Step 3: resolve the date to its shift (unknown date → not found).
-	date, err := time.Parse("2006-01-02", params.Date)
-	if err != nil {
-		return nil, wrapf(ErrInvalidInput, "invalid date format %q: expected YYYY-MM-DD", params.Date)
-	}
 	shift, err := store.GetShiftByDate(ctx, date)
 	if err != nil {
 		return nil, fmt.Errorf("failed to look up shift for date %s: %w", params.Date, err)
@@ -258,6 +258,40 @@ func TestAddPreallocation_InactiveVolunteer(t *testing.T) {
 	assert.Contains(t, err.Error(), "not active")
 }
 
+// A pin is judged on its own date: somebody paused is pinned on a shift they
+// are back for, and refused on one they are not. The shift is years ahead so
+// the pause is still running today.
+func TestAddPreallocation_PausedVolunteer(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		wantErr bool
+	}{
+		{name: "back before the shift", status: "Paused until 2036-08-01"},
+		{name: "back on the day", status: "Paused until 2036-08-03"},
+		{name: "back after it", status: "Paused until 2036-08-10", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := oneShiftStore()
+			store.shifts[0].Date = "2036-08-03"
+			volunteers := &preallocVolClient{volunteers: []model.Volunteer{
+				{ID: "erin", FirstName: "Erin", DisplayName: "Erin", Roles: []string{"Service volunteer"}, Status: tt.status},
+			}}
+
+			_, err := AddPreallocation(context.Background(), store, volunteers, testCfg,
+				AddPreallocationParams{Date: "2036-08-03", VolunteerID: "erin", RoleID: "role-service-volunteer"}, zap.NewNop())
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+				assert.Empty(t, store.inserted)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, store.inserted, 1)
+		})
+	}
+}
+
 func TestAddPreallocation_RoleTheVolunteerDoesNotHold(t *testing.T) {
 	store := oneShiftStore()
 	_, err := AddPreallocation(context.Background(), store, preallocVolunteers(), testCfg,
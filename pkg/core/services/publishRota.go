@@ -44,15 +44,64 @@ func PublishRota(
 ) (*sheetsclient.PublishedRota, error) {
 	logger.Debug("Starting publishRota", zap.String("rota_id", rotaID))
 
+	publishedRota, rotations, targetRota, err := buildPublishedRota(ctx, database, volunteerClient, cfg, logger, rotaID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the previous rotation to name the previous rota tab
+	previousRotaTabTitle := findPreviousRotaTabTitle(rotations, targetRota, logger)
+
+	// Publish to Google Sheets
+	logger.Debug("Publishing to Google Sheets", zap.String("spreadsheet_id", cfg.RotaSheetID))
+	err = sheetsClient.PublishRota(cfg.RotaSheetID, publishedRota, previousRotaTabTitle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish to Google Sheets: %w", err)
+	}
+
+	logger.Info("Rota published successfully to Google Sheets",
+		zap.String("rota_id", targetRota.ID))
+
+	return publishedRota, nil
+}
+
+// BuildPublishedRota builds a rota as it would be published — Role columns in
+// priority order, closed shifts marked, everybody where the Alterations left
+// them — without going near Google Sheets. It is what an export writes, so a
+// download and the published tab can never disagree about who is on.
+// If rotaID is empty, it defaults to the latest rota.
+func BuildPublishedRota(
+	ctx context.Context,
+	database PublishRotaStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+	rotaID string,
+) (*sheetsclient.PublishedRota, error) {
+	publishedRota, _, _, err := buildPublishedRota(ctx, database, volunteerClient, cfg, logger, rotaID)
+	return publishedRota, err
+}
+
+// buildPublishedRota is BuildPublishedRota, also returning the rotations it
+// read and the one it built, which publishing needs to name the tab the
+// previous rota is archived to.
+func buildPublishedRota(
+	ctx context.Context,
+	database PublishRotaStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+	rotaID string,
+) (*sheetsclient.PublishedRota, []db.Rotation, *db.Rotation, error) {
 	// Step 1: Fetch the target rota
 	logger.Debug("Fetching rotations")
 	rotations, err := database.GetRotations(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch rotations: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch rotations: %w", err)
 	}
 
 	if len(rotations) == 0 {
-		return nil, fmt.Errorf("no rotations found")
+		return nil, nil, nil, wrapf(ErrNotFound, "no rotations found")
 	}
 
 	// Find the target rota (or default to latest if rotaID is empty)
@@ -71,7 +120,7 @@ func PublishRota(
 		}
 
 		if targetRota == nil {
-			return nil, fmt.Errorf("rota not found: %s", rotaID)
+			return nil, nil, nil, wrapf(ErrNotFound, "rota not found: %s", rotaID)
 		}
 	}
 
@@ -86,10 +135,10 @@ func PublishRota(
 	// shift; an empty result is a broken invariant and fails loudly.
 	shifts, err := database.GetShiftsByRotaID(ctx, targetRota.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch shifts: %w", err)
 	}
 	if len(shifts) == 0 {
-		return nil, fmt.Errorf("rota %s has no shifts", targetRota.ID)
+		return nil, nil, nil, fmt.Errorf("rota %s has no shifts", targetRota.ID)
 	}
 	shiftIDs := make([]string, len(shifts))
 	for i, s := range shifts {
@@ -100,7 +149,7 @@ func PublishRota(
 	logger.Debug("Fetching allocations")
 	rotaAllocations, err := database.GetAllocationsByShiftIDs(ctx, shiftIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch allocations: %w", err)
 	}
 	logger.Debug("Fetched allocations for rota", zap.Int("count", len(rotaAllocations)))
 
@@ -108,11 +157,11 @@ func PublishRota(
 	logger.Debug("Fetching volunteers")
 	roles, err := RoleTable(ctx, database)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	volunteers, err := volunteerClient.ListVolunteers(cfg, roles)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch volunteers: %w", err)
 	}
 
 	// Build volunteer lookup map
@@ -131,7 +180,7 @@ func PublishRota(
 	logger.Debug("Fetching alterations")
 	rotaAlterations, err := database.GetAlterationsByShiftIDs(ctx, shiftIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch alterations: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to fetch alterations: %w", err)
 	}
 	logger.Debug("Applying alterations", zap.Int("count", len(rotaAlterations)))
 	allocationsByShiftID = utils.ApplyAlterations(allocationsByShiftID, rotaAlterations)
@@ -160,12 +209,13 @@ func PublishRota(
 	for _, shift := range shifts {
 		shiftDate, err := time.Parse("2006-01-02", shift.Date)
 		if err != nil {
-			return nil, fmt.Errorf("invalid shift date %q: %w", shift.Date, err)
+			return nil, nil, nil, fmt.Errorf("invalid shift date %q: %w", shift.Date, err)
 		}
 		allocations := allocationsByShiftID[shift.ID]
 
 		row := sheetsclient.PublishedRotaRow{
 			Date:        shiftDate.Format("Mon Jan 02 2006"),
+			ShiftDate:   shift.Date,
 			Roles:       map[string][]string{},
 			UnknownRole: []string{},
 			HotFood:     "",
@@ -188,7 +238,7 @@ func PublishRota(
 			if allocation.VolunteerID != "" {
 				volunteer, exists := volunteersByID[allocation.VolunteerID]
 				if !exists {
-					return nil, fmt.Errorf("volunteer not found: %s (allocation %s, shift %s)",
+					return nil, nil, nil, fmt.Errorf("volunteer not found: %s (allocation %s, shift %s)",
 						allocation.VolunteerID, allocation.ID, shift.Date)
 				}
 				name = volunteer.DisplayName
@@ -221,20 +271,7 @@ func PublishRota(
 		zap.String("rota_id", targetRota.ID),
 		zap.Int("shift_count", len(rows)))
 
-	// Step 7: Find the previous rotation to name the previous rota tab
-	previousRotaTabTitle := findPreviousRotaTabTitle(rotations, targetRota, logger)
-
-	// Step 8: Publish to Google Sheets
-	logger.Debug("Publishing to Google Sheets", zap.String("spreadsheet_id", cfg.RotaSheetID))
-	err = sheetsClient.PublishRota(cfg.RotaSheetID, publishedRota, previousRotaTabTitle)
-	if err != nil {
-		return nil, fmt.Errorf("failed to publish to Google Sheets: %w", err)
-	}
-
-	logger.Info("Rota published successfully to Google Sheets",
-		zap.String("rota_id", targetRota.ID))
-
-	return publishedRota, nil
+	return publishedRota, rotations, targetRota, nil
 }
 
 // findPreviousRotaTabTitle finds the rotation immediately before targetRota by start date
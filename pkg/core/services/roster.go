@@ -0,0 +1,358 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// The roster can be kept in the database rather than the Google Sheet
+// (config roster.source: database).
+//
+// The sheet has always been the roster, and the server holds what it read in
+// memory between syncs: restarted while Sheets was unreachable, it served
+// nobody until an admin's sync got through. Kept in the database, the roster is
+// read on every use like everything else, and edited on the volunteers screen
+// rather than in a spreadsheet.
+//
+// Moving across is one-shot: ImportRoster copies the sheet (or the dev CSV)
+// into an empty roster, and from then on the two are separate. Nothing keeps
+// them in step, because nothing could say which of two disagreeing copies is
+// right — so the config names one, and only that one is read.
+
+// RosterReader is the one read a roster kept in the database needs.
+type RosterReader interface {
+	GetVolunteers(ctx context.Context) ([]db.Volunteer, error)
+}
+
+// RosterStore is what editing a roster kept in the database needs. Roles come
+// with it because a volunteer holds them by id, and an admin names them.
+type RosterStore interface {
+	RoleStore
+	RosterReader
+	GetVolunteerByID(ctx context.Context, id string) (*db.Volunteer, error)
+	InsertVolunteer(ctx context.Context, volunteer db.Volunteer) error
+	UpdateVolunteer(ctx context.Context, volunteer db.Volunteer) (bool, error)
+	DeleteVolunteerByID(ctx context.Context, id string) (bool, error)
+	ImportVolunteers(ctx context.Context, volunteers []db.Volunteer) error
+}
+
+// DatabaseRoster is the VolunteerClient for a roster kept in the database. It
+// reads on every call rather than caching: the roster is one small query, and
+// a cache is exactly the thing that left the sheet-backed server empty.
+type DatabaseRoster struct {
+	store RosterReader
+}
+
+// NewDatabaseRoster returns the roster kept in store.
+func NewDatabaseRoster(store RosterReader) *DatabaseRoster {
+	return &DatabaseRoster{store: store}
+}
+
+// ListVolunteers reads the roster, with the Roles each volunteer holds named
+// and in priority order, and display names worked out across it exactly as
+// they are for the sheet. cfg is unused: it is the sheet's address.
+//
+// VolunteerClient carries no context, because the sheet client it was written
+// for never needed one, so the read runs on its own.
+func (r *DatabaseRoster) ListVolunteers(_ *config.Config, roles model.Roles) ([]model.Volunteer, error) {
+	rows, err := r.store.GetVolunteers(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the roster: %w", err)
+	}
+
+	volunteers := make([]model.Volunteer, 0, len(rows))
+	for _, row := range rows {
+		volunteers = append(volunteers, toModelVolunteer(row, roles))
+	}
+	sheetsclient.ComputeDisplayNames(volunteers)
+	return volunteers, nil
+}
+
+// toModelVolunteer names the Roles a row holds, in priority order, so the
+// first is the one a caller showing a single Role wants — as the sheet's
+// parser leaves them.
+func toModelVolunteer(row db.Volunteer, roles model.Roles) model.Volunteer {
+	held := make(map[string]bool, len(row.RoleIDs))
+	for _, id := range row.RoleIDs {
+		held[id] = true
+	}
+	names := make([]string, 0, len(row.RoleIDs))
+	for _, role := range roles.ByPriority() {
+		if held[role.ID] {
+			names = append(names, role.Name)
+		}
+	}
+
+	return model.Volunteer{
+		ID:        row.ID,
+		FirstName: row.FirstName,
+		LastName:  row.LastName,
+		Roles:     names,
+		Status:    row.Status,
+		Gender:    row.Gender,
+		Email:     row.Email,
+		GroupKey:  row.GroupKey,
+	}
+}
+
+// VolunteerParams is a volunteer as an admin states them, the same on the way
+// in for a creation and an edit. The id is not here: on a creation it is
+// minted, and on an edit it is what the volunteer is addressed by.
+type VolunteerParams struct {
+	FirstName string
+	LastName  string
+	// Roles are named, as the rest of the API names them.
+	Roles []string
+	// State is one of the model.VolunteerState values an admin may choose:
+	// active, onboarding, paused or left. PausedUntil is the day a pause
+	// ends, "2006-01-02", and only for paused.
+	State       string
+	PausedUntil string
+	Gender      string
+	Email       string
+	// Group is the group key, empty for no group.
+	Group string
+}
+
+// validate turns an admin's answers into the row to write, or says why it will
+// not.
+func (p VolunteerParams) validate(roles model.Roles) (db.Volunteer, error) {
+	first := strings.TrimSpace(p.FirstName)
+	if first == "" {
+		return db.Volunteer{}, wrapf(ErrInvalidInput, "a volunteer needs a first name")
+	}
+
+	status, err := p.status()
+	if err != nil {
+		return db.Volunteer{}, err
+	}
+
+	email := strings.TrimSpace(p.Email)
+	if email != "" {
+		if parsed, err := mail.ParseAddress(email); err != nil || parsed.Address != email {
+			return db.Volunteer{}, wrapf(ErrInvalidInput, "%q is not an email address", p.Email)
+		}
+	}
+
+	roleIDs := make([]string, 0, len(p.Roles))
+	seen := make(map[string]bool, len(p.Roles))
+	for _, name := range p.Roles {
+		role, ok := roles.ByName(strings.TrimSpace(name))
+		if !ok {
+			return db.Volunteer{}, wrapf(ErrInvalidInput, "there is no role called %q", name)
+		}
+		if !seen[role.ID] {
+			seen[role.ID] = true
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+
+	// "none" is how the sheet says no group, and an admin used to the sheet
+	// may well type it.
+	group := strings.TrimSpace(p.Group)
+	if strings.EqualFold(group, "none") {
+		group = ""
+	}
+
+	return db.Volunteer{
+		FirstName: first,
+		LastName:  strings.TrimSpace(p.LastName),
+		Status:    status.Cell(),
+		Gender:    strings.TrimSpace(p.Gender),
+		Email:     email,
+		GroupKey:  group,
+		RoleIDs:   roleIDs,
+	}, nil
+}
+
+// status reads the state an admin chose. Unrecognised is not one of them: it
+// is the state of a cell nobody meant, and the screen writing this one means
+// something.
+func (p VolunteerParams) status() (model.VolunteerStatus, error) {
+	switch model.VolunteerState(p.State) {
+	case model.StateActive, model.StateOnboarding, model.StateLeft:
+		if p.PausedUntil != "" {
+			return model.VolunteerStatus{}, wrapf(ErrInvalidInput, "only a paused volunteer has a date they are back")
+		}
+		return model.VolunteerStatus{State: model.VolunteerState(p.State)}, nil
+	case model.StatePaused:
+		until, err := time.Parse(time.DateOnly, p.PausedUntil)
+		if err != nil {
+			return model.VolunteerStatus{}, wrapf(ErrInvalidInput, "a pause needs the date it ends, as YYYY-MM-DD")
+		}
+		return model.VolunteerStatus{State: model.StatePaused, Until: until.Format(time.DateOnly)}, nil
+	}
+	return model.VolunteerStatus{}, wrapf(ErrInvalidInput, "%q is not a state a volunteer can be put in; use active, onboarding, paused or left", p.State)
+}
+
+// requireDatabaseRoster refuses an edit to a roster the app does not own. The
+// sheet is edited in the sheet; a row written here would be read by nothing.
+func requireDatabaseRoster(cfg *config.Config) error {
+	if cfg.RosterSource() != config.RosterSourceDatabase {
+		return wrapf(ErrConflict, "the roster is read from the Google Sheet - edit it there")
+	}
+	return nil
+}
+
+// CreateVolunteer adds somebody to a roster kept in the database and returns
+// them as the roster now reads them, display name included.
+//
+// The id is minted here. One imported from the sheet keeps the sheet's, since
+// everything already written names them by it; somebody new has no such
+// history.
+func CreateVolunteer(ctx context.Context, store RosterStore, cfg *config.Config, params VolunteerParams, logger *zap.Logger) (*model.Volunteer, error) {
+	if err := requireDatabaseRoster(cfg); err != nil {
+		return nil, err
+	}
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	row, err := params.validate(roles)
+	if err != nil {
+		return nil, err
+	}
+	row.ID = uuid.New().String()
+
+	if err := store.InsertVolunteer(ctx, row); err != nil {
+		return nil, fmt.Errorf("failed to create volunteer: %w", err)
+	}
+
+	logger.Info("Volunteer added to the roster", zap.String("id", row.ID), zap.String("status", row.Status))
+	return rosterEntry(ctx, store, roles, row.ID)
+}
+
+// UpdateVolunteer rewrites one volunteer on a roster kept in the database and
+// returns them as the roster now reads them.
+func UpdateVolunteer(ctx context.Context, store RosterStore, cfg *config.Config, id string, params VolunteerParams, logger *zap.Logger) (*model.Volunteer, error) {
+	if err := requireDatabaseRoster(cfg); err != nil {
+		return nil, err
+	}
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	row, err := params.validate(roles)
+	if err != nil {
+		return nil, err
+	}
+	row.ID = id
+
+	updated, err := store.UpdateVolunteer(ctx, row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update volunteer %s: %w", id, err)
+	}
+	if !updated {
+		return nil, wrapf(ErrNotFound, "volunteer %s not found", id)
+	}
+
+	logger.Info("Volunteer updated", zap.String("id", id), zap.String("status", row.Status))
+	return rosterEntry(ctx, store, roles, id)
+}
+
+// DeleteVolunteer removes somebody from a roster kept in the database. It is
+// for a volunteer added by mistake: anybody a rota, a request or a record
+// names is refused, and is taken off the roster by marking them Left, as they
+// always were on the sheet.
+func DeleteVolunteer(ctx context.Context, store RosterStore, cfg *config.Config, id string, logger *zap.Logger) error {
+	if err := requireDatabaseRoster(cfg); err != nil {
+		return err
+	}
+	deleted, err := store.DeleteVolunteerByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, db.ErrVolunteerHasHistory) {
+			return wrapf(ErrConflict, "volunteer %s is on a rota or has been asked about one, so they stay on the roster - mark them as left instead", id)
+		}
+		return fmt.Errorf("failed to delete volunteer %s: %w", id, err)
+	}
+	if !deleted {
+		return wrapf(ErrNotFound, "volunteer %s not found", id)
+	}
+
+	logger.Info("Volunteer removed from the roster", zap.String("id", id))
+	return nil
+}
+
+// ImportRoster copies a roster read from the sheet (or the dev CSV) into the
+// database, which must have nobody on it yet, and reports how many it wrote.
+//
+// It is allowed whichever source the config names, so that the copy can be
+// made and checked before the switch rather than after it.
+//
+// Every volunteer needs the sheet's Unique ID, and no two may share one:
+// everything already written names a volunteer by it, so an import that
+// minted one, or merged two, would cut somebody off from their own history.
+// Status cells are copied as they are, an unrecognised one included, so the
+// volunteers screen flags it for putting right exactly as the sync did.
+func ImportRoster(ctx context.Context, store RosterStore, volunteers []model.Volunteer, logger *zap.Logger) (int, error) {
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := make([]db.Volunteer, 0, len(volunteers))
+	seen := make(map[string]bool, len(volunteers))
+	for _, v := range volunteers {
+		if v.ID == "" {
+			return 0, wrapf(ErrInvalidInput, "%s has no Unique ID on the sheet - give them one and import again", volunteerName(v))
+		}
+		if seen[v.ID] {
+			return 0, wrapf(ErrInvalidInput, "two volunteers on the sheet share the Unique ID %q - give them one each and import again", v.ID)
+		}
+		seen[v.ID] = true
+
+		roleIDs := make([]string, 0, len(v.Roles))
+		for _, name := range v.Roles {
+			// The sheet's parser has already dropped the names no Role has.
+			if role, ok := roles.ByName(name); ok {
+				roleIDs = append(roleIDs, role.ID)
+			}
+		}
+		rows = append(rows, db.Volunteer{
+			ID:        v.ID,
+			FirstName: v.FirstName,
+			LastName:  v.LastName,
+			Status:    v.Status,
+			Gender:    v.Gender,
+			Email:     v.Email,
+			GroupKey:  v.GroupKey,
+			RoleIDs:   roleIDs,
+		})
+	}
+
+	if err := store.ImportVolunteers(ctx, rows); err != nil {
+		if errors.Is(err, db.ErrRosterNotEmpty) {
+			return 0, wrapf(ErrConflict, "the roster in the database already has volunteers on it, so there is nothing to import into")
+		}
+		return 0, fmt.Errorf("failed to import the roster: %w", err)
+	}
+
+	logger.Info("Roster imported into the database", zap.Int("count", len(rows)))
+	return len(rows), nil
+}
+
+// rosterEntry reads one volunteer back as the roster has them. The whole
+// roster is read, because a display name is only unambiguous across all of it.
+func rosterEntry(ctx context.Context, store RosterReader, roles model.Roles, id string) (*model.Volunteer, error) {
+	volunteers, err := NewDatabaseRoster(store).ListVolunteers(nil, roles)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := findVolunteer(volunteers, id)
+	if !ok {
+		return nil, wrapf(ErrNotFound, "volunteer %s not found", id)
+	}
+	return &v, nil
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// A sync replaces the whole roster with whatever the sheet holds, and the
+// sheet is edited by hand. A deleted column, a Role chip picked wrong or a row
+// pasted over another changes who is eligible for what, and used to do it the
+// moment an admin pressed Sync, with nothing to say so. A sync is now read
+// first and shown as a RosterDiff against the roster in use; only once an admin
+// confirms it is it applied, and the diff applied is kept as a RosterSync, the
+// record of who changed the roster and how.
+
+// RosterSyncStore records the syncs applied.
+type RosterSyncStore interface {
+	InsertRosterSync(ctx context.Context, sync db.RosterSync) error
+	GetRosterSyncs(ctx context.Context, limit int) ([]db.RosterSync, error)
+}
+
+// RosterDiff is what a sync would change about the roster, volunteer by
+// volunteer. Volunteers are matched by ID, so a row whose ID was edited reads
+// as one volunteer leaving and another joining — which, to every past rota that
+// names the old ID, is what it is.
+type RosterDiff struct {
+	Added   []RosterDiffVolunteer `json:"added"`
+	Removed []RosterDiffVolunteer `json:"removed"`
+	Changed []VolunteerChange     `json:"changed"`
+	// UnknownRoles are the Roles cells naming a Role the app does not know, on
+	// the incoming roster. They are not changes — they are skipped whether or
+	// not the sheet named them last time — but they are the edit an admin
+	// most needs to see before confirming, since whoever was meant to hold that
+	// Role will not.
+	UnknownRoles []UnknownRoleValue `json:"unknownRoles"`
+}
+
+// RosterDiffVolunteer names somebody joining or leaving the roster.
+type RosterDiffVolunteer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// VolunteerChange is what moved for one volunteer on both rosters. A field
+// that did not move is nil (or, for the Roles, empty).
+type VolunteerChange struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// RolesGained and RolesLost are in priority order, as a volunteer's Roles
+	// are.
+	RolesGained []string     `json:"rolesGained,omitempty"`
+	RolesLost   []string     `json:"rolesLost,omitempty"`
+	Status      *ValueChange `json:"status,omitempty"`
+	Group       *ValueChange `json:"group,omitempty"`
+	Email       *ValueChange `json:"email,omitempty"`
+}
+
+// ValueChange is one cell's before and after. Either may be empty: a cell
+// filled in, or cleared.
+type ValueChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// UnknownRoleValue is one value a volunteer's Roles cell held that names no
+// Role.
+type UnknownRoleValue struct {
+	VolunteerID string `json:"volunteerId"`
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+}
+
+// Empty reports whether applying the sync would change nothing anybody reads.
+// Unknown Role values do not count: they are skipped either way.
+func (d RosterDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffRoster compares the roster in use with the one a sync would replace it
+// with. Each list is ordered by name, then ID, so the same two rosters always
+// read the same way however the sheet's rows are ordered.
+//
+// Only the fields a bad edit changes eligibility through are compared: the
+// Roles held, the Status, the group, and the email the round is sent to. A name
+// or a gender corrected is a sync's ordinary business and not worth a line.
+func DiffRoster(current, incoming []model.Volunteer) RosterDiff {
+	before := make(map[string]model.Volunteer, len(current))
+	for _, v := range current {
+		before[v.ID] = v
+	}
+	after := make(map[string]bool, len(incoming))
+
+	diff := RosterDiff{
+		Added:        []RosterDiffVolunteer{},
+		Removed:      []RosterDiffVolunteer{},
+		Changed:      []VolunteerChange{},
+		UnknownRoles: []UnknownRoleValue{},
+	}
+	for _, v := range incoming {
+		after[v.ID] = true
+		for _, value := range v.UnknownRoles {
+			diff.UnknownRoles = append(diff.UnknownRoles, UnknownRoleValue{
+				VolunteerID: v.ID, Name: fullName(v), Value: value,
+			})
+		}
+
+		was, known := before[v.ID]
+		if !known {
+			diff.Added = append(diff.Added, RosterDiffVolunteer{ID: v.ID, Name: fullName(v)})
+			continue
+		}
+		if change, moved := diffVolunteer(was, v); moved {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for _, v := range current {
+		if !after[v.ID] {
+			diff.Removed = append(diff.Removed, RosterDiffVolunteer{ID: v.ID, Name: fullName(v)})
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool {
+		return byNameThenID(diff.Added[i].Name, diff.Added[i].ID, diff.Added[j].Name, diff.Added[j].ID)
+	})
+	sort.Slice(diff.Removed, func(i, j int) bool {
+		return byNameThenID(diff.Removed[i].Name, diff.Removed[i].ID, diff.Removed[j].Name, diff.Removed[j].ID)
+	})
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return byNameThenID(diff.Changed[i].Name, diff.Changed[i].ID, diff.Changed[j].Name, diff.Changed[j].ID)
+	})
+	sort.SliceStable(diff.UnknownRoles, func(i, j int) bool {
+		a, b := diff.UnknownRoles[i], diff.UnknownRoles[j]
+		return byNameThenID(a.Name, a.VolunteerID, b.Name, b.VolunteerID)
+	})
+	return diff
+}
+
+func diffVolunteer(was, now model.Volunteer) (VolunteerChange, bool) {
+	change := VolunteerChange{
+		ID:          now.ID,
+		Name:        fullName(now),
+		RolesGained: missingFrom(now.Roles, was.Roles),
+		RolesLost:   missingFrom(was.Roles, now.Roles),
+		Status:      valueChange(was.Status, now.Status),
+		Group:       valueChange(was.GroupKey, now.GroupKey),
+		Email:       valueChange(was.Email, now.Email),
+	}
+	moved := len(change.RolesGained) > 0 || len(change.RolesLost) > 0 ||
+		change.Status != nil || change.Group != nil || change.Email != nil
+	return change, moved
+}
+
+// missingFrom is every Role in held not in other, in held's order.
+func missingFrom(held, other []string) []string {
+	var out []string
+	for _, role := range held {
+		if !slices.Contains(other, role) {
+			out = append(out, role)
+		}
+	}
+	return out
+}
+
+func valueChange(before, after string) *ValueChange {
+	if before == after {
+		return nil
+	}
+	return &ValueChange{Before: before, After: after}
+}
+
+// fullName is how a diff names somebody: in full, since a diff is read
+// against the sheet, where nobody has a display name.
+func fullName(v model.Volunteer) string {
+	return strings.TrimSpace(v.FirstName + " " + v.LastName)
+}
+
+func byNameThenID(nameA, idA, nameB, idB string) bool {
+	if nameA != nameB {
+		return nameA < nameB
+	}
+	return idA < idB
+}
+
+// RosterSyncRecord is one applied sync as the volunteers screen lists it.
+type RosterSyncRecord struct {
+	ID       string
+	SyncedBy string
+	SyncedAt time.Time
+	Diff     RosterDiff
+}
+
+// RecordRosterSync keeps the diff a sync applied, and who applied it. The
+// caller has already replaced the roster: a record that fails to write is
+// logged and reported, but the roster is not put back — the sync the admin
+// confirmed happened.
+func RecordRosterSync(ctx context.Context, store RosterSyncStore, diff RosterDiff, by string, logger *zap.Logger) error {
+	document, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to encode the roster diff: %w", err)
+	}
+	if err := store.InsertRosterSync(ctx, db.RosterSync{
+		ID:       uuid.New().String(),
+		SyncedBy: by,
+		Added:    len(diff.Added),
+		Removed:  len(diff.Removed),
+		Changed:  len(diff.Changed),
+		Diff:     document,
+	}); err != nil {
+		return fmt.Errorf("failed to record the roster sync: %w", err)
+	}
+
+	logger.Info("Roster sync recorded",
+		zap.String("by", by),
+		zap.Int("added", len(diff.Added)),
+		zap.Int("removed", len(diff.Removed)),
+		zap.Int("changed", len(diff.Changed)),
+		zap.Int("unknown_roles", len(diff.UnknownRoles)))
+	return nil
+}
+
+// ListRosterSyncs reads the most recent applied syncs, newest first.
+func ListRosterSyncs(ctx context.Context, store RosterSyncStore, limit int) ([]RosterSyncRecord, error) {
+	syncs, err := store.GetRosterSyncs(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the roster syncs: %w", err)
+	}
+
+	out := make([]RosterSyncRecord, 0, len(syncs))
+	for _, s := range syncs {
+		var diff RosterDiff
+		if err := json.Unmarshal(s.Diff, &diff); err != nil {
+			return nil, fmt.Errorf("failed to decode roster sync %s: %w", s.ID, err)
+		}
+		out = append(out, RosterSyncRecord{
+			ID:       s.ID,
+			SyncedBy: s.SyncedBy,
+			SyncedAt: s.SyncedAt,
+			Diff:     diff,
+		})
+	}
+	return out, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+type mockRosterSyncStore struct {
+	syncs []db.RosterSync
+}
+
+func (m *mockRosterSyncStore) InsertRosterSync(_ context.Context, sync db.RosterSync) error {
+	m.syncs = append([]db.RosterSync{sync}, m.syncs...)
+	return nil
+}
+
+func (m *mockRosterSyncStore) GetRosterSyncs(_ context.Context, limit int) ([]db.RosterSync, error) {
+	if len(m.syncs) > limit {
+		return m.syncs[:limit], nil
+	}
+	return m.syncs, nil
+}
+
+func TestDiffRoster(t *testing.T) {
+	current := []model.Volunteer{
+		{ID: "alice", FirstName: "Alice", LastName: "Adams", Roles: []string{"Team lead", "Service volunteer"}, Status: "Active", Email: "alice@example.com"},
+		{ID: "bob", FirstName: "Bob", Roles: []string{"Service volunteer"}, Status: "Active", GroupKey: "smiths"},
+		{ID: "carol", FirstName: "Carol", Roles: []string{"Service volunteer"}, Status: "Active"},
+		{ID: "dan", FirstName: "Dan", Status: "Left"},
+	}
+	incoming := []model.Volunteer{
+		// Rows in another order, and a name corrected: neither is a change.
+		{ID: "carol", FirstName: "Carole", Roles: []string{"Service volunteer"}, Status: "Active"},
+		{ID: "alice", FirstName: "Alice", LastName: "Adams", Roles: []string{"Service volunteer"}, Status: "Actve", Email: "alice@example.org"},
+		{ID: "bob", FirstName: "Bob", Roles: []string{"Team lead", "Service volunteer"}, Status: "Active", UnknownRoles: []string{"Kitchen"}},
+		{ID: "erin", FirstName: "Erin", Roles: []string{"Service volunteer"}, Status: "Onboarding", UnknownRoles: []string{"Sevice volunteer"}},
+	}
+
+	diff := DiffRoster(current, incoming)
+
+	assert.Equal(t, []RosterDiffVolunteer{{ID: "erin", Name: "Erin"}}, diff.Added)
+	assert.Equal(t, []RosterDiffVolunteer{{ID: "dan", Name: "Dan"}}, diff.Removed)
+	assert.Equal(t, []VolunteerChange{
+		{
+			ID: "alice", Name: "Alice Adams",
+			RolesLost: []string{"Team lead"},
+			Status:    &ValueChange{Before: "Active", After: "Actve"},
+			Email:     &ValueChange{Before: "alice@example.com", After: "alice@example.org"},
+		},
+		{
+			ID: "bob", Name: "Bob",
+			RolesGained: []string{"Team lead"},
+			Group:       &ValueChange{Before: "smiths", After: ""},
+		},
+	}, diff.Changed)
+	assert.Equal(t, []UnknownRoleValue{
+		{VolunteerID: "bob", Name: "Bob", Value: "Kitchen"},
+		{VolunteerID: "erin", Name: "Erin", Value: "Sevice volunteer"},
+	}, diff.UnknownRoles)
+	assert.False(t, diff.Empty())
+}
+
+// The same roster, however the sheet is ordered, is no change — and a value
+// naming no Role is still shown, though it changes nothing.
+func TestDiffRoster_NothingMoved(t *testing.T) {
+	roster := []model.Volunteer{
+		{ID: "alice", FirstName: "Alice", Roles: []string{"Team lead"}, Status: "Active"},
+		{ID: "bob", FirstName: "Bob", Status: "Active", UnknownRoles: []string{"Kitchen"}},
+	}
+	reordered := []model.Volunteer{roster[1], roster[0]}
+
+	diff := DiffRoster(roster, reordered)
+	assert.True(t, diff.Empty())
+	assert.Len(t, diff.UnknownRoles, 1)
+
+	// Lists rather than nulls, so the JSON reads the same empty or not.
+	encoded, err := json.Marshal(diff)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"added":[],"removed":[],"changed":[],"unknownRoles":[{"volunteerId":"bob","name":"Bob","value":"Kitchen"}]}`, string(encoded))
+}
+
+// The first sync after startup is against an empty roster: everybody joins.
+func TestDiffRoster_FromEmpty(t *testing.T) {
+	diff := DiffRoster(nil, []model.Volunteer{{ID: "b", FirstName: "Bob"}, {ID: "a", FirstName: "Alice"}})
+	assert.Equal(t, []RosterDiffVolunteer{{ID: "a", Name: "Alice"}, {ID: "b", Name: "Bob"}}, diff.Added)
+}
+
+func TestRecordAndListRosterSyncs(t *testing.T) {
+	store := &mockRosterSyncStore{}
+	diff := DiffRoster(
+		[]model.Volunteer{{ID: "alice", FirstName: "Alice"}},
+		[]model.Volunteer{{ID: "bob", FirstName: "Bob"}},
+	)
+
+	require.NoError(t, RecordRosterSync(context.Background(), store, diff, "admin@example.com", zap.NewNop()))
+	require.Len(t, store.syncs, 1)
+	assert.NotEmpty(t, store.syncs[0].ID)
+	assert.Equal(t, 1, store.syncs[0].Added)
+	assert.Equal(t, 1, store.syncs[0].Removed)
+	assert.Equal(t, 0, store.syncs[0].Changed)
+
+	records, err := ListRosterSyncs(context.Background(), store, 10)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "admin@example.com", records[0].SyncedBy)
+	assert.Equal(t, diff, records[0].Diff, "the diff reads back as it was applied")
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// mockRosterStore is a roster kept in memory, in insertion order.
+type mockRosterStore struct {
+	testRoleStore
+	volunteers []db.Volunteer
+	// history is the ids a rota names, which a delete refuses.
+	history map[string]bool
+}
+
+func (m *mockRosterStore) GetVolunteers(context.Context) ([]db.Volunteer, error) {
+	return m.volunteers, nil
+}
+
+func (m *mockRosterStore) GetVolunteerByID(_ context.Context, id string) (*db.Volunteer, error) {
+	for i := range m.volunteers {
+		if m.volunteers[i].ID == id {
+			return &m.volunteers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockRosterStore) InsertVolunteer(_ context.Context, volunteer db.Volunteer) error {
+	m.volunteers = append(m.volunteers, volunteer)
+	return nil
+}
+
+func (m *mockRosterStore) UpdateVolunteer(_ context.Context, volunteer db.Volunteer) (bool, error) {
+	for i := range m.volunteers {
+		if m.volunteers[i].ID == volunteer.ID {
+			m.volunteers[i] = volunteer
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRosterStore) DeleteVolunteerByID(_ context.Context, id string) (bool, error) {
+	if m.history[id] {
+		return false, db.ErrVolunteerHasHistory
+	}
+	for i := range m.volunteers {
+		if m.volunteers[i].ID == id {
+			m.volunteers = append(m.volunteers[:i], m.volunteers[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockRosterStore) ImportVolunteers(_ context.Context, volunteers []db.Volunteer) error {
+	if len(m.volunteers) > 0 {
+		return db.ErrRosterNotEmpty
+	}
+	m.volunteers = append(m.volunteers, volunteers...)
+	return nil
+}
+
+var databaseRosterCfg = &config.Config{Roster: &config.RosterConfig{Source: config.RosterSourceDatabase}}
+
+// The roster reads as the sheet's did: Roles named and in priority order
+// whatever order they were stored in, and display names worked out across the
+// whole of it.
+func TestDatabaseRoster_ListVolunteers(t *testing.T) {
+	store := &mockRosterStore{volunteers: []db.Volunteer{
+		{ID: "v1", FirstName: "John", LastName: "Smith", Status: "Active", RoleIDs: []string{"role-service-volunteer", "role-team-lead"}},
+		{ID: "v2", FirstName: "John", LastName: "Evans", Status: "Left", RoleIDs: []string{}},
+		{ID: "v3", FirstName: "Aaliyah", Status: "Onboarding", GroupKey: "khans", RoleIDs: []string{"role-service-volunteer"}},
+	}}
+
+	volunteers, err := NewDatabaseRoster(store).ListVolunteers(nil, testRoles)
+	require.NoError(t, err)
+	require.Len(t, volunteers, 3)
+
+	assert.Equal(t, []string{"Team lead", "Service volunteer"}, volunteers[0].Roles)
+	assert.Equal(t, "John S.", volunteers[0].DisplayName)
+	assert.Equal(t, "John E.", volunteers[1].DisplayName)
+	assert.Empty(t, volunteers[1].Roles)
+	assert.Equal(t, "Aaliyah", volunteers[2].DisplayName)
+	assert.Equal(t, "khans", volunteers[2].GroupKey)
+	assert.Equal(t, model.StateOnboarding, volunteers[2].Lifecycle().State)
+}
+
+func TestCreateVolunteer_Validation(t *testing.T) {
+	valid := func() VolunteerParams {
+		return VolunteerParams{FirstName: "Alice", LastName: "Smith", Roles: []string{"Service volunteer"}, State: "active"}
+	}
+	tests := []struct {
+		name    string
+		edit    func(p *VolunteerParams)
+		wantErr bool
+	}{
+		{name: "valid", edit: func(*VolunteerParams) {}},
+		{name: "no first name", edit: func(p *VolunteerParams) { p.FirstName = "  " }, wantErr: true},
+		{name: "unknown state", edit: func(p *VolunteerParams) { p.State = "inactive" }, wantErr: true},
+		{name: "unrecognised is not a choice", edit: func(p *VolunteerParams) { p.State = "unrecognised" }, wantErr: true},
+		{name: "paused with a date", edit: func(p *VolunteerParams) { p.State, p.PausedUntil = "paused", "2026-12-01" }},
+		{name: "paused without a date", edit: func(p *VolunteerParams) { p.State = "paused" }, wantErr: true},
+		{name: "a date without a pause", edit: func(p *VolunteerParams) { p.PausedUntil = "2026-12-01" }, wantErr: true},
+		{name: "unknown role", edit: func(p *VolunteerParams) { p.Roles = []string{"Chef"} }, wantErr: true},
+		{name: "no roles", edit: func(p *VolunteerParams) { p.Roles = nil }},
+		{name: "bad email", edit: func(p *VolunteerParams) { p.Email = "alice at example" }, wantErr: true},
+		{name: "email with a display name", edit: func(p *VolunteerParams) { p.Email = "Alice <alice@example.com>" }, wantErr: true},
+		{name: "email", edit: func(p *VolunteerParams) { p.Email = "alice@example.com" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &mockRosterStore{}
+			params := valid()
+			tt.edit(&params)
+
+			_, err := CreateVolunteer(context.Background(), store, databaseRosterCfg, params, zap.NewNop())
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidInput)
+				assert.Empty(t, store.volunteers, "nothing is written")
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, store.volunteers, 1)
+		})
+	}
+}
+
+func TestCreateVolunteer_WritesTheSheetsSpelling(t *testing.T) {
+	store := &mockRosterStore{}
+	created, err := CreateVolunteer(context.Background(), store, databaseRosterCfg, VolunteerParams{
+		FirstName: " Alice ", Roles: []string{"Service volunteer", "Team lead", "Team lead"},
+		State: "paused", PausedUntil: "2026-12-01", Group: "None",
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	require.Len(t, store.volunteers, 1)
+	row := store.volunteers[0]
+	assert.NotEmpty(t, row.ID, "the id is minted")
+	assert.Equal(t, "Alice", row.FirstName)
+	assert.Equal(t, "Paused until 2026-12-01", row.Status)
+	assert.Empty(t, row.GroupKey, `"none" is no group, as on the sheet`)
+	assert.Equal(t, []string{"role-service-volunteer", "role-team-lead"}, row.RoleIDs, "a Role named twice is held once")
+
+	assert.Equal(t, row.ID, created.ID)
+	assert.Equal(t, "Alice", created.DisplayName)
+	assert.Equal(t, []string{"Team lead", "Service volunteer"}, created.Roles)
+}
+
+// The sheet is edited in the sheet: a row written here would be read by
+// nothing.
+func TestRosterEditsNeedADatabaseRoster(t *testing.T) {
+	store := &mockRosterStore{volunteers: []db.Volunteer{{ID: "v1", FirstName: "Alice", Status: "Active"}}}
+	params := VolunteerParams{FirstName: "Alice", State: "left"}
+
+	_, err := CreateVolunteer(context.Background(), store, testCfg, params, zap.NewNop())
+	require.ErrorIs(t, err, ErrConflict)
+	_, err = UpdateVolunteer(context.Background(), store, testCfg, "v1", params, zap.NewNop())
+	require.ErrorIs(t, err, ErrConflict)
+	err = DeleteVolunteer(context.Background(), store, testCfg, "v1", zap.NewNop())
+	require.ErrorIs(t, err, ErrConflict)
+
+	assert.Equal(t, "Active", store.volunteers[0].Status, "nothing is written")
+}
+
+func TestUpdateVolunteer(t *testing.T) {
+	store := &mockRosterStore{volunteers: []db.Volunteer{{ID: "v1", FirstName: "Alice", Status: "Active"}}}
+
+	updated, err := UpdateVolunteer(context.Background(), store, databaseRosterCfg, "v1",
+		VolunteerParams{FirstName: "Alice", LastName: "Jones", State: "left"}, zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "Jones", updated.LastName)
+	assert.Equal(t, "Left", store.volunteers[0].Status)
+	assert.Equal(t, "v1", store.volunteers[0].ID, "the id never moves")
+
+	_, err = UpdateVolunteer(context.Background(), store, databaseRosterCfg, "nobody",
+		VolunteerParams{FirstName: "No", State: "active"}, zap.NewNop())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeleteVolunteer(t *testing.T) {
+	store := &mockRosterStore{
+		volunteers: []db.Volunteer{{ID: "v1", FirstName: "Alice"}, {ID: "v2", FirstName: "Bob"}},
+		history:    map[string]bool{"v1": true},
+	}
+
+	err := DeleteVolunteer(context.Background(), store, databaseRosterCfg, "v1", zap.NewNop())
+	require.ErrorIs(t, err, ErrConflict, "somebody on a rota is marked left, not deleted")
+
+	require.NoError(t, DeleteVolunteer(context.Background(), store, databaseRosterCfg, "v2", zap.NewNop()))
+	assert.Len(t, store.volunteers, 1)
+
+	err = DeleteVolunteer(context.Background(), store, databaseRosterCfg, "v2", zap.NewNop())
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestImportRoster(t *testing.T) {
+	sheet := []model.Volunteer{
+		{ID: "v1", FirstName: "Alice", Status: "Active", Roles: []string{"Team lead", "Service volunteer"}, GroupKey: "smiths"},
+		{ID: "v2", FirstName: "Bob", Status: "Actve"},
+	}
+
+	t.Run("into an empty roster", func(t *testing.T) {
+		store := &mockRosterStore{}
+		count, err := ImportRoster(context.Background(), store, sheet, zap.NewNop())
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+		require.Len(t, store.volunteers, 2)
+		assert.Equal(t, []string{"role-team-lead", "role-service-volunteer"}, store.volunteers[0].RoleIDs)
+		assert.Equal(t, "smiths", store.volunteers[0].GroupKey)
+		assert.Equal(t, "Actve", store.volunteers[1].Status, "a cell nobody meant is copied, to be flagged and put right")
+	})
+
+	t.Run("into one with somebody on it", func(t *testing.T) {
+		store := &mockRosterStore{volunteers: []db.Volunteer{{ID: "v9", FirstName: "Carol"}}}
+		_, err := ImportRoster(context.Background(), store, sheet, zap.NewNop())
+		require.ErrorIs(t, err, ErrConflict)
+	})
+
+	t.Run("somebody with no id", func(t *testing.T) {
+		store := &mockRosterStore{}
+		_, err := ImportRoster(context.Background(), store, append(sheet, model.Volunteer{FirstName: "Dan"}), zap.NewNop())
+		require.ErrorIs(t, err, ErrInvalidInput)
+		assert.Contains(t, err.Error(), "Dan")
+		assert.Empty(t, store.volunteers)
+	})
+
+	t.Run("two with one id", func(t *testing.T) {
+		store := &mockRosterStore{}
+		_, err := ImportRoster(context.Background(), store, append(sheet, model.Volunteer{ID: "v1", FirstName: "Alicia"}), zap.NewNop())
+		require.ErrorIs(t, err, ErrInvalidInput)
+		assert.Empty(t, store.volunteers)
+	})
+}
@@ -30,6 +30,9 @@ type RotaDefaultsStore interface {
 type RotaDefaultsWriteStore interface {
 	SaveRotaDefaults(ctx context.Context, defaults db.RotaDefaults) error
 	SaveAllocationSettings(ctx context.Context, settings string) error
+	SaveEmailTemplate(ctx context.Context, kind, template string) error
+	ResetEmailTemplate(ctx context.Context, kind string) error
+	SaveSwapsNeedApproval(ctx context.Context, needApproval bool) error
 }
 
 // RotaDefaults reads what an admin has decided about how the drop-in runs.
@@ -52,6 +55,8 @@ func RotaDefaults(ctx context.Context, store RotaDefaultsStore) (model.RotaDefau
 		ShiftEndTime:       row.ShiftEndTime,
 		ShiftTimezone:      row.ShiftTimezone,
 		AllocationSettings: parseAllocationSettings(row.AllocationSettings),
+		EmailTemplates:     parseEmailTemplates(row.EmailTemplates),
+		SwapsNeedApproval:  row.SwapsNeedApproval,
 	}, nil
 }
 
@@ -275,6 +280,17 @@ type AllocationSettingsParams struct {
 	// MaxFrequency is the share of a rota's shifts one volunteer may work.
 	// Required when max_frequency is on, and kept as given when it is off.
 	MaxFrequency float64
+
+	// FairnessRotas and FairnessMonths are how far back fairness looks, in
+	// one unit or the other; neither is the previous rota alone.
+	// FairnessDecay is how much less each rota further back counts, zero for
+	// no decay.
+	FairnessRotas  int
+	FairnessMonths int
+	FairnessDecay  float64
+	// FairnessSkipsNoShows leaves shifts somebody did not turn up to out of
+	// their history.
+	FairnessSkipsNoShows bool
 }
 
 // validate turns an admin's answers into the settings to store, or says why it
@@ -292,7 +308,15 @@ func (p AllocationSettingsParams) validate() (model.AllocationSettings, error) {
 		}
 	}
 
-	settings := model.AllocationSettings{Enabled: enabled, MaxFrequency: p.MaxFrequency}
+	settings := model.AllocationSettings{
+		Enabled:        enabled,
+		MaxFrequency:   p.MaxFrequency,
+		FairnessRotas:  p.FairnessRotas,
+		FairnessMonths: p.FairnessMonths,
+		FairnessDecay:  p.FairnessDecay,
+
+		FairnessSkipsNoShows: p.FairnessSkipsNoShows,
+	}
 
 	// The value is only asked for when the rule that reads it is on. Off, it
 	// is kept as given: it constrains nothing there, and blanking it would
@@ -302,6 +326,27 @@ func (p AllocationSettingsParams) validate() (model.AllocationSettings, error) {
 			"the maximum allocation frequency is a share of a rota between 0 and 1, and %v is not one", p.MaxFrequency)
 	}
 
+	// The horizon is refused rather than clamped: the settings read back
+	// would otherwise say something other than what the admin typed, and a
+	// horizon quietly shorter than the one asked for is a fairness question
+	// nobody would think to ask.
+	if p.FairnessRotas != 0 && p.FairnessMonths != 0 {
+		return model.AllocationSettings{}, wrapf(ErrInvalidInput,
+			"fairness looks back a number of rotas or a number of months, not both")
+	}
+	if p.FairnessRotas < 0 || p.FairnessRotas > model.MaxFairnessRotas {
+		return model.AllocationSettings{}, wrapf(ErrInvalidInput,
+			"fairness looks back between 1 and %d rotas, not %d", model.MaxFairnessRotas, p.FairnessRotas)
+	}
+	if p.FairnessMonths < 0 || p.FairnessMonths > model.MaxFairnessMonths {
+		return model.AllocationSettings{}, wrapf(ErrInvalidInput,
+			"fairness looks back between 1 and %d months, not %d", model.MaxFairnessMonths, p.FairnessMonths)
+	}
+	if p.FairnessDecay < 0 || p.FairnessDecay > 1 {
+		return model.AllocationSettings{}, wrapf(ErrInvalidInput,
+			"the fairness decay is how much each older rota counts, between 0 and 1, and %v is not one", p.FairnessDecay)
+	}
+
 	return settings, nil
 }
 
@@ -330,7 +375,22 @@ func SaveAllocationSettings(
 
 	logger.Info("Allocation settings saved",
 		zap.Strings("enabled", settings.EnabledConstraints()),
-		zap.Float64("max_frequency", settings.MaxFrequency))
+		zap.Float64("max_frequency", settings.MaxFrequency),
+		zap.Int("fairness_rotas", settings.HistoryRotas()),
+		zap.Int("fairness_months", settings.HistoryMonths()),
+		zap.Float64("fairness_decay", settings.FairnessDecay),
+		zap.Bool("fairness_skips_no_shows", settings.FairnessSkipsNoShows))
 
 	return settings, nil
 }
+
+// SaveSwapSettings writes whether volunteers' swaps wait for an admin. It is the
+// setting at the moment a swap is taken that decides, so switching it off
+// leaves the swaps already waiting still waiting for an admin to answer.
+func SaveSwapSettings(ctx context.Context, store RotaDefaultsWriteStore, needApproval bool, logger *zap.Logger) error {
+	if err := store.SaveSwapsNeedApproval(ctx, needApproval); err != nil {
+		return fmt.Errorf("failed to save swap settings: %w", err)
+	}
+	logger.Info("Swap settings saved", zap.Bool("swaps_need_approval", needApproval))
+	return nil
+}
@@ -32,6 +32,14 @@ func (s *stubRotaDefaultsStore) SaveAllocationSettings(_ context.Context, settin
 	return nil
 }
 
+func (s *stubRotaDefaultsStore) SaveSwapsNeedApproval(_ context.Context, needApproval bool) error {
+	if s.writeErr != nil {
+		return s.writeErr
+	}
+	s.defaults.SwapsNeedApproval = needApproval
+	return nil
+}
+
 func (s *stubRotaDefaultsStore) GetRotaDefaults(context.Context) (db.RotaDefaults, error) {
 	if s.readErr != nil {
 		return db.RotaDefaults{}, s.readErr
@@ -264,3 +272,54 @@ func TestSaveAllocationSettingsKeepsTheValueWhenTheRuleIsOff(t *testing.T) {
 	assert.Empty(t, settings.EnabledConstraints())
 	assert.Equal(t, 0.34, settings.MaxFrequency)
 }
+
+// The fairness horizon is stored as stated, beside the rules.
+func TestSaveAllocationSettingsStoresTheFairnessHorizon(t *testing.T) {
+	store := &stubRotaDefaultsStore{}
+
+	settings, err := SaveAllocationSettings(context.Background(), store, AllocationSettingsParams{
+		FairnessMonths: 6,
+		FairnessDecay:  0.5,
+	}, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, settings.HistoryMonths())
+	assert.Equal(t, 0.5, settings.FairnessDecay)
+	require.Len(t, store.savedAllocation, 1)
+	assert.JSONEq(t, `{"fairnessMonths":6,"fairnessDecay":0.5}`, store.savedAllocation[0])
+}
+
+func TestSaveAllocationSettingsRefusesABadFairnessHorizon(t *testing.T) {
+	tests := []struct {
+		name   string
+		params AllocationSettingsParams
+	}{
+		{name: "rotas and months both", params: AllocationSettingsParams{FairnessRotas: 3, FairnessMonths: 6}},
+		{name: "negative rotas", params: AllocationSettingsParams{FairnessRotas: -1}},
+		{name: "too many rotas", params: AllocationSettingsParams{FairnessRotas: 13}},
+		{name: "too many months", params: AllocationSettingsParams{FairnessMonths: 25}},
+		{name: "decay above one", params: AllocationSettingsParams{FairnessDecay: 1.5}},
+		{name: "negative decay", params: AllocationSettingsParams{FairnessDecay: -0.5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &stubRotaDefaultsStore{}
+
+			_, err := SaveAllocationSettings(context.Background(), store, tt.params, zap.NewNop())
+
+			assert.ErrorIs(t, err, ErrInvalidInput)
+			assert.Empty(t, store.savedAllocation, "a refused save writes nothing")
+		})
+	}
+}
+
+func TestSaveSwapSettings(t *testing.T) {
+	store := &stubRotaDefaultsStore{}
+
+	require.NoError(t, SaveSwapSettings(context.Background(), store, true, zap.NewNop()))
+
+	defaults, err := RotaDefaults(context.Background(), store)
+	require.NoError(t, err)
+	assert.True(t, defaults.SwapsNeedApproval)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/utils/xlsx"
+)
+
+// The formats a rota can be exported in.
+const (
+	RotaExportCSV  = "csv"
+	RotaExportJSON = "json"
+	RotaExportXLSX = "xlsx"
+)
+
+// CheckRotaExportFormat refuses a format WriteRotaExport cannot write.
+func CheckRotaExportFormat(format string) error {
+	switch format {
+	case RotaExportCSV, RotaExportJSON, RotaExportXLSX:
+		return nil
+	}
+	return wrapf(ErrInvalidInput, "format must be csv, json or xlsx, got %q", format)
+}
+
+// RotaExportContentType is what a response carrying an export in format says
+// it is.
+func RotaExportContentType(format string) string {
+	switch format {
+	case RotaExportJSON:
+		return "application/json"
+	case RotaExportXLSX:
+		return xlsx.ContentType
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// RotaExportFilename names an export for its rota's first shift, which is how
+// the tabs on the rota sheet tell rotas apart too.
+func RotaExportFilename(rota *sheetsclient.PublishedRota, format string) string {
+	return "rota-" + rota.StartDate + "." + format
+}
+
+// RotaExport is a rota in the shape a program reads, which is not the shape
+// the sheet has. The sheet numbers a Role's columns because a sheet has to;
+// here each shift lists a Role's people under the Role's name.
+type RotaExport struct {
+	StartDate  string `json:"start_date"`
+	ShiftCount int    `json:"shift_count"`
+	// Roles are the configured Roles in priority order.
+	Roles  []string          `json:"roles"`
+	Shifts []RotaExportShift `json:"shifts"`
+}
+
+// RotaExportShift is one shift of an export. roles has a key for every
+// configured Role, empty for one nobody fills, so a reader need not know the
+// Roles to tell "nobody" from "not asked about".
+type RotaExportShift struct {
+	Date        string              `json:"date"`
+	Closed      bool                `json:"closed"`
+	Roles       map[string][]string `json:"roles"`
+	UnknownRole []string            `json:"unknown_role"`
+}
+
+// WriteRotaExport writes the rota in format. The CSV and the workbook are the
+// published tab cell for cell — the same Table — so somebody without the
+// Google Sheet gets what those with it see. The JSON is RotaExport.
+func WriteRotaExport(w io.Writer, rota *sheetsclient.PublishedRota, format string) error {
+	if err := CheckRotaExportFormat(format); err != nil {
+		return err
+	}
+
+	switch format {
+	case RotaExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(toRotaExport(rota)); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
+		}
+		return nil
+	case RotaExportXLSX:
+		return xlsx.Write(w, "Rota "+rota.StartDate, rota.Table())
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.WriteAll(rota.Table()); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+func toRotaExport(rota *sheetsclient.PublishedRota) RotaExport {
+	export := RotaExport{
+		StartDate:  rota.StartDate,
+		ShiftCount: rota.ShiftCount,
+		Roles:      append([]string{}, rota.RoleNames...),
+		Shifts:     make([]RotaExportShift, 0, len(rota.Rows)),
+	}
+	for _, row := range rota.Rows {
+		shift := RotaExportShift{
+			Date:        row.ShiftDate,
+			Closed:      row.Closed,
+			Roles:       make(map[string][]string, len(rota.RoleNames)),
+			UnknownRole: append([]string{}, row.UnknownRole...),
+		}
+		for _, name := range rota.RoleNames {
+			shift.Roles[name] = append([]string{}, row.Roles[name]...)
+		}
+		export.Shifts = append(export.Shifts, shift)
+	}
+	return export
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// exportRota is a rota of three shifts: Alice leads the first with Bob, whom
+// Charlie has since covered; the second is closed; nobody is on the third.
+func exportRota(t *testing.T) *sheetsclient.PublishedRota {
+	t.Helper()
+	shifts := sundayShifts("rota-1", "2025-01-05", 3)
+	shifts[1].Closed = true
+	store := &mockPublishRotaStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: "2025-01-05", ShiftCount: 3}},
+		shifts:    shifts,
+		allocations: []db.Allocation{
+			{ID: "alloc-1", ShiftID: "2025-01-05", Role: "Team lead", VolunteerID: "alice"},
+			{ID: "alloc-2", ShiftID: "2025-01-05", Role: "Service volunteer", VolunteerID: "bob"},
+		},
+		alterations: []db.Alteration{
+			{ID: "alt-1", ShiftID: "2025-01-05", Direction: "remove", VolunteerID: "bob", Role: "Service volunteer"},
+			{ID: "alt-2", ShiftID: "2025-01-05", Direction: "add", VolunteerID: "charlie", Role: "Service volunteer"},
+		},
+	}
+	volunteerClient := &mockVolClient{volunteers: []model.Volunteer{
+		{ID: "alice", FirstName: "Alice", LastName: "Smith"},
+		{ID: "bob", FirstName: "Bob", LastName: "Jones"},
+		{ID: "charlie", FirstName: "Charlie", LastName: "Brown"},
+	}}
+
+	rota, err := BuildPublishedRota(context.Background(), store, volunteerClient, &config.Config{}, zap.NewNop(), "rota-1")
+	require.NoError(t, err)
+	return rota
+}
+
+func TestBuildPublishedRota(t *testing.T) {
+	rota := exportRota(t)
+	require.Len(t, rota.Rows, 3)
+	assert.Equal(t, []string{"Charlie"}, ordinaryNames(rota.Rows[0]), "the cover, not who it replaced")
+	assert.Equal(t, "2025-01-05", rota.Rows[0].ShiftDate)
+	assert.True(t, rota.Rows[1].Closed)
+
+	_, err := BuildPublishedRota(context.Background(), &mockPublishRotaStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: "2025-01-05", ShiftCount: 1}},
+	}, &mockVolClient{}, &config.Config{}, zap.NewNop(), "rota-999")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestWriteRotaExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteRotaExport(&buf, exportRota(t), RotaExportCSV))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"Date", "Team lead", "Service volunteer", "Hot food", "Collection"},
+		{"Sun Jan 05 2025", "Alice", "Charlie", "", ""},
+		{"Sun Jan 12 2025", "CLOSED", "", "", ""},
+		{"Sun Jan 19 2025", "", "", "", ""},
+	}, rows)
+}
+
+func TestWriteRotaExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteRotaExport(&buf, exportRota(t), RotaExportJSON))
+
+	var got RotaExport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "2025-01-05", got.StartDate)
+	assert.Equal(t, []string{"Team lead", "Service volunteer"}, got.Roles)
+	require.Len(t, got.Shifts, 3)
+	assert.Equal(t, RotaExportShift{
+		Date:        "2025-01-05",
+		Roles:       map[string][]string{"Team lead": {"Alice"}, "Service volunteer": {"Charlie"}},
+		UnknownRole: []string{},
+	}, got.Shifts[0])
+	assert.True(t, got.Shifts[1].Closed)
+	assert.Equal(t, []string{}, got.Shifts[2].Roles["Team lead"], "an empty Role is a list, not missing")
+}
+
+func TestWriteRotaExportXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteRotaExport(&buf, exportRota(t), RotaExportXLSX))
+
+	_, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err, "a workbook is a zip")
+}
+
+func TestWriteRotaExportRefusesAnUnknownFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteRotaExport(&buf, exportRota(t), "pdf")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Zero(t, buf.Len())
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/utils/pdf"
+)
+
+// The drop-in prints two things every week: the rota, to pin up, and a sheet
+// for the shift that night, to sign in on. Both are drawn here rather than in
+// the browser so they come out the same from the rota page and from the CLI,
+// and so the sign-in sheet can be printed by whoever has the laptop without
+// anybody opening a print dialog's margins.
+
+// roleColourValues are the light values of the Role palette — the ones drawn
+// on a white page — copied from web/src/index.css, which owns them. Each is
+// 4.5:1 against white, so white text on one reads as well as the colour does
+// on white.
+var roleColourValues = map[string]string{
+	model.ColourViolet: "#a233f5",
+	model.ColourTeal:   "#0a8168",
+	model.ColourBlue:   "#1d68c7",
+	model.ColourIndigo: "#4f46e5",
+	model.ColourCyan:   "#0e7490",
+	model.ColourGreen:  "#3f7d1f",
+	model.ColourAmber:  "#8a6100",
+	model.ColourOrange: "#b4400c",
+	model.ColourRose:   "#c02a51",
+	model.ColourPink:   "#b01f8f",
+	model.ColourBrown:  "#7c5237",
+	model.ColourSlate:  "#53616f",
+}
+
+// roleColour is a palette token as a colour to print, falling back to the
+// default Role colour for a token the palette does not have — or for the
+// Unknown role column, which has none.
+func roleColour(token string) pdf.Colour {
+	value, ok := roleColourValues[token]
+	if !ok {
+		value = roleColourValues[model.DefaultRoleColour]
+	}
+	// The values above are all well formed, which the tests hold them to.
+	c, _ := pdf.Hex(value)
+	return c
+}
+
+var (
+	printGrey  = pdf.Colour{R: 0.4, G: 0.4, B: 0.4}
+	printRule  = pdf.Colour{R: 0.75, G: 0.75, B: 0.75}
+	printShade = pdf.Colour{R: 0.94, G: 0.94, B: 0.94}
+)
+
+// Half an inch of margin, which every office printer manages, and text at a
+// size that reads on a noticeboard from a step back.
+const (
+	printMargin    = 36.0
+	printLineSize  = 10.0
+	printLineSpace = 13.0
+)
+
+// RotaOverview is a rota to print: the published rota — Role columns in
+// priority order, closed shifts marked, Alterations applied — and the colour
+// each Role is drawn in.
+type RotaOverview struct {
+	Rota *sheetsclient.PublishedRota
+	// Colours are palette tokens by Role name.
+	Colours map[string]string
+}
+
+// BuildRotaOverview reads a rota to print. If rotaID is empty, it defaults to
+// the latest rota, as publishing does.
+func BuildRotaOverview(
+	ctx context.Context,
+	store PublishRotaStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	logger *zap.Logger,
+	rotaID string,
+) (*RotaOverview, error) {
+	rota, err := BuildPublishedRota(ctx, store, volunteerClient, cfg, logger, rotaID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	colours := make(map[string]string, len(roles.ByPriority()))
+	for _, role := range roles.ByPriority() {
+		colours[role.Name] = role.Colour
+	}
+	return &RotaOverview{Rota: rota, Colours: colours}, nil
+}
+
+// RotaOverviewFilename names a printed rota for its first shift, as an export
+// is named.
+func RotaOverviewFilename(overview *RotaOverview) string {
+	return "rota-" + overview.Rota.StartDate + ".pdf"
+}
+
+// printColumn is one column of the overview: a Role, or the Unknown role.
+type printColumn struct {
+	heading string
+	colour  pdf.Colour
+	names   func(row sheetsclient.PublishedRotaRow) []string
+}
+
+// WriteRotaOverviewPDF prints the rota on landscape A4: a row per shift, a
+// column per Role headed in the Role's colour, everybody in a Role listed down
+// its cell. A rota too long for a page carries on over the next under the
+// same headings.
+func WriteRotaOverviewPDF(w io.Writer, overview *RotaOverview) error {
+	rota := overview.Rota
+	columns := make([]printColumn, 0, len(rota.RoleNames)+1)
+	for _, name := range rota.RoleNames {
+		columns = append(columns, printColumn{
+			heading: name,
+			colour:  roleColour(overview.Colours[name]),
+			names:   func(row sheetsclient.PublishedRotaRow) []string { return row.Roles[name] },
+		})
+	}
+	for _, row := range rota.Rows {
+		if len(row.UnknownRole) > 0 {
+			columns = append(columns, printColumn{
+				heading: "Unknown role",
+				colour:  roleColour(""),
+				names:   func(row sheetsclient.PublishedRotaRow) []string { return row.UnknownRole },
+			})
+			break
+		}
+	}
+
+	width, height := pdf.A4Height, pdf.A4Width
+	doc := pdf.New(width, height)
+	doc.Title = "Ilford Drop-in rota from " + longDate(rota.StartDate)
+
+	const dateWidth, headerHeight, padding = 110.0, 22.0, 6.0
+	columnWidth := width - 2*printMargin - dateWidth
+	if len(columns) > 0 {
+		columnWidth /= float64(len(columns))
+	}
+
+	var page *pdf.Page
+	var y float64
+	newPage := func() {
+		page = doc.AddPage()
+		y = printMargin
+		page.Text(printMargin, y+18, pdf.HelveticaBold, 18, pdf.Black, "Ilford Drop-in rota")
+		page.Text(printMargin, y+36, pdf.Helvetica, 11, printGrey, rotaSpan(rota))
+		y += 50
+
+		page.FillRect(printMargin, y, dateWidth, headerHeight, printShade)
+		page.Text(printMargin+padding, y+15, pdf.HelveticaBold, printLineSize, pdf.Black, "Date")
+		x := printMargin + dateWidth
+		for _, c := range columns {
+			page.FillRect(x, y, columnWidth, headerHeight, c.colour)
+			page.Text(x+padding, y+15, pdf.HelveticaBold, printLineSize, pdf.White,
+				pdf.Fit(pdf.HelveticaBold, printLineSize, columnWidth-2*padding, c.heading))
+			x += columnWidth
+		}
+		y += headerHeight
+	}
+	newPage()
+
+	for _, row := range rota.Rows {
+		lines := 1
+		if !row.Closed {
+			for _, c := range columns {
+				if n := len(c.names(row)); n > lines {
+					lines = n
+				}
+			}
+		}
+		rowHeight := float64(lines)*printLineSpace + 2*padding
+		if y+rowHeight > height-printMargin {
+			newPage()
+		}
+
+		baseline := y + padding + printLineSize
+		page.Text(printMargin+padding, baseline, pdf.HelveticaBold, printLineSize, pdf.Black, shortDate(row))
+		if row.Closed {
+			page.FillRect(printMargin+dateWidth, y, columnWidth*float64(len(columns)), rowHeight, printShade)
+			page.Text(printMargin+dateWidth+padding, baseline, pdf.Helvetica, printLineSize, printGrey, "Closed")
+		} else {
+			x := printMargin + dateWidth
+			for _, c := range columns {
+				for i, name := range c.names(row) {
+					// A bar of the Role's colour beside each name ties the
+					// name to its column once the headings are a page away.
+					line := baseline + float64(i)*printLineSpace
+					page.FillRect(x+padding, line-printLineSize+1, 2.5, printLineSize, c.colour)
+					page.Text(x+padding+6, line, pdf.Helvetica, printLineSize, pdf.Black,
+						pdf.Fit(pdf.Helvetica, printLineSize, columnWidth-2*padding-6, name))
+				}
+				x += columnWidth
+			}
+		}
+		y += rowHeight
+		page.Line(printMargin, y, width-printMargin, y, 0.5, printRule)
+	}
+
+	return doc.Write(w)
+}
+
+// SignInSheetStore is what a shift's sign-in sheet is read from.
+type SignInSheetStore interface {
+	RoleStore
+	RotaDefaultsStore
+	GetShiftByID(ctx context.Context, id string) (*db.ShiftInRange, error)
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+}
+
+// SignInPerson is one line of a sign-in sheet: somebody expected on the shift.
+type SignInPerson struct {
+	Name string
+	Role string
+	// Colour is the Role's palette token; empty for a Role the app does not
+	// know.
+	Colour string
+}
+
+// SignInSheet is one shift's sheet to sign in on: who is expected, as the
+// rota now stands, and when. Start and End are "18:30", in the drop-in's own
+// zone, and empty for a shift nobody has given times.
+type SignInSheet struct {
+	ShiftID string
+	Date    string
+	Start   string
+	End     string
+	People  []SignInPerson
+}
+
+// DefaultWalkInRows is how many blank lines a sign-in sheet leaves for people
+// nobody was expecting. A busy night at the drop-in sees a dozen.
+const DefaultWalkInRows = 12
+
+// BuildSignInSheet reads one shift's sign-in sheet. It is refused for a
+// closed shift, where nobody will be signing in, and for a shift whose rota
+// has not been allocated, where a sheet naming nobody would be read as
+// nobody being on.
+func BuildSignInSheet(
+	ctx context.Context,
+	store SignInSheetStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	shiftID string,
+) (*SignInSheet, error) {
+	if _, err := uuid.Parse(shiftID); err != nil {
+		return nil, wrapf(ErrNotFound, "shift %s not found", shiftID)
+	}
+	shift, err := store.GetShiftByID(ctx, shiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up shift %s: %w", shiftID, err)
+	}
+	if shift == nil {
+		return nil, wrapf(ErrNotFound, "shift %s not found", shiftID)
+	}
+	if shift.Closed {
+		return nil, wrapf(ErrConflict, "the drop-in is closed on %s", shift.Date)
+	}
+	if !shift.Allocated {
+		return nil, wrapf(ErrConflict, "the rota for %s has not been allocated, so nobody is on the shift yet", shift.Date)
+	}
+
+	defaults, err := RotaDefaults(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteers := volunteersByID(roster)
+	onShift, err := effectiveAllocations(ctx, store, []string{shift.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &SignInSheet{ShiftID: shift.ID, Date: shift.Date, People: []SignInPerson{}}
+	if shift.StartAt != "" {
+		start, end, err := defaults.ShiftInstants(shift.StartAt, shift.EndAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the times of shift %s: %w", shift.Date, err)
+		}
+		sheet.Start, sheet.End = start.Format("15:04"), end.Format("15:04")
+	}
+
+	priority := make(map[string]int)
+	for i, r := range roles.ByPriority() {
+		priority[r.Name] = i
+	}
+	for _, a := range onShift[shift.ID] {
+		// A custom entry is somebody pinned by name rather than from the
+		// roster, and will be at the door all the same.
+		name := a.CustomEntry
+		if a.VolunteerID != "" {
+			name = nameOf(volunteers, a.VolunteerID)
+		}
+		person := SignInPerson{Name: name, Role: a.Role}
+		if role, ok := roles.ByName(a.Role); ok {
+			person.Colour = role.Colour
+		} else {
+			priority[a.Role] = len(priority)
+		}
+		sheet.People = append(sheet.People, person)
+	}
+	sort.SliceStable(sheet.People, func(i, j int) bool {
+		pi, pj := priority[sheet.People[i].Role], priority[sheet.People[j].Role]
+		if pi != pj {
+			return pi < pj
+		}
+		return sheet.People[i].Name < sheet.People[j].Name
+	})
+	return sheet, nil
+}
+
+// ShiftOnDateStore is what finding a shift by its date needs.
+type ShiftOnDateStore interface {
+	GetShiftsInRange(ctx context.Context, from, to time.Time) ([]db.ShiftInRange, error)
+}
+
+// ShiftOnDate is the id of the shift on date, for a caller who knows the
+// drop-in by its dates — the CLI — to ask for a sign-in sheet by.
+func ShiftOnDate(ctx context.Context, store ShiftOnDateStore, date string) (string, error) {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return "", wrapf(ErrInvalidInput, "%q is not a date — write it as 2026-08-02", date)
+	}
+	shifts, err := store.GetShiftsInRange(ctx, day, day)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch shifts: %w", err)
+	}
+	if len(shifts) == 0 {
+		return "", wrapf(ErrNotFound, "there is no shift on %s", date)
+	}
+	return shifts[0].ID, nil
+}
+
+// SignInSheetFilename names a sign-in sheet for its shift's date.
+func SignInSheetFilename(sheet *SignInSheet) string {
+	return "sign-in-" + sheet.Date + ".pdf"
+}
+
+// WriteSignInSheetPDF prints a shift's sign-in sheet on portrait A4: everyone
+// expected, Team lead first, with boxes for the time they came and went and a
+// signature, then walkIns blank lines for anybody else. The lines carry on
+// over a second page if they must, headings and all.
+func WriteSignInSheetPDF(w io.Writer, sheet *SignInSheet, walkIns int) error {
+	if walkIns < 0 {
+		return wrapf(ErrInvalidInput, "walk-in rows cannot be negative, got %d", walkIns)
+	}
+
+	width, height := pdf.A4Width, pdf.A4Height
+	doc := pdf.New(width, height)
+	doc.Title = "Sign-in sheet for " + longDate(sheet.Date)
+
+	const rowHeight, padding = 26.0, 6.0
+	headings := []struct {
+		title string
+		width float64
+	}{
+		{"Name", 165}, {"Role", 110}, {"In", 55}, {"Out", 55}, {"Signature", width - 2*printMargin - 385},
+	}
+
+	var page *pdf.Page
+	var y float64
+	newPage := func() {
+		page = doc.AddPage()
+		y = printMargin
+		page.Text(printMargin, y+18, pdf.HelveticaBold, 18, pdf.Black, "Sign-in sheet")
+		when := longDate(sheet.Date)
+		if sheet.Start != "" {
+			when += ", " + sheet.Start + " – " + sheet.End
+		}
+		page.Text(printMargin, y+36, pdf.Helvetica, 12, printGrey, when)
+		y += 50
+
+		page.FillRect(printMargin, y, width-2*printMargin, 20, printShade)
+		x := printMargin
+		for _, h := range headings {
+			page.Text(x+padding, y+14, pdf.HelveticaBold, printLineSize, pdf.Black, h.title)
+			x += h.width
+		}
+		y += 20
+	}
+	line := func(draw func(baseline float64)) {
+		if y+rowHeight > height-printMargin {
+			newPage()
+		}
+		draw(y + rowHeight/2 + printLineSize/2 - 1)
+		y += rowHeight
+		x := printMargin
+		for _, h := range headings[:len(headings)-1] {
+			x += h.width
+			page.Line(x, y-rowHeight, x, y, 0.5, printRule)
+		}
+		page.Line(printMargin, y, width-printMargin, y, 0.5, printRule)
+	}
+	newPage()
+
+	for _, person := range sheet.People {
+		line(func(baseline float64) {
+			page.Text(printMargin+padding, baseline, pdf.Helvetica, printLineSize+1, pdf.Black,
+				pdf.Fit(pdf.Helvetica, printLineSize+1, headings[0].width-2*padding, person.Name))
+			x := printMargin + headings[0].width
+			colour := roleColour(person.Colour)
+			page.FillRect(x+padding, baseline-printLineSize+1, 2.5, printLineSize, colour)
+			page.Text(x+padding+6, baseline, pdf.Helvetica, printLineSize, pdf.Black,
+				pdf.Fit(pdf.Helvetica, printLineSize, headings[1].width-2*padding-6, person.Role))
+		})
+	}
+
+	if walkIns > 0 {
+		if y+20+rowHeight > height-printMargin {
+			newPage()
+		}
+		page.Text(printMargin+padding, y+16, pdf.HelveticaBold, printLineSize, printGrey, "Walk-ins")
+		y += 22
+		page.Line(printMargin, y, width-printMargin, y, 0.5, printRule)
+		for i := 0; i < walkIns; i++ {
+			line(func(float64) {})
+		}
+	}
+
+	return doc.Write(w)
+}
+
+// longDate is a "2006-01-02" date as a heading says it: "Sunday 5 January 2025".
+func longDate(date string) string {
+	parsed, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return parsed.Format("Monday 2 January 2006")
+}
+
+// shortDate is a row's date as the overview's first column says it.
+func shortDate(row sheetsclient.PublishedRotaRow) string {
+	parsed, err := time.Parse("2006-01-02", row.ShiftDate)
+	if err != nil {
+		return row.Date
+	}
+	return parsed.Format("Mon 2 Jan 2006")
+}
+
+// rotaSpan is the overview's subheading: the dates its shifts run between.
+func rotaSpan(rota *sheetsclient.PublishedRota) string {
+	if len(rota.Rows) == 0 {
+		return "From " + longDate(rota.StartDate)
+	}
+	first, last := rota.Rows[0].ShiftDate, rota.Rows[len(rota.Rows)-1].ShiftDate
+	if first == last {
+		return longDate(first)
+	}
+	return longDate(first) + " to " + longDate(last)
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/clients/sheetsclient"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/utils/pdf"
+)
+
+func TestRoleColourValuesCoverThePalette(t *testing.T) {
+	for _, token := range model.RoleColours {
+		_, err := pdf.Hex(roleColourValues[token])
+		assert.NoError(t, err, token)
+	}
+	assert.Equal(t, roleColour(model.DefaultRoleColour), roleColour("mauve"), "an unknown token prints as the default")
+}
+
+func TestWriteRotaOverviewPDF(t *testing.T) {
+	overview := &RotaOverview{
+		Rota:    exportRota(t),
+		Colours: map[string]string{"Team lead": model.ColourViolet, "Service volunteer": model.ColourTeal},
+	}
+	assert.Equal(t, "rota-2025-01-05.pdf", RotaOverviewFilename(overview))
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteRotaOverviewPDF(&buf, overview))
+	out := buf.String()
+	assert.Contains(t, out, "/Count 1")
+	assert.Contains(t, out, "(Sunday 5 January 2025 to Sunday 19 January 2025)")
+	assert.Contains(t, out, "(Sun 12 Jan 2025)")
+	assert.Contains(t, out, "(Closed)")
+	assert.Contains(t, out, "(Charlie)")
+	assert.NotContains(t, out, "(Bob)", "Charlie covered him")
+	assert.Contains(t, out, "0.64 0.2 0.96 rg", "the Team lead column is violet")
+}
+
+// A rota longer than a page carries on over the next, headings and all.
+func TestWriteRotaOverviewPDFRunsOntoAnotherPage(t *testing.T) {
+	rota := &sheetsclient.PublishedRota{StartDate: "2025-01-05", RoleNames: []string{"Team lead"}}
+	for i := 0; i < 20; i++ {
+		rota.Rows = append(rota.Rows, sheetsclient.PublishedRotaRow{
+			ShiftDate: fmt.Sprintf("2025-%02d-01", i%12+1),
+			Roles:     map[string][]string{"Team lead": {"Alice", "Bob"}},
+		})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteRotaOverviewPDF(&buf, &RotaOverview{Rota: rota}))
+	assert.Contains(t, buf.String(), "/Count 2")
+}
+
+func buildSignInSheet(store *mockAvailabilityStore, shiftID string) (*SignInSheet, error) {
+	return BuildSignInSheet(context.Background(), store, swapVolunteers(), sendTestCfg, shiftID)
+}
+
+func TestBuildSignInSheet(t *testing.T) {
+	store := attendanceStore()
+	store.alterations = []db.Alteration{
+		{ID: "alt-1", ShiftID: attendanceShift1, Direction: "remove", VolunteerID: "sara", Role: "Service volunteer", CoverID: "cover-1"},
+		{ID: "alt-2", ShiftID: attendanceShift1, Direction: "add", VolunteerID: "emma", Role: "Service volunteer", CoverID: "cover-1"},
+		{ID: "alt-3", ShiftID: attendanceShift1, Direction: "add", CustomValue: "St Mary's youth group", Role: "Service volunteer", CoverID: "cover-2"},
+	}
+
+	sheet, err := buildSignInSheet(store, attendanceShift1)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-08-02", sheet.Date)
+	assert.Equal(t, "18:30", sheet.Start, "the time of day in the drop-in's own zone")
+	assert.Equal(t, "21:00", sheet.End)
+	require.Len(t, sheet.People, 3)
+	assert.Equal(t, SignInPerson{Name: "Michael Smith", Role: "Team lead", Colour: sheet.People[0].Colour}, sheet.People[0], "the Team lead first")
+	assert.NotEmpty(t, sheet.People[0].Colour)
+	assert.Equal(t, "Emma Williams", sheet.People[1].Name, "who covered Sara, not Sara")
+	assert.Equal(t, "St Mary's youth group", sheet.People[2].Name)
+	assert.Equal(t, "sign-in-2026-08-02.pdf", SignInSheetFilename(sheet))
+}
+
+func TestBuildSignInSheetRefusals(t *testing.T) {
+	tests := []struct {
+		name    string
+		shiftID string
+		setup   func(store *mockAvailabilityStore)
+		wantErr error
+	}{
+		{name: "not an id", shiftID: "shift-1", wantErr: ErrNotFound},
+		{name: "no such shift", shiftID: "0a7e1d2c-0000-4000-8000-000000000009", wantErr: ErrNotFound},
+		{
+			name:    "closed",
+			shiftID: attendanceShift1,
+			setup:   func(store *mockAvailabilityStore) { store.shifts[1].Closed = true },
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "not allocated",
+			shiftID: attendanceShift1,
+			setup:   func(store *mockAvailabilityStore) { store.rotations[0].AllocatedDatetime = "" },
+			wantErr: ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := attendanceStore()
+			if tt.setup != nil {
+				tt.setup(store)
+			}
+			_, err := buildSignInSheet(store, tt.shiftID)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestWriteSignInSheetPDF(t *testing.T) {
+	sheet := &SignInSheet{
+		Date:  "2026-08-02",
+		Start: "18:30",
+		End:   "21:00",
+		People: []SignInPerson{
+			{Name: "Michael Smith", Role: "Team lead", Colour: model.ColourViolet},
+			{Name: "Emma Williams", Role: "Service volunteer", Colour: model.ColourTeal},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSignInSheetPDF(&buf, sheet, DefaultWalkInRows))
+	out := buf.String()
+	assert.Contains(t, out, "/Count 1")
+	assert.Contains(t, out, "(Sunday 2 August 2026, 18:30 \x96 21:00)")
+	assert.Contains(t, out, "(Michael Smith)")
+	assert.Contains(t, out, "(Walk-ins)")
+
+	buf.Reset()
+	require.NoError(t, WriteSignInSheetPDF(&buf, sheet, 40))
+	assert.Contains(t, buf.String(), "/Count 2", "forty walk-ins do not fit on one page")
+
+	buf.Reset()
+	require.NoError(t, WriteSignInSheetPDF(&buf, sheet, 0))
+	assert.NotContains(t, buf.String(), "(Walk-ins)")
+
+	assert.ErrorIs(t, WriteSignInSheetPDF(&buf, sheet, -1), ErrInvalidInput)
+}
+
+func TestShiftOnDate(t *testing.T) {
+	store := &mockListShiftsStore{shifts: []db.ShiftInRange{
+		{Shift: db.Shift{ID: "shift-1", Date: "2026-08-02"}, Allocated: true},
+	}}
+
+	id, err := ShiftOnDate(context.Background(), store, "2026-08-02")
+	require.NoError(t, err)
+	assert.Equal(t, "shift-1", id)
+
+	_, err = ShiftOnDate(context.Background(), store, "2026-08-09")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = ShiftOnDate(context.Background(), store, "Sunday")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
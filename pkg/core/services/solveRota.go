@@ -40,6 +40,10 @@ type rotaSolve struct {
 	// the allocator's own types.
 	output       *allocator.CpsatOutput
 	solvedShifts []*allocator.Shift
+	// emptySeats is why each Seat the solve left empty was left empty, keyed
+	// onto Shift ids. Worked out once here, while the input that explains it is
+	// still to hand, rather than by each caller.
+	emptySeats []EmptySeat
 	// roles and volunteersByID are what turn the answer back into names, for the
 	// caller that reports the rota it drafted rather than only storing it. Kept
 	// from the assembly rather than re-read: the roster is a Google Sheet, and a
@@ -136,11 +140,6 @@ func solveRotaInFlight(
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
 	}
-	activeVolunteers := utils.FilterActiveVolunteers(allVolunteers)
-	logger.Debug("Active volunteers", zap.Int("count", len(activeVolunteers)))
-
-	allocatorVolunteers := convertToAllocatorVolunteers(activeVolunteers)
-
 	// What each Shift asks for, read from the Shift itself rather than
 	// recomputed from the settings (#137): a rota is allocated against the Shape
 	// it was defined with, whatever the settings have been edited to since. The
@@ -156,7 +155,7 @@ func solveRotaInFlight(
 	// is told what each day asks for and which days the drop-in does not run,
 	// rather than working either out (#132, #137).
 	shiftSpecs := make([]allocator.ShiftSpec, len(shiftDates))
-	orderedShiftIDs := make([]string, len(shiftDates))
+	orderedShifts := make([]AvailabilityShift, len(shiftDates))
 	for i, date := range shiftDates {
 		dateStr := date.Format("2006-01-02")
 		shiftID := shiftIDByDate[dateStr]
@@ -165,15 +164,22 @@ func solveRotaInFlight(
 			Shape:  convertShape(shapes[shiftID]),
 			Closed: closedByDate[dateStr],
 		}
-		orderedShiftIDs[i] = shiftID
+		orderedShifts[i] = AvailabilityShift{ID: shiftID, Date: dateStr}
 	}
 
-	groupAvailability, err := fetchGroupAvailability(
+	// Who is volunteering is read against the rota's own dates, not today's.
+	activeVolunteers, pausedShiftIDs := allocatableVolunteers(allVolunteers, orderedShifts)
+	logger.Debug("Active volunteers", zap.Int("count", len(activeVolunteers)))
+
+	allocatorVolunteers := convertToAllocatorVolunteers(activeVolunteers)
+
+	groupAvailability, preferredShiftCounts, err := fetchGroupAvailability(
 		ctx,
 		database,
 		targetRota.ID,
 		allocatorVolunteers,
-		orderedShiftIDs,
+		orderedShifts,
+		pausedShiftIDs,
 		logger,
 	)
 	if err != nil {
@@ -188,6 +194,7 @@ func solveRotaInFlight(
 		rotations,
 		targetRota,
 		convertToAllocatorVolunteers(allVolunteers),
+		settings.AllocationSettings,
 		logger,
 	)
 	if err != nil {
@@ -218,7 +225,7 @@ func solveRotaInFlight(
 		return nil, err
 	}
 
-	// Build the solver input and run the Python subprocess.
+	// Build the solver input and run whichever engine this deployment uses.
 	input, err := allocator.BuildCpsatInput(
 		allocatorVolunteers,
 		groupAvailability,
@@ -231,19 +238,31 @@ func solveRotaInFlight(
 	if err != nil {
 		return nil, fmt.Errorf("failed to build cpsat input: %w", err)
 	}
+	// Grouping decides which groups reach the solver, so the preferences are
+	// attached to the groups it kept rather than handed in beside them.
+	for i := range input.Groups {
+		input.Groups[i].PreferredShiftCount = preferredShiftCounts[input.Groups[i].GroupKey]
+	}
+	// The same goes for the Pairing Rules: only a pair who both reached the
+	// solver can be put on together or kept apart.
+	pairingRules, err := database.GetPairingRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pairing rules: %w", err)
+	}
+	input.PairRules = allocator.PairRulesFor(input, contractPairRules(pairingRules))
 	logger.Debug("Built cpsat input",
 		zap.Int("groups", len(input.Groups)),
 		zap.Int("shifts", len(input.Shifts)),
-		zap.Int("max_allocation_count", input.MaxAllocationCount))
+		zap.Int("max_allocation_count", input.MaxAllocationCount),
+		zap.Int("pair_rules", len(input.PairRules)))
 
-	pythonPath := allocator.ResolvePythonInterpreter(pythonFlag)
-	logger.Info("Running CP-SAT allocator", zap.String("python", pythonPath))
-	output, err := allocator.RunCpsatAllocator(ctx, pythonPath, input, logger)
+	output, err := runAllocator(ctx, cfg, pythonFlag, input, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.Info("CP-SAT solve completed",
+	logger.Info("Allocator solve completed",
+		zap.String("engine", cfg.AllocatorEngine()),
 		zap.String("solver_status", output.SolverStatus),
 		zap.Bool("success", output.Success),
 		zap.Int("objective_value", output.ObjectiveValue),
@@ -267,11 +286,37 @@ func solveRotaInFlight(
 		shapes:         shapes,
 		output:         output,
 		solvedShifts:   solvedShifts,
+		emptySeats:     explainEmptySeats(input, output, shiftIDByDate),
 		roles:          roles,
 		volunteersByID: volunteersByID,
 	}, nil
 }
 
+// runAllocator solves the input with the engine the deployment configured. Both
+// read a CpsatInput and answer with a CpsatOutput, so nothing after this point
+// knows which one ran — but only CP-SAT is the reference, and a draft solved by
+// one engine is not the rota the other would allocate, so a deployment should
+// not switch engines with a draft in front of an admin.
+//
+// The python flag means nothing to the in-process engine; it is passed through
+// for CP-SAT alone.
+func runAllocator(
+	ctx context.Context,
+	cfg *config.Config,
+	pythonFlag string,
+	input *allocator.CpsatInput,
+	logger *zap.Logger,
+) (*allocator.CpsatOutput, error) {
+	if cfg.AllocatorEngine() == config.AllocatorEngineGo {
+		logger.Info("Running in-process allocator")
+		return allocator.RunGoAllocator(ctx, input, logger)
+	}
+
+	pythonPath := allocator.ResolvePythonInterpreter(pythonFlag)
+	logger.Info("Running CP-SAT allocator", zap.String("python", pythonPath))
+	return allocator.RunCpsatAllocator(ctx, pythonPath, input, logger)
+}
+
 // seatsAsked is how many Seats the solve was asked to fill: every Seat of every
 // open Shift's Shape. A closed Shift asks for nobody however it is shaped, so it
 // counts for nothing.
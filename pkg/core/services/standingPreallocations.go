@@ -166,7 +166,10 @@ func AddStandingPreallocation(
 		if vol == nil {
 			return nil, wrapf(ErrNotFound, "volunteer %s not found", params.VolunteerID)
 		}
-		if len(utils.FilterActiveVolunteers([]model.Volunteer{*vol})) == 0 {
+		// A standing pin is about rotas not yet defined, so somebody paused is
+		// back for them; only somebody who has left, or whose status cannot be
+		// read, is refused.
+		if !vol.Lifecycle().State.Ongoing() {
 			return nil, wrapf(ErrInvalidInput, "volunteer %s is not active", params.VolunteerID)
 		}
 		if !vol.Holds(role.Name) {
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
 	"github.com/jakechorley/ilford-drop-in/pkg/db"
 )
 
@@ -154,6 +155,20 @@ func TestAddStandingPreallocation_VolunteerHappyPath(t *testing.T) {
 	assert.Equal(t, "Alice", view.Name)
 }
 
+// A standing pin is about rotas to come, which somebody paused is back for.
+func TestAddStandingPreallocation_PausedVolunteer(t *testing.T) {
+	store := &mockStandingStore{}
+	volunteers := &preallocVolClient{volunteers: []model.Volunteer{
+		{ID: "erin", FirstName: "Erin", DisplayName: "Erin", Roles: []string{"Service volunteer"}, Status: "Paused until 2036-08-01"},
+	}}
+
+	_, err := AddStandingPreallocation(context.Background(), store, volunteers, testCfg, AddStandingPreallocationParams{
+		RRule: "FREQ=WEEKLY;BYDAY=SU", RoleID: "role-service-volunteer", VolunteerID: "erin",
+	}, zap.NewNop())
+	require.NoError(t, err)
+	assert.Len(t, store.inserted, 1)
+}
+
 func TestAddStandingPreallocation_CustomHappyPath(t *testing.T) {
 	store := &mockStandingStore{}
 	view, err := addStanding(t, store, AddStandingPreallocationParams{
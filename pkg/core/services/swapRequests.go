@@ -0,0 +1,806 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/internal/config"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/core/services/utils"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+// SwapStore is what volunteers' own swaps need: the link a volunteer holds,
+// the rota as it now stands, who said they could do which shift, and the
+// requests themselves. Making a swap is an ordinary rota change, so it needs
+// everything one of those does.
+type SwapStore interface {
+	ChangeRotaStore
+	RotaDefaultsStore
+	GetRotations(ctx context.Context) ([]db.Rotation, error)
+	GetShiftsByRotaID(ctx context.Context, rotaID string) ([]db.Shift, error)
+	GetShiftByID(ctx context.Context, id string) (*db.ShiftInRange, error)
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+	GetAvailabilityRequestByToken(ctx context.Context, token string) (*db.AvailabilityRequest, error)
+	GetAvailabilityRequestsByRotaID(ctx context.Context, rotaID string) ([]db.AvailabilityRequest, error)
+	GetLatestAvailability(ctx context.Context, requestIDs []string, cutoff *time.Time) (map[string]db.AvailabilityGeneration, error)
+	InsertSwapRequest(ctx context.Context, req db.SwapRequest) error
+	GetSwapRequest(ctx context.Context, id string) (*db.SwapRequest, error)
+	GetSwapRequestsByStatus(ctx context.Context, statuses []string) ([]db.SwapRequest, error)
+	TakeSwapRequest(ctx context.Context, id, volunteerID string) (bool, error)
+	ReopenSwapRequest(ctx context.Context, id string) error
+	DeclineSwapRequest(ctx context.Context, id, adminEmail string) (bool, error)
+	WithdrawSwapRequest(ctx context.Context, id, volunteerID string) (bool, error)
+}
+
+// liveSwapStatuses are the requests still going somewhere: on offer, or taken
+// and waiting for an admin.
+var liveSwapStatuses = []string{db.SwapOpen, db.SwapTaken}
+
+// SwapShift is one of the volunteer's own upcoming shifts, as their swap page
+// shows it. RequestID and RequestStatus are their live ask to give it up, both
+// empty when they have not asked; TakenBy is who has taken it, once somebody
+// has and an admin has yet to say yes.
+type SwapShift struct {
+	ShiftID       string
+	Date          string // YYYY-MM-DD
+	Start         string // the shift's own wall-clock times; empty when untimed
+	End           string
+	Role          string
+	RequestID     string
+	RequestStatus string
+	TakenBy       string
+}
+
+// SwapOffer is somebody else's shift the volunteer could take: one they said
+// they were free for, in a Role they hold, on a date they are not already
+// working. From is the asker's first name — enough to know who they are
+// helping, and nothing a stranger holding the link could use.
+type SwapOffer struct {
+	RequestID string
+	ShiftID   string
+	Date      string
+	Start     string
+	End       string
+	Role      string
+	From      string
+}
+
+// SwapPage is what a volunteer sees behind their link once its rota is out:
+// their shifts from today on, and the ones other people are asking to give up
+// that they could take. NeedsApproval says whether taking one changes the rota
+// there and then, or waits for an admin. LeadsShifts says whether they hold
+// the leading Role, and so whether the page offers the way to their
+// attendance page.
+type SwapPage struct {
+	VolunteerName string
+	Shifts        []SwapShift
+	Offers        []SwapOffer
+	NeedsApproval bool
+	LeadsShifts   bool
+}
+
+// SwapRequestView is one live swap as an admin reads it.
+type SwapRequestView struct {
+	ID            string
+	ShiftID       string
+	Date          string
+	Role          string
+	Status        string
+	VolunteerID   string
+	VolunteerName string
+	TakenBy       string
+	TakenByName   string
+	RequestedAt   time.Time
+	TakenAt       *time.Time
+}
+
+// swapContext is everything the swap page is worked out from, read once per
+// request: the link's volunteer, their rota's upcoming shifts as they stand,
+// and what everyone on the round said they could do.
+type swapContext struct {
+	request    *db.AvailabilityRequest
+	volunteers map[string]model.Volunteer
+	roles      model.Roles
+	defaults   model.RotaDefaults
+	// shifts are the rota's open shifts from today on, in date order, and
+	// onShift who is on each of them now, alterations applied.
+	shifts  []db.Shift
+	onShift map[string][]db.Allocation
+	// available is each volunteer's latest yeses on the rota, as they stood
+	// at allocation.
+	available map[string]map[string]bool
+	// live is every open or taken request on those shifts.
+	live []db.SwapRequest
+}
+
+// loadSwapContext resolves a link to its swap page's inputs.
+//
+// The link is the volunteer's availability link, which stops answering for
+// availability once its rota is allocated and starts answering for this: one
+// link a volunteer already holds, with no second one to send them. A link whose
+// rota is not allocated yet has no shifts to swap, and is refused as a conflict
+// rather than as not found — it is a real link, arriving too early.
+func loadSwapContext(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token string,
+	now time.Time,
+) (*swapContext, error) {
+	request, err := store.GetAvailabilityRequestByToken(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up swap link: %w", err)
+	}
+	if request == nil {
+		return nil, wrapf(ErrNotFound, "no swap page for this link")
+	}
+
+	rota, err := findRotation(ctx, store, request.RotaID)
+	if err != nil {
+		return nil, err
+	}
+	if rota.AllocatedDatetime == "" {
+		return nil, wrapf(ErrConflict, "the rota for this link has not been allocated yet")
+	}
+
+	defaults, err := RotaDefaults(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	today, err := localDate(now, defaults)
+	if err != nil {
+		return nil, err
+	}
+
+	rotaShifts, err := store.GetShiftsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+	}
+	var shifts []db.Shift
+	var shiftIDs []string
+	for _, s := range rotaShifts {
+		if s.Closed || s.Date < today {
+			continue
+		}
+		shifts = append(shifts, s)
+		shiftIDs = append(shiftIDs, s.ID)
+	}
+	sort.Slice(shifts, func(i, j int) bool { return shifts[i].Date < shifts[j].Date })
+
+	onShift, err := effectiveAllocations(ctx, store, shiftIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteers := make(map[string]model.Volunteer, len(roster))
+	for _, v := range roster {
+		volunteers[v.ID] = v
+	}
+
+	available, err := roundAvailability(ctx, store, rota)
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := store.GetSwapRequestsByStatus(ctx, liveSwapStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swap requests: %w", err)
+	}
+	upcoming := make(map[string]bool, len(shiftIDs))
+	for _, id := range shiftIDs {
+		upcoming[id] = true
+	}
+	var live []db.SwapRequest
+	for _, r := range requests {
+		if upcoming[r.ShiftID] {
+			live = append(live, r)
+		}
+	}
+
+	return &swapContext{
+		request:    request,
+		volunteers: volunteers,
+		roles:      roles,
+		defaults:   defaults,
+		shifts:     shifts,
+		onShift:    onShift,
+		available:  available,
+		live:       live,
+	}, nil
+}
+
+// rotationStore is the one read findRotation needs, so the pages a
+// volunteer's link opens can share it.
+type rotationStore interface {
+	GetRotations(ctx context.Context) ([]db.Rotation, error)
+}
+
+// findRotation is one rota by id.
+func findRotation(ctx context.Context, store rotationStore, rotaID string) (*db.Rotation, error) {
+	rotations, err := store.GetRotations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rotations: %w", err)
+	}
+	for i := range rotations {
+		if rotations[i].ID == rotaID {
+			return &rotations[i], nil
+		}
+	}
+	return nil, wrapf(ErrNotFound, "rota %s not found", rotaID)
+}
+
+// localDate is the date it is now at the drop-in, which is what "upcoming" is
+// measured against: a shift tonight is still tonight's until midnight in
+// Ilford, wherever the server is.
+func localDate(now time.Time, defaults model.RotaDefaults) (string, error) {
+	loc, err := time.LoadLocation(defaults.Timezone())
+	if err != nil {
+		return "", fmt.Errorf("failed to load shift timezone %q: %w", defaults.Timezone(), err)
+	}
+	return now.In(loc).Format("2006-01-02"), nil
+}
+
+// rotaStateStore is what reading the rota as it now stands takes.
+type rotaStateStore interface {
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+}
+
+// effectiveAllocations is who is on each shift now: the allocation with every
+// alteration since applied.
+func effectiveAllocations(ctx context.Context, store rotaStateStore, shiftIDs []string) (map[string][]db.Allocation, error) {
+	if len(shiftIDs) == 0 {
+		return map[string][]db.Allocation{}, nil
+	}
+	allocations, err := store.GetAllocationsByShiftIDs(ctx, shiftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch allocations: %w", err)
+	}
+	alterations, err := store.GetAlterationsByShiftIDs(ctx, shiftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch alterations: %w", err)
+	}
+	byShiftID := make(map[string][]db.Allocation)
+	for _, a := range allocations {
+		byShiftID[a.ShiftID] = append(byShiftID[a.ShiftID], a)
+	}
+	return utils.ApplyAlterations(byShiftID, alterations), nil
+}
+
+// roundAvailabilityStore is what reading a round's answers takes, so a swap
+// offer's email can read them by the page's rule.
+type roundAvailabilityStore interface {
+	GetAvailabilityRequestsByRotaID(ctx context.Context, rotaID string) ([]db.AvailabilityRequest, error)
+	GetLatestAvailability(ctx context.Context, requestIDs []string, cutoff *time.Time) (map[string]db.AvailabilityGeneration, error)
+}
+
+// roundAvailability is each volunteer's yeses on a rota, read as they stood
+// when it was allocated — the answer allocation itself went on. Somebody who
+// has since become free can tell an admin; the page offers shifts only to the
+// people who said so when asked.
+func roundAvailability(ctx context.Context, store roundAvailabilityStore, rota *db.Rotation) (map[string]map[string]bool, error) {
+	requests, err := store.GetAvailabilityRequestsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch availability requests: %w", err)
+	}
+	ids := make([]string, 0, len(requests))
+	for _, r := range requests {
+		ids = append(ids, r.ID)
+	}
+	latest, err := store.GetLatestAvailability(ctx, ids, rotaCutoff(rota))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read availability: %w", err)
+	}
+
+	available := make(map[string]map[string]bool, len(requests))
+	for _, r := range requests {
+		yes := make(map[string]bool)
+		for _, a := range latest[r.ID].Answers {
+			yes[a.ShiftID] = true
+		}
+		available[r.VolunteerID] = yes
+	}
+	return available, nil
+}
+
+// placeOn is the volunteer's place on a shift now, if they have one.
+func (c *swapContext) placeOn(shiftID, volunteerID string) (db.Allocation, bool) {
+	for _, a := range c.onShift[shiftID] {
+		if a.VolunteerID == volunteerID {
+			return a, true
+		}
+	}
+	return db.Allocation{}, false
+}
+
+// canTake reports whether a volunteer may take a place on a shift in a Role:
+// volunteering on its date, holding the Role, said they were free that day, and
+// not already working it. A Role the drop-in no longer has asks nothing of whoever
+// takes it, so holding it is not checked.
+func (c *swapContext) canTake(volunteerID, shiftID, role string) bool {
+	volunteer, ok := c.volunteers[volunteerID]
+	if !ok || !c.volunteeringOn(volunteer, shiftID) {
+		return false
+	}
+	if _, configured := c.roles.ByName(role); configured && !volunteer.Holds(role) {
+		return false
+	}
+	if !c.available[volunteerID][shiftID] {
+		return false
+	}
+	_, already := c.placeOn(shiftID, volunteerID)
+	return !already
+}
+
+// volunteeringOn reports whether a volunteer is volunteering on the date of one
+// of the context's shifts. Somebody paused until before it is back by then.
+func (c *swapContext) volunteeringOn(volunteer model.Volunteer, shiftID string) bool {
+	for _, s := range c.shifts {
+		if s.ID != shiftID {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, s.Date)
+		return err == nil && utils.IsActiveOn(volunteer, date)
+	}
+	return false
+}
+
+// page is the swap page for the link's volunteer.
+func (c *swapContext) page() *SwapPage {
+	me := c.request.VolunteerID
+	page := &SwapPage{
+		VolunteerName: me,
+		Shifts:        []SwapShift{},
+		Offers:        []SwapOffer{},
+		NeedsApproval: c.defaults.SwapsNeedApproval,
+	}
+	if v, ok := c.volunteers[me]; ok {
+		page.VolunteerName = volunteerName(v)
+		if ordered := c.roles.ByPriority(); len(ordered) > 0 {
+			page.LeadsShifts = v.Holds(ordered[0].Name)
+		}
+	}
+
+	mine := make(map[string]db.SwapRequest)
+	for _, r := range c.live {
+		if r.VolunteerID == me {
+			mine[r.ShiftID] = r
+		}
+	}
+
+	for _, s := range c.shifts {
+		if place, ok := c.placeOn(s.ID, me); ok {
+			shift := SwapShift{ShiftID: s.ID, Date: s.Date, Start: s.StartAt, End: s.EndAt, Role: place.Role}
+			if r, asked := mine[s.ID]; asked {
+				shift.RequestID = r.ID
+				shift.RequestStatus = r.Status
+				shift.TakenBy = c.firstName(r.TakenBy)
+			}
+			page.Shifts = append(page.Shifts, shift)
+		}
+	}
+
+	shiftsByID := make(map[string]db.Shift, len(c.shifts))
+	for _, s := range c.shifts {
+		shiftsByID[s.ID] = s
+	}
+	for _, r := range c.live {
+		if r.Status != db.SwapOpen || r.VolunteerID == me || !c.canTake(me, r.ShiftID, r.Role) {
+			continue
+		}
+		s := shiftsByID[r.ShiftID]
+		page.Offers = append(page.Offers, SwapOffer{
+			RequestID: r.ID,
+			ShiftID:   r.ShiftID,
+			Date:      s.Date,
+			Start:     s.StartAt,
+			End:       s.EndAt,
+			Role:      r.Role,
+			From:      c.firstName(r.VolunteerID),
+		})
+	}
+	return page
+}
+
+// firstName is how a swap page names somebody else. Empty for nobody.
+func (c *swapContext) firstName(volunteerID string) string {
+	if volunteerID == "" {
+		return ""
+	}
+	if v, ok := c.volunteers[volunteerID]; ok && v.FirstName != "" {
+		return v.FirstName
+	}
+	return "another volunteer"
+}
+
+// GetSwapPage resolves a volunteer's link to their swap page.
+func GetSwapPage(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token string,
+	now time.Time,
+) (*SwapPage, error) {
+	c, err := loadSwapContext(ctx, store, volunteerClient, cfg, token, now)
+	if err != nil {
+		return nil, err
+	}
+	return c.page(), nil
+}
+
+// RequestSwap records the link's volunteer asking to give up one of their
+// upcoming shifts, which offers it to everyone who could take it. The place
+// they hold — its Role — is what is offered: whoever takes it takes that.
+//
+// Nobody is emailed here. The offer is on the swap page of everyone who could
+// take it from now on; telling them is a send in its own mode
+// (SendModeSwapOffer), which needs an admin's Gmail, as a cover request's
+// does.
+func RequestSwap(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token, shiftID string,
+	now time.Time,
+	logger *zap.Logger,
+) (*SwapPage, error) {
+	c, err := loadSwapContext(ctx, store, volunteerClient, cfg, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	me := c.request.VolunteerID
+	place, ok := c.placeOn(shiftID, me)
+	if !ok {
+		return nil, wrapf(ErrConflict, "you are not on that shift, or it has already happened")
+	}
+
+	req := db.SwapRequest{
+		ID:          uuid.New().String(),
+		ShiftID:     shiftID,
+		VolunteerID: me,
+		Role:        place.Role,
+		Status:      db.SwapOpen,
+	}
+	if err := store.InsertSwapRequest(ctx, req); err != nil {
+		if errors.Is(err, db.ErrDuplicateSwapRequest) {
+			return nil, wrapf(ErrConflict, "%v", err)
+		}
+		return nil, fmt.Errorf("failed to record swap request: %w", err)
+	}
+	req.RequestedAt = now
+	c.live = append(c.live, req)
+
+	logger.Info("Swap requested",
+		zap.String("swap_request_id", req.ID),
+		zap.String("shift_id", shiftID),
+		zap.String("volunteer_id", me))
+
+	return c.page(), nil
+}
+
+// WithdrawSwap takes back the link's volunteer's own ask, before it is made.
+// Once taken and waiting for an admin it can still be withdrawn — the person
+// who took it has changed nothing yet.
+func WithdrawSwap(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token, requestID string,
+	now time.Time,
+	logger *zap.Logger,
+) (*SwapPage, error) {
+	c, err := loadSwapContext(ctx, store, volunteerClient, cfg, token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawn, err := store.WithdrawSwapRequest(ctx, requestID, c.request.VolunteerID)
+	if err != nil {
+		return nil, err
+	}
+	if !withdrawn {
+		return nil, wrapf(ErrNotFound, "you have no swap waiting with that id")
+	}
+	c.live = dropRequest(c.live, requestID)
+
+	logger.Info("Swap withdrawn",
+		zap.String("swap_request_id", requestID),
+		zap.String("volunteer_id", c.request.VolunteerID))
+
+	return c.page(), nil
+}
+
+// AcceptSwap hands an offered shift to the link's volunteer. The first to
+// accept gets it; anyone after is told it has gone.
+//
+// Without approval the rota changes there and then, as an ordinary Cover. A
+// change the rota refuses — the asker has since been moved off by an admin,
+// say — puts the offer back for somebody else and says why. With approval it
+// waits, taken, for an admin to say yes.
+func AcceptSwap(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	token, requestID string,
+	now time.Time,
+	logger *zap.Logger,
+) (*SwapPage, error) {
+	c, err := loadSwapContext(ctx, store, volunteerClient, cfg, token, now)
+	if err != nil {
+		return nil, err
+	}
+	me := c.request.VolunteerID
+
+	var offered *db.SwapRequest
+	for i := range c.live {
+		if c.live[i].ID == requestID {
+			offered = &c.live[i]
+		}
+	}
+	if offered == nil {
+		return nil, wrapf(ErrNotFound, "that shift is no longer on offer")
+	}
+	if offered.Status != db.SwapOpen {
+		return nil, wrapf(ErrConflict, "somebody else has already taken that shift")
+	}
+	if offered.VolunteerID == me || !c.canTake(me, offered.ShiftID, offered.Role) {
+		return nil, wrapf(ErrConflict, "that shift is not one you can take")
+	}
+
+	taken, err := store.TakeSwapRequest(ctx, requestID, me)
+	if err != nil {
+		return nil, err
+	}
+	if !taken {
+		return nil, wrapf(ErrConflict, "somebody else has already taken that shift")
+	}
+	offered.Status = db.SwapTaken
+	offered.TakenBy = me
+
+	logger.Info("Swap taken",
+		zap.String("swap_request_id", requestID),
+		zap.String("volunteer_id", me),
+		zap.Bool("needs_approval", c.defaults.SwapsNeedApproval))
+
+	if c.defaults.SwapsNeedApproval {
+		return c.page(), nil
+	}
+
+	madeBy := me
+	if v, ok := c.volunteers[me]; ok && v.Email != "" {
+		madeBy = v.Email
+	}
+	if _, err := makeSwap(ctx, store, volunteerClient, cfg, *offered, c.shiftDate(offered.ShiftID), c.volunteers, madeBy, logger); err != nil {
+		if reopenErr := store.ReopenSwapRequest(ctx, requestID); reopenErr != nil {
+			logger.Error("Failed to put a refused swap back on offer",
+				zap.String("swap_request_id", requestID), zap.Error(reopenErr))
+		}
+		return nil, err
+	}
+
+	// The change moved people, so the page is read again rather than patched:
+	// the shift the volunteer took is now one of theirs.
+	return GetSwapPage(ctx, store, volunteerClient, cfg, token, now)
+}
+
+func (c *swapContext) shiftDate(shiftID string) string {
+	for _, s := range c.shifts {
+		if s.ID == shiftID {
+			return s.Date
+		}
+	}
+	return ""
+}
+
+// makeSwap changes the rota for a taken swap: the asker out, the taker in, in
+// the asker's Role, recorded as a Cover that settles the request in the same
+// transaction. madeBy is who the Cover says made the change — the admin who
+// approved it, or the volunteer who took it when nobody had to.
+func makeSwap(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	req db.SwapRequest,
+	date string,
+	volunteers map[string]model.Volunteer,
+	madeBy string,
+	logger *zap.Logger,
+) (*ChangeRotaResult, error) {
+	role := req.Role
+	if role == "" {
+		// A place recorded before alterations carried a Role. ChangeRota needs
+		// one for whoever comes in, and the one they would be allocated in
+		// first is the honest guess.
+		if v, ok := volunteers[req.TakenBy]; ok && len(v.Roles) > 0 {
+			role = v.Roles[0]
+		}
+	}
+
+	return ChangeRota(ctx, store, volunteerClient, cfg, ChangeRotaParams{
+		Date:          date,
+		Out:           req.VolunteerID,
+		In:            req.TakenBy,
+		Role:          role,
+		Reason:        fmt.Sprintf("Swap arranged by the volunteers: %s gave the shift up, %s took it", nameOf(volunteers, req.VolunteerID), nameOf(volunteers, req.TakenBy)),
+		UserEmail:     madeBy,
+		SwapRequestID: req.ID,
+	}, logger)
+}
+
+// nameOf is a volunteer's full name for the record, or their id for one the
+// roster has dropped.
+func nameOf(volunteers map[string]model.Volunteer, id string) string {
+	if v, ok := volunteers[id]; ok {
+		return volunteerName(v)
+	}
+	return id
+}
+
+func dropRequest(requests []db.SwapRequest, id string) []db.SwapRequest {
+	out := requests[:0:0]
+	for _, r := range requests {
+		if r.ID != id {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ListSwapRequests is every live swap, oldest first, for the admins: those
+// still on offer, and those taken and waiting for one of them to say yes.
+func ListSwapRequests(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+) ([]SwapRequestView, error) {
+	requests, err := store.GetSwapRequestsByStatus(ctx, liveSwapStatuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swap requests: %w", err)
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteers := make(map[string]model.Volunteer, len(roster))
+	for _, v := range roster {
+		volunteers[v.ID] = v
+	}
+
+	views := make([]SwapRequestView, 0, len(requests))
+	for _, r := range requests {
+		shift, err := store.GetShiftByID(ctx, r.ShiftID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up shift %s: %w", r.ShiftID, err)
+		}
+		view := SwapRequestView{
+			ID:            r.ID,
+			ShiftID:       r.ShiftID,
+			Role:          r.Role,
+			Status:        r.Status,
+			VolunteerID:   r.VolunteerID,
+			VolunteerName: nameOf(volunteers, r.VolunteerID),
+			TakenBy:       r.TakenBy,
+			RequestedAt:   r.RequestedAt,
+			TakenAt:       r.TakenAt,
+		}
+		if shift != nil {
+			view.Date = shift.Date
+		}
+		if r.TakenBy != "" {
+			view.TakenByName = nameOf(volunteers, r.TakenBy)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// takenSwap reads a swap an admin is answering, refusing one that is not
+// waiting for an answer.
+func takenSwap(ctx context.Context, store SwapStore, id string) (*db.SwapRequest, error) {
+	req, err := store.GetSwapRequest(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swap request %s: %w", id, err)
+	}
+	if req == nil {
+		return nil, wrapf(ErrNotFound, "swap request %s not found", id)
+	}
+	if req.Status != db.SwapTaken {
+		return nil, wrapf(ErrConflict, "that swap is not waiting for approval: it is %s", req.Status)
+	}
+	return req, nil
+}
+
+// ApproveSwap makes a taken swap, as the admin approving it. A swap the rota
+// now refuses — somebody moved since it was taken — stays waiting, with the
+// reason, for the admin to decline instead.
+func ApproveSwap(
+	ctx context.Context,
+	store SwapStore,
+	volunteerClient VolunteerClient,
+	cfg *config.Config,
+	id, adminEmail string,
+	logger *zap.Logger,
+) (*ChangeRotaResult, error) {
+	req, err := takenSwap(ctx, store, id)
+	if err != nil {
+		return nil, err
+	}
+	shift, err := store.GetShiftByID(ctx, req.ShiftID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up shift %s: %w", req.ShiftID, err)
+	}
+	if shift == nil {
+		return nil, wrapf(ErrNotFound, "the shift for swap request %s no longer exists", id)
+	}
+
+	roles, err := RoleTable(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	roster, err := volunteerClient.ListVolunteers(cfg, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch volunteers: %w", err)
+	}
+	volunteers := make(map[string]model.Volunteer, len(roster))
+	for _, v := range roster {
+		volunteers[v.ID] = v
+	}
+
+	result, err := makeSwap(ctx, store, volunteerClient, cfg, *req, shift.Date, volunteers, adminEmail, logger)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Swap approved",
+		zap.String("swap_request_id", id),
+		zap.String("admin_email", adminEmail),
+		zap.String("cover_id", result.CoverID))
+	return result, nil
+}
+
+// DeclineSwap refuses a taken swap. The asker keeps the shift; if they still
+// cannot do it they can ask again, or tell an admin.
+func DeclineSwap(ctx context.Context, store SwapStore, id, adminEmail string, logger *zap.Logger) error {
+	if _, err := takenSwap(ctx, store, id); err != nil {
+		return err
+	}
+	declined, err := store.DeclineSwapRequest(ctx, id, adminEmail)
+	if err != nil {
+		return err
+	}
+	if !declined {
+		return wrapf(ErrConflict, "that swap stopped waiting for approval while it was being declined")
+	}
+	logger.Info("Swap declined",
+		zap.String("swap_request_id", id),
+		zap.String("admin_email", adminEmail))
+	return nil
+}
@@ -0,0 +1,563 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+)
+
+func (m *mockAvailabilityStore) GetShiftByDate(_ context.Context, date time.Time) (*db.Shift, error) {
+	for i := range m.shifts {
+		if m.shifts[i].Date == date.Format("2006-01-02") {
+			return &m.shifts[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockAvailabilityStore) WithRotaLock(_ context.Context, _ []string, fn func(store db.RotaChangeStore) error) error {
+	return fn(m)
+}
+
+// InsertCoverAndAlterations lands the change on the rota the mock serves, so a
+// page read after a swap shows it made. Each alteration is stamped later than
+// the last, as the database's clock would.
+func (m *mockAvailabilityStore) InsertCoverAndAlterations(_ context.Context, cover *db.Cover, alterations []db.Alteration) error {
+	m.covers = append(m.covers, *cover)
+	for _, a := range alterations {
+		a.SetTime = fmt.Sprintf("2026-08-01T12:00:%02dZ", len(m.alterations))
+		m.alterations = append(m.alterations, a)
+	}
+	return nil
+}
+
+func (m *mockAvailabilityStore) swap(id string) *db.SwapRequest {
+	for i := range m.swaps {
+		if m.swaps[i].ID == id {
+			return &m.swaps[i]
+		}
+	}
+	return nil
+}
+
+func (m *mockAvailabilityStore) CompleteSwapRequest(_ context.Context, id, coverID string) error {
+	req := m.swap(id)
+	if req == nil || req.Status != db.SwapTaken {
+		return db.ErrSwapRequestNotTaken
+	}
+	req.Status, req.CoverID = db.SwapDone, coverID
+	return nil
+}
+
+func (m *mockAvailabilityStore) InsertSwapRequest(_ context.Context, req db.SwapRequest) error {
+	for _, r := range m.swaps {
+		if r.ShiftID == req.ShiftID && r.VolunteerID == req.VolunteerID && (r.Status == db.SwapOpen || r.Status == db.SwapTaken) {
+			return db.ErrDuplicateSwapRequest
+		}
+	}
+	req.RequestedAt = time.Date(2026, 8, 1, 9, len(m.swaps), 0, 0, time.UTC)
+	m.swaps = append(m.swaps, req)
+	return nil
+}
+
+func (m *mockAvailabilityStore) GetSwapRequest(_ context.Context, id string) (*db.SwapRequest, error) {
+	if req := m.swap(id); req != nil {
+		found := *req
+		return &found, nil
+	}
+	return nil, nil
+}
+
+func (m *mockAvailabilityStore) GetSwapRequestsByStatus(_ context.Context, statuses []string) ([]db.SwapRequest, error) {
+	var out []db.SwapRequest
+	for _, r := range m.swaps {
+		for _, status := range statuses {
+			if r.Status == status {
+				out = append(out, r)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (m *mockAvailabilityStore) TakeSwapRequest(_ context.Context, id, volunteerID string) (bool, error) {
+	req := m.swap(id)
+	if req == nil || req.Status != db.SwapOpen {
+		return false, nil
+	}
+	req.Status, req.TakenBy = db.SwapTaken, volunteerID
+	return true, nil
+}
+
+func (m *mockAvailabilityStore) ReopenSwapRequest(_ context.Context, id string) error {
+	if req := m.swap(id); req != nil && req.Status == db.SwapTaken {
+		req.Status, req.TakenBy = db.SwapOpen, ""
+	}
+	return nil
+}
+
+func (m *mockAvailabilityStore) DeclineSwapRequest(_ context.Context, id, adminEmail string) (bool, error) {
+	req := m.swap(id)
+	if req == nil || req.Status != db.SwapTaken {
+		return false, nil
+	}
+	req.Status, req.DecidedBy = db.SwapDeclined, adminEmail
+	return true, nil
+}
+
+func (m *mockAvailabilityStore) WithdrawSwapRequest(_ context.Context, id, volunteerID string) (bool, error) {
+	req := m.swap(id)
+	if req == nil || req.VolunteerID != volunteerID || (req.Status != db.SwapOpen && req.Status != db.SwapTaken) {
+		return false, nil
+	}
+	req.Status = db.SwapWithdrawn
+	return true, nil
+}
+
+// swapNow is the morning of 1 August: the rota's last-week shift has been,
+// and its two August ones are still to come.
+var swapNow = time.Date(2026, 8, 1, 9, 0, 0, 0, time.UTC)
+
+// swapVolunteers is the roster the swap tests run against. Tom has stopped
+// volunteering; everybody else is active.
+func swapVolunteers() *mockVolunteerClient {
+	return &mockVolunteerClient{volunteers: []model.Volunteer{
+		{ID: "michael", FirstName: "Michael", LastName: "Smith", Email: "michael@example.com", Status: "Active", Roles: []string{"Team lead", "Service volunteer"}},
+		{ID: "emma", FirstName: "Emma", LastName: "Williams", Email: "emma@example.com", Status: "Active", Roles: []string{"Service volunteer"}},
+		{ID: "sara", FirstName: "Sara", LastName: "Ali", Email: "sara@example.com", Status: "Active", Roles: []string{"Service volunteer"}},
+		{ID: "priya", FirstName: "Priya", LastName: "Shah", Email: "priya@example.com", Status: "Active", Roles: []string{"Team lead"}},
+		{ID: "tom", FirstName: "Tom", LastName: "Jones", Email: "tom@example.com", Status: "Inactive", Roles: []string{"Service volunteer"}},
+	}}
+}
+
+// swapStore is an allocated rota with one shift behind it and two to come.
+// Michael leads the first of those with Sara beside him; Emma works the
+// second. Emma, Priya and Tom all said they could do the first.
+func swapStore() *mockAvailabilityStore {
+	answered := time.Date(2026, 7, 28, 12, 0, 0, 0, time.UTC)
+	yes := func(request string, shiftIDs ...string) db.AvailabilityGeneration {
+		g := db.AvailabilityGeneration{RequestID: request, ResponseID: "resp-" + request, SubmittedAt: answered}
+		for _, id := range shiftIDs {
+			g.Answers = append(g.Answers, db.ShiftAnswer{ShiftID: id, Answer: db.AnswerYes})
+		}
+		return g
+	}
+
+	return &mockAvailabilityStore{
+		rotations: []db.Rotation{{ID: "rota-1", Start: "2026-07-26", End: "2026-08-09", ShiftCount: 3, AllocatedDatetime: "2026-07-31T09:00:00Z"}},
+		shifts: []db.Shift{
+			{ID: "shift-0", RotaID: "rota-1", Date: "2026-07-26"},
+			{ID: "shift-1", RotaID: "rota-1", Date: "2026-08-02", StartAt: "2026-08-02T18:30:00", EndAt: "2026-08-02T21:00:00"},
+			{ID: "shift-2", RotaID: "rota-1", Date: "2026-08-09"},
+		},
+		requests: []db.AvailabilityRequest{
+			{ID: "req-michael", RotaID: "rota-1", VolunteerID: "michael", Token: "tok-michael"},
+			{ID: "req-emma", RotaID: "rota-1", VolunteerID: "emma", Token: "tok-emma"},
+			{ID: "req-sara", RotaID: "rota-1", VolunteerID: "sara", Token: "tok-sara"},
+			{ID: "req-priya", RotaID: "rota-1", VolunteerID: "priya", Token: "tok-priya"},
+			{ID: "req-tom", RotaID: "rota-1", VolunteerID: "tom", Token: "tok-tom"},
+		},
+		generations: []db.AvailabilityGeneration{
+			yes("req-michael", "shift-0", "shift-1"),
+			yes("req-emma", "shift-1", "shift-2"),
+			yes("req-sara", "shift-0", "shift-1"),
+			yes("req-priya", "shift-1"),
+			yes("req-tom", "shift-1"),
+		},
+		allocations: []db.Allocation{
+			{ID: "a0", ShiftID: "shift-0", VolunteerID: "sara", Role: "Service volunteer"},
+			{ID: "a1", ShiftID: "shift-1", VolunteerID: "michael", Role: "Team lead"},
+			{ID: "a2", ShiftID: "shift-1", VolunteerID: "sara", Role: "Service volunteer"},
+			{ID: "a3", ShiftID: "shift-2", VolunteerID: "emma", Role: "Service volunteer"},
+		},
+	}
+}
+
+func requestSwap(t *testing.T, store *mockAvailabilityStore, token, shiftID string) *SwapPage {
+	t.Helper()
+	page, err := RequestSwap(context.Background(), store, swapVolunteers(), sendTestCfg, token, shiftID, swapNow, zap.NewNop())
+	require.NoError(t, err)
+	return page
+}
+
+func swapPage(t *testing.T, store *mockAvailabilityStore, token string) *SwapPage {
+	t.Helper()
+	page, err := GetSwapPage(context.Background(), store, swapVolunteers(), sendTestCfg, token, swapNow)
+	require.NoError(t, err)
+	return page
+}
+
+// TestSwapPageShowsUpcomingShifts: the page is the volunteer's own shifts
+// from today on — last week's is not theirs to give away any more.
+func TestSwapPageShowsUpcomingShifts(t *testing.T) {
+	page := swapPage(t, swapStore(), "tok-sara")
+
+	assert.Equal(t, "Sara Ali", page.VolunteerName)
+	require.Len(t, page.Shifts, 1)
+	assert.Equal(t, SwapShift{
+		ShiftID: "shift-1",
+		Date:    "2026-08-02",
+		Start:   "2026-08-02T18:30:00",
+		End:     "2026-08-02T21:00:00",
+		Role:    "Service volunteer",
+	}, page.Shifts[0])
+	assert.Empty(t, page.Offers)
+	assert.False(t, page.NeedsApproval)
+}
+
+// TestSwapIsOfferedOnlyToThoseWhoCouldTakeIt: a shift somebody asks to give up
+// is offered to the people who hold its Role, said they were free that day,
+// are still volunteering and are not already on it — and to nobody else.
+func TestSwapIsOfferedOnlyToThoseWhoCouldTakeIt(t *testing.T) {
+	store := swapStore()
+	page := requestSwap(t, store, "tok-sara", "shift-1")
+	require.Len(t, page.Shifts, 1)
+	assert.Equal(t, db.SwapOpen, page.Shifts[0].RequestStatus)
+
+	tests := []struct {
+		token   string
+		offered bool
+		why     string
+	}{
+		{token: "tok-emma", offered: true, why: "free that day and a service volunteer"},
+		{token: "tok-michael", offered: false, why: "already working it"},
+		{token: "tok-priya", offered: false, why: "does not hold the Role"},
+		{token: "tok-tom", offered: false, why: "no longer volunteering"},
+		{token: "tok-sara", offered: false, why: "it is her own"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			offers := swapPage(t, store, tt.token).Offers
+			if !tt.offered {
+				assert.Empty(t, offers, tt.why)
+				return
+			}
+			require.Len(t, offers, 1, tt.why)
+			assert.Equal(t, "shift-1", offers[0].ShiftID)
+			assert.Equal(t, "Service volunteer", offers[0].Role)
+			assert.Equal(t, "Sara", offers[0].From)
+		})
+	}
+}
+
+// TestAcceptSwapChangesTheRota: with nobody to approve it, the first to accept
+// is on the rota there and then, as an ordinary Cover, and the swap is settled
+// against it. Anyone after finds it gone.
+func TestAcceptSwapChangesTheRota(t *testing.T) {
+	store := swapStore()
+	store.generations = append(store.generations, db.AvailabilityGeneration{
+		RequestID: "req-michael", ResponseID: "resp-late", SubmittedAt: time.Date(2026, 7, 29, 0, 0, 0, 0, time.UTC),
+		Answers: []db.ShiftAnswer{{ShiftID: "shift-2", Answer: db.AnswerYes}},
+	})
+	requestSwap(t, store, "tok-sara", "shift-1")
+	requestID := store.swaps[0].ID
+
+	page, err := AcceptSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-emma", requestID, swapNow, zap.NewNop())
+	require.NoError(t, err)
+
+	require.Len(t, page.Shifts, 2, "the shift Emma took is one of hers now")
+	assert.Equal(t, "shift-1", page.Shifts[0].ShiftID)
+	assert.Equal(t, "Service volunteer", page.Shifts[0].Role)
+
+	require.Len(t, store.covers, 1)
+	assert.Equal(t, "emma@example.com", store.covers[0].UserEmail)
+	assert.Contains(t, store.covers[0].Reason, "Sara Ali gave the shift up, Emma Williams took it")
+	assert.Equal(t, db.SwapDone, store.swaps[0].Status)
+	assert.Equal(t, store.covers[0].ID, store.swaps[0].CoverID)
+	assert.Empty(t, swapPage(t, store, "tok-sara").Shifts)
+
+	_, err = AcceptSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-emma", requestID, swapNow, zap.NewNop())
+	assert.ErrorIs(t, err, ErrNotFound, "a swap already made is no longer on offer")
+}
+
+// TestAcceptSwapWaitsForApprovalWhenConfigured: with approval switched on, a
+// take changes nothing until an admin says yes — and the asker can see who
+// has taken it in the meantime.
+func TestAcceptSwapWaitsForApprovalWhenConfigured(t *testing.T) {
+	store := swapStore()
+	store.defaults.SwapsNeedApproval = true
+	requestSwap(t, store, "tok-sara", "shift-1")
+	requestID := store.swaps[0].ID
+
+	page, err := AcceptSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-emma", requestID, swapNow, zap.NewNop())
+	require.NoError(t, err)
+	assert.True(t, page.NeedsApproval)
+	assert.Empty(t, store.covers, "nothing changes before an admin says yes")
+
+	sara := swapPage(t, store, "tok-sara")
+	require.Len(t, sara.Shifts, 1)
+	assert.Equal(t, db.SwapTaken, sara.Shifts[0].RequestStatus)
+	assert.Equal(t, "Emma", sara.Shifts[0].TakenBy)
+
+	waiting, err := ListSwapRequests(context.Background(), store, swapVolunteers(), sendTestCfg)
+	require.NoError(t, err)
+	require.Len(t, waiting, 1)
+	assert.Equal(t, "2026-08-02", waiting[0].Date)
+	assert.Equal(t, "Sara Ali", waiting[0].VolunteerName)
+	assert.Equal(t, "Emma Williams", waiting[0].TakenByName)
+
+	result, err := ApproveSwap(context.Background(), store, swapVolunteers(), sendTestCfg, requestID, "admin@example.com", zap.NewNop())
+	require.NoError(t, err)
+	require.Len(t, store.covers, 1)
+	assert.Equal(t, result.CoverID, store.covers[0].ID)
+	assert.Equal(t, "admin@example.com", store.covers[0].UserEmail)
+	assert.Equal(t, db.SwapDone, store.swaps[0].Status)
+
+	err = DeclineSwap(context.Background(), store, requestID, "admin@example.com", zap.NewNop())
+	assert.ErrorIs(t, err, ErrConflict, "a swap already made is not waiting for an answer")
+}
+
+// TestDeclineSwapLeavesTheRotaAlone: the asker keeps the shift.
+func TestDeclineSwapLeavesTheRotaAlone(t *testing.T) {
+	store := swapStore()
+	store.defaults.SwapsNeedApproval = true
+	requestSwap(t, store, "tok-sara", "shift-1")
+	requestID := store.swaps[0].ID
+	_, err := AcceptSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-emma", requestID, swapNow, zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, DeclineSwap(context.Background(), store, requestID, "admin@example.com", zap.NewNop()))
+
+	assert.Equal(t, db.SwapDeclined, store.swaps[0].Status)
+	assert.Empty(t, store.covers)
+	sara := swapPage(t, store, "tok-sara")
+	require.Len(t, sara.Shifts, 1)
+	assert.Empty(t, sara.Shifts[0].RequestStatus, "she can ask again")
+}
+
+// TestAcceptSwapPutsARefusedSwapBackOnOffer: the rota has the last word. A
+// swap it refuses — an admin has moved the asker off since — is not left
+// taken by somebody who did not get it.
+func TestAcceptSwapPutsARefusedSwapBackOnOffer(t *testing.T) {
+	store := swapStore()
+	requestSwap(t, store, "tok-sara", "shift-1")
+	requestID := store.swaps[0].ID
+	store.alterations = append(store.alterations, db.Alteration{
+		ID: "alt-admin", ShiftID: "shift-1", Direction: "remove", VolunteerID: "sara", CoverID: "cover-admin", SetTime: "2026-08-01T08:00:00Z",
+	})
+
+	_, err := AcceptSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-emma", requestID, swapNow, zap.NewNop())
+
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, db.SwapOpen, store.swaps[0].Status)
+	assert.Empty(t, store.swaps[0].TakenBy)
+	assert.Empty(t, store.covers)
+}
+
+// TestSwapRefusals: what a link may not do, and how it is told.
+func TestSwapRefusals(t *testing.T) {
+	unallocated := swapStore()
+	unallocated.rotations[0].AllocatedDatetime = ""
+
+	tests := []struct {
+		name    string
+		store   *mockAvailabilityStore
+		act     func(store *mockAvailabilityStore) error
+		wantErr error
+	}{
+		{
+			name: "a link that is not one",
+			act: func(store *mockAvailabilityStore) error {
+				_, err := GetSwapPage(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-nobody", swapNow)
+				return err
+			},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:  "a rota not allocated yet",
+			store: unallocated,
+			act: func(store *mockAvailabilityStore) error {
+				_, err := GetSwapPage(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-sara", swapNow)
+				return err
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "somebody else's shift",
+			act: func(store *mockAvailabilityStore) error {
+				_, err := RequestSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-sara", "shift-2", swapNow, zap.NewNop())
+				return err
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "a shift that has been",
+			act: func(store *mockAvailabilityStore) error {
+				_, err := RequestSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-sara", "shift-0", swapNow, zap.NewNop())
+				return err
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "asking twice",
+			act: func(store *mockAvailabilityStore) error {
+				requestSwap(t, store, "tok-sara", "shift-1")
+				_, err := RequestSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-sara", "shift-1", swapNow, zap.NewNop())
+				return err
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "taking one not offered to you",
+			act: func(store *mockAvailabilityStore) error {
+				requestSwap(t, store, "tok-sara", "shift-1")
+				_, err := AcceptSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-priya", store.swaps[0].ID, swapNow, zap.NewNop())
+				return err
+			},
+			wantErr: ErrConflict,
+		},
+		{
+			name: "withdrawing somebody else's",
+			act: func(store *mockAvailabilityStore) error {
+				requestSwap(t, store, "tok-sara", "shift-1")
+				_, err := WithdrawSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-emma", store.swaps[0].ID, swapNow, zap.NewNop())
+				return err
+			},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store
+			if store == nil {
+				store = swapStore()
+			}
+			assert.ErrorIs(t, tt.act(store), tt.wantErr)
+			assert.Empty(t, store.covers)
+		})
+	}
+}
+
+// aheadOfToday moves the store's rota ten years on, for a test about a pause
+// that must still be running on the real today: the lifecycle used to be
+// judged on that, rather than on the shift. The answers and the allocation
+// stay where they were, so they still come before the rota's cutoff.
+func aheadOfToday(store *mockAvailabilityStore) time.Time {
+	ahead := func(s string) string { return strings.Replace(s, "2026-", "2036-", 1) }
+	for i := range store.shifts {
+		s := &store.shifts[i]
+		s.Date, s.StartAt, s.EndAt = ahead(s.Date), ahead(s.StartAt), ahead(s.EndAt)
+	}
+	for i := range store.rotations {
+		r := &store.rotations[i]
+		r.Start, r.End = ahead(r.Start), ahead(r.End)
+	}
+	return swapNow.AddDate(10, 0, 0)
+}
+
+// TestSwapIsOfferedToAVolunteerBackByThen: a pause is judged on the shift's
+// own date, so somebody back by the day is offered it and somebody back after
+// it is not.
+func TestSwapIsOfferedToAVolunteerBackByThen(t *testing.T) {
+	tests := []struct {
+		status  string
+		offered bool
+	}{
+		{status: "Paused until 2036-08-02", offered: true},
+		{status: "Paused until 2036-08-03", offered: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			store := swapStore()
+			now := aheadOfToday(store)
+			volunteers := swapVolunteers()
+			volunteers.volunteers[1].Status = tt.status // Emma
+
+			_, err := RequestSwap(context.Background(), store, volunteers, sendTestCfg, "tok-sara", "shift-1", now, zap.NewNop())
+			require.NoError(t, err)
+			page, err := GetSwapPage(context.Background(), store, volunteers, sendTestCfg, "tok-emma", now)
+			require.NoError(t, err)
+
+			if tt.offered {
+				assert.Len(t, page.Offers, 1)
+			} else {
+				assert.Empty(t, page.Offers)
+			}
+		})
+	}
+}
+
+// TestWithdrawSwapTakesTheOfferBack: the asker changed their mind, so nobody
+// is offered it any more.
+func TestWithdrawSwapTakesTheOfferBack(t *testing.T) {
+	store := swapStore()
+	requestSwap(t, store, "tok-sara", "shift-1")
+
+	page, err := WithdrawSwap(context.Background(), store, swapVolunteers(), sendTestCfg, "tok-sara", store.swaps[0].ID, swapNow, zap.NewNop())
+	require.NoError(t, err)
+
+	assert.Empty(t, page.Shifts[0].RequestStatus)
+	assert.Equal(t, db.SwapWithdrawn, store.swaps[0].Status)
+	assert.Empty(t, swapPage(t, store, "tok-emma").Offers)
+}
+
+// swapOfferParams is the send an admin starts from the swap list. The send
+// judges the shift against the real clock, not swapNow, so the test's swap is
+// moved a week ahead of today once asked for.
+func swapOfferParams(t *testing.T, store *mockAvailabilityStore) SendParams {
+	t.Helper()
+	requestSwap(t, store, "tok-sara", "shift-1")
+	ahead := time.Now().AddDate(0, 0, 7).Format(time.DateOnly)
+	for i := range store.shifts {
+		if store.shifts[i].ID == "shift-1" {
+			store.shifts[i].Date = ahead
+			store.shifts[i].StartAt = ahead + "T18:30:00"
+			store.shifts[i].EndAt = ahead + "T21:00:00"
+		}
+	}
+
+	params := sendParams(SendModeSwapOffer)
+	params.SwapRequestID = store.swaps[0].ID
+	return params
+}
+
+// TestSendSwapOfferTellsThoseWhoCouldTakeIt: the offer goes to exactly the
+// volunteers the swap page would offer it to, each with a link to their own
+// page, and not to the asker.
+func TestSendSwapOfferTellsThoseWhoCouldTakeIt(t *testing.T) {
+	store := swapStore()
+	params := swapOfferParams(t, store)
+	mailer := &mockMailer{}
+
+	send, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, params, zap.NewNop())
+	require.NoError(t, err)
+	RunAvailabilitySend(context.Background(), store, swapVolunteers(), mailer, sendTestCfg, zap.NewNop(), *send, params.Link, params.CoverLink, params.SwapLink, params.CalendarLink)
+
+	assert.Equal(t, "rota-1", send.RotaID)
+	assert.Equal(t, store.swaps[0].ID, send.SwapRequestID)
+	assert.Equal(t, []string{"emma@example.com"}, mailer.recipients())
+	require.Len(t, mailer.sent, 1)
+	assert.Contains(t, mailer.sent[0].body, "https://drop-in.example/swaps/tok-emma")
+	assert.Contains(t, mailer.sent[0].body, "Sara")
+}
+
+// TestSendSwapOfferRefusesASwapNoLongerOpen: a swap somebody took or the
+// asker withdrew is nobody's to be offered.
+func TestSendSwapOfferRefusesASwapNoLongerOpen(t *testing.T) {
+	for _, status := range []string{db.SwapTaken, db.SwapWithdrawn} {
+		t.Run(status, func(t *testing.T) {
+			store := swapStore()
+			params := swapOfferParams(t, store)
+			store.swaps[0].Status = status
+
+			_, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, params, zap.NewNop())
+			assert.ErrorIs(t, err, ErrConflict)
+		})
+	}
+
+	store := swapStore()
+	params := swapOfferParams(t, store)
+	params.SwapRequestID = "00000000-0000-0000-0000-000000000000"
+	_, err := BeginAvailabilitySend(context.Background(), store, sendAdmin, params, zap.NewNop())
+	assert.ErrorIs(t, err, ErrNotFound)
+}
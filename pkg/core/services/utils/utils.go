@@ -3,7 +3,6 @@ package utils
 import (
 	"fmt"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/jakechorley/ilford-drop-in/pkg/core/model"
@@ -37,14 +36,45 @@ func FindLatestRotation(rotations []db.Rotation) *db.Rotation {
 	return latest
 }
 
-// IsActive reports whether a volunteer is still volunteering. Status is free
-// text from the sheet, so it is matched case-insensitively and anything other
-// than "Active" is not.
+// IsActive reports whether a volunteer is volunteering today: Active, or
+// Onboarding, or Paused with the pause over (model.VolunteerState).
 func IsActive(volunteer model.Volunteer) bool {
-	return strings.EqualFold(volunteer.Status, "Active")
+	return IsActiveOn(volunteer, time.Now())
 }
 
-// FilterActiveVolunteers filters volunteers to only those with "Active" status (case-insensitive)
+// IsActiveOn reports whether a volunteer is volunteering on the day date falls
+// on.
+func IsActiveOn(volunteer model.Volunteer, date time.Time) bool {
+	return volunteer.Lifecycle().On(date).Volunteering()
+}
+
+// IsActiveOnAny reports whether a volunteer is volunteering on any of dates —
+// a rota's shift dates, say: somebody paused until partway through a rota is
+// on it from then, and is asked about it and allocated to it like anybody else.
+func IsActiveOnAny(volunteer model.Volunteer, dates []time.Time) bool {
+	status := volunteer.Lifecycle()
+	for _, date := range dates {
+		if status.On(date).Volunteering() {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterActiveOnAny filters volunteers to those volunteering on any of dates,
+// as IsActiveOnAny decides it.
+func FilterActiveOnAny(volunteers []model.Volunteer, dates []time.Time) []model.Volunteer {
+	active := make([]model.Volunteer, 0)
+	for _, vol := range volunteers {
+		if IsActiveOnAny(vol, dates) {
+			active = append(active, vol)
+		}
+	}
+	return active
+}
+
+// FilterActiveVolunteers filters volunteers to only those volunteering today,
+// as IsActive decides it.
 func FilterActiveVolunteers(volunteers []model.Volunteer) []model.Volunteer {
 	active := make([]model.Volunteer, 0)
 	for _, vol := range volunteers {
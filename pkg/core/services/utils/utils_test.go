@@ -2,6 +2,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,14 +17,35 @@ func TestFilterActiveVolunteers(t *testing.T) {
 		{ID: "vol-3", Status: "Inactive"},
 		{ID: "vol-4", Status: "ACTIVE"},
 		{ID: "vol-5", Status: "On Leave"},
+		{ID: "vol-6", Status: "Onboarding"},
+		{ID: "vol-7", Status: "Left"},
+		{ID: "vol-8", Status: "Paused until 2000-01-01"},
+		{ID: "vol-9", Status: "Paused until 2999-01-01"},
 	}
 
 	active := FilterActiveVolunteers(volunteers)
 
-	require.Len(t, active, 3)
-	assert.Equal(t, "vol-1", active[0].ID)
-	assert.Equal(t, "vol-2", active[1].ID)
-	assert.Equal(t, "vol-4", active[2].ID)
+	assert.Equal(t, []string{"vol-1", "vol-2", "vol-4", "vol-6", "vol-8"}, GetVolunteerIDs(active),
+		"onboarding counts, and so does a pause that is over")
+}
+
+func TestFilterActiveOnAny(t *testing.T) {
+	volunteers := []model.Volunteer{
+		{ID: "vol-1", Status: "Active"},
+		{ID: "vol-2", Status: "Left"},
+		{ID: "vol-3", Status: "Paused until 2036-08-01"},
+		{ID: "vol-4", Status: "Paused until 2036-08-10"},
+		{ID: "vol-5", Status: "Paused until 2036-08-11"},
+	}
+	dates := []time.Time{
+		time.Date(2036, 8, 3, 0, 0, 0, 0, time.UTC),
+		time.Date(2036, 8, 10, 0, 0, 0, 0, time.UTC),
+	}
+
+	active := FilterActiveOnAny(volunteers, dates)
+
+	assert.Equal(t, []string{"vol-1", "vol-3", "vol-4"}, GetVolunteerIDs(active),
+		"a pause over by the last date counts, whatever today is")
 }
 
 func TestGetVolunteerIDs(t *testing.T) {
@@ -20,10 +20,17 @@ import (
 // a generation that ticked nothing is "no_availability"; anything else is
 // "available". The Forms path had a fifth, "form_error", for a form that could
 // not be read — there is no API left to fail, so it is gone.
+//
+// Attended, NoShows and LeftEarly count the rota's shifts the volunteer was on,
+// as the rota finally stood, by what was recorded of them. A shift nobody
+// recorded counts towards none of them, so they need not add up to anything.
 type VolunteerRotaStatus struct {
 	Status         string // "no_form", "no_response", "no_availability", "available"
 	AvailableCount int    // number of shifts available (only set when Status == "available")
 	ShiftCount     int    // total shifts in the rotation
+	Attended       int
+	NoShows        int
+	LeftEarly      int
 }
 
 // ViewHistoricalResponsesResult contains the historical response data for display
@@ -33,12 +40,22 @@ type ViewHistoricalResponsesResult struct {
 	Matrix     map[string]map[string]VolunteerRotaStatus // [volunteerID][rotaID] -> status
 }
 
+// roundStatusStore is what reading one rota's round takes, shared with the
+// activity report.
+type roundStatusStore interface {
+	GetAvailabilityRequestsByRotaID(ctx context.Context, rotaID string) ([]db.AvailabilityRequest, error)
+	GetLatestAvailability(ctx context.Context, requestIDs []string, cutoff *time.Time) (map[string]db.AvailabilityGeneration, error)
+}
+
 // ViewHistoricalResponsesStore defines the database operations needed
 type ViewHistoricalResponsesStore interface {
 	RoleStore
+	roundStatusStore
 	GetRotations(ctx context.Context) ([]db.Rotation, error)
-	GetAvailabilityRequestsByRotaID(ctx context.Context, rotaID string) ([]db.AvailabilityRequest, error)
-	GetLatestAvailability(ctx context.Context, requestIDs []string, cutoff *time.Time) (map[string]db.AvailabilityGeneration, error)
+	GetShiftsByRotaID(ctx context.Context, rotaID string) ([]db.Shift, error)
+	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Allocation, error)
+	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Alteration, error)
+	GetAttendanceByShiftIDs(ctx context.Context, shiftIDs []string) ([]db.Attendance, error)
 }
 
 // ViewHistoricalResponses summarises volunteer response status across recent
@@ -93,7 +110,10 @@ func ViewHistoricalResponses(
 
 	// Step 2: Read each selected rota's round — who was asked, and what they had
 	// said by the time the rota was allocated.
+	// Attendance is read alongside: somebody who answers every round and
+	// then does not come is the other half of what the report is looking for.
 	statusByRota := make(map[string]map[string]VolunteerRotaStatus, len(selectedRotations))
+	attendanceByRota := make(map[string]map[string]VolunteerRotaStatus, len(selectedRotations))
 	askedVolunteerIDs := make(map[string]bool)
 	for _, rota := range selectedRotations {
 		statuses, err := rotaResponseStatuses(ctx, database, rota)
@@ -104,6 +124,15 @@ func ViewHistoricalResponses(
 		for volunteerID := range statuses {
 			askedVolunteerIDs[volunteerID] = true
 		}
+
+		attendance, err := rotaAttendanceCounts(ctx, database, rota)
+		if err != nil {
+			return nil, fmt.Errorf("rota %s: %w", rota.ID, err)
+		}
+		attendanceByRota[rota.ID] = attendance
+		for volunteerID := range attendance {
+			askedVolunteerIDs[volunteerID] = true
+		}
 	}
 
 	// Step 3: Fetch volunteer list
@@ -152,6 +181,8 @@ func ViewHistoricalResponses(
 			if !asked {
 				status = VolunteerRotaStatus{Status: "no_form", ShiftCount: rota.ShiftCount}
 			}
+			counts := attendanceByRota[rota.ID][vol.ID]
+			status.Attended, status.NoShows, status.LeftEarly = counts.Attended, counts.NoShows, counts.LeftEarly
 			matrix[vol.ID][rota.ID] = status
 		}
 	}
@@ -172,7 +203,7 @@ func ViewHistoricalResponses(
 // are simply absent — the caller decides what that means.
 func rotaResponseStatuses(
 	ctx context.Context,
-	database ViewHistoricalResponsesStore,
+	database roundStatusStore,
 	rota db.Rotation,
 ) (map[string]VolunteerRotaStatus, error) {
 	cutoff, err := time.Parse(time.RFC3339, rota.AllocatedDatetime)
@@ -214,3 +245,44 @@ func rotaResponseStatuses(
 
 	return statuses, nil
 }
+
+// rotaAttendanceCounts counts what was recorded of each volunteer on a rota's
+// shifts, keyed by volunteer id, in a status carrying only the counts. Only
+// the rota as it finally stood is counted: a record left against somebody
+// since taken off the shift is not held against them.
+func rotaAttendanceCounts(
+	ctx context.Context,
+	database ViewHistoricalResponsesStore,
+	rota db.Rotation,
+) (map[string]VolunteerRotaStatus, error) {
+	shifts, err := database.GetShiftsByRotaID(ctx, rota.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch shifts: %w", err)
+	}
+	onShift, err := effectiveAllocations(ctx, database, shiftIDsOf(shifts))
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := database.GetAttendanceByShiftIDs(ctx, shiftIDsOf(shifts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attendance: %w", err)
+	}
+
+	counts := make(map[string]VolunteerRotaStatus)
+	for _, a := range recorded {
+		if !isOnShift(onShift[a.ShiftID], a.VolunteerID) {
+			continue
+		}
+		c := counts[a.VolunteerID]
+		switch a.Status {
+		case db.AttendanceAttended:
+			c.Attended++
+		case db.AttendanceNoShow:
+			c.NoShows++
+		case db.AttendanceLeftEarly:
+			c.LeftEarly++
+		}
+		counts[a.VolunteerID] = c
+	}
+	return counts, nil
+}
@@ -22,6 +22,10 @@ type mockHistoricalStore struct {
 	rotations          []db.Rotation
 	requests           []db.AvailabilityRequest
 	generations        map[string]db.AvailabilityGeneration // keyed by request id
+	shifts             []db.Shift
+	allocations        []db.Allocation
+	alterations        []db.Alteration
+	attendance         []db.Attendance
 	getRotationsErr    error
 	getAvailabilityErr error
 }
@@ -61,6 +65,49 @@ func (m *mockHistoricalStore) GetLatestAvailability(ctx context.Context, request
 	return latest, nil
 }
 
+func (m *mockHistoricalStore) GetShiftsByRotaID(_ context.Context, rotaID string) ([]db.Shift, error) {
+	var out []db.Shift
+	for _, s := range m.shifts {
+		if s.RotaID == rotaID {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockHistoricalStore) GetAllocationsByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Allocation, error) {
+	want := idSet(shiftIDs)
+	var out []db.Allocation
+	for _, a := range m.allocations {
+		if want[a.ShiftID] {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockHistoricalStore) GetAlterationsByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Alteration, error) {
+	want := idSet(shiftIDs)
+	var out []db.Alteration
+	for _, a := range m.alterations {
+		if want[a.ShiftID] {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockHistoricalStore) GetAttendanceByShiftIDs(_ context.Context, shiftIDs []string) ([]db.Attendance, error) {
+	want := idSet(shiftIDs)
+	var out []db.Attendance
+	for _, a := range m.attendance {
+		if want[a.ShiftID] {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
 // answersOn builds a generation's positives from shift ids, submitted at the
 // given moment.
 func answersOn(submittedAt time.Time, shiftIDs ...string) db.AvailabilityGeneration {
@@ -389,3 +436,50 @@ func TestViewHistoricalResponses_EmptyVolunteerIDFilterShowsAll(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, result.Volunteers, 2)
 }
+
+// Attendance is counted per rota against whoever was on each shift as the
+// rota finally stood. Bob was never asked about rota-1 but worked it, so he
+// appears; a record left against Alice after she was swapped off is ignored.
+func TestViewHistoricalResponses_Attendance(t *testing.T) {
+	store := &mockHistoricalStore{
+		rotations: []db.Rotation{allocatedRota("rota-1", "2025-01-05", 3)},
+		requests:  []db.AvailabilityRequest{historicalRequest("rota-1", "alice")},
+		shifts: []db.Shift{
+			{ID: "s1", RotaID: "rota-1", Date: "2025-01-05"},
+			{ID: "s2", RotaID: "rota-1", Date: "2025-01-12"},
+			{ID: "s3", RotaID: "rota-1", Date: "2025-01-19"},
+		},
+		allocations: []db.Allocation{
+			{ID: "a1", ShiftID: "s1", VolunteerID: "alice", Role: "Service volunteer"},
+			{ID: "a2", ShiftID: "s2", VolunteerID: "alice", Role: "Service volunteer"},
+			{ID: "a3", ShiftID: "s2", VolunteerID: "bob", Role: "Service volunteer"},
+			{ID: "a4", ShiftID: "s3", VolunteerID: "alice", Role: "Service volunteer"},
+		},
+		alterations: []db.Alteration{
+			{ID: "alt-1", ShiftID: "s3", Direction: "remove", VolunteerID: "alice", Role: "Service volunteer", CoverID: "c1"},
+		},
+		attendance: []db.Attendance{
+			{ShiftID: "s1", VolunteerID: "alice", Status: db.AttendanceNoShow},
+			{ShiftID: "s2", VolunteerID: "alice", Status: db.AttendanceAttended},
+			{ShiftID: "s2", VolunteerID: "bob", Status: db.AttendanceLeftEarly},
+			{ShiftID: "s3", VolunteerID: "alice", Status: db.AttendanceNoShow},
+		},
+	}
+	volClient := &mockHistoricalVolClient{volunteers: []model.Volunteer{
+		{ID: "alice", FirstName: "Alice", LastName: "Smith"},
+		{ID: "bob", FirstName: "Bob", LastName: "Jones"},
+	}}
+
+	result, err := ViewHistoricalResponses(context.Background(), store, volClient, &config.Config{}, zap.NewNop(), 1, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Volunteers, 2)
+
+	alice := result.Matrix["alice"]["rota-1"]
+	assert.Equal(t, "no_response", alice.Status)
+	assert.Equal(t, 1, alice.Attended)
+	assert.Equal(t, 1, alice.NoShows, "the no-show after she was swapped off does not count")
+
+	bob := result.Matrix["bob"]["rota-1"]
+	assert.Equal(t, "no_form", bob.Status)
+	assert.Equal(t, 1, bob.LeftEarly)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -32,14 +33,19 @@ var calendarReminders = []struct {
 }
 
 // BuildVolunteerCalendar renders the volunteer's shifts as a subscribable
-// iCal feed. Pure (no I/O); callers should pass the volunteer's open shifts,
-// e.g. FilterShiftsByVolunteer(shifts, volunteer.ID).
+// iCal feed. Pure (no I/O); callers should pass the volunteer's shifts as
+// VolunteerCalendarShifts picks them.
 //
 // Stability matters to polling calendar clients: UIDs are derived from
 // volunteer and date so clients update events in place rather than
 // duplicating them, SEQUENCE increases with each alteration to the shift, and
 // DTSTAMP only changes when the shift changes.
 //
+// A shift the volunteer has been taken off, or that has closed under them, is
+// written as a cancellation of the event they had rather than left out. Some
+// clients — Outlook among them — keep an event a feed stops mentioning, so a
+// volunteer swapped off a Sunday would otherwise turn up for it.
+//
 // The times come from each shift, which carries the local hours it runs
 // between (ADR 0007). The settings supply the zone those hours are read in, so
 // a subscriber in another country sees the evening at their own reckoning of
@@ -49,16 +55,17 @@ var calendarReminders = []struct {
 // rotaURL is where the rota lives, for the link on each event. Empty leaves the
 // link out rather than writing a broken one.
 func BuildVolunteerCalendar(shifts []Shift, volunteer model.Volunteer, roles model.Roles, defaults model.RotaDefaults, rotaURL string) (string, error) {
-	cal := ics.NewCalendar()
-	cal.SetProductId("-//ilford-drop-in//EN")
-	cal.SetCalscale("GREGORIAN")
-	cal.SetMethod(ics.MethodPublish)
-	cal.SetXWRCalName("Ilford Drop-In — " + volunteer.DisplayName)
-	cal.SetXWRTimezone(defaults.Timezone())
-	cal.SetRefreshInterval(calendarRefreshInterval)
-	cal.SetXPublishedTTL(calendarRefreshInterval)
+	cal := newCalendar("Ilford Drop-In — "+volunteer.DisplayName, defaults)
 
 	for _, shift := range shifts {
+		uid := fmt.Sprintf("%s-%s@ilford-drop-in", volunteer.ID, shift.Date)
+		if shift.Closed || slices.Contains(shift.FormerVolunteerIDs, volunteer.ID) {
+			if err := addCancelledEvent(cal, uid, shift, defaults, rotaURL); err != nil {
+				return "", err
+			}
+			continue
+		}
+
 		// The summary names the Role the volunteer is doing the shift in.
 		// It used to name only a capped one, on the grounds that being on the
 		// shift *was* the uncapped Role and saying so would be noise; with no
@@ -69,27 +76,10 @@ func BuildVolunteerCalendar(shifts []Shift, volunteer model.Volunteer, roles mod
 			summary += " (" + role + ")"
 		}
 
-		event := cal.AddEvent(fmt.Sprintf("%s-%s@ilford-drop-in", volunteer.ID, shift.Date))
-		stamp, err := setEventDates(event, shift, defaults)
-		if err != nil {
+		description := shiftDescription(shift, volunteer, roles, rotaURL)
+		if err := addShiftEvent(cal, uid, shift, summary, description, defaults, rotaURL); err != nil {
 			return "", err
 		}
-		event.SetSummary(summary)
-		// Newlines and commas go in as themselves: DESCRIPTION is a TEXT
-		// property, and the library escapes those on the way out.
-		event.SetDescription(shiftDescription(shift, volunteer, roles, rotaURL))
-		if rotaURL != "" {
-			event.SetURL(rotaURL)
-		}
-		addReminders(event)
-		event.SetSequence(shift.AlterationCount)
-		// DTSTAMP must only churn when the shift actually changes; unaltered
-		// shifts fall back to their own start.
-		if shift.LastChanged.IsZero() {
-			event.SetDtStampTime(stamp)
-		} else {
-			event.SetDtStampTime(shift.LastChanged)
-		}
 	}
 
 	// RFC 5545 requires CRLF line endings regardless of platform
@@ -166,6 +156,92 @@ func shiftDescription(shift Shift, volunteer model.Volunteer, roles model.Roles,
 	return strings.Join(lines, "\n")
 }
 
+// newCalendar starts a feed named name, read in the drop-in's own zone and
+// polled as often as every feed the app serves is.
+func newCalendar(name string, defaults model.RotaDefaults) *ics.Calendar {
+	cal := ics.NewCalendar()
+	cal.SetProductId("-//ilford-drop-in//EN")
+	cal.SetCalscale("GREGORIAN")
+	cal.SetMethod(ics.MethodPublish)
+	cal.SetXWRCalName(name)
+	cal.SetXWRTimezone(defaults.Timezone())
+	cal.SetRefreshInterval(calendarRefreshInterval)
+	cal.SetXPublishedTTL(calendarRefreshInterval)
+	return cal
+}
+
+// addShiftEvent adds one shift to a feed as the event uid. Everything a
+// subscriber's client keys on is decided here, so every feed agrees on it: the
+// span from the shift's own times, the reminders, and a SEQUENCE and DTSTAMP
+// that move only when the shift does.
+func addShiftEvent(cal *ics.Calendar, uid string, shift Shift, summary, description string, defaults model.RotaDefaults, rotaURL string) error {
+	event := cal.AddEvent(uid)
+	stamp, err := setEventDates(event, shift, defaults)
+	if err != nil {
+		return err
+	}
+	event.SetSummary(summary)
+	// Newlines and commas go in as themselves: DESCRIPTION is a TEXT
+	// property, and the library escapes those on the way out.
+	event.SetDescription(description)
+	if rotaURL != "" {
+		event.SetURL(rotaURL)
+	}
+	addReminders(event)
+	event.SetSequence(eventSequence(shift))
+	// DTSTAMP must only churn when the shift actually changes; unaltered
+	// shifts fall back to their own start.
+	if shift.LastChanged.IsZero() {
+		event.SetDtStampTime(stamp)
+	} else {
+		event.SetDtStampTime(shift.LastChanged)
+	}
+	return nil
+}
+
+// addCancelledEvent writes the event uid as cancelled: the same UID the
+// volunteer's client holds it under, STATUS:CANCELLED, and no reminders.
+//
+// SEQUENCE has to beat the copy the client has, or it may keep that one. A
+// removal is itself an Alteration and closing a shift is a closure change, so
+// eventSequence already has. The summary says "Cancelled" as well, for the
+// clients that show a cancelled event rather than drop it.
+func addCancelledEvent(cal *ics.Calendar, uid string, shift Shift, defaults model.RotaDefaults, rotaURL string) error {
+	event := cal.AddEvent(uid)
+	stamp, err := setEventDates(event, shift, defaults)
+	if err != nil {
+		return err
+	}
+	event.SetStatus(ics.ObjectStatusCancelled)
+	event.SetSummary("Cancelled: Ilford Drop-In shift")
+
+	lines := []string{"You are no longer on this shift."}
+	if shift.Closed {
+		lines = []string{"The drop-in is closed on this date."}
+	}
+	if rotaURL != "" {
+		lines = append(lines, "", "The whole rota: "+rotaURL)
+		event.SetURL(rotaURL)
+	}
+	event.SetDescription(strings.Join(lines, "\n"))
+
+	event.SetSequence(eventSequence(shift))
+	if shift.LastChanged.IsZero() {
+		event.SetDtStampTime(stamp)
+	} else {
+		event.SetDtStampTime(shift.LastChanged)
+	}
+	return nil
+}
+
+// eventSequence is a shift's event's SEQUENCE: one more for every Alteration to
+// it and every time it has been closed or reopened. Both only ever go up, so a
+// shift reopened after its cancellation went out is sent at a number that beats
+// the cancellation, and the client shows it running again.
+func eventSequence(shift Shift) int {
+	return shift.AlterationCount + shift.ClosureChanges
+}
+
 // addReminders hangs the standard reminders off one event.
 func addReminders(event *ics.VEvent) {
 	for _, reminder := range calendarReminders {
@@ -271,3 +271,59 @@ func TestBuildVolunteerCalendar_ShipsReminders(t *testing.T) {
 	event := out[strings.Index(out, "BEGIN:VEVENT"):strings.Index(out, "END:VEVENT")]
 	assert.Equal(t, 2, strings.Count(event, "BEGIN:VALARM"))
 }
+
+// A shift the volunteer was taken off, or that closed under them, is cancelled
+// under the UID their client already holds, with a SEQUENCE that beats it.
+func TestBuildVolunteerCalendar_Cancellations(t *testing.T) {
+	tests := []struct {
+		name         string
+		closed       bool
+		wantSequence string
+		wantText     string
+	}{
+		{name: "taken off", wantSequence: "SEQUENCE:2", wantText: "You are no longer on this shift."},
+		{name: "closed", closed: true, wantSequence: "SEQUENCE:3", wantText: "The drop-in is closed on this date."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shift := calendarShift(t, "2026-01-12")
+			shift.Closed = tt.closed
+			if tt.closed {
+				shift.ClosureChanges = 1
+			}
+			shift.AlterationCount, shift.LastChanged = 2, time.Date(2026, 1, 2, 10, 30, 0, 0, time.UTC)
+			shift.FormerVolunteerIDs = []string{"alice"}
+
+			out, err := BuildVolunteerCalendar([]Shift{shift}, calendarTestVolunteer(), testRoles, calendarTestDefaults, calendarTestURL)
+			require.NoError(t, err)
+			out = unfolded(out)
+
+			assert.Contains(t, out, "UID:alice-2026-01-12@ilford-drop-in\r\n")
+			assert.Contains(t, out, "STATUS:CANCELLED\r\n")
+			assert.Contains(t, out, "SUMMARY:Cancelled: Ilford Drop-In shift\r\n")
+			assert.Contains(t, out, tt.wantSequence+"\r\n")
+			assert.Contains(t, out, "DTSTAMP:20260102T103000Z")
+			assert.Contains(t, out, tt.wantText)
+			assert.NotContains(t, out, "BEGIN:VALARM", "nobody wants reminding of a shift they are not doing")
+		})
+	}
+}
+
+// A shift closed and then reopened is sent at a SEQUENCE past the
+// cancellation its closing sent, or a client would keep showing it cancelled.
+func TestBuildVolunteerCalendar_ReopenedOutranksItsCancellation(t *testing.T) {
+	shift := calendarShift(t, "2026-01-12")
+	shift.Closed, shift.ClosureChanges = true, 1
+	shift.AlterationCount = 1
+
+	cancelled, err := BuildVolunteerCalendar([]Shift{shift}, calendarTestVolunteer(), testRoles, calendarTestDefaults, calendarTestURL)
+	require.NoError(t, err)
+	assert.Contains(t, unfolded(cancelled), "SEQUENCE:2\r\n")
+
+	shift.Closed, shift.ClosureChanges = false, 2
+	reopened, err := BuildVolunteerCalendar([]Shift{shift}, calendarTestVolunteer(), testRoles, calendarTestDefaults, calendarTestURL)
+	require.NoError(t, err)
+	reopened = unfolded(reopened)
+	assert.NotContains(t, reopened, "STATUS:CANCELLED")
+	assert.Contains(t, reopened, "SEQUENCE:3\r\n")
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const absenceColumns = `id, volunteer_id, from_date, to_date, note, created_by, created_at`
+
+func scanAbsence(row rowScanner) (Absence, error) {
+	var a Absence
+	var from, to time.Time
+	var note, createdBy *string
+	if err := row.Scan(&a.ID, &a.VolunteerID, &from, &to, &note, &createdBy, &a.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return a, err
+		}
+		return a, fmt.Errorf("failed to scan absence: %w", err)
+	}
+	a.From = from.Format("2006-01-02")
+	a.To = to.Format("2006-01-02")
+	a.Note = deref(note)
+	a.CreatedBy = deref(createdBy)
+	return a, nil
+}
+
+// GetAbsencesFrom reads every Absence not over by date ("2006-01-02"): the ones
+// still to come, and the ones under way. An Absence that has ended is history
+// no round, solve or screen has a use for, so there is no read of everything.
+// Ordered by volunteer, then by when each starts.
+func (d *DB) GetAbsencesFrom(ctx context.Context, date string) ([]Absence, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT `+absenceColumns+`
+		FROM volunteer_absence
+		WHERE to_date >= $1::date
+		ORDER BY volunteer_id, from_date, to_date
+	`, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query absences: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Absence
+	for rows.Next() {
+		a, err := scanAbsence(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating absences: %w", err)
+	}
+	return out, nil
+}
+
+// GetAbsenceByID reads one, or nil when there is no such Absence.
+func (d *DB) GetAbsenceByID(ctx context.Context, id string) (*Absence, error) {
+	a, err := scanAbsence(d.pool.QueryRow(ctx, `
+		SELECT `+absenceColumns+` FROM volunteer_absence WHERE id = $1
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// InsertAbsence writes one and returns it as stored, which is where its time
+// comes from. The table's CHECK refuses one that ends before it starts; the
+// caller is expected to have said so in words first.
+//
+// An Absence is an allocator input for every rota not yet allocated, so the
+// write marks them as having moved, in the same transaction, as a Pairing Rule
+// does.
+func (d *DB) InsertAbsence(ctx context.Context, absence Absence) (Absence, error) {
+	var saved Absence
+	err := d.inTx(ctx, func(tx pgx.Tx) error {
+		var err error
+		saved, err = scanAbsence(tx.QueryRow(ctx, `
+			INSERT INTO volunteer_absence (id, volunteer_id, from_date, to_date, note, created_by)
+			VALUES ($1, $2, $3::date, $4::date, NULLIF($5, ''), NULLIF($6, ''))
+			RETURNING `+absenceColumns,
+			absence.ID, absence.VolunteerID, absence.From, absence.To, absence.Note, absence.CreatedBy))
+		if err != nil {
+			return fmt.Errorf("failed to insert absence: %w", err)
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+	return saved, err
+}
+
+// DeleteAbsenceByID removes one, reporting whether a row was actually deleted.
+// Only a delete that removed something marks the rota in flight as having
+// moved.
+func (d *DB) DeleteAbsenceByID(ctx context.Context, id string) (bool, error) {
+	var deleted bool
+	err := d.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM volunteer_absence WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete absence %s: %w", id, err)
+		}
+		deleted = tag.RowsAffected() > 0
+		if !deleted {
+			return nil
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+	return deleted, err
+}
@@ -0,0 +1,70 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/db/dbtest"
+)
+
+func TestAbsenceInsertReadDelete(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	december, err := database.InsertAbsence(ctx, db.Absence{
+		ID: uuid.New().String(), VolunteerID: "alice", From: "2026-12-01", To: "2026-12-31", Note: "abroad", CreatedBy: "admin@example.com",
+	})
+	require.NoError(t, err)
+	assert.False(t, december.CreatedAt.IsZero(), "the time comes back from the table")
+
+	wedding, err := database.InsertAbsence(ctx, db.Absence{
+		ID: uuid.New().String(), VolunteerID: "alice", From: "2026-09-05", To: "2026-09-05",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, wedding.Note)
+	assert.Empty(t, wedding.CreatedBy, "a volunteer's own absence has nobody in created_by")
+
+	got, err := database.GetAbsencesFrom(ctx, "2026-09-01")
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, wedding.ID, got[0].ID, "in the order they start")
+	assert.Equal(t, "2026-12-01", got[1].From)
+	assert.Equal(t, "2026-12-31", got[1].To)
+
+	got, err = database.GetAbsencesFrom(ctx, "2026-09-06")
+	require.NoError(t, err)
+	require.Len(t, got, 1, "an absence that has ended is not read")
+	assert.Equal(t, december.ID, got[0].ID)
+
+	one, err := database.GetAbsenceByID(ctx, december.ID)
+	require.NoError(t, err)
+	require.NotNil(t, one)
+	assert.Equal(t, "abroad", one.Note)
+
+	deleted, err := database.DeleteAbsenceByID(ctx, december.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = database.DeleteAbsenceByID(ctx, december.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted, "a second delete reports that nothing matched")
+
+	one, err = database.GetAbsenceByID(ctx, december.ID)
+	require.NoError(t, err)
+	assert.Nil(t, one)
+}
+
+// The table holds the range the right way round whatever the caller checked.
+func TestAbsenceRefusesARangeThatEndsBeforeItStarts(t *testing.T) {
+	database, _ := dbtest.New(t)
+
+	_, err := database.InsertAbsence(context.Background(), db.Absence{
+		ID: uuid.New().String(), VolunteerID: "alice", From: "2026-12-31", To: "2026-12-01",
+	})
+	assert.Error(t, err)
+}
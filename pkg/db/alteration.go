@@ -33,6 +33,22 @@ func getAlterationsByShiftIDs(ctx context.Context, q querier, shiftIDs []string)
 	return scanAlterations(rows)
 }
 
+// GetAlterationsByCoverID retrieves the alterations one rota change recorded,
+// in the order it recorded them. Every cover has at least one, so none means
+// there is no such cover.
+func (d *DB) GetAlterationsByCoverID(ctx context.Context, coverID string) ([]Alteration, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT id, shift_id, direction, volunteer_id, custom_value, cover_id, set_time, role
+		FROM alteration
+		WHERE cover_id = $1
+		ORDER BY set_time ASC, id
+	`, coverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alterations for cover %s: %w", coverID, err)
+	}
+	return scanAlterations(rows)
+}
+
 func scanAlterations(rows pgx.Rows) ([]Alteration, error) {
 	defer rows.Close()
 
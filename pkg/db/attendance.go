@@ -0,0 +1,65 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// RecordAttendance writes one volunteer's attendance on a Shift, replacing
+// whatever was recorded before: the latest word on who turned up is the one
+// that stands.
+func (d *DB) RecordAttendance(ctx context.Context, a Attendance) error {
+	_, err := d.pool.Exec(ctx, `
+		INSERT INTO attendance (shift_id, volunteer_id, status, recorded_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (shift_id, volunteer_id) DO UPDATE
+		SET status = EXCLUDED.status, recorded_by = EXCLUDED.recorded_by, recorded_at = NOW()
+	`, a.ShiftID, a.VolunteerID, a.Status, a.RecordedBy)
+	if err != nil {
+		return fmt.Errorf("failed to record attendance for %s on shift %s: %w", a.VolunteerID, a.ShiftID, err)
+	}
+	return nil
+}
+
+// ClearAttendance forgets one volunteer's attendance on a Shift, for an answer
+// recorded against the wrong person. Clearing what was never recorded is not
+// an error.
+func (d *DB) ClearAttendance(ctx context.Context, shiftID, volunteerID string) error {
+	_, err := d.pool.Exec(ctx, `
+		DELETE FROM attendance WHERE shift_id = $1 AND volunteer_id = $2
+	`, shiftID, volunteerID)
+	if err != nil {
+		return fmt.Errorf("failed to clear attendance for %s on shift %s: %w", volunteerID, shiftID, err)
+	}
+	return nil
+}
+
+// GetAttendanceByShiftIDs reads everything recorded on the given Shifts.
+func (d *DB) GetAttendanceByShiftIDs(ctx context.Context, shiftIDs []string) ([]Attendance, error) {
+	if len(shiftIDs) == 0 {
+		return nil, nil
+	}
+	rows, err := d.pool.Query(ctx, `
+		SELECT shift_id, volunteer_id, status, recorded_by, recorded_at
+		FROM attendance
+		WHERE shift_id = ANY($1)
+		ORDER BY shift_id, volunteer_id
+	`, shiftIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query attendance: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Attendance
+	for rows.Next() {
+		var a Attendance
+		if err := rows.Scan(&a.ShiftID, &a.VolunteerID, &a.Status, &a.RecordedBy, &a.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan attendance: %w", err)
+		}
+		out = append(out, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating attendance: %w", err)
+	}
+	return out, nil
+}
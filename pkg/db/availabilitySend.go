@@ -0,0 +1,240 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// availabilitySendColumns reads a send with its outcomes counted, so a list of
+// sends costs one query rather than one per send.
+const availabilitySendColumns = `
+	s.id, s.rota_id, s.admin_email, s.mode, s.deadline, s.volunteer_id, s.cover_id, s.cover_request_id, s.swap_request_id,
+	s.started_at, s.total, s.last_progress_at, s.finished_at, s.error,
+	(SELECT COUNT(*) FROM availability_send_outcome o WHERE o.send_id = s.id AND o.error IS NULL),
+	(SELECT COUNT(*) FROM availability_send_outcome o WHERE o.send_id = s.id AND o.error IS NOT NULL)`
+
+func scanAvailabilitySend(row rowScanner) (AvailabilitySend, error) {
+	var send AvailabilitySend
+	var volunteerID, coverID, coverRequestID, swapRequestID, sendErr *string
+	var total *int
+	if err := row.Scan(
+		&send.ID, &send.RotaID, &send.AdminEmail, &send.Mode, &send.Deadline, &volunteerID, &coverID, &coverRequestID, &swapRequestID,
+		&send.StartedAt, &total, &send.LastProgressAt, &send.FinishedAt, &sendErr,
+		&send.Sent, &send.Failed,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return send, err
+		}
+		return send, fmt.Errorf("failed to scan availability send: %w", err)
+	}
+	send.VolunteerID = deref(volunteerID)
+	send.CoverID = deref(coverID)
+	send.CoverRequestID = deref(coverRequestID)
+	send.SwapRequestID = deref(swapRequestID)
+	send.Error = deref(sendErr)
+	if total != nil {
+		send.Total = *total
+	}
+	return send, nil
+}
+
+// InsertAvailabilitySend records a send as started. It is written before the
+// first email rather than after the recipients are chosen, so the send an admin
+// is redirected to watch exists from the moment they are redirected.
+func (d *DB) InsertAvailabilitySend(ctx context.Context, send AvailabilitySend) error {
+	_, err := d.pool.Exec(ctx, `
+		INSERT INTO availability_send (id, rota_id, admin_email, mode, deadline, volunteer_id, cover_id, cover_request_id, swap_request_id)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid, NULLIF($8, '')::uuid, NULLIF($9, '')::uuid)
+	`, send.ID, send.RotaID, send.AdminEmail, send.Mode, send.Deadline, send.VolunteerID, send.CoverID, send.CoverRequestID, send.SwapRequestID)
+	if err != nil {
+		return fmt.Errorf("failed to insert availability send: %w", err)
+	}
+	return nil
+}
+
+// SetAvailabilitySendTotal records how many volunteers the send selected. It
+// counts as progress, because choosing the recipients is the send doing
+// something.
+func (d *DB) SetAvailabilitySendTotal(ctx context.Context, id string, total int) error {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE availability_send
+		SET total = $2, last_progress_at = NOW()
+		WHERE id = $1
+	`, id, total)
+	if err != nil {
+		return fmt.Errorf("failed to set total on availability send %s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no availability send with id %s", id)
+	}
+	return nil
+}
+
+// RecordAvailabilitySendOutcome writes what happened to one volunteer's email
+// and moves the send's heartbeat, in one transaction, so a send can never look
+// stalled while it is still recording outcomes.
+//
+// A volunteer recorded twice keeps the later outcome. A resumed send skips the
+// volunteers it already holds, so this is only ever a send telling the truth
+// about a retry.
+func (d *DB) RecordAvailabilitySendOutcome(ctx context.Context, outcome AvailabilitySendOutcome) error {
+	return d.inTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO availability_send_outcome (send_id, volunteer_id, volunteer_name, email, error)
+			VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''))
+			ON CONFLICT (send_id, volunteer_id) DO UPDATE
+			SET volunteer_name = EXCLUDED.volunteer_name,
+			    email = EXCLUDED.email,
+			    error = EXCLUDED.error,
+			    recorded_at = NOW()
+		`, outcome.SendID, outcome.VolunteerID, outcome.VolunteerName, outcome.Email, outcome.Error)
+		if err != nil {
+			return fmt.Errorf("failed to record availability send outcome: %w", err)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE availability_send SET last_progress_at = NOW() WHERE id = $1
+		`, outcome.SendID)
+		if err != nil {
+			return fmt.Errorf("failed to move availability send %s on: %w", outcome.SendID, err)
+		}
+		return nil
+	})
+}
+
+// FinishAvailabilitySend marks a send as done, with the reason it stopped short
+// when errText is not empty.
+func (d *DB) FinishAvailabilitySend(ctx context.Context, id string, errText string) error {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE availability_send
+		SET finished_at = NOW(), last_progress_at = NOW(), error = NULLIF($2, '')
+		WHERE id = $1
+	`, id, errText)
+	if err != nil {
+		return fmt.Errorf("failed to finish availability send %s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no availability send with id %s", id)
+	}
+	return nil
+}
+
+// ClaimAvailabilitySend takes over an interrupted send so it can be resumed,
+// reporting whether it did. Only an unfinished send that has not moved since
+// staleBefore can be claimed, and claiming moves its heartbeat, so of two
+// admins' tabs resuming the same send at once exactly one wins — the check and
+// the claim are one statement.
+func (d *DB) ClaimAvailabilitySend(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE availability_send
+		SET last_progress_at = NOW()
+		WHERE id = $1 AND finished_at IS NULL AND last_progress_at < $2
+	`, id, staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim availability send %s: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetAvailabilitySend reads one send, or nil when there is none with that id.
+func (d *DB) GetAvailabilitySend(ctx context.Context, id string) (*AvailabilitySend, error) {
+	row := d.pool.QueryRow(ctx, `
+		SELECT `+availabilitySendColumns+`
+		FROM availability_send s
+		WHERE s.id = $1
+	`, id)
+
+	send, err := scanAvailabilitySend(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &send, nil
+}
+
+// GetAvailabilitySendsByRotaID reads a round's sends, newest first: the history
+// an admin browses is most often asked "what did the last send do".
+func (d *DB) GetAvailabilitySendsByRotaID(ctx context.Context, rotaID string) ([]AvailabilitySend, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT `+availabilitySendColumns+`
+		FROM availability_send s
+		WHERE s.rota_id = $1
+		ORDER BY s.started_at DESC, s.id
+	`, rotaID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query availability sends: %w", err)
+	}
+	return scanAvailabilitySends(rows)
+}
+
+// GetAvailabilitySendsByCoverID reads the sends that told volunteers about one
+// rota change, newest first.
+func (d *DB) GetAvailabilitySendsByCoverID(ctx context.Context, coverID string) ([]AvailabilitySend, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT `+availabilitySendColumns+`
+		FROM availability_send s
+		WHERE s.cover_id = $1
+		ORDER BY s.started_at DESC, s.id
+	`, coverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query availability sends for cover %s: %w", coverID, err)
+	}
+	return scanAvailabilitySends(rows)
+}
+
+func scanAvailabilitySends(rows pgx.Rows) ([]AvailabilitySend, error) {
+	defer rows.Close()
+
+	var sends []AvailabilitySend
+	for rows.Next() {
+		send, err := scanAvailabilitySend(rows)
+		if err != nil {
+			return nil, err
+		}
+		sends = append(sends, send)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating availability sends: %w", err)
+	}
+
+	return sends, nil
+}
+
+// GetAvailabilitySendOutcomes reads every outcome a send has recorded, in the
+// order they happened.
+func (d *DB) GetAvailabilitySendOutcomes(ctx context.Context, sendID string) ([]AvailabilitySendOutcome, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT send_id, volunteer_id, volunteer_name, email, error, recorded_at
+		FROM availability_send_outcome
+		WHERE send_id = $1
+		ORDER BY recorded_at, volunteer_id
+	`, sendID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query availability send outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var outcomes []AvailabilitySendOutcome
+	for rows.Next() {
+		var o AvailabilitySendOutcome
+		var email, outcomeErr *string
+		if err := rows.Scan(&o.SendID, &o.VolunteerID, &o.VolunteerName, &email, &outcomeErr, &o.RecordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan availability send outcome: %w", err)
+		}
+		o.Email = deref(email)
+		o.Error = deref(outcomeErr)
+		outcomes = append(outcomes, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating availability send outcomes: %w", err)
+	}
+
+	return outcomes, nil
+}
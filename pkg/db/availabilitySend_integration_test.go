@@ -0,0 +1,192 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/db/dbtest"
+)
+
+func TestAvailabilitySendRecordsOutcomesAndFinishes(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	rotaID, _ := roundFixture(t, database)
+
+	send := db.AvailabilitySend{
+		ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "admin@example.com", Mode: "round", Deadline: "Friday",
+	}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, send))
+	require.NoError(t, database.SetAvailabilitySendTotal(ctx, send.ID, 2))
+	require.NoError(t, database.RecordAvailabilitySendOutcome(ctx, db.AvailabilitySendOutcome{
+		SendID: send.ID, VolunteerID: "alice", VolunteerName: "Alice A", Email: "alice@example.com",
+	}))
+	require.NoError(t, database.RecordAvailabilitySendOutcome(ctx, db.AvailabilitySendOutcome{
+		SendID: send.ID, VolunteerID: "bob", VolunteerName: "Bob B", Error: "no email address on the roster",
+	}))
+
+	got, err := database.GetAvailabilitySend(ctx, send.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "admin@example.com", got.AdminEmail)
+	assert.Empty(t, got.VolunteerID, "a round send names no volunteer")
+	assert.Equal(t, 2, got.Total)
+	assert.Equal(t, 1, got.Sent)
+	assert.Equal(t, 1, got.Failed)
+	assert.Nil(t, got.FinishedAt, "not finished until it says so")
+
+	outcomes, err := database.GetAvailabilitySendOutcomes(ctx, send.ID)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 2)
+	assert.Equal(t, "alice@example.com", outcomes[0].Email)
+	assert.Empty(t, outcomes[0].Error)
+	assert.Empty(t, outcomes[1].Email)
+	assert.Equal(t, "no email address on the roster", outcomes[1].Error)
+
+	require.NoError(t, database.FinishAvailabilitySend(ctx, send.ID, ""))
+	got, err = database.GetAvailabilitySend(ctx, send.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, got.FinishedAt)
+	assert.Empty(t, got.Error)
+
+	missing, err := database.GetAvailabilitySend(ctx, uuid.New().String())
+	require.NoError(t, err)
+	assert.Nil(t, missing, "an unknown id is a miss, not an error")
+}
+
+// A resumed send retrying a volunteer keeps the newer outcome rather than
+// failing on the primary key.
+func TestAvailabilitySendOutcomeKeepsTheLatest(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	rotaID, _ := roundFixture(t, database)
+
+	send := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "a@example.com", Mode: "round", Deadline: "Friday"}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, send))
+	require.NoError(t, database.RecordAvailabilitySendOutcome(ctx, db.AvailabilitySendOutcome{
+		SendID: send.ID, VolunteerID: "alice", VolunteerName: "Alice A", Email: "alice@example.com", Error: "rate limited",
+	}))
+	require.NoError(t, database.RecordAvailabilitySendOutcome(ctx, db.AvailabilitySendOutcome{
+		SendID: send.ID, VolunteerID: "alice", VolunteerName: "Alice A", Email: "alice@example.com",
+	}))
+
+	outcomes, err := database.GetAvailabilitySendOutcomes(ctx, send.ID)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.Empty(t, outcomes[0].Error)
+}
+
+// Only a send that is unfinished and has stopped moving can be claimed, and
+// the claim itself moves it, so a second claim at the same moment loses.
+func TestClaimAvailabilitySend(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	rotaID, _ := roundFixture(t, database)
+
+	send := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "a@example.com", Mode: "round", Deadline: "Friday"}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, send))
+
+	claimed, err := database.ClaimAvailabilitySend(ctx, send.ID, time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.False(t, claimed, "a send that moved a moment ago is still running")
+
+	claimed, err = database.ClaimAvailabilitySend(ctx, send.ID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = database.ClaimAvailabilitySend(ctx, send.ID, time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.False(t, claimed, "the claim moved the heartbeat, so a second claimant loses")
+
+	require.NoError(t, database.FinishAvailabilitySend(ctx, send.ID, "rota allocated"))
+	claimed, err = database.ClaimAvailabilitySend(ctx, send.ID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, claimed, "a finished send is never resumed")
+}
+
+func TestGetAvailabilitySendsByRotaIDNewestFirst(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	rotaID, _ := roundFixture(t, database)
+	otherRota, _ := roundFixture(t, database)
+
+	first := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "a@example.com", Mode: "round", Deadline: "Friday"}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, first))
+	second := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "b@example.com", Mode: "resend", Deadline: "Friday", VolunteerID: "alice"}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, second))
+	require.NoError(t, database.InsertAvailabilitySend(ctx, db.AvailabilitySend{
+		ID: uuid.New().String(), RotaID: otherRota, AdminEmail: "a@example.com", Mode: "round", Deadline: "Friday",
+	}))
+
+	sends, err := database.GetAvailabilitySendsByRotaID(ctx, rotaID)
+	require.NoError(t, err)
+	require.Len(t, sends, 2, "another rota's sends are not this round's history")
+	assert.Equal(t, second.ID, sends[0].ID)
+	assert.Equal(t, "alice", sends[0].VolunteerID)
+	assert.Equal(t, first.ID, sends[1].ID)
+}
+
+// TestAvailabilitySendTakesAnAllocationSend: a send telling volunteers their
+// shifts is recorded in the same table, with no deadline to quote.
+func TestAvailabilitySendTakesAnAllocationSend(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	rotaID, _ := roundFixture(t, database)
+
+	send := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "admin@example.com", Mode: "allocation"}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, send))
+
+	got, err := database.GetAvailabilitySend(ctx, send.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "allocation", got.Mode)
+	assert.Empty(t, got.Deadline)
+
+	bogus := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "admin@example.com", Mode: "newsletter"}
+	assert.Error(t, database.InsertAvailabilitySend(ctx, bogus), "a mode nothing sends is refused")
+}
+
+// TestAvailabilitySendIsReadBackByItsCover: a send telling volunteers about a
+// rota change names the change, and the change's sends and alterations are
+// both read back by it. A cover send without a cover is refused.
+func TestAvailabilitySendIsReadBackByItsCover(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	rotaID, shiftIDs := roundFixture(t, database)
+
+	coverID := uuid.New().String()
+	require.NoError(t, database.WithRotaLock(ctx, []string{rotaID}, func(store db.RotaChangeStore) error {
+		return store.InsertCoverAndAlterations(ctx,
+			&db.Cover{ID: coverID, Reason: "away", UserEmail: "admin@example.com"},
+			[]db.Alteration{
+				{ID: uuid.New().String(), ShiftID: shiftIDs[0], Direction: "remove", VolunteerID: "alice", CoverID: coverID},
+				{ID: uuid.New().String(), ShiftID: shiftIDs[0], Direction: "add", VolunteerID: "bob", Role: "Service volunteer", CoverID: coverID},
+			})
+	}))
+
+	alterations, err := database.GetAlterationsByCoverID(ctx, coverID)
+	require.NoError(t, err)
+	require.Len(t, alterations, 2)
+	none, err := database.GetAlterationsByCoverID(ctx, uuid.New().String())
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	send := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "admin@example.com", Mode: "cover", CoverID: coverID}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, send))
+	round := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "admin@example.com", Mode: "round", Deadline: "Friday"}
+	require.NoError(t, database.InsertAvailabilitySend(ctx, round))
+
+	sends, err := database.GetAvailabilitySendsByCoverID(ctx, coverID)
+	require.NoError(t, err)
+	require.Len(t, sends, 1)
+	assert.Equal(t, send.ID, sends[0].ID)
+	assert.Equal(t, coverID, sends[0].CoverID)
+
+	orphan := db.AvailabilitySend{ID: uuid.New().String(), RotaID: rotaID, AdminEmail: "admin@example.com", Mode: "cover"}
+	assert.Error(t, database.InsertAvailabilitySend(ctx, orphan), "a cover send must say which change it is about")
+}
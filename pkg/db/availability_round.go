@@ -175,13 +175,13 @@ func (d *DB) GetLatestAvailability(ctx context.Context, requestIDs []string, cut
 	rows, err := d.pool.Query(ctx, `
 		WITH latest AS (
 			SELECT DISTINCT ON (availability_request_id)
-				id, availability_request_id, submitted_at
+				id, availability_request_id, submitted_at, preferred_shift_count
 			FROM availability_response
 			WHERE availability_request_id = ANY($1)
 			  AND submitted_at <= COALESCE($2, 'infinity'::timestamptz)
 			ORDER BY availability_request_id, submitted_at DESC, id DESC
 		)
-		SELECT l.availability_request_id, l.id, l.submitted_at, sa.shift_id, sa.answer
+		SELECT l.availability_request_id, l.id, l.submitted_at, l.preferred_shift_count, sa.shift_id, sa.answer
 		FROM latest l
 		LEFT JOIN shift_availability sa ON sa.response_id = l.id
 		ORDER BY l.availability_request_id, sa.shift_id
@@ -194,9 +194,10 @@ func (d *DB) GetLatestAvailability(ctx context.Context, requestIDs []string, cut
 	for rows.Next() {
 		var requestID, responseID string
 		var submittedAt time.Time
+		var preferredShiftCount *int
 		// Null when the generation ticked nothing, which the LEFT JOIN preserves.
 		var shiftID, answer *string
-		if err := rows.Scan(&requestID, &responseID, &submittedAt, &shiftID, &answer); err != nil {
+		if err := rows.Scan(&requestID, &responseID, &submittedAt, &preferredShiftCount, &shiftID, &answer); err != nil {
 			return nil, fmt.Errorf("failed to scan availability generation: %w", err)
 		}
 
@@ -207,6 +208,9 @@ func (d *DB) GetLatestAvailability(ctx context.Context, requestIDs []string, cut
 				ResponseID:  responseID,
 				SubmittedAt: submittedAt,
 			}
+			if preferredShiftCount != nil {
+				generation.PreferredShiftCount = *preferredShiftCount
+			}
 		}
 		if shiftID != nil && answer != nil {
 			generation.Answers = append(generation.Answers, ShiftAnswer{ShiftID: *shiftID, Answer: *answer})
@@ -277,7 +281,10 @@ func (d *DB) InsertBackfilledAvailabilityResponse(ctx context.Context, requestID
 // row because they said no" from "no row because the insert failed", so a
 // half-written generation would silently record unavailability (ADR 0004).
 // Callers pass the full set of positives every time, never a delta.
-func (d *DB) InsertAvailabilityResponse(ctx context.Context, requestID string, answers []ShiftAnswer) (*AvailabilityGeneration, error) {
+//
+// preferredShiftCount is part of the generation like the answers are, so it is
+// written with them and replaced with them; 0 stores as NULL, no preference.
+func (d *DB) InsertAvailabilityResponse(ctx context.Context, requestID string, answers []ShiftAnswer, preferredShiftCount int) (*AvailabilityGeneration, error) {
 	tx, err := d.pool.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -287,10 +294,10 @@ func (d *DB) InsertAvailabilityResponse(ctx context.Context, requestID string, a
 	responseID := uuid.New().String()
 	var submittedAt time.Time
 	err = tx.QueryRow(ctx, `
-		INSERT INTO availability_response (id, availability_request_id)
-		VALUES ($1, $2)
+		INSERT INTO availability_response (id, availability_request_id, preferred_shift_count)
+		VALUES ($1, $2, NULLIF($3, 0))
 		RETURNING submitted_at
-	`, responseID, requestID).Scan(&submittedAt)
+	`, responseID, requestID, preferredShiftCount).Scan(&submittedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert availability response: %w", err)
 	}
@@ -317,10 +324,11 @@ func (d *DB) InsertAvailabilityResponse(ctx context.Context, requestID string, a
 	}
 
 	return &AvailabilityGeneration{
-		RequestID:   requestID,
-		ResponseID:  responseID,
-		SubmittedAt: submittedAt,
-		Answers:     answers,
+		RequestID:           requestID,
+		ResponseID:          responseID,
+		SubmittedAt:         submittedAt,
+		Answers:             answers,
+		PreferredShiftCount: preferredShiftCount,
 	}, nil
 }
 
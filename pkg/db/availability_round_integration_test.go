@@ -125,12 +125,12 @@ func TestLatestAvailabilityTakesTheNewestGeneration(t *testing.T) {
 	_, err = database.InsertAvailabilityResponse(ctx, req.ID, []db.ShiftAnswer{
 		{ShiftID: shiftIDs[0], Answer: db.AnswerYes},
 		{ShiftID: shiftIDs[1], Answer: db.AnswerYes},
-	})
+	}, 1)
 	require.NoError(t, err)
 
 	second, err := database.InsertAvailabilityResponse(ctx, req.ID, []db.ShiftAnswer{
 		{ShiftID: shiftIDs[2], Answer: db.AnswerYes},
-	})
+	}, 0)
 	require.NoError(t, err)
 
 	latest, err := database.GetLatestAvailability(ctx, []string{req.ID}, nil)
@@ -142,6 +142,38 @@ func TestLatestAvailabilityTakesTheNewestGeneration(t *testing.T) {
 	require.Len(t, generation.Answers, 1, "the latest generation replaces the earlier one wholesale")
 	assert.Equal(t, shiftIDs[2], generation.Answers[0].ShiftID)
 	assert.Equal(t, db.AnswerYes, generation.Answers[0].Answer)
+	assert.Zero(t, generation.PreferredShiftCount, "a preferred count is part of the generation, so it is replaced with it")
+}
+
+// TestLatestAvailabilityReadsThePreferredShiftCount round-trips the "how many
+// would you like" number, and its absence: no preference is stored as NULL and
+// read back as 0.
+func TestLatestAvailabilityReadsThePreferredShiftCount(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	rotaID, shiftIDs := roundFixture(t, database)
+
+	choosy := request(rotaID, "alice", "token-alice")
+	easy := request(rotaID, "bob", "token-bob")
+	_, err := database.MintAvailabilityRequests(ctx, []db.AvailabilityRequest{choosy, easy})
+	require.NoError(t, err)
+
+	all := []db.ShiftAnswer{
+		{ShiftID: shiftIDs[0], Answer: db.AnswerYes},
+		{ShiftID: shiftIDs[1], Answer: db.AnswerYes},
+		{ShiftID: shiftIDs[2], Answer: db.AnswerYes},
+	}
+	inserted, err := database.InsertAvailabilityResponse(ctx, choosy.ID, all, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, inserted.PreferredShiftCount)
+	_, err = database.InsertAvailabilityResponse(ctx, easy.ID, all, 0)
+	require.NoError(t, err)
+
+	latest, err := database.GetLatestAvailability(ctx, []string{choosy.ID, easy.ID}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, latest[choosy.ID].PreferredShiftCount)
+	assert.Len(t, latest[choosy.ID].Answers, 3, "the count does not narrow the answers it came with")
+	assert.Zero(t, latest[easy.ID].PreferredShiftCount)
 }
 
 // TestLatestAvailabilityRecordsAnEmptyGeneration covers "available for nothing",
@@ -157,7 +189,7 @@ func TestLatestAvailabilityRecordsAnEmptyGeneration(t *testing.T) {
 	_, err := database.MintAvailabilityRequests(ctx, []db.AvailabilityRequest{replied, silent})
 	require.NoError(t, err)
 
-	_, err = database.InsertAvailabilityResponse(ctx, replied.ID, nil)
+	_, err = database.InsertAvailabilityResponse(ctx, replied.ID, nil, 0)
 	require.NoError(t, err)
 
 	latest, err := database.GetLatestAvailability(ctx, []string{replied.ID, silent.ID}, nil)
@@ -186,7 +218,7 @@ func TestLatestAvailabilityRespectsTheCutoff(t *testing.T) {
 
 	inTime, err := database.InsertAvailabilityResponse(ctx, req.ID, []db.ShiftAnswer{
 		{ShiftID: shiftIDs[0], Answer: db.AnswerYes},
-	})
+	}, 0)
 	require.NoError(t, err)
 
 	cutoff := inTime.SubmittedAt.Add(time.Millisecond)
@@ -194,7 +226,7 @@ func TestLatestAvailabilityRespectsTheCutoff(t *testing.T) {
 	// A later generation exists, but allocation has already happened.
 	_, err = database.InsertAvailabilityResponse(ctx, req.ID, []db.ShiftAnswer{
 		{ShiftID: shiftIDs[1], Answer: db.AnswerYes},
-	})
+	}, 0)
 	require.NoError(t, err)
 
 	latest, err := database.GetLatestAvailability(ctx, []string{req.ID}, &cutoff)
@@ -228,7 +260,7 @@ func TestInsertAvailabilityResponseIsAtomic(t *testing.T) {
 	_, err = database.InsertAvailabilityResponse(ctx, req.ID, []db.ShiftAnswer{
 		{ShiftID: shiftIDs[0], Answer: db.AnswerYes},
 		{ShiftID: uuid.New().String(), Answer: db.AnswerYes},
-	})
+	}, 0)
 	require.Error(t, err)
 
 	latest, err := database.GetLatestAvailability(ctx, []string{req.ID}, nil)
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const calendarTokenColumns = `volunteer_id, token, created_by, created_at`
+
+func scanCalendarToken(row rowScanner) (CalendarToken, error) {
+	var t CalendarToken
+	if err := row.Scan(&t.VolunteerID, &t.Token, &t.CreatedBy, &t.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return t, err
+		}
+		return t, fmt.Errorf("failed to scan calendar token: %w", err)
+	}
+	return t, nil
+}
+
+// GetCalendarTokenByToken reads the token a feed was asked for by, or nil when
+// it is not one — never minted, or replaced since.
+func (d *DB) GetCalendarTokenByToken(ctx context.Context, token string) (*CalendarToken, error) {
+	t, err := scanCalendarToken(d.pool.QueryRow(ctx, `
+		SELECT `+calendarTokenColumns+` FROM calendar_token WHERE token = $1
+	`, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetCalendarTokens reads every volunteer's token, by volunteer.
+func (d *DB) GetCalendarTokens(ctx context.Context) ([]CalendarToken, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT `+calendarTokenColumns+` FROM calendar_token ORDER BY volunteer_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query calendar tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var out []CalendarToken
+	for rows.Next() {
+		t, err := scanCalendarToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating calendar tokens: %w", err)
+	}
+	return out, nil
+}
+
+// SaveCalendarToken gives a volunteer the token, replacing the one they had:
+// the old feed URL stops answering the moment this commits. It returns the row
+// as stored, which is where its time comes from.
+func (d *DB) SaveCalendarToken(ctx context.Context, t CalendarToken) (CalendarToken, error) {
+	saved, err := scanCalendarToken(d.pool.QueryRow(ctx, `
+		INSERT INTO calendar_token (volunteer_id, token, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (volunteer_id) DO UPDATE
+		SET token = EXCLUDED.token, created_by = EXCLUDED.created_by, created_at = NOW()
+		RETURNING `+calendarTokenColumns, t.VolunteerID, t.Token, t.CreatedBy))
+	if err != nil {
+		return CalendarToken{}, fmt.Errorf("failed to save calendar token for %s: %w", t.VolunteerID, err)
+	}
+	return saved, nil
+}
+
+// InsertCalendarTokenIfAbsent gives a volunteer the token only if they have
+// none, reporting whether it did. A volunteer already subscribed keeps the URL
+// they subscribed to.
+func (d *DB) InsertCalendarTokenIfAbsent(ctx context.Context, t CalendarToken) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		INSERT INTO calendar_token (volunteer_id, token, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (volunteer_id) DO NOTHING
+	`, t.VolunteerID, t.Token, t.CreatedBy)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert calendar token for %s: %w", t.VolunteerID, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeleteCalendarToken revokes a volunteer's token, reporting whether they had
+// one to revoke.
+func (d *DB) DeleteCalendarToken(ctx context.Context, volunteerID string) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		DELETE FROM calendar_token WHERE volunteer_id = $1
+	`, volunteerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete calendar token for %s: %w", volunteerID, err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
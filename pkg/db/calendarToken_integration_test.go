@@ -0,0 +1,64 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/db/dbtest"
+)
+
+func TestCalendarTokenSaveReadDelete(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	first, err := database.SaveCalendarToken(ctx, db.CalendarToken{VolunteerID: "alice", Token: "tok-1", CreatedBy: "admin@example.com"})
+	require.NoError(t, err)
+	assert.False(t, first.CreatedAt.IsZero())
+
+	got, err := database.GetCalendarTokenByToken(ctx, "tok-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "alice", got.VolunteerID)
+
+	// Saving again replaces: the old token stops answering.
+	_, err = database.SaveCalendarToken(ctx, db.CalendarToken{VolunteerID: "alice", Token: "tok-2", CreatedBy: "admin@example.com"})
+	require.NoError(t, err)
+	got, err = database.GetCalendarTokenByToken(ctx, "tok-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	tokens, err := database.GetCalendarTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "tok-2", tokens[0].Token)
+
+	deleted, err := database.DeleteCalendarToken(ctx, "alice")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = database.DeleteCalendarToken(ctx, "alice")
+	require.NoError(t, err)
+	assert.False(t, deleted, "a second revoke reports that nothing matched")
+}
+
+// Minting the missing tokens leaves a volunteer who already has one with the
+// URL they subscribed to.
+func TestInsertCalendarTokenIfAbsent(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	inserted, err := database.InsertCalendarTokenIfAbsent(ctx, db.CalendarToken{VolunteerID: "alice", Token: "tok-1", CreatedBy: "admin@example.com"})
+	require.NoError(t, err)
+	assert.True(t, inserted)
+
+	inserted, err = database.InsertCalendarTokenIfAbsent(ctx, db.CalendarToken{VolunteerID: "alice", Token: "tok-2", CreatedBy: "admin@example.com"})
+	require.NoError(t, err)
+	assert.False(t, inserted)
+
+	got, err := database.GetCalendarTokenByToken(ctx, "tok-1")
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+}
@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDuplicateCoverRequest reports a second open request for the same place:
+// the same Shift, Role and volunteer dropping out. Named for the reason
+// ErrDuplicateSwapRequest is.
+var ErrDuplicateCoverRequest = errors.New("cover is already being asked for that place")
+
+// ErrCoverRequestNotOpen reports a request filled after it stopped being open:
+// another admin filled or cancelled it between the read and the change.
+var ErrCoverRequestNotOpen = errors.New("that cover request is no longer open")
+
+func isDuplicateCoverRequest(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) &&
+		pgErr.Code == uniqueViolation &&
+		pgErr.ConstraintName == "idx_cover_request_open"
+}
+
+const coverRequestColumns = `
+	id, shift_id, role, replacing, reason, created_by, created_at, expires_at,
+	status, closed_by, closed_at, cover_id`
+
+func scanCoverRequest(row rowScanner) (CoverRequest, error) {
+	var req CoverRequest
+	var replacing, closedBy, coverID *string
+	if err := row.Scan(
+		&req.ID, &req.ShiftID, &req.Role, &replacing, &req.Reason, &req.CreatedBy, &req.CreatedAt, &req.ExpiresAt,
+		&req.Status, &closedBy, &req.ClosedAt, &coverID,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return req, err
+		}
+		return req, fmt.Errorf("failed to scan cover request: %w", err)
+	}
+	req.Replacing = deref(replacing)
+	req.ClosedBy = deref(closedBy)
+	req.CoverID = deref(coverID)
+	return req, nil
+}
+
+// InsertCoverRequest records a cover request with a link for every volunteer
+// it asks, in one transaction: a request nobody can answer, or links to a
+// request that was never recorded, are both worse than nothing. A second open
+// request for the same place is reported as ErrDuplicateCoverRequest.
+func (d *DB) InsertCoverRequest(ctx context.Context, req CoverRequest, tokens []CoverRequestToken) error {
+	return d.inTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO cover_request (id, shift_id, role, replacing, reason, created_by, expires_at, status)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)
+		`, req.ID, req.ShiftID, req.Role, req.Replacing, req.Reason, req.CreatedBy, req.ExpiresAt, req.Status)
+		if err != nil {
+			if isDuplicateCoverRequest(err) {
+				return ErrDuplicateCoverRequest
+			}
+			return fmt.Errorf("failed to insert cover request: %w", err)
+		}
+
+		for _, t := range tokens {
+			_, err := tx.Exec(ctx, `
+				INSERT INTO cover_request_token (token, cover_request_id, volunteer_id)
+				VALUES ($1, $2, $3)
+			`, t.Token, req.ID, t.VolunteerID)
+			if err != nil {
+				return fmt.Errorf("failed to insert cover request token for %s: %w", t.VolunteerID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetCoverRequest reads one, or nil when there is no such request.
+func (d *DB) GetCoverRequest(ctx context.Context, id string) (*CoverRequest, error) {
+	req, err := scanCoverRequest(d.pool.QueryRow(ctx, `
+		SELECT `+coverRequestColumns+` FROM cover_request WHERE id = $1
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// GetCoverRequestsByStatus reads every request in one of the given states,
+// oldest first.
+func (d *DB) GetCoverRequestsByStatus(ctx context.Context, statuses []string) ([]CoverRequest, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT `+coverRequestColumns+` FROM cover_request
+		WHERE status = ANY($1)
+		ORDER BY created_at, id
+	`, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cover requests: %w", err)
+	}
+	defer rows.Close()
+
+	var out []CoverRequest
+	for rows.Next() {
+		req, err := scanCoverRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cover requests: %w", err)
+	}
+	return out, nil
+}
+
+const coverRequestTokenColumns = `
+	token, cover_request_id, volunteer_id, answer, answered_at, sent_at`
+
+func scanCoverRequestToken(row rowScanner) (CoverRequestToken, error) {
+	var t CoverRequestToken
+	var answer *string
+	if err := row.Scan(&t.Token, &t.CoverRequestID, &t.VolunteerID, &answer, &t.AnsweredAt, &t.SentAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return t, err
+		}
+		return t, fmt.Errorf("failed to scan cover request token: %w", err)
+	}
+	t.Answer = deref(answer)
+	return t, nil
+}
+
+// GetCoverRequestToken reads one volunteer's link, or nil when the token is
+// not one.
+func (d *DB) GetCoverRequestToken(ctx context.Context, token string) (*CoverRequestToken, error) {
+	t, err := scanCoverRequestToken(d.pool.QueryRow(ctx, `
+		SELECT `+coverRequestTokenColumns+` FROM cover_request_token WHERE token = $1
+	`, token))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetCoverRequestTokens reads every link a request handed out, earliest
+// answer first and the unanswered after, so the first yes is the first row.
+func (d *DB) GetCoverRequestTokens(ctx context.Context, coverRequestID string) ([]CoverRequestToken, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT `+coverRequestTokenColumns+` FROM cover_request_token
+		WHERE cover_request_id = $1
+		ORDER BY answered_at NULLS LAST, volunteer_id
+	`, coverRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tokens for cover request %s: %w", coverRequestID, err)
+	}
+	defer rows.Close()
+
+	var out []CoverRequestToken
+	for rows.Next() {
+		t, err := scanCoverRequestToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cover request tokens: %w", err)
+	}
+	return out, nil
+}
+
+// AnswerCoverRequest records a volunteer's answer on their link. A changed
+// mind overwrites the first answer and moves its time, so a yes given late
+// queues behind the yeses given early.
+func (d *DB) AnswerCoverRequest(ctx context.Context, token, answer string) error {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE cover_request_token
+		SET answer = $2, answered_at = NOW()
+		WHERE token = $1
+	`, token, answer)
+	if err != nil {
+		return fmt.Errorf("failed to record cover request answer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no cover request token %s", token)
+	}
+	return nil
+}
+
+// MarkCoverRequestTokenSent stamps sent_at on one link, one row at a time for
+// the reason MarkAvailabilityRequestSent is.
+func (d *DB) MarkCoverRequestTokenSent(ctx context.Context, token string) error {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE cover_request_token
+		SET sent_at = NOW()
+		WHERE token = $1
+	`, token)
+	if err != nil {
+		return fmt.Errorf("failed to mark cover request token as sent: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("no cover request token %s", token)
+	}
+	return nil
+}
+
+// CancelCoverRequest records an admin no longer asking, reporting whether
+// there was an open request to cancel.
+func (d *DB) CancelCoverRequest(ctx context.Context, id, adminEmail string) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE cover_request
+		SET status = 'cancelled', closed_by = $2, closed_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`, id, adminEmail)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel cover request %s: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// fillCoverRequest marks an open request filled by the Cover that put
+// somebody on. Reachable only through WithRotaLock, as completeSwapRequest is
+// and for the same reason: a request can never be filled twice, or be on the
+// rota and still look open.
+func fillCoverRequest(ctx context.Context, q querier, id, coverID, adminEmail string) error {
+	tag, err := q.Exec(ctx, `
+		UPDATE cover_request
+		SET status = 'filled', cover_id = $2, closed_by = $3, closed_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`, id, coverID, adminEmail)
+	if err != nil {
+		return fmt.Errorf("failed to fill cover request %s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrCoverRequestNotOpen
+	}
+	return nil
+}
@@ -21,7 +21,7 @@ func (d *DB) GetDraftRotaAllocation(ctx context.Context, rotaID string) (*DraftR
 	var inputsChangedAt *time.Time
 	err := d.pool.QueryRow(ctx, `
 		SELECT rota_id, solved_at, success, solver_status, objective_value, diagnostics,
-		       inputs_changed_at, seats_asked, seats_filled
+		       empty_seats, inputs_changed_at, seats_asked, seats_filled
 		FROM draft_rota_allocation
 		WHERE rota_id = $1
 	`, rotaID).Scan(
@@ -31,6 +31,7 @@ func (d *DB) GetDraftRotaAllocation(ctx context.Context, rotaID string) (*DraftR
 		&draft.SolverStatus,
 		&objectiveValue,
 		&draft.Diagnostics,
+		&draft.EmptySeats,
 		&inputsChangedAt,
 		&draft.SeatsAsked,
 		&draft.SeatsFilled,
@@ -146,12 +147,17 @@ func (d *DB) ReplaceDraftRotaAllocation(ctx context.Context, draft DraftRotaAllo
 		stamp := draft.InputsChangedAt.UTC()
 		inputsChangedAt = &stamp
 	}
+	// A draft that says nothing about empty Seats has none to explain.
+	emptySeats := draft.EmptySeats
+	if emptySeats == nil {
+		emptySeats = []byte(`[]`)
+	}
 	if _, err := tx.Exec(ctx, `
 		INSERT INTO draft_rota_allocation (rota_id, solved_at, success, solver_status, objective_value, diagnostics,
-		                                   inputs_changed_at, seats_asked, seats_filled)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		                                   empty_seats, inputs_changed_at, seats_asked, seats_filled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`, draft.RotaID, draft.SolvedAt.UTC(), draft.Success, draft.SolverStatus, int64(draft.ObjectiveValue), draft.Diagnostics,
-		inputsChangedAt, draft.SeatsAsked, draft.SeatsFilled); err != nil {
+		emptySeats, inputsChangedAt, draft.SeatsAsked, draft.SeatsFilled); err != nil {
 		return fmt.Errorf("failed to write the draft for rota %s: %w", draft.RotaID, err)
 	}
 
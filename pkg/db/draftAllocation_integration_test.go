@@ -44,6 +44,7 @@ func TestReplaceDraftRotaAllocation(t *testing.T) {
 		SolverStatus:   "OPTIMAL",
 		ObjectiveValue: 42,
 		Diagnostics:    []byte(`{"solve_time_seconds":0.5,"num_groups":3}`),
+		EmptySeats:     []byte(`[{"shift_id":"x","role":"Team lead","count":1,"reason":"no_holder","detail":""}]`),
 	}, seats))
 
 	draft, err := database.GetDraftRotaAllocation(ctx, rota.ID)
@@ -55,6 +56,7 @@ func TestReplaceDraftRotaAllocation(t *testing.T) {
 	assert.Equal(t, "OPTIMAL", draft.SolverStatus)
 	assert.Equal(t, 42, draft.ObjectiveValue)
 	assert.JSONEq(t, `{"solve_time_seconds":0.5,"num_groups":3}`, string(draft.Diagnostics))
+	assert.JSONEq(t, `[{"shift_id":"x","role":"Team lead","count":1,"reason":"no_holder","detail":""}]`, string(draft.EmptySeats))
 
 	// Scoped by shift, like allocations: the caller has already resolved the
 	// shifts it cares about and never re-derives a date window (ADR 0001).
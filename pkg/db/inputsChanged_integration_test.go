@@ -80,7 +80,7 @@ func TestEveryAllocatorInputStampsTheRota(t *testing.T) {
 
 				_, err = database.InsertAvailabilityResponse(ctx, request.ID, []db.ShiftAnswer{
 					{ShiftID: first.ID, Answer: db.AnswerYes},
-				})
+				}, 0)
 				require.NoError(t, err)
 			},
 		},
@@ -161,6 +161,70 @@ func TestEveryAllocatorInputStampsTheRota(t *testing.T) {
 				}))
 			},
 		},
+		{
+			// A standing fact about two people, like the Roles: it stamps the
+			// rota in flight because the next solve reads it.
+			name: "a Pairing Rule",
+			move: func(t *testing.T) {
+				require.NoError(t, database.InsertPairingRule(ctx, db.PairingRule{
+					ID: pairingRuleID, VolunteerA: "alice", VolunteerB: "bob", Kind: "apart",
+				}))
+			},
+		},
+		{
+			name: "a Pairing Rule being removed",
+			move: func(t *testing.T) {
+				deleted, err := database.DeletePairingRuleByID(ctx, pairingRuleID)
+				require.NoError(t, err)
+				require.True(t, deleted)
+			},
+		},
+		{
+			// A volunteer's days away are a hard no for the next solve.
+			name: "an Absence",
+			move: func(t *testing.T) {
+				_, err := database.InsertAbsence(ctx, db.Absence{
+					ID: absenceID, VolunteerID: "alice", From: "2026-08-01", To: "2026-08-10",
+				})
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "an Absence being removed",
+			move: func(t *testing.T) {
+				deleted, err := database.DeleteAbsenceByID(ctx, absenceID)
+				require.NoError(t, err)
+				require.True(t, deleted)
+			},
+		},
+		{
+			// On a roster kept in the database, who is on it and what they
+			// hold are inputs like any other.
+			name: "a volunteer joining the roster",
+			move: func(t *testing.T) {
+				require.NoError(t, database.InsertVolunteer(ctx, db.Volunteer{
+					ID: "carol", FirstName: "Carol", Status: "Active", RoleIDs: []string{roles[0].ID},
+				}))
+			},
+		},
+		{
+			name: "a volunteer's Roles changing",
+			move: func(t *testing.T) {
+				written, err := database.UpdateVolunteer(ctx, db.Volunteer{
+					ID: "carol", FirstName: "Carol", Status: "Active", RoleIDs: []string{roles[1].ID},
+				})
+				require.NoError(t, err)
+				require.True(t, written)
+			},
+		},
+		{
+			name: "a volunteer leaving the roster",
+			move: func(t *testing.T) {
+				deleted, err := database.DeleteVolunteerByID(ctx, "carol")
+				require.NoError(t, err)
+				require.True(t, deleted)
+			},
+		},
 	} {
 		t.Run(input.name, func(t *testing.T) {
 			before := inputsChangedAt(t, database)
@@ -171,8 +235,13 @@ func TestEveryAllocatorInputStampsTheRota(t *testing.T) {
 	}
 }
 
-// pinID is shared by the pin-and-unpin pair above, which run in order.
-var pinID = uuid.New().String()
+// pinID, pairingRuleID and absenceID are shared by the add-and-remove pairs
+// above, which run in order.
+var (
+	pinID         = uuid.New().String()
+	pairingRuleID = uuid.New().String()
+	absenceID     = uuid.New().String()
+)
 
 // A Shift's times are descriptive rather than an allocator input (ADR 0007), so
 // moving a session within its day leaves the draft speaking for the rota. Moving
@@ -0,0 +1,13 @@
+-- A Draft Rota Allocation says why each Seat it left empty was left empty.
+--
+-- Seats filled against Seats asked tells an admin there are gaps; it does not
+-- tell them whether to chase a volunteer, relax a rule or accept the gap. The
+-- reasons are worked out from the solver's input, which has moved on by the time
+-- anybody reads the draft, so they are stored with the outcome rather than
+-- recomputed on each read.
+--
+-- Stored as the service layer's JSON, like diagnostics: a list of
+-- {shift_id, role, count, reason, detail}. The default fills the drafts already
+-- stored with "nothing explained", which is what they are; the next solve
+-- replaces them.
+ALTER TABLE draft_rota_allocation ADD COLUMN empty_seats JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,14 @@
+-- A volunteer can say how many shifts they would like, as well as which ones
+-- they can do.
+--
+-- "Free for all four Sundays but only want two" could not be said before: a yes
+-- was a yes, and the solver was free to use every one of them. The number is a
+-- preference, not a limit — the solver weighs it against leaving a Seat empty —
+-- so it sits beside the answers rather than changing what a yes means.
+--
+-- It belongs to the generation, not to the request: it is part of what was
+-- said in one submission, and a later submission replaces it with everything
+-- else. NULL is "no preference", which is what every generation already stored
+-- said, and what a backfilled Forms answer says, since Forms never asked.
+ALTER TABLE availability_response
+    ADD COLUMN preferred_shift_count INTEGER CHECK (preferred_shift_count > 0);
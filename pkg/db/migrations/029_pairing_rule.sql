@@ -0,0 +1,39 @@
+-- Pairing Rules: two volunteers the allocator should try to put on together, or
+-- must never put on the same Shift.
+--
+-- A group (the roster's GroupKey) is all-or-nothing: its members work every
+-- shift together or none. That is right for a couple and wrong for the looser
+-- things an admin actually knows — two friends who come more happily together,
+-- or two people who must not be on the same session, which is most often a
+-- safeguarding decision. Neither is a group, and neither belongs on the roster
+-- sheet, which is shared far more widely than the admin screens.
+--
+-- These are standing facts about people rather than about one rota, so they
+-- sit beside the roster rather than under a Rotation, and every solve reads
+-- them all.
+CREATE TABLE pairing_rule (
+    id UUID PRIMARY KEY,
+
+    -- The two volunteers, by roster id, stored in a fixed order so the pair
+    -- has one spelling: "Alice with Bob" and "Bob with Alice" are the same rule.
+    -- The order is byte order, as the service writes it, so the CHECK below
+    -- compares under "C": the default collation on postgres:16 is en_US.utf8,
+    -- which sorts "Bob" after "alice" where bytes put it before.
+    volunteer_a TEXT NOT NULL,
+    volunteer_b TEXT NOT NULL,
+
+    -- 'together' is a preference the solver weighs; 'apart' is a rule it never
+    -- breaks. One column rather than two tables because a pair has at most one
+    -- of them: wanting two people together and never together is a
+    -- contradiction, and the unique index below refuses it.
+    kind TEXT NOT NULL CHECK (kind IN ('together', 'apart')),
+
+    -- Why, for the next admin. Optional, and deliberately free text: the
+    -- reason for keeping two people apart is often not one to write down in
+    -- detail, and "safeguarding" is enough.
+    note TEXT,
+
+    CONSTRAINT pairing_rule_order_check CHECK (volunteer_a COLLATE "C" < volunteer_b COLLATE "C")
+);
+
+CREATE UNIQUE INDEX idx_pairing_rule_pair ON pairing_rule (volunteer_a, volunteer_b);
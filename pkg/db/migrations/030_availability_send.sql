@@ -0,0 +1,58 @@
+-- Availability sends: each time an admin emails a round's links, and what
+-- happened to every email in it.
+--
+-- A send takes about a minute and a half — Gmail is throttled to one email
+-- every three seconds — and until now its progress and its report lived only in
+-- the process running it. A deploy partway through lost both: the admin's
+-- progress bar answered 404, and the report of who was and was not emailed was
+-- gone. sent_at on availability_request already made a round send safe to run
+-- again; this is what makes one visible again, and resumable from where it
+-- stopped.
+CREATE TABLE availability_send (
+    id UUID PRIMARY KEY,
+    rota_id UUID NOT NULL REFERENCES rotation(id) ON DELETE CASCADE,
+
+    -- Who sent it. Mail goes out as them, through a Gmail token that is never
+    -- stored, so only they can resume it, and only they are shown its report.
+    admin_email TEXT NOT NULL,
+
+    mode TEXT NOT NULL CHECK (mode IN ('round', 'reminder', 'resend')),
+    -- The date quoted in the emails, kept so a resumed send quotes the same one.
+    deadline TEXT NOT NULL,
+    -- The one volunteer a resend is for; NULL for the other modes.
+    volunteer_id TEXT,
+
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- How many the mode selected. NULL until the recipients are known, which is
+    -- a moment after the send starts.
+    total INT,
+    -- Moved by every recorded outcome. A send that is not finished and has not
+    -- moved for a while is one whose process went away: there is no sweep at
+    -- startup to mark it, because whether a send is still running is a question
+    -- only this heartbeat can answer once there is more than one process.
+    last_progress_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    -- Why the send stopped short, when it did: a rota allocated under it, or a
+    -- mailer that could not be reached. A failed email is not this — it is an
+    -- outcome, and the send carries on past it.
+    error TEXT
+);
+
+CREATE INDEX idx_availability_send_rota ON availability_send (rota_id, started_at);
+
+-- One row per volunteer a send dealt with, written as each email goes out or
+-- fails, so the report is true at every point during the send rather than only
+-- after it.
+CREATE TABLE availability_send_outcome (
+    send_id UUID NOT NULL REFERENCES availability_send(id) ON DELETE CASCADE,
+    volunteer_id TEXT NOT NULL,
+    -- As the admin would recognise them at the time; the roster may have moved
+    -- on by the time somebody reads the history.
+    volunteer_name TEXT NOT NULL,
+    email TEXT,
+    -- NULL when the email went out.
+    error TEXT,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (send_id, volunteer_id)
+);
@@ -0,0 +1,17 @@
+-- Email Templates: the wording of the emails the drop-in sends, as an admin
+-- has reworded it on the Settings screen.
+--
+-- JSON for the reason allocation_settings is (018): the emails come and go with
+-- features, and a column each would make every new one a migration. The
+-- document is an object keyed by which email — "round", "reminder" — each value
+-- holding that email's subject, text and HTML templates. The list of emails
+-- there are, and what each says by default, lives in code; a key here is only
+-- ever an admin's rewording of one of them, and an email with no key reads as
+-- its default.
+--
+-- NULL until an admin saves a template, which reads the same as "{}".
+ALTER TABLE rota_defaults ADD COLUMN email_templates JSONB;
+
+ALTER TABLE rota_defaults ADD CONSTRAINT rota_defaults_email_templates_object CHECK (
+    email_templates IS NULL OR jsonb_typeof(email_templates) = 'object'
+);
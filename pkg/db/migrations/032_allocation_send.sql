@@ -0,0 +1,12 @@
+-- Allocation sends: emailing everyone on an allocated rota the shifts they have
+-- been given. They are recorded, counted and resumed exactly as availability
+-- sends are (030), so they share the table rather than copying it; all that
+-- changes is the mode a send may have.
+--
+-- An allocation send quotes no deadline — there is nothing left to answer — so
+-- its deadline is stored empty rather than made nullable for every mode.
+ALTER TABLE availability_send DROP CONSTRAINT availability_send_mode_check;
+
+ALTER TABLE availability_send ADD CONSTRAINT availability_send_mode_check CHECK (
+    mode IN ('round', 'reminder', 'resend', 'allocation')
+);
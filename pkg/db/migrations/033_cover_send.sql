@@ -0,0 +1,22 @@
+-- Cover sends: telling the volunteers a rota change affects that it happened.
+-- A change is recorded as a Cover and its Alterations (003), and until now the
+-- people it moved heard about it from whoever remembered to text them.
+--
+-- The emails are recorded, counted and resumed as every other send is (030),
+-- so they share the table. What a cover send adds is the Cover it is about:
+-- the send is the audit of who was told, and it is read back by the Cover.
+ALTER TABLE availability_send DROP CONSTRAINT availability_send_mode_check;
+
+ALTER TABLE availability_send ADD CONSTRAINT availability_send_mode_check CHECK (
+    mode IN ('round', 'reminder', 'resend', 'allocation', 'cover')
+);
+
+-- NULL for every mode but 'cover'. rota_id still names a rota — the one the
+-- change's first shift is in — so a round's history stays one query.
+ALTER TABLE availability_send ADD COLUMN cover_id UUID REFERENCES cover(id) ON DELETE CASCADE;
+
+ALTER TABLE availability_send ADD CONSTRAINT availability_send_cover_check CHECK (
+    (mode = 'cover') = (cover_id IS NOT NULL)
+);
+
+CREATE INDEX idx_availability_send_cover ON availability_send (cover_id) WHERE cover_id IS NOT NULL;
@@ -0,0 +1,49 @@
+-- Swap requests: a volunteer asking, through their own link, to give up one of
+-- their shifts on an allocated rota, and whoever takes it off them.
+--
+-- Until now every swap went through an admin recording a Cover by hand. A row
+-- here is the ask and its outcome; the change to the rota itself is still a
+-- Cover and its Alterations, written by the same code an admin's change is, and
+-- cover_id points at it once there is one.
+CREATE TABLE swap_request (
+    id UUID PRIMARY KEY,
+    shift_id UUID NOT NULL REFERENCES shift(id) ON DELETE CASCADE,
+    -- Who asked to drop the shift.
+    volunteer_id TEXT NOT NULL,
+    -- The Role they hold on it, which is the Role whoever takes it over holds.
+    -- NULL for a place recorded before alterations carried a Role.
+    role TEXT,
+
+    -- open: waiting for somebody to take it.
+    -- taken: somebody has, and an admin has yet to approve it.
+    -- done: the rota has been changed; cover_id is the change.
+    -- declined: an admin said no. The asker keeps the shift.
+    -- withdrawn: the asker changed their mind before it was done.
+    status TEXT NOT NULL CHECK (status IN ('open', 'taken', 'done', 'declined', 'withdrawn')),
+    requested_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- The first volunteer to accept. Only the first: accepting is a conditional
+    -- update on an open row, so two people tapping at once cannot both win.
+    taken_by TEXT,
+    taken_at TIMESTAMPTZ,
+    -- The admin who approved or declined it, when approval is asked for.
+    decided_by TEXT,
+    decided_at TIMESTAMPTZ,
+    cover_id UUID REFERENCES cover(id),
+
+    -- Declined and withdrawn keep whoever had taken it, if anybody had.
+    CHECK (status NOT IN ('taken', 'done') OR taken_by IS NOT NULL),
+    CHECK ((status = 'done') = (cover_id IS NOT NULL))
+);
+
+-- A volunteer asks to drop a shift once at a time: a second ask for the same
+-- place while the first is still live would offer it twice.
+CREATE UNIQUE INDEX idx_swap_request_live ON swap_request (shift_id, volunteer_id)
+    WHERE status IN ('open', 'taken');
+
+CREATE INDEX idx_swap_request_status ON swap_request (status, requested_at);
+
+-- Whether a taken swap waits for an admin before the rota changes. NULL until
+-- an admin saves it, which reads as no: a volunteer who finds cover has done
+-- what an admin would have asked them to.
+ALTER TABLE rota_defaults ADD COLUMN swaps_need_approval BOOLEAN;
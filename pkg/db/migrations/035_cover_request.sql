@@ -0,0 +1,70 @@
+-- Cover requests: an admin asking everyone who holds a Role whether they can
+-- cover one Shift on an allocated rota, and the answers that come back.
+--
+-- Availability links stop answering for availability at allocation, so once a
+-- rota is out the only way to find somebody for a gap was to text round. A row
+-- here is the ask; each volunteer asked gets their own short-lived link to
+-- answer it on. Filling it is an ordinary Cover and its Alterations, written by
+-- the same code an admin's change is, and cover_id points at it.
+CREATE TABLE cover_request (
+    id UUID PRIMARY KEY,
+    shift_id UUID NOT NULL REFERENCES shift(id) ON DELETE CASCADE,
+    -- The Role whoever covers takes, and so the Role everybody asked holds.
+    role TEXT NOT NULL,
+    -- The volunteer dropping out, taken off when the request is filled. NULL
+    -- when the shift is simply short.
+    replacing TEXT,
+    reason TEXT NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- When the links stop answering: the shift's start. Stored rather than
+    -- worked out on every read, because the shift's times can move and the
+    -- links handed out should not move with them.
+    expires_at TIMESTAMPTZ NOT NULL,
+
+    -- open: asking.
+    -- filled: an admin put somebody on; cover_id is the change.
+    -- cancelled: an admin stopped asking.
+    status TEXT NOT NULL CHECK (status IN ('open', 'filled', 'cancelled')),
+    closed_by TEXT,
+    closed_at TIMESTAMPTZ,
+    cover_id UUID REFERENCES cover(id),
+
+    CHECK ((status = 'filled') = (cover_id IS NOT NULL)),
+    CHECK ((status = 'open') = (closed_at IS NULL))
+);
+
+-- One open ask per place: a second broadcast for the same gap would have two
+-- sets of yeses and no way to say which one an admin acted on.
+CREATE UNIQUE INDEX idx_cover_request_open ON cover_request (shift_id, role, COALESCE(replacing, ''))
+    WHERE status = 'open';
+
+CREATE INDEX idx_cover_request_status ON cover_request (status, created_at);
+
+-- One link per volunteer asked, and their answer. The token is the only thing
+-- the volunteer's page is looked up by, as an availability link's is.
+CREATE TABLE cover_request_token (
+    token TEXT PRIMARY KEY,
+    cover_request_id UUID NOT NULL REFERENCES cover_request(id) ON DELETE CASCADE,
+    volunteer_id TEXT NOT NULL,
+    answer TEXT CHECK (answer IN ('yes', 'no')),
+    answered_at TIMESTAMPTZ,
+    sent_at TIMESTAMPTZ,
+
+    UNIQUE (cover_request_id, volunteer_id),
+    CHECK ((answer IS NULL) = (answered_at IS NULL))
+);
+
+-- The emails asking are recorded, counted and resumed as every other send is
+-- (030), so they share the table, read back by the request they were for.
+ALTER TABLE availability_send DROP CONSTRAINT availability_send_mode_check;
+
+ALTER TABLE availability_send ADD CONSTRAINT availability_send_mode_check CHECK (
+    mode IN ('round', 'reminder', 'resend', 'allocation', 'cover', 'cover-request')
+);
+
+ALTER TABLE availability_send ADD COLUMN cover_request_id UUID REFERENCES cover_request(id) ON DELETE CASCADE;
+
+ALTER TABLE availability_send ADD CONSTRAINT availability_send_cover_request_check CHECK (
+    (mode = 'cover-request') = (cover_request_id IS NOT NULL)
+);
@@ -0,0 +1,20 @@
+-- Attendance: whether each volunteer on a Shift that has happened turned up.
+--
+-- The rota, alterations applied, says who was meant to be there; nothing said
+-- who was. A row here is the Team lead's or an admin's word on one volunteer
+-- for one Shift, and there is at most one — a correction overwrites it rather
+-- than adding a second answer to choose between.
+CREATE TABLE attendance (
+    shift_id UUID NOT NULL REFERENCES shift(id) ON DELETE CASCADE,
+    volunteer_id TEXT NOT NULL,
+    -- attended: there for the shift.
+    -- no_show: did not come, and did not say in time for cover to be found.
+    -- left_early: came, and went before the end.
+    status TEXT NOT NULL CHECK (status IN ('attended', 'no_show', 'left_early')),
+    -- Who said: an admin's email, or the Team lead's volunteer id when it was
+    -- recorded on their link.
+    recorded_by TEXT NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (shift_id, volunteer_id)
+);
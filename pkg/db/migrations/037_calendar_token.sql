@@ -0,0 +1,17 @@
+-- Calendar tokens: the secret a volunteer's calendar feed is addressed by.
+--
+-- A feed used to be addressed by the volunteer's "Unique ID" from the sheet,
+-- which anybody who has seen the rota page can work out and nobody can take
+-- back. A token is random, so a feed URL says nothing about whose it is, and
+-- it is a row, so an admin can replace it — the old URL stops answering and
+-- the volunteer is handed the new one — or delete it outright.
+--
+-- One per volunteer: a second live feed URL for the same person would be a
+-- second thing to revoke, and nothing needs it.
+CREATE TABLE calendar_token (
+    volunteer_id TEXT PRIMARY KEY,
+    token TEXT NOT NULL UNIQUE,
+    -- The admin who minted it, or who sent the email it was minted for.
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
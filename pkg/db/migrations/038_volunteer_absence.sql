@@ -0,0 +1,31 @@
+-- Absences: a stretch of dates a volunteer has said in advance they are away.
+--
+-- "Abroad all of December" used to live in somebody's memory until the round
+-- came out, and then only if the volunteer remembered to untick December. An
+-- Absence is written down once, by an admin or by the volunteer through their
+-- link, and every round and every solve that touches those dates reads it as a
+-- no, whatever the answer says.
+--
+-- A standing fact about a person rather than about one rota, like a Pairing
+-- Rule, so it hangs off nothing: a long absence spans rotas, and one made
+-- before a rota is defined must still apply to it.
+CREATE TABLE volunteer_absence (
+    id UUID PRIMARY KEY,
+    volunteer_id TEXT NOT NULL,
+
+    -- Both ends are days away, inclusive. A one-day absence has them equal.
+    from_date DATE NOT NULL,
+    to_date DATE NOT NULL,
+
+    -- Optional, and for the admins: "wedding", "abroad".
+    note TEXT,
+
+    -- The admin who recorded it, or NULL when the volunteer did it themselves.
+    created_by TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT volunteer_absence_range_check CHECK (to_date >= from_date)
+);
+
+-- Every read is "the absences not over by this date".
+CREATE INDEX idx_volunteer_absence_to_date ON volunteer_absence (to_date);
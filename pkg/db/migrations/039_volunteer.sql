@@ -0,0 +1,40 @@
+-- The volunteer roster, for a deployment that keeps it here rather than in the
+-- Google Sheet (config roster.source: database).
+--
+-- The sheet was the only roster there was, and the server holds what it read in
+-- memory: restarted while Sheets is unreachable, it had nobody on its roster
+-- until an admin's sync got through. Held here, the roster is as available as
+-- everything else the app reads.
+--
+-- The columns are the sheet's, so a roster imported from it reads exactly as
+-- it did. The id is the sheet's Unique ID and stays TEXT: allocations, answers,
+-- pins and rules all name a volunteer by it, and none of them may move.
+CREATE TABLE volunteer (
+    id TEXT PRIMARY KEY,
+    first_name TEXT NOT NULL CHECK (first_name <> ''),
+    last_name TEXT NOT NULL DEFAULT '',
+
+    -- The Status cell as the sheet would spell it ("Active", "Paused until
+    -- 2026-03-01"), so one parser (model.ParseVolunteerStatus) reads both.
+    status TEXT NOT NULL,
+
+    -- Free text, as on the sheet.
+    gender TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL DEFAULT '',
+
+    -- Empty for somebody in no group.
+    group_key TEXT NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- The Roles a volunteer holds. By id rather than by name, unlike the sheet's
+-- cell: here the app owns both halves of the contract, so renaming a Role
+-- cannot leave anybody holding a name that no longer exists (ADR 0006). Roles
+-- are never deleted, so nothing cascades from that side.
+CREATE TABLE volunteer_role (
+    volunteer_id TEXT NOT NULL REFERENCES volunteer (id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES role (id),
+    PRIMARY KEY (volunteer_id, role_id)
+);
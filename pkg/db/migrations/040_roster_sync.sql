@@ -0,0 +1,26 @@
+-- Roster syncs: each time an admin replaced the roster with the sheet, and what
+-- that changed.
+--
+-- A sync used to swap the roster wholesale and leave nothing behind but a log
+-- line, so "when did Emma stop being a team lead?" had no answer. A sync is now
+-- previewed as a diff and applied only once confirmed; the diff applied is
+-- kept here, with who confirmed it.
+--
+-- The diff is JSON for the reason a draft's diagnostics are (022): it is read
+-- back whole and never queried into, and its shape is the service layer's.
+-- The counts are its headline, kept as columns so a list of syncs need not
+-- decode each one.
+CREATE TABLE roster_sync (
+    id UUID PRIMARY KEY,
+    synced_by TEXT NOT NULL,
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    added INTEGER NOT NULL,
+    removed INTEGER NOT NULL,
+    changed INTEGER NOT NULL,
+    diff JSONB NOT NULL,
+
+    CONSTRAINT roster_sync_diff_object CHECK (jsonb_typeof(diff) = 'object')
+);
+
+-- Every read is "the most recent".
+CREATE INDEX idx_roster_sync_synced_at ON roster_sync (synced_at DESC);
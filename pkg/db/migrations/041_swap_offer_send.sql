@@ -0,0 +1,18 @@
+-- Swap offers: telling the volunteers who could take a shift somebody has asked
+-- to give up that it is on offer.
+--
+-- A swap request (034) offered the shift on the swap page of everyone who could
+-- take it, and nowhere else, so it was taken only by somebody who happened to
+-- open their link. The email is a send like any other (030), recorded against
+-- the request it offered.
+ALTER TABLE availability_send DROP CONSTRAINT availability_send_mode_check;
+
+ALTER TABLE availability_send ADD CONSTRAINT availability_send_mode_check CHECK (
+    mode IN ('round', 'reminder', 'resend', 'allocation', 'cover', 'cover-request', 'swap-offer')
+);
+
+ALTER TABLE availability_send ADD COLUMN swap_request_id UUID REFERENCES swap_request(id) ON DELETE CASCADE;
+
+ALTER TABLE availability_send ADD CONSTRAINT availability_send_swap_request_check CHECK (
+    (mode = 'swap-offer') = (swap_request_id IS NOT NULL)
+);
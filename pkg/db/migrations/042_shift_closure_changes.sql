@@ -0,0 +1,15 @@
+-- How many times a Shift has been closed or reopened, for the calendar feeds.
+--
+-- A feed tells a client that a closed Shift is cancelled by sending its event
+-- again with a higher SEQUENCE than the copy the client holds. Closing is not an
+-- Alteration, so the alteration count alone cannot say that a Shift was closed,
+-- and cannot say that it was reopened after: a cancellation numbered one past
+-- the count is outranked by nothing once the Shift is open again, and a client
+-- keeps showing it cancelled. Counting each change of the flag gives SEQUENCE
+-- something that only ever goes up.
+--
+-- A Shift already closed has been closed once, which is what the feeds have
+-- been numbering its cancellation as.
+ALTER TABLE shift ADD COLUMN closure_changes INTEGER NOT NULL DEFAULT 0;
+
+UPDATE shift SET closure_changes = 1 WHERE closed;
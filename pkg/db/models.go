@@ -32,6 +32,10 @@ type Shift struct {
 	// hand while the rota is unallocated and false at mint; there is no stored
 	// list of known closure dates (issue #132, amending ADR 0001).
 	Closed bool
+	// ClosureChanges is how many times Closed has been set or cleared, which
+	// only ever goes up: a calendar feed numbers a Shift's event with it, so a
+	// reopened Shift outranks the cancellation its closing sent.
+	ClosureChanges int
 	// StartAt and EndAt are when the session runs, spelled
 	// "2006-01-02T15:04:05". They are local wall-clock times in the drop-in's
 	// own zone — TIMESTAMP without time zone, carrying no offset, because a
@@ -64,6 +68,43 @@ type AvailabilityRequest struct {
 	SentAt      string // TIMESTAMPTZ, empty string if NULL
 }
 
+// AvailabilitySend is one time an admin emailed a round's links: who did it,
+// which mode, and how far it got. Sent and Failed are counted from its
+// outcomes when it is read, so they can never disagree with them.
+//
+// A send that is not finished is either still running or was stopped by its
+// process going away; LastProgressAt is what tells those apart, and the
+// service layer is what decides how stale is stale.
+type AvailabilitySend struct {
+	ID             string // UUID
+	RotaID         string // UUID
+	AdminEmail     string
+	Mode           string
+	Deadline       string
+	VolunteerID    string // resend only, empty string if NULL
+	CoverID        string // cover only, empty string if NULL
+	CoverRequestID string // cover-request only, empty string if NULL
+	SwapRequestID  string // swap-offer only, empty string if NULL
+	StartedAt      time.Time
+	Total          int // NULL, before the recipients are known, stored as 0
+	LastProgressAt time.Time
+	FinishedAt     *time.Time
+	Error          string // empty string if NULL
+	Sent           int
+	Failed         int
+}
+
+// AvailabilitySendOutcome is what happened to one volunteer's email in a send.
+// An empty Error means it went out.
+type AvailabilitySendOutcome struct {
+	SendID        string // UUID
+	VolunteerID   string
+	VolunteerName string
+	Email         string // empty string if NULL
+	Error         string // empty string if NULL
+	RecordedAt    time.Time
+}
+
 // Answers a volunteer may give for a shift. Only positives are stored — an
 // absent row is a no — so there is no NO to record. PREFERRED has no consumer
 // yet (ADR 0004).
@@ -83,11 +124,15 @@ type ShiftAnswer struct {
 // that submission said yes to, and is empty for a volunteer who submitted
 // nothing — a state the Forms encoding could not express, and which reads
 // differently from never having replied (no generation at all).
+//
+// PreferredShiftCount is how many of those shifts they would like to work, and
+// 0 when they did not say. It is a preference the solver weighs, not a cap.
 type AvailabilityGeneration struct {
-	RequestID   string // UUID of the availability_request row
-	ResponseID  string // UUID
-	SubmittedAt time.Time
-	Answers     []ShiftAnswer
+	RequestID           string // UUID of the availability_request row
+	ResponseID          string // UUID
+	SubmittedAt         time.Time
+	Answers             []ShiftAnswer
+	PreferredShiftCount int // NULL stored as 0
 }
 
 // Allocation represents a database allocation record. It is keyed solely by
@@ -121,6 +166,10 @@ type DraftRotaAllocation struct {
 	// the JSON it arrived as. This package never looks inside it: the shape
 	// belongs to the allocator, which this layer does not import.
 	Diagnostics []byte
+	// EmptySeats is why each Seat the solve left empty was left empty, stored as
+	// the JSON the service layer wrote. Like Diagnostics it is opaque here; nil
+	// is stored as an empty list.
+	EmptySeats []byte
 	// InputsChangedAt is the Rotation's own stamp as it stood when this solve
 	// began. It is dirtiness by comparison: a Rotation whose stamp has moved on
 	// since has had an input change the draft has not seen (issue #142).
@@ -185,6 +234,18 @@ type StandingPreallocation struct {
 	CustomValue string // nullable
 }
 
+// PairingRule is two volunteers the allocator should try to put on the same
+// Shift (Kind "together"), or must never put on the same one (Kind "apart").
+// VolunteerA sorts before VolunteerB, so a pair has one spelling whichever way
+// round an admin named it. Note is why, for the next admin, and may be empty.
+type PairingRule struct {
+	ID         string // UUID
+	VolunteerA string
+	VolunteerB string
+	Kind       string
+	Note       string // nullable
+}
+
 // Cover represents a database cover record (audit trail for rota changes)
 type Cover struct {
 	ID        string // UUID
@@ -206,3 +267,137 @@ type Alteration struct {
 	SetTime     string // TIMESTAMPTZ
 	Role        string // nullable - role for "add" alterations
 }
+
+// Where a swap request has got to. Only open and taken are live: the other
+// three are the end of it, kept so what happened can be read back.
+const (
+	SwapOpen      = "open"
+	SwapTaken     = "taken"
+	SwapDone      = "done"
+	SwapDeclined  = "declined"
+	SwapWithdrawn = "withdrawn"
+)
+
+// SwapRequest is a volunteer asking, through their own link, to give up one of
+// their Shifts, and whoever takes it over. The change to the rota, once there
+// is one, is an ordinary Cover; CoverID is it.
+type SwapRequest struct {
+	ID          string // UUID
+	ShiftID     string // UUID
+	VolunteerID string // who asked to drop the shift
+	Role        string // their Role on it; empty string if NULL
+	Status      string
+	RequestedAt time.Time
+	TakenBy     string // empty string if NULL
+	TakenAt     *time.Time
+	DecidedBy   string // admin email, declines only; empty string if NULL
+	DecidedAt   *time.Time
+	CoverID     string // UUID, done only; empty string if NULL
+}
+
+// Where a cover request has got to. Only open is live; an open request whose
+// links have expired is still open until an admin fills or cancels it, so the
+// yeses it collected stay readable.
+const (
+	CoverRequestOpen      = "open"
+	CoverRequestFilled    = "filled"
+	CoverRequestCancelled = "cancelled"
+)
+
+// CoverRequest is an admin asking everyone who holds a Role whether they can
+// cover one Shift on an allocated rota. Filling it is an ordinary Cover;
+// CoverID is it.
+type CoverRequest struct {
+	ID        string // UUID
+	ShiftID   string // UUID
+	Role      string
+	Replacing string // the volunteer dropping out; empty string if NULL
+	Reason    string
+	CreatedBy string // admin email
+	CreatedAt time.Time
+	ExpiresAt time.Time // the shift's start, when the links stop answering
+	Status    string
+	ClosedBy  string // admin email; empty string if NULL
+	ClosedAt  *time.Time
+	CoverID   string // UUID, filled only; empty string if NULL
+}
+
+// CoverRequestToken is one volunteer's link to answer a cover request, and
+// their answer. Answer is "yes", "no", or empty for nobody has said.
+type CoverRequestToken struct {
+	Token          string
+	CoverRequestID string // UUID
+	VolunteerID    string
+	Answer         string // empty string if NULL
+	AnsweredAt     *time.Time
+	SentAt         *time.Time
+}
+
+// CalendarToken is the secret a volunteer's calendar feed is addressed by.
+// Replacing it is how a feed URL is revoked, so there is one per volunteer.
+type CalendarToken struct {
+	VolunteerID string
+	Token       string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// Absence is a stretch of days a volunteer is away, From to To inclusive,
+// both "2006-01-02". CreatedBy is the admin who recorded it, empty when the
+// volunteer did it themselves through their link.
+type Absence struct {
+	ID          string // UUID
+	VolunteerID string
+	From        string
+	To          string
+	Note        string // nullable
+	CreatedBy   string // nullable
+	CreatedAt   time.Time
+}
+
+// Volunteer is one person on the roster, for a deployment that keeps it in the
+// database rather than the sheet. The fields are the sheet's columns; RoleIDs
+// are the Roles held, by id, in no particular order — which of them comes
+// first is the Roles' priority, and the domain's to apply.
+type Volunteer struct {
+	ID        string
+	FirstName string
+	LastName  string
+	Status    string // the sheet's Status cell, spelt as the sheet would
+	Gender    string
+	Email     string
+	GroupKey  string // empty for no group
+	RoleIDs   []string
+}
+
+// The states a volunteer's attendance on a Shift can be recorded in.
+const (
+	AttendanceAttended  = "attended"
+	AttendanceNoShow    = "no_show"
+	AttendanceLeftEarly = "left_early"
+)
+
+// Attendance is whether one volunteer turned up to one Shift that has
+// happened. RecordedBy is an admin's email, or the Team lead's volunteer id
+// when it was recorded on their link.
+type Attendance struct {
+	ShiftID     string // UUID
+	VolunteerID string
+	Status      string
+	RecordedBy  string
+	RecordedAt  time.Time
+}
+
+// RosterSync is one sync of the roster from the sheet an admin confirmed: who,
+// when, and what it changed. Diff is the JSON the service layer wrote, opaque
+// here as a draft's Diagnostics are; the counts beside it are the headline, so
+// a list of syncs need not decode every one.
+type RosterSync struct {
+	ID       string // UUID
+	SyncedBy string
+	SyncedAt time.Time
+	Added    int
+	Removed  int
+	Changed  int
+	Diff     []byte
+}
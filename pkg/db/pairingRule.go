@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDuplicatePairingRule reports that the two volunteers already have a
+// Pairing Rule, of either kind. Named for the same reason ErrDuplicateRoleName
+// is: an admin adding a rule that is already there, or its opposite, has made
+// an ordinary mistake and is told so rather than shown a driver error code.
+var ErrDuplicatePairingRule = errors.New("those two volunteers already have a pairing rule")
+
+func isDuplicatePairingRule(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) &&
+		pgErr.Code == uniqueViolation &&
+		pgErr.ConstraintName == "idx_pairing_rule_pair"
+}
+
+// GetPairingRules reads every Pairing Rule. Like the Standing Preallocations,
+// there is nothing to filter by: the admin screen lists them all, and every
+// solve applies them all.
+func (d *DB) GetPairingRules(ctx context.Context) ([]PairingRule, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT id, volunteer_a, volunteer_b, kind, note
+		FROM pairing_rule
+		ORDER BY volunteer_a, volunteer_b
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pairing rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []PairingRule
+	for rows.Next() {
+		var r PairingRule
+		var note *string
+		if err := rows.Scan(&r.ID, &r.VolunteerA, &r.VolunteerB, &r.Kind, &note); err != nil {
+			return nil, fmt.Errorf("failed to scan pairing rule: %w", err)
+		}
+		r.Note = deref(note)
+		rules = append(rules, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pairing rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// InsertPairingRule writes one, reporting a second rule for the same pair as
+// ErrDuplicatePairingRule. The caller puts the pair in order; the table's CHECK
+// refuses one that is not, rather than this quietly swapping them.
+//
+// A Pairing Rule is an allocator input for every rota not yet allocated, so the
+// write marks them as having moved, in the same transaction.
+func (d *DB) InsertPairingRule(ctx context.Context, rule PairingRule) error {
+	return d.inTx(ctx, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO pairing_rule (id, volunteer_a, volunteer_b, kind, note)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		`, rule.ID, rule.VolunteerA, rule.VolunteerB, rule.Kind, rule.Note)
+		if err != nil {
+			if isDuplicatePairingRule(err) {
+				return ErrDuplicatePairingRule
+			}
+			return fmt.Errorf("failed to insert pairing rule: %w", err)
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+}
+
+// DeletePairingRuleByID removes one, reporting whether a row was actually
+// deleted so a caller can tell a missing one from a successful delete. Only a
+// delete that removed something marks the rota in flight as having moved.
+func (d *DB) DeletePairingRuleByID(ctx context.Context, id string) (bool, error) {
+	var deleted bool
+	err := d.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM pairing_rule WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete pairing rule %s: %w", id, err)
+		}
+		deleted = tag.RowsAffected() > 0
+		if !deleted {
+			return nil
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+	return deleted, err
+}
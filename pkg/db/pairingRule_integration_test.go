@@ -0,0 +1,83 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/db/dbtest"
+)
+
+func TestPairingRuleInsertReadDelete(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	apart := db.PairingRule{
+		ID: uuid.New().String(), VolunteerA: "alice", VolunteerB: "bob", Kind: "apart", Note: "safeguarding",
+	}
+	together := db.PairingRule{
+		ID: uuid.New().String(), VolunteerA: "carol", VolunteerB: "dan", Kind: "together",
+	}
+	require.NoError(t, database.InsertPairingRule(ctx, apart))
+	require.NoError(t, database.InsertPairingRule(ctx, together))
+
+	rules, err := database.GetPairingRules(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []db.PairingRule{apart, together}, rules, "read back whole, in pair order, with no note as empty")
+
+	deleted, err := database.DeletePairingRuleByID(ctx, apart.ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = database.DeletePairingRuleByID(ctx, apart.ID)
+	require.NoError(t, err)
+	assert.False(t, deleted, "a second delete reports that nothing matched")
+}
+
+// A pair has at most one rule: the same one twice is a slip, and its opposite
+// is a contradiction. Either is refused as a duplicate.
+func TestPairingRuleRefusesASecondRuleForThePair(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	require.NoError(t, database.InsertPairingRule(ctx, db.PairingRule{
+		ID: uuid.New().String(), VolunteerA: "alice", VolunteerB: "bob", Kind: "together",
+	}))
+
+	err := database.InsertPairingRule(ctx, db.PairingRule{
+		ID: uuid.New().String(), VolunteerA: "alice", VolunteerB: "bob", Kind: "apart",
+	})
+	assert.ErrorIs(t, err, db.ErrDuplicatePairingRule)
+
+	// The pair has one spelling, and the table holds the writer to it rather
+	// than accepting a second, reversed row.
+	err = database.InsertPairingRule(ctx, db.PairingRule{
+		ID: uuid.New().String(), VolunteerA: "bob", VolunteerB: "alice", Kind: "together",
+	})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, db.ErrDuplicatePairingRule)
+}
+
+// The pair's order is byte order, which is how the service writes it, not the
+// database's collation: under en_US "Bob" sorts after "alice", and "a-2" after
+// "a1", so a CHECK compared that way refused both.
+func TestPairingRuleOrderIsByteOrder(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	for _, pair := range [][2]string{{"Bob", "alice"}, {"a-2", "a1"}} {
+		require.Less(t, pair[0], pair[1], "the service writes the pair in Go's order")
+		require.NoError(t, database.InsertPairingRule(ctx, db.PairingRule{
+			ID: uuid.New().String(), VolunteerA: pair[0], VolunteerB: pair[1], Kind: "apart",
+		}), "%s and %s", pair[0], pair[1])
+	}
+
+	err := database.InsertPairingRule(ctx, db.PairingRule{
+		ID: uuid.New().String(), VolunteerA: "alice", VolunteerB: "Bob", Kind: "together",
+	})
+	require.Error(t, err, "the other spelling is still refused")
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// InsertRosterSync records one applied sync. It is written after the roster
+// was replaced, not with it — the roster is held in memory, not here — so
+// there is no transaction to share.
+func (d *DB) InsertRosterSync(ctx context.Context, sync RosterSync) error {
+	_, err := d.pool.Exec(ctx, `
+		INSERT INTO roster_sync (id, synced_by, added, removed, changed, diff)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+	`, sync.ID, sync.SyncedBy, sync.Added, sync.Removed, sync.Changed, string(sync.Diff))
+	if err != nil {
+		return fmt.Errorf("failed to insert roster sync: %w", err)
+	}
+	return nil
+}
+
+// GetRosterSyncs reads the most recent limit syncs, newest first.
+func (d *DB) GetRosterSyncs(ctx context.Context, limit int) ([]RosterSync, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT id, synced_by, synced_at, added, removed, changed, diff
+		FROM roster_sync
+		ORDER BY synced_at DESC, id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roster syncs: %w", err)
+	}
+	defer rows.Close()
+
+	var out []RosterSync
+	for rows.Next() {
+		var s RosterSync
+		if err := rows.Scan(&s.ID, &s.SyncedBy, &s.SyncedAt, &s.Added, &s.Removed, &s.Changed, &s.Diff); err != nil {
+			return nil, fmt.Errorf("failed to scan roster sync: %w", err)
+		}
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating roster syncs: %w", err)
+	}
+	return out, nil
+}
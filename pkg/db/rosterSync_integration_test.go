@@ -0,0 +1,44 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/db/dbtest"
+)
+
+func TestRosterSyncInsertAndRead(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	first := db.RosterSync{ID: uuid.New().String(), SyncedBy: "admin@example.com", Added: 1, Diff: []byte(`{"added":[{"id":"alice","name":"Alice"}]}`)}
+	second := db.RosterSync{ID: uuid.New().String(), SyncedBy: "other@example.com", Removed: 2, Changed: 3, Diff: []byte(`{"removed":[]}`)}
+	require.NoError(t, database.InsertRosterSync(ctx, first))
+	require.NoError(t, database.InsertRosterSync(ctx, second))
+
+	syncs, err := database.GetRosterSyncs(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, syncs, 2)
+	assert.Equal(t, second.ID, syncs[0].ID, "newest first")
+	assert.Equal(t, 2, syncs[0].Removed)
+	assert.Equal(t, 3, syncs[0].Changed)
+	assert.False(t, syncs[0].SyncedAt.IsZero())
+	assert.JSONEq(t, string(first.Diff), string(syncs[1].Diff))
+
+	limited, err := database.GetRosterSyncs(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+}
+
+// The diff is an object or nothing: a list of syncs decodes each one.
+func TestRosterSyncRefusesANonObjectDiff(t *testing.T) {
+	database, _ := dbtest.New(t)
+
+	err := database.InsertRosterSync(context.Background(), db.RosterSync{ID: uuid.New().String(), SyncedBy: "admin@example.com", Diff: []byte(`[]`)})
+	require.Error(t, err)
+}
@@ -39,6 +39,14 @@ type RotaDefaults struct {
 	// column is JSON is that this layer should not need changing when a
 	// constraint arrives or leaves (ADR 0006).
 	AllocationSettings string
+	// EmailTemplates is the admin's wording for the emails the drop-in sends,
+	// as the JSON document the column holds: an object keyed by which email.
+	// Empty means nobody has reworded any. Carried verbatim for the same
+	// reason AllocationSettings is.
+	EmailTemplates string
+	// SwapsNeedApproval is whether a swap a volunteer arranges through their
+	// link waits for an admin before the rota changes. NULL reads as false.
+	SwapsNeedApproval bool
 }
 
 // GetRotaDefaults reads the settings record.
@@ -51,14 +59,17 @@ func (d *DB) GetRotaDefaults(ctx context.Context) (RotaDefaults, error) {
 	// to_char renders the TIME the way the app states it. Doing the formatting
 	// in SQL keeps a time of day a string on this side of the boundary, where
 	// scanning into a time.Time would attach a meaningless date to it.
-	var start, end, timezone, allocation *string
+	var start, end, timezone, allocation, templates *string
+	var swapsNeedApproval *bool
 	err := d.pool.QueryRow(ctx, `
 		SELECT to_char(shift_start_time, 'HH24:MI'),
 		       to_char(shift_end_time, 'HH24:MI'),
 		       shift_timezone,
-		       allocation_settings::text
+		       allocation_settings::text,
+		       email_templates::text,
+		       swaps_need_approval
 		FROM rota_defaults
-	`).Scan(&start, &end, &timezone, &allocation)
+	`).Scan(&start, &end, &timezone, &allocation, &templates, &swapsNeedApproval)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return RotaDefaults{}, nil
 	}
@@ -71,6 +82,8 @@ func (d *DB) GetRotaDefaults(ctx context.Context) (RotaDefaults, error) {
 		ShiftEndTime:       deref(end),
 		ShiftTimezone:      deref(timezone),
 		AllocationSettings: deref(allocation),
+		EmailTemplates:     deref(templates),
+		SwapsNeedApproval:  swapsNeedApproval != nil && *swapsNeedApproval,
 	}, nil
 }
 
@@ -127,6 +140,61 @@ func (d *DB) SaveAllocationSettings(ctx context.Context, settings string) error
 	})
 }
 
+// SaveEmailTemplate writes the wording of one email, creating the settings
+// record if this is the first time anyone has saved it.
+//
+// One key of the document rather than the whole of it, so two admins
+// rewording two different emails cannot undo each other's save. The template
+// is written as given, a JSON object; what is inside it is the domain's.
+//
+// Not an allocator input, so unlike the allocation settings it leaves every
+// draft as clean as it was.
+func (d *DB) SaveEmailTemplate(ctx context.Context, kind, template string) error {
+	_, err := d.pool.Exec(ctx, `
+		INSERT INTO rota_defaults (id, email_templates)
+		VALUES (TRUE, jsonb_build_object($1::text, $2::jsonb))
+		ON CONFLICT (id) DO UPDATE SET
+			email_templates = COALESCE(rota_defaults.email_templates, '{}'::jsonb) || EXCLUDED.email_templates
+	`, kind, template)
+	if err != nil {
+		return fmt.Errorf("failed to save the %s email template: %w", kind, err)
+	}
+	return nil
+}
+
+// ResetEmailTemplate drops the saved wording of one email, so it reads as its
+// default again. Dropping one nobody saved is not an error: the email already
+// says what it would say afterwards.
+func (d *DB) ResetEmailTemplate(ctx context.Context, kind string) error {
+	_, err := d.pool.Exec(ctx, `
+		UPDATE rota_defaults SET email_templates = email_templates - $1::text
+		WHERE email_templates IS NOT NULL
+	`, kind)
+	if err != nil {
+		return fmt.Errorf("failed to reset the %s email template: %w", kind, err)
+	}
+	return nil
+}
+
+// SaveSwapsNeedApproval writes whether volunteers' swaps wait for an admin,
+// creating the settings record if this is the first time anyone has saved it.
+// Its own method, like every section's: saving it must not blank another.
+//
+// Not an allocator input: it decides what happens to a rota already
+// allocated, so no draft goes stale over it.
+func (d *DB) SaveSwapsNeedApproval(ctx context.Context, needApproval bool) error {
+	_, err := d.pool.Exec(ctx, `
+		INSERT INTO rota_defaults (id, swaps_need_approval)
+		VALUES (TRUE, $1)
+		ON CONFLICT (id) DO UPDATE SET
+			swaps_need_approval = EXCLUDED.swaps_need_approval
+	`, needApproval)
+	if err != nil {
+		return fmt.Errorf("failed to save the swap settings: %w", err)
+	}
+	return nil
+}
+
 // deref reads a nullable text column as the empty string, which is how this
 // package spells "the admin has not set this".
 func deref(value *string) string {
@@ -149,3 +149,35 @@ func TestSaveAllocationSettingsRefusesANonObject(t *testing.T) {
 	err := database.SaveAllocationSettings(context.Background(), `["no_back_to_back"]`)
 	require.Error(t, err)
 }
+
+// Each email's wording is its own key: saving one leaves the others as they
+// were, and resetting one drops only that key.
+func TestSaveAndResetEmailTemplate(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	require.NoError(t, database.SaveEmailTemplate(ctx, "round", `{"subject":"Round","text":"Hi"}`))
+	require.NoError(t, database.SaveEmailTemplate(ctx, "reminder", `{"subject":"Reminder","text":"Hi again"}`))
+	require.NoError(t, database.SaveEmailTemplate(ctx, "round", `{"subject":"Round, reworded","text":"Hello"}`))
+
+	defaults, err := database.GetRotaDefaults(ctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"round": {"subject":"Round, reworded","text":"Hello"},
+		"reminder": {"subject":"Reminder","text":"Hi again"}
+	}`, defaults.EmailTemplates)
+
+	require.NoError(t, database.ResetEmailTemplate(ctx, "round"))
+
+	defaults, err = database.GetRotaDefaults(ctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"reminder": {"subject":"Reminder","text":"Hi again"}}`, defaults.EmailTemplates)
+}
+
+// Resetting on a deployment with no settings row is a no-op, not an error: the
+// email already reads as its default.
+func TestResetEmailTemplateUnset(t *testing.T) {
+	database, _ := dbtest.New(t)
+
+	require.NoError(t, database.ResetEmailTemplate(context.Background(), "round"))
+}
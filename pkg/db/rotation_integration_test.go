@@ -85,11 +85,11 @@ func TestGetRotaInFlight_CountsTheRoundInVolunteers(t *testing.T) {
 
 	// Alice answers twice; Bob once. Carol has her link and has said nothing.
 	answers := []db.ShiftAnswer{{ShiftID: shift.ID, Answer: "YES"}}
-	_, err = database.InsertAvailabilityResponse(ctx, requests[0].ID, answers)
+	_, err = database.InsertAvailabilityResponse(ctx, requests[0].ID, answers, 0)
 	require.NoError(t, err)
-	_, err = database.InsertAvailabilityResponse(ctx, requests[0].ID, answers)
+	_, err = database.InsertAvailabilityResponse(ctx, requests[0].ID, answers, 0)
 	require.NoError(t, err)
-	_, err = database.InsertAvailabilityResponse(ctx, requests[1].ID, nil)
+	_, err = database.InsertAvailabilityResponse(ctx, requests[1].ID, nil, 0)
 	require.NoError(t, err)
 
 	inFlight, err := database.GetRotaInFlight(ctx)
@@ -122,7 +122,7 @@ func TestDiscardRota(t *testing.T) {
 	keeperRequests := []db.AvailabilityRequest{{ID: uuid.New().String(), RotaID: keeper.ID, VolunteerID: "alice", Token: "keeper-token"}}
 	_, err = database.MintAvailabilityRequests(ctx, keeperRequests)
 	require.NoError(t, err)
-	_, err = database.InsertAvailabilityResponse(ctx, keeperRequests[0].ID, []db.ShiftAnswer{{ShiftID: keeperShift.ID, Answer: "YES"}})
+	_, err = database.InsertAvailabilityResponse(ctx, keeperRequests[0].ID, []db.ShiftAnswer{{ShiftID: keeperShift.ID, Answer: "YES"}}, 0)
 	require.NoError(t, err)
 
 	doomed := &db.Rotation{ID: uuid.New().String()}
@@ -147,7 +147,7 @@ func TestDiscardRota(t *testing.T) {
 	_, err = database.MintAvailabilityRequests(ctx, doomedRequests)
 	require.NoError(t, err)
 	_, err = database.InsertAvailabilityResponse(ctx, doomedRequests[0].ID,
-		[]db.ShiftAnswer{{ShiftID: doomedShifts[0].ID, Answer: "YES"}, {ShiftID: doomedShifts[1].ID, Answer: "YES"}})
+		[]db.ShiftAnswer{{ShiftID: doomedShifts[0].ID, Answer: "YES"}, {ShiftID: doomedShifts[1].ID, Answer: "YES"}}, 0)
 	require.NoError(t, err)
 
 	// A Draft Rota Allocation on each. Both cascade rather than being deleted
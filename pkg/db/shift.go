@@ -58,7 +58,7 @@ func localTimestamp(t time.Time) string {
 // instead (ADR 0001).
 func (d *DB) GetShiftsByRotaID(ctx context.Context, rotaID string) ([]Shift, error) {
 	rows, err := d.pool.Query(ctx, `
-		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.start_at, s.end_at
+		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.closure_changes, s.start_at, s.end_at
 		FROM shift s
 		WHERE s.rota_id = $1
 		ORDER BY `+shiftDateExpr+`
@@ -72,7 +72,7 @@ func (d *DB) GetShiftsByRotaID(ctx context.Context, rotaID string) ([]Shift, err
 	for rows.Next() {
 		var s Shift
 		var date, startAt, endAt time.Time
-		if err := rows.Scan(&s.ID, &date, &s.RotaID, &s.Closed, &startAt, &endAt); err != nil {
+		if err := rows.Scan(&s.ID, &date, &s.RotaID, &s.Closed, &s.ClosureChanges, &startAt, &endAt); err != nil {
 			return nil, fmt.Errorf("failed to scan shift: %w", err)
 		}
 		s.Date = date.Format("2006-01-02")
@@ -103,7 +103,7 @@ type ShiftInRange struct {
 func (d *DB) GetShiftsInRange(ctx context.Context, from, to time.Time) ([]ShiftInRange, error) {
 	where, args := shiftDateWhere(from, to)
 	rows, err := d.pool.Query(ctx, `
-		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.start_at, s.end_at, r.allocated_datetime IS NOT NULL
+		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.closure_changes, s.start_at, s.end_at, r.allocated_datetime IS NOT NULL
 		FROM shift s
 		JOIN rotation r ON r.id = s.rota_id
 	`+where+`
@@ -118,7 +118,7 @@ func (d *DB) GetShiftsInRange(ctx context.Context, from, to time.Time) ([]ShiftI
 	for rows.Next() {
 		var s ShiftInRange
 		var date, startAt, endAt time.Time
-		if err := rows.Scan(&s.ID, &date, &s.RotaID, &s.Closed, &startAt, &endAt, &s.Allocated); err != nil {
+		if err := rows.Scan(&s.ID, &date, &s.RotaID, &s.Closed, &s.ClosureChanges, &startAt, &endAt, &s.Allocated); err != nil {
 			return nil, fmt.Errorf("failed to scan shift: %w", err)
 		}
 		s.Date = date.Format("2006-01-02")
@@ -160,10 +160,10 @@ func (d *DB) GetShiftByDate(ctx context.Context, date time.Time) (*Shift, error)
 	var s Shift
 	var d0, startAt, endAt time.Time
 	err := d.pool.QueryRow(ctx, `
-		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.start_at, s.end_at
+		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.closure_changes, s.start_at, s.end_at
 		FROM shift s
 		WHERE `+shiftDateExpr+` = $1
-	`, date).Scan(&s.ID, &d0, &s.RotaID, &s.Closed, &startAt, &endAt)
+	`, date).Scan(&s.ID, &d0, &s.RotaID, &s.Closed, &s.ClosureChanges, &startAt, &endAt)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -183,11 +183,11 @@ func (d *DB) GetShiftByID(ctx context.Context, id string) (*ShiftInRange, error)
 	var s ShiftInRange
 	var date, startAt, endAt time.Time
 	err := d.pool.QueryRow(ctx, `
-		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.start_at, s.end_at, r.allocated_datetime IS NOT NULL
+		SELECT s.id, `+shiftDateExpr+`, s.rota_id, s.closed, s.closure_changes, s.start_at, s.end_at, r.allocated_datetime IS NOT NULL
 		FROM shift s
 		JOIN rotation r ON r.id = s.rota_id
 		WHERE s.id = $1
-	`, id).Scan(&s.ID, &date, &s.RotaID, &s.Closed, &startAt, &endAt, &s.Allocated)
+	`, id).Scan(&s.ID, &date, &s.RotaID, &s.Closed, &s.ClosureChanges, &startAt, &endAt, &s.Allocated)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
@@ -201,14 +201,20 @@ func (d *DB) GetShiftByID(ctx context.Context, id string) (*ShiftInRange, error)
 
 // setShiftClosed writes a shift's closed flag, reporting whether a row matched.
 // It carries no freeze check of its own: the caller holds the rota's row lock
-// and has already established that the rota is unallocated.
+// and has already established that the rota is unallocated. A flag that moves
+// is counted in closure_changes; one set to what it already was is not.
 //
 // Whether the drop-in runs that day is an allocator input, so the rota's draft
 // is stamped stale. Closing a Shift that was already closed stamps it too: the
 // alternative is a read-before-write to find out, and the cost of being wrong
 // is one re-solve.
 func setShiftClosed(ctx context.Context, q querier, id string, closed bool) (bool, error) {
-	tag, err := q.Exec(ctx, `UPDATE shift SET closed = $2 WHERE id = $1`, id, closed)
+	tag, err := q.Exec(ctx, `
+		UPDATE shift
+		SET closed = $2,
+		    closure_changes = closure_changes + CASE WHEN closed = $2 THEN 0 ELSE 1 END
+		WHERE id = $1
+	`, id, closed)
 	if err != nil {
 		return false, fmt.Errorf("failed to set closed on shift %s: %w", id, err)
 	}
@@ -181,6 +181,30 @@ func TestShiftClosedRoundTrips(t *testing.T) {
 	assert.True(t, byDate.Closed)
 }
 
+// TestShiftClosureChangesCount checks that closing and reopening are each
+// counted, and that setting the flag to what it already is is not: the count
+// numbers the calendar feeds' events, so it must only move when the flag does.
+func TestShiftClosureChangesCount(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+
+	rota := &db.Rotation{ID: uuid.New().String()}
+	shift := dbtest.Shift(rota.ID, "2026-12-27")
+	require.NoError(t, database.InsertDefinedRota(ctx, rota, []db.Shift{shift}, nil, nil))
+
+	for _, closed := range []bool{true, true, false} {
+		require.NoError(t, database.WithRotaShiftLock(ctx, []string{rota.ID}, func(tx db.ShiftTxStore) error {
+			_, err := tx.SetShiftClosed(ctx, shift.ID, closed)
+			return err
+		}))
+	}
+
+	got, err := database.GetShiftByID(ctx, shift.ID)
+	require.NoError(t, err)
+	assert.False(t, got.Closed)
+	assert.Equal(t, 2, got.ClosureChanges, "closed once, closed again to no effect, reopened once")
+}
+
 // TestGetShiftByIDUnknownReturnsNil keeps "no such shift" distinguishable from
 // a failure, which is what lets the close/reopen flow answer 404 rather than
 // 500 for an id that never existed.
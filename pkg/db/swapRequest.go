@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDuplicateSwapRequest reports that the volunteer has already asked to drop
+// that shift and the ask is still live. Named for the reason
+// ErrDuplicateRoleName is: asking twice is an ordinary mistake, and telling it
+// apart from a failure is this package's job.
+var ErrDuplicateSwapRequest = errors.New("you have already asked to swap that shift")
+
+// ErrSwapRequestNotTaken reports a swap settled after it stopped being taken:
+// the asker withdrew, or an admin declined, between the take and the change.
+var ErrSwapRequestNotTaken = errors.New("that swap is no longer waiting to be made")
+
+func isDuplicateSwapRequest(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) &&
+		pgErr.Code == uniqueViolation &&
+		pgErr.ConstraintName == "idx_swap_request_live"
+}
+
+const swapRequestColumns = `
+	id, shift_id, volunteer_id, role, status, requested_at,
+	taken_by, taken_at, decided_by, decided_at, cover_id`
+
+func scanSwapRequest(row rowScanner) (SwapRequest, error) {
+	var req SwapRequest
+	var role, takenBy, decidedBy, coverID *string
+	if err := row.Scan(
+		&req.ID, &req.ShiftID, &req.VolunteerID, &role, &req.Status, &req.RequestedAt,
+		&takenBy, &req.TakenAt, &decidedBy, &req.DecidedAt, &coverID,
+	); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return req, err
+		}
+		return req, fmt.Errorf("failed to scan swap request: %w", err)
+	}
+	req.Role = deref(role)
+	req.TakenBy = deref(takenBy)
+	req.DecidedBy = deref(decidedBy)
+	req.CoverID = deref(coverID)
+	return req, nil
+}
+
+// InsertSwapRequest records a volunteer asking to drop a shift, reporting a
+// second live ask for the same place as ErrDuplicateSwapRequest.
+func (d *DB) InsertSwapRequest(ctx context.Context, req SwapRequest) error {
+	_, err := d.pool.Exec(ctx, `
+		INSERT INTO swap_request (id, shift_id, volunteer_id, role, status)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+	`, req.ID, req.ShiftID, req.VolunteerID, req.Role, req.Status)
+	if err != nil {
+		if isDuplicateSwapRequest(err) {
+			return ErrDuplicateSwapRequest
+		}
+		return fmt.Errorf("failed to insert swap request: %w", err)
+	}
+	return nil
+}
+
+// GetSwapRequest reads one, or nil when there is no such request.
+func (d *DB) GetSwapRequest(ctx context.Context, id string) (*SwapRequest, error) {
+	req, err := scanSwapRequest(d.pool.QueryRow(ctx, `
+		SELECT `+swapRequestColumns+` FROM swap_request WHERE id = $1
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// GetSwapRequestsByStatus reads every request in one of the given states,
+// oldest first: the order they were asked in is the order they are offered in.
+func (d *DB) GetSwapRequestsByStatus(ctx context.Context, statuses []string) ([]SwapRequest, error) {
+	rows, err := d.pool.Query(ctx, `
+		SELECT `+swapRequestColumns+` FROM swap_request
+		WHERE status = ANY($1)
+		ORDER BY requested_at, id
+	`, statuses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query swap requests: %w", err)
+	}
+	defer rows.Close()
+
+	var out []SwapRequest
+	for rows.Next() {
+		req, err := scanSwapRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, req)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating swap requests: %w", err)
+	}
+	return out, nil
+}
+
+// TakeSwapRequest hands an open request to the volunteer taking it, reporting
+// whether they got it. Conditional on the request still being open, so of two
+// volunteers accepting at once exactly one does.
+func (d *DB) TakeSwapRequest(ctx context.Context, id, volunteerID string) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE swap_request
+		SET status = 'taken', taken_by = $2, taken_at = NOW()
+		WHERE id = $1 AND status = 'open'
+	`, id, volunteerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to take swap request %s: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReopenSwapRequest puts a taken request back on offer, for a take the rota
+// then refused — the volunteer it was handed to is the one who could not have
+// it, and anybody else still might.
+func (d *DB) ReopenSwapRequest(ctx context.Context, id string) error {
+	_, err := d.pool.Exec(ctx, `
+		UPDATE swap_request
+		SET status = 'open', taken_by = NULL, taken_at = NULL
+		WHERE id = $1 AND status = 'taken'
+	`, id)
+	if err != nil {
+		return fmt.Errorf("failed to reopen swap request %s: %w", id, err)
+	}
+	return nil
+}
+
+// DeclineSwapRequest records an admin refusing a taken swap, reporting whether
+// there was a taken request to refuse.
+func (d *DB) DeclineSwapRequest(ctx context.Context, id, adminEmail string) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE swap_request
+		SET status = 'declined', decided_by = $2, decided_at = NOW()
+		WHERE id = $1 AND status = 'taken'
+	`, id, adminEmail)
+	if err != nil {
+		return false, fmt.Errorf("failed to decline swap request %s: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// WithdrawSwapRequest records the asker changing their mind, reporting whether
+// there was a live request of theirs to withdraw. Only theirs: the volunteer is
+// part of the condition, so one volunteer's link cannot withdraw another's.
+func (d *DB) WithdrawSwapRequest(ctx context.Context, id, volunteerID string) (bool, error) {
+	tag, err := d.pool.Exec(ctx, `
+		UPDATE swap_request
+		SET status = 'withdrawn'
+		WHERE id = $1 AND volunteer_id = $2 AND status IN ('open', 'taken')
+	`, id, volunteerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to withdraw swap request %s: %w", id, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// completeSwapRequest marks a taken request done with the Cover that settled
+// it. Reachable only through WithRotaLock, like the Cover's own insert: the
+// two land in one transaction, so a swap can never be on the rota and still
+// look unsettled, or be settled twice. A request no longer taken — withdrawn
+// or declined in the meantime — fails the whole change.
+func completeSwapRequest(ctx context.Context, q querier, id, coverID string) error {
+	tag, err := q.Exec(ctx, `
+		UPDATE swap_request
+		SET status = 'done', cover_id = $2
+		WHERE id = $1 AND status = 'taken'
+	`, id, coverID)
+	if err != nil {
+		return fmt.Errorf("failed to complete swap request %s: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrSwapRequestNotTaken
+	}
+	return nil
+}
@@ -50,6 +50,12 @@ type RotaChangeStore interface {
 	GetAllocationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]Allocation, error)
 	GetAlterationsByShiftIDs(ctx context.Context, shiftIDs []string) ([]Alteration, error)
 	InsertCoverAndAlterations(ctx context.Context, cover *Cover, alterations []Alteration) error
+	// CompleteSwapRequest marks the volunteer's swap request a change settles
+	// as done, in the change's own transaction.
+	CompleteSwapRequest(ctx context.Context, id, coverID string) error
+	// FillCoverRequest marks the cover request a change answers as filled,
+	// in the change's own transaction.
+	FillCoverRequest(ctx context.Context, id, coverID, adminEmail string) error
 }
 
 // WithRotaLock runs fn inside a transaction that first locks the given
@@ -195,6 +201,14 @@ func (r *rotaTx) InsertCoverAndAlterations(ctx context.Context, cover *Cover, al
 	return insertCoverAndAlterations(ctx, r.tx, cover, alterations)
 }
 
+func (r *rotaTx) CompleteSwapRequest(ctx context.Context, id, coverID string) error {
+	return completeSwapRequest(ctx, r.tx, id, coverID)
+}
+
+func (r *rotaTx) FillCoverRequest(ctx context.Context, id, coverID, adminEmail string) error {
+	return fillCoverRequest(ctx, r.tx, id, coverID, adminEmail)
+}
+
 func (r *rotaTx) RotaAllocated(ctx context.Context, rotaID string) (bool, error) {
 	return rotaAllocated(ctx, r.tx, rotaID)
 }
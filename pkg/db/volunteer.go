@@ -0,0 +1,275 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDuplicateVolunteerID reports a write that would have given two volunteers
+// one id. Named for the reason ErrDuplicateRoleName is: it is an admin's
+// mistake to be told about, not a failure.
+var ErrDuplicateVolunteerID = errors.New("a volunteer with that id already exists")
+
+// ErrRosterNotEmpty reports an import into a roster that already has somebody
+// on it. An import is the one-shot move off the sheet; run again, it would
+// overwrite whatever has been edited here since, and nothing could tell an
+// edit from a stale cell.
+var ErrRosterNotEmpty = errors.New("the roster already has volunteers on it")
+
+// ErrVolunteerHasHistory reports a delete of somebody a rota, a request or a
+// record names. Those are read by volunteer id, so removing them would leave
+// rows naming nobody — a pin to nobody fails the next solve outright; marking
+// them Left is how somebody comes off the roster.
+var ErrVolunteerHasHistory = errors.New("that volunteer is on a rota")
+
+// volunteerHistory is every column that records something that happened to, or
+// was asked of, a volunteer: a rota they are on or pinned to, a draft of one,
+// an availability request or send, a swap or cover, attendance. Any of them
+// refuses a delete.
+var volunteerHistory = []struct{ table, column string }{
+	{"allocation", "volunteer_id"},
+	{"alteration", "volunteer_id"},
+	{"preallocation", "volunteer_id"},
+	{"draft_allocation", "volunteer_id"},
+	{"availability_request", "volunteer_id"},
+	{"availability_send", "volunteer_id"},
+	{"availability_send_outcome", "volunteer_id"},
+	{"swap_request", "volunteer_id"},
+	{"swap_request", "taken_by"},
+	{"cover_request", "replacing"},
+	{"cover_request_token", "volunteer_id"},
+	{"attendance", "volunteer_id"},
+}
+
+// volunteerHistoryQuery asks whether any of volunteerHistory names $1.
+var volunteerHistoryQuery = func() string {
+	checks := make([]string, 0, len(volunteerHistory))
+	for _, h := range volunteerHistory {
+		checks = append(checks, fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE %s = $1)", h.table, h.column))
+	}
+	return "SELECT " + strings.Join(checks, "\n    OR ")
+}()
+
+// volunteerArrangements are the standing rows about a volunteer that mean
+// nothing without them — the pairs they are in, the pins every rota seeds for
+// them, the days they are away, their calendar link — and go with them.
+var volunteerArrangements = []string{
+	`DELETE FROM pairing_rule WHERE volunteer_a = $1 OR volunteer_b = $1`,
+	`DELETE FROM standing_preallocation WHERE volunteer_id = $1`,
+	`DELETE FROM volunteer_absence WHERE volunteer_id = $1`,
+	`DELETE FROM calendar_token WHERE volunteer_id = $1`,
+}
+
+func isDuplicateVolunteerID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) &&
+		pgErr.Code == uniqueViolation &&
+		pgErr.ConstraintName == "volunteer_pkey"
+}
+
+// volunteerSelect reads a volunteer with the Roles they hold folded into one
+// array, so the roster is one query however many Roles there are.
+const volunteerSelect = `
+	SELECT v.id, v.first_name, v.last_name, v.status, v.gender, v.email, v.group_key,
+	       COALESCE(array_agg(vr.role_id::text ORDER BY vr.role_id) FILTER (WHERE vr.role_id IS NOT NULL), '{}')
+	FROM volunteer v
+	LEFT JOIN volunteer_role vr ON vr.volunteer_id = v.id
+`
+
+func scanVolunteer(row rowScanner) (Volunteer, error) {
+	var v Volunteer
+	if err := row.Scan(&v.ID, &v.FirstName, &v.LastName, &v.Status, &v.Gender, &v.Email, &v.GroupKey, &v.RoleIDs); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return v, err
+		}
+		return v, fmt.Errorf("failed to scan volunteer: %w", err)
+	}
+	return v, nil
+}
+
+// GetVolunteers reads the whole roster, ordered by id. An empty roster is an
+// ordinary answer: it is what a deployment looks like before its import.
+func (d *DB) GetVolunteers(ctx context.Context) ([]Volunteer, error) {
+	rows, err := d.pool.Query(ctx, volunteerSelect+`
+		GROUP BY v.id
+		ORDER BY v.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query volunteers: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Volunteer
+	for rows.Next() {
+		v, err := scanVolunteer(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating volunteers: %w", err)
+	}
+	return out, nil
+}
+
+// GetVolunteerByID reads one, or nil when there is nobody with that id.
+func (d *DB) GetVolunteerByID(ctx context.Context, id string) (*Volunteer, error) {
+	v, err := scanVolunteer(d.pool.QueryRow(ctx, volunteerSelect+`
+		WHERE v.id = $1
+		GROUP BY v.id
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// InsertVolunteer adds one to the roster, reporting an id already taken as
+// ErrDuplicateVolunteerID.
+//
+// Who is on the roster, which Roles they hold and which group they are in are
+// all allocator inputs, so every write here marks the rota in flight's draft
+// as having moved — which the sheet, with no change notification, never could.
+func (d *DB) InsertVolunteer(ctx context.Context, volunteer Volunteer) error {
+	return d.inTx(ctx, func(tx pgx.Tx) error {
+		if err := insertVolunteer(ctx, tx, volunteer); err != nil {
+			return err
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+}
+
+// UpdateVolunteer rewrites every field of one volunteer, and the Roles they
+// hold, addressed by id. The id itself never moves. The bool reports whether
+// anybody matched.
+func (d *DB) UpdateVolunteer(ctx context.Context, volunteer Volunteer) (bool, error) {
+	var written bool
+	err := d.inTx(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `
+			UPDATE volunteer
+			SET first_name = $2, last_name = $3, status = $4, gender = $5, email = $6, group_key = $7,
+			    updated_at = now()
+			WHERE id = $1
+		`, volunteer.ID, volunteer.FirstName, volunteer.LastName, volunteer.Status,
+			volunteer.Gender, volunteer.Email, volunteer.GroupKey)
+		if err != nil {
+			return fmt.Errorf("failed to update volunteer %s: %w", volunteer.ID, err)
+		}
+		written = tag.RowsAffected() > 0
+		if !written {
+			return nil
+		}
+		if err := writeVolunteerRoles(ctx, tx, volunteer.ID, volunteer.RoleIDs); err != nil {
+			return err
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+	if err != nil {
+		return false, err
+	}
+	return written, nil
+}
+
+// DeleteVolunteerByID removes somebody nobody's rota names, reporting whether
+// there was anybody to remove. Anybody volunteerHistory names is refused with
+// ErrVolunteerHasHistory; their volunteerArrangements are deleted with them,
+// in the same transaction.
+func (d *DB) DeleteVolunteerByID(ctx context.Context, id string) (bool, error) {
+	var deleted bool
+	err := d.inTx(ctx, func(tx pgx.Tx) error {
+		var named bool
+		if err := tx.QueryRow(ctx, volunteerHistoryQuery, id).Scan(&named); err != nil {
+			return fmt.Errorf("failed to check the history of volunteer %s: %w", id, err)
+		}
+		if named {
+			return fmt.Errorf("failed to delete volunteer %s: %w", id, ErrVolunteerHasHistory)
+		}
+
+		tag, err := tx.Exec(ctx, `DELETE FROM volunteer WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete volunteer %s: %w", id, err)
+		}
+		deleted = tag.RowsAffected() > 0
+		if !deleted {
+			return nil
+		}
+		for _, statement := range volunteerArrangements {
+			if _, err := tx.Exec(ctx, statement, id); err != nil {
+				return fmt.Errorf("failed to delete what names volunteer %s: %w", id, err)
+			}
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// ImportVolunteers fills an empty roster in one transaction: everybody or
+// nobody. A roster with anybody on it is refused with ErrRosterNotEmpty, and
+// the table is locked while that is checked so two imports cannot both find it
+// empty.
+func (d *DB) ImportVolunteers(ctx context.Context, volunteers []Volunteer) error {
+	return d.inTx(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `LOCK TABLE volunteer IN EXCLUSIVE MODE`); err != nil {
+			return fmt.Errorf("failed to lock the roster for import: %w", err)
+		}
+		var existing bool
+		if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM volunteer)`).Scan(&existing); err != nil {
+			return fmt.Errorf("failed to check the roster is empty: %w", err)
+		}
+		if existing {
+			return fmt.Errorf("failed to import volunteers: %w", ErrRosterNotEmpty)
+		}
+
+		for _, v := range volunteers {
+			if err := insertVolunteer(ctx, tx, v); err != nil {
+				return err
+			}
+		}
+		return markAllRotaInputsChanged(ctx, tx)
+	})
+}
+
+func insertVolunteer(ctx context.Context, tx pgx.Tx, volunteer Volunteer) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO volunteer (id, first_name, last_name, status, gender, email, group_key)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, volunteer.ID, volunteer.FirstName, volunteer.LastName, volunteer.Status,
+		volunteer.Gender, volunteer.Email, volunteer.GroupKey)
+	if err != nil {
+		if isDuplicateVolunteerID(err) {
+			return fmt.Errorf("failed to insert volunteer %s: %w", volunteer.ID, ErrDuplicateVolunteerID)
+		}
+		return fmt.Errorf("failed to insert volunteer %s: %w", volunteer.ID, err)
+	}
+	return writeVolunteerRoles(ctx, tx, volunteer.ID, volunteer.RoleIDs)
+}
+
+// writeVolunteerRoles replaces the Roles one volunteer holds with roleIDs.
+func writeVolunteerRoles(ctx context.Context, tx pgx.Tx, volunteerID string, roleIDs []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM volunteer_role WHERE volunteer_id = $1`, volunteerID); err != nil {
+		return fmt.Errorf("failed to clear the roles of volunteer %s: %w", volunteerID, err)
+	}
+	if len(roleIDs) == 0 {
+		return nil
+	}
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO volunteer_role (volunteer_id, role_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, volunteerID, roleIDs); err != nil {
+		return fmt.Errorf("failed to write the roles of volunteer %s: %w", volunteerID, err)
+	}
+	return nil
+}
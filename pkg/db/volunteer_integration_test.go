@@ -0,0 +1,164 @@
+package db_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/jakechorley/ilford-drop-in/pkg/db"
+	"github.com/jakechorley/ilford-drop-in/pkg/db/dbtest"
+)
+
+func TestVolunteerInsertReadUpdateDelete(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	dbtest.SeedRoles(t, database)
+	roles, err := database.ListRoles(ctx)
+	require.NoError(t, err)
+	lead, service := roles[0].ID, roles[1].ID
+
+	require.NoError(t, database.InsertVolunteer(ctx, db.Volunteer{
+		ID: "alice", FirstName: "Alice", LastName: "Smith", Status: "Active",
+		Gender: "Female", Email: "alice@example.com", GroupKey: "smiths", RoleIDs: []string{service, lead},
+	}))
+	require.NoError(t, database.InsertVolunteer(ctx, db.Volunteer{ID: "bob", FirstName: "Bob", Status: "Onboarding"}))
+
+	err = database.InsertVolunteer(ctx, db.Volunteer{ID: "bob", FirstName: "Robert", Status: "Active"})
+	require.ErrorIs(t, err, db.ErrDuplicateVolunteerID)
+
+	all, err := database.GetVolunteers(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "alice", all[0].ID)
+	assert.ElementsMatch(t, []string{lead, service}, all[0].RoleIDs)
+	assert.Equal(t, "smiths", all[0].GroupKey)
+	assert.Equal(t, []string{}, all[1].RoleIDs, "somebody holding no Role has an empty list, not nil")
+
+	written, err := database.UpdateVolunteer(ctx, db.Volunteer{
+		ID: "alice", FirstName: "Alice", LastName: "Jones", Status: "Paused until 2026-12-01", RoleIDs: []string{service},
+	})
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	one, err := database.GetVolunteerByID(ctx, "alice")
+	require.NoError(t, err)
+	require.NotNil(t, one)
+	assert.Equal(t, "Jones", one.LastName)
+	assert.Equal(t, "Paused until 2026-12-01", one.Status)
+	assert.Empty(t, one.GroupKey)
+	assert.Equal(t, []string{service}, one.RoleIDs, "the Roles are replaced, not added to")
+
+	written, err = database.UpdateVolunteer(ctx, db.Volunteer{ID: "nobody", FirstName: "No", Status: "Active"})
+	require.NoError(t, err)
+	assert.False(t, written)
+
+	deleted, err := database.DeleteVolunteerByID(ctx, "bob")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = database.DeleteVolunteerByID(ctx, "bob")
+	require.NoError(t, err)
+	assert.False(t, deleted, "a second delete reports that nothing matched")
+
+	one, err = database.GetVolunteerByID(ctx, "bob")
+	require.NoError(t, err)
+	assert.Nil(t, one)
+}
+
+// Somebody a past rota names stays on the roster: the rota reads them by id.
+func TestDeleteVolunteerRefusesSomebodyOnARota(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	dbtest.SeedRoles(t, database)
+	rota := db.Rotation{ID: uuid.New().String()}
+	shift := dbtest.Shift(rota.ID, "2026-08-02")
+	require.NoError(t, database.InsertDefinedRota(ctx, &rota, []db.Shift{shift}, nil, nil))
+	require.NoError(t, database.InsertVolunteer(ctx, db.Volunteer{ID: "alice", FirstName: "Alice", Status: "Active"}))
+	require.NoError(t, database.InsertAllocationsAndSetAllocated(ctx, []db.Allocation{
+		{ID: uuid.New().String(), ShiftID: shift.ID, Role: "Team lead", VolunteerID: "alice"},
+	}, rota.ID, time.Now().UTC()))
+
+	_, err := database.DeleteVolunteerByID(ctx, "alice")
+	require.ErrorIs(t, err, db.ErrVolunteerHasHistory)
+
+	one, err := database.GetVolunteerByID(ctx, "alice")
+	require.NoError(t, err)
+	assert.NotNil(t, one)
+}
+
+// An import fills an empty roster whole, and refuses one that is not empty.
+func TestImportVolunteers(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	dbtest.SeedRoles(t, database)
+	roles, err := database.ListRoles(ctx)
+	require.NoError(t, err)
+
+	err = database.ImportVolunteers(ctx, []db.Volunteer{
+		{ID: "alice", FirstName: "Alice", Status: "Active", RoleIDs: []string{roles[0].ID}},
+		{ID: "alice", FirstName: "Alicia", Status: "Active"},
+	})
+	require.ErrorIs(t, err, db.ErrDuplicateVolunteerID)
+	all, err := database.GetVolunteers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, all, "a failed import writes nobody")
+
+	require.NoError(t, database.ImportVolunteers(ctx, []db.Volunteer{
+		{ID: "alice", FirstName: "Alice", Status: "Active", RoleIDs: []string{roles[0].ID}},
+		{ID: "bob", FirstName: "Bob", Status: "Left"},
+	}))
+	all, err = database.GetVolunteers(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	err = database.ImportVolunteers(ctx, []db.Volunteer{{ID: "carol", FirstName: "Carol", Status: "Active"}})
+	require.ErrorIs(t, err, db.ErrRosterNotEmpty)
+}
+
+// A pin is a rota naming somebody as surely as an allocation is: one left
+// pointing at nobody fails the next solve.
+func TestDeleteVolunteerRefusesSomebodyPinned(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	dbtest.SeedRoles(t, database)
+	roles, err := database.ListRoles(ctx)
+	require.NoError(t, err)
+
+	rota := db.Rotation{ID: uuid.New().String()}
+	shift := dbtest.Shift(rota.ID, "2026-08-02")
+	require.NoError(t, database.InsertVolunteer(ctx, db.Volunteer{ID: "alice", FirstName: "Alice", Status: "Active"}))
+	require.NoError(t, database.InsertDefinedRota(ctx, &rota, []db.Shift{shift}, []db.Preallocation{
+		{ID: uuid.New().String(), ShiftID: shift.ID, RoleID: roles[0].ID, VolunteerID: "alice"},
+	}, nil))
+
+	_, err = database.DeleteVolunteerByID(ctx, "alice")
+	require.ErrorIs(t, err, db.ErrVolunteerHasHistory)
+
+	one, err := database.GetVolunteerByID(ctx, "alice")
+	require.NoError(t, err)
+	assert.NotNil(t, one)
+}
+
+// A Pairing Rule is about the two people in it, so it goes when one of them
+// does, rather than pairing the other with nobody.
+func TestDeleteVolunteerTakesTheirPairingRules(t *testing.T) {
+	database, _ := dbtest.New(t)
+	ctx := context.Background()
+	require.NoError(t, database.InsertVolunteer(ctx, db.Volunteer{ID: "alice", FirstName: "Alice", Status: "Active"}))
+	require.NoError(t, database.InsertVolunteer(ctx, db.Volunteer{ID: "bob", FirstName: "Bob", Status: "Active"}))
+	require.NoError(t, database.InsertPairingRule(ctx, db.PairingRule{
+		ID: uuid.New().String(), VolunteerA: "alice", VolunteerB: "bob", Kind: "apart",
+	}))
+
+	deleted, err := database.DeleteVolunteerByID(ctx, "bob")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	rules, err := database.GetPairingRules(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
@@ -0,0 +1,75 @@
+package pdf
+
+import "strings"
+
+// The advance widths of the printable ASCII characters, space to tilde, in
+// thousandths of the font size, from Adobe's metrics for the two faces. A
+// reader supplies the fonts, so these are all the writer needs to know of
+// them to lay a line out.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
+
+// extraWidths are the WinAnsi characters outside ASCII that are not roughly
+// the width of a letter.
+var extraWidths = map[byte]int{
+	0x85: 1000, // ellipsis
+	0x91: 222,  // quoteleft
+	0x92: 222,  // quoteright
+	0x93: 333,  // quotedblleft
+	0x94: 333,  // quotedblright
+	0x95: 350,  // bullet
+	0x97: 1000, // emdash
+}
+
+// TextWidth is how wide s is set in font at size, in points. Accented letters
+// are taken to be as wide as a lowercase letter, which is near enough to fit
+// a name in a box.
+func TextWidth(font Font, size float64, s string) float64 {
+	widths := &helveticaWidths
+	if font == HelveticaBold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	for _, c := range encode(s) {
+		switch {
+		case c >= 32 && c <= 126:
+			total += widths[c-32]
+		case extraWidths[c] != 0:
+			total += extraWidths[c]
+		default:
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Fit is s cut short with an ellipsis, if that is what it takes to fit in
+// width at size. A cell that cannot hold even the ellipsis gets nothing.
+func Fit(font Font, size, width float64, s string) string {
+	if TextWidth(font, size, s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for n := len(runes) - 1; n > 0; n-- {
+		cut := strings.TrimRight(string(runes[:n]), " ") + "…"
+		if TextWidth(font, size, cut) <= width {
+			return cut
+		}
+	}
+	return ""
+}
@@ -0,0 +1,262 @@
+// Package pdf writes plain PDF documents: pages of text, lines and filled
+// boxes, set in the two Helvetica faces every PDF reader carries.
+//
+// It is the least of the format a printed rota needs. Nothing is embedded —
+// Helvetica is one of the standard fonts a reader must supply itself — so
+// the writer is a few hundred lines of the standard library rather than a PDF
+// library and the font files that come with one. The price is the character
+// set: text is written in WinAnsiEncoding, which covers the Latin alphabets a
+// volunteer's name is likely to be spelled in, and anything outside it prints
+// as a question mark.
+//
+// Coordinates are in points from the top left of the page, y growing down,
+// because that is how a page is laid out by whoever is writing one. The
+// writer turns them the right way up for the file.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A4 in points, portrait. Swap them for landscape.
+const (
+	A4Width  = 595.28
+	A4Height = 841.89
+)
+
+// Font is one of the faces text can be set in.
+type Font int
+
+const (
+	Helvetica Font = iota
+	HelveticaBold
+)
+
+// resourceName is how a page's content names the font.
+func (f Font) resourceName() string {
+	if f == HelveticaBold {
+		return "F2"
+	}
+	return "F1"
+}
+
+// Colour is an RGB colour, each channel 0 to 1.
+type Colour struct {
+	R, G, B float64
+}
+
+var (
+	Black = Colour{}
+	White = Colour{R: 1, G: 1, B: 1}
+)
+
+// Hex reads a colour written as CSS writes one, "#a233f5".
+func Hex(s string) (Colour, error) {
+	if len(s) != 7 || s[0] != '#' {
+		return Colour{}, fmt.Errorf("colour %q is not #rrggbb", s)
+	}
+	v, err := strconv.ParseUint(s[1:], 16, 32)
+	if err != nil {
+		return Colour{}, fmt.Errorf("colour %q is not #rrggbb", s)
+	}
+	return Colour{
+		R: float64(v>>16&0xff) / 255,
+		G: float64(v>>8&0xff) / 255,
+		B: float64(v&0xff) / 255,
+	}, nil
+}
+
+// Mix is c moved towards other by amount, 0 being c and 1 other: a tint of a
+// colour is the colour mixed with white.
+func (c Colour) Mix(other Colour, amount float64) Colour {
+	return Colour{
+		R: c.R + (other.R-c.R)*amount,
+		G: c.G + (other.G-c.G)*amount,
+		B: c.B + (other.B-c.B)*amount,
+	}
+}
+
+// Document is a PDF being put together a page at a time. Every page is the
+// size the document was made with.
+type Document struct {
+	// Title is what a reader shows in its title bar in place of the file name.
+	Title  string
+	width  float64
+	height float64
+	pages  []*Page
+}
+
+// New starts a document of pages width by height points.
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height}
+}
+
+// AddPage adds a blank page to the end of the document and returns it to be
+// drawn on.
+func (d *Document) AddPage() *Page {
+	p := &Page{height: d.height}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Page is one page's drawing, kept as the content stream it becomes.
+type Page struct {
+	height  float64
+	content bytes.Buffer
+}
+
+// Text sets s with its baseline at y, starting at x.
+func (p *Page) Text(x, y float64, font Font, size float64, colour Colour, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %s Tf %s rg %s %s Td (%s) Tj ET\n",
+		font.resourceName(), num(size), rgb(colour), num(x), num(p.height-y), literal(s))
+}
+
+// FillRect fills the box whose top left corner is at x, y.
+func (p *Page) FillRect(x, y, w, h float64, colour Colour) {
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n",
+		rgb(colour), num(x), num(p.height-y-h), num(w), num(h))
+}
+
+// StrokeRect outlines the box whose top left corner is at x, y.
+func (p *Page) StrokeRect(x, y, w, h, lineWidth float64, colour Colour) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s %s %s re S\n",
+		rgb(colour), num(lineWidth), num(x), num(p.height-y-h), num(w), num(h))
+}
+
+// Line draws a straight line from x1, y1 to x2, y2.
+func (p *Page) Line(x1, y1, x2, y2, lineWidth float64, colour Colour) {
+	fmt.Fprintf(&p.content, "%s RG %s w %s %s m %s %s l S\n",
+		rgb(colour), num(lineWidth), num(x1), num(p.height-y1), num(x2), num(p.height-y2))
+}
+
+// Write writes the document. A document with no pages gets one blank one,
+// because a PDF of none is not one every reader will open.
+func (d *Document) Write(w io.Writer) error {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	// Objects 1 to 5 are fixed; each page is then a page object followed by
+	// its content stream.
+	const firstPage = 6
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		pagesObject(len(d.pages), firstPage),
+		fontObject("Helvetica"),
+		fontObject("Helvetica-Bold"),
+		fmt.Sprintf("<< /Title (%s) /Producer (ilford-drop-in) >>", literal(d.Title)),
+	}
+	for i, page := range d.pages {
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+				"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				num(d.width), num(d.height), firstPage+2*i+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()),
+		)
+	}
+
+	var out bytes.Buffer
+	// The second line is the binary comment the format recommends, so a tool
+	// guessing at the file does not take it for text.
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	if _, err := out.WriteTo(w); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+func pagesObject(count, first int) string {
+	kids := make([]string, count)
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", first+2*i)
+	}
+	return fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), count)
+}
+
+func fontObject(base string) string {
+	return "<< /Type /Font /Subtype /Type1 /BaseFont /" + base + " /Encoding /WinAnsiEncoding >>"
+}
+
+// num writes a number as briefly as the format allows, to two places.
+func num(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+func rgb(c Colour) string {
+	return num(c.R) + " " + num(c.G) + " " + num(c.B)
+}
+
+// literal is s as the inside of a PDF string: in WinAnsiEncoding, with the
+// three characters the format treats specially escaped.
+func literal(s string) string {
+	var b strings.Builder
+	for _, c := range encode(s) {
+		switch c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n', '\r':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// encode is s in WinAnsiEncoding. Latin-1 is WinAnsi's upper half bar a few
+// control characters, which Windows spent on typography; those are mapped
+// back by hand, and everything else becomes a question mark.
+func encode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			out = append(out, byte(r))
+		case r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		default:
+			if c, ok := winAnsiExtras[r]; ok {
+				out = append(out, c)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// winAnsiExtras are the characters WinAnsiEncoding puts where Latin-1 has
+// control codes — the ones anybody is likely to type, at least.
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80,
+	'…': 0x85,
+	'‘': 0x91,
+	'’': 0x92,
+	'“': 0x93,
+	'”': 0x94,
+	'•': 0x95,
+	'–': 0x96,
+	'—': 0x97,
+}
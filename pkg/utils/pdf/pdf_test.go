@@ -0,0 +1,93 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	doc := New(A4Width, A4Height)
+	doc.Title = "Rota (January)"
+	page := doc.AddPage()
+	page.Text(36, 50, HelveticaBold, 18, Black, `Sign-in \ sheet`)
+	page.FillRect(36, 60, 100, 20, White)
+	doc.AddPage()
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.Write(&buf))
+	out := buf.String()
+
+	assert.True(t, strings.HasPrefix(out, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(out, "%%EOF\n"))
+	assert.Contains(t, out, "/Count 2")
+	assert.Contains(t, out, `/Title (Rota \(January\))`)
+	assert.Contains(t, out, `BT /F2 18 Tf 0 0 0 rg 36 791.89 Td (Sign-in \\ sheet) Tj ET`, "y is measured from the top")
+	assert.Contains(t, out, "1 1 1 rg 36 761.89 100 20 re f")
+
+	// Every offset in the cross-reference table is where its object starts,
+	// which is what a reader seeks by.
+	xrefAt := strings.Index(out, "\nxref\n") + 1
+	xref := out[xrefAt:]
+	entries := regexp.MustCompile(`(\d{10}) 00000 n`).FindAllStringSubmatch(xref, -1)
+	require.Len(t, entries, 9, "five fixed objects and two per page")
+	for i, entry := range entries {
+		offset, err := strconv.Atoi(entry[1])
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(out[offset:], strconv.Itoa(i+1)+" 0 obj"), "object %d", i+1)
+	}
+	startxref := regexp.MustCompile(`startxref\n(\d+)`).FindStringSubmatch(out)
+	require.NotNil(t, startxref)
+	assert.Equal(t, strconv.Itoa(xrefAt), startxref[1])
+}
+
+func TestWriteAlwaysHasAPage(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, New(A4Width, A4Height).Write(&buf))
+	assert.Contains(t, buf.String(), "/Count 1")
+}
+
+func TestLiteral(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Alice", want: "Alice"},
+		{in: "(Bob)", want: `\(Bob\)`},
+		{in: "Zoë", want: "Zo\xeb"},
+		{in: "Mon – Sun", want: "Mon \x96 Sun"},
+		{in: "Łukasz", want: "?ukasz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.want, literal(tt.in))
+		})
+	}
+}
+
+func TestHex(t *testing.T) {
+	c, err := Hex("#ff8000")
+	require.NoError(t, err)
+	assert.Equal(t, Colour{R: 1, G: 128.0 / 255, B: 0}, c)
+
+	_, err = Hex("ff8000")
+	assert.Error(t, err)
+	_, err = Hex("#ff80zz")
+	assert.Error(t, err)
+}
+
+func TestTextWidthAndFit(t *testing.T) {
+	assert.InDelta(t, 5.56, TextWidth(Helvetica, 10, "a"), 0.001)
+	assert.Greater(t, TextWidth(HelveticaBold, 10, "Team lead"), TextWidth(Helvetica, 10, "Team lead"))
+
+	assert.Equal(t, "Alice", Fit(Helvetica, 10, 100, "Alice"))
+	cut := Fit(Helvetica, 10, 40, "Alexandra Konstantinou")
+	assert.True(t, strings.HasSuffix(cut, "…"))
+	assert.LessOrEqual(t, TextWidth(Helvetica, 10, cut), 40.0)
+	assert.Equal(t, "", Fit(Helvetica, 10, 2, "Alice"))
+}
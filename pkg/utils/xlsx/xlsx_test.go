@@ -0,0 +1,54 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readPart(t *testing.T, workbook []byte, name string) string {
+	t.Helper()
+	z, err := zip.NewReader(bytes.NewReader(workbook), int64(len(workbook)))
+	require.NoError(t, err)
+	f, err := z.Open(name)
+	require.NoError(t, err, name)
+	defer f.Close()
+	body, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, "Rota 2026/03", [][]string{
+		{"Date", "Team lead"},
+		{"Sun Mar 01 2026", "Alice & Bob", "", "<Carla>"},
+	}))
+
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels"} {
+		readPart(t, buf.Bytes(), part)
+	}
+	assert.Contains(t, readPart(t, buf.Bytes(), "xl/workbook.xml"), `name="Rota 2026-03"`, "a slash is not allowed in a sheet name")
+
+	sheet := readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	assert.Contains(t, sheet, `<c r="B1" t="inlineStr"><is><t xml:space="preserve">Team lead</t></is></c>`)
+	assert.Contains(t, sheet, "Alice &amp; Bob")
+	assert.NotContains(t, sheet, `r="C2"`, "an empty cell is left out")
+	assert.Contains(t, sheet, `r="D2"`)
+	assert.Contains(t, sheet, "&lt;Carla&gt;")
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		assert.Equal(t, want, ColumnName(index), index)
+	}
+}
+
+func TestCleanSheetName(t *testing.T) {
+	assert.Equal(t, "Sheet1", cleanSheetName(""))
+	assert.Len(t, []rune(cleanSheetName("A rota name far longer than Excel allows")), maxSheetName)
+}
@@ -12,6 +12,7 @@ from __future__ import annotations
 from typing import Sequence
 
 from .constraints import Constraint, constraints_for
+from .diagnosis import diagnose
 from .domain import AllocationInput, AllocationOutput, Diagnostics
 from .model_builder import build
 from .preferences import DEFAULT_PREFERENCES, Preference
@@ -38,7 +39,12 @@ def solve(
         return extract_solution(problem, built.x, result, built.constraints_applied)
 
     # INFEASIBLE (or UNKNOWN etc.) is a well-formed result: no rota, but
-    # not a crash. The Go side reports the status to the operator.
+    # not a crash. The Go side reports the status to the operator — and, for
+    # a proven INFEASIBLE, which rules and pins cannot all hold, since that
+    # is what the operator has to go and change.
+    conflict = None
+    if result.status == "INFEASIBLE":
+        conflict = diagnose(problem, constraints)
     return AllocationOutput(
         solver_status=result.status,
         success=False,
@@ -50,5 +56,6 @@ def solve(
             num_groups=len(problem.groups),
             num_variables=len(built.x),
             constraints_applied=built.constraints_applied,
+            conflict=conflict,
         ),
     )
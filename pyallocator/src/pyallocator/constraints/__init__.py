@@ -25,6 +25,7 @@ from . import (
     grouping,
     male_required,
     max_frequency,
+    never_together,
     no_back_to_back,
     no_duplicate_allocation,
     one_shift_per_month,
@@ -40,6 +41,7 @@ FUNDAMENTAL_CONSTRAINTS: list[Constraint] = [
     seat_capacity.CONSTRAINT,
     closed_shifts.CONSTRAINT,
     preallocations.CONSTRAINT,
+    never_together.CONSTRAINT,
 ]
 
 SWITCHABLE_CONSTRAINTS: list[Constraint] = [
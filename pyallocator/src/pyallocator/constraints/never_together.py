@@ -0,0 +1,32 @@
+"""Ensures two volunteers an admin has kept apart never share a shift.
+
+An "apart" Pairing Rule is most often a safeguarding decision, which is why
+this is fundamental rather than switchable: it is not a policy to trade for a
+fuller rota, and a pin does not override it — a pin that seats a pair together
+makes the run INFEASIBLE, and diagnosis names this rule alongside the pins.
+
+The rule is on the two volunteers, not their groups, but grouping carries it:
+a partner's group cannot work a shift the other's group does. Two members of
+one group kept apart can never work at all; Go refuses to record such a rule,
+and this does not second-guess one that arrives.
+"""
+
+from __future__ import annotations
+
+from ortools.sat.python import cp_model
+
+from ..problem import Problem
+from .base import Vars
+
+
+class NeverTogetherConstraint:
+    name = "never_together"
+    description = "two volunteers kept apart never work the same shift"
+
+    def apply(self, model: cp_model.CpModel, x: Vars, problem: Problem) -> None:
+        for a, b in problem.apart_pairs:
+            for shift in problem.shifts:
+                model.Add(x.attend[(a, shift.index)] + x.attend[(b, shift.index)] <= 1)
+
+
+CONSTRAINT = NeverTogetherConstraint()
@@ -0,0 +1,180 @@
+"""Explains an INFEASIBLE solve: which rules and which pins cannot all hold.
+
+"No rota is possible" is the answer an admin can do least with. It is almost
+always a handful of pins and one switchable rule that cannot live together —
+someone pinned to two consecutive Sundays under no_back_to_back, or two pins
+to a Role the shift has one Seat of — and finding which handful by hand means
+unpinning things until the solve goes through.
+
+This does that search instead. Every constraint is applied behind an
+assumption literal, and so is every volunteer pin, one literal each: a pin is
+the unit an admin can undo, whereas a rule's individual model.Add calls are
+not. CP-SAT reports a set of assumptions sufficient for infeasibility; that
+set is then shrunk one element at a time, re-solving without each in turn and
+keeping it only if the rest stop being infeasible without it. What is left is
+minimal — drop any one of its members and the conflict goes away — though not
+necessarily the smallest conflict there is.
+
+The structural rule (a person fills one Seat per shift) is not guarded: it can
+always be met by nobody working, so it is never the reason.
+"""
+
+from __future__ import annotations
+
+import time
+from dataclasses import dataclass
+from typing import Sequence
+
+from ortools.sat.python import cp_model
+
+from .constraints.base import Constraint, Vars
+from .domain import Conflict, ConflictPin
+from .model_builder import build
+from .problem import Problem
+
+# Each re-solve is a feasibility check with no objective, and conflicts among
+# pins are found in presolve, so these are generous. They keep a pathological
+# input from turning one infeasible solve into a minute of diagnosis: once the
+# budget is spent the conflict is reported as it stands, which is still a
+# conflict, only possibly not a minimal one.
+_CHECK_SECONDS = 2.0
+_BUDGET_SECONDS = 10.0
+
+
+# Compared by identity: a literal's == builds a constraint, not a bool.
+@dataclass(frozen=True, eq=False)
+class _Item:
+    """One thing that can be switched off: a constraint, or a single pin."""
+
+    literal: cp_model.IntVar
+    constraint: str = ""
+    pin: ConflictPin | None = None
+
+
+class _GuardedModel:
+    """A model whose constraints hold only while a literal is true.
+
+    Constraint modules are written against CpModel and know nothing about
+    diagnosis, so they are handed this instead: every Add and AddBoolOr is
+    enforced only if the literal is, and everything else — new variables
+    above all — goes straight through.
+    """
+
+    def __init__(self, model: cp_model.CpModel, literal: cp_model.IntVar) -> None:
+        self._model = model
+        self._literal = literal
+
+    def Add(self, ct):  # noqa: N802 - mirrors the CpModel method it stands in for
+        return self._model.Add(ct).OnlyEnforceIf(self._literal)
+
+    def AddBoolOr(self, literals):  # noqa: N802
+        return self._model.AddBoolOr(literals).OnlyEnforceIf(self._literal)
+
+    def __getattr__(self, name: str):
+        return getattr(self._model, name)
+
+
+def diagnose(problem: Problem, constraints: Sequence[Constraint]) -> Conflict | None:
+    """A minimal set of the run's rules and pins that cannot all hold.
+
+    None when no conflict is found within the time budget — which is also
+    what an UNKNOWN solve gets, since there is no proof of infeasibility to
+    explain.
+    """
+    built = build(problem, [], [])
+    model = built.model
+    items: list[_Item] = []
+
+    for constraint in constraints:
+        if constraint.name == "preallocations":
+            continue  # guarded per pin below
+        literal = model.NewBoolVar(f"assume[{constraint.name}]")
+        constraint.apply(_GuardedModel(model, literal), built.x, problem)
+        items.append(_Item(literal=literal, constraint=constraint.name))
+
+    if any(c.name == "preallocations" for c in constraints):
+        items.extend(_pin_items(model, built.x, problem))
+
+    started = time.monotonic()
+    core = _sufficient(model, items)
+    if core is None:
+        return None
+    core = _shrink(model, core, started)
+
+    return Conflict(
+        constraints=tuple(i.constraint for i in core if i.constraint),
+        preallocations=tuple(i.pin for i in core if i.pin is not None),
+    )
+
+
+def _pin_items(model: cp_model.CpModel, x: Vars, problem: Problem) -> list[_Item]:
+    """One item per volunteer pin: its group on the shift, it in its Role.
+
+    The same two rules the preallocations constraint states for every pin at
+    once, stated here per pin so each can be switched off on its own.
+    """
+    items: list[_Item] = []
+    for shift in problem.shifts:
+        for pin in shift.preallocations:
+            if not pin.volunteer_id:
+                continue  # a custom entry is not a decision; it only takes a Seat
+            group_key = problem.group_key_of(pin.volunteer_id)
+            literal = model.NewBoolVar(f"assume[pin,{pin.volunteer_id},{shift.index}]")
+            for member in problem.group_by_key[group_key].members:
+                model.Add(x.attend[(member.id, shift.index)] == 1).OnlyEnforceIf(literal)
+            model.Add(x.role[(pin.volunteer_id, shift.index, pin.role)] == 1).OnlyEnforceIf(
+                literal
+            )
+            items.append(
+                _Item(
+                    literal=literal,
+                    pin=ConflictPin(
+                        shift_index=shift.index,
+                        date=shift.date,
+                        volunteer_id=pin.volunteer_id,
+                        group_key=group_key,
+                        role=pin.role,
+                    ),
+                )
+            )
+    return items
+
+
+def _solve_under(model: cp_model.CpModel, items: Sequence[_Item]) -> tuple[int, cp_model.CpSolver]:
+    model.ClearAssumptions()
+    model.AddAssumptions([i.literal for i in items])
+    solver = cp_model.CpSolver()
+    solver.parameters.max_time_in_seconds = _CHECK_SECONDS
+    solver.parameters.random_seed = 0
+    # Assumption cores are only reported by a single worker.
+    solver.parameters.num_search_workers = 1
+    return solver.Solve(model), solver
+
+
+def _sufficient(model: cp_model.CpModel, items: list[_Item]) -> list[_Item] | None:
+    """The items CP-SAT names as enough for infeasibility, in item order."""
+    status, solver = _solve_under(model, items)
+    if status != cp_model.INFEASIBLE:
+        return None
+    named = set(solver.SufficientAssumptionsForInfeasibility())
+    core = [i for i in items if i.literal.Index() in named]
+    # An empty core would mean the unguarded structure is infeasible on its
+    # own, which nobody working always satisfies. Fall back to every item
+    # rather than explain nothing.
+    return core or items
+
+
+def _shrink(model: cp_model.CpModel, core: list[_Item], started: float) -> list[_Item]:
+    """Deletion-based minimisation: drop each item the rest are infeasible
+    without. Items are tried in order, so the result is deterministic."""
+    kept = list(core)
+    for item in list(core):
+        if time.monotonic() - started > _BUDGET_SECONDS:
+            break
+        rest = [i for i in kept if i is not item]
+        if not rest:
+            break
+        status, _ = _solve_under(model, rest)
+        if status == cp_model.INFEASIBLE:
+            kept = rest
+    return kept
@@ -13,6 +13,10 @@ from dataclasses import dataclass, field
 # The only gender string with semantics (mirrors allocator.GenderMale in Go).
 GENDER_MALE = "Male"
 
+# The two kinds of PairRule (mirror allocator.PairTogether/PairApart in Go).
+PAIR_TOGETHER = "together"
+PAIR_APART = "apart"
+
 
 @dataclass(frozen=True)
 class Role:
@@ -64,13 +68,17 @@ class Group:
     """Allocation unit: couples/families are allocated together.
 
     Groups are built in Go (allocator.InitVolunteerGroups); availability
-    is already resolved to shift indices.
+    is already resolved to shift indices. preferred_shift_count is how
+    many of those shifts the group would like to work, 0 for no
+    preference — a soft wish (preferences/preferred_frequency.py), never
+    a limit.
     """
 
     group_key: str
     members: tuple[Member, ...]
     available_shift_indices: tuple[int, ...]
     historical_allocation_count: int
+    preferred_shift_count: int = 0
 
 
 @dataclass(frozen=True)
@@ -90,10 +98,32 @@ class ShiftSpec:
 
 @dataclass(frozen=True)
 class HistoricalShift:
-    """A past shift; group_keys are Go-derived. Sorted ascending by date."""
+    """A past shift; group_keys are Go-derived. Sorted ascending by date.
+
+    weight is how much the shift counts towards fairness, in (0, 1]: 1 for
+    the previous rota, less for an older one when the admin's fairness
+    horizon decays it. Nothing but fairness reads it.
+    """
 
     date: str
     group_keys: tuple[str, ...]
+    weight: float = 1.0
+
+
+@dataclass(frozen=True)
+class PairRule:
+    """Two volunteers to try to put on together, or never to.
+
+    "together" is a preference (preferences/pair_together.py); "apart" is a
+    rule (constraints/never_together.py), and a fundamental one: it is most
+    often a safeguarding decision, and not an admin's to trade away for a
+    fuller rota. Go sends only rules whose volunteers are both in the
+    problem, and never a "together" rule inside one group.
+    """
+
+    volunteer_a: str
+    volunteer_b: str
+    kind: str
 
 
 @dataclass(frozen=True)
@@ -108,6 +138,9 @@ class AllocationInput:
     constraints.SWITCHABLE_CONSTRAINTS' business; an unrecognised one is
     ignored. Empty means the fundamentals only — a rule nobody has switched
     on is off, and there is no default list on this side (ADR 0006).
+
+    pair_rules are the admin's Pairing Rules between volunteers in this
+    problem; the order the two are named in means nothing.
     """
 
     max_allocation_count: int
@@ -116,6 +149,7 @@ class AllocationInput:
     roles: tuple[Role, ...] = ()
     enabled_constraints: tuple[str, ...] = ()
     historical_shifts: tuple[HistoricalShift, ...] = ()
+    pair_rules: tuple[PairRule, ...] = ()
 
 
 @dataclass(frozen=True)
@@ -153,6 +187,35 @@ class OutputShift:
     allocated_group_keys: tuple[str, ...]
 
 
+@dataclass(frozen=True)
+class ConflictPin:
+    """A volunteer pin named in a Conflict: who, which shift, which Role.
+
+    group_key is carried because a pin forces the whole group on, so the
+    group is what the other rules in the conflict are counting.
+    """
+
+    shift_index: int
+    date: str
+    volunteer_id: str
+    group_key: str
+    role: str
+
+
+@dataclass(frozen=True)
+class Conflict:
+    """Why a run was INFEASIBLE: rules and pins that cannot all hold.
+
+    Minimal — drop any one and the rest can be met — so every member is a
+    candidate to undo, and nothing here is a bystander. constraints uses the
+    constraint names of constraints/; preallocations is empty when the rules
+    conflict with each other and no pin is involved.
+    """
+
+    constraints: tuple[str, ...]
+    preallocations: tuple[ConflictPin, ...] = ()
+
+
 @dataclass(frozen=True)
 class Diagnostics:
     """Solve statistics, for logs and sanity checks — never for control flow.
@@ -166,6 +229,9 @@ class Diagnostics:
     num_groups: int
     num_variables: int
     constraints_applied: tuple[str, ...]
+    # Set only on an INFEASIBLE run, and the one field here that is meant to
+    # reach an admin: see diagnosis.py.
+    conflict: Conflict | None = None
 
 
 @dataclass(frozen=True)
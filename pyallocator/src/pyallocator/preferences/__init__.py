@@ -5,17 +5,34 @@ preference modules here. Tests inject subsets via model_builder.build().
 
 Weight hierarchy (per unit, harmonic-diminishing):
     even_fill, one Seat (its Role's priority band, 61 apart, plus 60 // Seat)
-    > spread_males (30 // male)
+    > spread_males (30 // male) > pair_together (25 per shared shift)
     > fairness (20 // lifetime allocation) > maximize_allocations (1).
+
+preferred_frequency sits outside the hierarchy as a flat cost (40 per shift
+past a group's preferred count): above spread_males and fairness, below a
+shift's first Seat.
 """
 
-from . import even_fill, fairness, maximize_allocations, spread_males
+from . import (
+    even_fill,
+    fairness,
+    maximize_allocations,
+    pair_together,
+    preferred_frequency,
+    spread_males,
+)
 from .base import ObjectiveTerm, Preference
 
 FUNDAMENTAL_PREFERENCES: list[Preference] = [
     maximize_allocations.PREFERENCE,
     fairness.PREFERENCE,
     even_fill.PREFERENCE,
+    # Fundamental rather than switchable: it is the volunteer's wish, not
+    # the admin's policy, and it is inert for anyone who did not state one.
+    preferred_frequency.PREFERENCE,
+    # Likewise inert without a "together" Pairing Rule; with one, it is a
+    # standing fact about two people rather than a policy to switch.
+    pair_together.PREFERENCE,
 ]
 
 ADDITIONAL_PREFERENCES: list[Preference] = [
@@ -1,7 +1,14 @@
 """Encourages fair distribution of shifts BETWEEN volunteer groups:
 allocations to a group get progressively less valuable the more that
 group has already worked, counting both its historical allocations
-(previous rota) and its allocations in this rota.
+and its allocations in this rota.
+
+How far back "historical" reaches is the admin's fairness horizon —
+the previous rota unless they say otherwise — and an older shift can
+count for less than a recent one: Problem.fairness_history weighs each
+historical shift by its weight. History is then a real number, so the
+division is a real one, truncated; with every weight 1 it is exactly
+the integer division it always was.
 
 A fresh group's first allocation is worth FAIRNESS_WEIGHT; a group
 with 5 past allocations earns only FAIRNESS_WEIGHT // 6 for its next
@@ -41,10 +48,10 @@ class FairnessPreference:
             allocations = sum(
                 x.attend[(rep.id, shift.index)] for shift in problem.shifts
             )
-            history = group.historical_allocation_count
+            history = problem.fairness_history[group.group_key]
             levels = []
             for k in range(1, len(problem.shifts) + 1):
-                weight = FAIRNESS_WEIGHT // (history + k)
+                weight = int(FAIRNESS_WEIGHT / (history + k))
                 if weight == 0:
                     break
                 level = model.NewBoolVar(f"fair_level_{group.group_key}_{k}")
@@ -0,0 +1,48 @@
+"""Encourages two volunteers an admin has paired to work the same shifts:
+each shift they share is worth PAIR_TOGETHER_WEIGHT.
+
+A reward for sharing, never a cost for not: a pair is not pushed onto more
+shifts to collect it, only steered onto the same ones when they work. That
+is the difference from a group, which works every shift together or none —
+right for a couple, too strong for two friends who come more happily
+together.
+
+Below spread_males and a shift's Seats, so pairing never leaves a Seat
+empty or doubles up men to happen; above fairness, so between two
+otherwise equal placements the paired one wins.
+"""
+
+from __future__ import annotations
+
+from ortools.sat.python import cp_model
+
+from ..constraints.base import Vars
+from ..problem import Problem
+from .base import ObjectiveTerm
+
+# Weight of one shift a paired two share. Above fairness (20); below
+# spread_males (30) and even_fill's Seats.
+PAIR_TOGETHER_WEIGHT = 25
+
+
+class PairTogetherPreference:
+    name = "pair_together"
+    description = "volunteers an admin has paired work the same shifts"
+
+    def objective_terms(
+        self, model: cp_model.CpModel, x: Vars, problem: Problem
+    ) -> list[ObjectiveTerm]:
+        terms: list[ObjectiveTerm] = []
+        for a, b in problem.together_pairs:
+            for shift in problem.shifts:
+                if shift.closed:
+                    continue
+                # Maximising drives shared up to 1 exactly when both attend.
+                shared = model.NewBoolVar(f"together_{a}_{b}_{shift.index}")
+                model.Add(shared <= x.attend[(a, shift.index)])
+                model.Add(shared <= x.attend[(b, shift.index)])
+                terms.append((shared, PAIR_TOGETHER_WEIGHT))
+        return terms
+
+
+PREFERENCE = PairTogetherPreference()
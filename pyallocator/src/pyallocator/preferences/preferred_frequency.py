@@ -0,0 +1,62 @@
+"""Honours a group's "only want N": each shift a group works beyond its
+preferred_shift_count costs PREFERRED_FREQUENCY_WEIGHT.
+
+Availability says which shifts a group CAN work; this is how many it
+would LIKE to. A volunteer free for all four Sundays who only wants two
+says yes to all four and 2 here, so the solver keeps all four open to
+them but reaches for somebody else first once they have their two.
+
+A cost, not a rule. It outweighs fairness and spread_males, so where
+another volunteer can take the Seat, they do. It does not outweigh the
+first Seat of any Role on a shift (even_fill's 60 and up), so nobody's
+preference leaves a shift with nobody in a Role; a later Seat, worth
+less, may be left empty rather than go to someone past their number.
+
+Counted per group, like fairness: members work together, so the first
+member stands in for the group. A group with no preference (0) adds no
+variables at all, so a rota nobody stated a number for solves exactly
+as it did before this term existed.
+"""
+
+from __future__ import annotations
+
+from ortools.sat.python import cp_model
+
+from ..constraints.base import Vars
+from ..problem import Problem
+from .base import ObjectiveTerm
+
+# What each shift past a group's preferred count takes off the objective.
+# Above fairness (20) and spread_males (30); below a shift's first Seat.
+PREFERRED_FREQUENCY_WEIGHT = 40
+
+
+class PreferredFrequencyPreference:
+    name = "preferred_frequency"
+    description = (
+        "volunteers work no more shifts than they asked for, where others can cover"
+    )
+
+    def objective_terms(
+        self, model: cp_model.CpModel, x: Vars, problem: Problem
+    ) -> list[ObjectiveTerm]:
+        terms: list[ObjectiveTerm] = []
+        for group in problem.groups:
+            preferred = group.preferred_shift_count
+            if preferred <= 0:
+                continue
+            rep = group.members[0]
+            allocations = sum(
+                x.attend[(rep.id, shift.index)] for shift in problem.shifts
+            )
+            # Maximising a negative weight drives excess down to exactly
+            # max(0, allocations - preferred).
+            excess = model.NewIntVar(
+                0, len(problem.shifts), f"preferred_excess_{group.group_key}"
+            )
+            model.Add(excess >= allocations - preferred)
+            terms.append((excess, -PREFERRED_FREQUENCY_WEIGHT))
+        return terms
+
+
+PREFERENCE = PreferredFrequencyPreference()
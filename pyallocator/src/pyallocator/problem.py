@@ -14,7 +14,15 @@ from __future__ import annotations
 
 from dataclasses import dataclass
 
-from .domain import GENDER_MALE, AllocationInput, Group, Member, Role, ShiftSpec
+from .domain import (
+    GENDER_MALE,
+    PAIR_APART,
+    AllocationInput,
+    Group,
+    Member,
+    Role,
+    ShiftSpec,
+)
 
 
 class ProblemError(ValueError):
@@ -71,6 +79,14 @@ class Problem:
         historical_group_months: {group_key: frozenset of YYYY-MM months} the
             group already worked in history (the one-shift-per-month rule bars a
             group from any current shift in a month it already worked).
+        fairness_history: {group_key: float} the group's historical
+            allocations as fairness weighs them — historical_allocation_count,
+            less 1 - weight for each historical shift naming the group, never
+            below 0. With every weight 1 it is the count itself.
+        apart_pairs: (volunteer_id, volunteer_id) pairs never to share a
+            shift, input order.
+        together_pairs: (volunteer_id, volunteer_id) pairs the solver is
+            rewarded for putting on together, input order.
     """
 
     def __init__(self, input_: AllocationInput) -> None:
@@ -121,15 +137,26 @@ class Problem:
             input_.historical_shifts[-1].group_keys if input_.historical_shifts else ()
         )
 
-        # group_key -> months (YYYY-MM) that group already worked in history.
+        # group_key -> months (YYYY-MM) that group already worked in history,
+        # and how much the horizon's decay takes off its historical count.
         months: dict[str, set[str]] = {}
+        discount: dict[str, float] = {}
         for hs in input_.historical_shifts:
             month = hs.date[:7]
             for group_key in hs.group_keys:
                 months.setdefault(group_key, set()).add(month)
+                discount[group_key] = discount.get(group_key, 0.0) + (1 - hs.weight)
         self.historical_group_months: dict[str, frozenset[str]] = {
             k: frozenset(v) for k, v in months.items()
         }
+        self.fairness_history: dict[str, float] = {
+            g.group_key: max(g.historical_allocation_count - discount.get(g.group_key, 0.0), 0.0)
+            for g in self.groups
+        }
+
+        self.apart_pairs: tuple[tuple[str, str], ...] = ()
+        self.together_pairs: tuple[tuple[str, str], ...] = ()
+        self._resolve_pair_rules()
 
     def may_fill(self, volunteer: VolunteerView, shift_index: int, role: str) -> bool:
         """Whether this volunteer may take a Seat in this Role on this shift.
@@ -150,6 +177,10 @@ class Problem:
             or self.preallocated_roles.get((volunteer.id, shift_index)) == role
         )
 
+    def group_key_of(self, volunteer_id: str) -> str:
+        """The key of the group this volunteer belongs to."""
+        return self._group_key_by_member[volunteer_id]
+
     def seats_for(self, shift: ShiftSpec, role: str) -> int:
         """How many Seats this shift's Shape asks for in the named Role."""
         return sum(seat.count for seat in shift.shape if seat.role == role)
@@ -196,3 +227,24 @@ class Problem:
                 # Multiple ids from the same group dedupe to one pair —
                 # the whole group comes as a unit anyway.
                 self.preallocated_pairs.add((group_key, shift.index))
+
+    def _resolve_pair_rules(self) -> None:
+        # Go drops a rule naming somebody not in the problem, so one arriving
+        # here is a caller bug. Silently ignoring an "apart" rule would be the
+        # worst way to find that out.
+        apart: list[tuple[str, str]] = []
+        together: list[tuple[str, str]] = []
+        for rule in self.input.pair_rules:
+            for volunteer_id in (rule.volunteer_a, rule.volunteer_b):
+                if volunteer_id not in self._group_key_by_member:
+                    raise ProblemError(
+                        f"pair rule volunteer '{volunteer_id}' does not match "
+                        "any volunteer"
+                    )
+            pair = (rule.volunteer_a, rule.volunteer_b)
+            if rule.kind == PAIR_APART:
+                apart.append(pair)
+            else:
+                together.append(pair)
+        self.apart_pairs = tuple(apart)
+        self.together_pairs = tuple(together)
@@ -10,6 +10,8 @@ from __future__ import annotations
 from typing import Any
 
 from .domain import (
+    PAIR_APART,
+    PAIR_TOGETHER,
     AllocationInput,
     AllocationOutput,
     Diagnostics,
@@ -17,6 +19,7 @@ from .domain import (
     HistoricalShift,
     Member,
     OutputShift,
+    PairRule,
     Preallocation,
     Role,
     Seat,
@@ -87,6 +90,11 @@ def _parse_group(d: dict[str, Any], where: str) -> Group:
     members = tuple(
         _parse_member(m, f"{where}.members[{i}]") for i, m in enumerate(members_raw)
     )
+    preferred = _optional(d, "preferred_shift_count", int, 0, where)
+    if preferred < 0:
+        raise InputError(
+            f"{where}.preferred_shift_count: expected at least 0, got {preferred}"
+        )
     return Group(
         group_key=_require(d, "group_key", str, where),
         members=members,
@@ -94,6 +102,7 @@ def _parse_group(d: dict[str, Any], where: str) -> Group:
         historical_allocation_count=_optional(
             d, "historical_allocation_count", int, 0, where
         ),
+        preferred_shift_count=preferred,
     )
 
 
@@ -153,12 +162,33 @@ def _parse_shift(d: dict[str, Any], where: str) -> ShiftSpec:
 def _parse_historical_shift(d: dict[str, Any], where: str) -> HistoricalShift:
     if not isinstance(d, dict):
         raise InputError(f"{where}: expected object, got {type(d).__name__}")
+    # Optional: an input from before the fairness horizon existed has no
+    # weights, and every shift in it counted in full.
+    weight = _optional(d, "weight", float, 1.0, where)
+    if not 0 < weight <= 1:
+        raise InputError(f"{where}.weight: must be in (0, 1], got {weight}")
     return HistoricalShift(
         date=_require(d, "date", str, where),
         group_keys=_str_tuple(d, "group_keys", where),
+        weight=weight,
     )
 
 
+def _parse_pair_rule(d: dict[str, Any], where: str) -> PairRule:
+    if not isinstance(d, dict):
+        raise InputError(f"{where}: expected object, got {type(d).__name__}")
+    kind = _require(d, "kind", str, where)
+    if kind not in (PAIR_TOGETHER, PAIR_APART):
+        raise InputError(
+            f"{where}.kind: expected '{PAIR_TOGETHER}' or '{PAIR_APART}', got '{kind}'"
+        )
+    volunteer_a = _require(d, "volunteer_a", str, where)
+    volunteer_b = _require(d, "volunteer_b", str, where)
+    if volunteer_a == volunteer_b:
+        raise InputError(f"{where}: names volunteer '{volunteer_a}' twice")
+    return PairRule(volunteer_a=volunteer_a, volunteer_b=volunteer_b, kind=kind)
+
+
 def parse_input(data: Any) -> AllocationInput:
     """Convert a decoded-JSON dict into an AllocationInput, validating shape."""
     if not isinstance(data, dict):
@@ -168,6 +198,7 @@ def parse_input(data: Any) -> AllocationInput:
     groups_raw = _require(data, "groups", list, "input")
     roles_raw = _require(data, "roles", list, "input")
     historical_raw = _optional(data, "historical_shifts", list, [], "input")
+    pair_rules_raw = _optional(data, "pair_rules", list, [], "input")
 
     roles = tuple(_parse_role(r, f"input.roles[{i}]") for i, r in enumerate(roles_raw))
     role_names = {r.name for r in roles}
@@ -222,6 +253,10 @@ def parse_input(data: Any) -> AllocationInput:
             _parse_historical_shift(h, f"input.historical_shifts[{i}]")
             for i, h in enumerate(historical_raw)
         ),
+        pair_rules=tuple(
+            _parse_pair_rule(r, f"input.pair_rules[{i}]")
+            for i, r in enumerate(pair_rules_raw)
+        ),
     )
 
 
@@ -257,4 +292,19 @@ def output_to_dict(output: AllocationOutput) -> dict[str, Any]:
             "num_variables": output.diagnostics.num_variables,
             "constraints_applied": list(output.diagnostics.constraints_applied),
         }
+        conflict = output.diagnostics.conflict
+        if conflict is not None:
+            result["diagnostics"]["conflict"] = {
+                "constraints": list(conflict.constraints),
+                "preallocations": [
+                    {
+                        "shift_index": p.shift_index,
+                        "date": p.date,
+                        "volunteer_id": p.volunteer_id,
+                        "group_key": p.group_key,
+                        "role": p.role,
+                    }
+                    for p in conflict.preallocations
+                ],
+            }
     return result
@@ -25,6 +25,7 @@ from pyallocator.domain import (
     HistoricalShift,
     Member,
     OutputShift,
+    PairRule,
     Preallocation,
     Role,
     Seat,
@@ -75,6 +76,7 @@ def make_group(
     members: Sequence[Member] | None = None,
     available: Sequence[int] = (),
     historical_count: int = 0,
+    preferred_count: int = 0,
     team_lead: bool = False,
 ) -> Group:
     if members is None:
@@ -84,6 +86,7 @@ def make_group(
         members=tuple(members),
         available_shift_indices=tuple(available),
         historical_allocation_count=historical_count,
+        preferred_shift_count=preferred_count,
     )
 
 
@@ -134,6 +137,7 @@ def make_input(
     historical_shifts: Sequence[HistoricalShift] = (),
     roles: Sequence[Role] = DEFAULT_ROLES,
     enabled_constraints: Sequence[str] = (),
+    pair_rules: Sequence[PairRule] = (),
 ) -> AllocationInput:
     return AllocationInput(
         max_allocation_count=max_allocation_count,
@@ -142,6 +146,7 @@ def make_input(
         roles=tuple(roles),
         enabled_constraints=tuple(enabled_constraints),
         historical_shifts=tuple(historical_shifts),
+        pair_rules=tuple(pair_rules),
     )
 
 
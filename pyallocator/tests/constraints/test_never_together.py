@@ -0,0 +1,77 @@
+"""Solving with ONLY the never-together constraint, plus the resolution
+error case (which lives in Problem construction)."""
+
+from __future__ import annotations
+
+import pytest
+from conftest import allocations_by_shift, make_group, make_input, make_member, make_shift, solve_with
+from pyallocator.constraints import never_together, preallocations
+from pyallocator.domain import PAIR_APART, PairRule
+from pyallocator.problem import Problem, ProblemError
+
+ONLY = [never_together.CONSTRAINT]
+
+APART = PairRule(volunteer_a="ann", volunteer_b="bea", kind=PAIR_APART)
+
+
+def test_a_pair_kept_apart_never_shares_a_shift():
+    inp = make_input(
+        groups=[make_group("ann", available=[0, 1]), make_group("bea", available=[0, 1])],
+        shifts=[make_shift(0), make_shift(1)],
+        pair_rules=[APART],
+    )
+    out = solve_with(inp, ONLY)
+    assert out.success
+    for keys in allocations_by_shift(out).values():
+        assert not {"ann", "bea"} <= set(keys)
+    # Both still work: the rule separates them, it does not bench either.
+    worked = [k for keys in allocations_by_shift(out).values() for k in keys]
+    assert sorted(worked) == ["ann", "bea"]
+
+
+def test_without_the_rule_they_share_shifts():
+    inp = make_input(
+        groups=[make_group("ann", available=[0]), make_group("bea", available=[0])],
+        shifts=[make_shift(0)],
+    )
+    out = solve_with(inp, ONLY)
+    assert out.success
+    assert set(allocations_by_shift(out)[0]) == {"ann", "bea"}
+
+
+def test_the_rule_reaches_a_partner_through_their_group():
+    # bea is kept apart from ann, and cal is bea's partner: cal's group can
+    # only work a shift bea's group does, so neither can join ann.
+    family = make_group(
+        "bea_cal",
+        members=[make_member("bea"), make_member("cal")],
+        available=[0],
+    )
+    inp = make_input(
+        groups=[make_group("ann", available=[0]), family],
+        shifts=[make_shift(0)],
+        pair_rules=[APART],
+    )
+    out = solve_with(inp, ONLY)
+    assert out.success
+    assert allocations_by_shift(out)[0] in (("ann",), ("bea_cal",))
+
+
+def test_a_pin_does_not_override_it():
+    inp = make_input(
+        groups=[make_group("ann", available=[0]), make_group("bea", available=[0])],
+        shifts=[make_shift(0, preallocated_volunteer_ids=["ann", "bea"])],
+        pair_rules=[APART],
+    )
+    out = solve_with(inp, [preallocations.CONSTRAINT, *ONLY])
+    assert out.solver_status == "INFEASIBLE"
+
+
+def test_unknown_pair_rule_volunteer_errors():
+    inp = make_input(
+        groups=[make_group("ann", available=[0])],
+        shifts=[make_shift(0)],
+        pair_rules=[PairRule(volunteer_a="ann", volunteer_b="nobody", kind=PAIR_APART)],
+    )
+    with pytest.raises(ProblemError, match="does not match any volunteer"):
+        Problem(inp)
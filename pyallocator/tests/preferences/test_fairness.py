@@ -4,6 +4,7 @@ from __future__ import annotations
 
 from conftest import allocations_by_shift, make_group, make_input, make_shift, solve_with
 from pyallocator.constraints import seat_capacity
+from pyallocator.domain import HistoricalShift
 from pyallocator.preferences import fairness
 
 PREFS = [fairness.PREFERENCE]
@@ -40,3 +41,31 @@ def test_repeat_allocations_diminish_within_the_rota():
     by_shift = allocations_by_shift(out)
     allocated = [key for keys in by_shift.values() for key in keys]
     assert sorted(allocated) == ["g1", "g2"]
+
+
+def test_decayed_history_counts_for_less():
+    # "longago" has three historical allocations, all from rotas the
+    # horizon has decayed to a tenth; "recent" has one from the previous
+    # rota at full weight. Weighed, longago has worked less lately.
+    history = [
+        HistoricalShift(date="2026-03-01", group_keys=("longago",), weight=0.1),
+        HistoricalShift(date="2026-03-08", group_keys=("longago",), weight=0.1),
+        HistoricalShift(date="2026-03-15", group_keys=("longago",), weight=0.1),
+        HistoricalShift(date="2026-07-26", group_keys=("recent",)),
+    ]
+    groups = [
+        make_group("longago", available=[0], historical_count=3),
+        make_group("recent", available=[0], historical_count=1),
+    ]
+
+    inp = make_input(groups=groups, shifts=[make_shift(0, size=1)], historical_shifts=history)
+    out = solve_with(inp, [seat_capacity.CONSTRAINT], preferences=PREFS)
+    assert out.success
+    assert allocations_by_shift(out)[0] == ("longago",)
+
+    # Counted in full, the same history makes longago the busier of the two.
+    undecayed = [HistoricalShift(date=h.date, group_keys=h.group_keys) for h in history]
+    inp = make_input(groups=groups, shifts=[make_shift(0, size=1)], historical_shifts=undecayed)
+    out = solve_with(inp, [seat_capacity.CONSTRAINT], preferences=PREFS)
+    assert out.success
+    assert allocations_by_shift(out)[0] == ("recent",)
@@ -0,0 +1,49 @@
+"""The pair_together preference steers a paired two onto the same shifts."""
+
+from __future__ import annotations
+
+from conftest import allocations_by_shift, make_group, make_input, make_shift, solve_with
+from pyallocator.constraints import max_frequency, seat_capacity
+from pyallocator.domain import PAIR_TOGETHER, PairRule
+from pyallocator.preferences import fairness, maximize_allocations, pair_together
+
+PREFS = [maximize_allocations.PREFERENCE, fairness.PREFERENCE, pair_together.PREFERENCE]
+RULES = [seat_capacity.CONSTRAINT, max_frequency.CONSTRAINT]
+
+TOGETHER = PairRule(volunteer_a="ann", volunteer_b="bea", kind=PAIR_TOGETHER)
+
+
+def _input(pair_rules=()):
+    # One shift each, two Seats a shift. ann and cat can only do shift 0, bea
+    # either. Everyone works if bea takes shift 1; pairing her with ann costs
+    # cat, the veteran, her Seat.
+    return make_input(
+        groups=[
+            make_group("ann", available=[0]),
+            make_group("bea", available=[0, 1]),
+            make_group("cat", available=[0], historical_count=5),
+        ],
+        shifts=[make_shift(0, size=2), make_shift(1, size=2)],
+        max_allocation_count=1,
+        pair_rules=pair_rules,
+    )
+
+
+def test_a_paired_two_are_put_on_together():
+    out = solve_with(_input([TOGETHER]), RULES, preferences=PREFS)
+    assert out.success
+    assert set(allocations_by_shift(out)[0]) == {"ann", "bea"}
+
+
+def test_without_the_rule_everyone_works():
+    out = solve_with(_input(), RULES, preferences=PREFS)
+    assert out.success
+    by_shift = allocations_by_shift(out)
+    assert set(by_shift[0]) == {"ann", "cat"}
+    assert by_shift[1] == ("bea",)
+
+
+def test_no_rule_adds_nothing():
+    out = solve_with(_input(), RULES, preferences=[pair_together.PREFERENCE])
+    assert out.success
+    assert out.objective_value == 0
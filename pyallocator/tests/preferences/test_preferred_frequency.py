@@ -0,0 +1,62 @@
+"""The preferred_frequency preference keeps a group to the number of
+shifts it asked for, where somebody else can cover."""
+
+from __future__ import annotations
+
+from conftest import allocations_by_shift, make_group, make_input, make_shift, solve_with
+from pyallocator.constraints import seat_capacity
+from pyallocator.preferences import (
+    even_fill,
+    fairness,
+    maximize_allocations,
+    preferred_frequency,
+)
+
+PREFS = [
+    maximize_allocations.PREFERENCE,
+    fairness.PREFERENCE,
+    preferred_frequency.PREFERENCE,
+]
+
+
+def test_someone_else_takes_the_shift_past_the_preferred_count():
+    # "choosy" can do both shifts but only wants one. The veteran's history
+    # means fairness alone would give choosy both; the preference outweighs it.
+    inp = make_input(
+        groups=[
+            make_group("choosy", available=[0, 1], preferred_count=1),
+            make_group("veteran", available=[1], historical_count=5),
+        ],
+        shifts=[make_shift(0, size=1), make_shift(1, size=1)],
+    )
+    out = solve_with(inp, [seat_capacity.CONSTRAINT], preferences=PREFS)
+    assert out.success
+    by_shift = allocations_by_shift(out)
+    assert by_shift[0] == ("choosy",)
+    assert by_shift[1] == ("veteran",)
+
+
+def test_a_preference_is_not_a_cap():
+    # With nobody else to take it, the second shift still goes to choosy:
+    # its first Seat (even_fill) is worth more than the preference costs.
+    inp = make_input(
+        groups=[make_group("choosy", available=[0, 1], preferred_count=1)],
+        shifts=[make_shift(0, size=1), make_shift(1, size=1)],
+    )
+    out = solve_with(
+        inp, [seat_capacity.CONSTRAINT], preferences=[*PREFS, even_fill.PREFERENCE]
+    )
+    assert out.success
+    by_shift = allocations_by_shift(out)
+    assert by_shift[0] == ("choosy",)
+    assert by_shift[1] == ("choosy",)
+
+
+def test_no_preference_adds_nothing():
+    inp = make_input(
+        groups=[make_group("easy", available=[0, 1])],
+        shifts=[make_shift(0, size=1), make_shift(1, size=1)],
+    )
+    out = solve_with(inp, [seat_capacity.CONSTRAINT], preferences=PREFS)
+    assert out.success
+    assert allocations_by_shift(out) == {0: ("easy",), 1: ("easy",)}
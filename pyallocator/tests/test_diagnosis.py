@@ -0,0 +1,135 @@
+"""Diagnosing an INFEASIBLE run: the rules and pins that cannot all hold."""
+
+from __future__ import annotations
+
+from conftest import TEAM_LEAD, make_group, make_input, make_shift, solve_with
+from pyallocator.constraints import (
+    availability,
+    max_frequency,
+    never_together,
+    no_back_to_back,
+    preallocations,
+    seat_capacity,
+)
+from pyallocator.domain import PAIR_APART, ConflictPin, PairRule, Preallocation, Seat, ShiftSpec
+from pyallocator.serialization import output_to_dict
+
+
+def test_pins_against_no_back_to_back_name_both_pins_and_the_rule():
+    inp = make_input(
+        groups=[make_group("alice", available=[0, 1]), make_group("bob", available=[0, 1])],
+        shifts=[
+            make_shift(0, preallocated_volunteer_ids=["alice"]),
+            make_shift(1, preallocated_volunteer_ids=["alice"]),
+        ],
+    )
+    out = solve_with(
+        inp, [availability.CONSTRAINT, preallocations.CONSTRAINT, no_back_to_back.CONSTRAINT]
+    )
+
+    assert out.solver_status == "INFEASIBLE"
+    conflict = out.diagnostics.conflict
+    assert conflict is not None
+    assert conflict.constraints == ("no_back_to_back",)
+    assert conflict.preallocations == (
+        ConflictPin(shift_index=0, date="2026-07-13", volunteer_id="alice", group_key="alice", role="Service volunteer"),
+        ConflictPin(shift_index=1, date="2026-07-20", volunteer_id="alice", group_key="alice", role="Service volunteer"),
+    )
+
+
+def test_two_pins_to_one_seat_conflict_with_seat_capacity():
+    inp = make_input(
+        groups=[
+            make_group("alice", available=[0], team_lead=True),
+            make_group("bob", available=[0], team_lead=True),
+        ],
+        shifts=[
+            ShiftSpec(
+                index=0,
+                date="2026-07-13",
+                closed=False,
+                shape=(Seat(role=TEAM_LEAD, count=1),),
+                preallocations=(
+                    Preallocation(volunteer_id="alice", custom="", role=TEAM_LEAD),
+                    Preallocation(volunteer_id="bob", custom="", role=TEAM_LEAD),
+                ),
+            )
+        ],
+    )
+
+    out = solve_with(inp, [preallocations.CONSTRAINT, seat_capacity.CONSTRAINT])
+
+    assert out.solver_status == "INFEASIBLE"
+    conflict = out.diagnostics.conflict
+    assert conflict.constraints == ("seat_capacity",)
+    assert {p.volunteer_id for p in conflict.preallocations} == {"alice", "bob"}
+
+
+def test_pinning_a_pair_kept_apart_together_names_the_rule():
+    inp = make_input(
+        groups=[make_group("ann", available=[0]), make_group("bea", available=[0])],
+        shifts=[make_shift(0, preallocated_volunteer_ids=["ann", "bea"])],
+        pair_rules=[PairRule(volunteer_a="ann", volunteer_b="bea", kind=PAIR_APART)],
+    )
+
+    out = solve_with(inp, [preallocations.CONSTRAINT, never_together.CONSTRAINT])
+
+    conflict = out.diagnostics.conflict
+    assert conflict.constraints == ("never_together",)
+    assert [p.volunteer_id for p in conflict.preallocations] == ["ann", "bea"]
+
+
+def test_the_conflict_is_minimal():
+    # Three pins under a cap of two: any two are fine, all three are not, so
+    # all three and the cap are the conflict. The unrelated pin on bob is not.
+    inp = make_input(
+        groups=[make_group("alice", available=[0, 2, 4]), make_group("bob", available=[0])],
+        shifts=[
+            make_shift(0, preallocated_volunteer_ids=["alice", "bob"]),
+            make_shift(1),
+            make_shift(2, preallocated_volunteer_ids=["alice"]),
+            make_shift(3),
+            make_shift(4, preallocated_volunteer_ids=["alice"]),
+        ],
+        max_allocation_count=2,
+    )
+
+    out = solve_with(inp, [preallocations.CONSTRAINT, max_frequency.CONSTRAINT])
+
+    conflict = out.diagnostics.conflict
+    assert conflict.constraints == ("max_frequency",)
+    assert [(p.volunteer_id, p.shift_index) for p in conflict.preallocations] == [
+        ("alice", 0),
+        ("alice", 2),
+        ("alice", 4),
+    ]
+
+
+def test_a_feasible_run_carries_no_conflict():
+    inp = make_input(groups=[make_group("alice", available=[0])], shifts=[make_shift(0)])
+
+    out = solve_with(inp, [availability.CONSTRAINT])
+
+    assert out.success
+    assert out.diagnostics.conflict is None
+    assert "conflict" not in output_to_dict(out)["diagnostics"]
+
+
+def test_the_conflict_is_serialised_into_the_diagnostics():
+    inp = make_input(
+        groups=[make_group("alice", available=[0, 1])],
+        shifts=[
+            make_shift(0, preallocated_volunteer_ids=["alice"]),
+            make_shift(1, preallocated_volunteer_ids=["alice"]),
+        ],
+    )
+
+    out = solve_with(inp, [preallocations.CONSTRAINT, no_back_to_back.CONSTRAINT])
+
+    assert output_to_dict(out)["diagnostics"]["conflict"] == {
+        "constraints": ["no_back_to_back"],
+        "preallocations": [
+            {"shift_index": 0, "date": "2026-07-13", "volunteer_id": "alice", "group_key": "alice", "role": "Service volunteer"},
+            {"shift_index": 1, "date": "2026-07-20", "volunteer_id": "alice", "group_key": "alice", "role": "Service volunteer"},
+        ],
+    }
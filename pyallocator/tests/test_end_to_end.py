@@ -39,6 +39,7 @@ from conftest import (
 )
 from pyallocator.api import solve
 from pyallocator.domain import (
+    PAIR_APART,
     AllocationInput,
     AllocationOutput,
     Group,
@@ -184,6 +185,12 @@ def verify_solution(inp: AllocationInput, out: AllocationOutput) -> list[str]:
                 f"shift {shift.index}: assigned ids {sorted(set(assigned))} != "
                 f"allocated group members {sorted(expected_ids)}"
             )
+        for rule in inp.pair_rules:
+            if rule.kind == PAIR_APART and {rule.volunteer_a, rule.volunteer_b} <= expected_ids:
+                problems.append(
+                    f"shift {shift.index}: {rule.volunteer_a} and "
+                    f"{rule.volunteer_b} are kept apart but both work"
+                )
         if lead_id:
             tl_group = member_to_group.get(lead_id)
             if tl_group not in keys:
@@ -328,6 +335,7 @@ def test_end_to_end_scenario():
         "no_back_to_back",
         "closed_shifts",
         "preallocations",
+        "never_together",
     }
 
 
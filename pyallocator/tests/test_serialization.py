@@ -67,9 +67,11 @@ VALID_INPUT = {
             ],
             "available_shift_indices": [0, 1],
             "historical_allocation_count": 3,
+            "preferred_shift_count": 1,
         }
     ],
-    "historical_shifts": [{"date": "2026-06-29", "group_keys": ["couple_x"]}],
+    "historical_shifts": [{"date": "2026-06-29", "group_keys": ["couple_x"], "weight": 0.5}],
+    "pair_rules": [{"volunteer_a": "vol-1", "volunteer_b": "vol-9", "kind": "apart"}],
 }
 
 
@@ -99,7 +101,12 @@ def test_parse_valid_input():
     assert group.members[1].roles == ("Team lead", "Service volunteer")
     assert group.available_shift_indices == (0, 1)
     assert group.historical_allocation_count == 3
+    assert group.preferred_shift_count == 1
     assert parsed.historical_shifts[0].group_keys == ("couple_x",)
+    assert parsed.historical_shifts[0].weight == 0.5
+    assert [(r.volunteer_a, r.volunteer_b, r.kind) for r in parsed.pair_rules] == [
+        ("vol-1", "vol-9", "apart"),
+    ]
 
 
 @pytest.mark.parametrize(
@@ -120,6 +127,10 @@ def test_parse_valid_input():
             lambda d: d["groups"].append(dict(d["groups"][0])),
             "duplicate group_key",
         ),
+        (
+            lambda d: d["groups"][0].update(preferred_shift_count=-1),
+            "preferred_shift_count",
+        ),
         (lambda d: d.update(max_allocation_count="4"), "expected int"),
         (lambda d: d.pop("roles"), "roles"),
         (
@@ -142,6 +153,17 @@ def test_parse_valid_input():
             ),
             "exactly one of 'volunteer_id' and 'custom'",
         ),
+        (lambda d: d["pair_rules"][0].pop("kind"), "kind"),
+        (
+            lambda d: d["pair_rules"][0].update(kind="sometimes"),
+            "expected 'together' or 'apart'",
+        ),
+        (
+            lambda d: d["pair_rules"][0].update(volunteer_b="vol-1"),
+            "names volunteer 'vol-1' twice",
+        ),
+        (lambda d: d["historical_shifts"][0].update(weight=0), r"must be in \(0, 1\]"),
+        (lambda d: d["historical_shifts"][0].update(weight=1.5), r"must be in \(0, 1\]"),
     ],
 )
 def test_parse_rejects_bad_input(mutate, fragment):
@@ -153,6 +175,14 @@ def test_parse_rejects_bad_input(mutate, fragment):
         parse_input(data)
 
 
+def test_parse_historical_shift_without_a_weight_counts_in_full():
+    import copy
+
+    data = copy.deepcopy(VALID_INPUT)
+    del data["historical_shifts"][0]["weight"]
+    assert parse_input(data).historical_shifts[0].weight == 1.0
+
+
 def test_parse_rejects_non_dict():
     with pytest.raises(InputError):
         parse_input([1, 2, 3])
@@ -2,6 +2,9 @@ import { Link, Redirect, Route, Switch, useLocation } from "wouter";
 import RotaViewer from "./components/RotaViewer";
 import AdminPage from "./components/AdminPage";
 import AvailabilityForm from "./components/AvailabilityForm";
+import SwapPage from "./components/SwapPage";
+import CoverPage from "./components/CoverPage";
+import AttendancePage from "./components/AttendancePage";
 import { ADMIN_TABS } from "./components/adminTabs";
 import { useRota } from "./hooks/useRota";
 import { useAuth } from "./auth-context";
@@ -63,7 +66,8 @@ function Header() {
 // reveals shifts whose rota has not been allocated yet, and unlocks editing.
 function HomeView() {
   const { email } = useAuth();
-  const { shifts, error, change, setClosed, setTimes, setShape } = useRota();
+  const { shifts, error, reload, change, setClosed, setTimes, setShape } =
+    useRota();
 
   if (error) {
     return <p className="app-status">Could not load the rota: {error}</p>;
@@ -79,6 +83,7 @@ function HomeView() {
       onSetClosed={setClosed}
       onSetTimes={setTimes}
       onSetShape={setShape}
+      onReload={reload}
     />
   );
 }
@@ -93,6 +98,21 @@ function App() {
       <Route path="/availability/:token">
         {(params) => <AvailabilityForm token={params.token} />}
       </Route>
+      {/* The same link once its rota is out, and outside the shell for the
+          same reason. */}
+      <Route path="/swaps/:token">
+        {(params) => <SwapPage token={params.token} />}
+      </Route>
+      {/* A cover request's link, asking about one shift. Outside the shell
+          for the same reason again. */}
+      <Route path="/cover/:token">
+        {(params) => <CoverPage token={params.token} />}
+      </Route>
+      {/* A Team lead's same link again, for saying who came to the shifts
+          they led. */}
+      <Route path="/attendance/:token">
+        {(params) => <AttendancePage token={params.token} />}
+      </Route>
 
       <Route>
         <>
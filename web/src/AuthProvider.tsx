@@ -1,28 +1,44 @@
-import { useEffect, useState, type ReactNode } from "react";
+import { useCallback, useEffect, useState, type ReactNode } from "react";
 import { fetchCurrentAdmin, logout as logoutRequest } from "./api";
 import { AuthContext } from "./auth-context";
+import type { RosterSyncStatus } from "./types";
 
 // AuthProvider checks the admin session once on mount and shares it with the
 // whole tree via AuthContext, so status lives in one place rather than being
 // re-fetched by each component that needs it.
 export function AuthProvider({ children }: { children: ReactNode }) {
   const [email, setEmail] = useState<string | null>(null);
+  const [rosterSync, setRosterSync] = useState<RosterSyncStatus | null>(null);
   const [loading, setLoading] = useState(true);
 
+  const refresh = useCallback(
+    () =>
+      fetchCurrentAdmin()
+        .then((admin) => {
+          setEmail(admin?.email ?? null);
+          setRosterSync(admin?.rosterSync ?? null);
+        })
+        .catch(() => {
+          setEmail(null);
+          setRosterSync(null);
+        }),
+    [],
+  );
+
   useEffect(() => {
-    fetchCurrentAdmin()
-      .then(setEmail)
-      .catch(() => setEmail(null))
-      .finally(() => setLoading(false));
-  }, []);
+    void refresh().finally(() => setLoading(false));
+  }, [refresh]);
 
   async function logout() {
     await logoutRequest();
     setEmail(null);
+    setRosterSync(null);
   }
 
   return (
-    <AuthContext.Provider value={{ email, loading, logout }}>
+    <AuthContext.Provider
+      value={{ email, loading, logout, rosterSync, refresh }}
+    >
       {children}
     </AuthContext.Provider>
   );
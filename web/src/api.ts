@@ -1,33 +1,59 @@
 import type {
+  Absence,
+  CalendarToken,
   AllocateOutcome,
   AllocationSettings,
   Assignee,
+  AttendanceStatus,
   AvailabilityEntry,
   AvailabilityFormState,
   AvailabilityLinkFailure,
   AvailabilityRound,
   AvailabilitySend,
+  AvailabilitySendSummary,
   ConfiguredRole,
+  CoverFormState,
+  CoverRequest,
   DefinedRota,
   DraftRotaState,
+  LeadAttendancePage,
+  NewAbsence,
+  NewCoverRequest,
   NewPreallocation,
   NewRota,
+  NewPairingRule,
   NewStandingPreallocation,
+  PairingRule,
   PersonRef,
   Preallocation,
   RoleColour,
   RoleEdit,
+  Roster,
+  RosterDiff,
+  RosterSource,
+  RosterSync,
+  RosterSyncStatus,
   RotaChange,
+  EmailPreview,
+  EmailTemplate,
+  EmailTemplateWording,
   RotaDefaults,
   RotaInFlight,
   RotaProposal,
   RotaShift,
   SendMode,
   SendOutcome,
+  SendStatus,
   ShapeSeat,
+  ShiftAttendance,
   ShiftTimes,
   StandingPreallocation,
+  SwapPageState,
+  SwapRequest,
+  SyncPreview,
   Volunteer,
+  VolunteerEdit,
+  VolunteerState,
 } from "./types";
 import {
   DEFAULT_ROLE_COLOUR,
@@ -45,6 +71,7 @@ interface ApiAssignee {
 
 interface ApiShift {
   id: string;
+  rotaId: string;
   date: string;
   start: string;
   end: string;
@@ -65,14 +92,21 @@ interface ApiVolunteer {
   id: string;
   name: string;
   fullName: string;
+  firstName: string;
+  lastName?: string;
+  email?: string;
   roles: string[];
   group?: string;
   gender?: string;
   active: boolean;
+  state: VolunteerState;
+  pausedUntil?: string;
+  status?: string;
 }
 
 interface ListVolunteersResponse {
   volunteers: ApiVolunteer[];
+  source: RosterSource;
 }
 
 interface DefineRotaResponse {
@@ -99,11 +133,17 @@ function toVolunteer(v: ApiVolunteer): Volunteer {
     id: v.id,
     name: v.name,
     fullName: v.fullName,
+    firstName: v.firstName,
+    lastName: v.lastName ?? "",
+    email: v.email || null,
     // In the order the API sends them: highest-priority Role first.
     roles: v.roles,
     group: v.group || null,
     gender: v.gender || null,
     active: v.active,
+    state: v.state,
+    pausedUntil: v.pausedUntil ?? null,
+    status: v.status ?? null,
   };
 }
 
@@ -125,6 +165,7 @@ function toAssignee(a: ApiAssignee): Assignee {
 function toRotaShift(shift: ApiShift): RotaShift {
   return {
     id: shift.id,
+    rotaId: shift.rotaId,
     date: shift.date,
     start: shift.start,
     end: shift.end,
@@ -136,16 +177,22 @@ function toRotaShift(shift: ApiShift): RotaShift {
   };
 }
 
-// fetchCurrentAdmin returns the logged-in admin's email, or null if there is no
-// active admin session.
-export async function fetchCurrentAdmin(): Promise<string | null> {
+// CurrentAdmin is the logged-in admin, with how fresh the roster is where the
+// sheet is the roster (absent where the database is).
+export interface CurrentAdmin {
+  email: string;
+  rosterSync?: RosterSyncStatus;
+}
+
+// fetchCurrentAdmin returns the logged-in admin, or null if there is no active
+// admin session.
+export async function fetchCurrentAdmin(): Promise<CurrentAdmin | null> {
   const res = await fetch("/auth/me");
   if (res.status === 401) return null;
   if (!res.ok) {
     throw new Error(`Failed to check login state (${res.status})`);
   }
-  const data = (await res.json()) as { email: string };
-  return data.email;
+  return (await res.json()) as CurrentAdmin;
 }
 
 // logout clears the admin session cookie.
@@ -313,6 +360,83 @@ export async function saveAllocationSettings(
   return (await res.json()) as AllocationSettings;
 }
 
+// saveSwapSettings writes whether a swap the volunteers arrange waits for an
+// admin. Resolves with nothing: the switch is the whole section, and what was
+// sent is what is held.
+export async function saveSwapSettings(
+  swapsNeedApproval: boolean,
+): Promise<void> {
+  const res = await fetch("/api/rota-defaults/swap-settings", {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ swapsNeedApproval }),
+  });
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to save the swap settings"),
+    );
+  }
+}
+
+// saveEmailTemplate rewords one email. Resolves with it as the server now
+// holds it. A refusal — a misspelled variable, wording without the link —
+// comes back with the server's own message, which names what was wrong.
+export async function saveEmailTemplate(
+  kind: string,
+  wording: EmailTemplateWording,
+): Promise<EmailTemplate> {
+  const res = await fetch(
+    `/api/rota-defaults/email-templates/${encodeURIComponent(kind)}`,
+    {
+      method: "PUT",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(wording),
+    },
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to save the email"));
+  }
+  return (await res.json()) as EmailTemplate;
+}
+
+// resetEmailTemplate puts one email back to its default wording, and resolves
+// with that wording.
+export async function resetEmailTemplate(kind: string): Promise<EmailTemplate> {
+  const res = await fetch(
+    `/api/rota-defaults/email-templates/${encodeURIComponent(kind)}`,
+    { method: "DELETE" },
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to reset the email"));
+  }
+  return (await res.json()) as EmailTemplate;
+}
+
+// previewEmailTemplate fills wording in for one volunteer without saving or
+// sending it, so an admin reads what that volunteer would get before anyone
+// does. The rota is the newest one unless rotaId says otherwise.
+export async function previewEmailTemplate(
+  kind: string,
+  preview: EmailTemplateWording & {
+    volunteerId: string;
+    rotaId?: string;
+    deadline?: string;
+  },
+): Promise<EmailPreview> {
+  const res = await fetch(
+    `/api/rota-defaults/email-templates/${encodeURIComponent(kind)}/preview`,
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(preview),
+    },
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to preview the email"));
+  }
+  return (await res.json()) as EmailPreview;
+}
+
 interface ApiPreallocation {
   id: string;
   date: string;
@@ -472,15 +596,266 @@ export async function deleteStandingPreallocation(id: string): Promise<void> {
   }
 }
 
-// fetchVolunteers returns the whole synced roster, inactive volunteers included,
-// already sorted by name server-side. Admin-only.
-export async function fetchVolunteers(): Promise<Volunteer[]> {
+interface ApiPairingRule {
+  id: string;
+  kind: PairingRule["kind"];
+  volunteerA: string;
+  nameA: string;
+  volunteerB: string;
+  nameB: string;
+  note?: string;
+}
+
+interface ListPairingRulesResponse {
+  pairingRules: ApiPairingRule[];
+}
+
+// fetchPairingRules returns every Pairing Rule, "apart" rules first. Admin-only:
+// the reason two people are kept apart is nobody else's business.
+export async function fetchPairingRules(): Promise<PairingRule[]> {
+  const res = await fetch("/api/pairing-rules");
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to load the pairing rules"),
+    );
+  }
+  const data = (await res.json()) as ListPairingRulesResponse;
+  return data.pairingRules.map((r) => ({ ...r, note: r.note ?? null }));
+}
+
+// createPairingRule adds one. Throws the server's own message, which says when
+// the two already have a rule or are in the same group.
+export async function createPairingRule(rule: NewPairingRule): Promise<void> {
+  const body: Record<string, string> = {
+    volunteerA: rule.volunteerA,
+    volunteerB: rule.volunteerB,
+    kind: rule.kind,
+  };
+  if (rule.note.trim()) body.note = rule.note.trim();
+
+  const res = await fetch("/api/pairing-rules", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(body),
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to add the rule"));
+  }
+}
+
+// deletePairingRule removes one. The draft of the rota in flight is marked as
+// having moved, as it is for any other change to what the allocator reads.
+export async function deletePairingRule(id: string): Promise<void> {
+  const res = await fetch(`/api/pairing-rules/${encodeURIComponent(id)}`, {
+    method: "DELETE",
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to remove the rule"));
+  }
+}
+
+interface ApiAbsence {
+  id: string;
+  volunteerId: string;
+  name?: string;
+  from: string;
+  to: string;
+  note?: string;
+  createdBy?: string;
+  createdAt: string;
+}
+
+function toAbsence(a: ApiAbsence): Absence {
+  return {
+    ...a,
+    name: a.name ?? "",
+    note: a.note ?? null,
+    createdBy: a.createdBy ?? null,
+  };
+}
+
+// fetchAbsences returns every Absence under way or still to come, soonest
+// first. Admin-only: who is away when is nobody else's business.
+export async function fetchAbsences(): Promise<Absence[]> {
+  const res = await fetch("/api/absences");
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to load the absences"));
+  }
+  const data = (await res.json()) as { absences: ApiAbsence[] };
+  return data.absences.map(toAbsence);
+}
+
+// createAbsence records days away for a volunteer. Throws the server's own
+// message, which says when the dates are the wrong way round or already past.
+export async function createAbsence(
+  volunteerId: string,
+  absence: NewAbsence,
+): Promise<void> {
+  const body: Record<string, string> = {
+    volunteerId,
+    from: absence.from,
+    to: absence.to,
+  };
+  if (absence.note.trim()) body.note = absence.note.trim();
+
+  const res = await fetch("/api/absences", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(body),
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to add the absence"));
+  }
+}
+
+// deleteAbsence removes one. The draft of the rota in flight is marked as
+// having moved, as it is for a Pairing Rule.
+export async function deleteAbsence(id: string): Promise<void> {
+  const res = await fetch(`/api/absences/${encodeURIComponent(id)}`, {
+    method: "DELETE",
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to remove the absence"));
+  }
+}
+
+interface ListCalendarTokensResponse {
+  calendarTokens: CalendarToken[];
+}
+
+// fetchCalendarTokens returns every volunteer's calendar link. Somebody without
+// one is left out rather than listed with nothing. Admin-only: a link is the
+// whole of the secret.
+export async function fetchCalendarTokens(): Promise<CalendarToken[]> {
+  const res = await fetch("/api/calendar-tokens");
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to load the calendar links"),
+    );
+  }
+  const data = (await res.json()) as ListCalendarTokensResponse;
+  return data.calendarTokens;
+}
+
+// mintMissingCalendarTokens gives everybody on the roster without a calendar
+// link one, and leaves everybody else's alone. Returns how many it made.
+export async function mintMissingCalendarTokens(): Promise<number> {
+  const res = await fetch("/api/calendar-tokens", { method: "POST" });
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to make the calendar links"),
+    );
+  }
+  const data = (await res.json()) as { minted: number };
+  return data.minted;
+}
+
+// mintCalendarToken gives one volunteer a new calendar link, which stops the
+// one they had from working.
+export async function mintCalendarToken(
+  volunteerId: string,
+): Promise<CalendarToken> {
+  const res = await fetch(
+    `/api/calendar-tokens/${encodeURIComponent(volunteerId)}`,
+    { method: "POST" },
+  );
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to make a new calendar link"),
+    );
+  }
+  return (await res.json()) as CalendarToken;
+}
+
+// revokeCalendarToken stops a volunteer's calendar link from working and gives
+// them no other until an admin makes one.
+export async function revokeCalendarToken(volunteerId: string): Promise<void> {
+  const res = await fetch(
+    `/api/calendar-tokens/${encodeURIComponent(volunteerId)}`,
+    { method: "DELETE" },
+  );
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to revoke the calendar link"),
+    );
+  }
+}
+
+// fetchVolunteers returns the whole roster, inactive volunteers included,
+// already sorted by name server-side, and where it is kept. Admin-only.
+export async function fetchVolunteers(): Promise<Roster> {
   const res = await fetch("/api/volunteers");
   if (!res.ok) {
     throw new Error(`Failed to load volunteers (${res.status})`);
   }
   const data = (await res.json()) as ListVolunteersResponse;
-  return data.volunteers.map(toVolunteer);
+  return { volunteers: data.volunteers.map(toVolunteer), source: data.source };
+}
+
+// The wire shape of a VolunteerEdit: a pause's end date only with a pause.
+function volunteerEditBody(edit: VolunteerEdit): Record<string, unknown> {
+  const body: Record<string, unknown> = {
+    firstName: edit.firstName.trim(),
+    lastName: edit.lastName.trim(),
+    roles: edit.roles,
+    state: edit.state,
+    gender: edit.gender.trim(),
+    email: edit.email.trim(),
+    group: edit.group.trim(),
+  };
+  if (edit.state === "paused") body.pausedUntil = edit.pausedUntil;
+  return body;
+}
+
+// createVolunteer adds somebody to a roster kept in the database. Throws the
+// server's own message, which names the field it could not accept — or says
+// the roster is the sheet, where nobody is added here.
+export async function createVolunteer(edit: VolunteerEdit): Promise<void> {
+  const res = await fetch("/api/volunteers", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(volunteerEditBody(edit)),
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to add the volunteer"));
+  }
+}
+
+// updateVolunteer rewrites one volunteer, every field at once.
+export async function updateVolunteer(
+  id: string,
+  edit: VolunteerEdit,
+): Promise<void> {
+  const res = await fetch(`/api/volunteers/${encodeURIComponent(id)}`, {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(volunteerEditBody(edit)),
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to save the volunteer"));
+  }
+}
+
+// deleteVolunteer removes somebody added by mistake. The server refuses
+// anybody a rota names, saying to mark them as left instead.
+export async function deleteVolunteer(id: string): Promise<void> {
+  const res = await fetch(`/api/volunteers/${encodeURIComponent(id)}`, {
+    method: "DELETE",
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to remove the volunteer"));
+  }
+}
+
+// importRoster copies the sheet's roster into an empty one in the database,
+// once, and returns how many it copied.
+export async function importRoster(): Promise<number> {
+  const res = await fetch("/api/volunteers/import", { method: "POST" });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to import the roster"));
+  }
+  const data = (await res.json()) as { imported: number };
+  return data.imported;
 }
 
 // fetchRotaProposal reads what the define form starts from: where the next rota
@@ -705,14 +1080,16 @@ function personFields(
 }
 
 // createAlteration records one change to a published rota: an add, a remove, a
-// move or a swap (see RotaChange). It resolves on success and throws the
-// server's own message otherwise — a 409 explains which volunteer contradicts
-// the shift's current state, which is worth showing the admin verbatim.
+// move or a swap (see RotaChange). It resolves to the change's cover id on
+// success — what a send telling the people it moved is recorded against — and
+// throws the server's own message otherwise: a 409 explains which volunteer
+// contradicts the shift's current state, which is worth showing the admin
+// verbatim.
 //
 // The rota it returns is not the changed one: alterations are layered over
 // allocations server-side, so the caller re-fetches the shifts rather than
 // patching what it has.
-export async function createAlteration(change: RotaChange): Promise<void> {
+export async function createAlteration(change: RotaChange): Promise<string> {
   const body: Record<string, string> = {
     date: change.date,
     reason: change.reason,
@@ -730,6 +1107,8 @@ export async function createAlteration(change: RotaChange): Promise<void> {
   if (!res.ok) {
     throw new Error(await errorMessage(res, "Failed to change the rota"));
   }
+  const data = (await res.json()) as { coverId: string };
+  return data.coverId;
 }
 
 // patchShift is the one write behind every per-shift edit. Each field is
@@ -814,6 +1193,8 @@ interface ApiAvailabilityEntry {
   replied: boolean;
   submittedAt?: string;
   availableShiftIds: string[] | null;
+  preferredShiftCount?: number;
+  absentShiftIds: string[] | null;
   coveredBy?: string[];
   roles: string[] | null;
 }
@@ -841,6 +1222,8 @@ interface ApiAvailabilityRound {
     name: string;
     replied: boolean;
     availableShiftIds: string[] | null;
+    preferredShiftCount?: number;
+    absentShiftIds: string[] | null;
     members: ApiAvailabilityEntry[] | null;
   }[];
 }
@@ -858,9 +1241,12 @@ interface ApiAvailabilityForm {
   selectedShiftIds: string[] | null;
   submitted: boolean;
   submittedAt?: string;
+  preferredShiftCount?: number;
   // Optional so an absent field stays quiet rather than warning everyone — see
   // AvailabilityFormState.counts.
   counts?: boolean;
+  absences: ApiAbsence[] | null;
+  absentShiftIds: string[] | null;
 }
 
 function toEntry(e: ApiAvailabilityEntry): AvailabilityEntry {
@@ -872,6 +1258,8 @@ function toEntry(e: ApiAvailabilityEntry): AvailabilityEntry {
     replied: e.replied,
     submittedAt: e.submittedAt ?? null,
     availableShiftIds: e.availableShiftIds ?? [],
+    preferredShiftCount: e.preferredShiftCount ?? null,
+    absentShiftIds: e.absentShiftIds ?? [],
     coveredBy: e.coveredBy ?? [],
     roles: e.roles ?? [],
   };
@@ -889,6 +1277,8 @@ function toRound(data: ApiAvailabilityRound): AvailabilityRound {
       name: g.name,
       replied: g.replied,
       availableShiftIds: g.availableShiftIds ?? [],
+      preferredShiftCount: g.preferredShiftCount ?? null,
+      absentShiftIds: g.absentShiftIds ?? [],
       members: (g.members ?? []).map(toEntry),
     })),
   };
@@ -902,7 +1292,10 @@ function toForm(data: ApiAvailabilityForm): AvailabilityFormState {
     selectedShiftIds: data.selectedShiftIds ?? [],
     submitted: data.submitted,
     submittedAt: data.submittedAt ?? null,
+    preferredShiftCount: data.preferredShiftCount ?? 0,
     counts: data.counts,
+    absences: (data.absences ?? []).map(toAbsence),
+    absentShiftIds: data.absentShiftIds ?? [],
   };
 }
 
@@ -949,14 +1342,18 @@ export async function fetchAvailabilityForm(
 // submitAvailability records one complete answer. shiftIds is everything the
 // volunteer is available for, never just what changed: an absent shift is a no,
 // so a partial send would record unavailability they did not give.
+//
+// preferredShiftCount is how many of those they would like to do, 0 for no
+// preference. It is part of the same answer, so leaving it out clears it.
 export async function submitAvailability(
   token: string,
   shiftIds: string[],
+  preferredShiftCount: number,
 ): Promise<AvailabilityFormState> {
   const res = await fetch(`/api/availability/${encodeURIComponent(token)}`, {
     method: "POST",
     headers: { "Content-Type": "application/json" },
-    body: JSON.stringify({ shiftIds }),
+    body: JSON.stringify({ shiftIds, preferredShiftCount }),
   });
   if (!res.ok) {
     throw (
@@ -967,6 +1364,481 @@ export async function submitAvailability(
   return toForm((await res.json()) as ApiAvailabilityForm);
 }
 
+// addOwnAbsence records days away for the volunteer the link belongs to, and
+// returns their form as it now stands.
+export async function addOwnAbsence(
+  token: string,
+  absence: NewAbsence,
+): Promise<AvailabilityFormState> {
+  const body: Record<string, string> = { from: absence.from, to: absence.to };
+  if (absence.note.trim()) body.note = absence.note.trim();
+
+  const res = await fetch(
+    `/api/availability/${encodeURIComponent(token)}/absences`,
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(body),
+    },
+  );
+  if (!res.ok) {
+    throw (
+      linkFailure(res.status) ??
+      new Error(await errorMessage(res, "Failed to save your time away"))
+    );
+  }
+  return toForm((await res.json()) as ApiAvailabilityForm);
+}
+
+// removeOwnAbsence removes one of the link holder's own Absences, and returns
+// their form as it now stands. A 404 here is the Absence, not the link — the
+// page it is removed from has just loaded through that link — so only a 410
+// is a dead link.
+export async function removeOwnAbsence(
+  token: string,
+  id: string,
+): Promise<AvailabilityFormState> {
+  const res = await fetch(
+    `/api/availability/${encodeURIComponent(token)}/absences/${encodeURIComponent(id)}`,
+    { method: "DELETE" },
+  );
+  if (res.status === 410) throw new AvailabilityLinkError("gone");
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to remove your time away"),
+    );
+  }
+  return toForm((await res.json()) as ApiAvailabilityForm);
+}
+
+// The swap page's payload as it comes off the wire: the ask-related fields are
+// omitted rather than null when there is no ask.
+interface ApiSwapShift {
+  shiftId: string;
+  date: string;
+  start: string;
+  end: string;
+  role?: string;
+  requestId?: string;
+  requestStatus?: "open" | "taken";
+  takenBy?: string;
+}
+
+interface ApiSwapPage {
+  volunteerName: string;
+  shifts: ApiSwapShift[] | null;
+  offers:
+    | {
+        requestId: string;
+        shiftId: string;
+        date: string;
+        start: string;
+        end: string;
+        role?: string;
+        from: string;
+      }[]
+    | null;
+  needsApproval: boolean;
+  leadsShifts: boolean;
+}
+
+function toSwapPage(data: ApiSwapPage): SwapPageState {
+  return {
+    volunteerName: data.volunteerName,
+    shifts: (data.shifts ?? []).map((s) => ({
+      shiftId: s.shiftId,
+      date: s.date,
+      start: s.start,
+      end: s.end,
+      role: s.role ?? null,
+      requestId: s.requestId ?? null,
+      requestStatus: s.requestStatus ?? null,
+      takenBy: s.takenBy ?? null,
+    })),
+    offers: (data.offers ?? []).map((o) => ({ ...o, role: o.role ?? null })),
+    needsApproval: data.needsApproval,
+    leadsShifts: data.leadsShifts,
+  };
+}
+
+// swapCall is one request against a volunteer's swap page. Every one of them
+// answers with the page as it now stands, so the page never re-reads itself
+// after acting. A 404 on the page itself is a link that is not one; on an
+// action it is an ask or an offer that has gone, which is an ordinary message.
+async function swapCall(
+  path: string,
+  init: RequestInit,
+  fallback: string,
+): Promise<SwapPageState> {
+  const res = await fetch(path, init);
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, fallback));
+  }
+  return toSwapPage((await res.json()) as ApiSwapPage);
+}
+
+// fetchSwapPage loads a volunteer's swap page: the same link as their
+// availability form, once the rota it asked about has been worked out. Public,
+// for the reason the form is.
+export async function fetchSwapPage(token: string): Promise<SwapPageState> {
+  const res = await fetch(`/api/swaps/${encodeURIComponent(token)}`);
+  if (res.status === 404) throw new AvailabilityLinkError("not-found");
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to load your shifts"));
+  }
+  return toSwapPage((await res.json()) as ApiSwapPage);
+}
+
+// requestSwap asks to give up one of the link's shifts, which offers it to
+// everyone who could take it.
+export function requestSwap(
+  token: string,
+  shiftId: string,
+): Promise<SwapPageState> {
+  return swapCall(
+    `/api/swaps/${encodeURIComponent(token)}/requests`,
+    {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ shiftId }),
+    },
+    "Failed to offer your shift",
+  );
+}
+
+// withdrawSwap takes an ask back before anybody has been given the shift.
+export function withdrawSwap(
+  token: string,
+  requestId: string,
+): Promise<SwapPageState> {
+  return swapCall(
+    `/api/swaps/${encodeURIComponent(token)}/requests/${encodeURIComponent(requestId)}`,
+    { method: "DELETE" },
+    "Failed to take your offer back",
+  );
+}
+
+// acceptSwap takes a shift on offer. The first to accept gets it; a second
+// is refused with a message saying somebody else already has.
+export function acceptSwap(
+  token: string,
+  requestId: string,
+): Promise<SwapPageState> {
+  return swapCall(
+    `/api/swaps/${encodeURIComponent(token)}/offers/${encodeURIComponent(requestId)}/acceptance`,
+    { method: "POST" },
+    "Failed to take the shift",
+  );
+}
+
+// fetchSwapRequests lists every live swap, for the admins.
+export async function fetchSwapRequests(): Promise<SwapRequest[]> {
+  const res = await fetch("/api/swap-requests");
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to load the swaps"));
+  }
+  const data = (await res.json()) as
+    | (Omit<SwapRequest, "role" | "takenBy" | "takenByName" | "takenAt"> & {
+        role?: string;
+        takenBy?: string;
+        takenByName?: string;
+        takenAt?: string;
+      })[]
+    | null;
+  return (data ?? []).map((r) => ({
+    ...r,
+    role: r.role ?? null,
+    takenBy: r.takenBy ?? null,
+    takenByName: r.takenByName ?? null,
+    takenAt: r.takenAt ?? null,
+  }));
+}
+
+// approveSwap makes a waiting swap, in the approving admin's name. Resolves
+// with the Cover it was recorded as.
+export async function approveSwap(id: string): Promise<string> {
+  const res = await fetch(
+    `/api/swap-requests/${encodeURIComponent(id)}/approval`,
+    { method: "POST" },
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to approve the swap"));
+  }
+  const data = (await res.json()) as { coverId: string };
+  return data.coverId;
+}
+
+// declineSwap refuses a waiting swap; the volunteer who asked keeps the shift.
+export async function declineSwap(id: string): Promise<void> {
+  const res = await fetch(
+    `/api/swap-requests/${encodeURIComponent(id)}/refusal`,
+    { method: "POST" },
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to decline the swap"));
+  }
+}
+
+// CoverLinkClosed is a cover link whose request has stopped answering: the
+// shift was covered, cover is no longer needed, or it has started. The server
+// says which, in words meant for the volunteer, and the page shows them as
+// they are — unlike a dead availability link, being late is not a mistake.
+export class CoverLinkClosed extends Error {
+  constructor(message: string) {
+    super(message);
+    this.name = "CoverLinkClosed";
+  }
+}
+
+interface ApiCoverForm {
+  firstName: string;
+  date: string;
+  start: string;
+  end: string;
+  role: string;
+  answer?: "yes" | "no";
+}
+
+function toCoverForm(data: ApiCoverForm): CoverFormState {
+  return { ...data, answer: data.answer ?? null };
+}
+
+async function coverLinkFailure(
+  res: Response,
+  fallback: string,
+): Promise<Error> {
+  if (res.status === 404) return new AvailabilityLinkError("not-found");
+  const message = await errorMessage(res, fallback);
+  if (res.status === 410) return new CoverLinkClosed(message);
+  return new Error(message);
+}
+
+// fetchCoverForm loads what is behind a volunteer's cover link. Public, for
+// the reason the availability form is.
+export async function fetchCoverForm(token: string): Promise<CoverFormState> {
+  const res = await fetch(`/api/cover/${encodeURIComponent(token)}`);
+  if (!res.ok) {
+    throw await coverLinkFailure(res, "Failed to load the shift");
+  }
+  return toCoverForm((await res.json()) as ApiCoverForm);
+}
+
+// answerCover says yes or no on a cover link, and resolves with the link as
+// it now stands. A yes puts nobody on the rota: it tells the admins who to ask.
+export async function answerCover(
+  token: string,
+  answer: "yes" | "no",
+): Promise<CoverFormState> {
+  const res = await fetch(`/api/cover/${encodeURIComponent(token)}`, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ answer }),
+  });
+  if (!res.ok) {
+    throw await coverLinkFailure(res, "Failed to send your answer");
+  }
+  return toCoverForm((await res.json()) as ApiCoverForm);
+}
+
+interface ApiAttendanceEntry {
+  volunteerId: string;
+  volunteerName: string;
+  role: string;
+  status?: AttendanceStatus;
+  recordedBy?: string;
+  recordedByName?: string;
+  recordedAt?: string;
+}
+
+interface ApiShiftAttendance {
+  shiftId: string;
+  date: string;
+  start: string;
+  end: string;
+  people: ApiAttendanceEntry[] | null;
+}
+
+interface ApiLeadAttendance {
+  volunteerName: string;
+  role: string;
+  shifts: ApiShiftAttendance[] | null;
+}
+
+function toShiftAttendance(data: ApiShiftAttendance): ShiftAttendance {
+  return {
+    ...data,
+    people: (data.people ?? []).map((p) => ({
+      ...p,
+      status: p.status ?? null,
+      recordedBy: p.recordedBy ?? null,
+      recordedByName: p.recordedByName ?? null,
+      recordedAt: p.recordedAt ?? null,
+    })),
+  };
+}
+
+function toLeadAttendance(data: ApiLeadAttendance): LeadAttendancePage {
+  return { ...data, shifts: (data.shifts ?? []).map(toShiftAttendance) };
+}
+
+// attendanceInit is how a change to one person's attendance is sent: a
+// status is put, and null takes back whatever was recorded.
+function attendanceInit(status: AttendanceStatus | null): RequestInit {
+  if (status === null) return { method: "DELETE" };
+  return {
+    method: "PUT",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ status }),
+  };
+}
+
+// fetchShiftAttendance loads who was on a shift that has happened, and what
+// has been recorded of them, for the admins.
+export async function fetchShiftAttendance(
+  shiftId: string,
+): Promise<ShiftAttendance> {
+  const res = await fetch(
+    `/api/shifts/${encodeURIComponent(shiftId)}/attendance`,
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to load the attendance"));
+  }
+  return toShiftAttendance((await res.json()) as ApiShiftAttendance);
+}
+
+// recordAttendance records whether one person turned up to a shift, or with
+// null forgets it, and resolves with the shift as it now stands.
+export async function recordAttendance(
+  shiftId: string,
+  volunteerId: string,
+  status: AttendanceStatus | null,
+): Promise<ShiftAttendance> {
+  const shift = encodeURIComponent(shiftId);
+  const volunteer = encodeURIComponent(volunteerId);
+  const res = await fetch(
+    `/api/shifts/${shift}/attendance/${volunteer}`,
+    attendanceInit(status),
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to record attendance"));
+  }
+  return toShiftAttendance((await res.json()) as ApiShiftAttendance);
+}
+
+// fetchLeadAttendance loads a Team lead's attendance page: the same link as
+// their availability form, showing the shifts they led. Public, for the
+// reason the form is.
+export async function fetchLeadAttendance(
+  token: string,
+): Promise<LeadAttendancePage> {
+  const res = await fetch(`/api/attendance/${encodeURIComponent(token)}`);
+  if (res.status === 404) throw new AvailabilityLinkError("not-found");
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to load your shifts"));
+  }
+  return toLeadAttendance((await res.json()) as ApiLeadAttendance);
+}
+
+// recordLeadAttendance is a Team lead marking somebody on a shift they led,
+// and resolves with their page as it now stands.
+export async function recordLeadAttendance(
+  token: string,
+  shiftId: string,
+  volunteerId: string,
+  status: AttendanceStatus | null,
+): Promise<LeadAttendancePage> {
+  const link = encodeURIComponent(token);
+  const shift = encodeURIComponent(shiftId);
+  const volunteer = encodeURIComponent(volunteerId);
+  const res = await fetch(
+    `/api/attendance/${link}/shifts/${shift}/${volunteer}`,
+    attendanceInit(status),
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to record attendance"));
+  }
+  return toLeadAttendance((await res.json()) as ApiLeadAttendance);
+}
+
+type ApiCoverRequest = Omit<CoverRequest, "replacing" | "replacingName"> & {
+  replacing?: string;
+  replacingName?: string;
+};
+
+function toCoverRequest(r: ApiCoverRequest): CoverRequest {
+  return {
+    ...r,
+    replacing: r.replacing ?? null,
+    replacingName: r.replacingName ?? null,
+    yes: r.yes ?? [],
+  };
+}
+
+// fetchCoverRequests lists every open cover request, for the admins.
+export async function fetchCoverRequests(): Promise<CoverRequest[]> {
+  const res = await fetch("/api/cover-requests");
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to load the cover requests"),
+    );
+  }
+  const data = (await res.json()) as ApiCoverRequest[] | null;
+  return (data ?? []).map(toCoverRequest);
+}
+
+// createCoverRequest asks everyone who could cover a place whether they can.
+// It emails nobody: that is a send, started with sendUrl("cover-request") and
+// the id this resolves with.
+export async function createCoverRequest(
+  req: NewCoverRequest,
+): Promise<CoverRequest> {
+  const body: Record<string, string> = {
+    shiftId: req.shiftId,
+    reason: req.reason,
+  };
+  if (req.role) body.role = req.role;
+  if (req.replacing) body.replacing = req.replacing;
+  const res = await fetch("/api/cover-requests", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(body),
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to ask for cover"));
+  }
+  return toCoverRequest((await res.json()) as ApiCoverRequest);
+}
+
+// acceptCoverAnswer puts a volunteer who said yes on the shift, in the
+// accepting admin's name. Resolves with the Cover it was recorded as.
+export async function acceptCoverAnswer(
+  id: string,
+  volunteerId: string,
+): Promise<string> {
+  const res = await fetch(
+    `/api/cover-requests/${encodeURIComponent(id)}/answers/${encodeURIComponent(volunteerId)}/acceptance`,
+    { method: "POST" },
+  );
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to put them on the rota"));
+  }
+  const data = (await res.json()) as { coverId: string };
+  return data.coverId;
+}
+
+// cancelCoverRequest stops asking. Its links tell whoever opens them that
+// cover is no longer needed.
+export async function cancelCoverRequest(id: string): Promise<void> {
+  const res = await fetch(`/api/cover-requests/${encodeURIComponent(id)}`, {
+    method: "DELETE",
+  });
+  if (!res.ok) {
+    throw new Error(
+      await errorMessage(res, "Failed to cancel the cover request"),
+    );
+  }
+}
+
 // fetchAvailabilityRound reads the latest rota's round: who was asked, their
 // link, and who has answered. Admin-only — it returns every volunteer's link.
 export async function fetchAvailabilityRound(): Promise<AvailabilityRound> {
@@ -999,15 +1871,25 @@ interface ApiSendOutcome {
   error?: string;
 }
 
-interface ApiAvailabilitySend {
+interface ApiAvailabilitySendSummary {
   id: string;
   mode: string;
+  adminEmail: string;
+  volunteerId?: string;
+  status: string;
+  startedAt: string;
+  finishedAt?: string;
   done: number;
   total: number;
+  sentCount: number;
+  failedCount: number;
+  error?: string;
+}
+
+interface ApiAvailabilitySend extends ApiAvailabilitySendSummary {
   finished: boolean;
   sent: ApiSendOutcome[] | null;
   failed: ApiSendOutcome[] | null;
-  error?: string;
 }
 
 function toOutcome(o: ApiSendOutcome): SendOutcome {
@@ -1025,24 +1907,50 @@ function toOutcome(o: ApiSendOutcome): SendOutcome {
 // consent screen and back.
 //
 // The deadline is quoted in the email and nowhere else. It is not stored, not
-// shown on the site and not enforced; allocation is the real cutoff.
+// shown on the site and not enforced; allocation is the real cutoff. An
+// allocation send has none — it goes out after the cutoff — so an empty one is
+// left off rather than sent blank. Nor does a cover send, which names the rota
+// change it is about instead, or a cover-request or swap-offer send, which names
+// the request.
 export function sendUrl(
   mode: SendMode,
   deadline: string,
   volunteerId?: string,
+  coverId?: string,
+  coverRequestId?: string,
+  swapRequestId?: string,
 ): string {
-  const params = new URLSearchParams({ mode, deadline });
+  const params = new URLSearchParams({ mode });
+  if (deadline) params.set("deadline", deadline);
   if (volunteerId) params.set("volunteerId", volunteerId);
+  if (coverId) params.set("coverId", coverId);
+  if (coverRequestId) params.set("coverRequestId", coverRequestId);
+  if (swapRequestId) params.set("swapRequestId", swapRequestId);
   return `/auth/gmail?${params.toString()}`;
 }
 
-// fetchSend reports on a send in progress or just finished. Admin-only, and
-// readable only by the admin who started it: it names every volunteer it reached
-// and every address it failed on.
-//
-// A send that has aged out of the server's memory is a 404, which is also the
-// answer for one that never existed — the same thing to a page that has an id
-// from an old tab.
+// resumeUrl is the address that carries on with an interrupted send. It goes
+// back through Google for the same reason sendUrl does: the grant the send
+// started with was never kept, so it went with the server that was using it.
+export function resumeUrl(id: string): string {
+  return `/auth/gmail?${new URLSearchParams({ resume: id }).toString()}`;
+}
+
+// printRotaUrl is a rota as a PDF to pin up. Opened rather than fetched: the
+// browser's own viewer is where the print button is.
+export function printRotaUrl(rotaId: string): string {
+  return `/api/rotations/${encodeURIComponent(rotaId)}/print`;
+}
+
+// signInSheetUrl is one shift's sign-in sheet as a PDF, opened the same way.
+export function signInSheetUrl(shiftId: string): string {
+  return `/api/shifts/${encodeURIComponent(shiftId)}/sign-in-sheet`;
+}
+
+// fetchSend reports on a send, running or finished. Admin-only, and readable
+// only by the admin who started it: it names every volunteer it reached and
+// every address it failed on. Sends are kept, so an id from an old tab still
+// reads; a 404 is one that never existed, or is somebody else's.
 export async function fetchSend(id: string): Promise<AvailabilitySend> {
   const res = await fetch(`/api/availability-sends/${encodeURIComponent(id)}`);
   if (!res.ok) {
@@ -1052,6 +1960,8 @@ export async function fetchSend(id: string): Promise<AvailabilitySend> {
   return {
     id: data.id,
     mode: data.mode as SendMode,
+    status: data.status as SendStatus,
+    startedAt: data.startedAt,
     done: data.done,
     total: data.total,
     finished: data.finished,
@@ -1061,12 +1971,68 @@ export async function fetchSend(id: string): Promise<AvailabilitySend> {
   };
 }
 
-// syncVolunteers re-reads the roster sheet into the database. The server uses
-// its own service account, so this is a plain authenticated POST with no OAuth
-// redirect dance.
-export async function syncVolunteers(): Promise<void> {
-  const res = await fetch("/auth/sync", { method: "POST" });
+// fetchSendHistory lists a round's sends, newest first, with no rotaId meaning
+// the latest round.
+export async function fetchSendHistory(
+  rotaId?: string,
+): Promise<AvailabilitySendSummary[]> {
+  const query = rotaId ? `?${new URLSearchParams({ rotaId }).toString()}` : "";
+  const res = await fetch(`/api/availability-sends${query}`);
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to load the sends"));
+  }
+  const data = (await res.json()) as ApiAvailabilitySendSummary[] | null;
+  return (data ?? []).map((s) => ({
+    id: s.id,
+    mode: s.mode as SendMode,
+    adminEmail: s.adminEmail,
+    volunteerId: s.volunteerId ?? null,
+    status: s.status as SendStatus,
+    startedAt: s.startedAt,
+    finishedAt: s.finishedAt ?? null,
+    done: s.done,
+    total: s.total,
+    sentCount: s.sentCount,
+    failedCount: s.failedCount,
+    error: s.error ?? null,
+  }));
+}
+
+// previewSync reads the roster sheet and says how the roster would change,
+// applying nothing. The server uses its own service account, so this is a
+// plain authenticated POST with no OAuth redirect dance.
+export async function previewSync(): Promise<SyncPreview> {
+  const res = await fetch("/api/volunteers/sync/preview", { method: "POST" });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to read the sheet"));
+  }
+  return (await res.json()) as SyncPreview;
+}
+
+// applySync replaces the roster with the one a preview read, and returns the
+// diff applied. Throws the server's own message for a preview gone stale —
+// another preview since, or the roster replaced under it — which says to
+// preview again.
+export async function applySync(previewId: string): Promise<RosterDiff> {
+  const res = await fetch("/api/volunteers/sync", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ previewId }),
+  });
+  if (!res.ok) {
+    throw new Error(await errorMessage(res, "Failed to sync volunteers"));
+  }
+  const data = (await res.json()) as { diff: RosterDiff };
+  return data.diff;
+}
+
+// fetchRosterSyncs returns the most recent syncs an admin confirmed, newest
+// first.
+export async function fetchRosterSyncs(): Promise<RosterSync[]> {
+  const res = await fetch("/api/volunteers/syncs");
   if (!res.ok) {
-    throw new Error(`Failed to sync volunteers (${res.status})`);
+    throw new Error(await errorMessage(res, "Failed to load the syncs"));
   }
+  const data = (await res.json()) as { syncs: RosterSync[] };
+  return data.syncs;
 }
@@ -1,4 +1,5 @@
 import { createContext, useContext } from "react";
+import type { RosterSyncStatus } from "./types";
 
 // AuthState is the global admin session, exposed to every component so that
 // admin-only UI can show or hide itself based on who (if anyone) is logged in.
@@ -10,6 +11,12 @@ export interface AuthState {
   loading: boolean;
   // logout clears the session and updates the global state.
   logout: () => Promise<void>;
+  // How fresh the roster is, where the sheet is the roster and an admin is
+  // logged in; null otherwise.
+  rosterSync: RosterSyncStatus | null;
+  // Checks the session again, for after something it reports on has moved —
+  // a sync applied, say.
+  refresh: () => Promise<void>;
 }
 
 export const AuthContext = createContext<AuthState | undefined>(undefined);
@@ -1,10 +1,13 @@
 import { useState } from "react";
 import Button from "../ui/Button";
 import Dialog from "../ui/Dialog";
+import { sendUrl } from "../api";
 import { useRoles } from "../hooks/useRoles";
 import { useRotaDefaults } from "../hooks/useRotaDefaults";
 import { useStandingPreallocations } from "../hooks/useStandingPreallocations";
+import { useSwapRequests } from "../hooks/useSwapRequests";
 import { useVolunteers } from "../hooks/useVolunteers";
+import EmailTemplatesSettings from "./EmailTemplatesSettings";
 import RotaDefaultsCard from "./RotaDefaultsCard";
 import SettingsSection from "./SettingsSection";
 import type {
@@ -14,10 +17,12 @@ import type {
   PersonRef,
   RoleColour,
   RoleEdit,
+  SwapRequest,
   SwitchableConstraint,
   Volunteer,
 } from "../types";
 import { CUSTOM_CHOICE, DEFAULT_ROLE_COLOUR, ROLE_COLOURS } from "../types";
+import { formatShiftDateLong } from "./shifts";
 import "./AdminSettings.css";
 
 // The colour a new Role starts on: the dullest token, so an admin who does not
@@ -536,8 +541,49 @@ function StandingPreallocationsSettings() {
   );
 }
 
-// AllocationRulesForm switches the optional allocator rules on and off, and
-// asks for the one value a rule carries.
+// DECAY_CHOICES are the decays the form offers, gentlest first. 0 is none:
+// every rota in the horizon counts the same.
+const DECAY_CHOICES = [0, 0.75, 0.5, 0.25];
+
+// decayLabel says what a decay does in words, as the form offers it.
+function decayLabel(decay: number): string {
+  switch (decay) {
+    case 0:
+    case 1:
+      return "the same";
+    case 0.75:
+      return "three-quarters as much";
+    case 0.5:
+      return "half as much";
+    case 0.25:
+      return "a quarter as much";
+    default:
+      return `${Math.round(decay * 100)}% as much`;
+  }
+}
+
+// describeHorizon is the Fairness Horizon as a sentence, with the decay only
+// when it has anything older than the previous rota to act on.
+function describeHorizon(settings: AllocationSettings): string {
+  let horizon = "The previous rota";
+  if (settings.fairnessMonths > 0) {
+    horizon = `The last ${settings.fairnessMonths} month${
+      settings.fairnessMonths === 1 ? "" : "s"
+    }`;
+  } else if (settings.fairnessRotas > 1) {
+    horizon = `The last ${settings.fairnessRotas} rotas`;
+  }
+  const decays = settings.fairnessDecay > 0 && settings.fairnessDecay < 1;
+  if (horizon === "The previous rota" || !decays) {
+    return horizon;
+  }
+  return `${horizon} \u2014 each rota further back counts ${decayLabel(
+    settings.fairnessDecay,
+  )}`;
+}
+
+// AllocationRulesForm switches the optional allocator rules on and off, asks
+// for the one value a rule carries, and sets the Fairness Horizon.
 //
 // Every rule the server offered is drawn from the list it sent, so a rule
 // arriving or leaving needs no change here: the registry lives in Go and this
@@ -564,16 +610,39 @@ function AllocationRulesForm({
       ? String(Math.round(settings.maxFrequency * 100))
       : "",
   );
+  // The horizon is one count in one of two units, so it is edited as a count
+  // and a unit and split back into the two fields on the way out.
+  const [horizonUnit, setHorizonUnit] = useState<"rotas" | "months">(
+    settings.fairnessMonths > 0 ? "months" : "rotas",
+  );
+  const [horizonCount, setHorizonCount] = useState(
+    String(settings.fairnessMonths || settings.fairnessRotas || 1),
+  );
+  const [decay, setDecay] = useState(String(settings.fairnessDecay));
+  const [skipsNoShows, setSkipsNoShows] = useState(
+    settings.fairnessSkipsNoShows,
+  );
   const [saving, setSaving] = useState(false);
   const [error, setError] = useState<string | null>(null);
 
+  // A decay saved by something other than this form still shows as itself
+  // rather than as whichever choice happens to be first.
+  const decayChoices = DECAY_CHOICES.includes(settings.fairnessDecay)
+    ? DECAY_CHOICES
+    : [...DECAY_CHOICES, settings.fairnessDecay];
+
   async function save() {
     setSaving(true);
     setError(null);
+    const count = horizonCount === "" ? 0 : Number(horizonCount);
     try {
       await onSave({
         enabled,
         maxFrequency: percent === "" ? 0 : Number(percent) / 100,
+        fairnessRotas: horizonUnit === "rotas" ? count : 0,
+        fairnessMonths: horizonUnit === "months" ? count : 0,
+        fairnessDecay: Number(decay),
+        fairnessSkipsNoShows: skipsNoShows,
       });
       onClose();
     } catch (err: unknown) {
@@ -634,6 +703,64 @@ function AllocationRulesForm({
           </div>
         ))}
 
+        {/* Not a rule but a tuning of one that is always on, so it has no
+            switch: fairness always looks back, and this says how far. */}
+        <div className="rule-choice">
+          <p className="rule-switch">How far back fairness looks</p>
+          <p className="settings-hint rule-description">
+            The allocator reaches first for whoever has worked least lately.
+            This is what &ldquo;lately&rdquo; means. One rota is the previous
+            rota alone.
+          </p>
+          <div className="rule-value horizon-fields">
+            <label className="settings-field">
+              The last
+              <input
+                type="number"
+                min={1}
+                max={horizonUnit === "rotas" ? 12 : 24}
+                step={1}
+                value={horizonCount}
+                onChange={(e) => setHorizonCount(e.target.value)}
+              />
+            </label>
+            <label className="settings-field">
+              Counted in
+              <select
+                value={horizonUnit}
+                onChange={(e) =>
+                  setHorizonUnit(e.target.value as "rotas" | "months")
+                }
+              >
+                <option value="rotas">rotas</option>
+                <option value="months">months</option>
+              </select>
+            </label>
+          </div>
+          <label className="settings-field rule-value">
+            Each rota further back counts
+            <select value={decay} onChange={(e) => setDecay(e.target.value)}>
+              {decayChoices.map((choice) => (
+                <option key={choice} value={String(choice)}>
+                  {decayLabel(choice)}
+                </option>
+              ))}
+            </select>
+          </label>
+          <label className="rule-switch rule-value">
+            <input
+              type="checkbox"
+              checked={skipsNoShows}
+              onChange={(e) => setSkipsNoShows(e.target.checked)}
+            />
+            Leave out shifts somebody did not turn up to
+          </label>
+          <p className="settings-hint rule-description">
+            A shift recorded as a no-show stops counting as one they worked, so
+            they are not rested for it next time.
+          </p>
+        </div>
+
         {error && <p className="settings-error">{error}</p>}
 
         <div className="settings-actions">
@@ -697,6 +824,10 @@ function AllocationRulesSettings() {
                 </dd>
               </div>
             ))}
+            <div className="settings-fact">
+              <dt>How far back fairness looks</dt>
+              <dd>{describeHorizon(defaults.allocationSettings)}</dd>
+            </div>
           </dl>
           {onRules.length === 0 && (
             <p className="settings-caption">
@@ -719,10 +850,141 @@ function AllocationRulesSettings() {
   );
 }
 
+// One live swap. Only a taken one has anything to answer: an open one is
+// listed so an admin can see who is looking for cover, and is left to the
+// volunteers.
+// Emailing is a full-page trip through Google for the gmail.send grant, so it
+// is a navigation rather than a fetch — the report lands on the rota page.
+function emailSwapOffer(id: string) {
+  window.location.assign(
+    sendUrl("swap-offer", "", undefined, undefined, undefined, id),
+  );
+}
+
+function SwapRequestRow({
+  request,
+  onApprove,
+  onDecline,
+}: {
+  request: SwapRequest;
+  onApprove: () => Promise<void>;
+  onDecline: () => Promise<void>;
+}) {
+  const [busy, setBusy] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const answer = async (apply: () => Promise<void>) => {
+    setBusy(true);
+    setError(null);
+    try {
+      await apply();
+    } catch (err: unknown) {
+      setError(err instanceof Error ? err.message : "Failed to answer");
+      setBusy(false);
+    }
+  };
+
+  return (
+    <li className="role-row">
+      <span className="role-name">
+        {formatShiftDateLong(request.date)}
+        {request.role && ` · ${request.role}`}
+      </span>
+      <span className="role-facts">
+        <span className="role-fact">
+          {request.takenByName
+            ? `${request.volunteerName} to ${request.takenByName}`
+            : `${request.volunteerName} is looking for cover`}
+        </span>
+        {error && <span className="settings-error">{error}</span>}
+      </span>
+      {request.status === "open" && (
+        <Button size="small" onClick={() => emailSwapOffer(request.id)}>
+          Email who could take it
+        </Button>
+      )}
+      {request.status === "taken" && (
+        <>
+          <Button
+            size="small"
+            disabled={busy}
+            onClick={() => void answer(onApprove)}
+          >
+            Approve
+          </Button>
+          <Button
+            size="small"
+            disabled={busy}
+            onClick={() => void answer(onDecline)}
+          >
+            Decline
+          </Button>
+        </>
+      )}
+    </li>
+  );
+}
+
+// SwapSettings is the volunteers' own swaps: whether one they arrange waits
+// for an admin, and the ones live now. The list is here rather than on a tab
+// of its own because it is the switch's consequence — with approval off it is
+// only ever a view of who is looking for cover.
+function SwapSettings() {
+  const { defaults, saveSwapApproval } = useRotaDefaults();
+  const { requests, error, approve, decline } = useSwapRequests();
+  const [saveError, setSaveError] = useState<string | null>(null);
+
+  return (
+    <SettingsSection
+      title="Swaps"
+      blurb="Volunteers can give up a shift from their link once the rota is out. It is offered to everyone free that day who holds the Role, and the first to take it goes on the rota in their place."
+    >
+      {defaults === null && <p className="settings-empty">Loading…</p>}
+
+      {defaults !== null && (
+        <label className="rule-switch">
+          <input
+            type="checkbox"
+            checked={defaults.swapsNeedApproval}
+            onChange={(e) => {
+              setSaveError(null);
+              saveSwapApproval(e.target.checked).catch((err: unknown) =>
+                setSaveError(
+                  err instanceof Error ? err.message : "Failed to save",
+                ),
+              );
+            }}
+          />
+          An admin approves each swap before the rota changes
+        </label>
+      )}
+      {saveError && <p className="settings-error">{saveError}</p>}
+
+      {error && <p className="settings-error">Could not load swaps: {error}</p>}
+      {requests !== null && requests.length === 0 && (
+        <p className="settings-caption">Nobody is swapping a shift just now.</p>
+      )}
+      {requests !== null && requests.length > 0 && (
+        <ul className="roles">
+          {requests.map((request) => (
+            <SwapRequestRow
+              key={request.id}
+              request={request}
+              onApprove={() => approve(request.id)}
+              onDecline={() => decline(request.id)}
+            />
+          ))}
+        </ul>
+      )}
+    </SettingsSection>
+  );
+}
+
 // AdminSettings is everything an admin decides about how the drop-in runs, as
 // opposed to what an operator sets when deploying it (ADR 0006). It is a stack
 // of independent sections: the Rota Defaults the whole drop-in runs on, the
-// Roles volunteers hold, and the pins made every rota.
+// Roles volunteers hold, the emails volunteers are sent, the swaps they make
+// between themselves, and the pins made every rota.
 //
 // The Rota Defaults card is the one section that is not only here — the define
 // screen shows the same component, because defining a rota is spending it
@@ -733,6 +995,8 @@ export default function AdminSettings() {
     <>
       <RotaDefaultsCard />
       <AllocationRulesSettings />
+      <EmailTemplatesSettings />
+      <SwapSettings />
       <RolesSettings />
       <StandingPreallocationsSettings />
     </>
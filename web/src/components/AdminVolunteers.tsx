@@ -1,9 +1,31 @@
-import { useMemo } from "react";
+import { useMemo, useState } from "react";
 import Button from "../ui/Button";
+import Dialog from "../ui/Dialog";
+import { useAuth } from "../auth-context";
+import { useAbsences } from "../hooks/useAbsences";
+import { useCalendarTokens } from "../hooks/useCalendarTokens";
+import { usePairingRules } from "../hooks/usePairingRules";
+import { useRosterSyncs } from "../hooks/useRosterSyncs";
 import type { RoleColourOf } from "../hooks/useRoles";
 import { useRoles } from "../hooks/useRoles";
 import { useVolunteers, type SyncState } from "../hooks/useVolunteers";
-import type { Volunteer } from "../types";
+import type {
+  Absence,
+  ConfiguredRole,
+  NewAbsence,
+  NewPairingRule,
+  PairingKind,
+  RosterSource,
+  RosterSyncStatus,
+  SyncPreview,
+  Volunteer,
+  VolunteerEdit,
+  VolunteerState,
+} from "../types";
+import { SyncHistory, SyncPreviewDialog } from "./RosterSync";
+import SettingsSection from "./SettingsSection";
+import { formatShiftDateLong } from "./shifts";
+import { timeAgo } from "./timeAgo";
 import "./AdminVolunteers.css";
 
 // The sync caption doubles as its own outcome message, so the line under the
@@ -11,10 +33,31 @@ import "./AdminVolunteers.css";
 const SYNC_CAPTION: Record<SyncState, string> = {
   idle: "Re-reads the Google Sheet",
   syncing: "Re-reading the Google Sheet…",
+  reviewing: "Review the changes to apply them",
   ok: "Roster up to date",
   error: "Sync failed — please try again",
 };
 
+// idleCaption is what the sync says while nobody is using it: how stale the
+// roster is, since the sheet moves on between syncs and a volunteer added to it
+// is not on the roster until the next. A failed read is worth its own line —
+// the roster shown is then older than whoever set up the timer expects.
+function idleCaption(status: RosterSyncStatus | null): {
+  text: string;
+  failed: boolean;
+} {
+  if (status === null || status.outcome === "none") {
+    return { text: SYNC_CAPTION.idle, failed: false };
+  }
+  if (status.lastSyncedAt === null) {
+    return { text: "The Google Sheet could not be read", failed: true };
+  }
+  const synced = `Last synced ${timeAgo(status.lastSyncedAt)}`;
+  return status.outcome === "failed"
+    ? { text: `${synced}; the latest read failed`, failed: true }
+    : { text: synced, failed: false };
+}
+
 interface RosterCounts {
   activeVolunteers: number;
   // One entry per Role anybody active holds, in priority order. Derived from
@@ -24,8 +67,22 @@ interface RosterCounts {
   // null when nobody is active, since there is then no denominator to take a
   // percentage of — shown as a dash rather than 0%, which would read as a fact.
   malePercentage: number | null;
+  // Everybody on the sheet by state, in STATE_LABELS order. Unrecognised is
+  // left out when nobody is in it: it is an alarm, and an alarm reading 0 is
+  // noise.
+  byState: { state: VolunteerState; count: number }[];
 }
 
+// How each state reads on the roster, in the order the counts are shown:
+// volunteering first, then away, then gone, then the sheet cells to fix.
+const STATE_LABELS: { state: VolunteerState; label: string }[] = [
+  { state: "active", label: "Active" },
+  { state: "onboarding", label: "Onboarding" },
+  { state: "paused", label: "Paused" },
+  { state: "left", label: "Left" },
+  { state: "unrecognised", label: "Status not recognised" },
+];
+
 // Gender is free text from the sheet, so "male" is matched case-insensitively
 // and anything else — "Female", "Prefer not to say", blank — simply is not male.
 // One definition, used by both the count and the tag, so the percentage can never
@@ -38,7 +95,9 @@ function isMale(volunteer: Volunteer): boolean {
 // who can actually be rostered, not who has ever been on the sheet. The per-Role
 // counts are subsets of that same total, not separate populations, and they
 // overlap each other — somebody who will lead and will do an ordinary shift is
-// counted under both, so these do not add up to the total.
+// counted under both, so these do not add up to the total. The counts by state
+// are the exception, and are over everybody: they are how an admin sees who is
+// not active, and why.
 function countRoster(volunteers: Volunteer[]): RosterCounts {
   const active = volunteers.filter((v) => v.active);
   const male = active.filter(isMale).length;
@@ -56,7 +115,13 @@ function countRoster(volunteers: Volunteer[]): RosterCounts {
     });
   }
 
+  const byState = STATE_LABELS.map(({ state }) => ({
+    state,
+    count: volunteers.filter((v) => v.state === state).length,
+  })).filter(({ state, count }) => state !== "unrecognised" || count > 0);
+
   return {
+    byState,
     activeVolunteers: active.length,
     byRole: [...counts]
       .map(([role, count]) => ({ role, count }))
@@ -95,12 +160,18 @@ function Count({
 //
 // Not being active is one of those exceptions, so it is tagged as well as dimmed —
 // the tag is what carries the state to a screen reader, which cannot see dimming.
+// So is onboarding, which is active but not yet in every Role they hold.
+//
+// onEdit is only passed where the roster is kept in the database: a sheet's
+// rows are edited in the sheet.
 function RosterRow({
   volunteer,
   colourOf,
+  onEdit,
 }: {
   volunteer: Volunteer;
   colourOf: RoleColourOf;
+  onEdit?: () => void;
 }) {
   return (
     <li
@@ -126,94 +197,1103 @@ function RosterRow({
             {volunteer.group}
           </span>
         )}
-        {!volunteer.active && (
-          <span className="roster-tag roster-tag--inactive">Not active</span>
-        )}
+        <StateTag volunteer={volunteer} />
       </span>
+      {onEdit && (
+        <Button size="small" onClick={onEdit}>
+          Edit
+        </Button>
+      )}
     </li>
   );
 }
 
-// AdminVolunteers is the volunteers tab: the synced roster, a summary of it, and
-// the button that re-syncs it. The sync sits top right and stays small — it is
-// an occasional maintenance action, not the point of the page.
-export default function AdminVolunteers() {
-  const { volunteers, error, syncState, sync } = useVolunteers();
-  // A Role wears its configured colour here as well as on the rota, so a lead
-  // looks like a lead wherever they appear.
-  const { colourOf } = useRoles();
-  const counts = useMemo(
-    () => (volunteers ? countRoster(volunteers) : null),
-    [volunteers],
+// StateTag says where somebody is when it is not simply active. A pause says
+// when it ends, since "paused" alone is the question the admin then asks; a
+// status the app could not read quotes the cell, since that is what to fix.
+function StateTag({ volunteer }: { volunteer: Volunteer }) {
+  switch (volunteer.state) {
+    case "active":
+      return null;
+    case "onboarding":
+      return <span className="roster-tag roster-tag--state">Onboarding</span>;
+    case "paused":
+      return (
+        <span className="roster-tag roster-tag--inactive">
+          {volunteer.pausedUntil
+            ? `Paused until ${formatShiftDateLong(volunteer.pausedUntil)}`
+            : "Paused"}
+        </span>
+      );
+    case "left":
+      return <span className="roster-tag roster-tag--inactive">Left</span>;
+    case "unrecognised":
+      return (
+        <span
+          className="roster-tag roster-tag--inactive roster-tag--unrecognised"
+          title="Use Active, Onboarding, Left or “Paused until” a date"
+        >
+          Status not recognised: “{volunteer.status ?? ""}”
+        </span>
+      );
+  }
+}
+
+// The states a volunteer can be put in, in the order the roster counts them.
+const EDITABLE_STATES = STATE_LABELS.filter(
+  (s): s is { state: VolunteerEdit["state"]; label: string } =>
+    s.state !== "unrecognised",
+);
+
+function toEdit(volunteer: Volunteer): VolunteerEdit {
+  return {
+    firstName: volunteer.firstName,
+    lastName: volunteer.lastName,
+    roles: volunteer.roles,
+    // A cell nobody meant has no state to start from; the form asks for one.
+    state: volunteer.state === "unrecognised" ? "active" : volunteer.state,
+    pausedUntil: volunteer.pausedUntil ?? "",
+    gender: volunteer.gender ?? "",
+    email: volunteer.email ?? "",
+    group: volunteer.group ?? "",
+  };
+}
+
+const BLANK_VOLUNTEER: VolunteerEdit = {
+  firstName: "",
+  lastName: "",
+  roles: [],
+  state: "onboarding",
+  pausedUntil: "",
+  gender: "",
+  email: "",
+  group: "",
+};
+
+// VolunteerForm adds somebody to a roster kept in the database, or edits
+// somebody on it. Removing is here rather than on the row because it is for a
+// mistake just made: anybody a rota names is marked left instead, and the
+// server says so.
+function VolunteerForm({
+  volunteer,
+  roles,
+  onSave,
+  onRemove,
+  onClose,
+}: {
+  // null for somebody new.
+  volunteer: Volunteer | null;
+  roles: ConfiguredRole[];
+  onSave: (edit: VolunteerEdit) => Promise<void>;
+  onRemove: () => Promise<void>;
+  onClose: () => void;
+}) {
+  const [edit, setEdit] = useState<VolunteerEdit>(
+    volunteer ? toEdit(volunteer) : BLANK_VOLUNTEER,
   );
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  function set<K extends keyof VolunteerEdit>(key: K, value: VolunteerEdit[K]) {
+    setEdit((current) => ({ ...current, [key]: value }));
+  }
+
+  function toggleRole(name: string, held: boolean) {
+    setEdit((current) => ({
+      ...current,
+      roles: held
+        ? [...current.roles, name]
+        : current.roles.filter((r) => r !== name),
+    }));
+  }
+
+  async function act(apply: () => Promise<void>, fallback: string) {
+    setSaving(true);
+    setError(null);
+    try {
+      await apply();
+      onClose();
+    } catch (err: unknown) {
+      // The server names the field it could not accept, or says why somebody
+      // cannot be removed, so its message is shown as-is and the form stays
+      // open on what was entered.
+      setError(err instanceof Error ? err.message : fallback);
+      setSaving(false);
+    }
+  }
+
+  const incomplete =
+    edit.firstName.trim() === "" ||
+    (edit.state === "paused" && edit.pausedUntil === "");
 
   return (
-    <section className="admin-panel volunteers">
-      <header className="volunteers-head">
-        <h2>Volunteers</h2>
-        <div className="volunteers-sync">
-          <Button
-            size="small"
-            onClick={() => void sync()}
-            disabled={syncState === "syncing"}
+    <Dialog
+      title={volunteer ? `Edit ${volunteer.fullName}` : "New volunteer"}
+      onClose={onClose}
+    >
+      <form
+        onSubmit={(e) => {
+          e.preventDefault();
+          void act(() => onSave(edit), "Failed to save the volunteer");
+        }}
+      >
+        {volunteer?.state === "unrecognised" && (
+          <p className="settings-hint">
+            Their status was copied from the sheet as “{volunteer.status ?? ""}”,
+            which is not a state. Choose the one that was meant.
+          </p>
+        )}
+
+        <label className="settings-field">
+          First name
+          <input
+            type="text"
+            value={edit.firstName}
+            onChange={(e) => set("firstName", e.target.value)}
+          />
+        </label>
+
+        <label className="settings-field">
+          Last name
+          <input
+            type="text"
+            value={edit.lastName}
+            onChange={(e) => set("lastName", e.target.value)}
+          />
+        </label>
+
+        <fieldset className="settings-field volunteer-roles">
+          <legend>Roles</legend>
+          {roles.map((role) => (
+            <label key={role.id} className="rule-switch">
+              <input
+                type="checkbox"
+                checked={edit.roles.includes(role.name)}
+                onChange={(e) => toggleRole(role.name, e.target.checked)}
+              />
+              {role.name}
+            </label>
+          ))}
+        </fieldset>
+
+        <label className="settings-field">
+          State
+          <select
+            value={edit.state}
+            onChange={(e) =>
+              set("state", e.target.value as VolunteerEdit["state"])
+            }
+          >
+            {EDITABLE_STATES.map((s) => (
+              <option key={s.state} value={s.state}>
+                {s.label}
+              </option>
+            ))}
+          </select>
+        </label>
+
+        {edit.state === "paused" && (
+          <label className="settings-field">
+            Paused until
+            <input
+              type="date"
+              value={edit.pausedUntil}
+              onChange={(e) => set("pausedUntil", e.target.value)}
+            />
+          </label>
+        )}
+
+        <label className="settings-field">
+          Gender (optional)
+          <input
+            type="text"
+            value={edit.gender}
+            onChange={(e) => set("gender", e.target.value)}
+          />
+        </label>
+
+        <label className="settings-field">
+          Email (optional)
+          <input
+            type="email"
+            value={edit.email}
+            onChange={(e) => set("email", e.target.value)}
+          />
+        </label>
+
+        <label className="settings-field">
+          Group (optional)
+          <input
+            type="text"
+            value={edit.group}
+            onChange={(e) => set("group", e.target.value)}
+            placeholder="e.g. smith-family"
+          />
+        </label>
+        <p className="settings-hint">
+          Everybody in one group is put on together.
+        </p>
+
+        {error && <p className="settings-error">{error}</p>}
+
+        <div className="settings-actions">
+          {volunteer && (
+            <Button
+              onClick={() =>
+                void act(onRemove, "Failed to remove the volunteer")
+              }
+              disabled={saving}
+            >
+              Remove
+            </Button>
+          )}
+          <Button onClick={onClose} disabled={saving}>
+            Cancel
+          </Button>
+          <Button type="submit" disabled={incomplete || saving}>
+            {saving ? "Saving…" : volunteer ? "Save" : "Add volunteer"}
+          </Button>
+        </div>
+      </form>
+    </Dialog>
+  );
+}
+
+// How each kind of Pairing Rule reads to an admin, in the form and on the list.
+// The words are the allocator's actual promise: "try" for the preference it may
+// trade away, "never" for the rule it will not.
+const PAIRING_KINDS: { kind: PairingKind; label: string }[] = [
+  { kind: "apart", label: "Never on the same shift" },
+  { kind: "together", label: "Try to put on together" },
+];
+
+function describeKind(kind: PairingKind): string {
+  return PAIRING_KINDS.find((k) => k.kind === kind)?.label ?? kind;
+}
+
+// PairingRuleForm makes one rule about two volunteers. There is no edit — a
+// pair has one rule at most, and changing it is removing it and making the one
+// that was meant.
+function PairingRuleForm({
+  volunteers,
+  onSave,
+  onClose,
+}: {
+  volunteers: Volunteer[];
+  onSave: (rule: NewPairingRule) => Promise<void>;
+  onClose: () => void;
+}) {
+  const [volunteerA, setVolunteerA] = useState("");
+  const [volunteerB, setVolunteerB] = useState("");
+  const [kind, setKind] = useState<PairingKind>("apart");
+  const [note, setNote] = useState("");
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  // Not the first volunteer, and nobody in their group: a group already works
+  // together, and the server refuses a rule inside one, so offering it here
+  // would be offering a refusal.
+  const first = volunteers.find((v) => v.id === volunteerA) ?? null;
+  const offered = volunteers.filter(
+    (v) =>
+      v.id !== volunteerA &&
+      !(first?.group != null && v.group === first.group),
+  );
+
+  function handleFirst(value: string) {
+    setVolunteerA(value);
+    const picked = volunteers.find((v) => v.id === value);
+    const second = volunteers.find((v) => v.id === volunteerB);
+    if (
+      value === volunteerB ||
+      (picked?.group != null && second?.group === picked.group)
+    ) {
+      setVolunteerB("");
+    }
+  }
+
+  async function save() {
+    if (!volunteerA || !volunteerB) return;
+    setSaving(true);
+    setError(null);
+    try {
+      await onSave({ volunteerA, volunteerB, kind, note });
+      onClose();
+    } catch (err: unknown) {
+      // The server's own message says when the two already have a rule, so it
+      // is shown as-is and the form stays open on what was chosen.
+      setError(err instanceof Error ? err.message : "Failed to add the rule");
+      setSaving(false);
+    }
+  }
+
+  return (
+    <Dialog title="New pairing rule" onClose={onClose}>
+      <form
+        onSubmit={(e) => {
+          e.preventDefault();
+          void save();
+        }}
+      >
+        <p className="settings-hint">
+          Applies to every rota allocated from now on, including the one in
+          progress. Only admins can see these rules.
+        </p>
+
+        <label className="settings-field">
+          Volunteer
+          <select
+            value={volunteerA}
+            onChange={(e) => handleFirst(e.target.value)}
+          >
+            <option value="">Choose someone…</option>
+            {volunteers.map((v) => (
+              <option key={v.id} value={v.id}>
+                {v.fullName}
+              </option>
+            ))}
+          </select>
+        </label>
+
+        <label className="settings-field">
+          Rule
+          <select
+            value={kind}
+            onChange={(e) => setKind(e.target.value as PairingKind)}
           >
-            {syncState === "syncing" ? "Syncing…" : "Sync"}
+            {PAIRING_KINDS.map((k) => (
+              <option key={k.kind} value={k.kind}>
+                {k.label}
+              </option>
+            ))}
+          </select>
+        </label>
+
+        <label className="settings-field">
+          With
+          <select
+            value={volunteerB}
+            onChange={(e) => setVolunteerB(e.target.value)}
+            disabled={!volunteerA}
+          >
+            <option value="">Choose someone…</option>
+            {offered.map((v) => (
+              <option key={v.id} value={v.id}>
+                {v.fullName}
+              </option>
+            ))}
+          </select>
+        </label>
+
+        <label className="settings-field">
+          Note (optional)
+          <input
+            type="text"
+            value={note}
+            onChange={(e) => setNote(e.target.value)}
+            placeholder="e.g. safeguarding"
+          />
+        </label>
+
+        {error && <p className="settings-error">{error}</p>}
+
+        <div className="settings-actions">
+          <Button onClick={onClose} disabled={saving}>
+            Cancel
           </Button>
-          <p
-            className={`volunteers-sync-caption volunteers-sync-caption--${syncState}`}
-            aria-live="polite"
+          <Button
+            type="submit"
+            disabled={volunteerA === "" || volunteerB === "" || saving}
           >
-            {SYNC_CAPTION[syncState]}
-          </p>
+            {saving ? "Saving…" : "Add rule"}
+          </Button>
         </div>
-      </header>
+      </form>
+    </Dialog>
+  );
+}
+
+// PairingRules is the looser things an admin knows about two volunteers than a
+// group can say. It sits on this screen rather than with the settings because
+// it is about people, and it is read beside the roster it names.
+function PairingRules({ volunteers }: { volunteers: Volunteer[] | null }) {
+  const { rules, error, addRule, removeRule } = usePairingRules();
+  const [adding, setAdding] = useState(false);
+  const [removeError, setRemoveError] = useState<string | null>(null);
 
+  return (
+    <SettingsSection
+      title="Pairing rules"
+      blurb="Two volunteers to try to put on together, or never to put on the same shift. A group always works together; these are for everyone else."
+      action={
+        volunteers !== null &&
+        volunteers.length > 1 && (
+          <Button size="small" onClick={() => setAdding(true)}>
+            New rule
+          </Button>
+        )
+      }
+    >
       {error && (
-        <p className="volunteers-message volunteers-message--error">
-          Could not load the roster: {error}
+        <p className="settings-error">Could not load the rules: {error}</p>
+      )}
+      {removeError && <p className="settings-error">{removeError}</p>}
+
+      {rules === null && !error && <p className="settings-empty">Loading…</p>}
+
+      {rules !== null && rules.length === 0 && (
+        <p className="settings-empty">
+          No pairing rules. The allocator places everyone outside a group on
+          their own merits.
         </p>
       )}
 
-      {counts && volunteers && volunteers.length > 0 && (
-        <>
-          <dl className="roster-counts">
-            <Count
-              label="Active volunteers"
-              value={String(counts.activeVolunteers)}
-            />
-            {counts.byRole.map(({ role, count }) => (
-              <Count key={role} label={role} value={String(count)} />
-            ))}
-            <Count
-              label="Male"
-              value={
-                counts.malePercentage === null
-                  ? "—"
-                  : `${counts.malePercentage}%`
-              }
-              note="of active volunteers"
-            />
-          </dl>
+      {rules !== null && rules.length > 0 && (
+        <ul className="pairing-rules">
+          {rules.map((rule) => (
+            <li key={rule.id} className="pairing-row">
+              <span className="pairing-pair">
+                {rule.nameA} and {rule.nameB}
+              </span>
+              <span className={`pairing-kind pairing-kind--${rule.kind}`}>
+                {describeKind(rule.kind)}
+                {rule.note && (
+                  <span className="pairing-note">{rule.note}</span>
+                )}
+              </span>
+              <Button
+                size="small"
+                onClick={() => {
+                  setRemoveError(null);
+                  void removeRule(rule.id).catch((err: unknown) => {
+                    setRemoveError(
+                      err instanceof Error
+                        ? err.message
+                        : "Failed to remove the rule",
+                    );
+                  });
+                }}
+              >
+                Remove
+              </Button>
+            </li>
+          ))}
+        </ul>
+      )}
 
-          <p className="roster-caption">
-            All {volunteers.length} volunteers on the sheet.
-          </p>
-          <ul className="roster">
+      {adding && volunteers && (
+        <PairingRuleForm
+          volunteers={volunteers}
+          onSave={addRule}
+          onClose={() => setAdding(false)}
+        />
+      )}
+    </SettingsSection>
+  );
+}
+
+// formatAbsenceDate carries the year, unlike a shift's date: an Absence is as
+// often next year's as this one's.
+function formatAbsenceDate(dateStr: string): string {
+  return new Date(dateStr).toLocaleDateString("en-GB", {
+    weekday: "short",
+    day: "numeric",
+    month: "short",
+    year: "numeric",
+  });
+}
+
+function describeAbsenceDates(absence: Absence): string {
+  return absence.from === absence.to
+    ? formatAbsenceDate(absence.from)
+    : `${formatAbsenceDate(absence.from)} – ${formatAbsenceDate(absence.to)}`;
+}
+
+// AbsenceForm records days away for one volunteer. Like a Pairing Rule there
+// is no edit: a wrong one is removed and the one that was meant added.
+function AbsenceForm({
+  volunteers,
+  onSave,
+  onClose,
+}: {
+  volunteers: Volunteer[];
+  onSave: (volunteerId: string, absence: NewAbsence) => Promise<void>;
+  onClose: () => void;
+}) {
+  const [volunteerId, setVolunteerId] = useState("");
+  const [from, setFrom] = useState("");
+  const [to, setTo] = useState("");
+  const [note, setNote] = useState("");
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  async function save() {
+    if (!volunteerId || !from || !to) return;
+    setSaving(true);
+    setError(null);
+    try {
+      await onSave(volunteerId, { from, to, note });
+      onClose();
+    } catch (err: unknown) {
+      // The server says when the dates are the wrong way round or already
+      // past, so its message is shown as-is and the form stays open.
+      setError(
+        err instanceof Error ? err.message : "Failed to add the absence",
+      );
+      setSaving(false);
+    }
+  }
+
+  return (
+    <Dialog title="New absence" onClose={onClose}>
+      <form
+        onSubmit={(e) => {
+          e.preventDefault();
+          void save();
+        }}
+      >
+        <p className="settings-hint">
+          Every shift in these dates is a no for them, whatever their
+          availability says — on the rota in progress and every one after it.
+        </p>
+
+        <label className="settings-field">
+          Volunteer
+          <select
+            value={volunteerId}
+            onChange={(e) => setVolunteerId(e.target.value)}
+          >
+            <option value="">Choose someone…</option>
             {volunteers.map((v) => (
-              <RosterRow key={v.id} volunteer={v} colourOf={colourOf} />
+              <option key={v.id} value={v.id}>
+                {v.fullName}
+              </option>
             ))}
-          </ul>
-        </>
+          </select>
+        </label>
+
+        <label className="settings-field">
+          From
+          <input
+            type="date"
+            value={from}
+            onChange={(e) => {
+              setFrom(e.target.value);
+              // One day away is the commonest case, so "to" follows "from"
+              // until it is moved on its own.
+              if (to === "" || to < e.target.value) setTo(e.target.value);
+            }}
+          />
+        </label>
+
+        <label className="settings-field">
+          To
+          <input
+            type="date"
+            value={to}
+            min={from || undefined}
+            onChange={(e) => setTo(e.target.value)}
+          />
+        </label>
+
+        <label className="settings-field">
+          Note (optional)
+          <input
+            type="text"
+            value={note}
+            onChange={(e) => setNote(e.target.value)}
+            placeholder="e.g. abroad"
+          />
+        </label>
+
+        {error && <p className="settings-error">{error}</p>}
+
+        <div className="settings-actions">
+          <Button onClick={onClose} disabled={saving}>
+            Cancel
+          </Button>
+          <Button
+            type="submit"
+            disabled={volunteerId === "" || from === "" || to === "" || saving}
+          >
+            {saving ? "Saving…" : "Add absence"}
+          </Button>
+        </div>
+      </form>
+    </Dialog>
+  );
+}
+
+// Absences is every stretch of days away under way or still to come, whoever
+// recorded it — an admin here, or the volunteer on their own availability
+// page. The ones a volunteer added are marked so, since nobody here will
+// remember making them.
+function Absences({ volunteers }: { volunteers: Volunteer[] | null }) {
+  const { absences, error, add, remove } = useAbsences();
+  const [adding, setAdding] = useState(false);
+  const [removeError, setRemoveError] = useState<string | null>(null);
+
+  return (
+    <SettingsSection
+      title="Absences"
+      blurb="Days a volunteer has told us they are away. Each shift in them is a no, whatever their availability says."
+      action={
+        volunteers !== null &&
+        volunteers.length > 0 && (
+          <Button size="small" onClick={() => setAdding(true)}>
+            New absence
+          </Button>
+        )
+      }
+    >
+      {error && (
+        <p className="settings-error">Could not load the absences: {error}</p>
+      )}
+      {removeError && <p className="settings-error">{removeError}</p>}
+
+      {absences === null && !error && (
+        <p className="settings-empty">Loading…</p>
+      )}
+
+      {absences !== null && absences.length === 0 && (
+        <p className="settings-empty">Nobody has said they will be away.</p>
       )}
 
-      {!error && volunteers === null && (
-        <p className="volunteers-message">Loading roster…</p>
+      {absences !== null && absences.length > 0 && (
+        <ul className="pairing-rules">
+          {absences.map((absence) => (
+            <li key={absence.id} className="pairing-row">
+              <span className="pairing-pair">{absence.name}</span>
+              <span className="absence-dates">
+                {describeAbsenceDates(absence)}
+                {(absence.note || absence.createdBy === null) && (
+                  <span className="pairing-note">
+                    {[
+                      absence.note,
+                      absence.createdBy === null && "added by them",
+                    ]
+                      .filter(Boolean)
+                      .join(" · ")}
+                  </span>
+                )}
+              </span>
+              <Button
+                size="small"
+                onClick={() => {
+                  setRemoveError(null);
+                  void remove(absence.id).catch((err: unknown) => {
+                    setRemoveError(
+                      err instanceof Error
+                        ? err.message
+                        : "Failed to remove the absence",
+                    );
+                  });
+                }}
+              >
+                Remove
+              </Button>
+            </li>
+          ))}
+        </ul>
       )}
-      {volunteers !== null && volunteers.length === 0 && (
-        <p className="volunteers-message">
-          No volunteers yet. Sync to pull the roster in.
+
+      {adding && volunteers && (
+        <AbsenceForm
+          volunteers={volunteers}
+          onSave={add}
+          onClose={() => setAdding(false)}
+        />
+      )}
+    </SettingsSection>
+  );
+}
+
+// CalendarLinks is the feed each volunteer subscribes to their shifts by. A
+// link is addressed by a secret rather than by the volunteer's id, so one that
+// has got out can be replaced — the old one stops working — or revoked
+// outright. Allocation and cover emails make a link for anybody without one,
+// so this is for the exceptions, and for moving everybody off the old links in
+// one go.
+function CalendarLinks({ volunteers }: { volunteers: Volunteer[] | null }) {
+  const { tokens, error, mintMissing, mint, revoke } = useCalendarTokens();
+  const [working, setWorking] = useState(false);
+  const [message, setMessage] = useState<string | null>(null);
+  const [actionError, setActionError] = useState<string | null>(null);
+
+  const linkOf = useMemo(
+    () => new Map((tokens ?? []).map((t) => [t.volunteerId, t.link])),
+    [tokens],
+  );
+  const missing = volunteers?.filter((v) => !linkOf.has(v.id)).length ?? 0;
+
+  // One action at a time, each reporting in the same line: which link changed
+  // is on the row, so the line only has to say whether it worked.
+  async function act(apply: () => Promise<string>) {
+    setWorking(true);
+    setMessage(null);
+    setActionError(null);
+    try {
+      setMessage(await apply());
+    } catch (err: unknown) {
+      setActionError(
+        err instanceof Error ? err.message : "Failed to change the link",
+      );
+    } finally {
+      setWorking(false);
+    }
+  }
+
+  async function copy(link: string) {
+    try {
+      await navigator.clipboard.writeText(link);
+      setActionError(null);
+      setMessage("Link copied");
+    } catch {
+      setActionError("Copy failed");
+    }
+  }
+
+  return (
+    <SettingsSection
+      title="Calendar links"
+      blurb="The link each volunteer subscribes to their shifts by. A new link stops the old one working; revoking leaves them none until you make one."
+      action={
+        tokens !== null &&
+        missing > 0 && (
+          <Button
+            size="small"
+            disabled={working}
+            onClick={() =>
+              void act(async () => {
+                const minted = await mintMissing();
+                return minted === 1 ? "1 link made" : `${minted} links made`;
+              })
+            }
+          >
+            Make missing links
+          </Button>
+        )
+      }
+    >
+      {error && (
+        <p className="settings-error">Could not load the links: {error}</p>
+      )}
+      {actionError && <p className="settings-error">{actionError}</p>}
+      {message && (
+        <p className="settings-hint" aria-live="polite">
+          {message}
         </p>
       )}
-    </section>
+
+      {(tokens === null || volunteers === null) && !error && (
+        <p className="settings-empty">Loading…</p>
+      )}
+
+      {tokens !== null && volunteers !== null && volunteers.length > 0 && (
+        <ul className="calendar-links">
+          {volunteers.map((v) => {
+            const link = linkOf.get(v.id);
+            return (
+              <li key={v.id} className="calendar-link-row">
+                <span className="calendar-link-name">{v.fullName}</span>
+                {link ? (
+                  <>
+                    <Button size="small" onClick={() => void copy(link)}>
+                      Copy
+                    </Button>
+                    <Button
+                      size="small"
+                      disabled={working}
+                      onClick={() =>
+                        void act(async () => {
+                          await mint(v.id);
+                          return `New link made for ${v.fullName}`;
+                        })
+                      }
+                    >
+                      New link
+                    </Button>
+                    <Button
+                      size="small"
+                      disabled={working}
+                      onClick={() =>
+                        void act(async () => {
+                          await revoke(v.id);
+                          return `${v.fullName}'s link revoked`;
+                        })
+                      }
+                    >
+                      Revoke
+                    </Button>
+                  </>
+                ) : (
+                  <>
+                    <span className="calendar-link-none">No link</span>
+                    <Button
+                      size="small"
+                      disabled={working}
+                      onClick={() =>
+                        void act(async () => {
+                          await mint(v.id);
+                          return `Link made for ${v.fullName}`;
+                        })
+                      }
+                    >
+                      Make link
+                    </Button>
+                  </>
+                )}
+              </li>
+            );
+          })}
+        </ul>
+      )}
+    </SettingsSection>
+  );
+}
+
+// RosterActions is the corner of the header: the sync where the sheet is the
+// roster, and adding somebody where the database is. Both stay small — the
+// roster is the point of the page, not its upkeep.
+function RosterActions({
+  source,
+  syncState,
+  rosterSync,
+  onSync,
+  onAdd,
+}: {
+  source: RosterSource | null;
+  syncState: SyncState;
+  rosterSync: RosterSyncStatus | null;
+  onSync: () => void;
+  onAdd: () => void;
+}) {
+  if (source === "database") {
+    return (
+      <div className="volunteers-sync">
+        <Button size="small" onClick={onAdd}>
+          New volunteer
+        </Button>
+      </div>
+    );
+  }
+  const idle = idleCaption(rosterSync);
+  const tone = syncState === "idle" && idle.failed ? "error" : syncState;
+  return (
+    <div className="volunteers-sync">
+      <Button
+        size="small"
+        onClick={onSync}
+        disabled={syncState === "syncing" || syncState === "reviewing"}
+      >
+        {syncState === "syncing" ? "Reading…" : "Sync"}
+      </Button>
+      <p
+        className={`volunteers-sync-caption volunteers-sync-caption--${tone}`}
+        aria-live="polite"
+      >
+        {syncState === "idle" ? idle.text : SYNC_CAPTION[syncState]}
+      </p>
+    </div>
+  );
+}
+
+// EmptyRoster is what a roster with nobody on it says, which depends on where
+// it is kept: a sheet is synced in, and a database roster starts as a copy of
+// the sheet it replaces — once, into an empty roster, which is the only time
+// this is shown.
+function EmptyRoster({
+  source,
+  onImport,
+}: {
+  source: RosterSource | null;
+  onImport: () => Promise<number>;
+}) {
+  const [importing, setImporting] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  if (source !== "database") {
+    return (
+      <p className="volunteers-message">
+        No volunteers yet. Sync to pull the roster in.
+      </p>
+    );
+  }
+
+  async function runImport() {
+    setImporting(true);
+    setError(null);
+    try {
+      await onImport();
+    } catch (err: unknown) {
+      setError(
+        err instanceof Error ? err.message : "Failed to import the roster",
+      );
+    } finally {
+      setImporting(false);
+    }
+  }
+
+  return (
+    <div className="volunteers-message">
+      <p>
+        No volunteers yet. Copy them from the Google Sheet to start, or add
+        them one at a time.
+      </p>
+      <Button onClick={() => void runImport()} disabled={importing}>
+        {importing ? "Importing…" : "Import from the sheet"}
+      </Button>
+      {error && <p className="settings-error">{error}</p>}
+    </div>
+  );
+}
+
+// AdminVolunteers is the volunteers tab: the roster, a summary of it, and the
+// means of keeping it — a sync where the sheet is the roster, previewed before
+// it is applied and listed once it is, and editing in place where the database
+// is. The Pairing Rules and the calendar links follow
+// the roster, since they name people on it.
+export default function AdminVolunteers() {
+  const {
+    volunteers,
+    source,
+    error,
+    syncState,
+    previewSync,
+    confirmSync,
+    cancelSync,
+    add,
+    save,
+    remove,
+    importFromSheet,
+  } = useVolunteers();
+  const fromSheet = source === "sheet";
+  const syncs = useRosterSyncs({ enabled: fromSheet });
+  const { rosterSync, refresh: refreshSession } = useAuth();
+  // The sync read and waiting on the admin, if any.
+  const [preview, setPreview] = useState<SyncPreview | null>(null);
+  // A Role wears its configured colour here as well as on the rota, so a lead
+  // looks like a lead wherever they appear.
+  const { roles, colourOf } = useRoles();
+  const counts = useMemo(
+    () => (volunteers ? countRoster(volunteers) : null),
+    [volunteers],
+  );
+  // The volunteer being edited: null for nobody, "new" for somebody new.
+  const [editing, setEditing] = useState<Volunteer | "new" | null>(null);
+  const editable = source === "database";
+
+  return (
+    <>
+      <section className="admin-panel volunteers">
+        <header className="volunteers-head">
+          <h2>Volunteers</h2>
+          <RosterActions
+            source={source}
+            syncState={syncState}
+            rosterSync={rosterSync}
+            onSync={() => void previewSync().then(setPreview)}
+            onAdd={() => setEditing("new")}
+          />
+        </header>
+
+        {error && (
+          <p className="volunteers-message volunteers-message--error">
+            Could not load the roster: {error}
+          </p>
+        )}
+
+        {counts && volunteers && volunteers.length > 0 && (
+          <>
+            <dl className="roster-counts">
+              <Count
+                label="Active volunteers"
+                value={String(counts.activeVolunteers)}
+              />
+              {counts.byRole.map(({ role, count }) => (
+                <Count key={role} label={role} value={String(count)} />
+              ))}
+              <Count
+                label="Male"
+                value={
+                  counts.malePercentage === null
+                    ? "—"
+                    : `${counts.malePercentage}%`
+                }
+                note="of active volunteers"
+              />
+            </dl>
+
+            <dl className="roster-counts roster-counts--states">
+              {counts.byState.map(({ state, count }) => (
+                <Count
+                  key={state}
+                  label={
+                    STATE_LABELS.find((s) => s.state === state)?.label ?? state
+                  }
+                  value={String(count)}
+                />
+              ))}
+            </dl>
+
+            <p className="roster-caption">
+              All {volunteers.length} volunteers on the{" "}
+              {editable ? "roster" : "sheet"}.
+            </p>
+            <ul className="roster">
+              {volunteers.map((v) => (
+                <RosterRow
+                  key={v.id}
+                  volunteer={v}
+                  colourOf={colourOf}
+                  onEdit={editable ? () => setEditing(v) : undefined}
+                />
+              ))}
+            </ul>
+          </>
+        )}
+
+        {!error && volunteers === null && (
+          <p className="volunteers-message">Loading roster…</p>
+        )}
+        {volunteers !== null && volunteers.length === 0 && (
+          <EmptyRoster source={source} onImport={importFromSheet} />
+        )}
+
+        {editing !== null && roles !== null && (
+          <VolunteerForm
+            volunteer={editing === "new" ? null : editing}
+            roles={roles}
+            onSave={(edit) =>
+              editing === "new" ? add(edit) : save(editing.id, edit)
+            }
+            onRemove={() =>
+              editing === "new" ? Promise.resolve() : remove(editing.id)
+            }
+            onClose={() => setEditing(null)}
+          />
+        )}
+
+        {preview !== null && (
+          <SyncPreviewDialog
+            preview={preview}
+            onApply={(previewId) =>
+              confirmSync(previewId).finally(() => {
+                syncs.reload();
+                void refreshSession();
+              })
+            }
+            onClose={() => {
+              setPreview(null);
+              cancelSync();
+            }}
+          />
+        )}
+      </section>
+      {fromSheet && <SyncHistory syncs={syncs.syncs} error={syncs.error} />}
+      <PairingRules volunteers={volunteers} />
+      <Absences volunteers={volunteers} />
+      <CalendarLinks volunteers={volunteers} />
+    </>
   );
 }
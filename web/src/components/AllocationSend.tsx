@@ -0,0 +1,77 @@
+import { useState } from "react";
+import Button from "../ui/Button";
+import Dialog from "../ui/Dialog";
+import SendReport from "./SendReport";
+import { useAvailabilitySend } from "../hooks/useAvailabilitySend";
+import "./AllocationSend.css";
+
+// AllocationSend emails everybody on the newest allocated rota the shifts they
+// were given: each date, the times and the Role, with their calendar link.
+//
+// It lives on the rota page rather than on Admin → Allocation because that is
+// where allocating lands an admin, and because what it sends is what this page
+// shows — an admin reads the rota over, then tells everyone. The send comes
+// back here too, so its report sits above the rota it was about.
+//
+// Asked about once before it goes: unlike a round it marks nobody, so a second
+// send is a second email to every volunteer on the rota.
+export default function AllocationSend({
+  canSend,
+}: {
+  // Whether there is an allocated rota to send. The report of a send already
+  // started is shown either way.
+  canSend: boolean;
+}) {
+  const { send, error, start, resume, dismiss } = useAvailabilitySend();
+  const [confirming, setConfirming] = useState(false);
+
+  return (
+    <>
+      {canSend && send === null && (
+        <div className="allocation-send">
+          <span>Tell everyone on the rota which shifts they have.</span>
+          <Button size="small" onClick={() => setConfirming(true)}>
+            Email everyone their shifts
+          </Button>
+        </div>
+      )}
+
+      {error !== null && (
+        <p className="rota-notice" role="alert">
+          {error}
+        </p>
+      )}
+
+      {send !== null && (
+        <SendReport
+          send={send}
+          onResume={() => resume(send.id)}
+          onDismiss={dismiss}
+        />
+      )}
+
+      {/* Confirming navigates the whole page out to Google, so, like the
+          round's dialog, there is no pending state to show. */}
+      {confirming && (
+        <Dialog
+          title="Email everyone their shifts"
+          onClose={() => setConfirming(false)}
+        >
+          <p className="allocation-send-note">
+            Everyone with a shift on the newest allocated rota is sent their
+            dates, times and Roles, with a link that adds them to their
+            calendar. Sending again emails them all again.
+          </p>
+          <p className="allocation-send-note">
+            Mail sends from your own Google account, so Google will ask you to
+            allow it the first time.
+          </p>
+          <div className="allocation-send-actions">
+            <Button onClick={() => setConfirming(false)}>Cancel</Button>
+            <Button onClick={() => start("allocation", "")}>Send</Button>
+          </div>
+        </Dialog>
+      )}
+    </>
+  );
+}
@@ -0,0 +1,77 @@
+import Button from "../ui/Button";
+import type { AttendanceEntry, AttendanceStatus } from "../types";
+import { ATTENDANCE_CHOICES } from "./shifts";
+import "./AttendanceList.css";
+
+// recordedNote says who gave an answer, when it was somebody else: an admin
+// by their email, a Team lead by name. Nothing when nothing is recorded.
+function recordedNote(entry: AttendanceEntry): string | null {
+  if (entry.status === null || entry.recordedBy === null) return null;
+  return `Recorded by ${entry.recordedByName ?? entry.recordedBy}`;
+}
+
+// AttendanceList is everyone on one shift that has happened, each with the
+// answers to whether they came. It is the same list in the admins' dialog and
+// on a Team lead's page; only where an answer is sent differs.
+//
+// The answers are toggles rather than a select: one tap records, and tapping
+// the answer already given takes it back, which is how a mis-tap on a phone
+// is undone without a fourth "not recorded" button.
+export default function AttendanceList({
+  people,
+  busy,
+  onRecord,
+}: {
+  people: AttendanceEntry[];
+  busy: boolean;
+  onRecord: (volunteerId: string, status: AttendanceStatus | null) => void;
+}) {
+  if (people.length === 0) {
+    return (
+      <p className="attendance-empty">Nobody on the rota was on this shift.</p>
+    );
+  }
+
+  return (
+    <ul className="attendance-people">
+      {people.map((entry) => {
+        const note = recordedNote(entry);
+        return (
+          <li key={entry.volunteerId} className="attendance-person">
+            <span className="attendance-name">
+              {entry.volunteerName}
+              <span className="attendance-role"> · {entry.role}</span>
+            </span>
+            <span
+              className="attendance-choices"
+              role="group"
+              aria-label={`Did ${entry.volunteerName} come?`}
+            >
+              {ATTENDANCE_CHOICES.map((choice) => {
+                const chosen = entry.status === choice.status;
+                return (
+                  <Button
+                    key={choice.status}
+                    size="small"
+                    className={chosen ? "attendance-chosen" : undefined}
+                    aria-pressed={chosen}
+                    disabled={busy}
+                    onClick={() =>
+                      onRecord(
+                        entry.volunteerId,
+                        chosen ? null : choice.status,
+                      )
+                    }
+                  >
+                    {choice.label}
+                  </Button>
+                );
+              })}
+            </span>
+            {note && <span className="attendance-note">{note}</span>}
+          </li>
+        );
+      })}
+    </ul>
+  );
+}
@@ -0,0 +1,84 @@
+import { useLeadAttendance } from "../hooks/useLeadAttendance";
+import AttendanceList from "./AttendanceList";
+import { formatShiftTimes, formatVolunteerDate } from "./shiftTimes";
+import "./AvailabilityForm.css";
+import "./AttendancePage.css";
+
+// AttendancePage is a Team lead's attendance link: the shifts they led that
+// have started, and who on each turned up. It stands on its own, outside the
+// shell, for the reason the availability form does, and borrows that form's
+// narrow measure because it is filled in on a phone at the end of the night.
+//
+// Every answer is sent as it is tapped; there is nothing to submit.
+export default function AttendancePage({ token }: { token: string }) {
+  const { page, deadLink, error, busy, record } = useLeadAttendance(token);
+
+  if (deadLink) {
+    return (
+      <main className="availability">
+        <h1>Who came?</h1>
+        <p className="availability-dead-link">
+          This link is not one we recognise. Check you followed the whole link
+          from your email.
+        </p>
+      </main>
+    );
+  }
+
+  if (page === null) {
+    return (
+      <main className="availability">
+        {error ? (
+          <p className="availability-message availability-message--error">
+            Could not load your shifts: {error}
+          </p>
+        ) : (
+          <p className="app-status">Loading…</p>
+        )}
+      </main>
+    );
+  }
+
+  return (
+    <main className="availability">
+      <h1>Who came, {page.volunteerName}?</h1>
+
+      <p className="availability-intro">
+        For each shift you led as {page.role}, say whether everyone on it
+        turned up. Tap an answer again to take it back.
+      </p>
+
+      <div aria-live="polite">
+        {error && (
+          <p className="availability-message availability-message--error">
+            {error}
+          </p>
+        )}
+      </div>
+
+      {page.shifts.length === 0 ? (
+        <p className="attendance-page-empty">
+          You have not led a shift on this rota yet.
+        </p>
+      ) : (
+        page.shifts.map((shift) => (
+          <section key={shift.shiftId} className="attendance-page-shift">
+            <h2 className="attendance-page-date">
+              {formatVolunteerDate(shift.date)}
+              <span className="shift-choice-time">
+                {formatShiftTimes(shift.start, shift.end)}
+              </span>
+            </h2>
+            <AttendanceList
+              people={shift.people}
+              busy={busy}
+              onRecord={(volunteerId, status) =>
+                void record(shift.shiftId, volunteerId, status)
+              }
+            />
+          </section>
+        ))
+      )}
+    </main>
+  );
+}
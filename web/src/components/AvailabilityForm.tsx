@@ -1,20 +1,15 @@
+import { useState } from "react";
 import Button from "../ui/Button";
 import { useAvailabilityForm } from "../hooks/useAvailabilityForm";
-import type { AvailabilityLinkFailure, AvailabilityShift } from "../types";
-import { formatShiftTimes } from "./shiftTimes";
+import type {
+  Absence,
+  AvailabilityLinkFailure,
+  AvailabilityShift,
+  NewAbsence,
+} from "../types";
+import { formatShiftTimes, formatVolunteerDate } from "./shiftTimes";
 import "./AvailabilityForm.css";
 
-// "Sunday 2 August" — the weekday matters more than the year here: a volunteer
-// is checking which Sundays they can do, and every shift is within a couple of
-// months.
-function formatShiftDate(date: string): string {
-  return new Date(date).toLocaleDateString("en-GB", {
-    weekday: "long",
-    day: "numeric",
-    month: "long",
-  });
-}
-
 // A link that will never work again gets its own screen, not an error banner
 // over an empty form. The two reasons need different words: one asks the
 // volunteer to check the link they followed, the other tells them the rota is
@@ -22,7 +17,7 @@ function formatShiftDate(date: string): string {
 const DEAD_LINK_MESSAGE: Record<AvailabilityLinkFailure, string> = {
   "not-found":
     "This availability link is not one we recognise. Check you followed the whole link from your email, or ask us for a new one.",
-  gone: "The rota for these dates has already been worked out, so this link has closed. Get in touch if something has changed.",
+  gone: "The rota for these dates has already been worked out, so there is nothing more to answer here.",
 };
 
 // One shift, as a no/yes pair. Radios rather than a tick box because no is an
@@ -38,17 +33,22 @@ const DEAD_LINK_MESSAGE: Record<AvailabilityLinkFailure, string> = {
 // is nothing to say — but it is still listed, so a volunteer can see that
 // instead of wondering why a Sunday is missing.
 //
+// A date one of their Absences covers is shown the same way, tagged "Away":
+// the Absence already says no, and a yes there would not be used.
+//
 // A row they have moved since their last send is marked, because the answer it
 // is replacing has gone from the screen: without the mark, a volunteer who came
 // back to change one Sunday has nothing to check their edit against before
 // sending it.
 function ShiftChoice({
   shift,
+  away,
   available,
   changed,
   onAnswer,
 }: {
   shift: AvailabilityShift;
+  away: boolean;
   available: boolean;
   // True when this answer differs from the one already sent. Never set before a
   // first send, so the note below can talk about what they told us without
@@ -56,13 +56,14 @@ function ShiftChoice({
   changed: boolean;
   onAnswer: (available: boolean) => void;
 }) {
-  const date = formatShiftDate(shift.date);
+  const date = formatVolunteerDate(shift.date);
+  const answerable = !shift.closed && !away;
 
   return (
     <li
       className={[
         "shift-choice",
-        shift.closed && "shift-choice--closed",
+        !answerable && "shift-choice--closed",
         changed && "shift-choice--changed",
       ]
         .filter(Boolean)
@@ -90,6 +91,8 @@ function ShiftChoice({
       </span>
       {shift.closed ? (
         <span className="shift-choice-tag">Closed</span>
+      ) : away ? (
+        <span className="shift-choice-tag">Away</span>
       ) : (
         <span className="shift-answer">
           <label className="shift-option">
@@ -126,6 +129,140 @@ function ShiftChoice({
 // full availability, which is harmless, where starting on no would let a mis-tap
 // record none at all — indistinguishable from a genuine "I can't do any of
 // these", and enough to drop someone from the rota silently (ADR 0004).
+// Days away, formatted the way the list of dates above them is.
+function absenceDates(absence: Absence): string {
+  const from = formatVolunteerDate(absence.from);
+  return absence.from === absence.to
+    ? from
+    : `${from} to ${formatVolunteerDate(absence.to)}`;
+}
+
+const NO_ABSENCE: NewAbsence = { from: "", to: "", note: "" };
+
+// TimeAway is where a volunteer says in advance they are away — "abroad all of
+// December" — once, instead of answering no on every rota it touches. It is
+// its own form beneath the answers, and takes effect the moment it is added:
+// it is not part of the answer waiting to be sent, and nobody should have to
+// send this rota's answer to tell us about next year's holiday.
+function TimeAway({
+  absences,
+  onAdd,
+  onRemove,
+}: {
+  absences: Absence[];
+  onAdd: (absence: NewAbsence) => Promise<void>;
+  onRemove: (id: string) => Promise<void>;
+}) {
+  const [draft, setDraft] = useState<NewAbsence>(NO_ABSENCE);
+  const [busy, setBusy] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const run = async (apply: () => Promise<void>) => {
+    setBusy(true);
+    setError(null);
+    try {
+      await apply();
+    } catch (err: unknown) {
+      setError(err instanceof Error ? err.message : "Something went wrong");
+    } finally {
+      setBusy(false);
+    }
+  };
+
+  return (
+    <section className="time-away">
+      <h2>Time away</h2>
+      <p className="availability-intro">
+        Going to be away? Tell us the dates and we will not put you on the rota
+        for any of them, now or on rotas still to come.
+      </p>
+
+      {absences.length > 0 && (
+        <ul className="time-away-list">
+          {absences.map((absence) => (
+            <li key={absence.id} className="time-away-item">
+              <span>
+                {absenceDates(absence)}
+                {absence.note && (
+                  <span className="time-away-note">{absence.note}</span>
+                )}
+              </span>
+              <Button
+                size="small"
+                disabled={busy}
+                onClick={() => void run(() => onRemove(absence.id))}
+                aria-label={`Remove time away: ${absenceDates(absence)}`}
+              >
+                Remove
+              </Button>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      <form
+        className="time-away-form"
+        onSubmit={(e) => {
+          e.preventDefault();
+          void run(async () => {
+            await onAdd(draft);
+            setDraft(NO_ABSENCE);
+          });
+        }}
+      >
+        <label>
+          From
+          <input
+            type="date"
+            required
+            value={draft.from}
+            onChange={(e) => {
+              const from = e.target.value;
+              // A day away is the commonest case, so "to" follows "from" until
+              // somebody moves it.
+              setDraft((d) => ({
+                ...d,
+                from,
+                to: d.to === "" || d.to < from ? from : d.to,
+              }));
+            }}
+          />
+        </label>
+        <label>
+          To
+          <input
+            type="date"
+            required
+            min={draft.from || undefined}
+            value={draft.to}
+            onChange={(e) => setDraft((d) => ({ ...d, to: e.target.value }))}
+          />
+        </label>
+        <label>
+          Note (optional)
+          <input
+            type="text"
+            maxLength={200}
+            value={draft.note}
+            onChange={(e) => setDraft((d) => ({ ...d, note: e.target.value }))}
+          />
+        </label>
+        <Button type="submit" disabled={busy}>
+          Add time away
+        </Button>
+      </form>
+
+      <div aria-live="polite">
+        {error && (
+          <p className="availability-message availability-message--error">
+            {error}
+          </p>
+        )}
+      </div>
+    </section>
+  );
+}
+
 export default function AvailabilityForm({ token }: { token: string }) {
   const {
     form,
@@ -134,8 +271,12 @@ export default function AvailabilityForm({ token }: { token: string }) {
     submitState,
     selected,
     changed,
+    preferred,
+    setPreferred,
     setAvailable,
     submit,
+    addAbsence,
+    removeAbsence,
   } = useAvailabilityForm(token);
 
   if (deadLink) {
@@ -143,6 +284,15 @@ export default function AvailabilityForm({ token }: { token: string }) {
       <main className="availability">
         <h1>Availability</h1>
         <p className="availability-dead-link">{DEAD_LINK_MESSAGE[deadLink]}</p>
+        {/* The rota being out is not the end of the link: it is where their
+            shifts are, and where they give one up. */}
+        {deadLink === "gone" && (
+          <p className="availability-intro">
+            <a href={`/swaps/${encodeURIComponent(token)}`}>
+              See your shifts, or swap one
+            </a>
+          </p>
+        )}
       </main>
     );
   }
@@ -161,7 +311,10 @@ export default function AvailabilityForm({ token }: { token: string }) {
     );
   }
 
-  const openShifts = form.shifts.filter((s) => !s.closed);
+  // A date they are away for is not one they are being asked about, so it is
+  // counted as neither a yes nor a date.
+  const away = new Set(form.absentShiftIds);
+  const openShifts = form.shifts.filter((s) => !s.closed && !away.has(s.id));
   const chosen = openShifts.filter((s) => selected.has(s.id)).length;
 
   return (
@@ -222,6 +375,7 @@ export default function AvailabilityForm({ token }: { token: string }) {
             <ShiftChoice
               key={shift.id}
               shift={shift}
+              away={away.has(shift.id)}
               available={selected.has(shift.id)}
               changed={changed.has(shift.id)}
               onAnswer={(available) => setAvailable(shift.id, available)}
@@ -245,6 +399,31 @@ export default function AvailabilityForm({ token }: { token: string }) {
           )}
         </p>
 
+        {/* "Free for all four but only want two". Only asked once there is a
+            choice to make: with one date on yes or none, fewer is not a
+            preference anyone can have. It is a wish, not a limit, and the
+            wording says so — the rota may still need them for more. The value
+            shown is held to the dates on yes, as it is when sent. */}
+        {chosen > 1 && (
+          <label className="availability-preferred">
+            How many of these would you like to do?
+            <select
+              value={Math.min(preferred, chosen)}
+              onChange={(e) => setPreferred(Number(e.target.value))}
+            >
+              <option value={0}>As many as you are needed for</option>
+              {Array.from({ length: chosen - 1 }, (_, i) => i + 1).map((n) => (
+                <option key={n} value={n}>
+                  {n === 1 ? "Just 1" : `Up to ${n}`}
+                </option>
+              ))}
+            </select>
+            <span className="availability-preferred-note">
+              We will try to keep to this, but may ask for more if we are short.
+            </span>
+          </label>
+        )}
+
         <Button type="submit" disabled={submitState === "sending"}>
           {submitState === "sending"
             ? "Sending…"
@@ -268,6 +447,12 @@ export default function AvailabilityForm({ token }: { token: string }) {
           )}
         </div>
       </form>
+
+      <TimeAway
+        absences={form.absences}
+        onAdd={addAbsence}
+        onRemove={removeAbsence}
+      />
     </main>
   );
 }
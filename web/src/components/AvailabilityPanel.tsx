@@ -2,9 +2,16 @@ import { useState } from "react";
 import Button from "../ui/Button";
 import Dialog from "../ui/Dialog";
 import ResponseGrid from "./ResponseGrid";
+import SendReport from "./SendReport";
 import { useAvailabilityRound } from "../hooks/useAvailabilityRound";
 import { useAvailabilitySend } from "../hooks/useAvailabilitySend";
-import type { AvailabilityRound, AvailabilitySend, SendMode } from "../types";
+import { useSendHistory } from "../hooks/useSendHistory";
+import { useAuth } from "../auth-context";
+import type {
+  AvailabilityRound,
+  AvailabilitySendSummary,
+  SendMode,
+} from "../types";
 import "./AvailabilityPanel.css";
 
 // A send the admin has asked for but not yet given a deadline to. The deadline
@@ -90,62 +97,81 @@ function SendDialog({
   );
 }
 
-// What a send did, or is doing.
+const SEND_MODE_LABELS: Record<SendMode, string> = {
+  round: "Round",
+  reminder: "Reminders",
+  resend: "Resend",
+  allocation: "Allocated shifts",
+  cover: "Rota change",
+  "cover-request": "Cover request",
+  "swap-offer": "Swap offer",
+};
+
+function formatSentAt(timestamp: string): string {
+  return new Date(timestamp).toLocaleString("en-GB", {
+    day: "numeric",
+    month: "short",
+    hour: "2-digit",
+    minute: "2-digit",
+  });
+}
+
+// The round's sends, newest first.
 //
-// A running send counts up rather than spinning: it takes about ninety seconds,
-// and a bar with no numbers on it for that long is indistinguishable from a
-// hang. A finished one leads with its failures, because those are the ones that
-// still need an admin — the successes are only there to say how many there were.
-function SendReport({
-  send,
-  onDismiss,
+// Every admin's sends are here, because "has somebody already sent the
+// reminders?" is the question it answers. Only the admin who started a send can
+// resume it: the mail goes out from their Google account.
+function SendHistory({
+  sends,
+  names,
+  admin,
+  onResume,
 }: {
-  send: AvailabilitySend;
-  onDismiss: () => void;
+  sends: AvailabilitySendSummary[];
+  // Volunteer names by id, for saying who a resend went to.
+  names: Map<string, string>;
+  admin: string | null;
+  onResume: (id: string) => void;
 }) {
-  const noun = send.mode === "reminder" ? "reminder" : "email";
-  const sent = `Sent ${send.sent.length} ${noun}${send.sent.length === 1 ? "" : "s"}`;
+  if (sends.length === 0) return null;
 
   return (
-    <div className="send-report">
-      <div className="send-report-head">
-        {send.finished ? (
-          <span>
-            {sent}
-            {send.failed.length > 0 && `, ${send.failed.length} failed`}
-          </span>
-        ) : (
-          <span>
-            Sending… {send.done} of {send.total}
-          </span>
-        )}
-        {send.finished && (
-          <Button size="small" onClick={onDismiss}>
-            Dismiss
-          </Button>
-        )}
-      </div>
-
-      {send.error !== null && <p className="send-report-error">{send.error}</p>}
-
-      {send.failed.length > 0 && (
-        <ul className="send-failures">
-          {send.failed.map((f) => (
-            <li key={f.volunteerId}>
-              <strong>{f.volunteerName}</strong>
-              {f.email !== null && ` (${f.email})`} — {f.error}
+    <>
+      <h3 className="round-section">Sends</h3>
+      <ul className="send-history">
+        {sends.map((s) => {
+          const mine =
+            admin !== null &&
+            s.adminEmail.toLowerCase() === admin.toLowerCase();
+          return (
+            <li key={s.id} className="send-history-row">
+              <span className="send-history-when">
+                {formatSentAt(s.startedAt)}
+              </span>
+              <span>
+                {SEND_MODE_LABELS[s.mode]}
+                {s.volunteerId !== null &&
+                  ` to ${names.get(s.volunteerId) ?? s.volunteerId}`}{" "}
+                by {mine ? "you" : s.adminEmail}
+              </span>
+              <span className="send-history-outcome">
+                {s.status === "running" && `Sending… ${s.done} of ${s.total}`}
+                {s.status === "interrupted" &&
+                  `Stopped at ${s.done} of ${s.total}`}
+                {s.status === "finished" &&
+                  `${s.sentCount} sent${s.failedCount > 0 ? `, ${s.failedCount} failed` : ""}`}
+                {s.error !== null && ` — ${s.error}`}
+              </span>
+              {s.status === "interrupted" && mine && (
+                <Button size="small" onClick={() => onResume(s.id)}>
+                  Resume
+                </Button>
+              )}
             </li>
-          ))}
-        </ul>
-      )}
-
-      {send.finished && send.failed.length > 0 && (
-        <p className="send-note">
-          Nobody here has been marked as sent, so sending the round again will
-          try them and leave everyone else alone.
-        </p>
-      )}
-    </div>
+          );
+        })}
+      </ul>
+    </>
   );
 }
 
@@ -164,12 +190,18 @@ function SendReport({
 // on the screen those are on.
 export default function AvailabilityPanel() {
   const { round, error, mintState, mint, reload } = useAvailabilityRound();
+  const { email: admin } = useAuth();
+  const history = useSendHistory(round?.rotaId);
   const {
     send,
     error: sendError,
     start,
+    resume,
     dismiss,
-  } = useAvailabilitySend(reload);
+  } = useAvailabilitySend(() => {
+    reload();
+    history.reload();
+  });
   const [pending, setPending] = useState<PendingSend | null>(null);
 
   const replied = round?.groups.filter((g) => g.replied).length ?? 0;
@@ -224,7 +256,13 @@ export default function AvailabilityPanel() {
           <p className="round-message round-message--error">{sendError}</p>
         )}
 
-        {send !== null && <SendReport send={send} onDismiss={dismiss} />}
+        {send !== null && (
+          <SendReport
+            send={send}
+            onResume={() => resume(send.id)}
+            onDismiss={dismiss}
+          />
+        )}
 
         {pending !== null && (
           <SendDialog
@@ -299,6 +337,22 @@ export default function AvailabilityPanel() {
                 />
               </>
             )}
+
+            {history.error !== null && (
+              <p className="round-message round-message--error">
+                Could not load the sends: {history.error}
+              </p>
+            )}
+            {history.sends !== null && (
+              <SendHistory
+                sends={history.sends}
+                names={
+                  new Map(members.map((m) => [m.volunteerId, m.volunteerName]))
+                }
+                admin={admin}
+                onResume={resume}
+              />
+            )}
           </>
         )}
       </section>
@@ -0,0 +1,111 @@
+import Button from "../ui/Button";
+import { useCoverForm } from "../hooks/useCoverForm";
+import { formatShiftTimes, formatVolunteerDate } from "./shiftTimes";
+import "./AvailabilityForm.css";
+import "./CoverPage.css";
+
+// What the page says once they have answered. A yes is not a place on the
+// rota — an admin picks one of the yeses — so it says so rather than thanking
+// them for a shift they may not get.
+function answered(answer: "yes" | "no" | null): string | null {
+  if (answer === "yes") {
+    return "Thank you — you said you can. An admin will let you know if you are needed.";
+  }
+  if (answer === "no") {
+    return "Thanks for letting us know you can't.";
+  }
+  return null;
+}
+
+// CoverPage is a volunteer's cover link: one shift somebody has dropped out
+// of, and whether they can do it instead. It stands on its own, outside the
+// shell, for the reason the availability form does, and borrows that form's
+// narrow measure because it is opened from the same email on the same phone.
+//
+// They can change their answer until the shift is covered or starts; after
+// that the link says which, in the server's words.
+export default function CoverPage({ token }: { token: string }) {
+  const { form, deadLink, closed, error, busy, answer } = useCoverForm(token);
+
+  if (deadLink) {
+    return (
+      <main className="availability">
+        <h1>Can you cover?</h1>
+        <p className="availability-dead-link">
+          This link is not one we recognise. Check you followed the whole link
+          from your email.
+        </p>
+      </main>
+    );
+  }
+
+  if (closed !== null) {
+    return (
+      <main className="availability">
+        <h1>Can you cover?</h1>
+        <p className="availability-dead-link">
+          {closed.charAt(0).toUpperCase() + closed.slice(1)}.
+        </p>
+      </main>
+    );
+  }
+
+  if (form === null) {
+    return (
+      <main className="availability">
+        {error ? (
+          <p className="availability-message availability-message--error">
+            Could not load the shift: {error}
+          </p>
+        ) : (
+          <p className="app-status">Loading…</p>
+        )}
+      </main>
+    );
+  }
+
+  const status = answered(form.answer);
+
+  return (
+    <main className="availability">
+      <h1>Can you cover, {form.firstName || "there"}?</h1>
+
+      <div className="cover-shift">
+        <span className="shift-choice-date">
+          {formatVolunteerDate(form.date)}
+        </span>
+        <span className="shift-choice-time">
+          {formatShiftTimes(form.start, form.end)} · {form.role}
+        </span>
+      </div>
+
+      <div aria-live="polite">
+        {status && (
+          <p className="availability-message availability-message--ok">
+            {status}
+          </p>
+        )}
+        {error && (
+          <p className="availability-message availability-message--error">
+            {error}
+          </p>
+        )}
+      </div>
+
+      <div className="cover-answers">
+        <Button
+          disabled={busy || form.answer === "yes"}
+          onClick={() => void answer("yes")}
+        >
+          Yes, I can
+        </Button>
+        <Button
+          disabled={busy || form.answer === "no"}
+          onClick={() => void answer("no")}
+        >
+          No, I can't
+        </Button>
+      </div>
+    </main>
+  );
+}
@@ -0,0 +1,308 @@
+import { useMemo, useState } from "react";
+import Button from "../ui/Button";
+import Dialog from "../ui/Dialog";
+import { sendUrl } from "../api";
+import { useCoverRequests } from "../hooks/useCoverRequests";
+import { useRoles } from "../hooks/useRoles";
+import type { CoverRequest, NewCoverRequest, RotaShift } from "../types";
+import { formatShiftDateLong } from "./shifts";
+import "./AllocationSend.css";
+import "./RotaEditDialogs.css";
+import "./CoverRequests.css";
+
+// Emailing is a full-page trip through Google for the gmail.send grant, so it
+// is a navigation rather than a fetch — the report lands back on this page.
+function emailCoverRequest(id: string) {
+  window.location.assign(
+    sendUrl("cover-request", "", undefined, undefined, id),
+  );
+}
+
+// The shifts cover can be asked for: allocated, open, and still to come. The
+// rota page shows the past too, but nobody is asked to cover last week.
+function coverableShifts(shifts: RotaShift[]): RotaShift[] {
+  const today = new Date().toISOString().slice(0, 10);
+  return shifts.filter((s) => s.allocated && !s.closed && s.date >= today);
+}
+
+const SHORT = "";
+
+// AskForCoverDialog is the ask: which shift, who is dropping out — or nobody,
+// when the shift is simply short and the Role has to be said — and why. The
+// reason is recorded against the change that fills the gap, as any other
+// change's is, so it is required and not pre-filled.
+function AskForCoverDialog({
+  shifts,
+  onAsk,
+  onClose,
+}: {
+  shifts: RotaShift[];
+  onAsk: (req: NewCoverRequest) => Promise<void>;
+  onClose: () => void;
+}) {
+  const { roles } = useRoles();
+  const [shiftId, setShiftId] = useState(shifts[0]?.id ?? "");
+  const [replacing, setReplacing] = useState(SHORT);
+  const [role, setRole] = useState("");
+  const [reason, setReason] = useState("");
+  const [busy, setBusy] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const shift = shifts.find((s) => s.id === shiftId) ?? null;
+  const onShift = (shift?.assignees ?? []).filter((a) => a.volunteerId);
+  const canAsk =
+    shift !== null &&
+    reason.trim() !== "" &&
+    (replacing !== SHORT || role !== "") &&
+    !busy;
+
+  const ask = async () => {
+    setBusy(true);
+    setError(null);
+    try {
+      await onAsk({
+        shiftId,
+        replacing: replacing === SHORT ? null : replacing,
+        role: replacing === SHORT ? role : null,
+        reason: reason.trim(),
+      });
+    } catch (err: unknown) {
+      setError(err instanceof Error ? err.message : "Failed to ask for cover");
+      setBusy(false);
+    }
+  };
+
+  return (
+    <Dialog title="Ask for cover" onClose={onClose}>
+      <label className="rota-edit-field">
+        Shift
+        <select
+          value={shiftId}
+          onChange={(e) => {
+            setShiftId(e.target.value);
+            setReplacing(SHORT);
+          }}
+        >
+          {shifts.map((s) => (
+            <option key={s.id} value={s.id}>
+              {formatShiftDateLong(s.date)}
+            </option>
+          ))}
+        </select>
+      </label>
+      <label className="rota-edit-field">
+        Who is dropping out
+        <select
+          value={replacing}
+          onChange={(e) => setReplacing(e.target.value)}
+        >
+          <option value={SHORT}>Nobody — the shift is short</option>
+          {onShift.map((a) => (
+            <option key={a.volunteerId} value={a.volunteerId ?? ""}>
+              {a.name} ({a.role})
+            </option>
+          ))}
+        </select>
+      </label>
+      {replacing === SHORT && (
+        <label className="rota-edit-field">
+          Role needed
+          <select value={role} onChange={(e) => setRole(e.target.value)}>
+            <option value="">Choose a Role</option>
+            {(roles ?? []).map((r) => (
+              <option key={r.id} value={r.name}>
+                {r.name}
+              </option>
+            ))}
+          </select>
+        </label>
+      )}
+      <label className="rota-edit-field">
+        Reason
+        <input
+          type="text"
+          value={reason}
+          onChange={(e) => setReason(e.target.value)}
+          placeholder="e.g. unwell"
+        />
+      </label>
+      <p className="rota-edit-note">
+        Everyone active who holds the Role and is not already on the shift is
+        emailed a link to say whether they can. Mail sends from your own Google
+        account, so Google may ask you to allow it.
+      </p>
+      {error && (
+        <p className="rota-edit-note" role="alert">
+          {error}
+        </p>
+      )}
+      <div className="rota-edit-actions">
+        <Button onClick={onClose}>Cancel</Button>
+        <Button disabled={!canAsk} onClick={() => void ask()}>
+          Ask
+        </Button>
+      </div>
+    </Dialog>
+  );
+}
+
+function CoverRequestRow({
+  request,
+  onAccept,
+  onCancel,
+}: {
+  request: CoverRequest;
+  onAccept: (volunteerId: string) => Promise<void>;
+  onCancel: () => Promise<void>;
+}) {
+  const [busy, setBusy] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const act = async (apply: () => Promise<void>) => {
+    setBusy(true);
+    setError(null);
+    try {
+      await apply();
+    } catch (err: unknown) {
+      setError(err instanceof Error ? err.message : "Something went wrong");
+      setBusy(false);
+    }
+  };
+
+  const unanswered = request.asked - request.yes.length - request.no;
+
+  return (
+    <li className="cover-request">
+      <div className="cover-request-head">
+        <strong>
+          {formatShiftDateLong(request.date)} · {request.role}
+        </strong>
+        <span>
+          {request.replacingName
+            ? `in place of ${request.replacingName}`
+            : "the shift is short"}
+          {" — "}
+          {request.reason}
+        </span>
+      </div>
+      <div className="cover-request-counts">
+        {request.asked} asked, {request.emailed} emailed, {request.no} said
+        no, {unanswered} yet to answer
+        {request.expired &&
+          " — the shift has started, so the links are closed"}
+      </div>
+      {request.yes.length > 0 && (
+        <ul className="cover-request-yeses">
+          {request.yes.map((a) => (
+            <li key={a.volunteerId}>
+              <span>{a.volunteerName} can cover</span>
+              <Button
+                size="small"
+                disabled={busy}
+                onClick={() => void act(() => onAccept(a.volunteerId))}
+              >
+                Put them on
+              </Button>
+            </li>
+          ))}
+        </ul>
+      )}
+      {error && (
+        <p className="rota-notice" role="alert">
+          {error}
+        </p>
+      )}
+      <div className="cover-request-actions">
+        {!request.expired && unanswered > 0 && (
+          <Button
+            size="small"
+            disabled={busy}
+            onClick={() => emailCoverRequest(request.id)}
+          >
+            {request.emailed === 0 ? "Email them" : "Chase the rest"}
+          </Button>
+        )}
+        <Button
+          size="small"
+          disabled={busy}
+          onClick={() => void act(onCancel)}
+        >
+          Stop asking
+        </Button>
+      </div>
+    </li>
+  );
+}
+
+// CoverRequests is asking for cover once a rota is out. Availability links stop
+// answering at allocation, so when somebody drops out this is how everyone who
+// could step in is asked at once: each gets a short-lived link of their own,
+// the yeses collect here, and one click puts one of them on the rota as an
+// ordinary change.
+//
+// It sits on the rota page beside the allocation send because it is about the
+// rota this page shows, and because accepting an answer changes it.
+export default function CoverRequests({
+  shifts,
+  onRotaChanged,
+}: {
+  shifts: RotaShift[];
+  // Reloads the rota after an answer is accepted.
+  onRotaChanged: () => Promise<void>;
+}) {
+  const { requests, error, create, accept, cancel } = useCoverRequests();
+  const [asking, setAsking] = useState(false);
+  const coverable = useMemo(() => coverableShifts(shifts), [shifts]);
+
+  if (coverable.length === 0 && (requests === null || requests.length === 0)) {
+    return null;
+  }
+
+  return (
+    <section className="cover-requests">
+      <div className="allocation-send">
+        <span>Somebody dropped out? Ask everyone who could cover.</span>
+        {coverable.length > 0 && (
+          <Button size="small" onClick={() => setAsking(true)}>
+            Ask for cover
+          </Button>
+        )}
+      </div>
+
+      {error && (
+        <p className="rota-notice" role="alert">
+          Could not load cover requests: {error}
+        </p>
+      )}
+
+      {requests !== null && requests.length > 0 && (
+        <ul className="cover-request-list">
+          {requests.map((r) => (
+            <CoverRequestRow
+              key={r.id}
+              request={r}
+              onAccept={async (volunteerId) => {
+                await accept(r.id, volunteerId);
+                await onRotaChanged();
+              }}
+              onCancel={() => cancel(r.id)}
+            />
+          ))}
+        </ul>
+      )}
+
+      {/* Asking lands the admin straight on the send: a request nobody has
+          been emailed about is one nobody knows to answer. */}
+      {asking && (
+        <AskForCoverDialog
+          shifts={coverable}
+          onAsk={async (req) => {
+            const created = await create(req);
+            emailCoverRequest(created.id);
+          }}
+          onClose={() => setAsking(false)}
+        />
+      )}
+    </section>
+  );
+}
@@ -25,31 +25,9 @@ import ShapeForm from "./ShapeForm";
 import type { RowEdit } from "./ShiftList";
 import ShiftList from "./ShiftList";
 import { formatShiftDateLong } from "./shifts";
+import { timeAgo } from "./timeAgo";
 import "./DraftRotaPanel.css";
 
-// How long ago the solve ran, which is the only thing anybody asks of a draft's
-// timestamp: the inputs move under it all through the availability window, so
-// "six hours ago" answers "is this worth trusting" where a clock time does not.
-//
-// Rounded down to the coarsest unit that still says something, and never
-// negative — a clock a few seconds behind the server's must not produce "in 4
-// seconds".
-function timeAgo(iso: string): string {
-  const solved = new Date(iso).getTime();
-  if (Number.isNaN(solved)) return "at an unknown time";
-
-  const minutes = Math.max(0, Math.floor((Date.now() - solved) / 60000));
-  if (minutes < 1) return "just now";
-  if (minutes < 60)
-    return `${minutes} ${minutes === 1 ? "minute" : "minutes"} ago`;
-
-  const hours = Math.floor(minutes / 60);
-  if (hours < 24) return `${hours} ${hours === 1 ? "hour" : "hours"} ago`;
-
-  const days = Math.floor(hours / 24);
-  return `${days} ${days === 1 ? "day" : "days"} ago`;
-}
-
 // What the solve concluded, in the terms an admin acts on.
 //
 // The two outcomes lead to different work, so they are worded as different
@@ -58,6 +36,11 @@ function timeAgo(iso: string): string {
 // resolve, and no amount of chasing fixes it.
 function describeOutcome(state: DraftRotaState): string {
   if (!state.success) {
+    // The conflict, when the solver narrowed it down, is the thing to go and
+    // change — so it is the sentence, rather than a footnote under one.
+    if (state.conflict) {
+      return `No rota is possible: ${state.conflict.description}.`;
+    }
     return (
       "No rota is possible from the availability, pins and shapes as they stand " +
       `(the solver said ${state.solverStatus}).`
@@ -410,6 +393,8 @@ export default function DraftRotaPanel({
       // to move to another one. Moving people about is the rota page's job,
       // after allocation, one alteration at a time.
       placement: null,
+      onRecordAttendance: null,
+      signInSheetUrl: null,
     };
   }
 
@@ -468,6 +453,22 @@ export default function DraftRotaPanel({
         </p>
       )}
 
+      {/* Under the count of empty seats, what each one is waiting on — a
+          volunteer to chase, or a rule to relax. */}
+      {state !== null && state.emptySeats.length > 0 && (
+        <ul className="draft-panel-empty-seats">
+          {state.emptySeats.map((seat) => (
+            <li key={`${seat.shiftId}-${seat.role}`}>
+              <span className="draft-panel-change-date">
+                {dateByShiftID.get(seat.shiftId) ?? seat.shiftId}
+              </span>{" "}
+              {seat.count === 1 ? "" : `${seat.count} × `}
+              {seat.role.toLowerCase()}: {seat.detail}
+            </li>
+          ))}
+        </ul>
+      )}
+
       {/* Verbatim, under the sentence that says there is no draft: it is the
           answer to why, and it names the thing to fix. */}
       {loadError && (
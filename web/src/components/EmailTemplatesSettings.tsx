@@ -0,0 +1,276 @@
+import { useState } from "react";
+import Button from "../ui/Button";
+import Dialog from "../ui/Dialog";
+import { previewEmailTemplate } from "../api";
+import { useRotaDefaults } from "../hooks/useRotaDefaults";
+import { useVolunteers } from "../hooks/useVolunteers";
+import SettingsSection from "./SettingsSection";
+import type {
+  EmailPreview,
+  EmailTemplate,
+  EmailTemplateVariable,
+  EmailTemplateWording,
+} from "../types";
+import "./EmailTemplatesSettings.css";
+
+// EmailTemplateForm rewords one email: its subject, its plain-text body and,
+// optionally, an HTML body sent alongside. The variables sit beside the fields
+// because a template is written by copying them, not by remembering them.
+//
+// Preview fills in what is typed — saved or not — for a volunteer the admin
+// picks, against the newest rota, so the wording is read as one real person
+// would get it before anyone does. Nothing is sent to produce it.
+function EmailTemplateForm({
+  template,
+  variables,
+  onSave,
+  onClose,
+}: {
+  template: EmailTemplate;
+  variables: EmailTemplateVariable[];
+  onSave: (wording: EmailTemplateWording) => Promise<void>;
+  onClose: () => void;
+}) {
+  const { volunteers } = useVolunteers();
+  const [subject, setSubject] = useState(template.subject);
+  const [text, setText] = useState(template.text);
+  const [html, setHtml] = useState(template.html);
+  const [volunteerId, setVolunteerId] = useState("");
+  const [preview, setPreview] = useState<EmailPreview | null>(null);
+  const [previewing, setPreviewing] = useState(false);
+  const [saving, setSaving] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+
+  const active = volunteers?.filter((v) => v.active) ?? [];
+  const wording = { subject, text, html };
+
+  async function save() {
+    setSaving(true);
+    setError(null);
+    try {
+      await onSave(wording);
+      onClose();
+    } catch (err: unknown) {
+      // The server's message says which part was wrong and why — a misspelled
+      // variable is named — so it is shown as-is and the form stays open.
+      setError(err instanceof Error ? err.message : "Failed to save the email");
+      setSaving(false);
+    }
+  }
+
+  async function showPreview() {
+    setPreviewing(true);
+    setError(null);
+    try {
+      setPreview(
+        await previewEmailTemplate(template.kind, { ...wording, volunteerId }),
+      );
+    } catch (err: unknown) {
+      setPreview(null);
+      setError(
+        err instanceof Error ? err.message : "Failed to preview the email",
+      );
+    } finally {
+      setPreviewing(false);
+    }
+  }
+
+  return (
+    <Dialog title={template.label} onClose={onClose}>
+      <form
+        className="email-template-form"
+        onSubmit={(e) => {
+          e.preventDefault();
+          void save();
+        }}
+      >
+        <label className="settings-field">
+          Subject
+          <input
+            type="text"
+            value={subject}
+            autoFocus
+            onChange={(e) => setSubject(e.target.value)}
+          />
+        </label>
+
+        <label className="settings-field">
+          Text
+          <textarea
+            rows={10}
+            value={text}
+            onChange={(e) => setText(e.target.value)}
+          />
+        </label>
+        <p className="settings-hint">
+          Every volunteer gets this, and it is all a mail client that does not
+          show HTML will display. It has to include the link.
+        </p>
+
+        <label className="settings-field">
+          HTML
+          <textarea
+            rows={8}
+            value={html}
+            onChange={(e) => setHtml(e.target.value)}
+          />
+        </label>
+        <p className="settings-hint">
+          Optional. Sent alongside the text for mail clients that show it. Leave
+          it empty to send the text alone.
+        </p>
+
+        <details className="email-template-variables">
+          <summary>What a template can say</summary>
+          <dl>
+            {variables.map((v) => (
+              <div key={v.name}>
+                <dt>
+                  <code>{v.name}</code>
+                </dt>
+                <dd>{v.description}</dd>
+              </div>
+            ))}
+          </dl>
+        </details>
+
+        <div className="email-template-preview-controls">
+          <label className="settings-field">
+            Preview for
+            <select
+              value={volunteerId}
+              onChange={(e) => {
+                setVolunteerId(e.target.value);
+                setPreview(null);
+              }}
+            >
+              <option value="">Choose a volunteer</option>
+              {active.map((v) => (
+                <option key={v.id} value={v.id}>
+                  {v.fullName}
+                </option>
+              ))}
+            </select>
+          </label>
+          <Button
+            size="small"
+            onClick={() => void showPreview()}
+            disabled={volunteerId === "" || previewing}
+          >
+            {previewing ? "Filling in…" : "Preview"}
+          </Button>
+        </div>
+
+        {preview && (
+          <div className="email-template-preview">
+            <p className="email-template-preview-head">
+              To {preview.volunteerName} &lt;{preview.to}&gt;
+              <br />
+              <strong>{preview.subject}</strong>
+            </p>
+            <pre className="email-template-preview-text">{preview.text}</pre>
+            {/* Sandboxed with nothing allowed: the HTML is the admin's own,
+                but it is still markup, and a preview must not run any. */}
+            {preview.html && (
+              <iframe
+                className="email-template-preview-html"
+                title="HTML version"
+                sandbox=""
+                srcDoc={preview.html}
+              />
+            )}
+          </div>
+        )}
+
+        {error && <p className="settings-error">{error}</p>}
+
+        <div className="settings-actions">
+          <Button onClick={onClose} disabled={saving}>
+            Cancel
+          </Button>
+          <Button
+            type="submit"
+            disabled={
+              subject.trim() === "" || text.trim() === "" || saving
+            }
+          >
+            {saving ? "Saving…" : "Save email"}
+          </Button>
+        </div>
+      </form>
+    </Dialog>
+  );
+}
+
+// EmailTemplatesSettings is the wording of the emails the drop-in sends
+// volunteers. Each is listed with whether it is still the default, and one an
+// admin has reworded can be put back.
+//
+// A reset is not asked about twice: the default is a click away from being
+// reworded again, and what an admin loses is only what they wrote.
+export default function EmailTemplatesSettings() {
+  const { defaults, saveEmailTemplate, resetEmailTemplate } = useRotaDefaults();
+  const [editing, setEditing] = useState<EmailTemplate | null>(null);
+  const [resetError, setResetError] = useState<string | null>(null);
+
+  async function reset(kind: string) {
+    setResetError(null);
+    try {
+      await resetEmailTemplate(kind);
+    } catch (err: unknown) {
+      setResetError(
+        err instanceof Error ? err.message : "Failed to reset the email",
+      );
+    }
+  }
+
+  return (
+    <SettingsSection
+      title="Emails"
+      blurb="What volunteers are sent when availability is asked for. Each email can be reworded; one that has not been reads as the drop-in always has."
+    >
+      {defaults === null && <p className="settings-empty">Loading…</p>}
+
+      {defaults !== null && (
+        <ul className="email-templates">
+          {defaults.emailTemplates.map((template) => (
+            <li key={template.kind} className="email-template-row">
+              <div className="email-template-summary">
+                <span className="email-template-label">{template.label}</span>
+                <span className="settings-caption">
+                  {template.description}
+                </span>
+              </div>
+              <span className="settings-section-actions">
+                {template.customised ? (
+                  <Button
+                    size="small"
+                    onClick={() => void reset(template.kind)}
+                  >
+                    Reset to default
+                  </Button>
+                ) : (
+                  <span className="settings-unset">Default</span>
+                )}
+                <Button size="small" onClick={() => setEditing(template)}>
+                  Edit
+                </Button>
+              </span>
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {resetError && <p className="settings-error">{resetError}</p>}
+
+      {editing && defaults && (
+        <EmailTemplateForm
+          template={editing}
+          variables={defaults.emailTemplateVariables}
+          onSave={(wording) => saveEmailTemplate(editing.kind, wording)}
+          onClose={() => setEditing(null)}
+        />
+      )}
+    </SettingsSection>
+  );
+}
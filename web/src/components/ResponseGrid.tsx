@@ -63,14 +63,25 @@ function holdsRole(group: AvailabilityGroup, role: Role): boolean {
 // chasing them would be chasing one we already hold. Only then is silence worth
 // reporting, and it reads differently depending on whether they were ever
 // emailed: nobody has failed to reply to a link that was never sent.
+//
+// A preferred count belongs with the answer it came in: it is what the member
+// said, which the group's tag can only summarise.
 function memberNote(member: AvailabilityEntry): string {
-  if (member.replied) return "answered";
+  if (member.replied) {
+    return member.preferredShiftCount === null
+      ? "answered"
+      : `answered, would like ${shiftCount(member.preferredShiftCount)}`;
+  }
   if (member.coveredBy.length > 0) {
     return `covered by ${member.coveredBy.join(" and ")}`;
   }
   return member.sentAt === null ? "not sent" : "no reply";
 }
 
+function shiftCount(n: number): string {
+  return n === 1 ? "1 shift" : `${n} shifts`;
+}
+
 // The URL, the button that copies it, and whatever else can be done with one
 // person's link. The URL is shown in full as well as copied: copying fails
 // silently on an insecure origin or a locked-down browser, and an admin who can
@@ -223,6 +234,27 @@ function AnswerCell({
       </td>
     );
   }
+  // An Absence is a no whether or not anybody answered, so it is shown before
+  // the reply is looked at. A member who ticked the day anyway is flagged: the
+  // tick will not be used, and they may not know they have said both.
+  if (group.absentShiftIds.includes(shift.id)) {
+    const clash = group.members.some(
+      (m) =>
+        m.absentShiftIds.includes(shift.id) &&
+        m.availableShiftIds.includes(shift.id),
+    );
+    return (
+      <td
+        className={`grid-cell grid-cell--away${clash ? " grid-cell--clash" : ""}`}
+        title={clash ? "Said yes, but away" : "Away"}
+      >
+        <span aria-hidden="true">{clash ? "!" : "–"}</span>
+        <span className="grid-hidden">
+          {clash ? "said yes, but away" : "away"}
+        </span>
+      </td>
+    );
+  }
   if (!group.replied) {
     return (
       <td className="grid-cell">
@@ -299,6 +331,20 @@ function GroupRow({
           ) : (
             <span className="grid-tag">{unsent ? "Not sent" : "No reply"}</span>
           )}
+          {/* "Free for all four but only want two": beside the name rather than
+              across the dates, because it is not about any one of them. The
+              ticks alone would read as four Sundays on offer. */}
+          {group.preferredShiftCount !== null && (
+            <>
+              {" "}
+              <span
+                className="grid-tag grid-tag--preferred"
+                title={`Would like ${shiftCount(group.preferredShiftCount)} at most`}
+              >
+                wants {group.preferredShiftCount}
+              </span>
+            </>
+          )}
         </th>
         {shifts.map((shift) => (
           <AnswerCell key={shift.id} group={group} shift={shift} />
@@ -0,0 +1,243 @@
+import { useState } from "react";
+import Button from "../ui/Button";
+import Dialog from "../ui/Dialog";
+import type {
+  RosterDiff,
+  RosterSync,
+  SyncPreview,
+  ValueChange,
+  VolunteerChange,
+} from "../types";
+import SettingsSection from "./SettingsSection";
+import "./RosterSync.css";
+
+function formatSyncedAt(timestamp: string): string {
+  return new Date(timestamp).toLocaleString("en-GB", {
+    day: "numeric",
+    month: "short",
+    hour: "2-digit",
+    minute: "2-digit",
+  });
+}
+
+// A cell cleared or filled in reads as such rather than as a quoted nothing.
+function describeValue(value: string): string {
+  return value === "" ? "(blank)" : value;
+}
+
+function describeMove(label: string, change: ValueChange): string {
+  return `${label}: ${describeValue(change.before)} → ${describeValue(change.after)}`;
+}
+
+// describeChange is one volunteer's line of moves, Roles first since they are
+// what decide the shifts somebody can be put on.
+function describeChange(change: VolunteerChange): string[] {
+  const moves: string[] = [];
+  if (change.rolesGained?.length) {
+    moves.push(`now ${change.rolesGained.join(", ")}`);
+  }
+  if (change.rolesLost?.length) {
+    moves.push(`no longer ${change.rolesLost.join(", ")}`);
+  }
+  if (change.status) moves.push(describeMove("Status", change.status));
+  if (change.group) moves.push(describeMove("Group", change.group));
+  if (change.email) moves.push(describeMove("Email", change.email));
+  return moves;
+}
+
+// summariseDiff is a diff in one line, for the history.
+function summariseDiff(diff: RosterDiff): string {
+  const parts = [
+    diff.added.length > 0 && `${diff.added.length} added`,
+    diff.removed.length > 0 && `${diff.removed.length} removed`,
+    diff.changed.length > 0 && `${diff.changed.length} changed`,
+  ].filter(Boolean);
+  return parts.length > 0 ? parts.join(", ") : "No changes";
+}
+
+// RosterDiffView is a diff set out as an admin checks it against the sheet:
+// who joins, who leaves, what moved for everybody else, and the Roles cells
+// naming no Role — those last because they are not changes, but they are the
+// edit most worth catching.
+export function RosterDiffView({ diff }: { diff: RosterDiff }) {
+  return (
+    <div className="roster-diff">
+      {diff.added.length > 0 && (
+        <section>
+          <h3>Joining ({diff.added.length})</h3>
+          <ul>
+            {diff.added.map((v) => (
+              <li key={v.id}>{v.name}</li>
+            ))}
+          </ul>
+        </section>
+      )}
+      {diff.removed.length > 0 && (
+        <section>
+          <h3>Leaving the roster ({diff.removed.length})</h3>
+          <ul>
+            {diff.removed.map((v) => (
+              <li key={v.id} className="roster-diff-removed">
+                {v.name}
+              </li>
+            ))}
+          </ul>
+        </section>
+      )}
+      {diff.changed.length > 0 && (
+        <section>
+          <h3>Changed ({diff.changed.length})</h3>
+          <ul>
+            {diff.changed.map((change) => (
+              <li key={change.id}>
+                <span className="roster-diff-name">{change.name}</span>
+                <span className="roster-diff-moves">
+                  {describeChange(change).join(" · ")}
+                </span>
+              </li>
+            ))}
+          </ul>
+        </section>
+      )}
+      {diff.unknownRoles.length > 0 && (
+        <section className="roster-diff-warning">
+          <h3>Roles not recognised ({diff.unknownRoles.length})</h3>
+          <p className="settings-hint">
+            These are skipped, so whoever they were meant for will not hold
+            them. Fix the cell on the sheet and preview again.
+          </p>
+          <ul>
+            {diff.unknownRoles.map((u) => (
+              <li key={`${u.volunteerId}-${u.value}`}>
+                <span className="roster-diff-name">{u.name}</span>
+                <span className="roster-diff-moves">“{u.value}”</span>
+              </li>
+            ))}
+          </ul>
+        </section>
+      )}
+    </div>
+  );
+}
+
+function diffIsEmpty(diff: RosterDiff): boolean {
+  return (
+    diff.added.length === 0 &&
+    diff.removed.length === 0 &&
+    diff.changed.length === 0
+  );
+}
+
+// SyncPreviewDialog is a sync read but not yet applied. Apply replaces the
+// roster with exactly what was read — the sheet is not read again — and a
+// refusal (another preview since, or this one left too long) is shown here,
+// beside the diff it refers to.
+export function SyncPreviewDialog({
+  preview,
+  onApply,
+  onClose,
+}: {
+  preview: SyncPreview;
+  onApply: (previewId: string) => Promise<unknown>;
+  onClose: () => void;
+}) {
+  const [applying, setApplying] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const empty = diffIsEmpty(preview.diff);
+
+  async function apply() {
+    setApplying(true);
+    setError(null);
+    try {
+      await onApply(preview.previewId);
+      onClose();
+    } catch (err: unknown) {
+      setError(
+        err instanceof Error ? err.message : "Failed to sync volunteers",
+      );
+      setApplying(false);
+    }
+  }
+
+  return (
+    <Dialog title="Sync from the sheet" onClose={onClose}>
+      {empty ? (
+        <p className="settings-empty">
+          Nobody joins or leaves, and no Roles, states, groups or emails
+          change. Applying still picks up any names corrected on the sheet.
+        </p>
+      ) : (
+        <p className="settings-hint">
+          Check these against the sheet before applying. Nothing changes until
+          you do.
+        </p>
+      )}
+      <RosterDiffView diff={preview.diff} />
+
+      {error && <p className="settings-error">{error}</p>}
+
+      <div className="settings-actions">
+        <Button onClick={onClose} disabled={applying}>
+          Cancel
+        </Button>
+        <Button onClick={() => void apply()} disabled={applying}>
+          {applying ? "Applying…" : "Apply"}
+        </Button>
+      </div>
+    </Dialog>
+  );
+}
+
+// SyncHistory is the syncs admins have applied, newest first, so a roster
+// that changed unexpectedly can be traced to who synced it and what it moved.
+// Each expands to the diff it applied. The list is loaded by the caller, which
+// reloads it once a sync it applied lands.
+export function SyncHistory({
+  syncs,
+  error,
+}: {
+  syncs: RosterSync[] | null;
+  error: string | null;
+}) {
+  const [open, setOpen] = useState<string | null>(null);
+
+  return (
+    <SettingsSection
+      title="Recent syncs"
+      blurb="Each sync applied from the sheet: who applied it, when, and what it changed."
+    >
+      {error && (
+        <p className="settings-error">Could not load the syncs: {error}</p>
+      )}
+      {syncs === null && !error && <p className="settings-empty">Loading…</p>}
+      {syncs !== null && syncs.length === 0 && (
+        <p className="settings-empty">No syncs applied yet.</p>
+      )}
+      {syncs !== null && syncs.length > 0 && (
+        <ul className="roster-syncs">
+          {syncs.map((s) => (
+            <li key={s.id}>
+              <div className="roster-sync-row">
+                <span>
+                  {formatSyncedAt(s.syncedAt)} by {s.syncedBy}
+                </span>
+                <span className="roster-sync-summary">
+                  {summariseDiff(s.diff)}
+                </span>
+                {!diffIsEmpty(s.diff) && (
+                  <Button
+                    size="small"
+                    onClick={() => setOpen(open === s.id ? null : s.id)}
+                  >
+                    {open === s.id ? "Hide" : "Show"}
+                  </Button>
+                )}
+              </div>
+              {open === s.id && <RosterDiffView diff={s.diff} />}
+            </li>
+          ))}
+        </ul>
+      )}
+    </SettingsSection>
+  );
+}
@@ -1,6 +1,7 @@
 import { useState } from "react";
 import Button from "../ui/Button";
 import Dialog from "../ui/Dialog";
+import { useShiftAttendance } from "../hooks/useShiftAttendance";
 import type {
   Assignee,
   PersonRef,
@@ -8,6 +9,7 @@ import type {
   Volunteer,
 } from "../types";
 import { CUSTOM_CHOICE, SERVICE_VOLUNTEER_ROLE, TEAM_LEAD_ROLE } from "../types";
+import AttendanceList from "./AttendanceList";
 import "./RotaEditDialogs.css";
 
 // The Role to offer for a volunteer before the admin says otherwise: the
@@ -26,7 +28,7 @@ function roleSuffix(role: Role): string {
 }
 
 // Every change to a published rota is recorded against a reason, so both
-// dialogs below end in the same field. It is deliberately not pre-filled: a
+// dialogs below share the same field. It is deliberately not pre-filled: a
 // placeholder reason would be worse than none, since the cover record is the
 // only account of why a rota stopped matching its allocation.
 function ReasonField({
@@ -49,6 +51,29 @@ function ReasonField({
   );
 }
 
+// Whether to email the volunteers the change moves. Off by default: most
+// changes are made after a phone call, with the people concerned already told,
+// and an email on top of that is noise. A custom entry has no address, so it is
+// never emailed either way.
+function NotifyField({
+  checked,
+  onChange,
+}: {
+  checked: boolean;
+  onChange: (checked: boolean) => void;
+}) {
+  return (
+    <label className="rota-edit-notify">
+      <input
+        type="checkbox"
+        checked={checked}
+        onChange={(e) => onChange(e.target.checked)}
+      />
+      Email the volunteers this moves
+    </label>
+  );
+}
+
 function DialogActions({
   confirmLabel,
   busy,
@@ -89,20 +114,22 @@ export function ConfirmChangeDialog({
   confirmLabel: string;
   busy: boolean;
   onCancel: () => void;
-  onConfirm: (reason: string) => void;
+  onConfirm: (reason: string, notify: boolean) => void;
 }) {
   const [reason, setReason] = useState("");
+  const [notify, setNotify] = useState(false);
 
   return (
     <Dialog title={title} onClose={onCancel}>
       <form
         onSubmit={(e) => {
           e.preventDefault();
-          onConfirm(reason.trim());
+          onConfirm(reason.trim(), notify);
         }}
       >
         <p className="rota-edit-summary">{summary}</p>
         <ReasonField value={reason} onChange={setReason} />
+        <NotifyField checked={notify} onChange={setNotify} />
         <DialogActions
           confirmLabel={confirmLabel}
           busy={busy}
@@ -149,12 +176,18 @@ export function AssigneeDialog({
   busy: boolean;
   onCancel: () => void;
   // role is omitted for a custom entry, which the API gives no role to.
-  onConfirm: (person: PersonRef, reason: string, role?: Role) => void;
+  onConfirm: (
+    person: PersonRef,
+    reason: string,
+    notify: boolean,
+    role?: Role,
+  ) => void;
 }) {
   const [choice, setChoice] = useState("");
   const [customName, setCustomName] = useState("");
   const [role, setRole] = useState<Role>(SERVICE_VOLUNTEER_ROLE);
   const [reason, setReason] = useState("");
+  const [notify, setNotify] = useState(false);
 
   const isCustom = choice === CUSTOM_CHOICE;
   const trimmedName = customName.trim();
@@ -199,6 +232,7 @@ export function AssigneeDialog({
             onConfirm(
               person,
               reason.trim(),
+              notify,
               isCustom ? undefined : incomingRole,
             );
         }}
@@ -282,6 +316,7 @@ export function AssigneeDialog({
           ))}
 
         <ReasonField value={reason} onChange={setReason} />
+        <NotifyField checked={notify} onChange={setNotify} />
         <DialogActions
           confirmLabel={change.kind === "add" ? "Add" : "Replace"}
           busy={busy}
@@ -649,3 +684,41 @@ export function UnpinDialog({
     </Dialog>
   );
 }
+
+// AttendanceDialog records who turned up to a shift that has happened. Each
+// answer is saved as it is tapped, so there is nothing to confirm and the one
+// button closes it: unlike the changes above, attendance alters nobody's place
+// on the rota, and a wrong tap is undone by tapping again.
+export function AttendanceDialog({
+  shiftId,
+  dateLabel,
+  onClose,
+}: {
+  shiftId: string;
+  dateLabel: string;
+  onClose: () => void;
+}) {
+  const { attendance, error, busy, record } = useShiftAttendance(shiftId);
+
+  return (
+    <Dialog title={`Who came on ${dateLabel}?`} onClose={onClose}>
+      {attendance === null ? (
+        !error && <p className="rota-edit-summary">Loading…</p>
+      ) : (
+        <AttendanceList
+          people={attendance.people}
+          busy={busy}
+          onRecord={record}
+        />
+      )}
+      {error && (
+        <p className="rota-edit-note" role="alert">
+          {error}
+        </p>
+      )}
+      <div className="rota-edit-actions">
+        <Button onClick={onClose}>Done</Button>
+      </div>
+    </Dialog>
+  );
+}
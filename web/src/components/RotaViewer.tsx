@@ -10,13 +10,18 @@ import type {
   Volunteer,
 } from "../types";
 import { TEAM_LEAD_ROLE } from "../types";
+import { printRotaUrl, sendUrl, signInSheetUrl } from "../api";
+import { useCalendarTokens } from "../hooks/useCalendarTokens";
 import { usePreallocations } from "../hooks/usePreallocations";
 import { useRoles } from "../hooks/useRoles";
 import { useVolunteers } from "../hooks/useVolunteers";
 import Button from "../ui/Button";
+import AllocationSend from "./AllocationSend";
+import CoverRequests from "./CoverRequests";
 import type { AssigneeChange } from "./RotaEditDialogs";
 import {
   AssigneeDialog,
+  AttendanceDialog,
   ClosureDialog,
   ConfirmChangeDialog,
   PinDialog,
@@ -40,10 +45,11 @@ interface RotaViewerProps {
   // Admins additionally see shifts whose rota has not been allocated yet, and
   // can turn on editing.
   isAdmin: boolean;
-  // Records one change to the rota and reloads it. Rejects with the server's
-  // own message when the change is refused. Only ever called by the editing
-  // affordances, which are unreachable unless isAdmin.
-  onChange: (change: RotaChange) => Promise<void>;
+  // Records one change to the rota and reloads it, resolving to the change's
+  // cover id. Rejects with the server's own message when the change is
+  // refused. Only ever called by the editing affordances, which are
+  // unreachable unless isAdmin.
+  onChange: (change: RotaChange) => Promise<string>;
   // Shuts or reopens one shift, on the same terms. Separate from onChange
   // because it is not an alteration: it changes what allocation will do rather
   // than what an allocated rota says.
@@ -58,6 +64,9 @@ interface RotaViewerProps {
     shiftId: string,
     seats: { roleId: string; count: number }[],
   ) => Promise<void>;
+  // Rereads the rota. Accepting an answer to a cover request changes it from
+  // outside the editing flow, which reloads after its own changes.
+  onReload: () => Promise<void>;
 }
 
 function getAllNames(shifts: RotaShift[]): string[] {
@@ -89,7 +98,11 @@ function getVolunteerId(shifts: RotaShift[], name: string): string | null {
 // Copies the volunteer's ICS subscription URL to the clipboard. One link
 // covers all their shifts and stays in sync as the rota changes; pasting it
 // into Google/Apple Calendar subscribes them.
-function CalendarCopyButton({ volunteerId }: { volunteerId: string }) {
+//
+// Admin-only, and the link is the volunteer's Calendar Token rather than their
+// id: the id is on every chip of this page, and a feed anybody could work out
+// was a feed anybody could subscribe to.
+function CalendarCopyButton({ url }: { url: string }) {
   const [copied, setCopied] = useState(false);
   const [failed, setFailed] = useState(false);
   const timer = useRef<ReturnType<typeof setTimeout>>(undefined);
@@ -97,7 +110,6 @@ function CalendarCopyButton({ volunteerId }: { volunteerId: string }) {
   useEffect(() => () => clearTimeout(timer.current), []);
 
   async function handleCopy() {
-    const url = `${window.location.origin}/calendars/${volunteerId}.ics`;
     clearTimeout(timer.current);
     try {
       await navigator.clipboard.writeText(url);
@@ -172,7 +184,10 @@ type EditDialog =
   | { kind: "times"; shift: RotaShift }
   // What a shift asks for, which is neither of the above: it is what allocation
   // will try to fill, and it is fixed once allocation has.
-  | { kind: "shape"; shift: RotaShift };
+  | { kind: "shape"; shift: RotaShift }
+  // Who turned up to a shift that has happened, which changes nobody's place
+  // on the rota and so asks for no reason.
+  | { kind: "attendance"; shift: RotaShift };
 
 export default function RotaViewer({
   rotaShifts,
@@ -181,6 +196,7 @@ export default function RotaViewer({
   onSetClosed,
   onSetTimes,
   onSetShape,
+  onReload,
 }: RotaViewerProps) {
   const [selectedName, setSelectedName] = useState("");
   const [inputValue, setInputValue] = useState("");
@@ -268,6 +284,23 @@ export default function RotaViewer({
     () => visibleShifts.some(isUnallocated),
     [visibleShifts],
   );
+  // Whether any rota has been allocated, which is what there is to tell
+  // volunteers about. Read from every shift, not the visible ones: an admin
+  // sees them all anyway, and nobody else is offered the send.
+  const hasAllocated = useMemo(
+    () => rotaShifts.some((s) => s.allocated),
+    [rotaShifts],
+  );
+
+  // The rota "Print rota" prints: the one running now or next, or failing
+  // that the last one there was. Allocated only — a rota nobody has been put
+  // on prints as a page of empty columns.
+  const printableRotaId = useMemo(() => {
+    const allocated = rotaShifts.filter((s) => s.allocated);
+    const today = new Date().toISOString().slice(0, 10);
+    const current = allocated.find((s) => s.date >= today);
+    return (current ?? allocated[allocated.length - 1])?.rotaId ?? null;
+  }, [rotaShifts]);
 
   // Whether any row can be shut or opened. A wider set than hasUnallocated: a
   // shift that is already closed is not "not yet allocated", but reopening it
@@ -286,6 +319,17 @@ export default function RotaViewer({
     () => getVolunteerId(visibleShifts, selectedName),
     [visibleShifts, selectedName],
   );
+  // Only admins are handed calendar links, so only admins fetch them. Somebody
+  // with no link yet gets no button rather than one that copies nothing.
+  const { tokens: calendarTokens } = useCalendarTokens({ enabled: isAdmin });
+  const calendarLink = useMemo(
+    () =>
+      (isAdmin &&
+        calendarTokens?.find((t) => t.volunteerId === selectedVolunteerId)
+          ?.link) ||
+      null,
+    [isAdmin, calendarTokens, selectedVolunteerId],
+  );
 
   const filteredNames = allNames.filter((n) =>
     n.toLowerCase().includes(inputValue.toLowerCase()),
@@ -404,10 +448,19 @@ export default function RotaViewer({
     }
   }
 
-  function submit(change: RotaChange) {
+  // notify emails the volunteers the change moved, once it has been recorded:
+  // the change stands whether or not the email goes, so a refused grant at
+  // Google's consent screen leaves the rota changed and nobody told. The send
+  // lands back on this page, where AllocationSend reports on it.
+  function submit(change: RotaChange, notify: boolean) {
     return run(
       change.date,
-      () => onChange(change),
+      async () => {
+        const coverId = await onChange(change);
+        // A hard navigation, for the reason useAvailabilitySend's start is:
+        // the target is a server redirect out to Google.
+        if (notify) window.location.assign(sendUrl("cover", "", undefined, coverId));
+      },
       "The change was not applied",
     );
   }
@@ -522,6 +575,10 @@ export default function RotaViewer({
     });
   }
 
+  // Today's date, for offering attendance. In UTC, as the cover requests read
+  // it: a row offered a few hours out is one the server refuses with a reason.
+  const today = new Date().toISOString().slice(0, 10);
+
   function rowEdit(shift: RotaShift): RowEdit {
     // Nobody can be on one shift twice, which rules out both ends of a swap
     // independently: the carried person must not already be on the destination,
@@ -602,6 +659,18 @@ export default function RotaViewer({
           });
         },
       },
+      // Offered from the day of the shift on. A shift later that same day is
+      // offered a little early, and the server says it has not started yet.
+      onRecordAttendance:
+        shift.allocated && !shift.closed && shift.date <= today
+          ? () => {
+              setChangeError(null);
+              setOpenMenu(null);
+              setDialog({ kind: "attendance", shift });
+            }
+          : null,
+      signInSheetUrl:
+        shift.allocated && !shift.closed ? signInSheetUrl(shift.id) : null,
     };
   }
 
@@ -637,13 +706,25 @@ export default function RotaViewer({
       <div className="rota-heading">
         <h1>Ilford Drop-in Rota</h1>
         {isAdmin && (
-          <Button
-            size="small"
-            aria-pressed={editing}
-            onClick={() => (editing ? stopEditing() : setEditRequested(true))}
-          >
-            {editing ? "Done" : "Edit rota"}
-          </Button>
+          <div className="rota-heading-actions">
+            {printableRotaId && (
+              <a
+                className="button button--small"
+                href={printRotaUrl(printableRotaId)}
+                target="_blank"
+                rel="noreferrer"
+              >
+                Print rota
+              </a>
+            )}
+            <Button
+              size="small"
+              aria-pressed={editing}
+              onClick={() => (editing ? stopEditing() : setEditRequested(true))}
+            >
+              {editing ? "Done" : "Edit rota"}
+            </Button>
+          </div>
         )}
       </div>
 
@@ -715,9 +796,7 @@ export default function RotaViewer({
                   .map((s) => formatShiftDate(s.date))
                   .join(" · ")}
               </span>
-              {selectedVolunteerId && (
-                <CalendarCopyButton volunteerId={selectedVolunteerId} />
-              )}
+              {calendarLink && <CalendarCopyButton url={calendarLink} />}
             </>
           ) : (
             <span className="upcoming-none">
@@ -802,6 +881,13 @@ export default function RotaViewer({
         </p>
       )}
 
+      {/* Allocating lands an admin here, so this is where the rota they have
+          just allocated is sent out from. */}
+      {isAdmin && <AllocationSend canSend={hasAllocated} />}
+      {isAdmin && (
+        <CoverRequests shifts={rotaShifts} onRotaChanged={onReload} />
+      )}
+
       <ShiftList
         shifts={visibleShifts}
         pinsByDate={pinsByDate}
@@ -821,7 +907,9 @@ export default function RotaViewer({
             setDialog(null);
             setPending(null);
           }}
-          onConfirm={(reason) => void submit({ ...dialog.change, reason })}
+          onConfirm={(reason, notify) =>
+            void submit({ ...dialog.change, reason }, notify)
+          }
         />
       )}
 
@@ -833,17 +921,20 @@ export default function RotaViewer({
           volunteersError={volunteersError}
           busy={saving}
           onCancel={() => setDialog(null)}
-          onConfirm={(person, reason, role) =>
-            void submit({
-              date: dialog.date,
-              in: person,
-              out:
-                dialog.change.kind === "replace"
-                  ? personRef(dialog.change.outgoing)
-                  : undefined,
-              role,
-              reason,
-            })
+          onConfirm={(person, reason, notify, role) =>
+            void submit(
+              {
+                date: dialog.date,
+                in: person,
+                out:
+                  dialog.change.kind === "replace"
+                    ? personRef(dialog.change.outgoing)
+                    : undefined,
+                role,
+                reason,
+              },
+              notify,
+            )
           }
         />
       )}
@@ -906,6 +997,14 @@ export default function RotaViewer({
         />
       )}
 
+      {editing && dialog?.kind === "attendance" && (
+        <AttendanceDialog
+          shiftId={dialog.shift.id}
+          dateLabel={formatShiftDateLong(dialog.shift.date)}
+          onClose={() => setDialog(null)}
+        />
+      )}
+
       {editing && dialog?.kind === "unpin" && (
         <UnpinDialog
           name={dialog.pin.name}
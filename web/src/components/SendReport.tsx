@@ -0,0 +1,105 @@
+import Button from "../ui/Button";
+import type { AvailabilitySend } from "../types";
+import "./SendReport.css";
+
+// What each kind of send calls one of its emails, in the count it leads with.
+const SEND_NOUNS: Record<AvailabilitySend["mode"], string> = {
+  round: "email",
+  reminder: "reminder",
+  resend: "email",
+  allocation: "email",
+  cover: "email",
+  "cover-request": "email",
+  "swap-offer": "email",
+};
+
+// What a send did, or is doing.
+//
+// A running send counts up rather than spinning: it takes about ninety seconds,
+// and a bar with no numbers on it for that long is indistinguishable from a
+// hang. A finished one leads with its failures, because those are the ones that
+// still need an admin — the successes are only there to say how many there were.
+// An interrupted one says where it stopped and offers to carry on from there.
+//
+// Shared by the two pages a send is started from: the round's, on Admin →
+// Allocation, and the allocated rota's, where everybody is told their shifts
+// and the volunteers a rota change moved are told what it did.
+export default function SendReport({
+  send,
+  onResume,
+  onDismiss,
+}: {
+  send: AvailabilitySend;
+  onResume: () => void;
+  onDismiss: () => void;
+}) {
+  const noun = SEND_NOUNS[send.mode];
+  const sent = `Sent ${send.sent.length} ${noun}${send.sent.length === 1 ? "" : "s"}`;
+
+  return (
+    <div className="send-report">
+      <div className="send-report-head">
+        {send.status === "finished" && (
+          <span>
+            {sent}
+            {send.failed.length > 0 && `, ${send.failed.length} failed`}
+          </span>
+        )}
+        {send.status === "interrupted" && (
+          <span>
+            Stopped at {send.done} of {send.total} — the server restarted
+            partway through
+          </span>
+        )}
+        {send.status === "running" && (
+          <span>
+            Sending… {send.done} of {send.total}
+          </span>
+        )}
+        {send.status === "interrupted" && (
+          <Button size="small" onClick={onResume}>
+            Resume
+          </Button>
+        )}
+        {send.status !== "running" && (
+          <Button size="small" onClick={onDismiss}>
+            Dismiss
+          </Button>
+        )}
+      </div>
+
+      {send.error !== null && <p className="send-report-error">{send.error}</p>}
+
+      {send.failed.length > 0 && (
+        <ul className="send-failures">
+          {send.failed.map((f) => (
+            <li key={f.volunteerId}>
+              <strong>{f.volunteerName}</strong>
+              {f.email !== null && ` (${f.email})`} — {f.error}
+            </li>
+          ))}
+        </ul>
+      )}
+
+      {/* Allocation and cover sends mark nobody, so there is no "only the
+          rest" to send to: trying again tells everybody on the rota again, and
+          a rota change is only ever told once from here. A cover request's
+          chases whoever has not answered, which is its own "the rest". A
+          swap offer marks nobody either, but the swap page offers the shift
+          whether or not the email arrived. */}
+      {send.finished && send.failed.length > 0 && (
+        <p className="send-report-note">
+          {send.mode === "allocation"
+            ? "Sending again emails everyone on the rota, not only these — fix their addresses on the roster first."
+            : send.mode === "cover"
+              ? "The change itself stands. Let these volunteers know some other way."
+              : send.mode === "cover-request"
+                ? "Emailing the request again asks everyone who has not answered yet, these included."
+                : send.mode === "swap-offer"
+                  ? "The shift is still offered on their swap page. Let these volunteers know some other way."
+                  : "Nobody here has been marked as sent, so sending the round again will try them and leave everyone else alone."}
+        </p>
+      )}
+    </div>
+  );
+}
@@ -166,6 +166,13 @@ export interface RowEdit {
   onEditShape: () => void;
   // Moving people between allocated shifts, where there are any.
   placement: PlacementEdit | null;
+  // Recording who turned up, offered on allocated shifts that have happened.
+  // Null everywhere else, including every row of a draft: nobody has been on
+  // a shift that has not been run yet.
+  onRecordAttendance: (() => void) | null;
+  // The shift's sign-in sheet to print, on allocated shifts that run. Null on
+  // closed ones and every row of a draft, where nobody is on to sign in.
+  signInSheetUrl: string | null;
 }
 
 function Chip({
@@ -736,6 +743,29 @@ function ShiftRow({
           </button>
         )}
 
+        {edit && !pending && edit.onRecordAttendance && (
+          <button
+            type="button"
+            className="shift-add shift-attendance"
+            aria-label={`Record who came on ${formatShiftDateLong(shift.date)}`}
+            onClick={edit.onRecordAttendance}
+          >
+            Attendance
+          </button>
+        )}
+
+        {edit && !pending && edit.signInSheetUrl && (
+          <a
+            className="shift-add shift-sign-in"
+            href={edit.signInSheetUrl}
+            target="_blank"
+            rel="noreferrer"
+            aria-label={`Print the sign-in sheet for ${formatShiftDateLong(shift.date)}`}
+          >
+            Sign-in sheet
+          </a>
+        )}
+
         {/* The tap and keyboard equivalent of dropping on empty space. Only
             while a pick is in flight, and never during a drag, where it would
             move the rows out from under the pointer. */}
@@ -0,0 +1,202 @@
+import { Link } from "wouter";
+import Button from "../ui/Button";
+import { useSwapPage } from "../hooks/useSwapPage";
+import type { SwapOffer, SwapShift } from "../types";
+import { formatShiftTimes, formatVolunteerDate } from "./shiftTimes";
+import "./AvailabilityForm.css";
+import "./SwapPage.css";
+
+// What one of their own shifts says about the ask to give it up. Taken is
+// worth naming who by: it is the volunteer's cue that they are off it the
+// moment an admin says yes, and whose name to expect on the rota.
+function askStatus(shift: SwapShift): string | null {
+  if (shift.requestStatus === "open") {
+    return "Offered to the others who could do it. Nobody has taken it yet.";
+  }
+  if (shift.requestStatus === "taken") {
+    return `${shift.takenBy ?? "Someone"} has taken it. It is yours until an admin approves the swap.`;
+  }
+  return null;
+}
+
+function OwnShift({
+  shift,
+  busy,
+  onOffer,
+  onWithdraw,
+}: {
+  shift: SwapShift;
+  busy: boolean;
+  onOffer: () => void;
+  onWithdraw: () => void;
+}) {
+  const status = askStatus(shift);
+
+  return (
+    <li className="shift-choice swap-shift">
+      <span className="shift-choice-label">
+        <span className="shift-choice-date">
+          {formatVolunteerDate(shift.date)}
+        </span>
+        <span className="shift-choice-time">
+          {formatShiftTimes(shift.start, shift.end)}
+          {shift.role && ` · ${shift.role}`}
+        </span>
+        {status && <span className="swap-shift-status">{status}</span>}
+      </span>
+      {shift.requestId === null ? (
+        <Button size="small" disabled={busy} onClick={onOffer}>
+          I can't do this
+        </Button>
+      ) : (
+        <Button size="small" disabled={busy} onClick={onWithdraw}>
+          I can do it after all
+        </Button>
+      )}
+    </li>
+  );
+}
+
+function Offer({
+  offer,
+  busy,
+  onAccept,
+}: {
+  offer: SwapOffer;
+  busy: boolean;
+  onAccept: () => void;
+}) {
+  return (
+    <li className="shift-choice swap-shift">
+      <span className="shift-choice-label">
+        <span className="shift-choice-date">
+          {formatVolunteerDate(offer.date)}
+        </span>
+        <span className="shift-choice-time">
+          {formatShiftTimes(offer.start, offer.end)}
+          {offer.role && ` · ${offer.role}`}
+        </span>
+        <span className="swap-shift-status">{offer.from} can't make it.</span>
+      </span>
+      <Button size="small" disabled={busy} onClick={onAccept}>
+        I'll do it
+      </Button>
+    </li>
+  );
+}
+
+// SwapPage is the volunteer's link once the rota is out: the shifts they are
+// down for, with a way to give one up, and the shifts others have given up
+// that they could do instead. Same link, same narrow page as the availability
+// form — it is the one they already have, opened from the same phone.
+//
+// Giving a shift up does not take them off it. It stays theirs until somebody
+// takes it — and, when admins approve swaps, until one does — because a
+// shift nobody takes still needs somebody there.
+export default function SwapPage({ token }: { token: string }) {
+  const { page, deadLink, error, busy, offer, withdraw, accept } =
+    useSwapPage(token);
+
+  if (deadLink) {
+    return (
+      <main className="availability">
+        <h1>Your shifts</h1>
+        <p className="availability-dead-link">
+          This link is not one we recognise. Check you followed the whole link
+          from your email, or ask us for a new one.
+        </p>
+      </main>
+    );
+  }
+
+  if (page === null) {
+    return (
+      <main className="availability">
+        {error ? (
+          <p className="availability-message availability-message--error">
+            Could not load your shifts: {error}
+          </p>
+        ) : (
+          <p className="app-status">Loading…</p>
+        )}
+      </main>
+    );
+  }
+
+  return (
+    <main className="availability">
+      <h1>Shifts for {page.volunteerName}</h1>
+
+      <p className="availability-intro">
+        If you can no longer do one of your shifts, offer it here. It goes to
+        everyone who could do it and said they were free, and the first to take
+        it is put on the rota in your place
+        {page.needsApproval ? " once an admin approves the swap" : ""}. Until
+        then the shift is still yours.
+      </p>
+
+      {/* Only a Team lead sees this: they are the one on the night who knows
+          who turned up, and the same link lets them say so. */}
+      {page.leadsShifts && (
+        <p className="swap-note">
+          <Link href={`/attendance/${token}`}>
+            Record who came to the shifts you led
+          </Link>
+        </p>
+      )}
+
+      {/* aria-live because an action moves nothing into focus: a refusal —
+          most often somebody else taking a shift first — would otherwise never
+          reach a screen reader. */}
+      <div aria-live="polite">
+        {error && (
+          <p className="availability-message availability-message--error">
+            {error}
+          </p>
+        )}
+      </div>
+
+      {page.shifts.length === 0 ? (
+        <p className="swap-empty">You have no shifts coming up.</p>
+      ) : (
+        <ul className="shift-choices">
+          {page.shifts.map((shift) => (
+            <OwnShift
+              key={shift.shiftId}
+              shift={shift}
+              busy={busy !== null}
+              onOffer={() => void offer(shift.shiftId)}
+              onWithdraw={() => void withdraw(shift.requestId ?? "")}
+            />
+          ))}
+        </ul>
+      )}
+
+      <h2 className="swap-heading">Shifts you could take</h2>
+      {page.offers.length === 0 ? (
+        <p className="swap-empty">
+          Nobody who shares a shift you are free for has offered one up.
+        </p>
+      ) : (
+        <>
+          {page.needsApproval && (
+            <p className="swap-note">
+              Taking one asks an admin to approve the swap. You are not on it
+              until they do.
+            </p>
+          )}
+          <ul className="shift-choices">
+            {page.offers.map((o) => (
+              <Offer
+                key={o.requestId}
+                offer={o}
+                busy={busy !== null}
+                onAccept={() => void accept(o.requestId)}
+              />
+            ))}
+          </ul>
+        </>
+      )}
+    </main>
+  );
+}
@@ -20,3 +20,14 @@ export function formatShiftTimes(start: string, end: string): string {
   if (from === "00:00" && to === "00:00") return "All day";
   return `${from}\u2013${to}`;
 }
+
+// "Sunday 2 August" — the weekday matters more than the year on the volunteer's
+// pages: they are checking which Sundays they can do, and every shift is within
+// a couple of months.
+export function formatVolunteerDate(date: string): string {
+  return new Date(date).toLocaleDateString("en-GB", {
+    weekday: "long",
+    day: "numeric",
+    month: "long",
+  });
+}
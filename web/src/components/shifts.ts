@@ -1,4 +1,9 @@
-import type { Assignee, PersonRef, RotaShift } from "../types";
+import type {
+  Assignee,
+  AttendanceStatus,
+  PersonRef,
+  RotaShift,
+} from "../types";
 
 // The small facts about a shift and the people on it that both the shift rows
 // and the screens around them need. Their own module rather than exports from
@@ -43,3 +48,15 @@ export function formatShiftDateLong(dateStr: string): string {
     month: "short",
   });
 }
+
+// ATTENDANCE_CHOICES are the answers to "did they come?", in the order they
+// are offered. Shared by the admins' dialog and a Team lead's page so that the
+// two never word the same answer differently.
+export const ATTENDANCE_CHOICES: {
+  status: AttendanceStatus;
+  label: string;
+}[] = [
+  { status: "attended", label: "Came" },
+  { status: "left_early", label: "Left early" },
+  { status: "no_show", label: "Didn't come" },
+];
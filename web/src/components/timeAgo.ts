@@ -0,0 +1,22 @@
+// How long ago something happened, which is all anybody asks of a draft's
+// solve or the roster's last sync: the thing underneath moves on its own, so
+// "six hours ago" answers "is this worth trusting" where a clock time does not.
+//
+// Rounded down to the coarsest unit that still says something, and never
+// negative — a clock a few seconds behind the server's must not produce "in 4
+// seconds".
+export function timeAgo(iso: string): string {
+  const then = new Date(iso).getTime();
+  if (Number.isNaN(then)) return "at an unknown time";
+
+  const minutes = Math.max(0, Math.floor((Date.now() - then) / 60000));
+  if (minutes < 1) return "just now";
+  if (minutes < 60)
+    return `${minutes} ${minutes === 1 ? "minute" : "minutes"} ago`;
+
+  const hours = Math.floor(minutes / 60);
+  if (hours < 24) return `${hours} ${hours === 1 ? "hour" : "hours"} ago`;
+
+  const days = Math.floor(hours / 24);
+  return `${days} ${days === 1 ? "day" : "days"} ago`;
+}
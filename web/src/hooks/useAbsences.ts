@@ -0,0 +1,66 @@
+import { useCallback, useEffect, useState } from "react";
+import { createAbsence, deleteAbsence, fetchAbsences } from "../api";
+import type { Absence, NewAbsence } from "../types";
+
+interface UseAbsences {
+  // null while the first load is still in flight.
+  absences: Absence[] | null;
+  error: string | null;
+  // Records days away for a volunteer, then reloads.
+  add: (volunteerId: string, absence: NewAbsence) => Promise<void>;
+  // Removes one, then reloads.
+  remove: (id: string) => Promise<void>;
+}
+
+// useAbsences owns the Absences an admin sees on the volunteers screen: every
+// one under way or still to come, whoever recorded it.
+export function useAbsences({
+  enabled = true,
+}: { enabled?: boolean } = {}): UseAbsences {
+  const [absences, setAbsences] = useState<Absence[] | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [reloads, setReloads] = useState(0);
+
+  useEffect(() => {
+    if (!enabled) return;
+    let cancelled = false;
+    void fetchAbsences()
+      .then((loaded) => {
+        if (cancelled) return;
+        setAbsences(loaded);
+        setError(null);
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        setError(
+          err instanceof Error ? err.message : "Failed to load the absences",
+        );
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [enabled, reloads]);
+
+  // Reloads whether or not the write landed, then re-throws so the caller can
+  // say why, as usePairingRules does.
+  const write = useCallback(async (apply: () => Promise<void>) => {
+    try {
+      await apply();
+    } finally {
+      setReloads((n) => n + 1);
+    }
+  }, []);
+
+  const add = useCallback(
+    (volunteerId: string, absence: NewAbsence) =>
+      write(() => createAbsence(volunteerId, absence)),
+    [write],
+  );
+
+  const remove = useCallback(
+    (id: string) => write(() => deleteAbsence(id)),
+    [write],
+  );
+
+  return { absences, error, add, remove };
+}
@@ -1,10 +1,16 @@
 import { useCallback, useEffect, useMemo, useState } from "react";
 import {
   AvailabilityLinkError,
+  addOwnAbsence,
   fetchAvailabilityForm,
+  removeOwnAbsence,
   submitAvailability,
 } from "../api";
-import type { AvailabilityFormState, AvailabilityLinkFailure } from "../types";
+import type {
+  AvailabilityFormState,
+  AvailabilityLinkFailure,
+  NewAbsence,
+} from "../types";
 
 export type SubmitState = "idle" | "sending" | "sent" | "error";
 
@@ -21,10 +27,18 @@ interface UseAvailabilityForm {
   // until they have answered once — before that the form's yeses are a default
   // nobody chose, so calling them changes would be inventing an earlier answer.
   changed: ReadonlySet<string>;
+  // How many of the selected dates they would like, 0 for no preference.
+  preferred: number;
+  setPreferred: (count: number) => void;
   // Set, not toggle: the form asks yes or no per shift, and answering "no" to a
   // shift already at no must leave it there rather than flip it to yes.
   setAvailable: (shiftId: string, available: boolean) => void;
   submit: () => Promise<void>;
+  // Record or remove one of their own Absences. Each takes effect at once — it
+  // is not part of the answer waiting to be sent — and throws the server's
+  // message so the caller can say why one was refused.
+  addAbsence: (absence: NewAbsence) => Promise<void>;
+  removeAbsence: (id: string) => Promise<void>;
 }
 
 const NOTHING_CHANGED: ReadonlySet<string> = new Set();
@@ -45,10 +59,12 @@ export function useAvailabilityForm(token: string): UseAvailabilityForm {
   const [error, setError] = useState<string | null>(null);
   const [submitState, setSubmitState] = useState<SubmitState>("idle");
   const [selected, setSelected] = useState<Set<string>>(new Set());
+  const [preferred, setPreferredCount] = useState(0);
 
   const adopt = useCallback((loaded: AvailabilityFormState) => {
     setForm(loaded);
     setSelected(new Set(loaded.selectedShiftIds));
+    setPreferredCount(loaded.preferredShiftCount);
     setError(null);
   }, []);
 
@@ -87,17 +103,24 @@ export function useAvailabilityForm(token: string): UseAvailabilityForm {
     });
   }, []);
 
+  const setPreferred = useCallback((count: number) => {
+    setSubmitState("idle");
+    setPreferredCount(count);
+  }, []);
+
   // `form` is the last thing the server confirmed, so it doubles as the answer
   // being edited away from — and a send adopts the response, which is what makes
   // the highlighting clear itself rather than persisting over a saved form.
   // Closed shifts are skipped: they carry no answer either side of the
-  // comparison.
+  // comparison. So are the days they are away, which are a no however the
+  // answer reads.
   const changed = useMemo(() => {
     if (form === null || !form.submitted) return NOTHING_CHANGED;
     const answered = new Set(form.selectedShiftIds);
+    const away = new Set(form.absentShiftIds);
     const differing = new Set<string>();
     for (const shift of form.shifts) {
-      if (shift.closed) continue;
+      if (shift.closed || away.has(shift.id)) continue;
       if (answered.has(shift.id) !== selected.has(shift.id)) {
         differing.add(shift.id);
       }
@@ -110,7 +133,15 @@ export function useAvailabilityForm(token: string): UseAvailabilityForm {
     try {
       // The whole selection every time, never just what changed: an absent
       // shift is a no, so a partial send would record unavailability.
-      adopt(await submitAvailability(token, [...selected]));
+      // The preferred count is held to the dates still on yes: saying no to a
+      // date after picking a number must not send a number the server refuses.
+      adopt(
+        await submitAvailability(
+          token,
+          [...selected],
+          Math.min(preferred, selected.size),
+        ),
+      );
       setSubmitState("sent");
     } catch (err: unknown) {
       if (err instanceof AvailabilityLinkError) {
@@ -121,7 +152,55 @@ export function useAvailabilityForm(token: string): UseAvailabilityForm {
       setError(err instanceof Error ? err.message : "Failed to send");
       setSubmitState("error");
     }
-  }, [token, selected, adopt]);
+  }, [token, selected, preferred, adopt]);
+
+  // An Absence comes back with the whole form, but adopting it would throw away
+  // any answer they have changed and not sent. Only the dates the Absence
+  // moved are touched: one it now covers comes off yes, and one it no longer
+  // covers goes back to what the server holds for it.
+  const absenceChanged = useCallback(
+    (loaded: AvailabilityFormState) => {
+      const wasAway = new Set(form?.absentShiftIds ?? []);
+      const away = new Set(loaded.absentShiftIds);
+      const serverYes = new Set(loaded.selectedShiftIds);
+      setForm(loaded);
+      setSelected((previous) => {
+        const next = new Set(previous);
+        for (const id of away) next.delete(id);
+        for (const id of wasAway) {
+          if (!away.has(id) && serverYes.has(id)) next.add(id);
+        }
+        return next;
+      });
+      setError(null);
+    },
+    [form],
+  );
+
+  const editAbsence = useCallback(
+    async (apply: () => Promise<AvailabilityFormState>) => {
+      try {
+        absenceChanged(await apply());
+      } catch (err: unknown) {
+        if (err instanceof AvailabilityLinkError) {
+          setDeadLink(err.reason);
+          return;
+        }
+        throw err;
+      }
+    },
+    [absenceChanged],
+  );
+
+  const addAbsence = useCallback(
+    (absence: NewAbsence) => editAbsence(() => addOwnAbsence(token, absence)),
+    [token, editAbsence],
+  );
+
+  const removeAbsence = useCallback(
+    (id: string) => editAbsence(() => removeOwnAbsence(token, id)),
+    [token, editAbsence],
+  );
 
   return {
     form,
@@ -130,7 +209,11 @@ export function useAvailabilityForm(token: string): UseAvailabilityForm {
     submitState,
     selected,
     changed,
+    preferred,
+    setPreferred,
     setAvailable,
     submit,
+    addAbsence,
+    removeAbsence,
   };
 }
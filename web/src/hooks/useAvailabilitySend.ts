@@ -1,6 +1,6 @@
 import { useCallback, useEffect, useRef, useState } from "react";
 import { useLocation, useSearch } from "wouter";
-import { fetchSend, sendUrl } from "../api";
+import { fetchSend, resumeUrl, sendUrl } from "../api";
 import type { AvailabilitySend, SendMode } from "../types";
 
 // How often a running send is asked how far it has got. A send moves once every
@@ -18,6 +18,9 @@ interface UseAvailabilitySend {
   // Starts a send. It navigates the whole page rather than fetching, because the
   // server answers with a redirect to Google's consent screen.
   start: (mode: SendMode, deadline: string, volunteerId?: string) => void;
+  // Carries on with an interrupted send. A navigation too, and for the same
+  // reason: it needs a fresh Gmail grant.
+  resume: (id: string) => void;
   // Clears the finished send off the screen by taking its id out of the URL, so
   // a reload does not bring the same report back.
   dismiss: () => void;
@@ -26,21 +29,21 @@ interface UseAvailabilitySend {
 // useAvailabilitySend owns the half of a send that happens after the redirect.
 //
 // A send is not a request this page makes: it is a full-page trip out to Google
-// for the gmail.send grant, which lands back here with a job id in the query.
+// for the gmail.send grant, which lands back here with a send id in the query.
 // Everything the admin sees of it — how far it has got, who it reached, who it
 // failed on — is read back from that id. The URL is therefore the state, which
 // is why dismissing means navigating rather than setting a flag: a reload has to
 // land on the same screen the admin last saw.
 //
-// onFinished is called once the send stops, because the round it acted on has
-// changed underneath the page — every volunteer it reached now carries a sent
-// stamp they did not have before.
+// onFinished is called once the send stops — finished, or found interrupted —
+// because the round it acted on has changed underneath the page: every volunteer
+// it reached now carries a sent stamp they did not have before.
 export function useAvailabilitySend(
   onFinished?: () => void,
 ): UseAvailabilitySend {
   const [location, navigate] = useLocation();
   const params = new URLSearchParams(useSearch());
-  const jobID = params.get("send");
+  const sendID = params.get("send");
   const consentError = params.get("sendError");
 
   const [send, setSend] = useState<AvailabilitySend | null>(null);
@@ -55,17 +58,19 @@ export function useAvailabilitySend(
   });
 
   useEffect(() => {
-    if (jobID === null) return;
+    if (sendID === null) return;
 
     let cancelled = false;
     let timer: ReturnType<typeof setTimeout> | undefined;
 
     const poll = () => {
-      fetchSend(jobID)
+      fetchSend(sendID)
         .then((latest) => {
           if (cancelled) return;
           setSend(latest);
-          if (latest.finished) {
+          // An interrupted send will not move again until somebody resumes
+          // it, which is a fresh trip through Google and a fresh page load.
+          if (latest.status !== "running") {
             onFinishedRef.current?.();
             return;
           }
@@ -85,7 +90,7 @@ export function useAvailabilitySend(
       cancelled = true;
       if (timer !== undefined) clearTimeout(timer);
     };
-  }, [jobID]);
+  }, [sendID]);
 
   const start = useCallback(
     (mode: SendMode, deadline: string, volunteerId?: string) => {
@@ -96,6 +101,10 @@ export function useAvailabilitySend(
     [],
   );
 
+  const resume = useCallback((id: string) => {
+    window.location.assign(resumeUrl(id));
+  }, []);
+
   // Navigating to the bare path drops both query parameters, which is what
   // takes the report off the screen — everything below is read from them.
   const dismiss = useCallback(() => {
@@ -103,9 +112,10 @@ export function useAvailabilitySend(
   }, [navigate, location]);
 
   return {
-    send: jobID === null ? null : send,
-    error: consentError ?? (jobID === null ? null : pollError),
+    send: sendID === null ? null : send,
+    error: consentError ?? (sendID === null ? null : pollError),
     start,
+    resume,
     dismiss,
   };
 }
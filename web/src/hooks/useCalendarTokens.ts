@@ -0,0 +1,83 @@
+import { useCallback, useEffect, useState } from "react";
+import {
+  fetchCalendarTokens,
+  mintCalendarToken,
+  mintMissingCalendarTokens,
+  revokeCalendarToken,
+} from "../api";
+import type { CalendarToken } from "../types";
+
+interface UseCalendarTokens {
+  // null while the first load is still in flight; [] is "nobody has a link",
+  // which the volunteers screen offers to fix rather than calling it loading.
+  tokens: CalendarToken[] | null;
+  error: string | null;
+  // Makes a link for everybody without one, then reloads. Resolves to how many
+  // it made, so the caller can say so.
+  mintMissing: () => Promise<number>;
+  // Replaces one volunteer's link, then reloads.
+  mint: (volunteerId: string) => Promise<void>;
+  // Removes one volunteer's link, then reloads.
+  revoke: (volunteerId: string) => Promise<void>;
+}
+
+// useCalendarTokens owns the links volunteers subscribe to their shifts by.
+// Only the volunteers screen and the rota page's copy button read them: a link
+// is a secret, so nothing else in the app has any business holding one.
+export function useCalendarTokens({
+  enabled = true,
+}: { enabled?: boolean } = {}): UseCalendarTokens {
+  const [tokens, setTokens] = useState<CalendarToken[] | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [reloads, setReloads] = useState(0);
+
+  useEffect(() => {
+    if (!enabled) return;
+    let cancelled = false;
+    void fetchCalendarTokens()
+      .then((loaded) => {
+        if (cancelled) return;
+        setTokens(loaded);
+        setError(null);
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        setError(
+          err instanceof Error ? err.message : "Failed to load the calendar links",
+        );
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [enabled, reloads]);
+
+  // Reloads whether or not the write landed, then re-throws so the caller can
+  // say why, as usePairingRules does.
+  const write = useCallback(async <T,>(apply: () => Promise<T>) => {
+    try {
+      return await apply();
+    } finally {
+      setReloads((n) => n + 1);
+    }
+  }, []);
+
+  const mintMissing = useCallback(
+    () => write(() => mintMissingCalendarTokens()),
+    [write],
+  );
+
+  const mint = useCallback(
+    (volunteerId: string) =>
+      write(async () => {
+        await mintCalendarToken(volunteerId);
+      }),
+    [write],
+  );
+
+  const revoke = useCallback(
+    (volunteerId: string) => write(() => revokeCalendarToken(volunteerId)),
+    [write],
+  );
+
+  return { tokens, error, mintMissing, mint, revoke };
+}
@@ -0,0 +1,76 @@
+import { useCallback, useEffect, useState } from "react";
+import {
+  AvailabilityLinkError,
+  CoverLinkClosed,
+  answerCover,
+  fetchCoverForm,
+} from "../api";
+import type { CoverFormState } from "../types";
+
+interface UseCoverForm {
+  // null while the first load is in flight.
+  form: CoverFormState | null;
+  // Set when the link is not one, which is its own screen.
+  deadLink: boolean;
+  // The server's reason a link stopped answering — covered, no longer
+  // needed, already started — shown as it is.
+  closed: string | null;
+  error: string | null;
+  busy: boolean;
+  answer: (answer: "yes" | "no") => Promise<void>;
+}
+
+// useCoverForm owns one volunteer's cover link. An answer comes back as the
+// link now stands, as the swap page's actions do, and a link that closes
+// between loading and answering moves to the closed screen rather than
+// showing an error beside a question nobody is asking any more.
+export function useCoverForm(token: string): UseCoverForm {
+  const [form, setForm] = useState<CoverFormState | null>(null);
+  const [deadLink, setDeadLink] = useState(false);
+  const [closed, setClosed] = useState<string | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [busy, setBusy] = useState(false);
+
+  const fail = useCallback((err: unknown, fallback: string) => {
+    if (err instanceof AvailabilityLinkError) {
+      setDeadLink(true);
+      return;
+    }
+    if (err instanceof CoverLinkClosed) {
+      setClosed(err.message);
+      return;
+    }
+    setError(err instanceof Error ? err.message : fallback);
+  }, []);
+
+  useEffect(() => {
+    let current = true;
+    fetchCoverForm(token)
+      .then((loaded) => {
+        if (current) setForm(loaded);
+      })
+      .catch((err: unknown) => {
+        if (current) fail(err, "Failed to load the shift");
+      });
+    return () => {
+      current = false;
+    };
+  }, [token, fail]);
+
+  const answer = useCallback(
+    async (value: "yes" | "no") => {
+      setBusy(true);
+      try {
+        setForm(await answerCover(token, value));
+        setError(null);
+      } catch (err: unknown) {
+        fail(err, "Failed to send your answer");
+      } finally {
+        setBusy(false);
+      }
+    },
+    [token, fail],
+  );
+
+  return { form, deadLink, closed, error, busy, answer };
+}
@@ -0,0 +1,81 @@
+import { useCallback, useEffect, useState } from "react";
+import {
+  acceptCoverAnswer,
+  cancelCoverRequest,
+  createCoverRequest,
+  fetchCoverRequests,
+} from "../api";
+import type { CoverRequest, NewCoverRequest } from "../types";
+
+interface UseCoverRequests {
+  // null while the first load is still in flight; [] is "nothing open".
+  requests: CoverRequest[] | null;
+  error: string | null;
+  // Asks for cover, then reloads. Resolves with the request, whose id the
+  // send that emails everybody is started with.
+  create: (req: NewCoverRequest) => Promise<CoverRequest>;
+  // Puts a volunteer who said yes on the shift, then reloads. Resolves with
+  // the Cover it was recorded as, so the caller can offer to tell people.
+  accept: (id: string, volunteerId: string) => Promise<string>;
+  cancel: (id: string) => Promise<void>;
+  // Rereads the list: accepting an answer changes the rota, and the page
+  // showing it reloads it alongside.
+  reload: () => void;
+}
+
+// useCoverRequests owns the open cover requests, for the admins asking. Every
+// write rejects with the server's own message — the rota having moved under a
+// request is the usual one — and reloads either way, as useSwapRequests does.
+export function useCoverRequests(): UseCoverRequests {
+  const [requests, setRequests] = useState<CoverRequest[] | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [reloads, setReloads] = useState(0);
+
+  useEffect(() => {
+    let cancelled = false;
+    void fetchCoverRequests()
+      .then((loaded) => {
+        if (cancelled) return;
+        setRequests(loaded);
+        setError(null);
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        setError(
+          err instanceof Error ? err.message : "Failed to load cover requests",
+        );
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [reloads]);
+
+  const reload = useCallback(() => setReloads((n) => n + 1), []);
+
+  const write = useCallback(
+    async <T>(apply: () => Promise<T>): Promise<T> => {
+      try {
+        return await apply();
+      } finally {
+        reload();
+      }
+    },
+    [reload],
+  );
+
+  const create = useCallback(
+    (req: NewCoverRequest) => write(() => createCoverRequest(req)),
+    [write],
+  );
+  const accept = useCallback(
+    (id: string, volunteerId: string) =>
+      write(() => acceptCoverAnswer(id, volunteerId)),
+    [write],
+  );
+  const cancel = useCallback(
+    (id: string) => write(() => cancelCoverRequest(id)),
+    [write],
+  );
+
+  return { requests, error, create, accept, cancel, reload };
+}
@@ -0,0 +1,79 @@
+import { useCallback, useEffect, useState } from "react";
+import {
+  AvailabilityLinkError,
+  fetchLeadAttendance,
+  recordLeadAttendance,
+} from "../api";
+import type { AttendanceStatus, LeadAttendancePage } from "../types";
+
+interface UseLeadAttendance {
+  // null while the first load is in flight.
+  page: LeadAttendancePage | null;
+  // Set when the link is not one, which is its own screen.
+  deadLink: boolean;
+  error: string | null;
+  // Whether an answer is in flight. One at a time: each comes back as the
+  // whole page, and two racing would leave whichever landed last on screen.
+  busy: boolean;
+  record: (
+    shiftId: string,
+    volunteerId: string,
+    status: AttendanceStatus | null,
+  ) => Promise<void>;
+}
+
+// useLeadAttendance owns a Team lead's attendance page. An answer comes back
+// as the page now stands, as the swap page's actions do, so what is on screen
+// after a tap is what the server holds.
+export function useLeadAttendance(token: string): UseLeadAttendance {
+  const [page, setPage] = useState<LeadAttendancePage | null>(null);
+  const [deadLink, setDeadLink] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [busy, setBusy] = useState(false);
+
+  useEffect(() => {
+    let current = true;
+    fetchLeadAttendance(token)
+      .then((loaded) => {
+        if (current) setPage(loaded);
+      })
+      .catch((err: unknown) => {
+        if (!current) return;
+        if (err instanceof AvailabilityLinkError) {
+          setDeadLink(true);
+          return;
+        }
+        setError(
+          err instanceof Error ? err.message : "Failed to load your shifts",
+        );
+      });
+    return () => {
+      current = false;
+    };
+  }, [token]);
+
+  const record = useCallback(
+    async (
+      shiftId: string,
+      volunteerId: string,
+      status: AttendanceStatus | null,
+    ) => {
+      setBusy(true);
+      try {
+        setPage(
+          await recordLeadAttendance(token, shiftId, volunteerId, status),
+        );
+        setError(null);
+      } catch (err: unknown) {
+        setError(
+          err instanceof Error ? err.message : "Failed to record attendance",
+        );
+      } finally {
+        setBusy(false);
+      }
+    },
+    [token],
+  );
+
+  return { page, deadLink, error, busy, record };
+}
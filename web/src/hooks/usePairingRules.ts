@@ -0,0 +1,70 @@
+import { useCallback, useEffect, useState } from "react";
+import {
+  createPairingRule,
+  deletePairingRule,
+  fetchPairingRules,
+} from "../api";
+import type { NewPairingRule, PairingRule } from "../types";
+
+interface UsePairingRules {
+  // null while the first load is still in flight; [] is "no rules", which the
+  // volunteers screen renders differently from "not loaded yet".
+  rules: PairingRule[] | null;
+  error: string | null;
+  // Adds one, then reloads. Rejects with the server's own message when the
+  // write is refused — "Alice and Bob already have a pairing rule" is the whole
+  // explanation, and the caller shows it rather than inventing one.
+  addRule: (rule: NewPairingRule) => Promise<void>;
+  // Removes one, then reloads.
+  removeRule: (id: string) => Promise<void>;
+}
+
+// usePairingRules owns the rules an admin has made about pairs of volunteers.
+// Only the volunteers screen uses it: the allocator reads them server-side, and
+// nothing else in the app needs to know who is kept apart from whom.
+export function usePairingRules(): UsePairingRules {
+  const [rules, setRules] = useState<PairingRule[] | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [reloads, setReloads] = useState(0);
+
+  useEffect(() => {
+    let cancelled = false;
+    void fetchPairingRules()
+      .then((loaded) => {
+        if (cancelled) return;
+        setRules(loaded);
+        setError(null);
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        setError(
+          err instanceof Error ? err.message : "Failed to load the pairing rules",
+        );
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [reloads]);
+
+  // Reloads whether or not the write landed, then re-throws so the caller can
+  // say why, as useStandingPreallocations does.
+  const write = useCallback(async (apply: () => Promise<void>) => {
+    try {
+      await apply();
+    } finally {
+      setReloads((n) => n + 1);
+    }
+  }, []);
+
+  const addRule = useCallback(
+    (rule: NewPairingRule) => write(() => createPairingRule(rule)),
+    [write],
+  );
+
+  const removeRule = useCallback(
+    (id: string) => write(() => deletePairingRule(id)),
+    [write],
+  );
+
+  return { rules, error, addRule, removeRule };
+}
@@ -0,0 +1,45 @@
+import { useCallback, useEffect, useState } from "react";
+import { fetchRosterSyncs } from "../api";
+import type { RosterSync } from "../types";
+
+interface UseRosterSyncs {
+  // null while the first load is still in flight.
+  syncs: RosterSync[] | null;
+  error: string | null;
+  // Reads the list again, for after a sync is applied.
+  reload: () => void;
+}
+
+// useRosterSyncs owns the record of the syncs admins have applied, newest
+// first, which the volunteers screen lists under the roster.
+export function useRosterSyncs({
+  enabled = true,
+}: { enabled?: boolean } = {}): UseRosterSyncs {
+  const [syncs, setSyncs] = useState<RosterSync[] | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [reloads, setReloads] = useState(0);
+
+  useEffect(() => {
+    if (!enabled) return;
+    let cancelled = false;
+    void fetchRosterSyncs()
+      .then((loaded) => {
+        if (cancelled) return;
+        setSyncs(loaded);
+        setError(null);
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        setError(
+          err instanceof Error ? err.message : "Failed to load the syncs",
+        );
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [enabled, reloads]);
+
+  const reload = useCallback(() => setReloads((n) => n + 1), []);
+
+  return { syncs, error, reload };
+}
@@ -18,8 +18,9 @@ interface UseRota {
   // at once, and it is done from the draft panel rather than from here.
   reload: () => Promise<void>;
   // change records one alteration and reloads the rota, whether or not the
-  // change was accepted. It rejects with the server's message when it was not.
-  change: (change: RotaChange) => Promise<void>;
+  // change was accepted. It resolves to the change's cover id, and rejects with
+  // the server's message when it was not accepted.
+  change: (change: RotaChange) => Promise<string>;
   // setClosed shuts or reopens one shift, reloading on the same terms. Not an
   // alteration: it changes what allocation will do rather than what an
   // allocated rota says.
@@ -74,7 +75,7 @@ export function useRota(): UseRota {
   const change = useCallback(
     async (rotaChange: RotaChange) => {
       try {
-        await createAlteration(rotaChange);
+        return await createAlteration(rotaChange);
       } finally {
         await load();
       }
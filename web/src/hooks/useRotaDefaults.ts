@@ -1,11 +1,20 @@
 import { useCallback, useEffect, useState } from "react";
 import {
   fetchRotaDefaults,
+  resetEmailTemplate,
   saveAllocationSettings,
   saveDefaultShape,
+  saveEmailTemplate,
   saveShiftTimeDefaults,
+  saveSwapSettings,
 } from "../api";
-import type { AllocationSettings, RotaDefaults, ShiftTimes } from "../types";
+import type {
+  AllocationSettings,
+  EmailTemplate,
+  EmailTemplateWording,
+  RotaDefaults,
+  ShiftTimes,
+} from "../types";
 
 interface UseRotaDefaults {
   // null while the first load is still in flight. A loaded record with empty
@@ -23,6 +32,15 @@ interface UseRotaDefaults {
   // stored — which is not always what was sent, since an answer naming a rule
   // this server does not have is dropped.
   saveAllocationRules: (settings: AllocationSettings) => Promise<void>;
+  // Rewords one email, or puts it back to the default. Either leaves the
+  // other emails as they were.
+  saveEmailTemplate: (
+    kind: string,
+    wording: EmailTemplateWording,
+  ) => Promise<void>;
+  resetEmailTemplate: (kind: string) => Promise<void>;
+  // Switches whether a swap the volunteers arrange waits for an admin.
+  saveSwapApproval: (needed: boolean) => Promise<void>;
 }
 
 // useRotaDefaults owns the settings an admin keeps for the drop-in as a whole.
@@ -81,5 +99,53 @@ export function useRotaDefaults(): UseRotaDefaults {
     setError(null);
   }, []);
 
-  return { defaults, error, saveShiftTimes, saveShape, saveAllocationRules };
+  // An email's write answers with that email alone, so it is swapped into
+  // the list in place — the same merge as the allocation rules, one level down.
+  const holdEmailTemplate = useCallback((saved: EmailTemplate) => {
+    setDefaults((current) =>
+      current === null
+        ? current
+        : {
+            ...current,
+            emailTemplates: current.emailTemplates.map((t) =>
+              t.kind === saved.kind ? saved : t,
+            ),
+          },
+    );
+    setError(null);
+  }, []);
+
+  const saveTemplate = useCallback(
+    async (kind: string, wording: EmailTemplateWording) => {
+      holdEmailTemplate(await saveEmailTemplate(kind, wording));
+    },
+    [holdEmailTemplate],
+  );
+
+  const resetTemplate = useCallback(
+    async (kind: string) => {
+      holdEmailTemplate(await resetEmailTemplate(kind));
+    },
+    [holdEmailTemplate],
+  );
+
+  // The swap switch is its whole section, so what was sent is what is held.
+  const saveSwapApproval = useCallback(async (needed: boolean) => {
+    await saveSwapSettings(needed);
+    setDefaults((current) =>
+      current === null ? current : { ...current, swapsNeedApproval: needed },
+    );
+    setError(null);
+  }, []);
+
+  return {
+    defaults,
+    error,
+    saveShiftTimes,
+    saveShape,
+    saveAllocationRules,
+    saveEmailTemplate: saveTemplate,
+    resetEmailTemplate: resetTemplate,
+    saveSwapApproval,
+  };
 }
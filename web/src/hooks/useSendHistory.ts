@@ -0,0 +1,44 @@
+import { useCallback, useEffect, useState } from "react";
+import { fetchSendHistory } from "../api";
+import type { AvailabilitySendSummary } from "../types";
+
+interface UseSendHistory {
+  // null while the first load is still in flight; [] is a round nobody has
+  // sent anything for yet.
+  sends: AvailabilitySendSummary[] | null;
+  error: string | null;
+  reload: () => void;
+}
+
+// useSendHistory reads a round's sends, newest first. It is what an admin
+// checks before sending reminders — whether somebody already has — and where
+// a send cut short by a deploy is found and resumed.
+//
+// rotaId is the round being shown; undefined is the latest, as the server
+// reads it.
+export function useSendHistory(rotaId?: string): UseSendHistory {
+  const [sends, setSends] = useState<AvailabilitySendSummary[] | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [reloads, setReloads] = useState(0);
+
+  useEffect(() => {
+    let cancelled = false;
+    void fetchSendHistory(rotaId)
+      .then((loaded) => {
+        if (cancelled) return;
+        setSends(loaded);
+        setError(null);
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        setError(err instanceof Error ? err.message : "Failed to load the sends");
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [rotaId, reloads]);
+
+  const reload = useCallback(() => setReloads((n) => n + 1), []);
+
+  return { sends, error, reload };
+}
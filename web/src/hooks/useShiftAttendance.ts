@@ -0,0 +1,57 @@
+import { useCallback, useEffect, useState } from "react";
+import { fetchShiftAttendance, recordAttendance } from "../api";
+import type { AttendanceStatus, ShiftAttendance } from "../types";
+
+interface UseShiftAttendance {
+  // null while the first load is in flight.
+  attendance: ShiftAttendance | null;
+  error: string | null;
+  busy: boolean;
+  record: (volunteerId: string, status: AttendanceStatus | null) => void;
+}
+
+// useShiftAttendance owns one shift's attendance while an admin has it open.
+// Each answer comes back as the shift now stands, so nothing is reconciled
+// here; a refusal leaves the list as it was and says why.
+export function useShiftAttendance(shiftId: string): UseShiftAttendance {
+  const [attendance, setAttendance] = useState<ShiftAttendance | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [busy, setBusy] = useState(false);
+
+  useEffect(() => {
+    let current = true;
+    fetchShiftAttendance(shiftId)
+      .then((loaded) => {
+        if (current) setAttendance(loaded);
+      })
+      .catch((err: unknown) => {
+        if (!current) return;
+        setError(
+          err instanceof Error ? err.message : "Failed to load the attendance",
+        );
+      });
+    return () => {
+      current = false;
+    };
+  }, [shiftId]);
+
+  const record = useCallback(
+    (volunteerId: string, status: AttendanceStatus | null) => {
+      setBusy(true);
+      recordAttendance(shiftId, volunteerId, status)
+        .then((saved) => {
+          setAttendance(saved);
+          setError(null);
+        })
+        .catch((err: unknown) => {
+          setError(
+            err instanceof Error ? err.message : "Failed to record attendance",
+          );
+        })
+        .finally(() => setBusy(false));
+    },
+    [shiftId],
+  );
+
+  return { attendance, error, busy, record };
+}
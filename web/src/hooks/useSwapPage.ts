@@ -0,0 +1,87 @@
+import { useCallback, useEffect, useState } from "react";
+import {
+  AvailabilityLinkError,
+  acceptSwap,
+  fetchSwapPage,
+  requestSwap,
+  withdrawSwap,
+} from "../api";
+import type { SwapPageState } from "../types";
+
+interface UseSwapPage {
+  // null while the first load is in flight.
+  page: SwapPageState | null;
+  // Set when the link is not one, which is its own screen.
+  deadLink: boolean;
+  error: string | null;
+  // The request or shift an action is in flight for, so its button alone
+  // shows it — the rest of the page stays usable.
+  busy: string | null;
+  offer: (shiftId: string) => Promise<void>;
+  withdraw: (requestId: string) => Promise<void>;
+  accept: (requestId: string) => Promise<void>;
+}
+
+// useSwapPage owns one volunteer's swap page. Every action answers with the
+// page as it now stands, so there is no local state to reconcile: what is on
+// screen after a tap is what the server holds.
+export function useSwapPage(token: string): UseSwapPage {
+  const [page, setPage] = useState<SwapPageState | null>(null);
+  const [deadLink, setDeadLink] = useState(false);
+  const [error, setError] = useState<string | null>(null);
+  const [busy, setBusy] = useState<string | null>(null);
+
+  useEffect(() => {
+    let current = true;
+    fetchSwapPage(token)
+      .then((loaded) => {
+        if (current) setPage(loaded);
+      })
+      .catch((err: unknown) => {
+        if (!current) return;
+        if (err instanceof AvailabilityLinkError) {
+          setDeadLink(true);
+          return;
+        }
+        setError(
+          err instanceof Error ? err.message : "Failed to load your shifts",
+        );
+      });
+    return () => {
+      current = false;
+    };
+  }, [token]);
+
+  // A refusal — somebody else took it first, the shift was moved — leaves the
+  // page as it was and says why. It does not re-read: the message is the
+  // thing to show, and the next tap will land on whatever is true by then.
+  const act = useCallback(
+    async (key: string, call: () => Promise<SwapPageState>) => {
+      setBusy(key);
+      try {
+        setPage(await call());
+        setError(null);
+      } catch (err: unknown) {
+        setError(err instanceof Error ? err.message : "Something went wrong");
+      } finally {
+        setBusy(null);
+      }
+    },
+    [],
+  );
+
+  const offer = useCallback(
+    (shiftId: string) => act(shiftId, () => requestSwap(token, shiftId)),
+    [act, token],
+  );
+  const withdraw = useCallback(
+    (requestId: string) => act(requestId, () => withdrawSwap(token, requestId)),
+    [act, token],
+  );
+  const accept = useCallback(
+    (requestId: string) => act(requestId, () => acceptSwap(token, requestId)),
+    [act, token],
+  );
+
+  return { page, deadLink, error, busy, offer, withdraw, accept };
+}
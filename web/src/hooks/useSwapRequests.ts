@@ -0,0 +1,61 @@
+import { useCallback, useEffect, useState } from "react";
+import { approveSwap, declineSwap, fetchSwapRequests } from "../api";
+import type { SwapRequest } from "../types";
+
+interface UseSwapRequests {
+  // null while the first load is still in flight; [] is "nothing live".
+  requests: SwapRequest[] | null;
+  error: string | null;
+  // Approves or declines one, then reloads. Rejects with the server's own
+  // message — most often that the rota no longer allows the swap, which is
+  // the admin's cue to decline it instead.
+  approve: (id: string) => Promise<void>;
+  decline: (id: string) => Promise<void>;
+}
+
+// useSwapRequests owns the swaps the volunteers are arranging, for the admins
+// who approve them. Only the settings screen reads it: that is where the
+// switch deciding whether anyone has to lives.
+export function useSwapRequests(): UseSwapRequests {
+  const [requests, setRequests] = useState<SwapRequest[] | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [reloads, setReloads] = useState(0);
+
+  useEffect(() => {
+    let cancelled = false;
+    void fetchSwapRequests()
+      .then((loaded) => {
+        if (cancelled) return;
+        setRequests(loaded);
+        setError(null);
+      })
+      .catch((err: unknown) => {
+        if (cancelled) return;
+        setError(err instanceof Error ? err.message : "Failed to load swaps");
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [reloads]);
+
+  // Reloads whether or not the answer landed, as usePairingRules does: a
+  // refusal usually means the list moved under the admin.
+  const write = useCallback(async (apply: () => Promise<unknown>) => {
+    try {
+      await apply();
+    } finally {
+      setReloads((n) => n + 1);
+    }
+  }, []);
+
+  const approve = useCallback(
+    (id: string) => write(() => approveSwap(id)),
+    [write],
+  );
+  const decline = useCallback(
+    (id: string) => write(() => declineSwap(id)),
+    [write],
+  );
+
+  return { requests, error, approve, decline };
+}
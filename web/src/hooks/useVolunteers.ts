@@ -1,16 +1,48 @@
 import { useCallback, useEffect, useState } from "react";
-import { fetchVolunteers, syncVolunteers } from "../api";
-import type { Volunteer } from "../types";
+import {
+  applySync,
+  createVolunteer,
+  deleteVolunteer,
+  fetchVolunteers,
+  importRoster,
+  previewSync,
+  updateVolunteer,
+} from "../api";
+import type {
+  RosterDiff,
+  RosterSource,
+  SyncPreview,
+  Volunteer,
+  VolunteerEdit,
+} from "../types";
 
-export type SyncState = "idle" | "syncing" | "ok" | "error";
+// A sync is read, then reviewed, then applied: "reviewing" is a preview open
+// and waiting on the admin.
+export type SyncState = "idle" | "syncing" | "reviewing" | "ok" | "error";
 
 interface UseVolunteers {
   // null while the first load is still in flight; [] is a genuinely empty
   // roster, which the caller renders differently from "not loaded yet".
   volunteers: Volunteer[] | null;
+  // Where the roster is kept; null until the first load lands.
+  source: RosterSource | null;
   error: string | null;
   syncState: SyncState;
-  sync: () => Promise<void>;
+  // Reads the sheet and resolves to what a sync would change, applying
+  // nothing; null when the sheet could not be read, which syncState reports.
+  previewSync: () => Promise<SyncPreview | null>;
+  // Applies the preview named, reloading before it resolves to the diff
+  // applied. Rejects with the server's own message for a preview gone stale.
+  confirmSync: (previewId: string) => Promise<RosterDiff>;
+  // Closes a preview; a no-op once it has been applied.
+  cancelSync: () => void;
+  // The edits a roster kept in the database allows. Each reloads whether or
+  // not it landed and rejects with the server's own message when refused.
+  add: (edit: VolunteerEdit) => Promise<void>;
+  save: (id: string, edit: VolunteerEdit) => Promise<void>;
+  remove: (id: string) => Promise<void>;
+  // Copies the sheet into an empty roster, resolving to how many it copied.
+  importFromSheet: () => Promise<number>;
 }
 
 interface UseVolunteersOptions {
@@ -22,12 +54,13 @@ interface UseVolunteersOptions {
 
 // useVolunteers owns the admin roster: the read and the sync that invalidates
 // it. They belong together because a sync is only worth firing to change what
-// the list shows, so the hook reloads on success and the view never has to
-// remember to.
+// the list shows, so the hook reloads once one is applied and the view never
+// has to remember to.
 export function useVolunteers({
   enabled = true,
 }: UseVolunteersOptions = {}): UseVolunteers {
   const [volunteers, setVolunteers] = useState<Volunteer[] | null>(null);
+  const [source, setSource] = useState<RosterSource | null>(null);
   const [error, setError] = useState<string | null>(null);
   const [syncState, setSyncState] = useState<SyncState>("idle");
 
@@ -39,7 +72,8 @@ export function useVolunteers({
     () =>
       fetchVolunteers()
         .then((loaded) => {
-          setVolunteers(loaded);
+          setVolunteers(loaded.volunteers);
+          setSource(loaded.source);
           setError(null);
         })
         .catch((err: unknown) => {
@@ -54,18 +88,78 @@ export function useVolunteers({
     if (enabled) void load();
   }, [enabled, load]);
 
-  const sync = useCallback(async () => {
+  const preview = useCallback(async () => {
     setSyncState("syncing");
     try {
-      await syncVolunteers();
-      // Reload before reporting success, so "Synced" is never shown next to the
-      // pre-sync list.
-      await load();
-      setSyncState("ok");
+      const read = await previewSync();
+      setSyncState("reviewing");
+      return read;
     } catch {
       setSyncState("error");
+      return null;
     }
-  }, [load]);
+  }, []);
+
+  // A refused confirm leaves the preview open, so the admin reads why beside
+  // the diff they agreed to rather than in the one-line caption.
+  const confirmSync = useCallback(
+    async (previewId: string) => {
+      const applied = await applySync(previewId);
+      // Reload before reporting success, so "Roster up to date" is never shown
+      // next to the pre-sync list.
+      await load();
+      setSyncState("ok");
+      return applied;
+    },
+    [load],
+  );
+
+  // Only a preview still open is cancelled: closing the dialog on an applied
+  // sync leaves "Roster up to date" standing.
+  const cancelSync = useCallback(
+    () => setSyncState((s) => (s === "reviewing" ? "idle" : s)),
+    [],
+  );
+
+  // An edit reloads the list under it whatever the outcome, so a refusal is
+  // shown beside what the roster actually holds, then re-throws for the caller
+  // to say why.
+  const write = useCallback(
+    async <T>(apply: () => Promise<T>): Promise<T> => {
+      try {
+        return await apply();
+      } finally {
+        await load();
+      }
+    },
+    [load],
+  );
+
+  const add = useCallback(
+    (edit: VolunteerEdit) => write(() => createVolunteer(edit)),
+    [write],
+  );
+  const save = useCallback(
+    (id: string, edit: VolunteerEdit) => write(() => updateVolunteer(id, edit)),
+    [write],
+  );
+  const remove = useCallback(
+    (id: string) => write(() => deleteVolunteer(id)),
+    [write],
+  );
+  const importFromSheet = useCallback(() => write(importRoster), [write]);
 
-  return { volunteers, error, syncState, sync };
+  return {
+    volunteers,
+    source,
+    error,
+    syncState,
+    previewSync: preview,
+    confirmSync,
+    cancelSync,
+    add,
+    save,
+    remove,
+    importFromSheet,
+  };
 }
@@ -125,6 +125,15 @@ export interface AllocationSettings {
   // The share of a rota one volunteer may work, 0 to 1. Only read when the
   // max_frequency rule is on.
   maxFrequency: number;
+  // The Fairness Horizon: how many rotas, or how many months, fairness looks
+  // back over. At most one is set; neither means the previous rota alone.
+  fairnessRotas: number;
+  fairnessMonths: number;
+  // How much each rota further back counts, 0 to 1. 0 means no decay.
+  fairnessDecay: number;
+  // Whether a shift somebody was recorded as not turning up to stops counting
+  // towards their share of past shifts.
+  fairnessSkipsNoShows: boolean;
 }
 
 // The settings record as the screen reads it: the answers, plus the rules the
@@ -136,6 +145,54 @@ export interface RotaDefaults extends ShiftTimes {
   defaultShape: ShapeSeat[];
   allocationSettings: AllocationSettings;
   switchableConstraints: SwitchableConstraint[];
+  // Every email an admin can reword, each with the wording it is sent with now.
+  emailTemplates: EmailTemplate[];
+  emailTemplateVariables: EmailTemplateVariable[];
+  // Whether a shift a volunteer takes off another waits for an admin before
+  // the rota changes.
+  swapsNeedApproval: boolean;
+}
+
+// EmailTemplate is one email's wording as the server offers it: the admin's when
+// they have saved one, the default otherwise. The list of emails comes down
+// with it, the way the switchable rules do, so a new email is a server edit.
+//
+// All three fields are Go templates — "{{.FirstName}}", "{{.Link}}". An empty
+// html sends the text alone.
+export interface EmailTemplate {
+  kind: string;
+  label: string;
+  description: string;
+  subject: string;
+  text: string;
+  html: string;
+  // False while the default is what is sent, which is when "Reset" has
+  // nothing to do.
+  customised: boolean;
+}
+
+// EmailTemplateWording is the part of an EmailTemplate an admin writes, and
+// the whole of what saving or previewing one sends.
+export type EmailTemplateWording = Pick<
+  EmailTemplate,
+  "subject" | "text" | "html"
+>;
+
+// EmailTemplateVariable is one thing a template can say, spelled the way a
+// template says it.
+export interface EmailTemplateVariable {
+  name: string;
+  description: string;
+}
+
+// EmailPreview is a template filled in for one real volunteer, as they would
+// receive it. Nothing is sent to produce it.
+export interface EmailPreview {
+  to: string;
+  volunteerName: string;
+  subject: string;
+  text: string;
+  html: string;
 }
 
 // Assignee is one person on a shift: a real volunteer or a custom (manual)
@@ -161,15 +218,124 @@ export interface Assignee {
 //
 // gender is free text as recorded on the roster sheet, so it is shown as-is and
 // null when nothing was recorded — never inferred. active is false for someone
-// who has stopped volunteering: the roster lists them rather than hiding them.
+// who is not volunteering today: the roster lists them rather than hiding them.
+//
+// state is why: where they are in their time with the drop-in, today, so a
+// pause that has ended reads as active. pausedUntil is the day a running pause
+// ends; status is the sheet's own cell when the app could not read it, which
+// is what an admin has to go and put right.
 export interface Volunteer {
   id: string;
   name: string;
   fullName: string;
+  firstName: string;
+  lastName: string;
+  email: string | null;
   roles: Role[];
   group: string | null;
   gender: string | null;
   active: boolean;
+  state: VolunteerState;
+  pausedUntil: string | null;
+  status: string | null;
+}
+
+// VolunteerState is a volunteer's Status cell, read. "active" and "onboarding"
+// are volunteering — sent rounds and allocated, though somebody onboarding only
+// in the last Role they hold. "unrecognised" is a cell saying none of these.
+export type VolunteerState =
+  | "active"
+  | "onboarding"
+  | "paused"
+  | "left"
+  | "unrecognised";
+
+// RosterSource is where the roster is kept: the Google Sheet, re-read by a
+// sync, or the database, edited on the volunteers screen. The server's config
+// decides; the screen only offers what that source allows.
+export type RosterSource = "sheet" | "database";
+
+// Roster is the volunteer list with where it came from.
+export interface Roster {
+  volunteers: Volunteer[];
+  source: RosterSource;
+}
+
+// RosterSyncStatus is how fresh a roster read from the sheet is: when a sync
+// last replaced it, and how the latest read went — "failed" leaves the roster
+// as lastSyncedAt had it. Times are null for never.
+export interface RosterSyncStatus {
+  lastSyncedAt: string | null;
+  lastAttemptAt: string | null;
+  outcome: "ok" | "failed" | "none";
+}
+
+// RosterDiff is what a sync from the sheet changes about the roster: who joins,
+// who leaves, and for everybody on both, the Roles, state, group and email that
+// moved. unknownRoles are the Roles cells naming a Role the app does not know
+// — skipped, so whoever was meant to hold it will not.
+export interface RosterDiff {
+  added: RosterDiffVolunteer[];
+  removed: RosterDiffVolunteer[];
+  changed: VolunteerChange[];
+  unknownRoles: UnknownRoleValue[];
+}
+
+export interface RosterDiffVolunteer {
+  id: string;
+  name: string;
+}
+
+// VolunteerChange is what moved for one volunteer. A field absent did not.
+export interface VolunteerChange {
+  id: string;
+  name: string;
+  rolesGained?: Role[];
+  rolesLost?: Role[];
+  status?: ValueChange;
+  group?: ValueChange;
+  email?: ValueChange;
+}
+
+// ValueChange is a sheet cell before and after; either may be "".
+export interface ValueChange {
+  before: string;
+  after: string;
+}
+
+export interface UnknownRoleValue {
+  volunteerId: string;
+  name: string;
+  value: string;
+}
+
+// SyncPreview is a sync read but not applied. Confirming names previewId, so
+// what is applied is what was shown.
+export interface SyncPreview {
+  previewId: string;
+  diff: RosterDiff;
+}
+
+// RosterSync is one sync an admin confirmed: who, when, and what it changed.
+export interface RosterSync {
+  id: string;
+  syncedBy: string;
+  syncedAt: string;
+  diff: RosterDiff;
+}
+
+// VolunteerEdit is a volunteer as an admin states them on a roster kept in the
+// database: the state is one of those a person can be put in — "unrecognised"
+// is a cell nobody means — and pausedUntil is filled in only for a pause.
+export interface VolunteerEdit {
+  firstName: string;
+  lastName: string;
+  roles: Role[];
+  state: Exclude<VolunteerState, "unrecognised">;
+  pausedUntil: string;
+  gender: string;
+  email: string;
+  group: string;
 }
 
 // DefinedRota is a rota that has just been defined: the span it covers and the
@@ -233,6 +399,36 @@ export interface DraftShift {
   assignees: Assignee[];
 }
 
+// EmptySeat is seats of one role on one shift that a draft left empty, and why.
+// reason is a stable code — no_holder, no_available_holder,
+// holders_already_on_shift, group_does_not_fit, not_chosen, several_reasons,
+// or the name of the switchable rule that kept every holder off (max_frequency,
+// no_back_to_back, one_shift_per_month, male_required). detail is the same as a
+// sentence, which is what this side shows.
+export interface EmptySeat {
+  shiftId: string;
+  role: string;
+  count: number;
+  reason: string;
+  detail: string;
+}
+
+// RotaConflict is why an infeasible draft found no rota: a minimal set of pins
+// and rules that cannot all hold, so undoing any one of them is a way out.
+// constraints are the rule names the Allocation Settings switch (plus the
+// fundamentals, e.g. seat_capacity); description says it as a sentence.
+export interface RotaConflict {
+  constraints: string[];
+  preallocations: {
+    shiftId: string;
+    date: string;
+    volunteerId: string;
+    name: string;
+    role: string;
+  }[];
+  description: string;
+}
+
 // DraftRotaState is where the rota in flight's Draft Rota Allocation has got to,
 // and the rota it drafted. There is one draft, for the one rota in flight, so
 // this is the whole of what the rota page knows about drafting.
@@ -282,6 +478,12 @@ export interface DraftRotaState {
   // it back.
   hash: string;
   shifts: DraftShift[];
+  // Why each seat the draft left empty was left empty, in date order: who to
+  // chase or which rule to relax. Empty for an infeasible draft.
+  emptySeats: EmptySeat[];
+  // Why an infeasible draft found no rota, or null — for a draft that solved,
+  // and for an infeasible one the solver could not narrow down.
+  conflict: RotaConflict | null;
 }
 
 // AllocateOutcome is what came of allocating: the rota went out, or it had
@@ -362,6 +564,11 @@ export interface AvailabilityEntry {
   replied: boolean;
   submittedAt: string | null;
   availableShiftIds: string[];
+  // How many of those shifts they would like, or null when they did not say.
+  preferredShiftCount: number | null;
+  // The shifts one of their Absences covers. A no whatever availableShiftIds
+  // says — a shift in both is the overlap the responses grid flags.
+  absentShiftIds: string[];
   coveredBy: string[];
   // The Roles they hold on the roster, in priority order — what the responses
   // grid filters its rows by. Empty for a volunteer the roster has dropped
@@ -370,8 +577,19 @@ export interface AvailabilityEntry {
 }
 
 // Which emails a send covers, and what they say. The server owns the selection
-// rules; these are the names it answers to.
-export type SendMode = "round" | "reminder" | "resend";
+// rules; these are the names it answers to. The first three ask for
+// availability; "allocation" tells everyone on an allocated rota their shifts;
+// "cover" tells the volunteers one rota change moved what it did to them; and
+// "cover-request" asks everyone a cover request is for whether they can; and
+// "swap-offer" tells everyone who could take a swapped shift that it is going.
+export type SendMode =
+  | "round"
+  | "reminder"
+  | "resend"
+  | "allocation"
+  | "cover"
+  | "cover-request"
+  | "swap-offer";
 
 // One volunteer a send reached, or failed to. error is what makes it a failure —
 // a bounced address, or a volunteer with no address at all.
@@ -382,7 +600,7 @@ export interface SendOutcome {
   error: string | null;
 }
 
-// AvailabilitySend is one send in flight or just finished.
+// AvailabilitySend is one send, in flight or finished.
 //
 // A send is watched rather than awaited because it takes about ninety seconds:
 // Gmail is throttled to one email every three seconds, and the browser arrives
@@ -391,6 +609,8 @@ export interface SendOutcome {
 export interface AvailabilitySend {
   id: string;
   mode: SendMode;
+  status: SendStatus;
+  startedAt: string;
   done: number;
   total: number;
   finished: boolean;
@@ -399,6 +619,29 @@ export interface AvailabilitySend {
   error: string | null;
 }
 
+// SendStatus is where a send has got to. "interrupted" is a send whose server
+// went away partway through — a deploy, usually — and is the only kind that
+// can be resumed: it carries on from the first volunteer it had not reached.
+export type SendStatus = "running" | "interrupted" | "finished";
+
+// AvailabilitySendSummary is one send in a round's history. Every admin's
+// sends are listed, as counts: who was emailed is only shown to the admin who
+// sent it.
+export interface AvailabilitySendSummary {
+  id: string;
+  mode: SendMode;
+  adminEmail: string;
+  volunteerId: string | null;
+  status: SendStatus;
+  startedAt: string;
+  finishedAt: string | null;
+  done: number;
+  total: number;
+  sentCount: number;
+  failedCount: number;
+  error: string | null;
+}
+
 // AvailabilityGroup is a round at the grain allocation happens at: the people
 // placed together, and the one answer that speaks for them.
 //
@@ -406,11 +649,20 @@ export interface AvailabilitySend {
 // intersection over whoever answered — so nothing here re-derives it. Empty for
 // a group nobody has answered for, which replied is what tells apart from a
 // group that answered "none of these".
+//
+// preferredShiftCount is the group's "only want N", settled by the server the
+// same way — the least any member who answered asked for — and null when none
+// of them gave a number.
+//
+// absentShiftIds are the shifts any member is away for, answered or not. The
+// server has already taken them out of availableShiftIds.
 export interface AvailabilityGroup {
   key: string;
   name: string;
   replied: boolean;
   availableShiftIds: string[];
+  preferredShiftCount: number | null;
+  absentShiftIds: string[];
   members: AvailabilityEntry[];
 }
 
@@ -466,22 +718,180 @@ export interface AvailabilityFormState {
   selectedShiftIds: string[];
   submitted: boolean;
   submittedAt: string | null;
+  // How many of the selected dates they would like to do; 0 is "no
+  // preference", which is also where a first visit lands.
+  preferredShiftCount: number;
   // False when nothing said here can reach a rota: the volunteer has stopped,
   // or is off the roster. Optional, and only an explicit false warns — a server
   // that does not send it must not tell every volunteer they have stopped.
   counts?: boolean;
+  // Their own Absences, soonest first, and the shifts those cover. A covered
+  // shift is never among selectedShiftIds: it is shown as a day away rather
+  // than offered as a yes.
+  absences: Absence[];
+  absentShiftIds: string[];
 }
 
 // Why a link stopped working, kept apart because they mean different things to
 // the person holding it: "this was never a link" versus "you are too late".
 export type AvailabilityLinkFailure = "not-found" | "gone";
 
+// SwapShift is one of a volunteer's own upcoming shifts on their swap page.
+// requestStatus is their live ask to give it up — "open" while it is on offer,
+// "taken" once somebody has taken it and an admin has yet to say yes — and null
+// when they have not asked.
+export interface SwapShift {
+  shiftId: string;
+  date: string;
+  start: string;
+  end: string;
+  role: string | null;
+  requestId: string | null;
+  requestStatus: "open" | "taken" | null;
+  // The first name of whoever has taken it, while it waits for an admin.
+  takenBy: string | null;
+}
+
+// SwapOffer is somebody else's shift this volunteer could take: they hold its
+// Role, said they were free that day, and are not already on it.
+export interface SwapOffer {
+  requestId: string;
+  shiftId: string;
+  date: string;
+  start: string;
+  end: string;
+  role: string | null;
+  // A first name only — the page is behind a link, not a login.
+  from: string;
+}
+
+// SwapPageState is what is behind a volunteer's link once their rota is out.
+export interface SwapPageState {
+  volunteerName: string;
+  shifts: SwapShift[];
+  offers: SwapOffer[];
+  // Whether a shift taken now waits for an admin, so the page can say so
+  // before anybody takes one rather than after.
+  needsApproval: boolean;
+  // Whether they hold the leading Role, and so can record who came to the
+  // shifts they led from their attendance page.
+  leadsShifts: boolean;
+}
+
+// SwapRequest is one live swap as the admins see it: on offer, or taken and
+// waiting for one of them.
+export interface SwapRequest {
+  id: string;
+  shiftId: string;
+  date: string;
+  role: string | null;
+  status: "open" | "taken";
+  volunteerId: string;
+  volunteerName: string;
+  takenBy: string | null;
+  takenByName: string | null;
+  requestedAt: string;
+  takenAt: string | null;
+}
+
+// CoverAnswer is one volunteer who said they could cover, and when.
+export interface CoverAnswer {
+  volunteerId: string;
+  volunteerName: string;
+  answeredAt: string;
+}
+
+// CoverRequest is an admin's ask for somebody to cover one place on an
+// allocated shift, as the admins see it. replacing is who is dropping out, or
+// null when the shift is simply short. yes is everyone who said they could,
+// first to answer first; the noes and the silent are only counted.
+//
+// expired is past the shift's start: the links have stopped answering, but a
+// yes already given can still be put on.
+export interface CoverRequest {
+  id: string;
+  shiftId: string;
+  date: string;
+  start: string;
+  end: string;
+  role: string;
+  replacing: string | null;
+  replacingName: string | null;
+  reason: string;
+  createdBy: string;
+  createdAt: string;
+  expiresAt: string;
+  expired: boolean;
+  asked: number;
+  emailed: number;
+  no: number;
+  yes: CoverAnswer[];
+}
+
+// NewCoverRequest is an admin asking for cover. role may be left null when
+// replacing is set, to mean the Role they hold on the shift.
+export interface NewCoverRequest {
+  shiftId: string;
+  role: string | null;
+  replacing: string | null;
+  reason: string;
+}
+
+// CoverFormState is what is behind a volunteer's cover link: the one shift,
+// and what they have said about it. answer is null until they say.
+export interface CoverFormState {
+  firstName: string;
+  date: string;
+  start: string;
+  end: string;
+  role: string;
+  answer: "yes" | "no" | null;
+}
+
+// AttendanceStatus is what was recorded of somebody on a shift that has
+// happened.
+export type AttendanceStatus = "attended" | "no_show" | "left_early";
+
+// AttendanceEntry is one person on a shift that has happened. status is null
+// until somebody says; recordedBy is an admin's email or a Team lead's
+// volunteer id, and recordedByName is only set for the latter.
+export interface AttendanceEntry {
+  volunteerId: string;
+  volunteerName: string;
+  role: string;
+  status: AttendanceStatus | null;
+  recordedBy: string | null;
+  recordedByName: string | null;
+  recordedAt: string | null;
+}
+
+// ShiftAttendance is a shift that has happened and everyone who was on it as
+// the rota stood, leading Role first.
+export interface ShiftAttendance {
+  shiftId: string;
+  date: string;
+  start: string;
+  end: string;
+  people: AttendanceEntry[];
+}
+
+// LeadAttendancePage is what is behind a Team lead's attendance link: the
+// shifts they led that have started.
+export interface LeadAttendancePage {
+  volunteerName: string;
+  role: string;
+  shifts: ShiftAttendance[];
+}
+
 export interface RotaShift {
   // How a change to this shift is addressed. The rota reads in dates, but a
   // close, a reopen or a change of hours is a change to the entity, which is
   // keyed by id.
   id: string;
   date: string;
+  // The rota the shift belongs to. The rota page lists shifts by date across
+  // rotas; this is how it gets from one of them to its rota whole, to print.
+  rotaId: string;
   // When the shift runs, as the shift itself holds it: local wall-clock time in
   // the drop-in's own zone, "2026-02-02T19:30:00", with no offset on the end.
   //
@@ -567,3 +977,68 @@ export interface NewStandingPreallocation {
   roleId: string;
   person: PersonRef;
 }
+
+// PairingKind is what a Pairing Rule asks of the allocator. "together" is a
+// preference it weighs against everything else it wants; "apart" is a rule it
+// never breaks, a pin included.
+export type PairingKind = "together" | "apart";
+
+// PairingRule is something an admin knows about two volunteers that a group
+// cannot say: try to put them on together, or never put them on the same
+// shift. Unlike a group it is not all-or-nothing, and unlike the roster sheet it
+// is seen only by admins — an "apart" rule is often a safeguarding decision.
+//
+// The two are named by roster id and by name; which is "a" and which is "b"
+// means nothing.
+export interface PairingRule {
+  id: string;
+  kind: PairingKind;
+  volunteerA: string;
+  nameA: string;
+  volunteerB: string;
+  nameB: string;
+  note: string | null;
+}
+
+// NewPairingRule is one rule to add. There is no edit: changing a rule is
+// removing it and making the one that was meant.
+export interface NewPairingRule {
+  volunteerA: string;
+  volunteerB: string;
+  kind: PairingKind;
+  note: string;
+}
+
+// Absence is a stretch of days a volunteer has said in advance they are away,
+// from and to inclusive. Every shift it covers is a no, whatever they answer.
+// name is the volunteer's, on the admins' list; createdBy is the admin who
+// recorded it, or null when the volunteer did it themselves.
+export interface Absence {
+  id: string;
+  volunteerId: string;
+  name: string;
+  from: string;
+  to: string;
+  note: string | null;
+  createdBy: string | null;
+  createdAt: string;
+}
+
+// NewAbsence is one to add. There is no edit, as there is none for a Pairing
+// Rule: changing one is removing it and adding the one that was meant.
+export interface NewAbsence {
+  from: string;
+  to: string;
+  note: string;
+}
+
+// CalendarToken is one volunteer's calendar feed as an admin hands it out: the
+// link to subscribe to, which is addressed by a secret rather than by the
+// volunteer's id, and who made it. Minting a new one for somebody revokes the
+// old, so a link that has got out stops working.
+export interface CalendarToken {
+  volunteerId: string;
+  link: string;
+  createdBy: string;
+  createdAt: string;
+}